@@ -126,3 +126,14 @@ func NewBTPBlockHeader(
 	}
 	return bb, nil
 }
+
+// NewBTPBlockHeaderFromBytes returns the BTPBlockHeader decoded from the
+// bytes returned by HeaderBytes.
+func NewBTPBlockHeaderFromBytes(bs []byte) (module.BTPBlockHeader, error) {
+	bh := &btpBlockHeader{}
+	if _, err := codec.UnmarshalFromBytes(bs, &bh.format); err != nil {
+		return nil, err
+	}
+	bh.bytes = bs
+	return bh, nil
+}
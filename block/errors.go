@@ -4,6 +4,7 @@ import "github.com/icon-project/goloop/common/errors"
 
 const (
 	ResultNotFinalizedError = errors.CodeBlock + iota
+	PrunedError
 )
 
 var (
@@ -27,9 +27,11 @@ const (
 )
 
 const (
-	keyLastBlockHeight = "block.lastHeight"
-	genesisHeight      = 0
-	ConfigCacheCap     = 10
+	keyLastBlockHeight   = "block.lastHeight"
+	keyLowestBlockHeight = "block.lowestHeight"
+	keyLowestStateHeight = "block.lowestStateHeight"
+	genesisHeight        = 0
+	ConfigCacheCap       = 10
 )
 
 type transactionLocator struct {
@@ -1005,30 +1007,35 @@ func (m *manager) finalize(bn *bnode, updatePCM bool) error {
 		m.bntr.TraceRef(bn)
 	}
 
-	err = block.(base.BlockVersionSpec).FinalizeHeader(m.chain.Database())
+	// write block data in a batch, so that it can't be written partially.
+	ldb := db.NewLayerDB(m.db())
+	err = block.(base.BlockVersionSpec).FinalizeHeader(ldb)
 	if err != nil {
 		return err
 	}
-	nextVer := m.sm.GetNextBlockVersion(m.finalized.in.mtransition().Result())
-	if m.activeHandlers.last().Version() != nextVer {
-		m.activeHandlers = m.handlers.upTo(nextVer)
-	}
-
 	if err = WriteTransactionLocators(
-		m.db(),
+		ldb,
 		block.Height(),
 		block.PatchTransactions(),
 		block.NormalTransactions(),
 	); err != nil {
 		return err
 	}
-	chainProp, err := m.bucketFor(db.ChainProperty)
+	chainProp, err := db.NewCodedBucket(ldb, db.ChainProperty, nil)
 	if err != nil {
 		return err
 	}
 	if err = chainProp.Set(db.Raw(keyLastBlockHeight), block.Height()); err != nil {
 		return err
 	}
+	if err = ldb.Flush(true); err != nil {
+		return err
+	}
+
+	nextVer := m.sm.GetNextBlockVersion(m.finalized.in.mtransition().Result())
+	if m.activeHandlers.last().Version() != nextVer {
+		m.activeHandlers = m.handlers.upTo(nextVer)
+	}
 
 	if updatePCM {
 		nextPCM, err := m.nextPCM.Update(m.finalized.block)
@@ -1889,6 +1896,60 @@ func GetLastHeightOf(dbase db.Database) int64 {
 	return height
 }
 
+func getHeightProperty(dbase db.Database, key string) (int64, error) {
+	bk, err := dbase.GetBucket(db.ChainProperty)
+	if err != nil {
+		return 0, err
+	}
+	bs, err := bk.Get([]byte(key))
+	if err != nil || bs == nil {
+		return 0, err
+	}
+	var height int64
+	if _, err := codec.BC.UnmarshalFromBytes(bs, &height); err != nil {
+		return 0, err
+	}
+	return height, nil
+}
+
+func setHeightProperty(dbase db.Database, key string, height int64) error {
+	bk, err := dbase.GetBucket(db.ChainProperty)
+	if err != nil {
+		return err
+	}
+	return bk.Set([]byte(key), codec.BC.MustMarshalToBytes(height))
+}
+
+// GetLowestHeight returns the lowest height of the blocks whose bodies and
+// results are retained. It returns zero if no blocks are pruned.
+func GetLowestHeight(dbase db.Database) (int64, error) {
+	return getHeightProperty(dbase, keyLowestBlockHeight)
+}
+
+func GetLowestHeightOf(dbase db.Database) int64 {
+	height, _ := GetLowestHeight(dbase)
+	return height
+}
+
+func SetLowestHeight(dbase db.Database, height int64) error {
+	return setHeightProperty(dbase, keyLowestBlockHeight, height)
+}
+
+// GetLowestStateHeight returns the lowest height of the blocks whose world
+// states are retained. It returns zero if no states are pruned.
+func GetLowestStateHeight(dbase db.Database) (int64, error) {
+	return getHeightProperty(dbase, keyLowestStateHeight)
+}
+
+func GetLowestStateHeightOf(dbase db.Database) int64 {
+	height, _ := GetLowestStateHeight(dbase)
+	return height
+}
+
+func SetLowestStateHeight(dbase db.Database, height int64) error {
+	return setHeightProperty(dbase, keyLowestStateHeight, height)
+}
+
 func ResetDB(d db.Database, c codec.Codec, height int64) error {
 	return SetLastHeight(d, c, height)
 }
@@ -46,13 +46,14 @@ func (r *finalizeRequest) finalize(blk module.BlockData) error {
 		return err
 	}
 
-	if err = blk.(base.BlockVersionSpec).FinalizeHeader(r.dbase); err != nil {
+	ldb := db.NewLayerDB(r.dbase)
+	if err = blk.(base.BlockVersionSpec).FinalizeHeader(ldb); err != nil {
 		return err
 	}
-	if err = WriteTransactionLocators(r.dbase, blk.Height(), blk.PatchTransactions(), blk.NormalTransactions()); err != nil {
+	if err = WriteTransactionLocators(ldb, blk.Height(), blk.PatchTransactions(), blk.NormalTransactions()); err != nil {
 		return err
 	}
-	return nil
+	return ldb.Flush(true)
 }
 
 func (r *finalizeRequest) OnValidate(t module.Transition, err error) {
@@ -0,0 +1,219 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ntm
+
+import (
+	"crypto/sha256"
+	"io"
+	"math/big"
+
+	bls12381 "github.com/kilic/bls12-381"
+	"golang.org/x/crypto/hkdf"
+
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/wallet"
+	"github.com/icon-project/goloop/module"
+)
+
+// BLS signatures with the proof of possession scheme of
+// draft-irtf-cfrg-bls-signature. Public keys are in G1 and signatures are
+// in G2, so aggregating public keys for verification is cheap.
+
+const (
+	blsPublicKeyLen = 48
+	blsSignatureLen = 96
+	blsSecretLen    = 32
+
+	blsKeyGenSalt = "BLS-SIG-KEYGEN-SALT-"
+	blsSigDST     = "BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_"
+	blsPopDST     = "BLS_POP_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_"
+
+	blsWalletPurpose = "goloop/bls12-381"
+)
+
+// blsDSAKeyLen is the length of DSA key which is a public key followed by
+// its proof of possession.
+const blsDSAKeyLen = blsPublicKeyLen + blsSignatureLen
+
+type blsSecretKey struct {
+	s *big.Int
+}
+
+// newBLSSecretKey generates a secret key from the input key material as
+// KeyGen of the draft.
+func newBLSSecretKey(ikm []byte) (*blsSecretKey, error) {
+	if len(ikm) < blsSecretLen {
+		return nil, errors.IllegalArgumentError.Errorf("ShortKeyMaterial(len=%d)", len(ikm))
+	}
+	salt := []byte(blsKeyGenSalt)
+	okm := make([]byte, 48)
+	s := new(big.Int)
+	for s.Sign() == 0 {
+		h := sha256.Sum256(salt)
+		salt = h[:]
+		r := hkdf.New(sha256.New, append(ikm[:len(ikm):len(ikm)], 0), salt, []byte{0, byte(len(okm))})
+		if _, err := io.ReadFull(r, okm); err != nil {
+			return nil, err
+		}
+		s.SetBytes(okm)
+		s.Mod(s, bls12381.NewG1().Q())
+	}
+	return &blsSecretKey{s: s}, nil
+}
+
+func (sk *blsSecretKey) PublicKey() []byte {
+	g1 := bls12381.NewG1()
+	return g1.ToCompressed(g1.MulScalarBig(g1.New(), g1.One(), sk.s))
+}
+
+func (sk *blsSecretKey) sign(msg []byte, dst string) ([]byte, error) {
+	g2 := bls12381.NewG2()
+	h, err := g2.HashToCurve(msg, []byte(dst))
+	if err != nil {
+		return nil, err
+	}
+	return g2.ToCompressed(g2.MulScalarBig(h, h, sk.s)), nil
+}
+
+func (sk *blsSecretKey) Sign(msg []byte) ([]byte, error) {
+	return sk.sign(msg, blsSigDST)
+}
+
+// DSAKey returns the public key with its proof of possession.
+func (sk *blsSecretKey) DSAKey() ([]byte, error) {
+	pk := sk.PublicKey()
+	pop, err := sk.sign(pk, blsPopDST)
+	if err != nil {
+		return nil, err
+	}
+	return append(pk, pop...), nil
+}
+
+func parseBLSPublicKey(pk []byte) (*bls12381.PointG1, error) {
+	g1 := bls12381.NewG1()
+	p, err := g1.FromCompressed(pk)
+	if err != nil {
+		return nil, errors.IllegalArgumentError.Wrapf(err, "InvalidPublicKey(%x)", pk)
+	}
+	if g1.IsZero(p) {
+		return nil, errors.IllegalArgumentError.Errorf("InfinityPublicKey(%x)", pk)
+	}
+	return p, nil
+}
+
+func parseBLSSignature(sig []byte) (*bls12381.PointG2, error) {
+	p, err := bls12381.NewG2().FromCompressed(sig)
+	if err != nil {
+		return nil, errors.IllegalArgumentError.Wrapf(err, "InvalidSignature(%x)", sig)
+	}
+	return p, nil
+}
+
+func blsVerify(pk *bls12381.PointG1, msg []byte, sig []byte, dst string) error {
+	s, err := parseBLSSignature(sig)
+	if err != nil {
+		return err
+	}
+	h, err := bls12381.NewG2().HashToCurve(msg, []byte(dst))
+	if err != nil {
+		return err
+	}
+	e := bls12381.NewEngine()
+	e.AddPair(pk, h)
+	e.AddPairInv(e.G1.One(), s)
+	if !e.Check() {
+		return errors.IllegalArgumentError.New("InvalidSignature")
+	}
+	return nil
+}
+
+// verifyBLSDSAKey verifies the proof of possession in the DSA key, and
+// returns the public key.
+func verifyBLSDSAKey(key []byte) ([]byte, error) {
+	if len(key) != blsDSAKeyLen {
+		return nil, errors.IllegalArgumentError.Errorf("InvalidKeyLength(len=%d)", len(key))
+	}
+	pkBytes := key[:blsPublicKeyLen]
+	pk, err := parseBLSPublicKey(pkBytes)
+	if err != nil {
+		return nil, err
+	}
+	if err := blsVerify(pk, pkBytes, key[blsPublicKeyLen:], blsPopDST); err != nil {
+		return nil, errors.IllegalArgumentError.Wrap(err, "InvalidProofOfPossession")
+	}
+	return pkBytes, nil
+}
+
+// aggregateBLSPublicKeys returns sum of public keys. Public keys shall be
+// registered with the proof of possession to prevent rogue key attack.
+func aggregateBLSPublicKeys(pks [][]byte) (*bls12381.PointG1, error) {
+	g1 := bls12381.NewG1()
+	agg := g1.Zero()
+	for _, pkBytes := range pks {
+		pk, err := parseBLSPublicKey(pkBytes)
+		if err != nil {
+			return nil, err
+		}
+		g1.Add(agg, agg, pk)
+	}
+	return agg, nil
+}
+
+func aggregateBLSSignatures(sigs [][]byte) ([]byte, error) {
+	g2 := bls12381.NewG2()
+	agg := g2.Zero()
+	for _, sigBytes := range sigs {
+		sig, err := parseBLSSignature(sigBytes)
+		if err != nil {
+			return nil, err
+		}
+		g2.Add(agg, agg, sig)
+	}
+	return g2.ToCompressed(agg), nil
+}
+
+// NewBLSWallet returns a wallet for BLS12-381 DSA. The secret key is derived
+// from the private key of the wallet. PublicKey of the returned wallet
+// returns the DSA key to be registered for the node.
+func NewBLSWallet(w module.Wallet) (module.BaseWallet, error) {
+	ikm, err := wallet.DeriveSecret(w, blsWalletPurpose, blsSecretLen)
+	if err != nil {
+		return nil, err
+	}
+	sk, err := newBLSSecretKey(ikm)
+	if err != nil {
+		return nil, err
+	}
+	key, err := sk.DSAKey()
+	if err != nil {
+		return nil, err
+	}
+	return &blsWallet{sk: sk, key: key}, nil
+}
+
+type blsWallet struct {
+	sk  *blsSecretKey
+	key []byte
+}
+
+func (w *blsWallet) Sign(data []byte) ([]byte, error) {
+	return w.sk.Sign(data)
+}
+
+func (w *blsWallet) PublicKey() []byte {
+	return w.key
+}
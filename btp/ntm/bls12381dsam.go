@@ -0,0 +1,41 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ntm
+
+const (
+	bls12381DSA = "bls12-381"
+)
+
+type bls12381DSAModule struct {
+}
+
+func (s bls12381DSAModule) Name() string {
+	return bls12381DSA
+}
+
+// Verify verifies the DSA key which is a public key followed by its proof
+// of possession.
+func (s bls12381DSAModule) Verify(pubKey []byte) error {
+	_, err := verifyBLSDSAKey(pubKey)
+	return err
+}
+
+var bls12381DSAModuleInstance bls12381DSAModule
+
+func init() {
+	registerDSAModule(bls12381DSAModuleInstance)
+}
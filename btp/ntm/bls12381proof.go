@@ -0,0 +1,397 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ntm
+
+import (
+	"math/bits"
+
+	bls12381 "github.com/kilic/bls12-381"
+
+	"github.com/icon-project/goloop/common/cache"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/module"
+)
+
+type blsProofPart struct {
+	Index     int
+	Signature []byte
+}
+
+func (pp *blsProofPart) Bytes() []byte {
+	return codec.MustMarshalToBytes(pp)
+}
+
+// blsProof has signatures of validators. It's used in commit votes, so
+// each proof part can be restored from it.
+type blsProof struct {
+	Signatures [][]byte
+	bytes      []byte
+}
+
+func (p *blsProof) Bytes() []byte {
+	if p.bytes == nil {
+		p.bytes = codec.MustMarshalToBytes(p)
+	}
+	return p.bytes
+}
+
+func (p *blsProof) Add(pp module.BTPProofPart) {
+	bpp := pp.(*blsProofPart)
+	p.Signatures[bpp.Index] = bpp.Signature
+	p.bytes = nil
+}
+
+func (p *blsProof) ValidatorCount() int {
+	return len(p.Signatures)
+}
+
+func (p *blsProof) ProofPartAt(i int) module.BTPProofPart {
+	if p.Signatures[i] == nil {
+		return nil
+	}
+	return &blsProofPart{i, p.Signatures[i]}
+}
+
+// Aggregate returns an aggregated proof which has only one signature and
+// bit flags of signed validators.
+func (p *blsProof) Aggregate() (module.BTPProof, error) {
+	ap := newBLSAggregatedProof(len(p.Signatures))
+	sigs := make([][]byte, 0, len(p.Signatures))
+	for i, sig := range p.Signatures {
+		if sig != nil {
+			ap.setSigner(i)
+			sigs = append(sigs, sig)
+		}
+	}
+	if len(sigs) > 0 {
+		sig, err := aggregateBLSSignatures(sigs)
+		if err != nil {
+			return nil, err
+		}
+		ap.Signature = sig
+	}
+	return ap, nil
+}
+
+// blsAggregatedProof is a compact proof for relays. Proof parts can be added
+// to it, but they can't be restored.
+type blsAggregatedProof struct {
+	Validators int
+	Signers    []byte
+	Signature  []byte
+	bytes      []byte
+}
+
+func newBLSAggregatedProof(validators int) *blsAggregatedProof {
+	return &blsAggregatedProof{
+		Validators: validators,
+		Signers:    make([]byte, (validators+7)/8),
+	}
+}
+
+func (p *blsAggregatedProof) hasSigner(i int) bool {
+	return p.Signers[i/8]&(1<<(i%8)) != 0
+}
+
+func (p *blsAggregatedProof) setSigner(i int) {
+	p.Signers[i/8] |= 1 << (i % 8)
+}
+
+func (p *blsAggregatedProof) signerCount() int {
+	cnt := 0
+	for _, b := range p.Signers {
+		cnt += bits.OnesCount8(b)
+	}
+	return cnt
+}
+
+func (p *blsAggregatedProof) Bytes() []byte {
+	if p.bytes == nil {
+		p.bytes = codec.MustMarshalToBytes(p)
+	}
+	return p.bytes
+}
+
+func (p *blsAggregatedProof) Add(pp module.BTPProofPart) {
+	bpp := pp.(*blsProofPart)
+	if p.hasSigner(bpp.Index) {
+		return
+	}
+	sigs := [][]byte{bpp.Signature}
+	if p.Signature != nil {
+		sigs = append(sigs, p.Signature)
+	}
+	sig, err := aggregateBLSSignatures(sigs)
+	if err != nil {
+		return
+	}
+	p.setSigner(bpp.Index)
+	p.Signature = sig
+	p.bytes = nil
+}
+
+func (p *blsAggregatedProof) ValidatorCount() int {
+	return p.Validators
+}
+
+func (p *blsAggregatedProof) ProofPartAt(i int) module.BTPProofPart {
+	return nil
+}
+
+func (p *blsAggregatedProof) Aggregate() (module.BTPProof, error) {
+	return p, nil
+}
+
+func (p *blsAggregatedProof) verifyFormat() error {
+	if p.Validators < 0 || len(p.Signers) != (p.Validators+7)/8 {
+		return errors.Errorf("invalid signers validators=%d len(signers)=%d", p.Validators, len(p.Signers))
+	}
+	if p.Validators%8 != 0 && len(p.Signers) > 0 {
+		if p.Signers[len(p.Signers)-1]>>(p.Validators%8) != 0 {
+			return errors.Errorf("invalid signers validators=%d signers=%x", p.Validators, p.Signers)
+		}
+	}
+	return nil
+}
+
+// newBLSProofFromBytes returns blsProof or blsAggregatedProof. They are
+// distinguished by the type of the first field.
+func newBLSProofFromBytes(bs []byte) (module.BTPProof, error) {
+	var p blsProof
+	if _, err := codec.UnmarshalFromBytes(bs, &p); err == nil {
+		return &p, nil
+	}
+	var ap blsAggregatedProof
+	if _, err := codec.UnmarshalFromBytes(bs, &ap); err != nil {
+		return nil, err
+	}
+	if err := ap.verifyFormat(); err != nil {
+		return nil, err
+	}
+	return &ap, nil
+}
+
+type blsProofContext struct {
+	Validators [][]byte
+	mod        *networkTypeModule
+	bytes      cache.ByteSlice
+	keyToIndex map[string]int
+}
+
+func newBLSProofContext(
+	mod *networkTypeModule,
+	keys [][]byte,
+) (*blsProofContext, error) {
+	pc := &blsProofContext{
+		Validators: make([][]byte, 0, len(keys)),
+		keyToIndex: make(map[string]int, len(keys)),
+		mod:        mod,
+	}
+	for i, key := range keys {
+		if key != nil {
+			if _, err := parseBLSPublicKey(key); err != nil {
+				return nil, errors.Wrapf(err, "invalid key index=%d key=%x", i, key)
+			}
+			pc.keyToIndex[string(key)] = i
+		}
+		pc.Validators = append(pc.Validators, key)
+	}
+	return pc, nil
+}
+
+func newBLSProofContextFromBytes(
+	mod *networkTypeModule,
+	bytes []byte,
+) (*blsProofContext, error) {
+	pc := &blsProofContext{
+		mod: mod,
+	}
+	if bytes != nil {
+		_, err := codec.UnmarshalFromBytes(bytes, pc)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return pc, nil
+}
+
+func (pc *blsProofContext) indexOf(key []byte) (int, bool) {
+	if pc.keyToIndex == nil {
+		pc.keyToIndex = make(map[string]int, len(pc.Validators))
+		for i, key := range pc.Validators {
+			if key != nil {
+				pc.keyToIndex[string(key)] = i
+			}
+		}
+	}
+	idx, ok := pc.keyToIndex[string(key)]
+	return idx, ok
+}
+
+func (pc *blsProofContext) NetworkTypeModule() module.NetworkTypeModule {
+	return pc.mod
+}
+
+func (pc *blsProofContext) Bytes() []byte {
+	return pc.bytes.Get(func() []byte {
+		if pc.Validators == nil {
+			return nil
+		}
+		return codec.MustMarshalToBytes(pc)
+	})
+}
+
+func (pc *blsProofContext) publicKeyAt(idx int) (*bls12381.PointG1, error) {
+	if idx < 0 || idx >= len(pc.Validators) {
+		return nil, errors.Errorf("invalid proof part index=%d numValidators=%d", idx, len(pc.Validators))
+	}
+	if pc.Validators[idx] == nil {
+		return nil, errors.Errorf("no public key for validator index=%d", idx)
+	}
+	return parseBLSPublicKey(pc.Validators[idx])
+}
+
+// VerifyPart returns validator index and error
+func (pc *blsProofContext) VerifyPart(dHash []byte, pp module.BTPProofPart) (int, error) {
+	bpp := pp.(*blsProofPart)
+	pk, err := pc.publicKeyAt(bpp.Index)
+	if err != nil {
+		return -1, err
+	}
+	if err := blsVerify(pk, dHash, bpp.Signature, blsSigDST); err != nil {
+		return -1, errors.Wrapf(err, "invalid proof part index=%d key=%x", bpp.Index, pc.Validators[bpp.Index])
+	}
+	return bpp.Index, nil
+}
+
+func (pc *blsProofContext) NewProofPartFromBytes(ppBytes []byte) (module.BTPProofPart, error) {
+	var pp blsProofPart
+	_, err := codec.UnmarshalFromBytes(ppBytes, &pp)
+	if err != nil {
+		return nil, err
+	}
+	return &pp, err
+}
+
+func (pc *blsProofContext) verifyAggregated(dHash []byte, signers []int, sig []byte) error {
+	if len(signers) <= 2*len(pc.Validators)/3 {
+		return errors.Errorf("not enough proof parts numValidator=%d numProofParts=%d", len(pc.Validators), len(signers))
+	}
+	g1 := bls12381.NewG1()
+	aggPK := g1.Zero()
+	for _, idx := range signers {
+		pk, err := pc.publicKeyAt(idx)
+		if err != nil {
+			return err
+		}
+		g1.Add(aggPK, aggPK, pk)
+	}
+	return blsVerify(aggPK, dHash, sig, blsSigDST)
+}
+
+func (pc *blsProofContext) Verify(dHash []byte, p module.BTPProof) error {
+	switch bp := p.(type) {
+	case *blsProof:
+		if len(bp.Signatures) != len(pc.Validators) {
+			return errors.Errorf("invalid proof numValidators=%d numSignatures=%d", len(pc.Validators), len(bp.Signatures))
+		}
+		signers := make([]int, 0, len(bp.Signatures))
+		sigs := make([][]byte, 0, len(bp.Signatures))
+		for i, sig := range bp.Signatures {
+			if sig != nil {
+				signers = append(signers, i)
+				sigs = append(sigs, sig)
+			}
+		}
+		if len(sigs) == 0 {
+			return errors.Errorf("not enough proof parts numValidator=%d numProofParts=0", len(pc.Validators))
+		}
+		sig, err := aggregateBLSSignatures(sigs)
+		if err != nil {
+			return err
+		}
+		return pc.verifyAggregated(dHash, signers, sig)
+	case *blsAggregatedProof:
+		if bp.Validators != len(pc.Validators) {
+			return errors.Errorf("invalid proof numValidators=%d proof.validators=%d", len(pc.Validators), bp.Validators)
+		}
+		if err := bp.verifyFormat(); err != nil {
+			return err
+		}
+		signers := make([]int, 0, bp.signerCount())
+		for i := 0; i < bp.Validators; i++ {
+			if bp.hasSigner(i) {
+				signers = append(signers, i)
+			}
+		}
+		return pc.verifyAggregated(dHash, signers, bp.Signature)
+	default:
+		return errors.Errorf("invalid proof type %T", p)
+	}
+}
+
+func (pc *blsProofContext) NewProofFromBytes(proofBytes []byte) (module.BTPProof, error) {
+	return newBLSProofFromBytes(proofBytes)
+}
+
+func (pc *blsProofContext) NewProofPart(
+	dHash []byte,
+	wp module.WalletProvider,
+) (module.BTPProofPart, error) {
+	w := wp.WalletFor(bls12381DSA)
+	if w == nil {
+		return nil, errors.Errorf("no wallet for uid=%s dsa=%s", pc.mod.UID(), bls12381DSA)
+	}
+	key := w.PublicKey()
+	if len(key) < blsPublicKeyLen {
+		return nil, errors.Errorf("invalid wallet key=%x", key)
+	}
+	idx, ok := pc.indexOf(key[:blsPublicKeyLen])
+	if !ok {
+		return nil, errors.Errorf("not validator key=%x", key[:blsPublicKeyLen])
+	}
+	sig, err := w.Sign(dHash)
+	if err != nil {
+		return nil, err
+	}
+	return &blsProofPart{
+		Index:     idx,
+		Signature: sig,
+	}, nil
+}
+
+func (pc *blsProofContext) DSA() string {
+	return bls12381DSA
+}
+
+func (pc *blsProofContext) NewProof() module.BTPProof {
+	return &blsProof{
+		Signatures: make([][]byte, len(pc.Validators)),
+	}
+}
+
+func blsPublicKeyOf(key []byte) ([]byte, error) {
+	switch len(key) {
+	case blsPublicKeyLen:
+		return key, nil
+	case blsDSAKeyLen:
+		return append([]byte(nil), key[:blsPublicKeyLen]...), nil
+	default:
+		return nil, errors.Errorf("invalid key length len=%d", len(key))
+	}
+}
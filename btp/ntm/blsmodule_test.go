@@ -0,0 +1,196 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ntm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common/wallet"
+	"github.com/icon-project/goloop/module"
+)
+
+func newBLSWalletProvider(t *testing.T) (*walletProvider, module.BaseWallet) {
+	w, err := NewBLSWallet(wallet.New())
+	assert.NoError(t, err)
+	wp := walletProvider{
+		wallets: map[string]module.BaseWallet{
+			bls12381DSA: w,
+		},
+	}
+	return &wp, w
+}
+
+func newBLSTestSetup(t *testing.T, count int) *testSetup {
+	s := &testSetup{
+		assert:  assert.New(t),
+		count:   count,
+		wallets: make([]*walletProvider, 0, count),
+		pubKeys: make([][]byte, 0, count),
+	}
+	for i := 0; i < count; i++ {
+		wp, w := newBLSWalletProvider(t)
+		s.wallets = append(s.wallets, wp)
+		key, err := blsModuleInstance.NetworkTypeKeyFromDSAKey(w.PublicKey())
+		s.assert.NoError(err)
+		s.pubKeys = append(s.pubKeys, key)
+	}
+	var err error
+	s.pc, err = blsModuleInstance.NewProofContext(s.pubKeys)
+	s.assert.NoError(err)
+	return s
+}
+
+func TestBLSWallet(t *testing.T) {
+	assert := assert.New(t)
+	w := wallet.New()
+	bw1, err := NewBLSWallet(w)
+	assert.NoError(err)
+	bw2, err := NewBLSWallet(w)
+	assert.NoError(err)
+	assert.Len(bw1.PublicKey(), blsDSAKeyLen)
+	assert.Equal(bw1.PublicKey(), bw2.PublicKey())
+
+	bw3, err := NewBLSWallet(wallet.New())
+	assert.NoError(err)
+	assert.NotEqual(bw1.PublicKey(), bw3.PublicKey())
+}
+
+func TestBLS12381DSAModule_Verify(t *testing.T) {
+	assert := assert.New(t)
+	dsam := DSAModuleForName(bls12381DSA)
+
+	_, w := newBLSWalletProvider(t)
+	key := w.PublicKey()
+	assert.NoError(dsam.Verify(key))
+	assert.Error(dsam.Verify(key[:blsPublicKeyLen]))
+	assert.Error(dsam.Verify(key[:len(key)-1]))
+
+	// proof of possession of other key
+	_, w2 := newBLSWalletProvider(t)
+	key2 := append([]byte{}, key[:blsPublicKeyLen]...)
+	key2 = append(key2, w2.PublicKey()[blsPublicKeyLen:]...)
+	assert.Error(dsam.Verify(key2))
+}
+
+func TestBLSProofContext_NewProofPart(t *testing.T) {
+	s := newBLSTestSetup(t, 4)
+	msgHash := keccak256([]byte("abc"))
+	for i := 0; i < s.count; i++ {
+		pp, err := s.pc.NewProofPart(msgHash, s.wallets[i])
+		s.assert.NoError(err)
+		pp2, err := s.pc.NewProofPartFromBytes(pp.Bytes())
+		s.assert.NoError(err)
+		idx, err := s.pc.VerifyPart(msgHash, pp2)
+		s.assert.NoError(err)
+		s.assert.Equal(i, idx)
+		_, err = s.pc.VerifyPart(keccak256([]byte("abcd")), pp2)
+		s.assert.Error(err)
+	}
+
+	wp, _ := newBLSWalletProvider(t)
+	_, err := s.pc.NewProofPart(msgHash, wp)
+	s.assert.Error(err)
+}
+
+func TestBLSProofContext_Verify(t *testing.T) {
+	msgHash := keccak256([]byte("abc"))
+	testCase := []struct {
+		ok      bool
+		ppCount int
+		pkCount int
+	}{
+		{false, 0, 1},
+		{true, 1, 1},
+		{false, 2, 3},
+		{true, 3, 3},
+		{false, 2, 4},
+		{true, 3, 4},
+		{false, 6, 9},
+		{true, 7, 9},
+	}
+	for _, c := range testCase {
+		s := newBLSTestSetup(t, c.pkCount)
+		p := s.newProofOfLen(c.ppCount, msgHash)
+		ap, err := p.(module.BTPAggregatableProof).Aggregate()
+		s.assert.NoError(err)
+		s.assert.Equal(c.pkCount, ap.ValidatorCount())
+		for _, pf := range []module.BTPProof{p, ap} {
+			pf2, err := s.pc.NewProofFromBytes(pf.Bytes())
+			s.assert.NoError(err)
+			s.assert.IsType(pf, pf2)
+			for _, v := range []module.BTPProof{pf, pf2} {
+				err = s.pc.Verify(msgHash, v)
+				if c.ok {
+					s.assert.NoError(err, "Verify ppCount=%d pkCount=%d proof=%T", c.ppCount, c.pkCount, v)
+				} else {
+					s.assert.Error(err, "Verify ppCount=%d pkCount=%d proof=%T", c.ppCount, c.pkCount, v)
+				}
+			}
+		}
+		s.assert.Error(s.pc.Verify(keccak256([]byte("abcd")), ap))
+	}
+}
+
+func TestBLSProofContext_Verify_FailInvalidPart(t *testing.T) {
+	s := newBLSTestSetup(t, 4)
+	s2 := newBLSTestSetup(t, 4)
+	msgHash := keccak256([]byte("abc"))
+	p := s.newProofOfLen(2, msgHash)
+	pp, err := s2.pc.NewProofPart(msgHash, s2.wallets[3])
+	s.assert.NoError(err)
+	p.Add(pp)
+	s.assert.Error(s.pc.Verify(msgHash, p))
+
+	ap, err := p.(module.BTPAggregatableProof).Aggregate()
+	s.assert.NoError(err)
+	s.assert.Error(s.pc.Verify(msgHash, ap))
+}
+
+func TestBLSAggregatedProof_Add(t *testing.T) {
+	s := newBLSTestSetup(t, 4)
+	msgHash := keccak256([]byte("abc"))
+	ap := newBLSAggregatedProof(s.count)
+	for i := 0; i < 3; i++ {
+		pp, err := s.pc.NewProofPart(msgHash, s.wallets[i])
+		s.assert.NoError(err)
+		ap.Add(pp)
+		ap.Add(pp)
+		s.assert.Nil(ap.ProofPartAt(i))
+	}
+	s.assert.Equal(3, ap.signerCount())
+	s.assert.NoError(s.pc.Verify(msgHash, ap))
+
+	p := s.newProofOfLen(3, msgHash)
+	ap2, err := p.(module.BTPAggregatableProof).Aggregate()
+	s.assert.NoError(err)
+	s.assert.Equal(ap2.Bytes(), ap.Bytes())
+}
+
+func TestBLSProofContext_codec(t *testing.T) {
+	s := newBLSTestSetup(t, 4)
+	msgHash := keccak256([]byte("abc"))
+	p := s.newProofOfLen(3, msgHash)
+	pc2, err := blsModuleInstance.NewProofContextFromBytes(s.pc.Bytes())
+	s.assert.NoError(err)
+	s.assert.Equal(s.pc.Hash(), pc2.Hash())
+	s.assert.NoError(pc2.Verify(msgHash, p))
+	s.pc = pc2
+	p2 := s.newProofOfLen(3, msgHash)
+	s.assert.NoError(s.pc.Verify(msgHash, p2))
+}
@@ -0,0 +1,165 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package relay
+
+import (
+	"time"
+
+	"github.com/icon-project/goloop/client"
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/intconv"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/server/jsonrpc"
+	v3 "github.com/icon-project/goloop/server/v3"
+)
+
+const (
+	DefaultBMCStepLimit = 1_000_000_000
+	bmcResultTimeout    = time.Minute
+	bmcPollInterval     = time.Second
+)
+
+// BMCConfig is the configuration of the BMC destination.
+type BMCConfig struct {
+	// NID is the network ID of the destination chain.
+	NID int64
+	// Address is the address of the BMC SCORE.
+	Address string
+	// Link is the BTP address of the BMC in the source chain.
+	Link string
+	// StepLimit is the step limit of the transactions.
+	StepLimit int64
+}
+
+// bmcDestination delivers messages to the BMC SCORE in a goloop chain.
+// It calls handleRelayMessage(_prev: str, _msg: bytes) with the bytes of
+// RelayMessage, and getStatus(_link: str) to get the status. The status
+// shall have rx_seq, and may have verifier.height.
+type bmcDestination struct {
+	c   *client.ClientV3
+	w   module.Wallet
+	cfg BMCConfig
+}
+
+func parseStatusInt(v interface{}) (int64, error) {
+	s, ok := v.(string)
+	if !ok {
+		return 0, errors.IllegalArgumentError.Errorf("InvalidStatusValue(%v)", v)
+	}
+	return intconv.ParseInt(s, 64)
+}
+
+func (d *bmcDestination) GetStatus() (*Status, error) {
+	res, err := d.c.Call(&v3.CallParam{
+		ToAddress: jsonrpc.Address(d.cfg.Address),
+		DataType:  "call",
+		Data: map[string]interface{}{
+			"method": "getStatus",
+			"params": map[string]interface{}{
+				"_link": d.cfg.Link,
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	jso, ok := res.(map[string]interface{})
+	if !ok {
+		return nil, errors.IllegalArgumentError.Errorf("InvalidStatus(%v)", res)
+	}
+	st := new(Status)
+	if st.RxSeq, err = parseStatusInt(jso["rx_seq"]); err != nil {
+		return nil, err
+	}
+	if verifier, ok := jso["verifier"].(map[string]interface{}); ok {
+		if h, ok := verifier["height"]; ok {
+			if st.Height, err = parseStatusInt(h); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return st, nil
+}
+
+func (d *bmcDestination) waitResult(txHash *jsonrpc.HexBytes) (*client.TransactionResult, error) {
+	param := &v3.TransactionHashParam{Hash: *txHash}
+	expire := time.Now().Add(bmcResultTimeout)
+	for {
+		res, err := d.c.WaitTransactionResult(param)
+		if err == nil {
+			return res, nil
+		}
+		je, ok := err.(*jsonrpc.Error)
+		if !ok {
+			return nil, err
+		}
+		switch je.Code {
+		case jsonrpc.ErrorCodePending, jsonrpc.ErrorCodeExecuting, jsonrpc.ErrorCodeSystemTimeout:
+			if time.Now().After(expire) {
+				return nil, errors.TimeoutError.Errorf("TimeoutForResult(tx=%s)", *txHash)
+			}
+			time.Sleep(bmcPollInterval)
+		default:
+			return nil, err
+		}
+	}
+}
+
+func (d *bmcDestination) Relay(msg *RelayMessage) error {
+	param := &v3.TransactionParam{
+		Version:     v3.VersionValue,
+		FromAddress: jsonrpc.Address(d.w.Address().String()),
+		ToAddress:   jsonrpc.Address(d.cfg.Address),
+		StepLimit:   jsonrpc.HexInt(intconv.FormatInt(d.cfg.StepLimit)),
+		NetworkID:   jsonrpc.HexInt(intconv.FormatInt(d.cfg.NID)),
+		DataType:    "call",
+		Data: map[string]interface{}{
+			"method": "handleRelayMessage",
+			"params": map[string]interface{}{
+				"_prev": d.cfg.Link,
+				"_msg":  common.HexBytes(msg.Bytes()).String(),
+			},
+		},
+	}
+	txHash, err := d.c.SendTransaction(d.w, param)
+	if err != nil {
+		return err
+	}
+	res, err := d.waitResult(txHash)
+	if err != nil {
+		return err
+	}
+	if status, err := res.Status.Int64(); err != nil || status != 1 {
+		if res.Failure != nil {
+			return errors.ExecutionFailError.Errorf("FailToRelay(tx=%s,code=%s,msg=%s)",
+				*txHash, res.Failure.CodeValue, res.Failure.MessageValue)
+		}
+		return errors.ExecutionFailError.Errorf("FailToRelay(tx=%s,status=%s)", *txHash, res.Status)
+	}
+	return nil
+}
+
+// NewBMCDestination returns the destination sending transactions to the BMC
+// with the wallet.
+func NewBMCDestination(c *client.ClientV3, w module.Wallet, cfg *BMCConfig) Destination {
+	d := &bmcDestination{c: c, w: w, cfg: *cfg}
+	if d.cfg.StepLimit <= 0 {
+		d.cfg.StepLimit = DefaultBMCStepLimit
+	}
+	return d
+}
@@ -0,0 +1,381 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package relay
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/btp/ntm"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/platform/basic"
+	"github.com/icon-project/goloop/test"
+)
+
+const (
+	e2eNetworkType = "eth"
+	e2eNetworkID   = 1
+	e2eTimeout     = 30 * time.Second
+	e2ePollDelay   = 50 * time.Millisecond
+)
+
+// newBTPChain returns a running chain of four validators having the BTP
+// network e2eNetworkID owned by the default node.
+func newBTPChain(t *testing.T) *test.Fixture {
+	f := test.NewFixture(t, test.AddDefaultNode(false), test.AddValidatorNodes(4))
+	tx := test.NewTx().Call("setRevision", map[string]string{
+		"code": fmt.Sprintf("0x%x", basic.MaxRevision),
+	}).Call("setMinimizeBlockGen", map[string]string{
+		"yn": "0x1",
+	})
+	dsa := ntm.ForUID(e2eNetworkType).DSA()
+	for _, v := range f.Validators {
+		tx.CallFrom(v.CommonAddress(), "setBTPPublicKey", map[string]string{
+			"name":   dsa,
+			"pubKey": fmt.Sprintf("0x%x", v.Chain.WalletFor(dsa).PublicKey()),
+		})
+	}
+	tx.Call("openBTPNetwork", map[string]string{
+		"networkTypeName": e2eNetworkType,
+		"name":            "e2e",
+		"owner":           f.CommonAddress().String(),
+	})
+	f.SendTransactionToProposer(tx)
+
+	test.NodeInterconnect(f.Nodes)
+	for _, n := range f.Nodes {
+		assert.NoError(t, n.CS.Start())
+	}
+	f.WaitForBlock(2)
+	return f
+}
+
+func getBlockByHeight(bm module.BlockManager, height int64) (module.Block, error) {
+	if height == 0 {
+		return bm.GetLastBlock()
+	}
+	return bm.GetBlockByHeight(height)
+}
+
+func getNetworkInfo(n *test.Node, nid, height int64) (*NetworkInfo, error) {
+	blk, err := getBlockByHeight(n.BM, height)
+	if err != nil {
+		return nil, err
+	}
+	nw, err := n.SM.BTPNetworkFromResult(blk.Result(), nid)
+	if err != nil {
+		return nil, err
+	}
+	return &NetworkInfo{
+		StartHeight:   nw.StartHeight(),
+		NextMessageSN: nw.NextMessageSN(),
+	}, nil
+}
+
+// chainSource is the Source using the default node of a chain.
+type chainSource struct {
+	n *test.Node
+}
+
+func (s *chainSource) GetNetworkInfo(nid, height int64) (*NetworkInfo, error) {
+	return getNetworkInfo(s.n, nid, height)
+}
+
+func (s *chainSource) GetLastHeight() (int64, error) {
+	blk, err := s.n.BM.GetLastBlock()
+	if err != nil {
+		return 0, err
+	}
+	return blk.Height(), nil
+}
+
+func (s *chainSource) GetProof(nid, height int64) ([]byte, error) {
+	blk, err := s.n.BM.GetBlockByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	_, proof, err := s.n.CS.GetBTPBlockHeaderAndProof(blk, nid, module.FlagBTPBlockProof)
+	return proof, err
+}
+
+func (s *chainSource) GetMessages(nid, height int64) ([][]byte, error) {
+	blk, err := s.n.BM.GetBlockByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	bd, err := s.n.SM.BTPDigestFromResult(blk.Result())
+	if err != nil {
+		return nil, err
+	}
+	nw, err := s.n.SM.BTPNetworkFromResult(blk.Result(), nid)
+	if err != nil {
+		return nil, err
+	}
+	ntd := bd.NetworkTypeDigestFor(nw.NetworkTypeID())
+	if ntd == nil || ntd.NetworkDigestFor(nid) == nil {
+		return nil, nil
+	}
+	ml, err := ntd.NetworkDigestFor(nid).MessageList(s.n.Chain.Database(), ntm.ForUID(e2eNetworkType))
+	if err != nil {
+		return nil, err
+	}
+	var msgs [][]byte
+	for i := 0; i < int(ml.Len()); i++ {
+		msg, err := ml.Get(i)
+		if err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, msg.Bytes())
+	}
+	return msgs, nil
+}
+
+func (s *chainSource) MonitorBTP(nid, height int64, cb func(header, proof []byte) error, cancel <-chan bool) error {
+	for h := height; ; {
+		blk, err := s.n.BM.GetBlockByHeight(h)
+		if errors.NotFoundError.Equals(err) {
+			select {
+			case <-cancel:
+				return nil
+			case <-time.After(e2ePollDelay):
+				continue
+			}
+		} else if err != nil {
+			return err
+		}
+		bh, proof, err := s.n.CS.GetBTPBlockHeaderAndProof(blk, nid,
+			module.FlagBTPBlockHeader|module.FlagBTPBlockProof)
+		if err == nil {
+			if err := cb(bh.HeaderBytes(), proof); err != nil {
+				return err
+			}
+		} else if !errors.NotFoundError.Equals(err) {
+			return err
+		}
+		h++
+	}
+}
+
+// chainDestination verifies RelayMessages as a BMV of the source network,
+// and delivers the messages to the BTP network of the destination chain.
+// The number of messages in the network of the destination is the RxSeq.
+type chainDestination struct {
+	f      *test.Fixture
+	srcUID []byte
+	mod    module.NetworkTypeModule
+	pc     module.BTPProofContext
+	height int64
+
+	// rxSeq notifies the RxSeq after each relay.
+	rxSeq chan int64
+}
+
+func (d *chainDestination) GetStatus() (*Status, error) {
+	ni, err := getNetworkInfo(d.f.Node, e2eNetworkID, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &Status{RxSeq: ni.NextMessageSN, Height: d.height}, nil
+}
+
+func (d *chainDestination) merkleRoot(leaf []byte, path []module.MerkleNode) []byte {
+	root := leaf
+	for _, n := range path {
+		if n.Value == nil {
+			continue
+		}
+		if n.Dir == module.DirLeft {
+			root = d.mod.Hash(append(append([]byte{}, n.Value...), root...))
+		} else {
+			root = d.mod.Hash(append(append([]byte{}, root...), n.Value...))
+		}
+	}
+	return root
+}
+
+func (d *chainDestination) verify(bu *testBlockUpdate) error {
+	h := bu.header
+	hashes := make(module.BytesSlice, len(bu.messages))
+	for i, msg := range bu.messages {
+		hashes[i] = d.mod.Hash(msg)
+	}
+	if root := d.mod.MerkleRoot(&hashes); !bytes.Equal(root, h.MessagesRoot()) {
+		return errors.IllegalArgumentError.Errorf("InvalidMessagesRoot(exp=%x,real=%x)",
+			h.MessagesRoot(), root)
+	}
+	nsHash := d.mod.Hash(codec.MustMarshalToBytes([]interface{}{
+		h.NetworkID(), h.UpdateNumber(), h.PrevNetworkSectionHash(),
+		h.MessageCount(), h.MessagesRoot(),
+	}))
+	ntsHash := d.mod.Hash(codec.MustMarshalToBytes([]interface{}{
+		h.NextProofContextHash(), d.merkleRoot(nsHash, h.NetworkSectionToRoot()),
+	}))
+	proof, err := d.pc.NewProofFromBytes(bu.proof)
+	if err != nil {
+		return err
+	}
+	ntsd := d.pc.NewDecision(d.srcUID, 1, h.MainHeight(), h.Round(), ntsHash)
+	if err := d.pc.Verify(ntsd.Hash(), proof); err != nil {
+		return err
+	}
+	if h.NextProofContextChanged() {
+		if d.pc, err = d.mod.NewProofContextFromBytes(h.NextProofContext()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *chainDestination) Relay(msg *RelayMessage) error {
+	bu, err := decodeRelayMessage(msg.Bytes())
+	if err != nil {
+		return err
+	}
+	if bu.header.MainHeight() <= d.height {
+		return errors.IllegalArgumentError.Errorf("InvalidHeight(height=%d,last=%d)",
+			bu.header.MainHeight(), d.height)
+	}
+	st, err := d.GetStatus()
+	if err != nil {
+		return err
+	}
+	if bu.header.MessageCount() > 0 && bu.header.FirstMessageSN() != st.RxSeq {
+		return errors.IllegalArgumentError.Errorf("InvalidSN(exp=%d,real=%d)",
+			st.RxSeq, bu.header.FirstMessageSN())
+	}
+	if err := d.verify(bu); err != nil {
+		return err
+	}
+	if len(bu.messages) > 0 {
+		tx := d.f.NewTx()
+		for _, m := range bu.messages {
+			tx.CallFrom(d.f.CommonAddress(), "sendBTPMessage", map[string]string{
+				"networkId": fmt.Sprintf("0x%x", e2eNetworkID),
+				"message":   fmt.Sprintf("0x%x", m),
+			})
+		}
+		d.f.SendTXToAllAndWaitForResultBlock(tx)
+	}
+	d.height = bu.header.MainHeight()
+	d.rxSeq <- st.RxSeq + int64(len(bu.messages))
+	return nil
+}
+
+// newChainDestination returns the destination initialized with the first
+// BTP block of the network in the source.
+func newChainDestination(t *testing.T, f *test.Fixture, src *test.Fixture) *chainDestination {
+	ni, err := getNetworkInfo(src.Node, e2eNetworkID, 0)
+	assert.NoError(t, err)
+	blk, err := src.BM.GetBlockByHeight(ni.StartHeight + 1)
+	assert.NoError(t, err)
+	h, _, err := src.CS.GetBTPBlockHeaderAndProof(blk, e2eNetworkID, module.FlagBTPBlockHeader)
+	assert.NoError(t, err)
+	mod := ntm.ForUID(e2eNetworkType)
+	pc, err := mod.NewProofContextFromBytes(h.NextProofContext())
+	assert.NoError(t, err)
+	return &chainDestination{
+		f:      f,
+		srcUID: module.GetSourceNetworkUID(src.Chain),
+		mod:    mod,
+		pc:     pc,
+		height: h.MainHeight(),
+		rxSeq:  make(chan int64, 16),
+	}
+}
+
+func TestRelay_BetweenChains(t *testing.T) {
+	src := newBTPChain(t)
+	defer src.Close()
+	dst := newBTPChain(t)
+	defer dst.Close()
+
+	var msgs [][]byte
+	sendMessages := func(n int) {
+		tx := src.NewTx()
+		for i := 0; i < n; i++ {
+			msg := []byte(fmt.Sprintf("message%d", len(msgs)))
+			msgs = append(msgs, msg)
+			tx.CallFrom(src.CommonAddress(), "sendBTPMessage", map[string]string{
+				"networkId": fmt.Sprintf("0x%x", e2eNetworkID),
+				"message":   fmt.Sprintf("0x%x", msg),
+			})
+		}
+		src.SendTXToAllAndWaitForResultBlock(tx)
+	}
+	sendMessages(2)
+
+	d := newChainDestination(t, dst, src)
+	var saved []Cursor
+	r := New(&chainSource{src.Node}, d, &Config{
+		NetworkID:     e2eNetworkID,
+		RetryInterval: time.Millisecond,
+		OnRelay: func(c Cursor) error {
+			saved = append(saved, c)
+			return nil
+		},
+	}, Cursor{}, nil)
+	cancel := make(chan bool)
+	done := make(chan error, 1)
+	go func() {
+		done <- r.Run(cancel)
+	}()
+
+	waitRxSeq := func(rxSeq int64) {
+		timeout := time.After(e2eTimeout)
+		for {
+			select {
+			case seq := <-d.rxSeq:
+				if seq >= rxSeq {
+					return
+				}
+			case err := <-done:
+				assert.FailNow(t, "relay ends", "err=%+v", err)
+			case <-timeout:
+				assert.FailNow(t, "timeout", "rxSeq=%d", rxSeq)
+			}
+		}
+	}
+	waitRxSeq(2)
+	sendMessages(3)
+	waitRxSeq(5)
+	close(cancel)
+	assert.NoError(t, <-done)
+
+	// all messages are delivered in order to the destination chain
+	st, err := d.GetStatus()
+	assert.NoError(t, err)
+	assert.EqualValues(t, len(msgs), st.RxSeq)
+	ni, err := getNetworkInfo(dst.Node, e2eNetworkID, 0)
+	assert.NoError(t, err)
+	last, err := dst.BM.GetLastBlock()
+	assert.NoError(t, err)
+	var received [][]byte
+	for h := ni.StartHeight + 1; h <= last.Height(); h++ {
+		m, err := (&chainSource{dst.Node}).GetMessages(e2eNetworkID, h)
+		assert.NoError(t, err)
+		received = append(received, m...)
+	}
+	assert.Equal(t, msgs, received)
+	assert.Equal(t, Cursor{Height: d.height, NextSN: int64(len(msgs))}, r.Cursor())
+	assert.Equal(t, r.Cursor(), saved[len(saved)-1])
+}
@@ -0,0 +1,400 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package relay delivers BTP blocks of a BTP network in the source chain to
+// the destination chain.
+//
+// The relay follows BTP blocks of the network from the source, and relays
+// the blocks having messages or a new proof context. Each block is relayed
+// as a RelayMessage in the format of the BTP2 BMV for BTP blocks, which has
+// a BlockUpdate with the header and the proof of the block, and a
+// MessageProof with all messages of the block if it has any.
+//
+// Progress of the relay is kept in a Cursor. The destination is the source
+// of truth, so the cursor is reconciled with the status of the destination
+// on start and on every failure.
+package relay
+
+import (
+	"time"
+
+	"github.com/icon-project/goloop/block"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/module"
+)
+
+const (
+	DefaultMaxRetry      = 5
+	DefaultRetryInterval = time.Second
+	maxRetryDelay        = time.Minute
+)
+
+// Types of TypePrefixedMessage
+const (
+	TypeBlockUpdate  = 1
+	TypeMessageProof = 2
+)
+
+// TypePrefixedMessage is an element of RelayMessage. Payload is the bytes
+// of BlockUpdate or MessageProof by Type.
+type TypePrefixedMessage struct {
+	Type    int
+	Payload []byte
+}
+
+// BlockUpdate delivers a BTP block header and the proof for it.
+type BlockUpdate struct {
+	Header []byte
+	Proof  []byte
+}
+
+// ProofNode is a node of MessageProof for the messages not included.
+type ProofNode struct {
+	NumOfLeaf int64
+	Value     []byte
+}
+
+// MessageProof delivers the messages of the last BTP block updated. Left
+// and Right are the proofs for the messages before and after Messages.
+type MessageProof struct {
+	Left     []ProofNode
+	Messages [][]byte
+	Right    []ProofNode
+}
+
+// RelayMessage is the message delivered to the destination for a BTP block.
+// It's encoded as a list of TypePrefixedMessage.
+type RelayMessage struct {
+	Messages []*TypePrefixedMessage
+}
+
+func (m *RelayMessage) RLPEncodeSelf(e codec.Encoder) error {
+	return e.Encode(m.Messages)
+}
+
+func (m *RelayMessage) RLPDecodeSelf(d codec.Decoder) error {
+	return d.Decode(&m.Messages)
+}
+
+// Add appends the object as a TypePrefixedMessage of the type.
+func (m *RelayMessage) Add(t int, obj interface{}) {
+	m.Messages = append(m.Messages, &TypePrefixedMessage{
+		Type:    t,
+		Payload: codec.MustMarshalToBytes(obj),
+	})
+}
+
+func (m *RelayMessage) Bytes() []byte {
+	return codec.MustMarshalToBytes(m)
+}
+
+// NewRelayMessage returns the message for the BTP block. All messages of
+// the block are relayed if there is any.
+func NewRelayMessage(header, proof []byte, messages [][]byte) *RelayMessage {
+	msg := new(RelayMessage)
+	msg.Add(TypeBlockUpdate, &BlockUpdate{Header: header, Proof: proof})
+	if len(messages) > 0 {
+		msg.Add(TypeMessageProof, &MessageProof{
+			Left:     []ProofNode{},
+			Messages: messages,
+			Right:    []ProofNode{},
+		})
+	}
+	return msg
+}
+
+// NetworkInfo is the status of the BTP network in the source.
+type NetworkInfo struct {
+	StartHeight   int64
+	NextMessageSN int64
+}
+
+// Source provides BTP blocks of the source chain.
+type Source interface {
+	// GetNetworkInfo returns the network information at the height. It
+	// returns the latest one if the height is zero.
+	GetNetworkInfo(nid, height int64) (*NetworkInfo, error)
+	GetLastHeight() (int64, error)
+	GetProof(nid, height int64) ([]byte, error)
+	GetMessages(nid, height int64) ([][]byte, error)
+
+	// MonitorBTP calls cb with the header and the proof of each BTP block of
+	// the network from the height until cancel is closed or cb returns an
+	// error. It returns nil only if it's canceled.
+	MonitorBTP(nid, height int64, cb func(header, proof []byte) error, cancel <-chan bool) error
+}
+
+// Status is the status of the link in the destination.
+type Status struct {
+	// RxSeq is the number of messages received from the source.
+	RxSeq int64
+	// Height is the main height of the last BTP block accepted by the
+	// destination. Zero if the destination doesn't know it.
+	Height int64
+}
+
+// Destination accepts RelayMessages.
+type Destination interface {
+	GetStatus() (*Status, error)
+	Relay(msg *RelayMessage) error
+}
+
+// Cursor is the position of the relay.
+type Cursor struct {
+	// Height is the main height of the last handled BTP block.
+	Height int64 `json:"height"`
+	// NextSN is the sequence number of the next message to be relayed.
+	NextSN int64 `json:"nextSN"`
+}
+
+type Config struct {
+	NetworkID     int64
+	MaxRetry      int
+	RetryInterval time.Duration
+
+	// OnRelay is called with the cursor after each relay to save it.
+	OnRelay func(c Cursor) error
+}
+
+type Relay struct {
+	src    Source
+	dst    Destination
+	cfg    Config
+	cursor Cursor
+	log    log.Logger
+
+	cancel <-chan bool
+}
+
+func New(src Source, dst Destination, cfg *Config, cursor Cursor, logger log.Logger) *Relay {
+	r := &Relay{
+		src:    src,
+		dst:    dst,
+		cfg:    *cfg,
+		cursor: cursor,
+		log:    logger,
+	}
+	if r.cfg.MaxRetry <= 0 {
+		r.cfg.MaxRetry = DefaultMaxRetry
+	}
+	if r.cfg.RetryInterval <= 0 {
+		r.cfg.RetryInterval = DefaultRetryInterval
+	}
+	if r.log == nil {
+		r.log = log.GlobalLogger()
+	}
+	return r
+}
+
+func (r *Relay) Cursor() Cursor {
+	return r.cursor
+}
+
+// Run relays BTP blocks until cancel is closed. It returns error if it
+// fails more than MaxRetry times without progress.
+func (r *Relay) Run(cancel <-chan bool) error {
+	r.cancel = cancel
+	var retry int
+	reached := r.cursor.Height
+	for {
+		err := r.resume()
+		if err == nil {
+			r.log.Infof("Monitor BTP nid=%d from=%d nextSN=%d",
+				r.cfg.NetworkID, r.cursor.Height+1, r.cursor.NextSN)
+			err = r.src.MonitorBTP(r.cfg.NetworkID, r.cursor.Height+1, r.handleBlock, cancel)
+			if err == nil {
+				return nil
+			}
+		}
+		if errors.IllegalArgumentError.Equals(err) {
+			return err
+		}
+		if r.cursor.Height > reached {
+			reached = r.cursor.Height
+			retry = 0
+		}
+		if retry >= r.cfg.MaxRetry {
+			return err
+		}
+		r.log.Warnf("Relay failed, retry after %v err=%+v", r.retryDelay(retry), err)
+		if !r.sleep(r.retryDelay(retry)) {
+			return nil
+		}
+		retry++
+	}
+}
+
+func (r *Relay) retryDelay(retry int) time.Duration {
+	d := r.cfg.RetryInterval << uint(retry)
+	if d <= 0 || d > maxRetryDelay {
+		return maxRetryDelay
+	}
+	return d
+}
+
+func (r *Relay) sleep(d time.Duration) bool {
+	select {
+	case <-r.cancel:
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
+
+// resume reconciles the cursor with the status of the destination and
+// the source.
+func (r *Relay) resume() error {
+	ni, err := r.src.GetNetworkInfo(r.cfg.NetworkID, 0)
+	if err != nil {
+		return err
+	}
+	st, err := r.dst.GetStatus()
+	if err != nil {
+		return err
+	}
+	if st.RxSeq > ni.NextMessageSN {
+		return errors.IllegalArgumentError.Errorf(
+			"InvalidLink(rxSeq=%d,nextSN=%d)", st.RxSeq, ni.NextMessageSN)
+	}
+	if st.RxSeq != r.cursor.NextSN {
+		r.log.Infof("Reset cursor height=%d nextSN=%d to height=%d nextSN=%d",
+			r.cursor.Height, r.cursor.NextSN, st.Height, st.RxSeq)
+		r.cursor = Cursor{Height: st.Height, NextSN: st.RxSeq}
+		if st.Height == 0 {
+			if err := r.rewind(); err != nil {
+				return err
+			}
+		}
+	} else if st.Height > r.cursor.Height {
+		r.cursor.Height = st.Height
+	}
+	if r.cursor.Height < ni.StartHeight {
+		r.cursor.Height = ni.StartHeight
+	}
+	return nil
+}
+
+// rewind moves the cursor before the BTP block having the message of
+// NextSN. It finds the lowest height where the next message SN of the
+// network is larger than NextSN.
+func (r *Relay) rewind() error {
+	ni, err := r.src.GetNetworkInfo(r.cfg.NetworkID, 0)
+	if err != nil {
+		return err
+	}
+	last, err := r.src.GetLastHeight()
+	if err != nil {
+		return err
+	}
+	if ni.NextMessageSN <= r.cursor.NextSN {
+		r.cursor.Height = last - 1
+		return nil
+	}
+	low, high := ni.StartHeight, last
+	for low < high {
+		mid := low + (high-low)/2
+		ni, err := r.src.GetNetworkInfo(r.cfg.NetworkID, mid)
+		if err != nil {
+			return err
+		}
+		if ni.NextMessageSN > r.cursor.NextSN {
+			high = mid
+		} else {
+			low = mid + 1
+		}
+	}
+	// The network information of a height reflects the result of the
+	// previous block, so the BTP block may be at the previous height.
+	r.cursor.Height = low - 2
+	return nil
+}
+
+func (r *Relay) handleBlock(hb, proof []byte) error {
+	h, err := block.NewBTPBlockHeaderFromBytes(hb)
+	if err != nil {
+		return errors.IllegalArgumentError.Wrap(err, "InvalidBTPBlockHeader")
+	}
+	if h.NetworkID() != r.cfg.NetworkID {
+		return errors.IllegalArgumentError.Errorf("InvalidNetworkID(exp=%d,real=%d)",
+			r.cfg.NetworkID, h.NetworkID())
+	}
+	if h.MainHeight() <= r.cursor.Height {
+		return nil
+	}
+	first := h.FirstMessageSN()
+	next := first + h.MessageCount()
+	if first > r.cursor.NextSN {
+		r.log.Warnf("Message gap at height=%d first=%d nextSN=%d",
+			h.MainHeight(), first, r.cursor.NextSN)
+		if err := r.rewind(); err != nil {
+			return err
+		}
+		return errors.InvalidStateError.Errorf("MessageGap(height=%d,first=%d,nextSN=%d)",
+			h.MainHeight(), first, r.cursor.NextSN)
+	}
+	if next <= r.cursor.NextSN && !h.NextProofContextChanged() {
+		r.cursor.Height = h.MainHeight()
+		return nil
+	}
+	return r.relayBlock(h, proof)
+}
+
+func (r *Relay) relayBlock(h module.BTPBlockHeader, proof []byte) error {
+	var err error
+	var msgs [][]byte
+	height := h.MainHeight()
+	if len(proof) == 0 {
+		if proof, err = r.src.GetProof(r.cfg.NetworkID, height); err != nil {
+			return err
+		}
+	}
+	if h.MessageCount() > 0 {
+		if msgs, err = r.src.GetMessages(r.cfg.NetworkID, height); err != nil {
+			return err
+		}
+		if int64(len(msgs)) != h.MessageCount() {
+			return errors.InvalidStateError.Errorf("InvalidMessageCount(exp=%d,real=%d)",
+				h.MessageCount(), len(msgs))
+		}
+	}
+	msg := NewRelayMessage(h.HeaderBytes(), proof, msgs)
+	next := h.FirstMessageSN() + h.MessageCount()
+	for retry := 0; ; retry++ {
+		err = r.dst.Relay(msg)
+		if err == nil {
+			break
+		}
+		if st, serr := r.dst.GetStatus(); serr == nil && h.MessageCount() > 0 && st.RxSeq >= next {
+			break
+		}
+		if retry >= r.cfg.MaxRetry {
+			return err
+		}
+		r.log.Warnf("Fail to relay height=%d, retry after %v err=%+v",
+			height, r.retryDelay(retry), err)
+		if !r.sleep(r.retryDelay(retry)) {
+			return err
+		}
+	}
+	r.log.Infof("Relayed height=%d messages=%d nextSN=%d", height, h.MessageCount(), next)
+	r.cursor = Cursor{Height: height, NextSN: next}
+	if r.cfg.OnRelay != nil {
+		return r.cfg.OnRelay(r.cursor)
+	}
+	return nil
+}
@@ -0,0 +1,357 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package relay
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/block"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/module"
+)
+
+const testNID = 1
+
+// testHeaderFormat is same as the format of BTP block header.
+type testHeaderFormat struct {
+	MainHeight             int64
+	Round                  int32
+	NextProofContextHash   []byte
+	NetworkSectionToRoot   []module.MerkleNode
+	NetworkID              int64
+	UpdateNumber           int64
+	PrevNetworkSectionHash []byte
+	MessageCount           int64
+	MessagesRoot           []byte
+	NextProofContext       []byte
+}
+
+type testBlock struct {
+	header   []byte
+	messages [][]byte
+}
+
+type testSource struct {
+	start  int64
+	last   int64
+	blocks map[int64]*testBlock
+	nextSN map[int64]int64
+
+	// heights to be dropped by the monitor once
+	drops map[int64]bool
+}
+
+func newTestSource(start int64) *testSource {
+	return &testSource{
+		start:  start,
+		last:   start,
+		blocks: make(map[int64]*testBlock),
+		nextSN: map[int64]int64{start: 0},
+		drops:  make(map[int64]bool),
+	}
+}
+
+// addBlock adds a BTP block with the messages at the height.
+func (s *testSource) addBlock(height int64, count int, pcChanged bool) {
+	sn := s.nextSN[s.last]
+	for h := s.last + 1; h < height; h++ {
+		s.nextSN[h] = sn
+	}
+	un := sn << 1
+	if pcChanged {
+		un |= 1
+	}
+	hb := codec.MustMarshalToBytes(&testHeaderFormat{
+		MainHeight:   height,
+		NetworkID:    testNID,
+		UpdateNumber: un,
+		MessageCount: int64(count),
+	})
+	b := &testBlock{header: hb}
+	for i := 0; i < count; i++ {
+		b.messages = append(b.messages, []byte(fmt.Sprintf("msg%d", sn+int64(i))))
+	}
+	s.blocks[height] = b
+	s.nextSN[height] = sn + int64(count)
+	s.last = height
+}
+
+func (s *testSource) GetNetworkInfo(nid, height int64) (*NetworkInfo, error) {
+	if height == 0 {
+		height = s.last
+	}
+	sn, ok := s.nextSN[height]
+	if !ok {
+		return nil, errors.NotFoundError.Errorf("NoNetworkInfo(height=%d)", height)
+	}
+	return &NetworkInfo{StartHeight: s.start, NextMessageSN: sn}, nil
+}
+
+func (s *testSource) GetLastHeight() (int64, error) {
+	return s.last, nil
+}
+
+func (s *testSource) GetProof(nid, height int64) ([]byte, error) {
+	if _, ok := s.blocks[height]; !ok {
+		return nil, errors.NotFoundError.Errorf("NoBlock(height=%d)", height)
+	}
+	return []byte(fmt.Sprintf("proof%d", height)), nil
+}
+
+func (s *testSource) GetMessages(nid, height int64) ([][]byte, error) {
+	b, ok := s.blocks[height]
+	if !ok {
+		return nil, errors.NotFoundError.Errorf("NoBlock(height=%d)", height)
+	}
+	return b.messages, nil
+}
+
+// MonitorBTP ends as if it's canceled after the last block.
+func (s *testSource) MonitorBTP(nid, height int64, cb func(header, proof []byte) error, cancel <-chan bool) error {
+	for h := height; h <= s.last; h++ {
+		b, ok := s.blocks[h]
+		if !ok {
+			continue
+		}
+		if s.drops[h] {
+			delete(s.drops, h)
+			continue
+		}
+		if err := cb(b.header, []byte(fmt.Sprintf("proof%d", h))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// testBlockUpdate is the BTP block decoded from a RelayMessage.
+type testBlockUpdate struct {
+	header   module.BTPBlockHeader
+	proof    []byte
+	messages [][]byte
+}
+
+// decodeRelayMessage decodes the message as a BMV does. It expects a
+// BlockUpdate optionally followed by a MessageProof having all messages of
+// the block.
+func decodeRelayMessage(bs []byte) (*testBlockUpdate, error) {
+	var rm RelayMessage
+	if _, err := codec.UnmarshalFromBytes(bs, &rm); err != nil {
+		return nil, err
+	}
+	if len(rm.Messages) < 1 || len(rm.Messages) > 2 {
+		return nil, errors.IllegalArgumentError.Errorf("InvalidMessageCount(%d)", len(rm.Messages))
+	}
+	if rm.Messages[0].Type != TypeBlockUpdate {
+		return nil, errors.IllegalArgumentError.Errorf("InvalidType(%d)", rm.Messages[0].Type)
+	}
+	var bu BlockUpdate
+	if _, err := codec.UnmarshalFromBytes(rm.Messages[0].Payload, &bu); err != nil {
+		return nil, err
+	}
+	h, err := block.NewBTPBlockHeaderFromBytes(bu.Header)
+	if err != nil {
+		return nil, err
+	}
+	res := &testBlockUpdate{header: h, proof: bu.Proof}
+	if len(rm.Messages) == 2 {
+		if rm.Messages[1].Type != TypeMessageProof {
+			return nil, errors.IllegalArgumentError.Errorf("InvalidType(%d)", rm.Messages[1].Type)
+		}
+		var mp MessageProof
+		if _, err := codec.UnmarshalFromBytes(rm.Messages[1].Payload, &mp); err != nil {
+			return nil, err
+		}
+		if len(mp.Left) != 0 || len(mp.Right) != 0 {
+			return nil, errors.IllegalArgumentError.New("PartialMessages")
+		}
+		res.messages = mp.Messages
+	}
+	if int64(len(res.messages)) != h.MessageCount() {
+		return nil, errors.IllegalArgumentError.Errorf("InvalidMessageCount(exp=%d,real=%d)",
+			h.MessageCount(), len(res.messages))
+	}
+	return res, nil
+}
+
+type testDestination struct {
+	status  Status
+	relayed []*testBlockUpdate
+	fails   int
+}
+
+func (d *testDestination) GetStatus() (*Status, error) {
+	st := d.status
+	return &st, nil
+}
+
+func (d *testDestination) Relay(msg *RelayMessage) error {
+	if d.fails > 0 {
+		d.fails--
+		return errors.ExecutionFailError.New("TestFailure")
+	}
+	bu, err := decodeRelayMessage(msg.Bytes())
+	if err != nil {
+		return err
+	}
+	h := bu.header
+	if h.MessageCount() > 0 && h.FirstMessageSN() != d.status.RxSeq {
+		return errors.IllegalArgumentError.Errorf("InvalidSN(exp=%d,real=%d)",
+			d.status.RxSeq, h.FirstMessageSN())
+	}
+	if string(bu.proof) != fmt.Sprintf("proof%d", h.MainHeight()) {
+		return errors.IllegalArgumentError.Errorf("InvalidProof(%s)", bu.proof)
+	}
+	d.status.RxSeq += int64(len(bu.messages))
+	d.status.Height = h.MainHeight()
+	d.relayed = append(d.relayed, bu)
+	return nil
+}
+
+func (d *testDestination) heights() []int64 {
+	var heights []int64
+	for _, bu := range d.relayed {
+		heights = append(heights, bu.header.MainHeight())
+	}
+	return heights
+}
+
+func newTestRelay(src Source, dst Destination, cursor Cursor, saved *[]Cursor) *Relay {
+	return New(src, dst, &Config{
+		NetworkID:     testNID,
+		MaxRetry:      2,
+		RetryInterval: time.Millisecond,
+		OnRelay: func(c Cursor) error {
+			*saved = append(*saved, c)
+			return nil
+		},
+	}, cursor, nil)
+}
+
+func newTestSourceWithBlocks() *testSource {
+	src := newTestSource(10)
+	src.addBlock(11, 0, true)
+	src.addBlock(12, 2, false)
+	src.addBlock(13, 0, false)
+	src.addBlock(15, 3, false)
+	return src
+}
+
+func TestRelay_Run(t *testing.T) {
+	src := newTestSourceWithBlocks()
+	dst := &testDestination{}
+	var saved []Cursor
+	r := newTestRelay(src, dst, Cursor{}, &saved)
+
+	assert.NoError(t, r.Run(make(chan bool)))
+	assert.Equal(t, []int64{11, 12, 15}, dst.heights())
+	assert.Equal(t, [][]byte{[]byte("msg0"), []byte("msg1")}, dst.relayed[1].messages)
+	assert.Equal(t, Cursor{Height: 15, NextSN: 5}, r.Cursor())
+	assert.Equal(t, []Cursor{{11, 0}, {12, 2}, {15, 5}}, saved)
+	assert.EqualValues(t, 5, dst.status.RxSeq)
+}
+
+func TestRelay_Resume(t *testing.T) {
+	src := newTestSourceWithBlocks()
+
+	// from the cursor
+	dst := &testDestination{status: Status{RxSeq: 2}}
+	var saved []Cursor
+	r := newTestRelay(src, dst, Cursor{Height: 12, NextSN: 2}, &saved)
+	assert.NoError(t, r.Run(make(chan bool)))
+	assert.Equal(t, []int64{15}, dst.heights())
+
+	// without the cursor, it finds the height of the next message
+	dst = &testDestination{status: Status{RxSeq: 2}}
+	saved = nil
+	r = newTestRelay(src, dst, Cursor{}, &saved)
+	assert.NoError(t, r.Run(make(chan bool)))
+	assert.Equal(t, []int64{15}, dst.heights())
+	assert.Equal(t, []Cursor{{15, 5}}, saved)
+
+	// the destination is ahead of the cursor
+	dst = &testDestination{status: Status{RxSeq: 2, Height: 12}}
+	saved = nil
+	r = newTestRelay(src, dst, Cursor{Height: 11, NextSN: 0}, &saved)
+	assert.NoError(t, r.Run(make(chan bool)))
+	assert.Equal(t, []int64{15}, dst.heights())
+
+	// the destination is up to date
+	dst = &testDestination{status: Status{RxSeq: 5, Height: 15}}
+	saved = nil
+	r = newTestRelay(src, dst, Cursor{}, &saved)
+	assert.NoError(t, r.Run(make(chan bool)))
+	assert.Empty(t, dst.relayed)
+}
+
+func TestRelay_Gap(t *testing.T) {
+	src := newTestSourceWithBlocks()
+	src.drops[12] = true
+	dst := &testDestination{}
+	var saved []Cursor
+	r := newTestRelay(src, dst, Cursor{}, &saved)
+
+	assert.NoError(t, r.Run(make(chan bool)))
+	assert.Equal(t, []int64{11, 12, 15}, dst.heights())
+	assert.Equal(t, Cursor{Height: 15, NextSN: 5}, r.Cursor())
+}
+
+func TestRelay_Retry(t *testing.T) {
+	src := newTestSourceWithBlocks()
+	dst := &testDestination{fails: 2}
+	var saved []Cursor
+	r := newTestRelay(src, dst, Cursor{}, &saved)
+	assert.NoError(t, r.Run(make(chan bool)))
+	assert.Equal(t, []int64{11, 12, 15}, dst.heights())
+
+	dst = &testDestination{fails: 100}
+	saved = nil
+	r = newTestRelay(src, dst, Cursor{}, &saved)
+	assert.True(t, errors.ExecutionFailError.Equals(r.Run(make(chan bool))))
+	assert.Empty(t, saved)
+}
+
+func TestRelay_InvalidLink(t *testing.T) {
+	src := newTestSourceWithBlocks()
+	dst := &testDestination{status: Status{RxSeq: 6}}
+	var saved []Cursor
+	r := newTestRelay(src, dst, Cursor{}, &saved)
+	assert.True(t, errors.IllegalArgumentError.Equals(r.Run(make(chan bool))))
+}
+
+func TestRelayMessage_Format(t *testing.T) {
+	header, proof := []byte("header"), []byte("proof")
+	msgs := [][]byte{[]byte("msg0"), []byte("msg1")}
+	bu := codec.MustMarshalToBytes([]interface{}{header, proof})
+	mp := codec.MustMarshalToBytes([]interface{}{[]interface{}{}, msgs, []interface{}{}})
+
+	msg := NewRelayMessage(header, proof, msgs)
+	assert.Equal(t, codec.MustMarshalToBytes([]interface{}{
+		[]interface{}{TypeBlockUpdate, bu},
+		[]interface{}{TypeMessageProof, mp},
+	}), msg.Bytes())
+
+	msg = NewRelayMessage(header, proof, nil)
+	assert.Equal(t, codec.MustMarshalToBytes([]interface{}{
+		[]interface{}{TypeBlockUpdate, bu},
+	}), msg.Bytes())
+}
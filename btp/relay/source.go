@@ -0,0 +1,138 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package relay
+
+import (
+	"encoding/base64"
+
+	"github.com/icon-project/goloop/client"
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/intconv"
+	"github.com/icon-project/goloop/server"
+	"github.com/icon-project/goloop/server/jsonrpc"
+	v3 "github.com/icon-project/goloop/server/v3"
+)
+
+type clientSource struct {
+	c *client.ClientV3
+}
+
+func (s *clientSource) GetNetworkInfo(nid, height int64) (*NetworkInfo, error) {
+	param := &v3.BTPQueryParam{
+		Id: jsonrpc.HexInt(intconv.FormatInt(nid)),
+	}
+	if height > 0 {
+		param.Height = jsonrpc.HexInt(intconv.FormatInt(height))
+	}
+	ni, err := s.c.GetBTPNetworkInfo(param)
+	if err != nil {
+		return nil, err
+	}
+	startHeight, err := ni.StartHeight.Int64()
+	if err != nil {
+		return nil, err
+	}
+	nextSN, err := ni.NextMessageSN.Int64()
+	if err != nil {
+		return nil, err
+	}
+	return &NetworkInfo{
+		StartHeight:   startHeight,
+		NextMessageSN: nextSN,
+	}, nil
+}
+
+func (s *clientSource) GetLastHeight() (int64, error) {
+	blk, err := s.c.GetLastBlock()
+	if err != nil {
+		return 0, err
+	}
+	return blk.Height, nil
+}
+
+func messagesParam(nid, height int64) *v3.BTPMessagesParam {
+	return &v3.BTPMessagesParam{
+		Height:    jsonrpc.HexInt(intconv.FormatInt(height)),
+		NetworkId: jsonrpc.HexInt(intconv.FormatInt(nid)),
+	}
+}
+
+func (s *clientSource) GetProof(nid, height int64) ([]byte, error) {
+	proof, err := s.c.GetBTPProof(messagesParam(nid, height))
+	if err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(proof)
+}
+
+func (s *clientSource) GetMessages(nid, height int64) ([][]byte, error) {
+	msgs, err := s.c.GetBTPMessages(messagesParam(nid, height))
+	if err != nil {
+		return nil, err
+	}
+	res := make([][]byte, len(msgs))
+	for i, msg := range msgs {
+		if res[i], err = base64.StdEncoding.DecodeString(msg); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func (s *clientSource) MonitorBTP(nid, height int64, cb func(header, proof []byte) error, cancel <-chan bool) error {
+	stop := make(chan bool)
+	defer close(stop)
+	ch := make(chan interface{})
+	req := &server.BTPRequest{
+		Height:    common.HexInt64{Value: height},
+		NetworkId: common.HexInt64{Value: nid},
+		ProofFlag: true,
+	}
+	err := s.c.Monitor("/btp", req, &server.BTPNotification{}, func(v interface{}) {
+		select {
+		case ch <- v:
+		case <-stop:
+		}
+	}, stop)
+	if err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-cancel:
+			return nil
+		case v := <-ch:
+			switch v := v.(type) {
+			case *server.BTPNotification:
+				proof, err := base64.StdEncoding.DecodeString(v.Proof)
+				if err != nil {
+					return err
+				}
+				if err := cb(v.Header, proof); err != nil {
+					return err
+				}
+			case error:
+				return v
+			}
+		}
+	}
+}
+
+// NewClientSource returns the source using JSON-RPC APIs of the node.
+func NewClientSource(c *client.ClientV3) Source {
+	return &clientSource{c}
+}
@@ -0,0 +1,292 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package chain
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"path"
+	"sort"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/errors"
+)
+
+const (
+	BackupManifestFile = "backup.manifest"
+	maxBackupSequence  = 1024
+)
+
+// BackupEntry is a file of the chain. Entries of backups made without the
+// manifest have no digest, so they are never shared by other backups.
+type BackupEntry struct {
+	Size   int64           `json:"size"`
+	SHA256 common.HexBytes `json:"sha256,omitempty"`
+}
+
+func (e *BackupEntry) Equal(e2 *BackupEntry) bool {
+	if e == nil || e2 == nil {
+		return e == e2
+	}
+	return e.Size == e2.Size && len(e.SHA256) > 0 &&
+		bytes.Equal(e.SHA256, e2.SHA256)
+}
+
+// BackupManifest has all files of the chain at the backup. Files of
+// incremental backups are stored in the backup or its bases.
+type BackupManifest struct {
+	Files map[string]*BackupEntry `json:"files"`
+}
+
+func (m *BackupManifest) entryOf(name string) *BackupEntry {
+	if m == nil {
+		return nil
+	}
+	return m.Files[name]
+}
+
+func newBackupManifest() *BackupManifest {
+	return &BackupManifest{Files: make(map[string]*BackupEntry)}
+}
+
+func writeBackupManifest(zw *zip.Writer, m *BackupManifest) error {
+	bs, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	w, err := zw.Create(BackupManifestFile)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(bs)
+	return err
+}
+
+// ReadBackupManifest returns the manifest of the backup. Backups made
+// without the manifest are full backups, so it's built with the entries.
+func ReadBackupManifest(zr *zip.Reader) (*BackupManifest, error) {
+	m := newBackupManifest()
+	for _, f := range zr.File {
+		if f.Name == BackupManifestFile {
+			rc, err := f.Open()
+			if err != nil {
+				return nil, err
+			}
+			defer rc.Close()
+			bs, err := ioutil.ReadAll(rc)
+			if err != nil {
+				return nil, err
+			}
+			m = newBackupManifest()
+			if err := json.Unmarshal(bs, m); err != nil {
+				return nil, errors.IllegalArgumentError.Wrap(err, "InvalidManifest")
+			}
+			return m, nil
+		}
+		if f.Mode().IsRegular() {
+			m.Files[f.Name] = &BackupEntry{
+				Size: int64(f.UncompressedSize64),
+			}
+		}
+	}
+	return m, nil
+}
+
+// BackupFile is an opened backup file.
+type BackupFile struct {
+	Name     string
+	Info     *BackupInfo
+	Manifest *BackupManifest
+
+	zr    *zip.ReadCloser
+	files map[string]*zip.File
+}
+
+func (b *BackupFile) fileOf(name string, e *BackupEntry) *zip.File {
+	if f, ok := b.files[name]; ok {
+		if be := b.Manifest.entryOf(name); be == e || be.Equal(e) {
+			return f
+		}
+	}
+	return nil
+}
+
+func (b *BackupFile) Close() error {
+	return b.zr.Close()
+}
+
+func OpenBackup(file string) (ret *BackupFile, err error) {
+	zr, err := zip.OpenReader(file)
+	if err != nil {
+		return nil, errors.IllegalArgumentError.Wrapf(err,
+			"ZipOpenFailure(backup=%s)", file)
+	}
+	defer func() {
+		if err != nil {
+			zr.Close()
+		}
+	}()
+	info, err := ReadBackupInfo(&zr.Reader)
+	if err != nil {
+		return nil, errors.IllegalArgumentError.Wrap(err, "InvalidBackupInfo")
+	}
+	manifest, err := ReadBackupManifest(&zr.Reader)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]*zip.File)
+	for _, f := range zr.File {
+		if f.Name != BackupManifestFile {
+			files[f.Name] = f
+		}
+	}
+	return &BackupFile{
+		Name:     path.Base(file),
+		Info:     info,
+		Manifest: manifest,
+		zr:       zr,
+		files:    files,
+	}, nil
+}
+
+// BackupSequence is a full backup followed by incremental backups. The
+// last one is the backup to be restored.
+type BackupSequence []*BackupFile
+
+// OpenBackupSequence opens the backup and its bases in the directory.
+func OpenBackupSequence(dir, name string) (ret BackupSequence, err error) {
+	var seq BackupSequence
+	defer func() {
+		if err != nil {
+			seq.Close()
+		}
+	}()
+	for {
+		if len(seq) >= maxBackupSequence {
+			return nil, errors.IllegalArgumentError.Errorf(
+				"TooLongBackupSequence(backup=%s)", name)
+		}
+		bf, err := OpenBackup(path.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		seq = append(BackupSequence{bf}, seq...)
+		if len(seq) > 1 {
+			if err := verifyBackupBase(seq[1].Info, bf.Info); err != nil {
+				return nil, err
+			}
+		}
+		if bf.Info.Base == nil {
+			return seq, nil
+		}
+		name = bf.Info.Base.Name
+		if path.Base(name) != name {
+			return nil, errors.IllegalArgumentError.Errorf(
+				"InvalidBaseName(base=%s)", name)
+		}
+	}
+}
+
+func verifyBackupBase(info, base *BackupInfo) error {
+	if info.NID != base.NID || info.CID != base.CID ||
+		info.Channel != base.Channel || info.Codec != base.Codec {
+		return errors.IllegalArgumentError.Errorf(
+			"IncompatibleBase(base=%s)", info.Base.Name)
+	}
+	if info.Base.Height != base.Height ||
+		!bytes.Equal(info.Base.Hash, base.Hash) {
+		return errors.IllegalArgumentError.Errorf(
+			"InvalidBase(base=%s,height=%d,exp=%d)",
+			info.Base.Name, base.Height, info.Base.Height)
+	}
+	return nil
+}
+
+func (s BackupSequence) Last() *BackupFile {
+	return s[len(s)-1]
+}
+
+// Files returns the zip entries of all files in the last backup. Each
+// file is from the latest backup having it.
+func (s BackupSequence) Files() ([]*zip.File, error) {
+	manifest := s.Last().Manifest
+	names := make([]string, 0, len(manifest.Files))
+	for name := range manifest.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var files []*zip.File
+	for _, name := range names {
+		e := manifest.Files[name]
+		var file *zip.File
+		for i := len(s) - 1; i >= 0 && file == nil; i-- {
+			file = s[i].fileOf(name, e)
+		}
+		if file == nil {
+			return nil, errors.NotFoundError.Errorf("MissingFile(name=%s)", name)
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// Verify checks whether all files in the last backup are available with
+// valid digests. on is called with index of each verified file.
+func (s BackupSequence) Verify(on func(idx int) error) error {
+	files, err := s.Files()
+	if err != nil {
+		return err
+	}
+	manifest := s.Last().Manifest
+	for idx, f := range files {
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		// zip reader checks CRC32 on EOF
+		h := sha256.New()
+		size, err := io.Copy(h, rc)
+		rc.Close()
+		if err != nil {
+			return errors.IllegalArgumentError.Wrapf(err,
+				"InvalidFile(name=%s)", f.Name)
+		}
+		if e := manifest.entryOf(f.Name); len(e.SHA256) > 0 {
+			if size != e.Size || !bytes.Equal(h.Sum(nil), e.SHA256) {
+				return errors.IllegalArgumentError.Errorf(
+					"InvalidDigest(name=%s)", f.Name)
+			}
+		}
+		if on != nil {
+			if err := on(idx); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s BackupSequence) Close() {
+	for _, bf := range s {
+		bf.Close()
+	}
+}
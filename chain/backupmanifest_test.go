@@ -0,0 +1,155 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package chain
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+)
+
+func writeTestBackup(t *testing.T, dir, name string, info *BackupInfo,
+	files map[string]string, stored map[string]string) {
+	fd, err := os.Create(path.Join(dir, name))
+	assert.NoError(t, err)
+	defer fd.Close()
+
+	zw := zip.NewWriter(fd)
+	assert.NoError(t, writeBackupInfo(zw, info))
+	m := newBackupManifest()
+	for n, v := range files {
+		digest := sha256.Sum256([]byte(v))
+		m.Files[n] = &BackupEntry{
+			Size:   int64(len(v)),
+			SHA256: digest[:],
+		}
+	}
+	for n, v := range stored {
+		w, err := zw.Create(n)
+		assert.NoError(t, err)
+		_, err = w.Write([]byte(v))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, writeBackupManifest(zw, m))
+	assert.NoError(t, zw.Close())
+}
+
+func readZipFile(t *testing.T, f *zip.File) string {
+	rc, err := f.Open()
+	assert.NoError(t, err)
+	defer rc.Close()
+	bs, err := ioutil.ReadAll(rc)
+	assert.NoError(t, err)
+	return string(bs)
+}
+
+func TestBackupSequence_Files(t *testing.T) {
+	dir, err := ioutil.TempDir("", "backup")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	full := &BackupInfo{
+		CID:     common.HexInt32{Value: 1},
+		NID:     common.HexInt32{Value: 1},
+		Channel: "test",
+		Height:  10,
+		Hash:    []byte{0x10},
+		Codec:   "rlp",
+	}
+	files1 := map[string]string{"a": "a1", "b": "b1"}
+	writeTestBackup(t, dir, "full.zip", full, files1, files1)
+
+	inc := *full
+	inc.Height = 20
+	inc.Hash = []byte{0x20}
+	inc.Base = &BackupBase{Name: "full.zip", Height: 10, Hash: []byte{0x10}}
+	files2 := map[string]string{"a": "a1", "b": "b2", "c": "c2"}
+	writeTestBackup(t, dir, "inc.zip", &inc, files2,
+		map[string]string{"b": "b2", "c": "c2"})
+
+	seq, err := OpenBackupSequence(dir, "inc.zip")
+	assert.NoError(t, err)
+	defer seq.Close()
+	assert.Len(t, seq, 2)
+	assert.Equal(t, int64(20), seq.Last().Info.Height)
+
+	files, err := seq.Files()
+	assert.NoError(t, err)
+	assert.Len(t, files, 3)
+	for _, f := range files {
+		assert.Equal(t, files2[f.Name], readZipFile(t, f))
+	}
+	assert.NoError(t, seq.Verify(nil))
+
+	// base with other blocks
+	bad := inc
+	bad.Base = &BackupBase{Name: "full.zip", Height: 10, Hash: []byte{0x11}}
+	writeTestBackup(t, dir, "bad.zip", &bad, files2,
+		map[string]string{"b": "b2", "c": "c2"})
+	_, err = OpenBackupSequence(dir, "bad.zip")
+	assert.Error(t, err)
+
+	// file missing in the base
+	missing := inc
+	writeTestBackup(t, dir, "missing.zip", &missing, files2,
+		map[string]string{"c": "c2"})
+	seq2, err := OpenBackupSequence(dir, "missing.zip")
+	assert.NoError(t, err)
+	defer seq2.Close()
+	_, err = seq2.Files()
+	assert.Error(t, err)
+
+	// file changed in the base with the same size
+	changed := inc
+	files3 := map[string]string{"a": "a3", "b": "b2", "c": "c2"}
+	writeTestBackup(t, dir, "changed.zip", &changed, files3,
+		map[string]string{"b": "b2", "c": "c2"})
+	seq3, err := OpenBackupSequence(dir, "changed.zip")
+	assert.NoError(t, err)
+	defer seq3.Close()
+	_, err = seq3.Files()
+	assert.Error(t, err)
+
+	// stored file doesn't match the digest
+	corrupted := inc
+	writeTestBackup(t, dir, "corrupted.zip", &corrupted, files2,
+		map[string]string{"b": "b3", "c": "c2"})
+	seq4, err := OpenBackupSequence(dir, "corrupted.zip")
+	assert.NoError(t, err)
+	defer seq4.Close()
+	assert.Error(t, seq4.Verify(nil))
+}
+
+func TestBackupEntry_Equal(t *testing.T) {
+	d1, d2 := sha256.Sum256([]byte("a1")), sha256.Sum256([]byte("a2"))
+	e := &BackupEntry{Size: 2, SHA256: d1[:]}
+	assert.True(t, e.Equal(&BackupEntry{Size: 2, SHA256: d1[:]}))
+	assert.False(t, e.Equal(&BackupEntry{Size: 2, SHA256: d2[:]}))
+	assert.False(t, e.Equal(&BackupEntry{Size: 3, SHA256: d1[:]}))
+	assert.False(t, e.Equal(nil))
+
+	// entries without digest are never equal
+	e = &BackupEntry{Size: 2}
+	assert.False(t, e.Equal(&BackupEntry{Size: 2}))
+}
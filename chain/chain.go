@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/icon-project/goloop/block"
+	"github.com/icon-project/goloop/btp/ntm"
 	"github.com/icon-project/goloop/chain/base"
 	"github.com/icon-project/goloop/chain/gs"
 	"github.com/icon-project/goloop/common/db"
@@ -75,10 +76,15 @@ type chainTask interface {
 }
 
 type singleChain struct {
-	wallet module.Wallet
+	wallet    module.Wallet
+	blsWallet module.BaseWallet
+	blsOnce   sync.Once
 
 	dbLock   sync.RWMutex
 	database db.Database
+	pdb      *prunableDatabase
+	index    *service.AddressIndex
+	pruner   *statePruner
 	vld      module.CommitVoteSetDecoder
 	pd       module.PatchDecoder
 	sm       module.ServiceManager
@@ -113,6 +119,8 @@ const (
 	DefaultContractDir = "contract"
 	DefaultCacheDir    = "cache"
 	DefaultTmpDBDir    = "tmp"
+	DefaultPruningDir  = "pruning"
+	DefaultGenesisFile = "genesis.zip"
 )
 
 func (c *singleChain) Database() db.Database {
@@ -250,6 +258,29 @@ func (c *singleChain) ValidateTxOnSend() bool {
 	return c.cfg.ValidateTxOnSend
 }
 
+// AddressIndex returns the index shared by the service manager updating it
+// and the handlers querying it.
+func (c *singleChain) AddressIndex() module.AddressIndex {
+	if !c.cfg.AddressIndex || c.index == nil {
+		return nil
+	}
+	return c.index
+}
+
+func (c *singleChain) MaxLogsRange() int64 {
+	if c.cfg.MaxLogsRange > 0 {
+		return c.cfg.MaxLogsRange
+	}
+	return ConfigDefaultMaxLogsRange
+}
+
+func (c *singleChain) MaxLogsResults() int {
+	if c.cfg.MaxLogsResults > 0 {
+		return c.cfg.MaxLogsResults
+	}
+	return ConfigDefaultMaxLogsResults
+}
+
 func (c *singleChain) State() (string, int64, error) {
 	c.mtx.RLock()
 	defer c.mtx.RUnlock()
@@ -408,8 +439,27 @@ func (c *singleChain) prepareDatabase(chainDir string) error {
 		_ = cdb.Close()
 		return errors.Wrapf(err, "UnknownCacheStrategy(%s)", c.cfg.NodeCache)
 	}
+	history, terms, err := ParseHistoryRetention(c.cfg.HistoryRetention)
+	if err != nil {
+		_ = cdb.Close()
+		return err
+	}
+	if c.cfg.StateRetention > 0 || history > 0 || terms > 0 {
+		pdb, err := newPrunableDatabase(cdb)
+		if err != nil {
+			_ = cdb.Close()
+			return err
+		}
+		c.pdb, cdb = pdb, pdb
+	}
 	cacheDir := path.Join(chainDir, DefaultCacheDir)
 	c.database = cache.AttachManager(cdb, cacheDir, mLevel, fLevel, stores)
+	if c.index, err = service.NewAddressIndex(c.database); err != nil {
+		c.database.Close()
+		c.database = nil
+		c.pdb = nil
+		return err
+	}
 	return nil
 }
 
@@ -419,6 +469,34 @@ func (c *singleChain) releaseDatabase() {
 	if c.database != nil {
 		c.database.Close()
 		c.database = nil
+		c.pdb = nil
+		c.index = nil
+	}
+}
+
+func (c *singleChain) startPruner() {
+	if c.pdb == nil {
+		return
+	}
+	retention := c.cfg.StateRetention
+	if retention > 0 && retention < ConfigMinStateRetention {
+		retention = ConfigMinStateRetention
+	}
+	history, terms, _ := ParseHistoryRetention(c.cfg.HistoryRetention)
+	if history > 0 && history < ConfigMinHistoryRetention {
+		history = ConfigMinHistoryRetention
+	}
+	c.pruner = newStatePruner(c.pdb, c.bm, c.plt,
+		path.Join(c.cfg.AbsBaseDir(), DefaultPruningDir), c.cfg.DBType,
+		c.cfg.GenesisStorage.Height(), retention, history, terms,
+		c.logger, metric.NewPruningMetric(c.metricCtx))
+	c.pruner.Start()
+}
+
+func (c *singleChain) stopPruner() {
+	if c.pruner != nil {
+		c.pruner.Stop()
+		c.pruner = nil
 	}
 }
 
@@ -590,8 +668,8 @@ func (c *singleChain) Prune(gsfile string, dbtype string, height int64) error {
 	return c._runTask(task, false)
 }
 
-func (c *singleChain) Backup(file string, extra []string) error {
-	task := newTaskBackup(c, file, extra)
+func (c *singleChain) Backup(file string, extra []string, base string) error {
+	task := newTaskBackup(c, file, extra, base)
 	return c._runTask(task, false)
 }
 
@@ -660,8 +738,7 @@ func (c *singleChain) Verify() error {
 func (c *singleChain) Reset(gs string, height int64, blockHash []byte) error {
 	if len(gs) == 0 {
 		chainDir := c.cfg.AbsBaseDir()
-		const chainGenesisZipFileName = "genesis.zip"
-		gs = path.Join(chainDir, chainGenesisZipFileName)
+		gs = path.Join(chainDir, DefaultGenesisFile)
 	}
 	task := newTaskReset(c, gs, height, blockHash)
 	return c._runTask(task, false)
@@ -692,6 +769,15 @@ func (c *singleChain) WalletFor(dsa string) module.BaseWallet {
 	switch dsa {
 	case "ecdsa/secp256k1":
 		return c.wallet
+	case "bls12-381":
+		c.blsOnce.Do(func() {
+			if w, err := ntm.NewBLSWallet(c.wallet); err != nil {
+				c.logger.Warnf("Fail to make BLS wallet err=%+v", err)
+			} else {
+				c.blsWallet = w
+			}
+		})
+		return c.blsWallet
 	}
 	return nil
 }
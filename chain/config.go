@@ -6,6 +6,7 @@ import (
 	"path"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/icon-project/goloop/common/crypto"
@@ -20,6 +21,10 @@ const (
 	ConfigDefaultTxTimeout        = 5000 * time.Millisecond
 	ConfigDefaultChildrenLimit    = 10
 	ConfigDefaultNephewLimit      = 10
+	ConfigDefaultMaxLogsRange     = 1000
+	ConfigDefaultMaxLogsResults   = 1000
+	ConfigMinStateRetention       = 10
+	ConfigMinHistoryRetention     = 100
 )
 
 const (
@@ -29,6 +34,11 @@ const (
 	NodeCacheDefault = NodeCacheNone
 )
 
+const (
+	HistoryRetentionArchive = "archive"
+	historyRetentionTerms   = "terms"
+)
+
 type Config struct {
 	// fixed
 	NID    int    `json:"nid"`
@@ -48,6 +58,11 @@ type Config struct {
 	ChildrenLimit    *int   `json:"children_limit,omitempty"`
 	NephewsLimit     *int   `json:"nephews_limit,omitempty"`
 	ValidateTxOnSend bool   `json:"validate_tx_on_send,omitempty"`
+	AddressIndex     bool   `json:"address_index,omitempty"`
+	MaxLogsRange     int64  `json:"max_logs_range,omitempty"`
+	MaxLogsResults   int    `json:"max_logs_results,omitempty"`
+	StateRetention   int64  `json:"state_retention,omitempty"`
+	HistoryRetention string `json:"history_retention,omitempty"`
 
 	// runtime
 	Channel        string `json:"channel"`
@@ -156,3 +171,28 @@ func ParseNodeCacheOption(s string) (int, int, int, error) {
 			"InvalidCacheStrategy(%q)", s)
 	}
 }
+
+func IsHistoryRetention(s string) bool {
+	_, _, err := ParseHistoryRetention(s)
+	return err == nil
+}
+
+// ParseHistoryRetention returns the number of recent blocks and the number
+// of recent terms whose history is retained. Both are zero for the archive
+// mode keeping all history. Allowed values are "archive" (or empty), "<N>"
+// for the last N blocks and "<N>terms" for the last N terms.
+func ParseHistoryRetention(s string) (int64, int64, error) {
+	if s == "" || s == HistoryRetentionArchive {
+		return 0, 0, nil
+	}
+	value := strings.TrimSuffix(s, historyRetentionTerms)
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, 0, errors.IllegalArgumentError.Errorf(
+			"InvalidHistoryRetention(%q)", s)
+	}
+	if value != s {
+		return 0, n, nil
+	}
+	return n, 0, nil
+}
@@ -0,0 +1,127 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package dbtool inspects and repairs the database of a stopped chain.
+package dbtool
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/icon-project/goloop/block"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+)
+
+// Open opens the chain database at the path. The path is the directory of
+// the database, like CHAIN_DIR/db/NID. If readOnly is true, then writing to
+// the database fails.
+func Open(path string, dbtype string, readOnly bool) (db.Database, error) {
+	if st, err := os.Stat(path); err != nil || !st.IsDir() {
+		return nil, errors.IllegalArgumentError.Errorf(
+			"InvalidDatabasePath(path=%s)", path)
+	}
+	dir, name := filepath.Split(filepath.Clean(path))
+	if readOnly {
+		return db.OpenReadOnly(dir, dbtype, name)
+	}
+	return db.Open(dir, dbtype, name)
+}
+
+// KnownBuckets are buckets used by the chain.
+var KnownBuckets = []db.BucketID{
+	db.MerkleTrie,
+	db.BytesByHash,
+	db.TransactionLocatorByHash,
+	db.BlockHeaderHashByHeight,
+	db.ChainProperty,
+	db.TransactionsByAddress,
+	db.ListByMerkleRootBase,
+}
+
+var bucketNames = map[db.BucketID]string{
+	db.MerkleTrie:               "MerkleTrie",
+	db.BytesByHash:              "BytesByHash",
+	db.TransactionLocatorByHash: "TransactionLocatorByHash",
+	db.BlockHeaderHashByHeight:  "BlockHeaderHashByHeight",
+	db.ChainProperty:            "ChainProperty",
+	db.TransactionsByAddress:    "TransactionsByAddress",
+	db.ListByMerkleRootBase:     "ListByMerkleRootBase",
+}
+
+// BucketName returns the name of the bucket. It returns empty string for
+// unknown buckets.
+func BucketName(id db.BucketID) string {
+	return bucketNames[id]
+}
+
+type BucketStat struct {
+	ID    db.BucketID
+	Keys  int64
+	Bytes int64
+}
+
+const hashSize = 32
+
+// CountBucket counts keys and bytes of the entries in the bucket. Some
+// backends share one key space for all buckets, so only entries with keys
+// of hash size are counted for the bucket with the hasher.
+func CountBucket(dbase db.Database, id db.BucketID) (*BucketStat, error) {
+	bk, err := dbase.GetBucket(id)
+	if err != nil {
+		return nil, err
+	}
+	it, err := db.NewIterator(bk, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Release()
+
+	hashed := id.Hasher() != nil
+	stat := &BucketStat{ID: id}
+	for it.Next() {
+		if hashed && len(it.Key()) != hashSize {
+			continue
+		}
+		stat.Keys += 1
+		stat.Bytes += int64(len(it.Key()) + len(it.Value()))
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	return stat, nil
+}
+
+// LastHeight returns the height of the last finalized block.
+func LastHeight(dbase db.Database) (int64, error) {
+	return block.GetLastHeight(dbase)
+}
+
+// LowestHeight returns the lowest height of the blocks whose bodies and
+// results are retained by the history retention.
+func LowestHeight(dbase db.Database) (int64, error) {
+	return block.GetLowestHeight(dbase)
+}
+
+// BlockResult returns the result of the block at the height, which is the
+// result of the execution of the transactions in the previous block.
+func BlockResult(dbase db.Database, height int64) ([]byte, error) {
+	h, err := readHeader(dbase, height)
+	if err != nil {
+		return nil, err
+	}
+	return h.format.Result, nil
+}
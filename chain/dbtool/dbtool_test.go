@@ -0,0 +1,174 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dbtool
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/block"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/platform/basic"
+	"github.com/icon-project/goloop/service/state"
+)
+
+func copyDatabase(t *testing.T, src db.Database) db.Database {
+	dst := db.NewMapDB()
+	for _, id := range KnownBuckets {
+		sbk, err := src.GetBucket(id)
+		assert.NoError(t, err)
+		dbk, err := dst.GetBucket(id)
+		assert.NoError(t, err)
+		it, err := db.NewIterator(sbk, nil, nil, nil)
+		assert.NoError(t, err)
+		for it.Next() {
+			assert.NoError(t, dbk.Set(it.Key(), it.Value()))
+		}
+		it.Release()
+	}
+	return dst
+}
+
+func newTestResult(t *testing.T, dbase db.Database) []byte {
+	ws := state.NewWorldState(dbase, nil, nil, nil, nil)
+	for i := 0; i < 20; i++ {
+		as := ws.GetAccountState(crypto.SHA3Sum256([]byte{byte(i)}))
+		as.SetBalance(big.NewInt(int64(i + 1)))
+		_, err := as.SetValue([]byte("key"), []byte{byte(i)})
+		assert.NoError(t, err)
+	}
+	wss := ws.GetSnapshot()
+	assert.NoError(t, wss.Flush())
+	return codec.BC.MustMarshalToBytes([][]byte{wss.StateHash(), nil, nil})
+}
+
+func TestCheckAndRepairBlock(t *testing.T) {
+	dbase := db.NewMapDB()
+	result := newTestResult(t, dbase)
+	writeTestHeader(t, dbase, &block.V2HeaderFormat{
+		Version: module.BlockVersion2,
+		Height:  0,
+		Result:  result,
+	})
+	src := copyDatabase(t, dbase)
+
+	missing, err := CheckBlock(dbase, basic.Platform, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, missing)
+
+	stat, err := CountBucket(dbase, db.MerkleTrie)
+	assert.NoError(t, err)
+	assert.True(t, stat.Keys > 1)
+
+	// remove a node
+	bk, _ := dbase.GetBucket(db.MerkleTrie)
+	it, _ := db.NewIterator(bk, nil, nil, nil)
+	assert.True(t, it.Next())
+	key := append([]byte{}, it.Key()...)
+	it.Release()
+	assert.NoError(t, bk.Delete(key))
+
+	missing, err = CheckBlock(dbase, basic.Platform, 0)
+	assert.NoError(t, err)
+	if assert.Len(t, missing, 1) {
+		assert.Equal(t, key, missing[0].Key)
+	}
+
+	// nothing to repair without the source
+	cnt, missing, err := RepairBlock(dbase, basic.Platform, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, cnt)
+	assert.Len(t, missing, 1)
+
+	cnt, missing, err = RepairBlock(dbase, basic.Platform, 0, src)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, cnt)
+	assert.Empty(t, missing)
+
+	missing, err = CheckBlock(dbase, basic.Platform, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, missing)
+}
+
+func writeTestHeader(t *testing.T, dbase db.Database, hf *block.V2HeaderFormat) []byte {
+	bs := codec.BC.MustMarshalToBytes(hf)
+	id := crypto.SHA3Sum256(bs)
+	hb, _ := dbase.GetBucket(db.BytesByHash)
+	assert.NoError(t, hb.Set(id, bs))
+	hh, _ := db.NewCodedBucket(dbase, db.BlockHeaderHashByHeight, nil)
+	assert.NoError(t, hh.Set(hf.Height, db.Raw(id)))
+	return id
+}
+
+func TestVerifyBlocks(t *testing.T) {
+	dbase := db.NewMapDB()
+	result := newTestResult(t, dbase)
+	votes := []byte("votes")
+	bk, _ := dbase.GetBucket(db.BytesByHash)
+	assert.NoError(t, bk.Set(crypto.SHA3Sum256(votes), votes))
+
+	var headers []*block.V2HeaderFormat
+	var prevID []byte
+	for height := int64(0); height < 5; height++ {
+		hf := &block.V2HeaderFormat{
+			Version:   module.BlockVersion2,
+			Height:    height,
+			Timestamp: height,
+			PrevID:    prevID,
+			VotesHash: crypto.SHA3Sum256(votes),
+			Result:    result,
+		}
+		prevID = writeTestHeader(t, dbase, hf)
+		headers = append(headers, hf)
+	}
+	assert.NoError(t, block.SetLastHeight(dbase, nil, 4))
+
+	verify := func(from, to int64) map[int64]error {
+		issues := make(map[int64]error)
+		err := VerifyBlocks(dbase, from, to, func(height int64, issue error) error {
+			if issue != nil {
+				issues[height] = issue
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+		return issues
+	}
+	assert.Empty(t, verify(0, -1))
+
+	// replace the block at height 2 with the one with invalid PrevID
+	headers[2].PrevID = []byte("invalid")
+	writeTestHeader(t, dbase, headers[2])
+	issues := verify(0, -1)
+	assert.Len(t, issues, 2)
+	assert.Contains(t, issues, int64(2))
+	assert.Contains(t, issues, int64(3))
+
+	// missing votes
+	assert.NoError(t, bk.Delete(crypto.SHA3Sum256(votes)))
+	assert.Len(t, verify(4, 4), 1)
+
+	err := VerifyBlocks(dbase, 3, 1, func(height int64, issue error) error {
+		return nil
+	})
+	assert.Error(t, err)
+}
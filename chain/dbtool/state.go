@@ -0,0 +1,212 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dbtool
+
+import (
+	"bytes"
+
+	"github.com/icon-project/goloop/chain/base"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/merkle"
+	"github.com/icon-project/goloop/service"
+	"github.com/icon-project/goloop/service/transaction"
+)
+
+// Missing is an entry which is missing or corrupted in the database.
+type Missing struct {
+	Key     []byte
+	Buckets []db.BucketID
+}
+
+// walkDatabase is used as the store of the builder. It returns the value
+// only for the entries already walked, so the builder requests all entries
+// not walked yet.
+type walkDatabase struct {
+	real   db.Database
+	walked map[string]bool
+}
+
+func (d *walkDatabase) GetBucket(id db.BucketID) (db.Bucket, error) {
+	return &walkBucket{d: d, id: id}, nil
+}
+
+func (d *walkDatabase) Close() error {
+	return nil
+}
+
+type walkBucket struct {
+	d  *walkDatabase
+	id db.BucketID
+}
+
+func (b *walkBucket) Get(key []byte) ([]byte, error) {
+	if !b.d.walked[string(b.id)+string(key)] {
+		return nil, nil
+	}
+	return db.DoGetWithBucketID(b.d.real, b.id, key)
+}
+
+func (b *walkBucket) Has(key []byte) (bool, error) {
+	return b.d.walked[string(b.id)+string(key)], nil
+}
+
+func (b *walkBucket) Set(key []byte, value []byte) error {
+	b.d.walked[string(b.id)+string(key)] = true
+	return nil
+}
+
+func (b *walkBucket) Delete(key []byte) error {
+	return errors.UnsupportedError.New("WalkDatabaseIsReadOnly")
+}
+
+// walker walks all entries for the result. Missing entries are fetched from
+// the sources and written to the layer.
+type walker struct {
+	layer   db.LayerDB
+	sources []db.Database
+	fetched int
+	missing []Missing
+}
+
+// find returns the value of the entry from the database or the sources.
+// Values are verified with the hasher of the bucket.
+func (w *walker) find(ids []db.BucketID, key []byte) (db.BucketID, []byte, bool, error) {
+	for i, dbase := range append([]db.Database{w.layer}, w.sources...) {
+		for _, id := range ids {
+			bk, err := dbase.GetBucket(id)
+			if err != nil {
+				return id, nil, false, err
+			}
+			value, err := bk.Get(key)
+			if err != nil {
+				return id, nil, false, err
+			}
+			if value != nil && bytes.Equal(id.Hasher().Hash(value), key) {
+				return id, value, i > 0, nil
+			}
+		}
+	}
+	return "", nil, false, nil
+}
+
+func (w *walker) run(builder merkle.Builder) error {
+	missing := make(map[string]bool)
+	for {
+		processed := 0
+		for itr := builder.Requests(); itr.Next(); {
+			key := itr.Key()
+			if missing[string(key)] {
+				continue
+			}
+			id, value, fetched, err := w.find(itr.BucketIDs(), key)
+			if err != nil {
+				return err
+			}
+			if value == nil {
+				missing[string(key)] = true
+				w.missing = append(w.missing, Missing{
+					Key:     key,
+					Buckets: itr.BucketIDs(),
+				})
+				continue
+			}
+			if fetched {
+				for _, id := range itr.BucketIDs() {
+					bk, err := w.layer.GetBucket(id)
+					if err != nil {
+						return err
+					}
+					if err := bk.Set(key, value); err != nil {
+						return err
+					}
+				}
+				w.fetched += 1
+			}
+			if err := builder.OnData(id, value); err != nil {
+				return err
+			}
+			// Same as merkle.CopyContext, stop after some items to prevent
+			// massive memory usage by cumulated requests.
+			if processed += 1; processed >= merkle.MaxNumberOfItemsToCopyInRow {
+				break
+			}
+		}
+		if processed == 0 {
+			return nil
+		}
+	}
+}
+
+func walkBlock(
+	dbase db.Database, plt base.Platform, height int64, sources []db.Database,
+) (*walker, error) {
+	h, err := readHeader(dbase, height)
+	if err != nil {
+		return nil, err
+	}
+	w := &walker{
+		layer:   db.NewLayerDB(dbase),
+		sources: sources,
+	}
+	builder := merkle.NewBuilderWithRawDatabase(&walkDatabase{
+		real:   w.layer,
+		walked: make(map[string]bool),
+	})
+	transaction.NewTransactionListWithBuilder(builder, h.format.PatchTransactionsHash)
+	transaction.NewTransactionListWithBuilder(builder, h.format.NormalTransactionsHash)
+	err = service.RequestResult(builder, plt, h.format.Result, h.format.NextValidatorsHash)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.run(builder); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// CheckBlock walks all entries for the transactions and the result in the
+// block at the height, and returns missing entries. Entries under the
+// missing entry can't be walked, so it may not return all missing entries
+// at once.
+func CheckBlock(dbase db.Database, plt base.Platform, height int64) ([]Missing, error) {
+	w, err := walkBlock(dbase, plt, height, nil)
+	if err != nil {
+		return nil, err
+	}
+	return w.missing, nil
+}
+
+// RepairBlock walks all entries for the transactions and the result in the
+// block at the height, and writes missing entries found in the sources to
+// the database. All fetched entries are written atomically if the database
+// supports Batch. It returns the number of written entries and the entries
+// not found in the sources.
+func RepairBlock(
+	dbase db.Database, plt base.Platform, height int64, sources ...db.Database,
+) (int, []Missing, error) {
+	w, err := walkBlock(dbase, plt, height, sources)
+	if err != nil {
+		return 0, nil, err
+	}
+	if w.fetched > 0 {
+		if err := w.layer.Flush(true); err != nil {
+			return 0, nil, err
+		}
+	}
+	return w.fetched, w.missing, nil
+}
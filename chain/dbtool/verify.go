@@ -0,0 +1,242 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dbtool
+
+import (
+	"bytes"
+
+	"github.com/icon-project/goloop/block"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service"
+	"github.com/icon-project/goloop/service/transaction"
+	"github.com/icon-project/goloop/service/txresult"
+)
+
+// transactionLocator is same as the one stored by the block manager.
+type transactionLocator struct {
+	BlockHeight      int64
+	TransactionGroup module.TransactionGroup
+	IndexInGroup     int
+}
+
+type blockHeader struct {
+	id       []byte
+	format   block.V2HeaderFormat
+	patchTxs int
+	txs      int
+}
+
+func readHeader(dbase db.Database, height int64) (*blockHeader, error) {
+	id, err := block.GetBlockHeaderHashByHeight(dbase, codec.BC, height)
+	if err != nil {
+		return nil, errors.Wrapf(err, "NoHeaderHash(height=%d)", height)
+	}
+	bs, err := db.DoGetWithBucketID(dbase, db.BytesByHash, id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "NoHeader(height=%d,id=%#x)", height, id)
+	}
+	if hash := crypto.SHA3Sum256(bs); !bytes.Equal(hash, id) {
+		return nil, errors.CriticalHashError.Errorf(
+			"InvalidHeaderHash(height=%d,id=%#x,hash=%#x)", height, id, hash)
+	}
+	h := &blockHeader{id: id}
+	if _, err := codec.BC.UnmarshalFromBytes(bs, &h.format); err != nil {
+		return nil, errors.CriticalFormatError.Wrapf(err,
+			"InvalidHeader(height=%d)", height)
+	}
+	if h.format.Version != module.BlockVersion2 {
+		return nil, errors.UnsupportedError.Errorf(
+			"UnsupportedBlockVersion(height=%d,version=%d)",
+			height, h.format.Version)
+	}
+	if h.format.Height != height {
+		return nil, errors.InvalidStateError.Errorf(
+			"InvalidHeight(exp=%d,real=%d)", height, h.format.Height)
+	}
+	return h, nil
+}
+
+func checkBytes(dbase db.Database, name string, hash []byte) error {
+	if len(hash) == 0 {
+		return nil
+	}
+	bs, err := db.DoGetWithBucketID(dbase, db.BytesByHash, hash)
+	if err != nil {
+		return errors.Wrapf(err, "No%s(hash=%#x)", name, hash)
+	}
+	if h := crypto.SHA3Sum256(bs); !bytes.Equal(h, hash) {
+		return errors.CriticalHashError.Errorf(
+			"Invalid%s(hash=%#x,real=%#x)", name, hash, h)
+	}
+	return nil
+}
+
+// checkTransactions checks the transactions and their locators, and returns
+// the number of transactions.
+func checkTransactions(
+	dbase db.Database, height int64, group module.TransactionGroup, hash []byte,
+) (int, error) {
+	if len(hash) == 0 {
+		return 0, nil
+	}
+	locators, err := db.NewCodedBucket(dbase, db.TransactionLocatorByHash, nil)
+	if err != nil {
+		return 0, err
+	}
+	tl := transaction.NewTransactionListFromHash(dbase, hash)
+	cnt := 0
+	for it := tl.Iterator(); it.Has(); {
+		tx, idx, err := it.Get()
+		if err != nil {
+			return cnt, errors.Wrapf(err,
+				"InvalidTransaction(group=%d,idx=%d)", group, cnt)
+		}
+		var loc transactionLocator
+		if err := locators.Get(db.Raw(tx.ID()), &loc); err != nil {
+			return cnt, errors.Wrapf(err,
+				"NoTransactionLocator(group=%d,idx=%d,id=%#x)",
+				group, idx, tx.ID())
+		}
+		if loc.BlockHeight != height || loc.TransactionGroup != group || loc.IndexInGroup != idx {
+			return cnt, errors.InvalidStateError.Errorf(
+				"InvalidTransactionLocator(group=%d,idx=%d,id=%#x,loc=%+v)",
+				group, idx, tx.ID(), loc)
+		}
+		cnt += 1
+		if err := it.Next(); err != nil {
+			return cnt, errors.Wrapf(err,
+				"InvalidTransactionList(group=%d,idx=%d)", group, cnt)
+		}
+	}
+	return cnt, nil
+}
+
+func countReceipts(dbase db.Database, hash []byte) (int, error) {
+	if len(hash) == 0 {
+		return 0, nil
+	}
+	rl := txresult.NewReceiptListFromHash(dbase, hash)
+	cnt := 0
+	for it := rl.Iterator(); it.Has(); {
+		if _, err := it.Get(); err != nil {
+			return cnt, errors.Wrapf(err, "InvalidReceipt(idx=%d)", cnt)
+		}
+		cnt += 1
+		if err := it.Next(); err != nil {
+			return cnt, errors.Wrapf(err, "InvalidReceiptList(idx=%d)", cnt)
+		}
+	}
+	return cnt, nil
+}
+
+// checkBlock checks the block at the height. prev is the header of the
+// previous block if it's verified.
+func checkBlock(dbase db.Database, height int64, prev *blockHeader) (*blockHeader, error) {
+	h, err := readHeader(dbase, height)
+	if err != nil {
+		return nil, err
+	}
+	if prev != nil && !bytes.Equal(prev.id, h.format.PrevID) {
+		return h, errors.InvalidStateError.Errorf(
+			"InvalidPrevID(exp=%#x,real=%#x)", prev.id, h.format.PrevID)
+	}
+	if err := checkBytes(dbase, "Votes", h.format.VotesHash); err != nil {
+		return h, err
+	}
+	if err := checkBytes(dbase, "NextValidators", h.format.NextValidatorsHash); err != nil {
+		return h, err
+	}
+	if h.patchTxs, err = checkTransactions(dbase, height,
+		module.TransactionGroupPatch, h.format.PatchTransactionsHash); err != nil {
+		return h, err
+	}
+	if h.txs, err = checkTransactions(dbase, height,
+		module.TransactionGroupNormal, h.format.NormalTransactionsHash); err != nil {
+		return h, err
+	}
+	return h, nil
+}
+
+// checkReceipts checks the receipts of the previous block in the result of
+// the block.
+func checkReceipts(dbase db.Database, prev *blockHeader, h *blockHeader) error {
+	prh, err := service.PatchReceiptHashFromResult(h.format.Result)
+	if err != nil {
+		return errors.CriticalFormatError.Wrap(err, "InvalidResult")
+	}
+	nrh, err := service.NormalReceiptHashFromResult(h.format.Result)
+	if err != nil {
+		return errors.CriticalFormatError.Wrap(err, "InvalidResult")
+	}
+	if cnt, err := countReceipts(dbase, prh); err != nil {
+		return errors.Wrap(err, "InvalidPatchReceipts")
+	} else if cnt != prev.patchTxs {
+		return errors.InvalidStateError.Errorf(
+			"InvalidPatchReceipts(txs=%d,receipts=%d)", prev.patchTxs, cnt)
+	}
+	if cnt, err := countReceipts(dbase, nrh); err != nil {
+		return errors.Wrap(err, "InvalidNormalReceipts")
+	} else if cnt != prev.txs {
+		return errors.InvalidStateError.Errorf(
+			"InvalidNormalReceipts(txs=%d,receipts=%d)", prev.txs, cnt)
+	}
+	return nil
+}
+
+// VerifyBlocks verifies headers, transactions and receipts of the blocks
+// from the height to the height, and their linkage. Receipts of the block
+// are verified with the result in the next block, so the next block of the
+// last one is also checked if it exists.
+//
+// cb is called for each height with the problem found in the block, or
+// nil if there is no problem. It stops verification if cb returns an error.
+func VerifyBlocks(dbase db.Database, from, to int64, cb func(height int64, issue error) error) error {
+	last, err := block.GetLastHeight(dbase)
+	if err != nil {
+		return err
+	}
+	if to < 0 || to > last {
+		to = last
+	}
+	if from < 0 || from > to {
+		return errors.IllegalArgumentError.Errorf(
+			"InvalidRange(from=%d,to=%d,last=%d)", from, to, last)
+	}
+	var prev *blockHeader
+	var prevIssue error
+	for height := from; height <= to+1; height++ {
+		var h *blockHeader
+		var issue error
+		if height <= last {
+			h, issue = checkBlock(dbase, height, prev)
+			if h != nil && prev != nil && prevIssue == nil {
+				prevIssue = checkReceipts(dbase, prev, h)
+			}
+		}
+		if height > from {
+			if err := cb(height-1, prevIssue); err != nil {
+				return err
+			}
+		}
+		prev, prevIssue = h, issue
+	}
+	return nil
+}
@@ -0,0 +1,378 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package chain
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"io"
+	"io/ioutil"
+
+	"golang.org/x/crypto/sha3"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+)
+
+const (
+	SnapshotVersion        = 1
+	SnapshotHeaderFile     = "snapshot.json"
+	SnapshotGenesisFile    = "genesis.zip"
+	SnapshotChunkDir       = "chunks/"
+	snapshotChunkSizeLimit = 4 * 1024 * 1024
+)
+
+// SnapshotHeader describes the snapshot. The snapshot has the entries of the
+// database for the block at the height in the chunks. Chunks are identified
+// by their hashes and listed in the order of writing. Entries of the buckets
+// without the hasher can't be verified with the state, so KeyedHash binds
+// them to the header.
+type SnapshotHeader struct {
+	Version   int               `json:"version"`
+	NID       common.HexInt32   `json:"nid"`
+	CID       common.HexInt32   `json:"cid"`
+	Channel   string            `json:"channel"`
+	Height    int64             `json:"height"`
+	Block     common.HexBytes   `json:"block"`
+	StateHash common.HexBytes   `json:"stateHash"`
+	Codec     string            `json:"codec"`
+	Entries   int64             `json:"entries"`
+	KeyedHash common.HexBytes   `json:"keyedHash"`
+	Chunks    []common.HexBytes `json:"chunks"`
+}
+
+// snapshotEntry is an entry of the database. Key is omitted for the bucket
+// with the hasher, then the key is the hash of the value.
+type snapshotEntry struct {
+	Bucket string
+	Key    []byte
+	Value  []byte
+}
+
+func snapshotChunkName(hash []byte) string {
+	return SnapshotChunkDir + hex.EncodeToString(hash)
+}
+
+// keyedHasher accumulates the entries of the buckets without the hasher
+// in the order of writing.
+type keyedHasher struct {
+	hash.Hash
+}
+
+func (h keyedHasher) add(e *snapshotEntry) {
+	h.Write(codec.BC.MustMarshalToBytes(e))
+}
+
+func newKeyedHasher() keyedHasher {
+	return keyedHasher{sha3.New256()}
+}
+
+type snapshotWriter struct {
+	zw      *zip.Writer
+	entries []snapshotEntry
+	size    int
+	limit   int
+	written map[string]bool
+	keyed   keyedHasher
+	header  SnapshotHeader
+	onChunk func(entries int64) error
+}
+
+func (w *snapshotWriter) add(id db.BucketID, key, value []byte) error {
+	if hasher := id.Hasher(); hasher != nil {
+		if hash := hasher.Hash(value); !bytes.Equal(hash, key) {
+			return errors.CriticalHashError.Errorf(
+				"InvalidHash(bucket=%q,key=%x,hash=%x)", id, key, hash)
+		}
+		wk := string(id) + string(key)
+		if w.written[wk] {
+			return nil
+		}
+		w.written[wk] = true
+		key = nil
+	}
+	w.entries = append(w.entries, snapshotEntry{
+		Bucket: string(id),
+		Key:    key,
+		Value:  value,
+	})
+	if id.Hasher() == nil {
+		w.keyed.add(&w.entries[len(w.entries)-1])
+	}
+	w.size += len(key) + len(value)
+	w.header.Entries += 1
+	if w.size >= w.limit {
+		return w.flush()
+	}
+	return nil
+}
+
+func (w *snapshotWriter) flush() error {
+	if len(w.entries) == 0 {
+		return nil
+	}
+	bs, err := codec.BC.MarshalToBytes(w.entries)
+	if err != nil {
+		return err
+	}
+	hash := crypto.SHA3Sum256(bs)
+	f, err := w.zw.CreateHeader(&zip.FileHeader{
+		Name:   snapshotChunkName(hash),
+		Method: zip.Store,
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(bs); err != nil {
+		return err
+	}
+	w.header.Chunks = append(w.header.Chunks, hash)
+	w.entries = nil
+	w.size = 0
+	if w.onChunk != nil {
+		return w.onChunk(w.header.Entries)
+	}
+	return nil
+}
+
+func (w *snapshotWriter) writeFile(name string, data []byte) error {
+	f, err := w.zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	return err
+}
+
+func (w *snapshotWriter) Close() error {
+	if err := w.flush(); err != nil {
+		return err
+	}
+	w.header.KeyedHash = w.keyed.Sum(nil)
+	bs, err := json.Marshal(&w.header)
+	if err != nil {
+		return err
+	}
+	if err := w.writeFile(SnapshotHeaderFile, bs); err != nil {
+		return err
+	}
+	return w.zw.Close()
+}
+
+func newSnapshotWriter(w io.Writer) *snapshotWriter {
+	return &snapshotWriter{
+		zw:      zip.NewWriter(w),
+		limit:   snapshotChunkSizeLimit,
+		written: make(map[string]bool),
+		keyed:   newKeyedHasher(),
+		header: SnapshotHeader{
+			Version: SnapshotVersion,
+		},
+	}
+}
+
+// snapshotDatabase writes all entries to the snapshot. It returns nothing
+// for the query, so the entries are exported with all children.
+type snapshotDatabase struct {
+	w *snapshotWriter
+}
+
+func (d *snapshotDatabase) GetBucket(id db.BucketID) (db.Bucket, error) {
+	return &snapshotBucket{w: d.w, id: id}, nil
+}
+
+func (d *snapshotDatabase) Close() error {
+	return nil
+}
+
+type snapshotBucket struct {
+	w  *snapshotWriter
+	id db.BucketID
+}
+
+func (b *snapshotBucket) Get(key []byte) ([]byte, error) {
+	return nil, nil
+}
+
+func (b *snapshotBucket) Has(key []byte) (bool, error) {
+	return false, nil
+}
+
+func (b *snapshotBucket) Set(key []byte, value []byte) error {
+	return b.w.add(b.id, key, value)
+}
+
+func (b *snapshotBucket) Delete(key []byte) error {
+	return errors.UnsupportedError.New("SnapshotIsWriteOnly")
+}
+
+// SnapshotFile is an opened snapshot file.
+type SnapshotFile struct {
+	Header *SnapshotHeader
+
+	zr    *zip.ReadCloser
+	files map[string]*zip.File
+}
+
+func readAllOfSnapshotFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return ioutil.ReadAll(rc)
+}
+
+func (s *SnapshotFile) readFile(name string) ([]byte, error) {
+	f, ok := s.files[name]
+	if !ok {
+		return nil, errors.NotFoundError.Errorf("NoFileInSnapshot(name=%s)", name)
+	}
+	return readAllOfSnapshotFile(f)
+}
+
+// Genesis returns the genesis storage for the chain starting from the block
+// of the snapshot.
+func (s *SnapshotFile) Genesis() ([]byte, error) {
+	return s.readFile(SnapshotGenesisFile)
+}
+
+// readChunk reads the chunk at the index after verifying its hash. The keys
+// of the entries are filled with the hashes of the values if the bucket has
+// the hasher.
+func (s *SnapshotFile) readChunk(idx int) ([]snapshotEntry, error) {
+	hash := []byte(s.Header.Chunks[idx])
+	bs, err := s.readFile(snapshotChunkName(hash))
+	if err != nil {
+		return nil, err
+	}
+	if h := crypto.SHA3Sum256(bs); !bytes.Equal(h, hash) {
+		return nil, errors.CriticalHashError.Errorf(
+			"InvalidChunk(idx=%d,exp=%x,real=%x)", idx, hash, h)
+	}
+	var entries []snapshotEntry
+	if _, err := codec.BC.UnmarshalFromBytes(bs, &entries); err != nil {
+		return nil, errors.IllegalArgumentError.Wrapf(err,
+			"InvalidChunk(idx=%d)", idx)
+	}
+	for i := range entries {
+		e := &entries[i]
+		if hasher := db.BucketID(e.Bucket).Hasher(); hasher != nil {
+			e.Key = hasher.Hash(e.Value)
+		} else if len(e.Key) == 0 {
+			return nil, errors.IllegalArgumentError.Errorf(
+				"NoKeyForEntry(idx=%d,bucket=%q)", idx, e.Bucket)
+		}
+	}
+	return entries, nil
+}
+
+// WriteTo writes all entries of the snapshot to the database. Entries of a
+// chunk are written in a batch if the database supports it. onChunk is called
+// after writing each chunk, and it may stop writing by returning an error.
+// It returns CriticalHashError if the entries of the buckets without the
+// hasher don't match KeyedHash of the header after writing all chunks, so
+// the database should be discarded on failure.
+func (s *SnapshotFile) WriteTo(dbase db.Database, onChunk func(idx int) error) error {
+	batcher := db.BatcherOf(dbase)
+	keyed := newKeyedHasher()
+	for idx := range s.Header.Chunks {
+		entries, err := s.readChunk(idx)
+		if err != nil {
+			return err
+		}
+		for i := range entries {
+			if e := &entries[i]; db.BucketID(e.Bucket).Hasher() == nil {
+				keyed.add(e)
+			}
+		}
+		if batcher != nil {
+			batch := batcher.NewBatch()
+			for _, e := range entries {
+				if err := batch.Set(db.BucketID(e.Bucket), e.Key, e.Value); err != nil {
+					return err
+				}
+			}
+			if err := batch.Write(); err != nil {
+				return err
+			}
+		} else {
+			for _, e := range entries {
+				bk, err := dbase.GetBucket(db.BucketID(e.Bucket))
+				if err != nil {
+					return err
+				}
+				if err := bk.Set(e.Key, e.Value); err != nil {
+					return err
+				}
+			}
+		}
+		if onChunk != nil {
+			if err := onChunk(idx); err != nil {
+				return err
+			}
+		}
+	}
+	if hash := keyed.Sum(nil); !bytes.Equal(hash, s.Header.KeyedHash) {
+		return errors.CriticalHashError.Errorf(
+			"InvalidKeyedHash(exp=%x,real=%x)", []byte(s.Header.KeyedHash), hash)
+	}
+	return nil
+}
+
+func (s *SnapshotFile) Close() error {
+	return s.zr.Close()
+}
+
+func OpenSnapshot(file string) (ret *SnapshotFile, err error) {
+	zr, err := zip.OpenReader(file)
+	if err != nil {
+		return nil, errors.IllegalArgumentError.Wrapf(err,
+			"ZipOpenFailure(snapshot=%s)", file)
+	}
+	defer func() {
+		if err != nil {
+			zr.Close()
+		}
+	}()
+	s := &SnapshotFile{
+		zr:    zr,
+		files: make(map[string]*zip.File),
+	}
+	for _, f := range zr.File {
+		s.files[f.Name] = f
+	}
+	bs, err := s.readFile(SnapshotHeaderFile)
+	if err != nil {
+		return nil, err
+	}
+	s.Header = new(SnapshotHeader)
+	if err := json.Unmarshal(bs, s.Header); err != nil {
+		return nil, errors.IllegalArgumentError.Wrap(err, "InvalidSnapshotHeader")
+	}
+	if s.Header.Version != SnapshotVersion {
+		return nil, errors.UnsupportedError.Errorf(
+			"UnsupportedSnapshotVersion(version=%d)", s.Header.Version)
+	}
+	return s, nil
+}
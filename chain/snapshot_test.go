@@ -0,0 +1,241 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package chain
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/merkle"
+	"github.com/icon-project/goloop/service/state"
+)
+
+func TestSnapshot_WriteAndRead(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	file := path.Join(dir, "snapshot.zip")
+	fd, err := os.Create(file)
+	assert.NoError(t, err)
+	sw := newSnapshotWriter(fd)
+	sw.header.Height = 10
+	assert.NoError(t, sw.writeFile(SnapshotGenesisFile, []byte("genesis")))
+
+	sdb := &snapshotDatabase{sw}
+	bk, err := sdb.GetBucket(db.BytesByHash)
+	assert.NoError(t, err)
+	v1 := []byte("value1")
+	assert.NoError(t, bk.Set(crypto.SHA3Sum256(v1), v1))
+	assert.NoError(t, bk.Set(crypto.SHA3Sum256(v1), v1))
+	assert.Error(t, bk.Set([]byte("key"), v1))
+
+	pk, err := sdb.GetBucket(db.ChainProperty)
+	assert.NoError(t, err)
+	assert.NoError(t, pk.Set([]byte("key"), []byte("v2")))
+	assert.NoError(t, sw.Close())
+	assert.NoError(t, fd.Close())
+
+	s, err := OpenSnapshot(file)
+	assert.NoError(t, err)
+	defer s.Close()
+	assert.Equal(t, int64(10), s.Header.Height)
+	assert.Equal(t, int64(2), s.Header.Entries)
+	assert.Len(t, s.Header.Chunks, 1)
+
+	gs, err := s.Genesis()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("genesis"), gs)
+
+	entries, err := s.readChunk(0)
+	assert.NoError(t, err)
+	assert.Equal(t, []snapshotEntry{
+		{string(db.BytesByHash), crypto.SHA3Sum256(v1), v1},
+		{string(db.ChainProperty), []byte("key"), []byte("v2")},
+	}, entries)
+}
+
+func TestSnapshot_InvalidChunk(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	file := path.Join(dir, "snapshot.zip")
+	fd, err := os.Create(file)
+	assert.NoError(t, err)
+	zw := zip.NewWriter(fd)
+	hash := crypto.SHA3Sum256([]byte("chunk"))
+	w, err := zw.Create(snapshotChunkName(hash))
+	assert.NoError(t, err)
+	_, err = w.Write([]byte("modified"))
+	assert.NoError(t, err)
+	bs, err := json.Marshal(&SnapshotHeader{
+		Version: SnapshotVersion,
+		Chunks:  []common.HexBytes{hash},
+	})
+	assert.NoError(t, err)
+	w, err = zw.Create(SnapshotHeaderFile)
+	assert.NoError(t, err)
+	_, err = w.Write(bs)
+	assert.NoError(t, err)
+	assert.NoError(t, zw.Close())
+	assert.NoError(t, fd.Close())
+
+	s, err := OpenSnapshot(file)
+	assert.NoError(t, err)
+	defer s.Close()
+	_, err = s.readChunk(0)
+	assert.Error(t, err)
+}
+
+// newSnapshotSource makes a database with a world state and the block index
+// entries. It returns the database and the state hash.
+func newSnapshotSource(t *testing.T) (db.Database, []byte) {
+	dbase := db.NewMapDB()
+	ws := state.NewWorldState(dbase, nil, nil, nil, nil)
+	owner := common.MustNewAddressFromString("hx0000000000000000000000000000000000000001")
+	for i := 0; i < 100; i++ {
+		addr := common.MustNewAddressFromString(fmt.Sprintf("hx%040x", i+1))
+		ws.GetAccountState(addr.ID()).SetBalance(big.NewInt(int64(i + 1)))
+	}
+	score := common.MustNewAddressFromString("cx0000000000000000000000000000000000000001")
+	as := ws.GetAccountState(score.ID())
+	as.InitContractAccount(owner)
+	for i := 0; i < 100; i++ {
+		_, err := as.SetValue([]byte(fmt.Sprintf("key%d", i)), []byte(fmt.Sprintf("value%d", i)))
+		assert.NoError(t, err)
+	}
+	wss := ws.GetSnapshot()
+	assert.NoError(t, wss.Flush())
+
+	hb := codec.BC.MustMarshalToBytes(int64(10))
+	bk, err := dbase.GetBucket(db.BlockHeaderHashByHeight)
+	assert.NoError(t, err)
+	assert.NoError(t, bk.Set(hb, crypto.SHA3Sum256([]byte("block"))))
+	bk, err = dbase.GetBucket(db.ChainProperty)
+	assert.NoError(t, err)
+	assert.NoError(t, bk.Set([]byte("block.lastHeight"), hb))
+	return dbase, wss.StateHash()
+}
+
+func exportSnapshotOf(t *testing.T, src db.Database, stateHash []byte, file string, modify func(w *snapshotWriter)) {
+	fd, err := os.Create(file)
+	assert.NoError(t, err)
+	defer fd.Close()
+
+	sw := newSnapshotWriter(fd)
+	sw.limit = 1024
+	sw.header.Height = 10
+	sw.header.StateHash = stateHash
+	ctx := merkle.NewCopyContext(src, &snapshotDatabase{sw})
+	_, err = state.NewWorldSnapshotWithBuilder(ctx.Builder(), stateHash, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, ctx.Run())
+	assert.NoError(t, ctx.Copy(db.BlockHeaderHashByHeight, codec.BC.MustMarshalToBytes(int64(10))))
+	assert.NoError(t, ctx.Copy(db.ChainProperty, []byte("block.lastHeight")))
+	if modify != nil {
+		modify(sw)
+	}
+	assert.NoError(t, sw.Close())
+}
+
+func entriesOf(t *testing.T, dbase db.Database, id db.BucketID) map[string]string {
+	bk, err := dbase.GetBucket(id)
+	assert.NoError(t, err)
+	itr, err := db.NewIterator(bk, nil, nil, nil)
+	assert.NoError(t, err)
+	defer itr.Release()
+	entries := make(map[string]string)
+	for itr.Next() {
+		entries[string(itr.Key())] = string(itr.Value())
+	}
+	assert.NoError(t, itr.Error())
+	return entries
+}
+
+func TestSnapshot_ExportAndImport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	src, stateHash := newSnapshotSource(t)
+	file := path.Join(dir, "snapshot.zip")
+	exportSnapshotOf(t, src, stateHash, file, nil)
+
+	s, err := OpenSnapshot(file)
+	assert.NoError(t, err)
+	defer s.Close()
+	assert.Equal(t, common.HexBytes(stateHash), s.Header.StateHash)
+	assert.Greater(t, len(s.Header.Chunks), 1)
+
+	dst := db.NewMapDB()
+	var chunks int
+	assert.NoError(t, s.WriteTo(dst, func(idx int) error {
+		chunks = idx + 1
+		return nil
+	}))
+	assert.Equal(t, len(s.Header.Chunks), chunks)
+
+	for _, id := range []db.BucketID{
+		db.MerkleTrie,
+		db.BytesByHash,
+		db.BlockHeaderHashByHeight,
+		db.ChainProperty,
+	} {
+		assert.Equal(t, entriesOf(t, src, id), entriesOf(t, dst, id), "bucket=%q", id)
+	}
+
+	// all entries for the state are available in the imported database
+	ctx := merkle.NewCopyContext(dst, verifierDatabase{})
+	_, err = state.NewWorldSnapshotWithBuilder(ctx.Builder(), stateHash, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, ctx.Run())
+}
+
+func TestSnapshot_ModifiedKeyedEntry(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	src, stateHash := newSnapshotSource(t)
+	file := path.Join(dir, "snapshot.zip")
+	exportSnapshotOf(t, src, stateHash, file, func(w *snapshotWriter) {
+		// chunks are still valid, but the entry differs from the header
+		e := &w.entries[len(w.entries)-1]
+		assert.Equal(t, string(db.ChainProperty), e.Bucket)
+		e.Value = codec.BC.MustMarshalToBytes(int64(11))
+	})
+
+	s, err := OpenSnapshot(file)
+	assert.NoError(t, err)
+	defer s.Close()
+	err = s.WriteTo(db.NewMapDB(), nil)
+	assert.True(t, errors.CriticalHashError.Equals(err), err)
+}
@@ -0,0 +1,815 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package chain
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/icon-project/goloop/block"
+	"github.com/icon-project/goloop/btp"
+	"github.com/icon-project/goloop/chain/base"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/common/merkle"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/server/metric"
+	"github.com/icon-project/goloop/service"
+	"github.com/icon-project/goloop/service/transaction"
+	"github.com/icon-project/goloop/service/txresult"
+)
+
+const (
+	pruningCheckInterval = time.Minute
+	pruningSweepBatch    = 1024
+	pruningHashSize      = 32
+
+	pruningHistoryDB = "history"
+	pruningBodiesDB  = "bodies"
+	pruningMarksDB   = "marks"
+	pruningKeyHeight = "height"
+	pruningKeyID     = "id"
+	pruningKeyLowest = "lowest"
+)
+
+// prunableBuckets are buckets pruned by statePruner.
+var prunableBuckets = []db.BucketID{db.MerkleTrie, db.BytesByHash}
+
+func isPrunableBucket(id db.BucketID) bool {
+	return id == db.MerkleTrie || id == db.BytesByHash
+}
+
+// prunableDatabase tracks entries written or checked for existence while
+// the pruning is running. They may become reachable from the new blocks,
+// so they must not be deleted even though they are not marked.
+// Reading entries doesn't make them reachable from the new blocks, so they
+// are not tracked.
+type prunableDatabase struct {
+	db.Database
+	batcher db.Batcher
+
+	lock    sync.Mutex
+	touched map[string]struct{}
+}
+
+func (d *prunableDatabase) touch(id db.BucketID, key []byte) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.touched != nil {
+		d.touched[string(id)+string(key)] = struct{}{}
+	}
+}
+
+func (d *prunableDatabase) startTracking() {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.touched = make(map[string]struct{})
+}
+
+func (d *prunableDatabase) stopTracking() {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.touched = nil
+}
+
+// deleteUntouched deletes entries not touched since the tracking started.
+// It returns the number of deleted entries and their bytes.
+func (d *prunableDatabase) deleteUntouched(id db.BucketID, keys, values [][]byte) (int, int, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	batch := d.batcher.NewBatch()
+	size := 0
+	for i, key := range keys {
+		if _, ok := d.touched[string(id)+string(key)]; ok {
+			continue
+		}
+		if err := batch.Delete(id, key); err != nil {
+			return 0, 0, err
+		}
+		size += len(key) + len(values[i])
+	}
+	if batch.Len() == 0 {
+		return 0, 0, nil
+	}
+	if err := batch.Write(); err != nil {
+		return 0, 0, err
+	}
+	return batch.Len(), size, nil
+}
+
+func (d *prunableDatabase) GetBucket(id db.BucketID) (db.Bucket, error) {
+	bk, err := d.Database.GetBucket(id)
+	if err != nil || !isPrunableBucket(id) {
+		return bk, err
+	}
+	return &prunableBucket{Bucket: bk, d: d, id: id}, nil
+}
+
+func (d *prunableDatabase) NewBatch() db.Batch {
+	return &prunableBatch{Batch: d.batcher.NewBatch(), d: d}
+}
+
+func newPrunableDatabase(dbase db.Database) (*prunableDatabase, error) {
+	batcher := db.BatcherOf(dbase)
+	if batcher == nil {
+		return nil, errors.UnsupportedError.New("BatchNotSupported")
+	}
+	return &prunableDatabase{
+		Database: dbase,
+		batcher:  batcher,
+	}, nil
+}
+
+type prunableBucket struct {
+	db.Bucket
+	d  *prunableDatabase
+	id db.BucketID
+}
+
+func (b *prunableBucket) Has(key []byte) (bool, error) {
+	b.d.touch(b.id, key)
+	return b.Bucket.Has(key)
+}
+
+func (b *prunableBucket) Set(key []byte, value []byte) error {
+	b.d.touch(b.id, key)
+	return b.Bucket.Set(key, value)
+}
+
+func (b *prunableBucket) Iterator(prefix, start, end []byte) (db.Iterator, error) {
+	return db.NewIterator(b.Bucket, prefix, start, end)
+}
+
+type prunableBatch struct {
+	db.Batch
+	d *prunableDatabase
+}
+
+func (b *prunableBatch) Set(id db.BucketID, key []byte, value []byte) error {
+	if isPrunableBucket(id) {
+		b.d.touch(id, key)
+	}
+	return b.Batch.Set(id, key, value)
+}
+
+func markBucketOf(id db.BucketID) db.BucketID {
+	return "m" + id
+}
+
+// markDatabase is used as the store of the builder for marking. It returns
+// the value only for the entries already marked, so the builder requests
+// all entries not marked yet.
+type markDatabase struct {
+	marks db.Database
+	real  db.Database
+}
+
+func (d *markDatabase) GetBucket(id db.BucketID) (db.Bucket, error) {
+	marks, err := d.marks.GetBucket(markBucketOf(id))
+	if err != nil {
+		return nil, err
+	}
+	real, err := d.real.GetBucket(id)
+	if err != nil {
+		return nil, err
+	}
+	return &markBucket{marks: marks, real: real}, nil
+}
+
+func (d *markDatabase) Close() error {
+	return nil
+}
+
+type markBucket struct {
+	marks db.Bucket
+	real  db.Bucket
+}
+
+func (b *markBucket) Get(key []byte) ([]byte, error) {
+	if ok, err := b.marks.Has(key); err != nil || !ok {
+		return nil, err
+	}
+	return b.real.Get(key)
+}
+
+func (b *markBucket) Has(key []byte) (bool, error) {
+	return b.marks.Has(key)
+}
+
+func (b *markBucket) Set(key []byte, value []byte) error {
+	return b.marks.Set(key, []byte{1})
+}
+
+func (b *markBucket) Delete(key []byte) error {
+	return errors.UnsupportedError.New("MarkDatabaseIsReadOnly")
+}
+
+// markIterator iterates marked keys in order to check whether the keys
+// from the other iterator in order are marked.
+type markIterator struct {
+	it   db.Iterator
+	key  []byte
+	done bool
+}
+
+func (m *markIterator) contains(key []byte) bool {
+	for !m.done && (m.key == nil || bytes.Compare(m.key, key) < 0) {
+		if !m.it.Next() {
+			m.done = true
+			break
+		}
+		if k := m.it.Key(); len(k) == pruningHashSize {
+			m.key = append(m.key[:0], k...)
+		}
+	}
+	return !m.done && bytes.Equal(m.key, key)
+}
+
+func newMarkIterator(marks db.Database, id db.BucketID) (*markIterator, error) {
+	bk, err := marks.GetBucket(markBucketOf(id))
+	if err != nil {
+		return nil, err
+	}
+	it, err := db.NewIterator(bk, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &markIterator{it: it}, nil
+}
+
+type blockGetter interface {
+	GetLastBlock() (module.Block, error)
+	GetBlockByHeight(height int64) (module.Block, error)
+}
+
+// termInfo is implemented by the extension snapshots of the platforms
+// having terms.
+type termInfo interface {
+	TermInfo() (start int64, period int64)
+}
+
+// statePruner deletes entries of MerkleTrie and BytesByHash which are not
+// reachable from the blocks in the background while the chain is running.
+// Headers and votes of all blocks are retained. Bodies and receipts are
+// retained only for the blocks from the lowest height decided by the
+// history retention, and the results are retained only for the last
+// retention blocks among them. Zero retention retains the results of all
+// the retained blocks.
+//
+// It marks reachable entries, then sweeps entries not marked and not touched
+// while it's running. Entries for the blocks never change, so their marks
+// are kept in the history databases, and only marks for new blocks are added
+// on each pruning. Marks for the bodies are rebuilt when the lowest height
+// changes.
+type statePruner struct {
+	dbase     *prunableDatabase
+	bg        blockGetter
+	plt       base.Platform
+	dir       string
+	dbType    string
+	base      int64
+	retention int64
+	history   int64
+	terms     int64
+	log       log.Logger
+	metric    *metric.PruningMetric
+
+	last       int64
+	lowest     int64
+	termWarned bool
+	progress   int
+	stop       chan struct{}
+	done       chan struct{}
+}
+
+func (p *statePruner) interrupted() bool {
+	select {
+	case <-p.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *statePruner) setProgress(base, span int, done, all int64) {
+	progress := base
+	if all > 0 {
+		progress += int(int64(span) * done / all)
+	}
+	if progress != p.progress {
+		p.progress = progress
+		p.metric.OnProgress(progress)
+	}
+}
+
+func (p *statePruner) openDatabase(name string) (db.Database, error) {
+	if err := os.MkdirAll(p.dir, 0700); err != nil {
+		return nil, err
+	}
+	return db.Open(p.dir, p.dbType, name)
+}
+
+func (p *statePruner) removeDatabase(name string) error {
+	return os.RemoveAll(path.Join(p.dir, name))
+}
+
+// mark marks all entries requested by the builder.
+func (p *statePruner) mark(builder merkle.Builder) error {
+	for builder.UnresolvedCount() > 0 {
+		if p.interrupted() {
+			return errors.ErrInterrupted
+		}
+		processed := 0
+		for itr := builder.Requests(); itr.Next(); {
+			key := itr.Key()
+			var id db.BucketID
+			var value []byte
+			for _, bid := range itr.BucketIDs() {
+				v, err := db.DoGetWithBucketID(p.dbase.Database, bid, key)
+				if err != nil {
+					return err
+				}
+				if v != nil {
+					id, value = bid, v
+					break
+				}
+			}
+			if value == nil {
+				return errors.NotFoundError.Errorf(
+					"MissingEntry(buckets=%q,key=%#x)", itr.BucketIDs(), key)
+			}
+			if err := builder.OnData(id, value); err != nil {
+				return err
+			}
+			// Same as merkle.CopyContext, stop after some items to prevent
+			// massive memory usage by cumulated requests.
+			if processed += 1; processed >= merkle.MaxNumberOfItemsToCopyInRow {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// markHeader marks the header, the votes and the next validators of the
+// block. The body of the base block is also marked, because the genesis
+// transaction in it is used to check the chain on start.
+func (p *statePruner) markHeader(marks db.Database, blk module.Block) error {
+	store := &markDatabase{marks: marks, real: p.dbase.Database}
+	bk, err := store.GetBucket(db.BytesByHash)
+	if err != nil {
+		return err
+	}
+	for _, key := range [][]byte{blk.ID(), blk.Votes().Hash(), blk.NextValidatorsHash()} {
+		if len(key) > 0 {
+			if err := bk.Set(key, nil); err != nil {
+				return err
+			}
+		}
+	}
+	if blk.Height() == p.base {
+		return p.markBody(marks, blk)
+	}
+	return nil
+}
+
+// markBody marks the transactions of the block. Receipts and BTP digest
+// in the result are also marked.
+func (p *statePruner) markBody(marks db.Database, blk module.Block) error {
+	store := &markDatabase{marks: marks, real: p.dbase.Database}
+	builder := merkle.NewBuilderWithRawDatabase(store)
+	transaction.NewTransactionListWithBuilder(builder, blk.PatchTransactions().Hash())
+	transaction.NewTransactionListWithBuilder(builder, blk.NormalTransactions().Hash())
+	if result := blk.Result(); len(result) > 0 {
+		prh, err := service.PatchReceiptHashFromResult(result)
+		if err != nil {
+			return err
+		}
+		nrh, err := service.NormalReceiptHashFromResult(result)
+		if err != nil {
+			return err
+		}
+		bdh, err := service.BTPDigestHashFromResult(result)
+		if err != nil {
+			return err
+		}
+		txresult.NewReceiptListWithBuilder(builder, prh)
+		txresult.NewReceiptListWithBuilder(builder, nrh)
+		if _, err := btp.NewDigestWithBuilder(builder, bdh); err != nil {
+			return err
+		}
+	}
+	return p.mark(builder)
+}
+
+// openHistory opens the history database having marks for the blocks from
+// the height, and returns it with the height of the last marked block.
+// Marks are dropped if they are for the other height or the marked block is
+// not in the chain any more.
+func (p *statePruner) openHistory(name string, from int64) (db.Database, int64, error) {
+	history, err := p.openDatabase(name)
+	if err != nil {
+		return nil, 0, err
+	}
+	bk, err := db.NewCodedBucket(history, db.ChainProperty, nil)
+	if err != nil {
+		history.Close()
+		return nil, 0, err
+	}
+	var lowest, height int64
+	var id []byte
+	if err := bk.Get(pruningKeyLowest, &lowest); err == nil && lowest == from {
+		if err := bk.Get(pruningKeyHeight, &height); err == nil {
+			if err := bk.Get(pruningKeyID, &id); err != nil {
+				history.Close()
+				return nil, 0, err
+			}
+			if blk, err := p.bg.GetBlockByHeight(height); err == nil && bytes.Equal(blk.ID(), id) {
+				return history, height, nil
+			}
+			p.log.Warnf("Drop marks in %s for the blocks height=%d id=%#x", name, height, id)
+		} else if errors.NotFoundError.Equals(err) {
+			return history, from - 1, nil
+		} else {
+			history.Close()
+			return nil, 0, err
+		}
+	} else if err != nil && !errors.NotFoundError.Equals(err) {
+		history.Close()
+		return nil, 0, err
+	}
+	history.Close()
+	if err := p.removeDatabase(name); err != nil {
+		return nil, 0, err
+	}
+	history, err = p.openDatabase(name)
+	if err != nil {
+		return nil, 0, err
+	}
+	if bk, err = db.NewCodedBucket(history, db.ChainProperty, nil); err == nil {
+		err = bk.Set(pruningKeyLowest, from)
+	}
+	if err != nil {
+		history.Close()
+		return nil, 0, err
+	}
+	return history, from - 1, nil
+}
+
+// markHistory marks entries for the blocks from the height to the height
+// using the mark function. Marks for each block are written atomically with
+// the height, so the history database never has partially marked entries.
+func (p *statePruner) markHistory(
+	history db.Database, from, to int64, progress int,
+	mark func(db.Database, module.Block) error,
+) error {
+	for height := from; height <= to; height++ {
+		blk, err := p.bg.GetBlockByHeight(height)
+		if err != nil {
+			return err
+		}
+		ldb := db.NewLayerDB(history)
+		if err := mark(ldb, blk); err != nil {
+			return errors.Wrapf(err, "fail to mark block height=%d", height)
+		}
+		bk, err := db.NewCodedBucket(ldb, db.ChainProperty, nil)
+		if err != nil {
+			return err
+		}
+		if err := bk.Set(pruningKeyHeight, height); err != nil {
+			return err
+		}
+		if err := bk.Set(pruningKeyID, blk.ID()); err != nil {
+			return err
+		}
+		if err := ldb.Flush(true); err != nil {
+			return err
+		}
+		p.setProgress(progress, 10, height-from+1, to-from+1)
+	}
+	return nil
+}
+
+// horizon returns the lowest height of the history to be retained for
+// the last block.
+func (p *statePruner) horizon(last module.Block) (int64, error) {
+	var height int64
+	switch {
+	case p.history > 0:
+		height = last.Height() - p.history + 1
+	case p.terms > 0:
+		ext, err := service.ExtensionDataFromResult(last.Result())
+		if err != nil {
+			return 0, err
+		}
+		ti, ok := p.plt.NewExtensionSnapshot(p.dbase.Database, ext).(termInfo)
+		if !ok {
+			return 0, errors.UnsupportedError.New("TermNotSupported")
+		}
+		start, period := ti.TermInfo()
+		if period == 0 {
+			return p.lowest, nil
+		}
+		height = start - (p.terms-1)*period
+	default:
+		height = p.base
+	}
+	if height < p.base {
+		height = p.base
+	}
+	return height, nil
+}
+
+// nextLowest returns the lowest height of the history to be retained by
+// the next pruning. In the block mode, it moves only after enough blocks
+// are added, because marks for the bodies are rebuilt when it moves.
+func (p *statePruner) nextLowest(last module.Block) (int64, error) {
+	height, err := p.horizon(last)
+	if err != nil {
+		return p.lowest, err
+	}
+	step := p.history / 10
+	if step < 1 {
+		step = 1
+	}
+	if height-p.lowest < step {
+		return p.lowest, nil
+	}
+	return height, nil
+}
+
+// markResults marks entries for the results in the blocks.
+func (p *statePruner) markResults(marks db.Database, from, to int64) error {
+	store := &markDatabase{marks: marks, real: p.dbase.Database}
+	for height := from; height <= to; height++ {
+		blk, err := p.bg.GetBlockByHeight(height)
+		if err != nil {
+			return err
+		}
+		builder := merkle.NewBuilderWithRawDatabase(store)
+		if err := service.RequestResult(builder, p.plt, blk.Result(), blk.NextValidatorsHash()); err != nil {
+			return err
+		}
+		if err := p.mark(builder); err != nil {
+			return errors.Wrapf(err, "fail to mark result height=%d", height)
+		}
+		p.setProgress(20, 20, height-from+1, to-from+1)
+	}
+	return nil
+}
+
+// sweep deletes entries of the bucket which are not marked in the databases.
+// It returns the number of deleted entries and their bytes.
+func (p *statePruner) sweep(id db.BucketID, progress int, marks ...db.Database) (int, int, error) {
+	bk, err := p.dbase.Database.GetBucket(id)
+	if err != nil {
+		return 0, 0, err
+	}
+	it, err := db.NewIterator(bk, nil, nil, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer it.Release()
+
+	mits := make([]*markIterator, len(marks))
+	for i, m := range marks {
+		if mits[i], err = newMarkIterator(m, id); err != nil {
+			return 0, 0, err
+		}
+		defer mits[i].it.Release()
+	}
+
+	var keys, values [][]byte
+	entries, size := 0, 0
+	flush := func() error {
+		cnt, bytes, err := p.dbase.deleteUntouched(id, keys, values)
+		if err != nil {
+			return err
+		}
+		entries, size = entries+cnt, size+bytes
+		p.metric.OnDelete(cnt, bytes)
+		keys, values = keys[:0], values[:0]
+		return nil
+	}
+	hasher := id.Hasher()
+	for it.Next() {
+		key := it.Key()
+		// some backends share the key space of the buckets, so it may
+		// return entries of the other buckets.
+		if len(key) != pruningHashSize {
+			continue
+		}
+		marked := false
+		for _, mit := range mits {
+			if mit.contains(key) {
+				marked = true
+			}
+		}
+		if marked || !bytes.Equal(hasher.Hash(it.Value()), key) {
+			continue
+		}
+		keys = append(keys, append([]byte{}, key...))
+		values = append(values, append([]byte{}, it.Value()...))
+		if len(keys) >= pruningSweepBatch {
+			if p.interrupted() {
+				return entries, size, errors.ErrInterrupted
+			}
+			if err := flush(); err != nil {
+				return entries, size, err
+			}
+			p.setProgress(progress, 30, int64(key[0]), 256)
+		}
+	}
+	if err := it.Error(); err != nil {
+		return entries, size, err
+	}
+	if len(keys) > 0 {
+		if err := flush(); err != nil {
+			return entries, size, err
+		}
+	}
+	return entries, size, nil
+}
+
+func (p *statePruner) prune() (rerr error) {
+	startTS := time.Now()
+	p.dbase.startTracking()
+	defer p.dbase.stopTracking()
+
+	// Blocks finalized after this are written while tracking, so it's
+	// enough to mark entries for the blocks up to the last block.
+	lastBlk, err := p.bg.GetLastBlock()
+	if err != nil {
+		return err
+	}
+	to := lastBlk.Height()
+	lowest, err := p.nextLowest(lastBlk)
+	if err != nil {
+		return err
+	}
+	from := lowest
+	if p.retention > 0 && to-p.retention+1 > from {
+		from = to - p.retention + 1
+	}
+	p.log.Infof("Pruning start history=[%d,%d] retained=[%d,%d]",
+		lowest, to, from, to)
+	p.progress = 0
+	p.metric.OnStart(from, to)
+
+	// Queries for the blocks below the lowest heights are rejected before
+	// their bodies and states are deleted.
+	if lowest != p.lowest {
+		if err := block.SetLowestHeight(p.dbase, lowest); err != nil {
+			return err
+		}
+		p.lowest = lowest
+	}
+	if err := block.SetLowestStateHeight(p.dbase, from); err != nil {
+		return err
+	}
+
+	headers, height, err := p.openHistory(pruningHistoryDB, p.base)
+	if err != nil {
+		return err
+	}
+	defer headers.Close()
+	if err := p.markHistory(headers, height+1, to, 0, p.markHeader); err != nil {
+		return err
+	}
+	bodies, height, err := p.openHistory(pruningBodiesDB, lowest)
+	if err != nil {
+		return err
+	}
+	defer bodies.Close()
+	if err := p.markHistory(bodies, height+1, to, 10, p.markBody); err != nil {
+		return err
+	}
+
+	if err := p.removeDatabase(pruningMarksDB); err != nil {
+		return err
+	}
+	marks, err := p.openDatabase(pruningMarksDB)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		marks.Close()
+		if err := p.removeDatabase(pruningMarksDB); err != nil && rerr == nil {
+			rerr = err
+		}
+	}()
+	if err := p.markResults(marks, from, to); err != nil {
+		return err
+	}
+
+	entries, size := 0, 0
+	for i, id := range prunableBuckets {
+		cnt, bytes, err := p.sweep(id, 40+i*30, headers, bodies, marks)
+		entries, size = entries+cnt, size+bytes
+		if err != nil {
+			return err
+		}
+	}
+	duration := time.Since(startTS)
+	p.metric.OnFinish(duration)
+	p.last = to
+	p.log.Infof("Pruning done history=[%d,%d] retained=[%d,%d] entries=%d bytes=%d duration=%s",
+		lowest, to, from, to, entries, size, duration)
+	return nil
+}
+
+// needPruning returns whether the results out of the retention or the
+// history below the horizon are there.
+func (p *statePruner) needPruning(last module.Block) bool {
+	if p.retention > 0 && last.Height()-p.last >= p.retention {
+		return true
+	}
+	lowest, err := p.nextLowest(last)
+	if err != nil {
+		if !p.termWarned {
+			p.log.Warnf("Fail to get the lowest height of the history err=%+v", err)
+			p.termWarned = true
+		}
+		return false
+	}
+	return lowest > p.lowest
+}
+
+func (p *statePruner) run() {
+	defer close(p.done)
+	ticker := time.NewTicker(pruningCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+		}
+		blk, err := p.bg.GetLastBlock()
+		if err != nil || !p.needPruning(blk) {
+			continue
+		}
+		if err := p.prune(); err != nil {
+			if errors.Is(err, errors.ErrInterrupted) {
+				return
+			}
+			p.log.Warnf("Fail to prune states err=%+v", err)
+		}
+	}
+}
+
+func (p *statePruner) Start() {
+	go p.run()
+}
+
+// Stop stops the pruning, and waits for it to be finished.
+func (p *statePruner) Stop() {
+	close(p.stop)
+	<-p.done
+}
+
+func newStatePruner(
+	dbase *prunableDatabase, bg blockGetter, plt base.Platform,
+	dir, dbType string, baseHeight, retention, history, terms int64,
+	logger log.Logger, m *metric.PruningMetric,
+) *statePruner {
+	lowest := block.GetLowestHeightOf(dbase)
+	if lowest < baseHeight {
+		lowest = baseHeight
+	}
+	return &statePruner{
+		dbase:     dbase,
+		bg:        bg,
+		plt:       plt,
+		dir:       dir,
+		dbType:    dbType,
+		base:      baseHeight,
+		retention: retention,
+		history:   history,
+		terms:     terms,
+		log:       logger,
+		metric:    m,
+		last:      baseHeight - 1,
+		lowest:    lowest,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
@@ -0,0 +1,301 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package chain
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/block"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/common/merkle"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/server/metric"
+	"github.com/icon-project/goloop/service"
+	"github.com/icon-project/goloop/service/platform/basic"
+	"github.com/icon-project/goloop/service/state"
+)
+
+type testVoteSet struct {
+	module.CommitVoteSet
+}
+
+func (v testVoteSet) Hash() []byte {
+	return nil
+}
+
+type testTxList struct {
+	module.TransactionList
+}
+
+func (l testTxList) Hash() []byte {
+	return nil
+}
+
+type testPrunerBlock struct {
+	module.Block
+	height int64
+	id     []byte
+	result []byte
+}
+
+func (b *testPrunerBlock) Height() int64                              { return b.height }
+func (b *testPrunerBlock) ID() []byte                                 { return b.id }
+func (b *testPrunerBlock) Result() []byte                             { return b.result }
+func (b *testPrunerBlock) Votes() module.CommitVoteSet                { return testVoteSet{} }
+func (b *testPrunerBlock) NextValidatorsHash() []byte                 { return nil }
+func (b *testPrunerBlock) PatchTransactions() module.TransactionList  { return testTxList{} }
+func (b *testPrunerBlock) NormalTransactions() module.TransactionList { return testTxList{} }
+
+type testPrunerChain struct {
+	dbase  db.Database
+	ws     state.WorldState
+	blocks []*testPrunerBlock
+}
+
+func (c *testPrunerChain) GetLastBlock() (module.Block, error) {
+	return c.blocks[len(c.blocks)-1], nil
+}
+
+func (c *testPrunerChain) GetBlockByHeight(height int64) (module.Block, error) {
+	if height < 0 || height >= int64(len(c.blocks)) {
+		return nil, errors.NotFoundError.Errorf("NoBlock(height=%d)", height)
+	}
+	return c.blocks[height], nil
+}
+
+// addBlock changes the balances of the accounts, then adds the block
+// with the result.
+func (c *testPrunerChain) addBlock(t *testing.T) {
+	height := int64(len(c.blocks))
+	for i := 0; i < 10; i++ {
+		as := c.ws.GetAccountState(crypto.SHA3Sum256([]byte{byte(i)}))
+		as.SetBalance(big.NewInt(height*10 + int64(i)))
+	}
+	wss := c.ws.GetSnapshot()
+	assert.NoError(t, wss.Flush())
+
+	header := codec.BC.MustMarshalToBytes(height)
+	id := crypto.SHA3Sum256(header)
+	bk, _ := c.dbase.GetBucket(db.BytesByHash)
+	assert.NoError(t, bk.Set(id, header))
+
+	c.blocks = append(c.blocks, &testPrunerBlock{
+		height: height,
+		id:     id,
+		result: codec.BC.MustMarshalToBytes([][]byte{wss.StateHash(), nil, nil}),
+	})
+}
+
+func (c *testPrunerChain) exportResult(height int64) error {
+	ctx := merkle.NewCopyContext(c.dbase, db.NewMapDB())
+	err := service.RequestResult(ctx.Builder(), basic.Platform, c.blocks[height].result, nil)
+	if err != nil {
+		return err
+	}
+	return ctx.Run()
+}
+
+func countKeys(t *testing.T, dbase db.Database, id db.BucketID) int {
+	bk, err := dbase.GetBucket(id)
+	assert.NoError(t, err)
+	it, err := db.NewIterator(bk, nil, nil, nil)
+	assert.NoError(t, err)
+	defer it.Release()
+	cnt := 0
+	for it.Next() {
+		cnt += 1
+	}
+	return cnt
+}
+
+func TestStatePruner_Prune(t *testing.T) {
+	pdb, err := newPrunableDatabase(db.NewMapDB())
+	assert.NoError(t, err)
+	c := &testPrunerChain{
+		dbase: pdb,
+		ws:    state.NewWorldState(pdb, nil, nil, nil, nil),
+	}
+	for i := 0; i < 5; i++ {
+		c.addBlock(t)
+	}
+
+	// garbage not reachable from any block
+	garbage := []byte("garbage")
+	bk, _ := pdb.GetBucket(db.BytesByHash)
+	assert.NoError(t, bk.Set(crypto.SHA3Sum256(garbage), garbage))
+
+	p := newStatePruner(pdb, c, basic.Platform, t.TempDir(),
+		string(db.GoLevelDBBackend), 0, 2, 0, 0, log.New(),
+		metric.NewPruningMetric(context.Background()))
+
+	nodes := countKeys(t, pdb, db.MerkleTrie)
+	assert.NoError(t, p.prune())
+	assert.Less(t, countKeys(t, pdb, db.MerkleTrie), nodes)
+	assert.EqualValues(t, 4, p.last)
+	assert.EqualValues(t, 3, block.GetLowestStateHeightOf(pdb))
+
+	for height := int64(0); height < 5; height++ {
+		if height >= 3 {
+			assert.NoError(t, c.exportResult(height))
+		} else {
+			assert.Error(t, c.exportResult(height))
+		}
+		// headers are retained
+		v, err := bk.Get(c.blocks[height].id)
+		assert.NoError(t, err)
+		assert.NotNil(t, v)
+	}
+	v, err := bk.Get(crypto.SHA3Sum256(garbage))
+	assert.NoError(t, err)
+	assert.Nil(t, v)
+
+	// marks for the blocks are added only for the new blocks
+	c.addBlock(t)
+	assert.NoError(t, p.prune())
+	assert.NoError(t, c.exportResult(4))
+	assert.NoError(t, c.exportResult(5))
+	assert.Error(t, c.exportResult(3))
+	assert.EqualValues(t, 4, block.GetLowestStateHeightOf(pdb))
+	for _, blk := range c.blocks {
+		v, err := bk.Get(blk.id)
+		assert.NoError(t, err)
+		assert.NotNil(t, v)
+	}
+}
+
+func TestStatePruner_History(t *testing.T) {
+	pdb, err := newPrunableDatabase(db.NewMapDB())
+	assert.NoError(t, err)
+	c := &testPrunerChain{
+		dbase: pdb,
+		ws:    state.NewWorldState(pdb, nil, nil, nil, nil),
+	}
+	for i := 0; i < 6; i++ {
+		c.addBlock(t)
+	}
+
+	p := newStatePruner(pdb, c, basic.Platform, t.TempDir(),
+		string(db.GoLevelDBBackend), 0, 0, 3, 0, log.New(),
+		metric.NewPruningMetric(context.Background()))
+	assert.True(t, p.needPruning(c.blocks[5]))
+	assert.NoError(t, p.prune())
+	assert.False(t, p.needPruning(c.blocks[5]))
+
+	lowest, err := block.GetLowestHeight(pdb)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, lowest)
+	assert.EqualValues(t, 3, block.GetLowestStateHeightOf(pdb))
+
+	bk, _ := pdb.GetBucket(db.BytesByHash)
+	for height := int64(0); height < 6; height++ {
+		// results of all the blocks from the lowest are retained
+		if height >= lowest {
+			assert.NoError(t, c.exportResult(height))
+		} else {
+			assert.Error(t, c.exportResult(height))
+		}
+		// headers are retained
+		v, err := bk.Get(c.blocks[height].id)
+		assert.NoError(t, err)
+		assert.NotNil(t, v)
+	}
+
+	// the lowest height is restored on restart, and it moves with blocks
+	c.addBlock(t)
+	p = newStatePruner(pdb, c, basic.Platform, p.dir,
+		string(db.GoLevelDBBackend), 0, 0, 3, 0, log.New(),
+		metric.NewPruningMetric(context.Background()))
+	assert.EqualValues(t, 3, p.lowest)
+	assert.True(t, p.needPruning(c.blocks[6]))
+	assert.NoError(t, p.prune())
+	assert.EqualValues(t, 4, block.GetLowestHeightOf(pdb))
+	assert.Error(t, c.exportResult(3))
+	assert.NoError(t, c.exportResult(4))
+}
+
+func TestParseHistoryRetention(t *testing.T) {
+	tests := []struct {
+		value  string
+		blocks int64
+		terms  int64
+		ok     bool
+	}{
+		{"", 0, 0, true},
+		{"archive", 0, 0, true},
+		{"1000", 1000, 0, true},
+		{"3terms", 0, 3, true},
+		{"0", 0, 0, false},
+		{"-1", 0, 0, false},
+		{"terms", 0, 0, false},
+		{"10blocks", 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			blocks, terms, err := ParseHistoryRetention(tt.value)
+			if !tt.ok {
+				assert.Error(t, err)
+				assert.False(t, IsHistoryRetention(tt.value))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.blocks, blocks)
+			assert.Equal(t, tt.terms, terms)
+		})
+	}
+}
+
+func TestPrunableDatabase_Tracking(t *testing.T) {
+	pdb, err := newPrunableDatabase(db.NewMapDB())
+	assert.NoError(t, err)
+	bk, _ := pdb.GetBucket(db.MerkleTrie)
+
+	values := [][]byte{[]byte("v1"), []byte("v2"), []byte("v3")}
+	keys := make([][]byte, len(values))
+	for i, v := range values {
+		keys[i] = crypto.SHA3Sum256(v)
+		assert.NoError(t, bk.Set(keys[i], v))
+	}
+
+	pdb.startTracking()
+	ok, err := bk.Has(keys[0])
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	batch := pdb.NewBatch()
+	assert.NoError(t, batch.Set(db.MerkleTrie, keys[1], values[1]))
+	assert.NoError(t, batch.Write())
+
+	// only the entry not touched while tracking is deleted
+	cnt, size, err := pdb.deleteUntouched(db.MerkleTrie, keys, values)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, cnt)
+	assert.Equal(t, len(keys[2])+len(values[2]), size)
+	pdb.stopTracking()
+
+	for i, key := range keys {
+		ok, err := bk.Has(key)
+		assert.NoError(t, err)
+		assert.Equal(t, i != 2, ok)
+	}
+}
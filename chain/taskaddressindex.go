@@ -0,0 +1,163 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package chain
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/module"
+)
+
+const (
+	AddressIndexTask = "address_index"
+)
+
+var addressIndexStates = map[State]string{
+	Starting: "address index starting",
+	Stopping: "address index stopping",
+	Failed:   "address index failed",
+	Finished: "address index done",
+}
+
+type addressIndexParams struct {
+	Height int64 `json:"height,omitempty"`
+}
+
+// taskAddressIndex rebuilds the index of transactions by address from
+// the blocks in the database.
+type taskAddressIndex struct {
+	chain   *singleChain
+	result  resultStore
+	height  int64
+	last    int64
+	current int64
+	stop    int32
+}
+
+func (t *taskAddressIndex) String() string {
+	return fmt.Sprintf("AddressIndex(height=%d)", t.height)
+}
+
+func (t *taskAddressIndex) DetailOf(s State) string {
+	switch s {
+	case Started:
+		return fmt.Sprintf("address index %d/%d",
+			atomic.LoadInt64(&t.current), t.last)
+	default:
+		if st, ok := addressIndexStates[s]; ok {
+			return st
+		} else {
+			return s.String()
+		}
+	}
+}
+
+func (t *taskAddressIndex) Start() error {
+	c := t.chain
+	if err := c.prepareManagers(); err != nil {
+		return err
+	}
+	blk, err := c.bm.GetLastBlock()
+	if err != nil {
+		c.releaseManagers()
+		return err
+	}
+	base := c.cfg.GenesisStorage.Height()
+	if t.height < base {
+		t.height = base
+	}
+	// receipts of the last block are not finalized yet.
+	t.last = blk.Height() - 1
+	if t.height > t.last {
+		c.releaseManagers()
+		return errors.IllegalArgumentError.Errorf(
+			"InvalidHeight(height=%d,last=%d)", t.height, t.last)
+	}
+	atomic.StoreInt64(&t.current, t.height)
+	go t.doIndex()
+	return nil
+}
+
+func (t *taskAddressIndex) doIndex() {
+	err := t._index()
+	t.chain.releaseManagers()
+	t.result.SetValue(err)
+}
+
+func (t *taskAddressIndex) _index() error {
+	c := t.chain
+	idx := c.index
+	c.logger.Infof("Index transactions by address from=%d to=%d",
+		t.height, t.last)
+	for height := t.height; height <= t.last; height++ {
+		if atomic.LoadInt32(&t.stop) != 0 {
+			return errors.ErrInterrupted
+		}
+		blk, err := c.bm.GetBlockByHeight(height)
+		if err != nil {
+			return err
+		}
+		nblk, err := c.bm.GetBlockByHeight(height + 1)
+		if err != nil {
+			return err
+		}
+		prl, err := c.sm.ReceiptListFromResult(nblk.Result(), module.TransactionGroupPatch)
+		if err != nil {
+			return err
+		}
+		nrl, err := c.sm.ReceiptListFromResult(nblk.Result(), module.TransactionGroupNormal)
+		if err != nil {
+			return err
+		}
+		if err := idx.AddBlock(height,
+			blk.PatchTransactions(), prl,
+			blk.NormalTransactions(), nrl,
+		); err != nil {
+			return err
+		}
+		atomic.StoreInt64(&t.current, height)
+	}
+	return nil
+}
+
+func (t *taskAddressIndex) Stop() {
+	atomic.StoreInt32(&t.stop, 1)
+}
+
+func (t *taskAddressIndex) Wait() error {
+	return t.result.Wait()
+}
+
+func taskAddressIndexFactory(c *singleChain, params json.RawMessage) (chainTask, error) {
+	p := new(addressIndexParams)
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, p); err != nil {
+			return nil, err
+		}
+	}
+	return &taskAddressIndex{
+		chain:  c,
+		height: p.Height,
+	}, nil
+}
+
+func init() {
+	registerTaskFactory(AddressIndexTask, taskAddressIndexFactory)
+}
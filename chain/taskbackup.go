@@ -18,6 +18,8 @@ package chain
 
 import (
 	"archive/zip"
+	"bytes"
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -28,6 +30,7 @@ import (
 	"sort"
 	"sync/atomic"
 
+	"github.com/icon-project/goloop/block"
 	"github.com/icon-project/goloop/common"
 	"github.com/icon-project/goloop/common/codec"
 	"github.com/icon-project/goloop/common/errors"
@@ -35,12 +38,21 @@ import (
 
 const TemporalBackupFile = ".backup"
 
+// BackupBase identifies the base of an incremental backup.
+type BackupBase struct {
+	Name   string          `json:"name"`
+	Height int64           `json:"height"`
+	Hash   common.HexBytes `json:"hash"`
+}
+
 type BackupInfo struct {
 	NID     common.HexInt32 `json:"nid"`
 	CID     common.HexInt32 `json:"cid"`
 	Channel string          `json:"channel"`
 	Height  int64           `json:"height"`
+	Hash    common.HexBytes `json:"hash,omitempty"`
 	Codec   string          `json:"codec"`
+	Base    *BackupBase     `json:"base,omitempty"`
 }
 
 var backupStates = map[State]string{
@@ -51,18 +63,24 @@ var backupStates = map[State]string{
 }
 
 type taskBackup struct {
-	chain   *singleChain
-	file    string
-	extra   []string
-	fd      io.WriteCloser
-	zw      *zip.Writer
-	current int32
-	total   int32
-	stop    int32
-	result  resultStore
+	chain    *singleChain
+	file     string
+	extra    []string
+	base     string
+	fd       io.WriteCloser
+	zw       *zip.Writer
+	manifest *BackupManifest
+	current  int32
+	total    int32
+	stop     int32
+	result   resultStore
 }
 
 func (t *taskBackup) String() string {
+	if t.base != "" {
+		return fmt.Sprintf("Backup(file=%s,base=%s)",
+			path.Base(t.file), path.Base(t.base))
+	}
 	return fmt.Sprintf("Backup(file=%s)", path.Base(t.file))
 }
 
@@ -108,16 +126,27 @@ func (t *taskBackup) Start() (ret error) {
 		return err
 	}
 
-	t.fd = tmp
-	t.zw = zip.NewWriter(tmp)
-
-	if err := writeBackupInfo(t.zw, &BackupInfo{
+	info := &BackupInfo{
 		NID:     common.HexInt32{Value: int32(t.chain.NID())},
 		CID:     common.HexInt32{Value: int32(t.chain.CID())},
 		Channel: t.chain.Channel(),
 		Height:  t.chain.lastBlockHeight(),
 		Codec:   codec.BC.Name(),
-	}); err != nil {
+	}
+	if t.chain.database != nil {
+		info.Hash, _ = block.GetBlockHeaderHashByHeight(
+			t.chain.database, codec.BC, info.Height)
+	}
+	if t.base != "" {
+		if err := t._loadBase(info); err != nil {
+			return err
+		}
+	}
+
+	t.fd = tmp
+	t.zw = zip.NewWriter(tmp)
+
+	if err := writeBackupInfo(t.zw, info); err != nil {
 		return err
 	}
 
@@ -136,61 +165,117 @@ func (t *taskBackup) Start() (ret error) {
 	return nil
 }
 
-func zipWrite(writer *zip.Writer, p, n string, on func(int64) error) error {
+// _loadBase loads the manifest of the base backup after checking that the
+// base is a backup of the chain on the same blocks.
+func (t *taskBackup) _loadBase(info *BackupInfo) error {
+	if t.chain.database == nil {
+		return errors.InvalidStateError.New("NoDatabase")
+	}
+	bf, err := OpenBackup(t.base)
+	if err != nil {
+		return err
+	}
+	defer bf.Close()
+
+	base := bf.Info
+	if base.NID != info.NID || base.CID != info.CID ||
+		base.Channel != info.Channel || base.Codec != info.Codec {
+		return errors.IllegalArgumentError.Errorf(
+			"IncompatibleBase(base=%s)", bf.Name)
+	}
+	if len(base.Hash) == 0 {
+		return errors.IllegalArgumentError.Errorf(
+			"NoHashInBase(base=%s)", bf.Name)
+	}
+	if base.Height > info.Height {
+		return errors.IllegalArgumentError.Errorf(
+			"InvalidBaseHeight(base=%d,last=%d)", base.Height, info.Height)
+	}
+	hash, err := block.GetBlockHeaderHashByHeight(
+		t.chain.database, codec.BC, base.Height)
+	if err != nil || !bytes.Equal(hash, base.Hash) {
+		return errors.IllegalArgumentError.Errorf(
+			"BlockMismatch(base=%s,height=%d)", bf.Name, base.Height)
+	}
+	info.Base = &BackupBase{
+		Name:   bf.Name,
+		Height: base.Height,
+		Hash:   base.Hash,
+	}
+	t.manifest = bf.Manifest
+	return nil
+}
+
+func walkFiles(p, n string, on func(n string, st fs.FileInfo) error) error {
 	p2 := path.Join(p, n)
 	st, err := os.Stat(p2)
 	if errors.Is(err, fs.ErrNotExist) {
 		return nil
 	} else if err != nil {
-		return errors.Wrap(err, "writeToZip: FAIL on os.State")
+		return errors.Wrap(err, "walkFiles: FAIL on os.State")
 	}
 	if st.Mode().IsRegular() {
-		fd, err := os.Open(p2)
-		defer fd.Close()
-		if err != nil {
-			return errors.Wrapf(err, "writeToZip: fail to open %s", p2)
-		}
-
-		fh, err := zip.FileInfoHeader(st)
-		if err != nil {
-			return errors.Wrapf(err, "writeToZip: fail to make header for %s", p2)
-		}
-		fh.Name = n
-		fh.Method = zip.Deflate
-		zf, err := writer.CreateHeader(fh)
-		if err != nil {
-			return err
-		}
-		if err != nil {
-			return errors.Wrapf(err, "writeToZip: fail to create entry %s", n)
-		}
-		if _, err := io.Copy(zf, fd); err != nil {
-			return errors.Wrap(err, "writeToZip: fail to copy")
-		}
-		if err := on(st.Size()); err != nil {
-			return err
-		}
-		return nil
+		return on(n, st)
 	} else if !st.IsDir() {
 		return nil
 	}
 
 	fis, err := ioutil.ReadDir(p2)
 	if err != nil {
-		return errors.Wrap(err, "writeToZip: FAIL on ReadDir")
+		return errors.Wrap(err, "walkFiles: FAIL on ReadDir")
 	}
 	// make it generate consistent compressed zip file.
 	sort.SliceStable(fis, func(i, j int) bool {
 		return fis[i].Name() < fis[j].Name()
 	})
 	for _, fi := range fis {
-		if err := zipWrite(writer, p, path.Join(n, fi.Name()), on); err != nil {
+		if err := walkFiles(p, path.Join(n, fi.Name()), on); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+func entryOf(p string) (*BackupEntry, error) {
+	fd, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+	h := sha256.New()
+	size, err := io.Copy(h, fd)
+	if err != nil {
+		return nil, err
+	}
+	return &BackupEntry{Size: size, SHA256: h.Sum(nil)}, nil
+}
+
+func zipWrite(writer *zip.Writer, p, n string, st fs.FileInfo) (*BackupEntry, error) {
+	p2 := path.Join(p, n)
+	fd, err := os.Open(p2)
+	if err != nil {
+		return nil, errors.Wrapf(err, "writeToZip: fail to open %s", p2)
+	}
+	defer fd.Close()
+
+	fh, err := zip.FileInfoHeader(st)
+	if err != nil {
+		return nil, errors.Wrapf(err, "writeToZip: fail to make header for %s", p2)
+	}
+	fh.Name = n
+	fh.Method = zip.Deflate
+	zf, err := writer.CreateHeader(fh)
+	if err != nil {
+		return nil, errors.Wrapf(err, "writeToZip: fail to create entry %s", n)
+	}
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(zf, h), fd)
+	if err != nil {
+		return nil, errors.Wrap(err, "writeToZip: fail to copy")
+	}
+	return &BackupEntry{Size: size, SHA256: h.Sum(nil)}, nil
+}
+
 func countFiles(p string) (int, error) {
 	st, err := os.Stat(p)
 	if errors.Is(err, fs.ErrNotExist) {
@@ -260,13 +345,34 @@ func (t *taskBackup) _backup() error {
 		t.total = int32(cnt)
 	}
 
+	// files same as the base are recorded only in the manifest.
+	base := t.manifest
+	t.manifest = newBackupManifest()
 	for _, name := range names {
-		if err := zipWrite(t.zw, chainDir, name, t.OnWrite); err != nil {
+		err := walkFiles(chainDir, name, func(n string, st fs.FileInfo) error {
+			if be := base.entryOf(n); be != nil && be.Size == st.Size() {
+				e, err := entryOf(path.Join(chainDir, n))
+				if err != nil {
+					return err
+				}
+				if e.Equal(be) {
+					t.manifest.Files[n] = e
+					return t.OnWrite(0)
+				}
+			}
+			e, err := zipWrite(t.zw, chainDir, n, st)
+			if err != nil {
+				return err
+			}
+			t.manifest.Files[n] = e
+			return t.OnWrite(e.Size)
+		})
+		if err != nil {
 			return err
 		}
 	}
 
-	return nil
+	return writeBackupManifest(t.zw, t.manifest)
 }
 
 func (t *taskBackup) Stop() {
@@ -283,11 +389,12 @@ func (t *taskBackup) Wait() error {
 	return t.result.Wait()
 }
 
-func newTaskBackup(chain *singleChain, file string, extra []string) chainTask {
+func newTaskBackup(chain *singleChain, file string, extra []string, base string) chainTask {
 	return &taskBackup{
 		chain: chain,
 		file:  file,
 		extra: extra,
+		base:  base,
 	}
 }
 
@@ -66,11 +66,13 @@ func (t *taskConsensus) _start(c *singleChain) error {
 	if err := c.nm.Start(); err != nil {
 		return err
 	}
+	c.startPruner()
 	return nil
 }
 
 func (t *taskConsensus) Stop() {
 	t.chain.srv.RemoveChain(t.chain.cfg.Channel)
+	t.chain.stopPruner()
 	t.chain.releaseManagers()
 	t.result.SetValue(errors.ErrInterrupted)
 }
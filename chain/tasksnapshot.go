@@ -0,0 +1,513 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package chain
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"sync/atomic"
+
+	"github.com/icon-project/goloop/block"
+	"github.com/icon-project/goloop/chain/gs"
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/common/merkle"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service"
+)
+
+const (
+	SnapshotExportTask = "snapshot_export"
+	SnapshotImportTask = "snapshot_import"
+)
+
+var snapshotExportStates = map[State]string{
+	Starting: "snapshot export starting",
+	Stopping: "snapshot export stopping",
+	Failed:   "snapshot export failed",
+	Finished: "snapshot export done",
+}
+
+type snapshotExportParams struct {
+	File   string `json:"file"`
+	Height int64  `json:"height,omitempty"`
+}
+
+// taskSnapshotExport exports the world state, the validators and the
+// receipts of the finalized block with the blocks required to continue
+// from the block.
+type taskSnapshotExport struct {
+	chain   *singleChain
+	result  resultStore
+	file    string
+	height  int64
+	entries int64
+	stop    int32
+}
+
+func (t *taskSnapshotExport) String() string {
+	return fmt.Sprintf("SnapshotExport(file=%s,height=%d)",
+		path.Base(t.file), t.height)
+}
+
+func (t *taskSnapshotExport) DetailOf(s State) string {
+	switch s {
+	case Started:
+		return fmt.Sprintf("snapshot export %d entries",
+			atomic.LoadInt64(&t.entries))
+	default:
+		if st, ok := snapshotExportStates[s]; ok {
+			return st
+		} else {
+			return s.String()
+		}
+	}
+}
+
+func (t *taskSnapshotExport) Start() error {
+	c := t.chain
+	if len(t.file) == 0 {
+		return errors.IllegalArgumentError.New("NoSnapshotFile")
+	}
+	if err := c.prepareManagers(); err != nil {
+		return err
+	}
+	blk, err := c.bm.GetLastBlock()
+	if err != nil {
+		c.releaseManagers()
+		return err
+	}
+	// votes for the block are in the next block.
+	last := blk.Height() - 1
+	if t.height == 0 {
+		t.height = last
+	}
+	if t.height < c.cfg.GenesisStorage.Height() || t.height > last {
+		c.releaseManagers()
+		return errors.IllegalArgumentError.Errorf(
+			"InvalidHeight(height=%d,last=%d)", t.height, last)
+	}
+	go t.doExport()
+	return nil
+}
+
+func (t *taskSnapshotExport) doExport() {
+	err := t._export()
+	t.chain.releaseManagers()
+	t.result.SetValue(err)
+}
+
+func (t *taskSnapshotExport) _exportGenesis(blk module.Block, votes module.CommitVoteSet) ([]byte, error) {
+	buf := bytes.NewBuffer(nil)
+	gsw := gs.NewGenesisStorageWriter(buf)
+	if err := t.chain.bm.ExportGenesis(blk, votes, gsw); err != nil {
+		return nil, errors.Wrap(err, "fail on exporting genesis storage")
+	}
+	if err := gsw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (t *taskSnapshotExport) _export() (ret error) {
+	c := t.chain
+	blk, err := c.bm.GetBlockByHeight(t.height)
+	if err != nil {
+		return err
+	}
+	nblk, err := c.bm.GetBlockByHeight(t.height + 1)
+	if err != nil {
+		return err
+	}
+	stateHash, err := service.StateHashFromResult(blk.Result())
+	if err != nil {
+		return err
+	}
+	genesis, err := t._exportGenesis(blk, nblk.Votes())
+	if err != nil {
+		return err
+	}
+
+	tmp := t.file + TempSuffix
+	fd, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_EXCL|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if fd != nil {
+			fd.Close()
+		}
+		if ret != nil {
+			os.Remove(tmp)
+		}
+	}()
+
+	sw := newSnapshotWriter(fd)
+	sw.onChunk = func(entries int64) error {
+		if atomic.LoadInt32(&t.stop) != 0 {
+			return errors.ErrInterrupted
+		}
+		atomic.StoreInt64(&t.entries, entries)
+		return nil
+	}
+	sw.header.NID = common.HexInt32{Value: int32(c.NID())}
+	sw.header.CID = common.HexInt32{Value: int32(c.CID())}
+	sw.header.Channel = c.Channel()
+	sw.header.Height = t.height
+	sw.header.Block = blk.ID()
+	sw.header.StateHash = stateHash
+	sw.header.Codec = codec.BC.Name()
+
+	if err := sw.writeFile(SnapshotGenesisFile, genesis); err != nil {
+		return err
+	}
+	c.logger.Infof("Export snapshot file=%s height=%d", t.file, t.height)
+	if err := c.bm.ExportBlocks(t.height, t.height, &snapshotDatabase{sw}, nil); err != nil {
+		return err
+	}
+	if err := sw.Close(); err != nil {
+		return err
+	}
+	c.logger.Infof("Snapshot exported entries=%d chunks=%d",
+		sw.header.Entries, len(sw.header.Chunks))
+	err = fd.Close()
+	fd = nil
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, t.file)
+}
+
+func (t *taskSnapshotExport) Stop() {
+	atomic.StoreInt32(&t.stop, 1)
+}
+
+func (t *taskSnapshotExport) Wait() error {
+	return t.result.Wait()
+}
+
+func taskSnapshotExportFactory(c *singleChain, params json.RawMessage) (chainTask, error) {
+	p := new(snapshotExportParams)
+	if err := json.Unmarshal(params, p); err != nil {
+		return nil, err
+	}
+	return &taskSnapshotExport{
+		chain:  c,
+		file:   c.cfg.ResolveAbsolute(p.File),
+		height: p.Height,
+	}, nil
+}
+
+var snapshotImportStates = map[State]string{
+	Starting: "snapshot import starting",
+	Stopping: "snapshot import stopping",
+	Failed:   "snapshot import failed",
+	Finished: "snapshot import done",
+}
+
+type snapshotImportParams struct {
+	File   string `json:"file"`
+	DBType string `json:"db_type,omitempty"`
+}
+
+// taskSnapshotImport replaces the database of the chain with the one built
+// from the snapshot. It verifies all entries for the block from its result
+// before replacing the database.
+type taskSnapshotImport struct {
+	chain    *singleChain
+	result   resultStore
+	file     string
+	dbtype   string
+	snapshot *SnapshotFile
+	current  int32
+	stop     int32
+}
+
+func (t *taskSnapshotImport) String() string {
+	return fmt.Sprintf("SnapshotImport(file=%s,dbtype=%s)",
+		path.Base(t.file), t.dbtype)
+}
+
+func (t *taskSnapshotImport) DetailOf(s State) string {
+	switch s {
+	case Started:
+		return fmt.Sprintf("snapshot import %d/%d",
+			atomic.LoadInt32(&t.current), len(t.snapshot.Header.Chunks))
+	default:
+		if st, ok := snapshotImportStates[s]; ok {
+			return st
+		} else {
+			return s.String()
+		}
+	}
+}
+
+func (t *taskSnapshotImport) Start() error {
+	c := t.chain
+	s, err := OpenSnapshot(t.file)
+	if err != nil {
+		return err
+	}
+	h := s.Header
+	if int(h.NID.Value) != c.NID() || int(h.CID.Value) != c.CID() {
+		s.Close()
+		return errors.IllegalArgumentError.Errorf(
+			"InvalidChain(nid=%#x,cid=%#x)", h.NID.Value, h.CID.Value)
+	}
+	if h.Codec != codec.BC.Name() {
+		s.Close()
+		return errors.IllegalArgumentError.Errorf(
+			"IncompatibleCodec(snapshot=%s,system=%s)",
+			h.Codec, codec.BC.Name())
+	}
+	t.snapshot = s
+	go t.doImport()
+	return nil
+}
+
+func (t *taskSnapshotImport) doImport() {
+	err := t._import()
+	t.snapshot.Close()
+	t.result.SetValue(err)
+}
+
+func (t *taskSnapshotImport) _writeChunks(dbase db.Database) error {
+	if atomic.LoadInt32(&t.stop) != 0 {
+		return errors.ErrInterrupted
+	}
+	return t.snapshot.WriteTo(dbase, func(idx int) error {
+		atomic.StoreInt32(&t.current, int32(idx+1))
+		if atomic.LoadInt32(&t.stop) != 0 {
+			return errors.ErrInterrupted
+		}
+		return nil
+	})
+}
+
+// verifierDatabase has nothing, so the builder requests all entries to
+// the source with verifying them.
+type verifierDatabase struct{}
+
+func (d verifierDatabase) GetBucket(id db.BucketID) (db.Bucket, error) {
+	return d, nil
+}
+
+func (d verifierDatabase) Close() error {
+	return nil
+}
+
+func (d verifierDatabase) Get(key []byte) ([]byte, error) {
+	return nil, nil
+}
+
+func (d verifierDatabase) Has(key []byte) (bool, error) {
+	return false, nil
+}
+
+func (d verifierDatabase) Set(key []byte, value []byte) error {
+	return nil
+}
+
+func (d verifierDatabase) Delete(key []byte) error {
+	return nil
+}
+
+func (t *taskSnapshotImport) _verify(dbase db.Database, genesis module.GenesisStorage) error {
+	h := t.snapshot.Header
+	if last, err := block.GetLastHeight(dbase); err != nil || last != h.Height {
+		return errors.InvalidStateError.Errorf(
+			"InvalidLastHeight(exp=%d,real=%d)", h.Height, last)
+	}
+	hash, err := block.GetBlockHeaderHashByHeight(dbase, codec.BC, h.Height)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(hash, h.Block) {
+		return errors.InvalidStateError.Errorf(
+			"InvalidBlock(exp=%#x,real=%#x)", []byte(h.Block), hash)
+	}
+	result, err := block.GetBlockResultByHeight(dbase, codec.BC, h.Height)
+	if err != nil {
+		return err
+	}
+	stateHash, err := service.StateHashFromResult(result)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(stateHash, h.StateHash) {
+		return errors.InvalidStateError.Errorf(
+			"InvalidStateHash(exp=%#x,real=%#x)", []byte(h.StateHash), stateHash)
+	}
+	nvl, err := block.GetNextValidatorsByHeight(dbase, codec.BC, h.Height)
+	if err != nil {
+		return err
+	}
+
+	if gt, err := genesis.Type(); err != nil || gt != module.GenesisPruned {
+		return errors.InvalidStateError.Errorf("InvalidGenesisType(type=%d)", gt)
+	}
+	pg, err := gs.NewPrunedGenesis(genesis.Genesis())
+	if err != nil {
+		return err
+	}
+	if pg.Height.Value != h.Height || !bytes.Equal(pg.Block, h.Block) {
+		return errors.InvalidStateError.Errorf(
+			"InvalidGenesis(height=%d,block=%#x)", pg.Height.Value, []byte(pg.Block))
+	}
+
+	t.chain.logger.Infof("Verify snapshot height=%d stateHash=%s",
+		h.Height, h.StateHash)
+	ctx := merkle.NewCopyContext(dbase, verifierDatabase{})
+	if err := service.RequestResult(ctx.Builder(), t.chain.plt, result, nvl.Hash()); err != nil {
+		return err
+	}
+	return ctx.Run()
+}
+
+func (t *taskSnapshotImport) _buildDatabase(dbpath string, genesis module.GenesisStorage) (ret error) {
+	c := t.chain
+	os.RemoveAll(dbpath)
+	dbase, err := c.openDatabase(dbpath, t.dbtype)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		log.Must(dbase.Close())
+		if ret != nil {
+			os.RemoveAll(dbpath)
+		}
+	}()
+	c.logger.Infof("Import snapshot file=%s to=%s type=%s",
+		t.file, dbpath, t.dbtype)
+	if err := t._writeChunks(dbase); err != nil {
+		return err
+	}
+	return t._verify(dbase, genesis)
+}
+
+func (t *taskSnapshotImport) _import() (ret error) {
+	c := t.chain
+	chainDir := c.cfg.AbsBaseDir()
+
+	gsBytes, err := t.snapshot.Genesis()
+	if err != nil {
+		return err
+	}
+	genesis, err := gs.New(gsBytes)
+	if err != nil {
+		return err
+	}
+
+	dbpath := path.Join(chainDir, DefaultTmpDBDir)
+	if err := t._buildDatabase(dbpath, genesis); err != nil {
+		return err
+	}
+
+	var rb Revertible
+	c.releaseDatabase()
+	defer c.ensureDatabase()
+	defer func() {
+		rb.RevertOrCommit(ret != nil)
+	}()
+	rb.Append(func(revert bool) {
+		if revert {
+			log.Must(os.RemoveAll(dbpath))
+		}
+	})
+
+	dbDir := path.Join(chainDir, DefaultDBDir)
+	if err := rb.Delete(dbDir); err != nil {
+		return err
+	}
+	if err := rb.Rename(dbpath, dbDir); err != nil {
+		return err
+	}
+	for _, dir := range []string{DefaultContractDir, DefaultWALDir, DefaultCacheDir} {
+		if err := rb.Delete(path.Join(chainDir, dir)); err != nil {
+			return err
+		}
+	}
+
+	gsfile := path.Join(chainDir, DefaultGenesisFile)
+	if err := rb.Delete(gsfile); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(gsfile, gsBytes, 0600); err != nil {
+		return err
+	}
+	rb.Append(func(revert bool) {
+		if revert {
+			log.Must(os.Remove(gsfile))
+		}
+	})
+
+	cfg := c.cfg
+	dbtype, gstorage, gtx := cfg.DBType, cfg.GenesisStorage, cfg.Genesis
+	cfg.DBType = t.dbtype
+	cfg.GenesisStorage = genesis
+	cfg.Genesis = genesis.Genesis()
+	rb.Append(func(revert bool) {
+		if revert {
+			cfg.DBType, cfg.GenesisStorage, cfg.Genesis = dbtype, gstorage, gtx
+		}
+	})
+	if err := cfg.Save(); err != nil {
+		return errors.UnknownError.Wrap(err, "fail to store configuration")
+	}
+	c.logger.Infof("Snapshot imported height=%d block=%s",
+		t.snapshot.Header.Height, t.snapshot.Header.Block)
+	return nil
+}
+
+func (t *taskSnapshotImport) Stop() {
+	atomic.StoreInt32(&t.stop, 1)
+}
+
+func (t *taskSnapshotImport) Wait() error {
+	return t.result.Wait()
+}
+
+func taskSnapshotImportFactory(c *singleChain, params json.RawMessage) (chainTask, error) {
+	p := new(snapshotImportParams)
+	if err := json.Unmarshal(params, p); err != nil {
+		return nil, err
+	}
+	if len(p.File) == 0 {
+		return nil, errors.IllegalArgumentError.New("NoSnapshotFile")
+	}
+	dbtype := p.DBType
+	if dbtype == "" {
+		dbtype = c.cfg.DBType
+	}
+	return &taskSnapshotImport{
+		chain:  c,
+		file:   c.cfg.ResolveAbsolute(p.File),
+		dbtype: dbtype,
+	}, nil
+}
+
+func init() {
+	registerTaskFactory(SnapshotExportTask, taskSnapshotExportFactory)
+	registerTaskFactory(SnapshotImportTask, taskSnapshotImportFactory)
+}
@@ -118,6 +118,16 @@ type EventLog struct {
 	Data    []*string       `json:"data"`
 }
 
+//refer server/eventlogs.go EventLogEntry
+type EventLogEntry struct {
+	BlockHeight jsonrpc.HexInt   `json:"blockHeight"`
+	BlockHash   jsonrpc.HexBytes `json:"blockHash"`
+	TxIndex     jsonrpc.HexInt   `json:"txIndex"`
+	TxHash      jsonrpc.HexBytes `json:"txHash"`
+	LogIndex    jsonrpc.HexInt   `json:"logIndex"`
+	Log         EventLog         `json:"log"`
+}
+
 //refer service/txresult/receipt.go:193 failureReason
 type FailureReason struct {
 	CodeValue    jsonrpc.HexInt `json:"code"`
@@ -171,6 +181,15 @@ func (c *ClientV3) GetLastBlock() (*Block, error) {
 	return blk, nil
 }
 
+func (c *ClientV3) GetLowestBlock() (*Block, error) {
+	blk := &Block{}
+	_, err := c.Do("icx_getLowestBlock", nil, blk)
+	if err != nil {
+		return nil, err
+	}
+	return blk, nil
+}
+
 func (c *ClientV3) GetBlockByHeight(param *v3.BlockHeightParam) (*Block, error) {
 	blk := &Block{}
 	_, err := c.Do("icx_getBlockByHeight", param, blk)
@@ -412,6 +431,15 @@ func (c *ClientV3) GetScoreStatus(param *v3.ScoreAddressParam) (interface{}, err
 	return result, nil
 }
 
+func (c *ClientV3) GetLogs(param *server.LogsRequest) ([]*EventLogEntry, error) {
+	var result []*EventLogEntry
+	_, err := c.Do("icx_getLogs", param, &result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 func (c *ClientV3) MonitorBlock(param *server.BlockRequest, cb func(v *server.BlockNotification), cancelCh <-chan bool) error {
 	resp := &server.BlockNotification{}
 	return c.Monitor("/block", param, resp, func(v interface{}) {
@@ -554,3 +582,21 @@ func (c *ClientV3) EstimateStep(param *v3.TransactionParamForEstimate) (*common.
 	}
 	return &result, nil
 }
+
+type SimulationResult struct {
+	Receipt        interface{}   `json:"receipt"`
+	BalanceChanges []interface{} `json:"balanceChanges"`
+}
+
+func (c *ClientV3) SimulateTransaction(param *v3.TransactionParamForSimulate) (*SimulationResult, error) {
+	if len(c.DebugEndPoint) == 0 {
+		return nil, errors.InvalidStateError.New("UnavailableDebugEndPoint")
+	}
+	param.Timestamp = jsonrpc.HexInt(intconv.FormatInt(time.Now().UnixNano() / int64(time.Microsecond)))
+	result := &SimulationResult{}
+	if _, err := c.DoURL(c.DebugEndPoint,
+		"debug_simulateTransaction", param, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
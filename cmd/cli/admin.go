@@ -146,6 +146,11 @@ func NewChainCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Comman
 				param.NephewsLimit = &nephewsLimit
 			}
 			param.ValidateTxOnSend, _ = fs.GetBool("validate_tx_on_send")
+			param.AddressIndex, _ = fs.GetBool("address_index")
+			param.MaxLogsRange, _ = fs.GetInt64("max_logs_range")
+			param.MaxLogsResults, _ = fs.GetInt("max_logs_results")
+			param.StateRetention, _ = fs.GetInt64("state_retention")
+			param.HistoryRetention, _ = fs.GetString("history_retention")
 
 			var buf *bytes.Buffer
 			if len(genesisZip) > 0 {
@@ -203,6 +208,11 @@ func NewChainCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Comman
 	joinFlags.Int("children_limit", -1, "Maximum number of child connections (-1: uses system default value)")
 	joinFlags.Int("nephews_limit", -1, "Maximum number of nephew connections (-1: uses system default value)")
 	joinFlags.Bool("validate_tx_on_send", false, "Validate transaction on send")
+	joinFlags.Bool("address_index", false, "Index transactions by address")
+	joinFlags.Int64("max_logs_range", 0, "Maximum range of block heights for icx_getLogs (0: uses system default value)")
+	joinFlags.Int("max_logs_results", 0, "Maximum number of event logs for icx_getLogs (0: uses system default value)")
+	joinFlags.Int64("state_retention", 0, "Number of recent blocks whose states are retained by online pruning (0: no pruning)")
+	joinFlags.String("history_retention", chain.HistoryRetentionArchive, "History retained by online pruning (archive: all, N: last N blocks, Nterms: last N terms)")
 
 	leaveCmd := &cobra.Command{
 		Use:   "leave CID",
@@ -392,8 +402,12 @@ func NewChainCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Comman
 		RunE: func(cmd *cobra.Command, args []string) error {
 			fs := cmd.Flags()
 			manual, _ := fs.GetBool("manual")
+			btype, _ := fs.GetString("type")
+			base, _ := fs.GetString("base")
 			param := &node.ChainBackupParam{
 				Manual: manual,
+				Type:   btype,
+				Base:   base,
 			}
 			var v string
 			reqUrl := node.UrlChain + "/" + args[0] + "/backup"
@@ -408,6 +422,60 @@ func NewChainCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Comman
 	rootCmd.AddCommand(backupCmd)
 	backupFlags := backupCmd.Flags()
 	backupFlags.Bool("manual", false, "Manual backup mode (just release database)")
+	backupFlags.String("type", "full", "Backup type(full,incremental,differential)")
+	backupFlags.String("base", "", "Name of the base backup (default:latest one for the type)")
+
+	snapshotExportCmd := &cobra.Command{
+		Use:   "snapshot_export CID",
+		Short: "Start to export the state snapshot at the height",
+		Args:  ArgsWithDefaultErrorFunc(cobra.ExactArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fs := cmd.Flags()
+			param := &node.ChainSnapshotExportParam{}
+			param.File, _ = fs.GetString("file")
+			param.Height, _ = fs.GetInt64("height")
+
+			var v string
+			reqUrl := node.UrlChain + "/" + args[0] + "/snapshot_export"
+			_, err := adminClient.PostWithJson(reqUrl, param, &v)
+			if err != nil {
+				return err
+			}
+			fmt.Println(v)
+			return nil
+		},
+	}
+	rootCmd.AddCommand(snapshotExportCmd)
+	snapshotExportFlags := snapshotExportCmd.Flags()
+	snapshotExportFlags.String("file", "", "Snapshot file to write(relative to the chain directory)")
+	snapshotExportFlags.Int64("height", 0, "Block Height(default:the last block having votes)")
+	MarkAnnotationRequired(snapshotExportFlags, "file")
+
+	snapshotImportCmd := &cobra.Command{
+		Use:   "snapshot_import CID",
+		Short: "Start to replace the chain data with the state snapshot",
+		Args:  ArgsWithDefaultErrorFunc(cobra.ExactArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fs := cmd.Flags()
+			param := &node.ChainSnapshotImportParam{}
+			param.File, _ = fs.GetString("file")
+			param.DBType, _ = fs.GetString("db_type")
+
+			var v string
+			reqUrl := node.UrlChain + "/" + args[0] + "/snapshot_import"
+			_, err := adminClient.PostWithJson(reqUrl, param, &v)
+			if err != nil {
+				return err
+			}
+			fmt.Println(v)
+			return nil
+		},
+	}
+	rootCmd.AddCommand(snapshotImportCmd)
+	snapshotImportFlags := snapshotImportCmd.Flags()
+	snapshotImportFlags.String("file", "", "Snapshot file to import(relative to the chain directory)")
+	snapshotImportFlags.String("db_type", "", "Database type(default:original database type)")
+	MarkAnnotationRequired(snapshotImportFlags, "file")
 
 	genesisCmd := &cobra.Command{
 		Use:   "genesis CID FILE",
@@ -480,6 +548,71 @@ func NewChainCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Comman
 	configFlags.String("value", "", "use if value starts with '-'.\n"+
 		"(if the third arg is used, this flag will be ignored)")
 
+	peersCmd := &cobra.Command{
+		Use:   "peers CID",
+		Short: "List scores and bans of peers",
+		Args:  ArgsWithDefaultErrorFunc(cobra.ExactArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			reqUrl := node.UrlChain + "/" + args[0] + "/peers"
+			var l []*node.PeerScoreView
+			resp, err := adminClient.Get(reqUrl, &l)
+			if err != nil {
+				return err
+			}
+			if err = JsonPrettyPrintln(os.Stdout, l); err != nil {
+				return errors.Errorf("failed JsonIntend resp=%+v, err=%+v", resp, err)
+			}
+			return nil
+		},
+	}
+	rootCmd.AddCommand(peersCmd)
+
+	banCmd := &cobra.Command{
+		Use:   "ban CID PEER",
+		Short: "Ban the peer temporarily",
+		Args:  ArgsWithDefaultErrorFunc(cobra.ExactArgs(2)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fs := cmd.Flags()
+			duration, _ := fs.GetString("duration")
+			reason, _ := fs.GetString("reason")
+			param := &node.ChainBanParam{
+				Peer:     args[1],
+				Duration: duration,
+				Reason:   reason,
+			}
+			var v string
+			reqUrl := node.UrlChain + "/" + args[0] + "/ban"
+			if _, err := adminClient.PostWithJson(reqUrl, param, &v); err != nil {
+				return err
+			}
+			fmt.Println(v)
+			return nil
+		},
+	}
+	rootCmd.AddCommand(banCmd)
+	banFlags := banCmd.Flags()
+	banFlags.String("duration", "1h", "Duration of the ban")
+	banFlags.String("reason", "", "Reason of the ban")
+
+	unbanCmd := &cobra.Command{
+		Use:   "unban CID PEER",
+		Short: "Remove the ban of the peer",
+		Args:  ArgsWithDefaultErrorFunc(cobra.ExactArgs(2)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			param := &node.ChainUnbanParam{
+				Peer: args[1],
+			}
+			var v string
+			reqUrl := node.UrlChain + "/" + args[0] + "/unban"
+			if _, err := adminClient.PostWithJson(reqUrl, param, &v); err != nil {
+				return err
+			}
+			fmt.Println(v)
+			return nil
+		},
+	}
+	rootCmd.AddCommand(unbanCmd)
+
 	rootCmd.Use = "chain TASK CID PARAM"
 	rootCmd.Args = ArgsWithDefaultErrorFunc(cobra.ExactArgs(3))
 	rootCmd.RunE = func(cmd *cobra.Command, args []string) error {
@@ -596,6 +729,25 @@ func NewBackupCmd(parent *cobra.Command, client *node.UnixDomainSockHttpClient)
 		},
 	}
 	rootCmd.AddCommand(listCmd)
+
+	verifyCmd := &cobra.Command{
+		Use:   "verify NAME",
+		Short: "Verify the backup with its bases",
+		Args:  ArgsWithDefaultErrorFunc(cobra.ExactArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			param := &node.VerifyBackupParam{
+				Name: args[0],
+			}
+			var v string
+			_, err := client.PostWithJson(node.UrlSystem+"/backup/verify", param, &v)
+			if err != nil {
+				return err
+			}
+			fmt.Println(v)
+			return nil
+		},
+	}
+	rootCmd.AddCommand(verifyCmd)
 }
 
 func NewRestoreCmd(parent *cobra.Command, client *node.UnixDomainSockHttpClient) {
@@ -0,0 +1,157 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/icon-project/goloop/btp/relay"
+	"github.com/icon-project/goloop/client"
+	"github.com/icon-project/goloop/common/intconv"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/common/wallet"
+	"github.com/icon-project/goloop/module"
+)
+
+func loadRelayCursor(file string) (relay.Cursor, error) {
+	var c relay.Cursor
+	bs, err := ioutil.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c, nil
+		}
+		return c, fmt.Errorf("fail to read cursor file=%s err=%+v", file, err)
+	}
+	if err := json.Unmarshal(bs, &c); err != nil {
+		return c, fmt.Errorf("fail to parse cursor file=%s err=%+v", file, err)
+	}
+	return c, nil
+}
+
+func loadRelayWallet(vc *viper.Viper) (module.Wallet, error) {
+	var kb, pb []byte
+	var err error
+	ksf := vc.GetString("key_store")
+	if kb, err = ioutil.ReadFile(ksf); err != nil {
+		return nil, fmt.Errorf("fail to open KeyStore file=%s err=%+v", ksf, err)
+	}
+	ksec := vc.GetString("key_secret")
+	kpass := vc.GetString("key_password")
+	if ksec != "" {
+		if pb, err = ioutil.ReadFile(ksec); err != nil {
+			return nil, fmt.Errorf("fail to open KeySecret file=%s err=%+v", ksec, err)
+		}
+	} else if kpass != "" {
+		pb = []byte(kpass)
+	} else {
+		return nil, fmt.Errorf("there is no password information for the KeyStore, use --key_secret or --key_password")
+	}
+	w, err := wallet.NewFromKeyStore(kb, pb)
+	if err != nil {
+		return nil, fmt.Errorf("fail to create wallet err=%+v", err)
+	}
+	return w, nil
+}
+
+func NewBTPCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Command, *viper.Viper) {
+	rootCmd, vc := NewCommand(parentCmd, parentVc, "btp", "BTP utilities")
+
+	relayCmd := &cobra.Command{
+		Use:   "relay",
+		Short: "Relay BTP blocks of the network to the BMC in the destination chain",
+		Args:  ArgsWithDefaultErrorFunc(cobra.NoArgs),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return ValidateFlagsWithViper(vc, cmd.Flags())
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			nid, err := intconv.ParseInt(vc.GetString("network_id"), 64)
+			if err != nil {
+				return fmt.Errorf("invalid network_id %s err=%+v", vc.GetString("network_id"), err)
+			}
+			dstNID, err := intconv.ParseInt(vc.GetString("dst_nid"), 64)
+			if err != nil {
+				return fmt.Errorf("invalid dst_nid %s err=%+v", vc.GetString("dst_nid"), err)
+			}
+			w, err := loadRelayWallet(vc)
+			if err != nil {
+				return err
+			}
+			file := vc.GetString("cursor")
+			cursor, err := loadRelayCursor(file)
+			if err != nil {
+				return err
+			}
+
+			src := relay.NewClientSource(client.NewClientV3(vc.GetString("src_uri")))
+			dst := relay.NewBMCDestination(client.NewClientV3(vc.GetString("dst_uri")), w,
+				&relay.BMCConfig{
+					NID:       dstNID,
+					Address:   vc.GetString("bmc"),
+					Link:      vc.GetString("link"),
+					StepLimit: vc.GetInt64("step_limit"),
+				})
+			r := relay.New(src, dst, &relay.Config{
+				NetworkID:     nid,
+				MaxRetry:      vc.GetInt("max_retry"),
+				RetryInterval: time.Duration(vc.GetInt("retry_interval")) * time.Millisecond,
+				OnRelay: func(c relay.Cursor) error {
+					return JsonPrettySaveFile(file, 0644, &c)
+				},
+			}, cursor, log.WithFields(log.Fields{log.FieldKeyModule: "relay"}))
+
+			cancel := make(chan bool)
+			sigs := make(chan os.Signal, 1)
+			signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+			go func() {
+				<-sigs
+				close(cancel)
+			}()
+			err = r.Run(cancel)
+			if perr := JsonPrettyPrintln(os.Stdout, r.Cursor()); perr != nil && err == nil {
+				err = perr
+			}
+			return err
+		},
+	}
+	rootCmd.AddCommand(relayCmd)
+	flags := relayCmd.Flags()
+	flags.String("src_uri", "", "URI of JSON-RPC API of the source chain")
+	flags.String("dst_uri", "", "URI of JSON-RPC API of the destination chain")
+	flags.String("network_id", "", "BTP network ID in the source chain")
+	flags.String("dst_nid", "", "Network ID of the destination chain")
+	flags.String("bmc", "", "Address of the BMC in the destination chain")
+	flags.String("link", "", "BTP address of the BMC in the source chain")
+	flags.String("key_store", "", "KeyStore file for wallet")
+	flags.String("key_secret", "", "Secret(password) file for KeyStore")
+	flags.String("key_password", "", "Password for the KeyStore file")
+	flags.Int64("step_limit", relay.DefaultBMCStepLimit, "StepLimit of relay transactions")
+	flags.String("cursor", "relay.json", "Cursor file to resume relay")
+	flags.Int("max_retry", relay.DefaultMaxRetry, "Maximum number of retries without progress")
+	flags.Int("retry_interval", int(relay.DefaultRetryInterval/time.Millisecond), "Initial retry interval(msec)")
+	MarkAnnotationCustom(flags, "src_uri", "dst_uri", "network_id", "dst_nid", "bmc", "link", "key_store")
+	BindPFlags(vc, flags)
+	return rootCmd, vc
+}
@@ -0,0 +1,317 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/icon-project/goloop/chain"
+	"github.com/icon-project/goloop/chain/dbtool"
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/intconv"
+)
+
+type dbBucketStat struct {
+	ID    string `json:"id"`
+	Name  string `json:"name,omitempty"`
+	Keys  int64  `json:"keys"`
+	Bytes int64  `json:"bytes"`
+}
+
+type dbMissingEntry struct {
+	Key     common.HexBytes `json:"key"`
+	Buckets []string        `json:"buckets"`
+}
+
+func toDBMissingEntries(missing []dbtool.Missing) []dbMissingEntry {
+	entries := make([]dbMissingEntry, len(missing))
+	for i, m := range missing {
+		entries[i].Key = m.Key
+		for _, id := range m.Buckets {
+			entries[i].Buckets = append(entries[i].Buckets, string(id))
+		}
+	}
+	return entries
+}
+
+// parseBucketID returns the bucket ID for the name or the ID of the bucket.
+func parseBucketID(s string) db.BucketID {
+	for _, id := range dbtool.KnownBuckets {
+		if dbtool.BucketName(id) == s {
+			return id
+		}
+	}
+	return db.BucketID(s)
+}
+
+func parseHeightArg(args []string) (int64, error) {
+	if len(args) == 0 {
+		return -1, nil
+	}
+	height, err := intconv.ParseInt(args[0], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid height %s err=%+v", args[0], err)
+	}
+	return height, nil
+}
+
+// openSnapshotAsDB writes entries of the snapshot file to the temporary
+// database, then returns it with the function to remove it.
+func openSnapshotAsDB(file string) (db.Database, func(), error) {
+	s, err := chain.OpenSnapshot(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer s.Close()
+	dir, err := ioutil.TempDir("", "snapshot")
+	if err != nil {
+		return nil, nil, err
+	}
+	dbase, err := db.Open(dir, string(db.GoLevelDBBackend), "snapshot")
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, nil, err
+	}
+	release := func() {
+		dbase.Close()
+		os.RemoveAll(dir)
+	}
+	if err := s.WriteTo(dbase, nil); err != nil {
+		release()
+		return nil, nil, err
+	}
+	return dbase, release, nil
+}
+
+func NewDatabaseCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Command, *viper.Viper) {
+	rootCmd, vc := NewCommand(parentCmd, parentVc, "db", "Inspect and repair the database of the stopped chain")
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		return ValidateFlagsWithViper(vc, cmd.Flags())
+	}
+	rootPFlags := rootCmd.PersistentFlags()
+	rootPFlags.String("db_path", "", "Path of the database(ex: CHAIN_DIR/db/NID)")
+	rootPFlags.String("db_type", string(db.GoLevelDBBackend), "Type of the database")
+	MarkAnnotationRequired(rootPFlags, "db_path")
+	BindPFlags(vc, rootPFlags)
+
+	open := func(readOnly bool) (db.Database, error) {
+		return dbtool.Open(vc.GetString("db_path"), vc.GetString("db_type"), readOnly)
+	}
+
+	bucketsCmd := &cobra.Command{
+		Use:   "buckets [BUCKET...]",
+		Short: "Count keys of the buckets(default: known buckets)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dbase, err := open(true)
+			if err != nil {
+				return err
+			}
+			defer dbase.Close()
+
+			ids := dbtool.KnownBuckets
+			if len(args) > 0 {
+				ids = make([]db.BucketID, len(args))
+				for i, arg := range args {
+					ids[i] = parseBucketID(arg)
+				}
+			}
+			stats := make([]*dbBucketStat, len(ids))
+			for i, id := range ids {
+				st, err := dbtool.CountBucket(dbase, id)
+				if err != nil {
+					return err
+				}
+				stats[i] = &dbBucketStat{
+					ID:    string(id),
+					Name:  dbtool.BucketName(id),
+					Keys:  st.Keys,
+					Bytes: st.Bytes,
+				}
+			}
+			return JsonPrettyPrintln(os.Stdout, stats)
+		},
+	}
+
+	checkCmd := &cobra.Command{
+		Use:   "check [HEIGHT]",
+		Short: "Find missing entries for the transactions and the result in the block(default: last block)",
+		Args:  ArgsWithDefaultErrorFunc(cobra.MaximumNArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			height, err := parseHeightArg(args)
+			if err != nil {
+				return err
+			}
+			platform, _ := cmd.Flags().GetString("platform")
+			plt, err := chain.NewPlatform(platform, "", 0)
+			if err != nil {
+				return err
+			}
+			dbase, err := open(true)
+			if err != nil {
+				return err
+			}
+			defer dbase.Close()
+
+			if height < 0 {
+				if height, err = dbtool.LastHeight(dbase); err != nil {
+					return err
+				}
+			}
+			missing, err := dbtool.CheckBlock(dbase, plt, height)
+			if err != nil {
+				return err
+			}
+			if err := JsonPrettyPrintln(os.Stdout, map[string]interface{}{
+				"height":  height,
+				"missing": toDBMissingEntries(missing),
+			}); err != nil {
+				return err
+			}
+			if len(missing) > 0 {
+				return fmt.Errorf("found %d missing entries", len(missing))
+			}
+			return nil
+		},
+	}
+	checkCmd.Flags().String("platform", "basic", "Name of the platform of the chain")
+
+	verifyCmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify headers, transactions and receipts of the blocks",
+		Args:  ArgsWithDefaultErrorFunc(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dbase, err := open(true)
+			if err != nil {
+				return err
+			}
+			defer dbase.Close()
+
+			from, _ := cmd.Flags().GetInt64("from")
+			if from < 0 {
+				if from, err = dbtool.LowestHeight(dbase); err != nil {
+					return err
+				}
+			}
+			to, _ := cmd.Flags().GetInt64("to")
+			issues := 0
+			err = dbtool.VerifyBlocks(dbase, from, to, func(height int64, issue error) error {
+				if issue != nil {
+					issues += 1
+					fmt.Fprintf(os.Stdout, "height=%d issue=%v\n", height, issue)
+				}
+				if height%10000 == 0 {
+					fmt.Fprintf(os.Stderr, "verified height=%d issues=%d\n", height, issues)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if issues > 0 {
+				return fmt.Errorf("found %d blocks with issues", issues)
+			}
+			fmt.Fprintln(os.Stdout, "no issues")
+			return nil
+		},
+	}
+	verifyFlags := verifyCmd.Flags()
+	verifyFlags.Int64("from", -1, "Height of the first block(default: lowest retained block)")
+	verifyFlags.Int64("to", -1, "Height of the last block(default: last block)")
+
+	repairCmd := &cobra.Command{
+		Use:   "repair [HEIGHT]",
+		Short: "Write missing entries for the transactions and the result in the block(default: last block) from the sources",
+		Args:  ArgsWithDefaultErrorFunc(cobra.MaximumNArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			height, err := parseHeightArg(args)
+			if err != nil {
+				return err
+			}
+			fs := cmd.Flags()
+			platform, _ := fs.GetString("platform")
+			plt, err := chain.NewPlatform(platform, "", 0)
+			if err != nil {
+				return err
+			}
+			srcPath, _ := fs.GetString("source_db_path")
+			srcType, _ := fs.GetString("source_db_type")
+			snapshot, _ := fs.GetString("source_snapshot")
+			if len(srcPath) == 0 && len(snapshot) == 0 {
+				return fmt.Errorf("no source is specified")
+			}
+
+			var sources []db.Database
+			if len(srcPath) > 0 {
+				src, err := dbtool.Open(srcPath, srcType, true)
+				if err != nil {
+					return err
+				}
+				defer src.Close()
+				sources = append(sources, src)
+			}
+			if len(snapshot) > 0 {
+				src, release, err := openSnapshotAsDB(snapshot)
+				if err != nil {
+					return err
+				}
+				defer release()
+				sources = append(sources, src)
+			}
+
+			dbase, err := open(false)
+			if err != nil {
+				return err
+			}
+			defer dbase.Close()
+
+			if height < 0 {
+				if height, err = dbtool.LastHeight(dbase); err != nil {
+					return err
+				}
+			}
+			cnt, missing, err := dbtool.RepairBlock(dbase, plt, height, sources...)
+			if err != nil {
+				return err
+			}
+			if err := JsonPrettyPrintln(os.Stdout, map[string]interface{}{
+				"height":   height,
+				"repaired": cnt,
+				"missing":  toDBMissingEntries(missing),
+			}); err != nil {
+				return err
+			}
+			if len(missing) > 0 {
+				return fmt.Errorf("fail to find %d missing entries", len(missing))
+			}
+			return nil
+		},
+	}
+	repairFlags := repairCmd.Flags()
+	repairFlags.String("platform", "basic", "Name of the platform of the chain")
+	repairFlags.String("source_db_path", "", "Path of the database having missing entries")
+	repairFlags.String("source_db_type", string(db.GoLevelDBBackend), "Type of the source database")
+	repairFlags.String("source_snapshot", "", "Snapshot file having missing entries")
+
+	rootCmd.AddCommand(bucketsCmd, checkCmd, verifyCmd, repairCmd)
+	return rootCmd, vc
+}
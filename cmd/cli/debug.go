@@ -24,6 +24,7 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/icon-project/goloop/client"
+	"github.com/icon-project/goloop/common/intconv"
 	"github.com/icon-project/goloop/server/jsonrpc"
 	v3 "github.com/icon-project/goloop/server/v3"
 )
@@ -66,7 +67,31 @@ func NewDebugCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Comman
 			return JsonPrettyPrintln(os.Stdout, trace.Result)
 		},
 	}
-	rootCmd.AddCommand(traceCmd)
+
+	iscoreCmd := &cobra.Command{
+		Use:   "iscore ADDRESS",
+		Short: "Get the breakdown of I-Score rewarded to the address for the previous term",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			param := &v3.AddressParam{
+				Address: jsonrpc.Address(args[0]),
+			}
+			height, err := intconv.ParseInt(cmd.Flag("height").Value.String(), 64)
+			if err != nil {
+				return err
+			}
+			if height != -1 {
+				param.Height = jsonrpc.HexInt(intconv.FormatInt(height))
+			}
+			breakdown, err := debugClient.Do("debug_getIScoreBreakdown", param, nil)
+			if err != nil {
+				return err
+			}
+			return JsonPrettyPrintln(os.Stdout, breakdown.Result)
+		},
+	}
+	iscoreCmd.Flags().Int("height", -1, "BlockHeight")
+	rootCmd.AddCommand(traceCmd, iscoreCmd)
 
 	return rootCmd, vc
 }
@@ -3,6 +3,7 @@ package cli
 import (
 	"encoding/hex"
 	"fmt"
+	"github.com/icon-project/goloop/btp/ntm"
 	"github.com/icon-project/goloop/common/wallet"
 	"github.com/spf13/cobra"
 	"io/ioutil"
@@ -49,6 +50,7 @@ func publickeyFromKeyStore(c string) *cobra.Command {
 	keystorePath := flags.StringP("keystore", "k", "keystore.json", "Keystore file path")
 	secret := flags.StringP("secret", "s", "", "KeySecret file path")
 	pass := flags.StringP("password", "p", "gochain", "Password for the keystore")
+	dsa := flags.String("dsa", "ecdsa/secp256k1", "DSA of the public key (ecdsa/secp256k1, bls12-381)")
 	cmd.Run = func(cmd *cobra.Command, args []string) {
 		var pb []byte
 		if kb, err := ioutil.ReadFile(*keystorePath); err != nil {
@@ -66,7 +68,19 @@ func publickeyFromKeyStore(c string) *cobra.Command {
 			if err != nil {
 				log.Panicf("Fail to decrypt KeyStore err=%+v", err)
 			}
-			fmt.Println("0x" + hex.EncodeToString(w.PublicKey()))
+			pubKey := w.PublicKey()
+			switch *dsa {
+			case "ecdsa/secp256k1":
+			case "bls12-381":
+				bw, err := ntm.NewBLSWallet(w)
+				if err != nil {
+					log.Panicf("Fail to make BLS wallet err=%+v", err)
+				}
+				pubKey = bw.PublicKey()
+			default:
+				log.Panicf("Unknown DSA %s", *dsa)
+			}
+			fmt.Println("0x" + hex.EncodeToString(pubKey))
 		}
 	}
 	return cmd
@@ -0,0 +1,256 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/icon-project/goloop/client"
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/intconv"
+	"github.com/icon-project/goloop/lightclient"
+	"github.com/icon-project/goloop/module"
+)
+
+// lightClientState is stored in the state file to resume verification
+// from the last verified header.
+type lightClientState struct {
+	Header     common.HexBytes `json:"header"`
+	Validators common.HexBytes `json:"validators"`
+}
+
+type lightClientHeader struct {
+	Height             common.HexInt64 `json:"height"`
+	ID                 common.HexBytes `json:"id"`
+	PrevID             common.HexBytes `json:"prevID"`
+	Timestamp          common.HexInt64 `json:"timestamp"`
+	NextValidatorsHash common.HexBytes `json:"nextValidatorsHash"`
+}
+
+func toLightClientHeader(h *lightclient.Header) *lightClientHeader {
+	return &lightClientHeader{
+		Height:             common.HexInt64{Value: h.Height()},
+		ID:                 h.ID(),
+		PrevID:             h.PrevID(),
+		Timestamp:          common.HexInt64{Value: h.Timestamp()},
+		NextValidatorsHash: h.NextValidatorsHash(),
+	}
+}
+
+func loadLightClient(src lightclient.Source, file string) (*lightclient.LightClient, error) {
+	bs, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("fail to read state file=%s err=%+v", file, err)
+	}
+	var st lightClientState
+	if err := json.Unmarshal(bs, &st); err != nil {
+		return nil, fmt.Errorf("fail to parse state file=%s err=%+v", file, err)
+	}
+	return lightclient.New(src, st.Header, st.Validators)
+}
+
+func saveLightClient(lc *lightclient.LightClient, file string) error {
+	st := &lightClientState{
+		Header:     lc.Header().Bytes(),
+		Validators: lc.Validators(),
+	}
+	return JsonPrettySaveFile(file, 0644, st)
+}
+
+func parseIntArgs(args []string) ([]int, error) {
+	values := make([]int, len(args))
+	for i, arg := range args {
+		v, err := intconv.ParseInt(arg, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s err=%+v", arg, err)
+		}
+		values[i] = int(v)
+	}
+	return values, nil
+}
+
+func eventLogsToJSON(evs []module.EventLog) []interface{} {
+	jso := make([]interface{}, len(evs))
+	for i, ev := range evs {
+		indexed := make([]common.HexBytes, len(ev.Indexed()))
+		for j, v := range ev.Indexed() {
+			indexed[j] = v
+		}
+		data := make([]common.HexBytes, len(ev.Data()))
+		for j, v := range ev.Data() {
+			data[j] = v
+		}
+		jso[i] = map[string]interface{}{
+			"scoreAddress": ev.Address(),
+			"indexed":      indexed,
+			"data":         data,
+		}
+	}
+	return jso
+}
+
+func NewLightClientCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Command, *viper.Viper) {
+	var rpcClient client.ClientV3
+	var src lightclient.Source
+	var lc *lightclient.LightClient
+	rootCmd, vc := NewCommand(parentCmd, parentVc, "lightclient", "Verify blocks and results with light client")
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if err := RpcPersistentPreRunE(vc, &rpcClient)(cmd, args); err != nil {
+			return err
+		}
+		src = lightclient.NewClientSource(&rpcClient)
+		return nil
+	}
+	AddRpcRequiredFlags(rootCmd)
+	rootPFlags := rootCmd.PersistentFlags()
+	rootPFlags.String("state", "lightclient.json", "State file of the light client")
+	BindPFlags(vc, rootPFlags)
+
+	// commands using the state file
+	load := func(cmd *cobra.Command, args []string) error {
+		var err error
+		lc, err = loadLightClient(src, vc.GetString("state"))
+		return err
+	}
+	save := func(cmd *cobra.Command, args []string) error {
+		return saveLightClient(lc, vc.GetString("state"))
+	}
+
+	initCmd := &cobra.Command{
+		Use:   "init HEIGHT BLOCK_ID",
+		Short: "Initialize the state with the trusted block",
+		Args:  ArgsWithDefaultErrorFunc(cobra.ExactArgs(2)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			height, err := intconv.ParseInt(args[0], 64)
+			if err != nil {
+				return fmt.Errorf("invalid height %s err=%+v", args[0], err)
+			}
+			var id common.HexBytes
+			if err := id.UnmarshalJSON([]byte(`"` + args[1] + `"`)); err != nil {
+				return fmt.Errorf("invalid block id %s err=%+v", args[1], err)
+			}
+			if lc, err = lightclient.NewWithTrustedID(src, height, id); err != nil {
+				return err
+			}
+			return JsonPrettyPrintln(os.Stdout, toLightClientHeader(lc.Header()))
+		},
+		PostRunE: save,
+	}
+
+	syncCmd := &cobra.Command{
+		Use:     "sync [HEIGHT]",
+		Short:   "Verify headers up to the height(default: last block)",
+		Args:    ArgsWithDefaultErrorFunc(cobra.MaximumNArgs(1)),
+		PreRunE: load,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			height := int64(-1)
+			if len(args) > 0 {
+				var err error
+				if height, err = intconv.ParseInt(args[0], 64); err != nil {
+					return fmt.Errorf("invalid height %s err=%+v", args[0], err)
+				}
+			}
+			err := lc.Sync(height)
+			if serr := saveLightClient(lc, vc.GetString("state")); serr != nil {
+				fmt.Fprintf(os.Stderr, "FAIL to save state file=%s err=%+v\n",
+					vc.GetString("state"), serr)
+			}
+			if err != nil {
+				return err
+			}
+			return JsonPrettyPrintln(os.Stdout, toLightClientHeader(lc.Header()))
+		},
+	}
+
+	headerCmd := &cobra.Command{
+		Use:     "header HEIGHT",
+		Short:   "Get the verified header",
+		Args:    ArgsWithDefaultErrorFunc(cobra.ExactArgs(1)),
+		PreRunE: load,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			height, err := intconv.ParseInt(args[0], 64)
+			if err != nil {
+				return fmt.Errorf("invalid height %s err=%+v", args[0], err)
+			}
+			h, err := lc.GetHeader(height)
+			if err != nil {
+				return err
+			}
+			return JsonPrettyPrintln(os.Stdout, toLightClientHeader(h))
+		},
+		PostRunE: save,
+	}
+
+	receiptCmd := &cobra.Command{
+		Use:     "receipt HEIGHT INDEX",
+		Short:   "Get the verified receipt of the transaction at the index in the block",
+		Args:    ArgsWithDefaultErrorFunc(cobra.ExactArgs(2)),
+		PreRunE: load,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			height, err := intconv.ParseInt(args[0], 64)
+			if err != nil {
+				return fmt.Errorf("invalid height %s err=%+v", args[0], err)
+			}
+			idx, err := parseIntArgs(args[1:])
+			if err != nil {
+				return err
+			}
+			rct, err := lc.GetReceipt(height, idx[0])
+			if err != nil {
+				return err
+			}
+			jso, err := rct.ToJSON(module.JSONVersionLast)
+			if err != nil {
+				return err
+			}
+			return JsonPrettyPrintln(os.Stdout, jso)
+		},
+		PostRunE: save,
+	}
+
+	eventsCmd := &cobra.Command{
+		Use:     "events HEIGHT INDEX EVENT_INDEX...",
+		Short:   "Get the verified events of the transaction at the index in the block",
+		Args:    ArgsWithDefaultErrorFunc(cobra.MinimumNArgs(3)),
+		PreRunE: load,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			height, err := intconv.ParseInt(args[0], 64)
+			if err != nil {
+				return fmt.Errorf("invalid height %s err=%+v", args[0], err)
+			}
+			values, err := parseIntArgs(args[1:])
+			if err != nil {
+				return err
+			}
+			_, evs, err := lc.GetEvents(height, values[0], values[1:])
+			if err != nil {
+				return err
+			}
+			return JsonPrettyPrintln(os.Stdout, eventLogsToJSON(evs))
+		},
+		PostRunE: save,
+	}
+
+	rootCmd.AddCommand(initCmd, syncCmd, headerCmd, receiptCmd, eventsCmd)
+	return rootCmd, vc
+}
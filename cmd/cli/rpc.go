@@ -57,6 +57,24 @@ func readFile(s string) ([]byte, error) {
 	}
 }
 
+// readJSONOrFile returns the JSON from the string if it's an object,
+// otherwise it reads the JSON from the file.
+func readJSONOrFile(s string) (json.RawMessage, error) {
+	var bs []byte
+	if strings.HasPrefix(strings.TrimSpace(s), "{") {
+		bs = []byte(s)
+	} else {
+		var err error
+		if bs, err = readFile(s); err != nil {
+			return nil, err
+		}
+	}
+	if !json.Valid(bs) {
+		return nil, errors.Errorf("invalid JSON %q", s)
+	}
+	return bs, nil
+}
+
 func AddRpcRequiredFlags(c *cobra.Command) {
 	pFlags := c.PersistentFlags()
 	pFlags.String("uri", "", "URI of JSON-RPC API")
@@ -88,6 +106,18 @@ func NewRpcCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Command,
 				return JsonPrettyPrintln(os.Stdout, blk)
 			},
 		},
+		&cobra.Command{
+			Use:   "lowestblock",
+			Short: "GetLowestBlock",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				blk, err := rpcClient.GetLowestBlock()
+				if err != nil {
+					return err
+				}
+				return JsonPrettyPrintln(os.Stdout, blk)
+			},
+		},
 		&cobra.Command{
 			Use:   "blockbyheight HEIGHT",
 			Short: "GetBlockByHeight",
@@ -262,6 +292,11 @@ func NewRpcCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Command,
 			if len(dataM) > 0 {
 				param.Data = dataM
 			}
+			if so := cmd.Flag("state_override").Value.String(); so != "" {
+				if param.StateOverride, err = readJSONOrFile(so); err != nil {
+					return err
+				}
+			}
 			blk, err := rpcClient.Call(param)
 			if err != nil {
 				return err
@@ -282,6 +317,7 @@ func NewRpcCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Command,
 	callFlags.StringToString("param", nil,
 		"key=value, Function parameters, if '--raw' used, will overwrite")
 	callFlags.String("raw", "", "call with 'data' using raw json file or json-string")
+	callFlags.String("state_override", "", "call with 'stateOverride' using raw json file or json-string")
 	MarkAnnotationRequired(callFlags, "to")
 
 	rawCmd := &cobra.Command{
@@ -424,6 +460,78 @@ func NewRpcCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Command,
 	flags = scoreStatusCmd.Flags()
 	flags.Int("height", -1, "BlockHeight")
 
+	logsCmd := &cobra.Command{
+		Use:   "logs FROM_HEIGHT [TO_HEIGHT]",
+		Short: "GetLogs",
+		Args:  ArgsWithDefaultErrorFunc(cobra.RangeArgs(1, 2)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			param := &server.LogsRequest{}
+			from, err := intconv.ParseInt(args[0], 64)
+			if err != nil {
+				return err
+			}
+			param.FromHeight = common.HexInt64{Value: from}
+			if len(args) > 1 {
+				to, err := intconv.ParseInt(args[1], 64)
+				if err != nil {
+					return err
+				}
+				param.ToHeight = &common.HexInt64{Value: to}
+			}
+
+			if sig := cmd.Flag("event").Value.String(); sig != "" {
+				param.Signature = sig
+			}
+			if addr := cmd.Flag("addr").Value.String(); addr != "" {
+				param.Addr = common.MustNewAddressFromString(addr)
+			}
+			if evtIndexed, err := cmd.Flags().GetStringSlice("indexed"); err == nil && len(evtIndexed) > 0 {
+				param.Indexed = make([]*string, len(evtIndexed))
+				for i, v := range evtIndexed {
+					param.Indexed[i] = &v
+				}
+			}
+			if evtData, err := cmd.Flags().GetStringSlice("data"); err == nil && len(evtData) > 0 {
+				param.Data = make([]*string, len(evtData))
+				for i, v := range evtData {
+					param.Data[i] = &v
+				}
+			}
+			fs, err := cmd.Flags().GetStringArray("filter")
+			if err != nil {
+				return err
+			}
+			for _, f := range fs {
+				ef := &server.EventFilter{}
+				var efBytes []byte
+				if strings.HasPrefix(strings.TrimSpace(f), "{") {
+					efBytes = []byte(f)
+				} else {
+					if efBytes, err = readFile(f); err != nil {
+						return err
+					}
+				}
+				if err := json.Unmarshal(efBytes, ef); err != nil {
+					return fmt.Errorf("fail to unmarshal from %s, err:%+v", f, err)
+				}
+				param.Filters = append(param.Filters, ef)
+			}
+			logs, err := rpcClient.GetLogs(param)
+			if err != nil {
+				return err
+			}
+			return JsonPrettyPrintln(os.Stdout, logs)
+		},
+	}
+	rootCmd.AddCommand(logsCmd)
+	flags = logsCmd.Flags()
+	flags.String("addr", "", "SCORE Address")
+	flags.String("event", "", "Signature of Event")
+	flags.StringSlice("indexed", nil, "Indexed Arguments of Event, comma-separated string")
+	flags.StringSlice("data", nil, "Not indexed Arguments of Event, comma-separated string")
+	flags.StringArray("filter", nil,
+		"EventFilter raw json file or json string")
+
 	rootCmd.AddCommand(
 		&cobra.Command{
 			Use:   "btpnetwork ID [HEIGHT]",
@@ -572,6 +680,13 @@ func NewSendTxCmd(parentCmd *cobra.Command, parentVc *viper.Viper) *cobra.Comman
 			return err
 		}
 
+		var stateOverride json.RawMessage
+		if so := vc.GetString("state_override"); so != "" {
+			var err error
+			if stateOverride, err = readJSONOrFile(so); err != nil {
+				return err
+			}
+		}
 		if estimate := vc.GetBool("estimate"); estimate {
 			rpcClientSendTx = func(w module.Wallet, p *v3.TransactionParam) (interface{}, error) {
 				params := &v3.TransactionParamForEstimate{
@@ -585,12 +700,42 @@ func NewSendTxCmd(parentCmd *cobra.Command, parentVc *viper.Viper) *cobra.Comman
 					DataType:    p.DataType,
 					Data:        p.Data,
 				}
+				if stateOverride != nil {
+					params.StateOverride = stateOverride
+				}
 				step, err := rpcClient.EstimateStep(params)
 				if err != nil {
 					return nil, err
 				}
 				return step, nil
 			}
+		} else if simulate := vc.GetBool("simulate"); simulate {
+			var height jsonrpc.HexInt
+			if h := vc.GetInt64("simulate_height"); h >= 0 {
+				height = jsonrpc.HexInt(intconv.FormatInt(h))
+			}
+			rpcClientSendTx = func(w module.Wallet, p *v3.TransactionParam) (interface{}, error) {
+				params := &v3.TransactionParamForSimulate{
+					Version:     p.Version,
+					FromAddress: p.FromAddress,
+					ToAddress:   p.ToAddress,
+					Value:       p.Value,
+					StepLimit:   p.StepLimit,
+					Timestamp:   p.Timestamp,
+					NetworkID:   p.NetworkID,
+					Nonce:       p.Nonce,
+					DataType:    p.DataType,
+					Data:        p.Data,
+					Height:      height,
+				}
+				if stateOverride != nil {
+					params.StateOverride = stateOverride
+				}
+				return rpcClient.SimulateTransaction(params)
+			}
+			if err := CheckFlagsWithViper(vc, cmd.Flags(), "step_limit"); err != nil {
+				return err
+			}
 		} else {
 			save := vc.GetString("save")
 			rpcClientSendTx = func(w module.Wallet, p *v3.TransactionParam) (interface{}, error) {
@@ -726,6 +871,9 @@ func NewSendTxCmd(parentCmd *cobra.Command, parentVc *viper.Viper) *cobra.Comman
 	rootPFlags.Int("wait_interval", 1000, "Polling interval(msec) for wait transaction result")
 	rootPFlags.Int("wait_timeout", 10, "Timeout(sec) for wait transaction result")
 	rootPFlags.Bool("estimate", false, "Just estimate steps for the tx")
+	rootPFlags.Bool("simulate", false, "Just simulate the tx and show the receipt with balance changes")
+	rootPFlags.Int64("simulate_height", -1, "Block height of the state for simulation(default: last)")
+	rootPFlags.String("state_override", "", "Estimate or simulate with 'stateOverride' using raw json file or json-string")
 	rootPFlags.String("save", "", "Store transaction to the file")
 	MarkAnnotationCustom(rootPFlags, "key_store", "nid")
 	BindPFlags(vc, rootCmd.PersistentFlags())
@@ -0,0 +1,83 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/icon/icsim"
+)
+
+func newICSimCmd() *cobra.Command {
+	rootCmd := &cobra.Command{
+		Use:   "icsim",
+		Short: "ICON IISS simulator",
+	}
+
+	runCmd := &cobra.Command{
+		Use:          "run SCENARIO",
+		Short:        "Run IISS scenario file (YAML or JSON)",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			level := "warn"
+			if cmd.Flags().Changed("log_level") {
+				level = cfg.LogLevel
+			}
+			lv, err := log.ParseLevel(level)
+			if err != nil {
+				return err
+			}
+			log.GlobalLogger().SetLevel(lv)
+
+			sc, err := icsim.LoadScenario(args[0])
+			if err != nil {
+				return err
+			}
+
+			var out io.Writer
+			if csvFile, _ := cmd.Flags().GetString("csv"); csvFile == "-" {
+				out = os.Stdout
+			} else if len(csvFile) > 0 {
+				f, err := os.Create(csvFile)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				out = f
+			}
+
+			sim, err := sc.Run(out)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Scenario completed steps=%d height=%d\n",
+				len(sc.Steps), sim.BlockHeight())
+			return nil
+		},
+	}
+	runCmd.Flags().String("csv", "",
+		"Write per-term summary as CSV to the file (\"-\" for stdout)")
+	rootCmd.AddCommand(runCmd)
+
+	return rootCmd
+}
@@ -34,17 +34,18 @@ const (
 
 type GoChainConfig struct {
 	chain.Config
-	P2PAddr       string `json:"p2p"`
-	P2PListenAddr string `json:"p2p_listen"`
-	EESocket      string `json:"ee_socket"`
-	RPCAddr       string `json:"rpc_addr"`
-	RPCDump       bool   `json:"rpc_dump"`
-	RPCDebug      bool   `json:"rpc_debug"`
-	RPCRosetta    bool   `json:"rpc_rosetta"`
-	RPCBatchLimit int    `json:"rpc_batch_limit,omitempty"`
-	EEInstances   int    `json:"ee_instances"`
-	Engines       string `json:"engines"`
-	WSMaxSession  int    `json:"ws_max_session"`
+	P2PAddr          string `json:"p2p"`
+	P2PListenAddr    string `json:"p2p_listen"`
+	EESocket         string `json:"ee_socket"`
+	RPCAddr          string `json:"rpc_addr"`
+	RPCDump          bool   `json:"rpc_dump"`
+	RPCDebug         bool   `json:"rpc_debug"`
+	RPCRosetta       bool   `json:"rpc_rosetta"`
+	RPCStateOverride bool   `json:"rpc_state_override"`
+	RPCBatchLimit    int    `json:"rpc_batch_limit,omitempty"`
+	EEInstances      int    `json:"ee_instances"`
+	Engines          string `json:"engines"`
+	WSMaxSession     int    `json:"ws_max_session"`
 
 	Key          []byte          `json:"key,omitempty"`
 	KeyStoreData json.RawMessage `json:"key_store"`
@@ -117,6 +118,7 @@ func main() {
 	flag.BoolVar(&cfg.RPCDump, "rpc_dump", false, "JSON-RPC Request, Response Dump flag")
 	flag.BoolVar(&cfg.RPCDebug, "rpc_debug", false, "JSON-RPC Debug enable")
 	flag.BoolVar(&cfg.RPCRosetta, "rpc_rosetta", false, "JSON-RPC Rosetta enable")
+	flag.BoolVar(&cfg.RPCStateOverride, "rpc_state_override", false, "JSON-RPC state override enable")
 	flag.IntVar(&cfg.RPCBatchLimit, "rpc_batch_limit", 10, "JSON-RPC batch limit")
 	flag.StringVar(&cfg.SeedAddr, "seed", "", "Ip-port of Seed")
 	flag.StringVar(&genesisStorage, "genesis_storage", "", "Genesis storage path")
@@ -138,6 +140,11 @@ func main() {
 	flag.IntVar(&cfg.MaxBlockTxBytes, "max_block_tx_bytes", 0, "Maximum size of transactions in a block")
 	flag.StringVar(&cfg.NodeCache, "node_cache", chain.NodeCacheDefault, "Node cache (none,small,large)")
 	flag.BoolVar(&cfg.ValidateTxOnSend, "validate_tx_on_send", false, "Validate transaction on send")
+	flag.BoolVar(&cfg.AddressIndex, "address_index", false, "Index transactions by address")
+	flag.Int64Var(&cfg.MaxLogsRange, "max_logs_range", 0, "Maximum range of block heights for icx_getLogs (0: uses system default value)")
+	flag.IntVar(&cfg.MaxLogsResults, "max_logs_results", 0, "Maximum number of event logs for icx_getLogs (0: uses system default value)")
+	flag.Int64Var(&cfg.StateRetention, "state_retention", 0, "Number of recent blocks whose states are retained by online pruning (0: no pruning)")
+	flag.StringVar(&cfg.HistoryRetention, "history_retention", chain.HistoryRetentionArchive, "History retained by online pruning (archive: all, N: last N blocks, Nterms: last N terms)")
 	cfg.ChildrenLimit = flag.Int("children_limit", -1, "Maximum number of child connections (-1: uses system default value)")
 	cfg.NephewsLimit = flag.Int("nephews_limit", -1, "Maximum number of nephew connections (-1: uses system default value)")
 	flag.StringVar(&cfg.LogLevel, "log_level", "debug", "Main log level")
@@ -172,9 +179,12 @@ func main() {
 	resetFlags.Int64("height", 0, "Block Height")
 	resetFlags.String("block_hash", "", "Hash of the block at the given height")
 	cmd.AddCommand(resetCmd)
+	cmd.AddCommand(newICSimCmd())
 
 	cmd.Run = Execute
-	cmd.Execute()
+	if err := cmd.Execute(); err != nil {
+		os.Exit(1)
+	}
 }
 
 func Execute(cmd *cobra.Command, args []string) {
@@ -486,12 +496,13 @@ func Execute(cmd *cobra.Command, args []string) {
 	pm.SetInstances(cfg.EEInstances, cfg.EEInstances, cfg.EEInstances)
 
 	config := &server.Config{
-		ServerAddress:       cfg.RPCAddr,
-		JSONRPCDump:         cfg.RPCDump,
-		JSONRPCIncludeDebug: cfg.RPCDebug,
-		JSONRPCRosetta:      cfg.RPCRosetta,
-		JSONRPCBatchLimit:   cfg.RPCBatchLimit,
-		WSMaxSession:        cfg.WSMaxSession,
+		ServerAddress:        cfg.RPCAddr,
+		JSONRPCDump:          cfg.RPCDump,
+		JSONRPCIncludeDebug:  cfg.RPCDebug,
+		JSONRPCRosetta:       cfg.RPCRosetta,
+		JSONRPCStateOverride: cfg.RPCStateOverride,
+		JSONRPCBatchLimit:    cfg.RPCBatchLimit,
+		WSMaxSession:         cfg.WSMaxSession,
 	}
 	srv := server.NewManager(config, wallet, logger)
 	hex.EncodeToString(wallet.Address().ID())
@@ -0,0 +1,102 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/icon-project/goloop/chain/dbtool"
+	"github.com/icon-project/goloop/cmd/cli"
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/icon/iiss"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service"
+)
+
+// addIScoreCmd adds the command running the reward calculation of the ICON
+// platform to the database command.
+func addIScoreCmd(dbCmd *cobra.Command, vc *viper.Viper) {
+	iscoreCmd := &cobra.Command{
+		Use:   "iscore [ADDRESS]",
+		Short: "Run the reward calculation for the previous term of the block offline",
+		Args:  cli.ArgsWithDefaultErrorFunc(cobra.MaximumNArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var address module.Address
+			if len(args) > 0 {
+				addr, err := common.NewAddressFromString(args[0])
+				if err != nil {
+					return fmt.Errorf("invalid address %s err=%+v", args[0], err)
+				}
+				address = addr
+			}
+			dbase, err := dbtool.Open(vc.GetString("db_path"), vc.GetString("db_type"), true)
+			if err != nil {
+				return err
+			}
+			defer dbase.Close()
+
+			height, _ := cmd.Flags().GetInt64("height")
+			if height < 0 {
+				if height, err = dbtool.LastHeight(dbase); err != nil {
+					return err
+				}
+			}
+			result, err := dbtool.BlockResult(dbase, height)
+			if err != nil {
+				return err
+			}
+			ed, err := service.ExtensionDataFromResult(result)
+			if err != nil {
+				return err
+			}
+			ess, ok := iiss.NewExtensionSnapshot(dbase, ed).(*iiss.ExtensionSnapshotImpl)
+			if !ok || ess == nil {
+				return fmt.Errorf("no extension data in block height=%d", height)
+			}
+			c, err := iiss.CalculateReward(context.Background(), dbase, ess.Back2(), ess.Reward(), address, log.New())
+			if err != nil {
+				return err
+			}
+			jso := map[string]interface{}{
+				"height":           height,
+				"startBlockHeight": c.StartHeight(),
+				"totalReward":      c.TotalReward(),
+				"result":           c.Result().Bytes(),
+			}
+			if address != nil {
+				jso["breakdown"] = c.Detail().ToJSON()
+			}
+			tobj, err := common.EncodeAny(jso)
+			if err != nil {
+				return err
+			}
+			out, err := common.DecodeAnyForJSON(tobj)
+			if err != nil {
+				return err
+			}
+			return cli.JsonPrettyPrintln(os.Stdout, out)
+		},
+	}
+	iscoreCmd.Flags().Int64("height", -1, "Height of the block whose state is used(default: last block)")
+	dbCmd.AddCommand(iscoreCmd)
+}
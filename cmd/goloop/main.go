@@ -45,6 +45,10 @@ func main() {
 	cli.NewStatsCmd(rootCmd, rootVc)
 	cli.NewRpcCmd(rootCmd, nil)
 	cli.NewDebugCmd(rootCmd, nil)
+	cli.NewLightClientCmd(rootCmd, nil)
+	cli.NewBTPCmd(rootCmd, nil)
+	dbCmd, dbVc := cli.NewDatabaseCmd(rootCmd, nil)
+	addIScoreCmd(dbCmd, dbVc)
 	rootCmd.AddCommand(
 		cli.NewGStorageCmd("gs"),
 		cli.NewGenesisCmd("gn"),
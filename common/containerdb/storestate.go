@@ -291,6 +291,36 @@ func NewBytesStoreStateFromRaw(s RawBytesStoreState) BytesStoreState {
 	return bytesStoreStateForRaw{s}
 }
 
+type bytesStoreStateWithTrace struct {
+	BytesStoreState
+	onChange func(key, before, after []byte)
+}
+
+func (s *bytesStoreStateWithTrace) SetValue(key []byte, value []byte) ([]byte, error) {
+	old, err := s.BytesStoreState.SetValue(key, value)
+	if err == nil {
+		s.onChange(key, old, value)
+	}
+	return old, err
+}
+
+func (s *bytesStoreStateWithTrace) DeleteValue(key []byte) ([]byte, error) {
+	old, err := s.BytesStoreState.DeleteValue(key)
+	if err == nil && old != nil {
+		s.onChange(key, old, nil)
+	}
+	return old, err
+}
+
+// NewBytesStoreStateWithTrace returns the store calling onChange with
+// the previous and the new value on each change of the value.
+func NewBytesStoreStateWithTrace(s BytesStoreState, onChange func(key, before, after []byte)) BytesStoreState {
+	if s == nil {
+		return nil
+	}
+	return &bytesStoreStateWithTrace{s, onChange}
+}
+
 type bytesStoreSnapshotForRaw struct {
 	rbs RawBytesStoreSnapshot
 }
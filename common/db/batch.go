@@ -0,0 +1,138 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package db
+
+// Batch collects changes on multiple buckets, then it writes them
+// atomically on Write.
+type Batch interface {
+	Set(id BucketID, key []byte, value []byte) error
+	Delete(id BucketID, key []byte) error
+
+	// Len returns number of changes in the batch.
+	Len() int
+
+	// Write writes all changes in the batch. The batch keeps the changes
+	// after Write, so use Reset for reusing it.
+	Write() error
+	Reset()
+}
+
+// Batcher is implemented by the database supporting Batch.
+type Batcher interface {
+	NewBatch() Batch
+}
+
+// BatcherOf returns Batcher of the database. It returns nil if the database
+// doesn't support Batch, then the caller should write changes one by one.
+func BatcherOf(dbase Database) Batcher {
+	type wrapper interface {
+		database() Database
+	}
+	for dbase != nil {
+		if b, ok := dbase.(Batcher); ok {
+			return b
+		}
+		if w, ok := dbase.(wrapper); ok {
+			dbase = w.database()
+		} else {
+			break
+		}
+	}
+	return nil
+}
+
+// writeBatchTo writes changes to the database. It uses Batch if the database
+// supports it, otherwise it writes changes one by one.
+func writeBatchTo(dbase Database, ops []batchOp) error {
+	if batcher := BatcherOf(dbase); batcher != nil {
+		batch := batcher.NewBatch()
+		for _, op := range ops {
+			var err error
+			if op.delete {
+				err = batch.Delete(op.id, op.key)
+			} else {
+				err = batch.Set(op.id, op.key, op.value)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return batch.Write()
+	}
+	for _, op := range ops {
+		bk, err := dbase.GetBucket(op.id)
+		if err != nil {
+			return err
+		}
+		if op.delete {
+			err = bk.Delete(op.key)
+		} else {
+			err = bk.Set(op.key, op.value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type batchOp struct {
+	id     BucketID
+	key    []byte
+	value  []byte
+	delete bool
+}
+
+// memBatch keeps changes in the memory, and writes them with the writer.
+type memBatch struct {
+	ops    []batchOp
+	writer func(ops []batchOp) error
+}
+
+func (b *memBatch) Set(id BucketID, key []byte, value []byte) error {
+	b.ops = append(b.ops, batchOp{
+		id:    id,
+		key:   append([]byte{}, key...),
+		value: append([]byte{}, value...),
+	})
+	return nil
+}
+
+func (b *memBatch) Delete(id BucketID, key []byte) error {
+	b.ops = append(b.ops, batchOp{
+		id:     id,
+		key:    append([]byte{}, key...),
+		delete: true,
+	})
+	return nil
+}
+
+func (b *memBatch) Len() int {
+	return len(b.ops)
+}
+
+func (b *memBatch) Write() error {
+	return b.writer(b.ops)
+}
+
+func (b *memBatch) Reset() {
+	b.ops = nil
+}
+
+func newMemBatch(writer func(ops []batchOp) error) *memBatch {
+	return &memBatch{writer: writer}
+}
@@ -63,6 +63,11 @@ const (
 	// ChainProperty is general key value map for chain property.
 	ChainProperty BucketID = "C"
 
+	// TransactionsByAddress maps list of transactions from address.
+	// It's optional node-local index, so it's not exported or imported
+	// with blocks.
+	TransactionsByAddress BucketID = "A"
+
 	// ListByMerkleRootBase is the base for the bucket that maps list
 	// from network type dependent merkle root(list)
 	ListByMerkleRootBase BucketID = "L"
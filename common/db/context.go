@@ -62,6 +62,10 @@ type databaseContext struct {
 	flags Flags
 }
 
+func (c *databaseContext) database() Database {
+	return c.Database
+}
+
 func (c *databaseContext) WithFlags(flags Flags) Context {
 	newFlags := c.flags.Merged(flags)
 	return &databaseContext{c.Database, newFlags}
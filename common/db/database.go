@@ -22,6 +22,7 @@ type BackendType string
 type dbCreator func(name string, dir string) (Database, error)
 
 var backends = map[BackendType]dbCreator{}
+var readOnlyBackends = map[BackendType]dbCreator{}
 
 func registerDBCreator(backend BackendType, creator dbCreator, force bool) {
 	_, ok := backends[backend]
@@ -31,6 +32,10 @@ func registerDBCreator(backend BackendType, creator dbCreator, force bool) {
 	backends[backend] = creator
 }
 
+func registerReadOnlyDBCreator(backend BackendType, creator dbCreator) {
+	readOnlyBackends[backend] = creator
+}
+
 func RegisteredBackendTypes() []string {
 	l := make([]string, 0)
 	for k := range backends {
@@ -44,6 +49,21 @@ func Open(dir, dbtype, name string) (Database, error) {
 	return openDatabase(BackendType(dbtype), name, dir)
 }
 
+// OpenReadOnly opens the existing database for reading. Writing to the
+// database returns an error. It returns UnsupportedError if the backend
+// doesn't support it.
+func OpenReadOnly(dir, dbtype, name string) (Database, error) {
+	if _, ok := backends[BackendType(dbtype)]; !ok {
+		return nil, errors.Errorf("UnknownBackend(type=%s)", dbtype)
+	}
+	dbCreator, ok := readOnlyBackends[BackendType(dbtype)]
+	if !ok {
+		return nil, errors.UnsupportedError.Errorf(
+			"ReadOnlyNotSupported(type=%s)", dbtype)
+	}
+	return dbCreator(name, dir)
+}
+
 func openDatabase(backend BackendType, name string, dir string) (Database, error) {
 	dbCreator, ok := backends[backend]
 	if !ok {
@@ -151,3 +151,145 @@ func TestDatabase_SetReopenGet(t *testing.T) {
 		})
 	}
 }
+
+func collectEntries(t *testing.T, bk Bucket, prefix, start, end []byte) []string {
+	it, err := NewIterator(bk, prefix, start, end)
+	assert.NoError(t, err)
+	defer it.Release()
+	var entries []string
+	for it.Next() {
+		entries = append(entries, string(it.Key())+"="+string(it.Value()))
+	}
+	assert.NoError(t, it.Error())
+	return entries
+}
+
+func testDatabase_Iterator(t *testing.T, creator dbCreator) {
+	dir := t.TempDir()
+	testDB, err := creator("test", dir)
+	assert.NoError(t, err)
+	defer testDB.Close()
+
+	bk, err := testDB.GetBucket("a")
+	assert.NoError(t, err)
+	other, err := testDB.GetBucket("b")
+	assert.NoError(t, err)
+	for _, k := range []string{"k3", "k1", "x1", "k2", "k\xff"} {
+		assert.NoError(t, bk.Set([]byte(k), []byte("v"+k)))
+		assert.NoError(t, other.Set([]byte(k), []byte("o"+k)))
+	}
+
+	assert.Equal(t, []string{"k1=vk1", "k2=vk2", "k3=vk3", "k\xff=vk\xff", "x1=vx1"},
+		collectEntries(t, bk, nil, nil, nil))
+	assert.Equal(t, []string{"k1=vk1", "k2=vk2", "k3=vk3", "k\xff=vk\xff"},
+		collectEntries(t, bk, []byte("k"), nil, nil))
+	assert.Equal(t, []string{"k2=vk2", "k3=vk3"},
+		collectEntries(t, bk, []byte("k"), []byte("k2"), []byte("k4")))
+	assert.Equal(t, []string{"k\xff=vk\xff", "x1=vx1"},
+		collectEntries(t, bk, nil, []byte("k4"), nil))
+	assert.Empty(t, collectEntries(t, bk, []byte("y"), nil, nil))
+}
+
+func testDatabase_Batch(t *testing.T, creator dbCreator) {
+	dir := t.TempDir()
+	testDB, err := creator("test", dir)
+	assert.NoError(t, err)
+	defer testDB.Close()
+
+	bk1, err := testDB.GetBucket("a")
+	assert.NoError(t, err)
+	bk2, err := testDB.GetBucket("b")
+	assert.NoError(t, err)
+	assert.NoError(t, bk1.Set([]byte("k0"), []byte("v0")))
+
+	batcher := BatcherOf(testDB)
+	if !assert.NotNil(t, batcher) {
+		return
+	}
+	batch := batcher.NewBatch()
+	assert.NoError(t, batch.Set("a", []byte("k1"), []byte("v1")))
+	assert.NoError(t, batch.Set("b", []byte("k2"), []byte("v2")))
+	assert.NoError(t, batch.Delete("a", []byte("k0")))
+	assert.Equal(t, 3, batch.Len())
+
+	// nothing is written before Write
+	v, err := bk1.Get([]byte("k1"))
+	assert.NoError(t, err)
+	assert.Nil(t, v)
+	v, err = bk1.Get([]byte("k0"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("v0"), v)
+
+	assert.NoError(t, batch.Write())
+	v, err = bk1.Get([]byte("k1"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("v1"), v)
+	v, err = bk2.Get([]byte("k2"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("v2"), v)
+	has, err := bk1.Has([]byte("k0"))
+	assert.NoError(t, err)
+	assert.False(t, has)
+
+	batch.Reset()
+	assert.Equal(t, 0, batch.Len())
+}
+
+func TestDatabase_IteratorAndBatch(t *testing.T) {
+	creators := map[string]dbCreator{
+		"layerdb": func(name string, dir string) (Database, error) {
+			origin := NewMapDB()
+			return NewLayerDB(origin), nil
+		},
+	}
+	for name, be := range backends {
+		creators[string(name)] = be
+	}
+	for name, creator := range creators {
+		t.Run(name, func(t *testing.T) {
+			t.Run("Iterator", func(t *testing.T) {
+				testDatabase_Iterator(t, creator)
+			})
+			t.Run("Batch", func(t *testing.T) {
+				testDatabase_Batch(t, creator)
+			})
+		})
+	}
+}
+
+func TestLayerDB_IteratorAndFlush(t *testing.T) {
+	dir := t.TempDir()
+	real, err := NewGoLevelDB("test", dir)
+	assert.NoError(t, err)
+	defer real.Close()
+
+	rbk, _ := real.GetBucket("a")
+	for _, k := range []string{"k1", "k2", "k4"} {
+		assert.NoError(t, rbk.Set([]byte(k), []byte("r"+k)))
+	}
+
+	ldb := NewLayerDB(WithFlags(real, Flags{"test": true}))
+	lbk, _ := ldb.GetBucket("a")
+	assert.NoError(t, lbk.Set([]byte("k0"), []byte("lk0")))
+	assert.NoError(t, lbk.Set([]byte("k2"), []byte("lk2")))
+	assert.NoError(t, lbk.Set([]byte("k3"), []byte("lk3")))
+	assert.NoError(t, lbk.Delete([]byte("k4")))
+	lbk2, _ := ldb.GetBucket("b")
+	assert.NoError(t, lbk2.Set([]byte("k1"), []byte("lk1")))
+
+	expected := []string{"k0=lk0", "k1=rk1", "k2=lk2", "k3=lk3"}
+	assert.Equal(t, expected, collectEntries(t, lbk, nil, nil, nil))
+	assert.Equal(t, []string{"k1=rk1", "k2=lk2"},
+		collectEntries(t, lbk, nil, []byte("k1"), []byte("k3")))
+
+	// nothing is written to the real database before Flush
+	assert.Equal(t, []string{"k1=rk1", "k2=rk2", "k4=rk4"},
+		collectEntries(t, rbk, nil, nil, nil))
+
+	assert.NoError(t, ldb.Flush(true))
+	assert.Equal(t, expected, collectEntries(t, rbk, nil, nil, nil))
+	rbk2, _ := real.GetBucket("b")
+	v, err := rbk2.Get([]byte("k1"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("lk1"), v)
+}
@@ -5,7 +5,9 @@ import (
 	"sync"
 
 	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/iterator"
 	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/util"
 )
 
 const GoLevelDBBackend BackendType = "goleveldb"
@@ -15,6 +17,12 @@ func init() {
 		return NewGoLevelDB(name, dir)
 	}
 	registerDBCreator(GoLevelDBBackend, dbCreator, false)
+	registerReadOnlyDBCreator(GoLevelDBBackend, func(name string, dir string) (Database, error) {
+		return NewGoLevelDBWithOpts(name, dir, &opt.Options{
+			ReadOnly:       true,
+			ErrorIfMissing: true,
+		})
+	})
 }
 
 func NewGoLevelDB(name string, dir string) (*GoLevelDB, error) {
@@ -38,6 +46,7 @@ func NewGoLevelDBWithOpts(name string, dir string, o *opt.Options) (*GoLevelDB,
 // Database
 
 var _ Database = (*GoLevelDB)(nil)
+var _ Batcher = (*GoLevelDB)(nil)
 
 type GoLevelDB struct {
 	lock    sync.Mutex
@@ -65,10 +74,44 @@ func (db *GoLevelDB) Close() error {
 	return db.db.Close()
 }
 
+func (db *GoLevelDB) NewBatch() Batch {
+	return &goLevelBatch{db: db.db}
+}
+
+//----------------------------------------
+// Batch
+
+type goLevelBatch struct {
+	db    *leveldb.DB
+	batch leveldb.Batch
+}
+
+func (b *goLevelBatch) Set(id BucketID, key []byte, value []byte) error {
+	b.batch.Put(internalKey(id, key), value)
+	return nil
+}
+
+func (b *goLevelBatch) Delete(id BucketID, key []byte) error {
+	b.batch.Delete(internalKey(id, key))
+	return nil
+}
+
+func (b *goLevelBatch) Len() int {
+	return b.batch.Len()
+}
+
+func (b *goLevelBatch) Write() error {
+	return b.db.Write(&b.batch, nil)
+}
+
+func (b *goLevelBatch) Reset() {
+	b.batch.Reset()
+}
+
 //----------------------------------------
 // GetBucket
 
-var _ Bucket = (*goLevelBucket)(nil)
+var _ IterableBucket = (*goLevelBucket)(nil)
 
 type goLevelBucket struct {
 	id BucketID
@@ -95,3 +138,32 @@ func (bucket *goLevelBucket) Set(key []byte, value []byte) error {
 func (bucket *goLevelBucket) Delete(key []byte) error {
 	return bucket.db.Delete(internalKey(bucket.id, key), nil)
 }
+
+// Iterator returns an iterator for the entries of the bucket.
+// All buckets share one key space, so iteration on the bucket with empty
+// ID(MerkleTrie) also returns entries of other buckets.
+func (bucket *goLevelBucket) Iterator(prefix, start, end []byte) (Iterator, error) {
+	lo, hi := keyRange(prefix, start, end)
+	r := &util.Range{Start: internalKey(bucket.id, lo)}
+	if hi != nil {
+		r.Limit = internalKey(bucket.id, hi)
+	} else {
+		r.Limit = prefixLimit([]byte(bucket.id))
+	}
+	return &goLevelIterator{
+		Iterator: bucket.db.NewIterator(r, nil),
+		offset:   len(bucket.id),
+	}, nil
+}
+
+type goLevelIterator struct {
+	iterator.Iterator
+	offset int
+}
+
+func (it *goLevelIterator) Key() []byte {
+	if key := it.Iterator.Key(); key != nil {
+		return key[it.offset:]
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package db
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/icon-project/goloop/common/errors"
+)
+
+// Iterator iterates entries of the bucket in ascending order of the keys.
+// Key and Value of the current entry are available after Next returns true.
+// They are valid until the next call of Next, and they shouldn't be modified.
+// Release should be called after use.
+type Iterator interface {
+	Next() bool
+	Key() []byte
+	Value() []byte
+	Error() error
+	Release()
+}
+
+// IterableBucket is implemented by the bucket supporting Iterator.
+type IterableBucket interface {
+	Bucket
+
+	// Iterator returns an iterator for the entries whose keys have the
+	// prefix and are in the range [start, end). nil for start or end
+	// means no limit on that side.
+	Iterator(prefix, start, end []byte) (Iterator, error)
+}
+
+// NewIterator returns an iterator of the bucket. It returns UnsupportedError
+// if the bucket doesn't support Iterator.
+func NewIterator(bk Bucket, prefix, start, end []byte) (Iterator, error) {
+	if ibk, ok := bk.(IterableBucket); ok {
+		return ibk.Iterator(prefix, start, end)
+	}
+	return nil, errors.UnsupportedError.New("IteratorNotSupported")
+}
+
+// prefixLimit returns the smallest key greater than all keys having the prefix.
+// It returns nil if there is no such key.
+func prefixLimit(prefix []byte) []byte {
+	for i := len(prefix) - 1; i >= 0; i-- {
+		if prefix[i] < 0xff {
+			limit := make([]byte, i+1)
+			copy(limit, prefix)
+			limit[i] += 1
+			return limit
+		}
+	}
+	return nil
+}
+
+// keyRange returns the range [lo, hi) of the keys having the prefix and in
+// the range [start, end). nil hi means no upper limit.
+func keyRange(prefix, start, end []byte) ([]byte, []byte) {
+	lo, hi := prefix, prefixLimit(prefix)
+	if start != nil && bytes.Compare(start, lo) > 0 {
+		lo = start
+	}
+	if end != nil && (hi == nil || bytes.Compare(end, hi) < 0) {
+		hi = end
+	}
+	return lo, hi
+}
+
+func inKeyRange(key, lo, hi []byte) bool {
+	return bytes.Compare(key, lo) >= 0 && (hi == nil || bytes.Compare(key, hi) < 0)
+}
+
+type kvEntry struct {
+	key   []byte
+	value []byte
+}
+
+func sortEntries(entries []kvEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		return bytes.Compare(entries[i].key, entries[j].key) < 0
+	})
+}
+
+// sliceIterator iterates sorted entries in the memory.
+type sliceIterator struct {
+	entries []kvEntry
+	index   int
+}
+
+func (it *sliceIterator) Next() bool {
+	if it.index < len(it.entries) {
+		it.index += 1
+	}
+	return it.index < len(it.entries)
+}
+
+func (it *sliceIterator) Key() []byte {
+	if it.index < 0 || it.index >= len(it.entries) {
+		return nil
+	}
+	return it.entries[it.index].key
+}
+
+func (it *sliceIterator) Value() []byte {
+	if it.index < 0 || it.index >= len(it.entries) {
+		return nil
+	}
+	return it.entries[it.index].value
+}
+
+func (it *sliceIterator) Error() error {
+	return nil
+}
+
+func (it *sliceIterator) Release() {
+	it.entries = nil
+	it.index = 0
+}
+
+func newSliceIterator(entries []kvEntry) *sliceIterator {
+	return &sliceIterator{entries: entries, index: -1}
+}
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"bytes"
 	"sync"
 
 	"github.com/icon-project/goloop/common/errors"
@@ -8,6 +9,7 @@ import (
 
 type layerBucket struct {
 	lock sync.Mutex
+	id   BucketID
 	data map[string][]byte
 	real Bucket
 }
@@ -66,6 +68,50 @@ func (bk *layerBucket) Delete(key []byte) error {
 	}
 }
 
+func (bk *layerBucket) Iterator(prefix, start, end []byte) (Iterator, error) {
+	bk.lock.Lock()
+	defer bk.lock.Unlock()
+
+	real, err := NewIterator(bk.real, prefix, start, end)
+	if err != nil || bk.data == nil {
+		return real, err
+	}
+	lo, hi := keyRange(prefix, start, end)
+	var entries []kvEntry
+	for k, v := range bk.data {
+		key := []byte(k)
+		if inKeyRange(key, lo, hi) {
+			entries = append(entries, kvEntry{key, v})
+		}
+	}
+	sortEntries(entries)
+	it := &layerIterator{
+		layer: entries,
+		real:  real,
+	}
+	it.hasReal = real.Next()
+	return it, nil
+}
+
+// flushTo puts changes in the layer to the batch.
+func (bk *layerBucket) flushTo(batch Batch) error {
+	bk.lock.Lock()
+	defer bk.lock.Unlock()
+
+	for k, v := range bk.data {
+		if v == nil {
+			if err := batch.Delete(bk.id, []byte(k)); err != nil {
+				return err
+			}
+		} else {
+			if err := batch.Set(bk.id, []byte(k), v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func (bk *layerBucket) Flush(write bool) error {
 	bk.lock.Lock()
 	defer bk.lock.Unlock()
@@ -87,6 +133,65 @@ func (bk *layerBucket) Flush(write bool) error {
 	return nil
 }
 
+// layerIterator merges changes in the layer with entries of the real bucket.
+// A deleted entry in the layer has nil value.
+type layerIterator struct {
+	layer   []kvEntry
+	real    Iterator
+	hasReal bool
+	key     []byte
+	value   []byte
+}
+
+func (it *layerIterator) Next() bool {
+	for {
+		hasLayer := len(it.layer) > 0
+		if !hasLayer && !it.hasReal {
+			it.key, it.value = nil, nil
+			return false
+		}
+		if hasLayer {
+			var cmp int
+			if it.hasReal {
+				cmp = bytes.Compare(it.layer[0].key, it.real.Key())
+			}
+			if !it.hasReal || cmp <= 0 {
+				entry := it.layer[0]
+				it.layer = it.layer[1:]
+				if it.hasReal && cmp == 0 {
+					it.hasReal = it.real.Next()
+				}
+				if entry.value == nil {
+					continue
+				}
+				it.key, it.value = entry.key, entry.value
+				return true
+			}
+		}
+		it.key = append(it.key[:0:0], it.real.Key()...)
+		it.value = append(it.value[:0:0], it.real.Value()...)
+		it.hasReal = it.real.Next()
+		return true
+	}
+}
+
+func (it *layerIterator) Key() []byte {
+	return it.key
+}
+
+func (it *layerIterator) Value() []byte {
+	return it.value
+}
+
+func (it *layerIterator) Error() error {
+	return it.real.Error()
+}
+
+func (it *layerIterator) Release() {
+	it.layer = nil
+	it.real.Release()
+}
+
 type layerDB struct {
 	lock sync.Mutex
 
@@ -99,6 +204,10 @@ func (ldb *layerDB) GetBucket(id BucketID) (Bucket, error) {
 	ldb.lock.Lock()
 	defer ldb.lock.Unlock()
 
+	return ldb.getBucketInLock(id)
+}
+
+func (ldb *layerDB) getBucketInLock(id BucketID) (Bucket, error) {
 	if bk, ok := ldb.buckets[string(id)]; ok {
 		return bk, nil
 	}
@@ -111,6 +220,7 @@ func (ldb *layerDB) GetBucket(id BucketID) (Bucket, error) {
 		return realbk, nil
 	}
 	bk := &layerBucket{
+		id:   id,
 		data: make(map[string][]byte),
 		real: realbk,
 	}
@@ -118,10 +228,27 @@ func (ldb *layerDB) GetBucket(id BucketID) (Bucket, error) {
 	return bk, nil
 }
 
+// Flush writes changes in the layer to the real database if write is true,
+// then it passes following requests to the real database. If the real
+// database supports Batch, then all changes are written atomically.
 func (ldb *layerDB) Flush(write bool) error {
 	ldb.lock.Lock()
 	defer ldb.lock.Unlock()
 
+	if write && !ldb.flushed {
+		if batcher := BatcherOf(ldb.real); batcher != nil {
+			batch := batcher.NewBatch()
+			for _, bk := range ldb.buckets {
+				if err := bk.flushTo(batch); err != nil {
+					return err
+				}
+			}
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			write = false
+		}
+	}
 	for _, bk := range ldb.buckets {
 		if err := bk.Flush(write); err != nil {
 			return err
@@ -135,11 +262,45 @@ func (ldb *layerDB) Close() error {
 	return nil
 }
 
+// NewBatch returns a batch applying changes to the layer. After Flush, the
+// changes are written to the real database.
+func (ldb *layerDB) NewBatch() Batch {
+	return newMemBatch(ldb.writeBatch)
+}
+
+func (ldb *layerDB) writeBatch(ops []batchOp) error {
+	ldb.lock.Lock()
+	defer ldb.lock.Unlock()
+
+	if ldb.flushed {
+		return writeBatchTo(ldb.real, ops)
+	}
+	for _, op := range ops {
+		bk, err := ldb.getBucketInLock(op.id)
+		if err != nil {
+			return err
+		}
+		if op.delete {
+			err = bk.Delete(op.key)
+		} else {
+			err = bk.Set(op.key, op.value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 type layerDBContext struct {
 	LayerDB
 	flags Flags
 }
 
+func (c *layerDBContext) database() Database {
+	return c.LayerDB
+}
+
 func (c *layerDBContext) WithFlags(flags Flags) Context {
 	newFlags := c.flags.Merged(flags)
 	return &layerDBContext{c.LayerDB, newFlags}
@@ -33,6 +33,7 @@ func NewMapDB() Database {
 // DB
 
 var _ Database = (*mapDatabase)(nil)
+var _ Batcher = (*mapDatabase)(nil)
 
 type mapDatabase struct {
 	lock sync.Mutex
@@ -44,15 +45,43 @@ func (t *mapDatabase) GetBucket(id BucketID) (Bucket, error) {
 	t.lock.Lock()
 	defer t.lock.Unlock()
 
+	return t.getBucketInLock(id), nil
+}
+
+func (t *mapDatabase) getBucketInLock(id BucketID) *mapBucket {
 	if bk, ok := t.bks[id]; ok {
-		return bk, nil
+		return bk
 	}
 	bk := &mapBucket{
 		id:   fmt.Sprintf("%s:%s", t.name, id),
 		real: make(map[string]string),
 	}
 	t.bks[id] = bk
-	return bk, nil
+	return bk
+}
+
+func (t *mapDatabase) NewBatch() Batch {
+	return newMemBatch(t.writeBatch)
+}
+
+func (t *mapDatabase) writeBatch(ops []batchOp) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for _, op := range ops {
+		if len(op.key) == 0 {
+			return errors.Errorf("Illegal Key:%x", op.key)
+		}
+	}
+	for _, op := range ops {
+		bk := t.getBucketInLock(op.id)
+		if op.delete {
+			bk.Delete(op.key)
+		} else {
+			bk.Set(op.key, op.value)
+		}
+	}
+	return nil
 }
 
 func (t *mapDatabase) Close() error {
@@ -62,7 +91,7 @@ func (t *mapDatabase) Close() error {
 //----------------------------------------
 // Bucket
 
-var _ Bucket = (*mapBucket)(nil)
+var _ IterableBucket = (*mapBucket)(nil)
 
 type mapBucket struct {
 	id    string
@@ -119,3 +148,19 @@ func (t *mapBucket) Delete(k []byte) error {
 	delete(t.real, string(k))
 	return nil
 }
+
+func (t *mapBucket) Iterator(prefix, start, end []byte) (Iterator, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	lo, hi := keyRange(prefix, start, end)
+	var entries []kvEntry
+	for k, v := range t.real {
+		key := []byte(k)
+		if inKeyRange(key, lo, hi) {
+			entries = append(entries, kvEntry{key, []byte(v)})
+		}
+	}
+	sortEntries(entries)
+	return newSliceIterator(entries), nil
+}
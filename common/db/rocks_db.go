@@ -20,10 +20,12 @@
 package db
 
 import (
+	"bytes"
 	"errors"
 	"os"
 	"path"
 	"reflect"
+	"runtime"
 	"sync"
 	"unsafe"
 
@@ -44,11 +46,18 @@ func init() {
 		return NewRocksDB(name, dir)
 	}
 	registerDBCreator(RocksDBBackend, dbCreator, false)
+	registerReadOnlyDBCreator(RocksDBBackend, func(name string, dir string) (Database, error) {
+		return newRocksDB(name, dir, true)
+	})
 }
 
+var _ Batcher = (*RocksDB)(nil)
+var _ IterableBucket = (*RocksBucket)(nil)
+
 type RocksDB struct {
-	lock    sync.Mutex
-	buckets map[BucketID]*RocksBucket
+	lock     sync.Mutex
+	buckets  map[BucketID]*RocksBucket
+	readOnly bool
 
 	db *C.rocksdb_t
 	ro *C.rocksdb_readoptions_t
@@ -56,6 +65,10 @@ type RocksDB struct {
 }
 
 func NewRocksDB(name string, dir string) (*RocksDB, error) {
+	return newRocksDB(name, dir, false)
+}
+
+func newRocksDB(name string, dir string, readOnly bool) (*RocksDB, error) {
 	if err := os.MkdirAll(dir, 0700); err != nil {
 		log.Errorln("fail to MkdirAll", err.Error())
 		return nil, err
@@ -80,7 +93,11 @@ func NewRocksDB(name string, dir string) (*RocksDB, error) {
 
 		//ignore and try open
 		cErr = nil
-		hdl = C.rocksdb_open(opts, cName, &cErr)
+		if readOnly {
+			hdl = C.rocksdb_open_for_read_only(opts, cName, C.uchar(0), &cErr)
+		} else {
+			hdl = C.rocksdb_open(opts, cName, &cErr)
+		}
 		if cErr != nil {
 			errMsg = C.GoString(cErr)
 			defer C.rocksdb_free(unsafe.Pointer(cErr))
@@ -96,14 +113,26 @@ func NewRocksDB(name string, dir string) (*RocksDB, error) {
 			cfOpts[i] = C.rocksdb_options_create()
 		}
 		cfhs := make([]*C.rocksdb_column_family_handle_t, numOfCfs)
-		hdl = C.rocksdb_open_column_families(
-			opts,
-			cName,
-			C.int(numOfCfs),
-			cfs,
-			&cfOpts[0],
-			&cfhs[0],
-			&cErr)
+		if readOnly {
+			hdl = C.rocksdb_open_for_read_only_column_families(
+				opts,
+				cName,
+				C.int(numOfCfs),
+				cfs,
+				&cfOpts[0],
+				&cfhs[0],
+				C.uchar(0),
+				&cErr)
+		} else {
+			hdl = C.rocksdb_open_column_families(
+				opts,
+				cName,
+				C.int(numOfCfs),
+				cfs,
+				&cfOpts[0],
+				&cfhs[0],
+				&cErr)
+		}
 		if cErr != nil {
 			errMsg := C.GoString(cErr)
 			defer C.rocksdb_free(unsafe.Pointer(cErr))
@@ -129,10 +158,11 @@ func NewRocksDB(name string, dir string) (*RocksDB, error) {
 	ro := C.rocksdb_readoptions_create()
 	wo := C.rocksdb_writeoptions_create()
 	rdb := &RocksDB{
-		db:      hdl,
-		ro:      ro,
-		wo:      wo,
-		buckets: buckets,
+		db:       hdl,
+		ro:       ro,
+		wo:       wo,
+		buckets:  buckets,
+		readOnly: readOnly,
 	}
 	if len(buckets) > 0 {
 		for _, bk := range buckets {
@@ -157,6 +187,9 @@ func (db *RocksDB) GetBucket(id BucketID) (Bucket, error) {
 	if bk, ok := db.buckets[id]; ok {
 		return bk, nil
 	}
+	if db.readOnly {
+		return nil, errors.New("NoBucketInReadOnlyDB")
+	}
 
 	cName := C.CString(string(id))
 	defer C.free(unsafe.Pointer(cName))
@@ -178,6 +211,24 @@ func (db *RocksDB) GetBucket(id BucketID) (Bucket, error) {
 	return bk, nil
 }
 
+func (db *RocksDB) NewBatch() Batch {
+	b := &RocksBatch{
+		db:    db,
+		batch: C.rocksdb_writebatch_create(),
+	}
+	runtime.SetFinalizer(b, func(b *RocksBatch) {
+		C.rocksdb_writebatch_destroy(b.batch)
+	})
+	return b
+}
+
+func cBytes(b []byte) *C.char {
+	if len(b) == 0 {
+		return nil
+	}
+	return (*C.char)(unsafe.Pointer(&b[0]))
+}
+
 func (db *RocksDB) getValue(cf *C.rocksdb_column_family_handle_t, k []byte) ([]byte, error) {
 	var (
 		cErr    *C.char
@@ -266,3 +317,128 @@ func (b *RocksBucket) Set(key []byte, value []byte) error {
 func (b *RocksBucket) Delete(key []byte) error {
 	return b.db.deleteValue(b.cf, key)
 }
+
+func (b *RocksBucket) Iterator(prefix, start, end []byte) (Iterator, error) {
+	lo, hi := keyRange(prefix, start, end)
+	it := &RocksIterator{
+		iter: C.rocksdb_create_iterator_cf(b.db.db, b.db.ro, b.cf),
+		hi:   hi,
+	}
+	if len(lo) > 0 {
+		C.rocksdb_iter_seek(it.iter, cBytes(lo), C.size_t(len(lo)))
+	} else {
+		C.rocksdb_iter_seek_to_first(it.iter)
+	}
+	return it, nil
+}
+
+type RocksBatch struct {
+	db    *RocksDB
+	batch *C.rocksdb_writebatch_t
+}
+
+func (b *RocksBatch) columnFamily(id BucketID) (*C.rocksdb_column_family_handle_t, error) {
+	bk, err := b.db.GetBucket(id)
+	if err != nil {
+		return nil, err
+	}
+	return bk.(*RocksBucket).cf, nil
+}
+
+func (b *RocksBatch) Set(id BucketID, key []byte, value []byte) error {
+	cf, err := b.columnFamily(id)
+	if err != nil {
+		return err
+	}
+	C.rocksdb_writebatch_put_cf(b.batch, cf,
+		cBytes(key), C.size_t(len(key)), cBytes(value), C.size_t(len(value)))
+	return nil
+}
+
+func (b *RocksBatch) Delete(id BucketID, key []byte) error {
+	cf, err := b.columnFamily(id)
+	if err != nil {
+		return err
+	}
+	C.rocksdb_writebatch_delete_cf(b.batch, cf, cBytes(key), C.size_t(len(key)))
+	return nil
+}
+
+func (b *RocksBatch) Len() int {
+	return int(C.rocksdb_writebatch_count(b.batch))
+}
+
+func (b *RocksBatch) Write() error {
+	var cErr *C.char
+	C.rocksdb_write(b.db.db, b.db.wo, b.batch, &cErr)
+	if cErr != nil {
+		defer C.rocksdb_free(unsafe.Pointer(cErr))
+		return errors.New(C.GoString(cErr))
+	}
+	return nil
+}
+
+func (b *RocksBatch) Reset() {
+	C.rocksdb_writebatch_clear(b.batch)
+}
+
+type RocksIterator struct {
+	iter    *C.rocksdb_iterator_t
+	hi      []byte
+	started bool
+	key     []byte
+	value   []byte
+}
+
+func (it *RocksIterator) Next() bool {
+	if it.iter == nil {
+		return false
+	}
+	if it.started {
+		C.rocksdb_iter_next(it.iter)
+	}
+	it.started = true
+	it.key, it.value = nil, nil
+	if C.rocksdb_iter_valid(it.iter) == 0 {
+		return false
+	}
+	var cLen C.size_t
+	cKey := C.rocksdb_iter_key(it.iter, &cLen)
+	key := C.GoBytes(unsafe.Pointer(cKey), C.int(cLen))
+	if it.hi != nil && bytes.Compare(key, it.hi) >= 0 {
+		return false
+	}
+	cValue := C.rocksdb_iter_value(it.iter, &cLen)
+	it.key = key
+	it.value = C.GoBytes(unsafe.Pointer(cValue), C.int(cLen))
+	return true
+}
+
+func (it *RocksIterator) Key() []byte {
+	return it.key
+}
+
+func (it *RocksIterator) Value() []byte {
+	return it.value
+}
+
+func (it *RocksIterator) Error() error {
+	if it.iter == nil {
+		return nil
+	}
+	var cErr *C.char
+	C.rocksdb_iter_get_error(it.iter, &cErr)
+	if cErr != nil {
+		defer C.rocksdb_free(unsafe.Pointer(cErr))
+		return errors.New(C.GoString(cErr))
+	}
+	return nil
+}
+
+func (it *RocksIterator) Release() {
+	if it.iter != nil {
+		C.rocksdb_iter_destroy(it.iter)
+		it.iter = nil
+	}
+	it.key, it.value = nil, nil
+}
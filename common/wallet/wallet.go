@@ -1,8 +1,14 @@
 package wallet
 
 import (
+	"crypto/sha256"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+
 	"github.com/icon-project/goloop/common"
 	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/errors"
 	"github.com/icon-project/goloop/module"
 )
 
@@ -42,3 +48,20 @@ func NewFromPrivateKey(sk *crypto.PrivateKey) (module.Wallet, error) {
 		pkey: pk,
 	}, nil
 }
+
+// DeriveSecret derives a secret of the size from the private key of the
+// wallet. The purpose makes secrets independent of each other, so keys of
+// other signature algorithms can be derived from the wallet. It fails if the
+// wallet doesn't hold the private key (ex. plugin wallet).
+func DeriveSecret(w module.Wallet, purpose string, size int) ([]byte, error) {
+	sw, ok := w.(*softwareWallet)
+	if !ok {
+		return nil, errors.UnsupportedError.Errorf("NotSupportedWallet(type=%T)", w)
+	}
+	secret := make([]byte, size)
+	r := hkdf.New(sha256.New, sw.skey.Bytes(), nil, []byte(purpose))
+	if _, err := io.ReadFull(r, secret); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
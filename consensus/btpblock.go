@@ -18,6 +18,8 @@ package consensus
 
 import (
 	"github.com/icon-project/goloop/block"
+	"github.com/icon-project/goloop/btp/ntm"
+	"github.com/icon-project/goloop/common/errors"
 	"github.com/icon-project/goloop/module"
 )
 
@@ -61,7 +63,36 @@ func (cs *consensus) GetBTPBlockHeaderAndProof(
 		if err != nil {
 			return btpBlk, nil, err
 		}
-		proof = cvs.NTSDProofAt(idx)
+		proof, err = aggregateNTSDProof(bd.NetworkTypeDigestFor(ntid), cvs.NTSDProofAt(idx))
+		if err != nil {
+			return btpBlk, nil, err
+		}
 	}
 	return btpBlk, proof, nil
 }
+
+// aggregateNTSDProof returns the aggregated proof if signatures in the proof
+// can be aggregated by the network type module. Otherwise, it returns the
+// proof as it is.
+func aggregateNTSDProof(ntd module.NetworkTypeDigest, proof []byte) ([]byte, error) {
+	if ntd == nil {
+		return proof, nil
+	}
+	mod := ntm.ForUID(ntd.UID())
+	if mod == nil {
+		return nil, errors.NotFoundError.Errorf("no network type module uid=%s", ntd.UID())
+	}
+	pf, err := mod.NewProofFromBytes(proof)
+	if err != nil {
+		return nil, err
+	}
+	apf, ok := pf.(module.BTPAggregatableProof)
+	if !ok {
+		return proof, nil
+	}
+	pf, err = apf.Aggregate()
+	if err != nil {
+		return nil, err
+	}
+	return pf.Bytes(), nil
+}
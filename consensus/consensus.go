@@ -36,13 +36,6 @@ type LastVoteData struct {
 	VotesBytes []byte
 }
 
-const (
-	timeoutPropose   = time.Second * 1
-	timeoutPrevote   = time.Second * 1
-	timeoutPrecommit = time.Second * 1
-	timeoutNewRound  = time.Second * 1
-)
-
 const (
 	ConfigEnginePriority = 2
 	ConfigSyncerPriority = 3
@@ -155,7 +148,11 @@ type consensus struct {
 	members            module.MemberList
 	minimizeBlockGen   bool
 	roundLimit         int32
+	timeouts           timeouts
+	proposeTime        time.Time
 	sentPatch          bool
+	evidences          map[string]bool
+	evidenceQueue      []evidence
 	lastVotes          VoteSet
 	hvs                heightVoteSet
 	nextProposeTime    time.Time
@@ -257,7 +254,9 @@ func (cs *consensus) _resetForNewHeight(prevBlock module.Block, votes *voteSet)
 	}
 	cs.minimizeBlockGen = cs.c.ServiceManager().GetMinimizeBlockGen(cs.lastBlock.Result())
 	cs.roundLimit = int32(cs.c.ServiceManager().GetRoundLimit(cs.lastBlock.Result(), cs.validators.Len()))
+	cs.timeouts.set(cs.c.ServiceManager().GetConsensusTimeouts(cs.lastBlock.Result()))
 	cs.sentPatch = false
+	cs.evidences = make(map[string]bool)
 	cs.lastVotes = votes
 	cs.hvs.reset(cs.validators.Len())
 	cs.lockedRound = -1
@@ -442,6 +441,7 @@ func (cs *consensus) ReceiveBlockPartMessage(msg *BlockPartMessage, unicast bool
 		} else {
 			cs.currentBlockParts.block = block
 		}
+		cs.onProposalComplete()
 	}
 
 	if (cs.step == stepTransactionWait || cs.step == stepPropose) && cs.isProposalAndPOLPrevotesComplete() {
@@ -483,6 +483,9 @@ func (cs *consensus) ReceiveVoteMessage(msg *VoteMessage, unicast bool) (int, er
 	if err != nil {
 		return -1, err
 	}
+	if omsg := cs.hvs.conflictOf(index, msg); omsg != nil {
+		cs.reportDoubleSign(omsg, msg)
+	}
 	added, votes := cs.hvs.add(index, msg)
 	if !added {
 		return -1, nil
@@ -502,6 +505,60 @@ func (cs *consensus) ReceiveVoteMessage(msg *VoteMessage, unicast bool) (int, er
 	return index, nil
 }
 
+// evidence is a detected double signing waiting to be submitted.
+type evidence struct {
+	patch *doubleSignPatch
+	// send is true if the patch needs to be sent to the service manager
+	send bool
+}
+
+// reportDoubleSign queues the evidence of the conflicting votes, and it's
+// submitted by submitEvidences after the lock is released.
+func (cs *consensus) reportDoubleSign(v1, v2 *VoteMessage) {
+	ev := newDoubleSignPatch(v1, v2)
+	key := ev.key()
+	if cs.evidences[key] {
+		return
+	}
+	cs.evidences[key] = true
+	cs.log.Warnf("double sign detected signer=%v H=%d R=%d type=%v", v1.address(), v1.Height, v1.Round, v1.Type)
+
+	cs.evidenceQueue = append(cs.evidenceQueue, evidence{
+		patch: ev,
+		send:  cs.validators.IndexOf(cs.c.Wallet().Address()) >= 0,
+	})
+	if len(cs.evidenceQueue) == 1 {
+		cs.mutex.CallAfterUnlock(cs.submitEvidences)
+	}
+}
+
+// submitEvidences gossips the conflicting votes of queued evidences, so that
+// other nodes may detect them, and sends the evidences to the service manager
+// to be included in a block as patches. It shall be called without the lock.
+func (cs *consensus) submitEvidences() {
+	cs.mutex.Lock()
+	evs := cs.evidenceQueue
+	cs.evidenceQueue = nil
+	cs.mutex.Unlock()
+
+	for _, ev := range evs {
+		msg := newVoteListMessage()
+		msg.VoteList = &ev.patch.VoteList
+		if bs, err := msgCodec.MarshalToBytes(msg); err != nil {
+			cs.log.Warnf("fail to marshal evidence: %+v", err)
+		} else if err = cs.ph.Multicast(ProtoVoteList, bs, module.ROLE_VALIDATOR); err != nil {
+			cs.log.Warnf("fail to gossip evidence: %+v", err)
+		}
+
+		if !ev.send {
+			continue
+		}
+		if err := cs.c.ServiceManager().SendPatch(ev.patch); err != nil {
+			cs.log.Warnf("fail to send double sign patch: %+v", err)
+		}
+	}
+}
+
 func (cs *consensus) ReceiveVoteListMessage(msg *voteListMessage, unicast bool) error {
 	var err error
 	for i := 0; i < msg.VoteList.Len(); i++ {
@@ -601,14 +658,18 @@ func (cs *consensus) enterPropose() {
 
 	now := time.Now()
 	if int(cs.round) > cs.validators.Len()*configRoundTimeoutThresholdFactor {
-		cs.nextProposeTime = now.Add(timeoutNewRound)
+		cs.nextProposeTime = now.Add(cs.timeouts.newRound(cs.round))
 	} else {
 		cs.nextProposeTime = now
 	}
+	cs.proposeTime = now
 	cs.c.Regulator().OnPropose(now)
 
+	timeout := cs.timeouts.propose(cs.round)
+	cs.metric.OnProposeTimeout(timeout)
+
 	hrs := cs.hrs
-	cs.timer = time.AfterFunc(timeoutPropose, func() {
+	cs.timer = time.AfterFunc(timeout, func() {
 		cs.mutex.Lock()
 		defer cs.mutex.Unlock()
 
@@ -662,6 +723,7 @@ func (cs *consensus) enterPropose() {
 
 					cs.sendProposal(bps, -1)
 					cs.currentBlockParts.Set(bps, blk, blk)
+					cs.onProposalComplete()
 					cs.enterPrevote()
 				},
 			)
@@ -696,6 +758,18 @@ func (cs *consensus) proposalHasValidProposer() bool {
 	return true
 }
 
+// onProposalComplete measures latency of the proposal from entering
+// propose step until all block parts of the proposal are prepared.
+func (cs *consensus) onProposalComplete() {
+	if cs.proposeTime.IsZero() {
+		return
+	}
+	latency := time.Since(cs.proposeTime)
+	cs.proposeTime = time.Time{}
+	cs.timeouts.onProposal(latency)
+	cs.metric.OnProposalLatency(latency)
+}
+
 func (cs *consensus) enterPrevote() {
 	cs.resetForNewStep(stepPrevote)
 
@@ -789,7 +863,7 @@ func (cs *consensus) enterPrevoteWait() {
 		cs.enterPrecommit()
 	} else {
 		hrs := cs.hrs
-		cs.timer = time.AfterFunc(timeoutPrevote, func() {
+		cs.timer = time.AfterFunc(cs.timeouts.prevote(cs.round), func() {
 			cs.mutex.Lock()
 			defer cs.mutex.Unlock()
 
@@ -887,7 +961,7 @@ func (cs *consensus) enterPrecommitWait() {
 	} else {
 		cs.log.Traceln("enterPrecommitWait: start timer")
 		hrs := cs.hrs
-		cs.timer = time.AfterFunc(timeoutPrecommit, func() {
+		cs.timer = time.AfterFunc(cs.timeouts.precommit(cs.round), func() {
 			cs.mutex.Lock()
 			defer cs.mutex.Unlock()
 
@@ -2035,7 +2109,7 @@ func (cs *consensus) processBlock(br fastsync.BlockResult) {
 
 	cvl := NewCommitVoteSetFromBytes(br.Votes())
 	if cvl == nil {
-		br.Reject()
+		br.RejectInvalid()
 		return
 	}
 
@@ -19,6 +19,7 @@ package consensus_test
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -27,6 +28,7 @@ import (
 	"github.com/icon-project/goloop/consensus"
 	"github.com/icon-project/goloop/consensus/fastsync"
 	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/network"
 	"github.com/icon-project/goloop/service/platform/basic"
 	"github.com/icon-project/goloop/test"
 )
@@ -240,8 +242,10 @@ type btpTest struct {
 }
 
 func newBTPTest(t *testing.T) *btpTest {
-	const dsa = "ecdsa/secp256k1"
-	const uid = "eth"
+	return newBTPTestWith(t, "ecdsa/secp256k1", "eth")
+}
+
+func newBTPTestWith(t *testing.T, dsa string, uid string) *btpTest {
 	assert := assert.New(t)
 	f := test.NewFixture(t, test.AddDefaultNode(false), test.AddValidatorNodes(4))
 
@@ -342,6 +346,54 @@ func TestConsensus_BTPBasic(t *testing.T) {
 	assert.NoError(err)
 }
 
+func TestConsensus_BTPBLS(t *testing.T) {
+	tst := newBTPTestWith(t, "bls12-381", "bls")
+	defer tst.Close()
+	f := tst.Fixture
+	assert := tst.Assertions
+
+	f.WaitForBlock(2)
+	testMsg := ([]byte)("test message")
+	blk := f.SendTXToAllAndWaitForResultBlock(
+		f.NewTx().CallFrom(f.CommonAddress(), "sendBTPMessage", map[string]string{
+			"networkId": "0x1",
+			"message":   fmt.Sprintf("0x%x", testMsg),
+		}),
+	)
+	bd, err := blk.BTPDigest()
+	assert.NoError(err)
+
+	bbh, pfBytes, err := f.CS.GetBTPBlockHeaderAndProof(
+		blk, 1,
+		module.FlagBTPBlockHeader|module.FlagBTPBlockProof,
+	)
+	assert.NoError(err)
+	prevBlk, err := f.BM.GetBlockByHeight(blk.Height() - 1)
+	assert.NoError(err)
+	pcm, err := prevBlk.NextProofContextMap()
+	assert.NoError(err)
+	pc, err := pcm.ProofContextFor(1)
+	assert.NoError(err)
+	assert.EqualValues("bls12-381", pc.DSA())
+
+	// proof in commit votes keeps signature of each validator
+	cvs, err := f.CS.GetVotesByHeight(blk.Height())
+	assert.NoError(err)
+	ntsdProof := cvs.NTSDProofAt(0)
+	pf, err := pc.NewProofFromBytes(ntsdProof)
+	assert.NoError(err)
+	assert.NotNil(pf.ProofPartAt(0))
+
+	// proof for relay has only aggregated signature
+	assert.True(len(pfBytes) < len(ntsdProof))
+	pf, err = pc.NewProofFromBytes(pfBytes)
+	assert.NoError(err)
+	assert.EqualValues(len(f.Validators), pf.ValidatorCount())
+	assert.Nil(pf.ProofPartAt(0))
+	ntsd := pc.NewDecision(module.SourceNetworkUID(1), 1, blk.Height(), bbh.Round(), bd.NetworkTypeDigestFor(1).NetworkTypeSectionHash())
+	assert.NoError(pc.Verify(ntsd.Hash(), pf))
+}
+
 func TestConsensus_BTPBlockBasic(t_ *testing.T) {
 	assert := assert.New(t_)
 	f := test.NewFixture(t_, test.AddDefaultNode(false), test.AddValidatorNodes(4))
@@ -733,3 +785,73 @@ func TestConsensus_Sync(t *testing.T) {
 	blk = nd.WaitForBlock(10)
 	assert.EqualValues(10, blk.Height())
 }
+
+func TestConsensus_PartitionAndHeal(t *testing.T) {
+	f := test.NewFixture(t, test.AddDefaultNode(false), test.AddValidatorNodes(4))
+	defer f.Close()
+
+	faults := network.NewFaults(1)
+	f.SetFaultModel(faults)
+	f.SendTransactionToProposer(test.NewTx().Call("setMinimizeBlockGen", map[string]string{
+		"yn": "0x1",
+	}))
+	test.NodeInterconnect(f.Nodes)
+	for _, n := range f.Nodes {
+		assert.NoError(t, n.CS.Start())
+	}
+	f.WaitForBlock(2)
+
+	addresses := func(nodes ...*test.Node) []string {
+		var res []string
+		for _, n := range nodes {
+			res = append(res, n.Address().String())
+		}
+		return res
+	}
+	heights := func() []int64 {
+		var res []int64
+		for _, n := range f.Nodes {
+			blk, err := n.BM.GetLastBlock()
+			assert.NoError(t, err)
+			res = append(res, blk.Height())
+		}
+		return res
+	}
+
+	// no group has votes more than 2/3 of the validators
+	v := f.Validators
+	faults.Partition(addresses(v[0], v[1]), addresses(v[2], v[3]))
+	time.Sleep(100 * time.Millisecond)
+	hs := heights()
+	tx := f.NewTx()
+	f.SendTransactionToAll(tx)
+	time.Sleep(2 * time.Second)
+	assert.Equal(t, hs, heights())
+
+	// all nodes finalize the transaction after healing
+	faults.Heal()
+	for h := hs[0] + 1; ; h++ {
+		if f.TXInBlock(tx, f.WaitForBlock(h)) {
+			break
+		}
+	}
+
+	// others make progress without the isolated validator, and it catches
+	// up after healing
+	faults.Partition(addresses(v[0]), addresses(v[1], v[2], v[3]))
+	tx = f.NewTx()
+	f.SendTransactionToAll(tx)
+	var blk module.Block
+	for h := f.Height + 1; ; h++ {
+		blk = test.NodeWaitForBlock(v[1:], h)
+		if f.TXInBlock(tx, blk) {
+			break
+		}
+	}
+	last, err := v[0].BM.GetLastBlock()
+	assert.NoError(t, err)
+	assert.Less(t, last.Height(), blk.Height())
+
+	faults.Heal()
+	assert.Equal(t, blk.ID(), v[0].WaitForBlock(blk.Height()).ID())
+}
@@ -0,0 +1,151 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package consensus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/common/wallet"
+	"github.com/icon-project/goloop/consensus/internal/test"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/state"
+)
+
+type tVoteListReactor struct {
+	ch chan *voteListMessage
+}
+
+func (r *tVoteListReactor) OnReceive(pi module.ProtocolInfo, b []byte, id module.PeerID) (bool, error) {
+	msg, err := UnmarshalMessage(pi.Uint16(), b)
+	if err != nil {
+		return false, err
+	}
+	if vlm, ok := msg.(*voteListMessage); ok {
+		r.ch <- vlm
+	}
+	return true, nil
+}
+
+func (r *tVoteListReactor) OnFailure(err error, pi module.ProtocolInfo, b []byte) {
+}
+
+func (r *tVoteListReactor) OnJoin(id module.PeerID) {
+}
+
+func (r *tVoteListReactor) OnLeave(id module.PeerID) {
+}
+
+type doubleSignTestSetUp struct {
+	t       *testing.T
+	cs      *consensus
+	sm      *test.ServiceManager
+	reactor *tVoteListReactor
+	signer  module.Wallet
+}
+
+func newDoubleSignTestSetUp(t *testing.T, validator bool) *doubleSignTestSetUp {
+	nm := test.NewNetworkManager()
+	peer := test.NewNetworkManager()
+	nm.Join(peer)
+	reactor := &tVoteListReactor{ch: make(chan *voteListMessage, 5)}
+	_, err := peer.RegisterReactor("consensus", module.ProtoConsensus, reactor, CsProtocols, ConfigEnginePriority, module.NotRegisteredProtocolPolicyClose)
+	assert.NoError(t, err)
+	ph, err := nm.RegisterReactor("consensus", module.ProtoConsensus, nil, CsProtocols, ConfigEnginePriority, module.NotRegisteredProtocolPolicyClose)
+	assert.NoError(t, err)
+
+	sm := test.NewServiceManager()
+	c := test.NewChain(nm, sm)
+	signer := wallet.New()
+	addrs := []module.Address{signer.Address(), wallet.New().Address()}
+	if validator {
+		addrs = append(addrs, c.Wallet().Address())
+	}
+	var vs []module.Validator
+	for _, addr := range addrs {
+		v, err := state.ValidatorFromAddress(addr)
+		assert.NoError(t, err)
+		vs = append(vs, v)
+	}
+	vl, err := state.ValidatorSnapshotFromSlice(db.NewMapDB(), vs)
+	assert.NoError(t, err)
+
+	cs := &consensus{
+		c:          c,
+		log:        log.New(),
+		ph:         ph,
+		validators: vl,
+		evidences:  make(map[string]bool),
+	}
+	return &doubleSignTestSetUp{t, cs, sm, reactor, signer}
+}
+
+func (s *doubleSignTestSetUp) prevote(id []byte) *VoteMessage {
+	return NewVoteMessage(
+		s.signer, VoteTypePrevote, 3, 0, id, nil, 0, nil, nil, 0,
+	)
+}
+
+func TestConsensus_ReportDoubleSign(t *testing.T) {
+	s := newDoubleSignTestSetUp(t, true)
+	v1 := s.prevote([]byte("block1"))
+	v2 := s.prevote([]byte("block2"))
+
+	// the service manager is called without the lock
+	s.sm.SetOnSendPatch(func(patch module.Patch) {
+		s.cs.mutex.Lock()
+		s.cs.mutex.Unlock()
+	})
+
+	s.cs.mutex.Lock()
+	s.cs.reportDoubleSign(v1, v2)
+	// the same equivocation is reported only once
+	s.cs.reportDoubleSign(v2, v1)
+	assert.Len(t, s.sm.Patches(), 0)
+	s.cs.mutex.Unlock()
+
+	patches := s.sm.Patches()
+	assert.Len(t, patches, 1)
+	patch, err := DecodePatch(patches[0].Type(), patches[0].Data())
+	assert.NoError(t, err)
+	dsp, ok := patch.(module.DoubleSignPatch)
+	assert.True(t, ok)
+	assert.EqualValues(t, 3, dsp.Height())
+	assert.True(t, s.signer.Address().Equal(dsp.Signer()))
+	assert.NoError(t, dsp.Verify(s.cs.validators))
+
+	// the conflicting votes are gossiped to other validators
+	msg := <-s.reactor.ch
+	assert.NoError(t, msg.Verify())
+	assert.Equal(t, 2, msg.VoteList.Len())
+}
+
+func TestConsensus_ReportDoubleSignByNonValidator(t *testing.T) {
+	s := newDoubleSignTestSetUp(t, false)
+
+	s.cs.mutex.Lock()
+	s.cs.reportDoubleSign(s.prevote([]byte("block1")), s.prevote([]byte("block2")))
+	s.cs.mutex.Unlock()
+
+	// it's gossiped, but the patch is not sent
+	msg := <-s.reactor.ch
+	assert.NoError(t, msg.Verify())
+	assert.Len(t, s.sm.Patches(), 0)
+}
@@ -74,15 +74,26 @@ func (br *blockResult) Consume() {
 }
 
 func (br *blockResult) Reject() {
+	br.reject(false)
+}
+
+func (br *blockResult) RejectInvalid() {
+	br.reject(true)
+}
+
+func (br *blockResult) reject(invalid bool) {
 	br.cl.Lock()
 	defer br.cl.Unlock()
 
 	cl := br.cl
-	cl.log.Tracef("Reject %d\n", br.blk.Height())
+	cl.log.Tracef("Reject %d invalid=%v\n", br.blk.Height(), invalid)
 	fr := br.fr
 	if cl.fr != fr {
 		return
 	}
+	if invalid {
+		cl.ph.ReportPeer(br.id, module.PeerPenaltyMajor, "InvalidBlockVotes")
+	}
 
 	for i, p := range fr.validPeers {
 		if p.id.Equal(br.id) {
@@ -490,6 +501,7 @@ func (f *fetcher) onReceive(pi module.ProtocolInfo, b []byte) {
 		var msg BlockMetadata
 		_, err := codec.UnmarshalFromBytes(b, &msg)
 		if err != nil {
+			f.cl.ph.ReportPeer(f.id, module.PeerPenaltyMinor, "InvalidBlockMetadata")
 			return
 		}
 		if msg.RequestID != f.requestID {
@@ -514,6 +526,7 @@ func (f *fetcher) onReceive(pi module.ProtocolInfo, b []byte) {
 		var msg BlockData
 		_, err := codec.UnmarshalFromBytes(b, &msg)
 		if err != nil {
+			f.cl.ph.ReportPeer(f.id, module.PeerPenaltyMinor, "InvalidBlockData")
 			return
 		}
 		if msg.RequestID != f.requestID {
@@ -535,8 +548,10 @@ func (f *fetcher) onReceive(pi module.ProtocolInfo, b []byte) {
 			r := io.MultiReader(bufs...)
 			blk, err := f.cl.bm.NewBlockDataFromReader(r)
 			if err != nil {
+				f.cl.ph.ReportPeer(f.id, module.PeerPenaltyMajor, "InvalidBlock")
 				f.cl.onResult(f, err, nil, nil)
 			} else if blk.Height() != f.height {
+				f.cl.ph.ReportPeer(f.id, module.PeerPenaltyMajor, "InvalidBlockHeight")
 				f.cl.onResult(f, errors.Errorf("bad Height"), nil, nil)
 			} else {
 				f.cl.onResult(f, nil, blk, f.voteList)
@@ -547,6 +562,7 @@ func (f *fetcher) onReceive(pi module.ProtocolInfo, b []byte) {
 				f.timer.Stop()
 				f.timer = nil
 			}
+			f.cl.ph.ReportPeer(f.id, module.PeerPenaltyMajor, "InvalidBlockLength")
 			f.cl.onResult(f, errors.Errorf("bad data"), nil, nil)
 		}
 	}
@@ -175,3 +175,36 @@ func TestClient_SuccessMulti(t *testing.T) {
 	ev2 = <-s.cb.ch
 	s.assertEndEvent(nil, ev2)
 }
+
+func TestClient_Reject(t *testing.T) {
+	s := newClientTestSetUp(t, 2)
+	_, err := s.m.FetchBlocks(1, 10, s.cb)
+	assert.Nil(t, err)
+	<-s.reactors[1].ch
+	s.respondBlockRequest(s.phs[1], 0x10000, s.rawBlocks[1], s.votes[2], s.nms[0].ID)
+
+	// rejected for local reasons, the peer is not penalized
+	ev := <-s.cb.ch
+	s.assertBlockEvent(s.rawBlocks[1], ev)
+	ev.(tOnBlockEvent).br.Reject()
+	assert.Equal(t, 0, s.nms[0].PenaltyOf(s.nms[1].ID))
+	assert.IsType(t, tOnEndEvent{}, <-s.cb.ch)
+}
+
+func TestClient_RejectInvalid(t *testing.T) {
+	s := newClientTestSetUp(t, 2)
+	_, err := s.m.FetchBlocks(1, 10, s.cb)
+	assert.Nil(t, err)
+	<-s.reactors[1].ch
+	s.respondBlockRequest(s.phs[1], 0x10000, s.rawBlocks[1], s.votes[2], s.nms[0].ID)
+
+	ev := <-s.cb.ch
+	s.assertBlockEvent(s.rawBlocks[1], ev)
+	ev.(tOnBlockEvent).br.RejectInvalid()
+	assert.Equal(t, module.PeerPenaltyMajor, s.nms[0].PenaltyOf(s.nms[1].ID))
+	assert.IsType(t, tOnEndEvent{}, <-s.cb.ch)
+
+	// result of the finished request is not judged
+	ev.(tOnBlockEvent).br.RejectInvalid()
+	assert.Equal(t, module.PeerPenaltyMajor, s.nms[0].PenaltyOf(s.nms[1].ID))
+}
@@ -21,6 +21,10 @@ type BlockResult interface {
 	Votes() []byte
 	Consume()
 	Reject()
+	// RejectInvalid rejects the result like Reject, and it also penalizes
+	// the peer. Use it only if the votes are invalid regardless of the
+	// local state.
+	RejectInvalid()
 }
 
 type FetchCallback interface {
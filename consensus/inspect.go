@@ -0,0 +1,27 @@
+package consensus
+
+import (
+	"github.com/icon-project/goloop/module"
+)
+
+func Inspect(c module.Chain, informal bool) map[string]interface{} {
+	var cs *consensus
+	if mc := c.Consensus(); mc == nil {
+		return nil
+	} else {
+		if impl, ok := mc.(*consensus); ok {
+			cs = impl
+		} else {
+			return nil
+		}
+	}
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+
+	m := make(map[string]interface{})
+	m["height"] = cs.height
+	m["round"] = cs.round
+	m["step"] = cs.step.String()
+	m["timeouts"] = cs.timeouts.inspect()
+	return m
+}
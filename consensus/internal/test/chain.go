@@ -0,0 +1,43 @@
+package test
+
+import (
+	"github.com/icon-project/goloop/chain/base"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/common/wallet"
+	"github.com/icon-project/goloop/module"
+)
+
+// Chain provides the parts of the chain used by the consensus.
+type Chain struct {
+	base.Chain
+
+	wallet module.Wallet
+	nm     *NetworkManager
+	sm     *ServiceManager
+	logger log.Logger
+}
+
+func NewChain(nm *NetworkManager, sm *ServiceManager) *Chain {
+	return &Chain{
+		wallet: wallet.New(),
+		nm:     nm,
+		sm:     sm,
+		logger: log.New(),
+	}
+}
+
+func (c *Chain) Wallet() module.Wallet {
+	return c.wallet
+}
+
+func (c *Chain) NetworkManager() module.NetworkManager {
+	return c.nm
+}
+
+func (c *Chain) ServiceManager() module.ServiceManager {
+	return c.sm
+}
+
+func (c *Chain) Logger() log.Logger {
+	return c.logger
+}
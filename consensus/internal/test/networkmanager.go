@@ -37,6 +37,7 @@ type NetworkManager struct {
 	reactorItems []*tReactorItem
 	peers        []*NetworkManager
 	drop         bool
+	penalties    map[string]int
 }
 
 type tProtocolHandler struct {
@@ -170,6 +171,24 @@ func (ph *tProtocolHandler) GetPeers() []module.PeerID {
 	return ph.nm.GetPeers()
 }
 
+func (ph *tProtocolHandler) ReportPeer(id module.PeerID, penalty int, reason string) {
+	ph.nm.Lock()
+	defer ph.nm.Unlock()
+
+	if ph.nm.penalties == nil {
+		ph.nm.penalties = make(map[string]int)
+	}
+	ph.nm.penalties[string(id.Bytes())] += penalty
+}
+
+// PenaltyOf returns sum of penalties reported for the peer.
+func (nm *NetworkManager) PenaltyOf(id module.PeerID) int {
+	nm.Lock()
+	defer nm.Unlock()
+
+	return nm.penalties[string(id.Bytes())]
+}
+
 func createAPeerID() module.PeerID {
 	return network.NewPeerIDFromAddress(wallet.New().Address())
 }
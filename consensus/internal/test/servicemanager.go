@@ -0,0 +1,52 @@
+package test
+
+import (
+	"sync"
+
+	"github.com/icon-project/goloop/module"
+)
+
+type ServiceManager struct {
+	module.ServiceManager
+
+	mu          sync.Mutex
+	patches     []module.Patch
+	onSendPatch func(patch module.Patch)
+}
+
+func NewServiceManager() *ServiceManager {
+	return &ServiceManager{}
+}
+
+// SetOnSendPatch sets a function called on SendPatch before the patch is
+// recorded.
+func (sm *ServiceManager) SetOnSendPatch(f func(patch module.Patch)) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	sm.onSendPatch = f
+}
+
+func (sm *ServiceManager) SendPatch(patch module.Patch) error {
+	sm.mu.Lock()
+	f := sm.onSendPatch
+	sm.mu.Unlock()
+
+	if f != nil {
+		f(patch)
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	sm.patches = append(sm.patches, patch)
+	return nil
+}
+
+// Patches returns patches sent by SendPatch.
+func (sm *ServiceManager) Patches() []module.Patch {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	return append([]module.Patch(nil), sm.patches...)
+}
@@ -2,6 +2,7 @@ package consensus
 
 import (
 	"bytes"
+	"fmt"
 
 	"github.com/icon-project/goloop/common/codec"
 	"github.com/icon-project/goloop/common/errors"
@@ -69,6 +70,91 @@ func newSkipPatch(vl *voteList) *skipPatch {
 	return &skipPatch{VoteList: *vl}
 }
 
+// doubleSignPatch is the evidence of double signing. It has two conflicting
+// votes of a validator for the same height, round and vote type.
+type doubleSignPatch struct {
+	VoteList voteList
+}
+
+func (s *doubleSignPatch) Type() string {
+	return module.PatchTypeDoubleSign
+}
+
+func (s *doubleSignPatch) Data() []byte {
+	return codec.MustMarshalToBytes(s)
+}
+
+func (s *doubleSignPatch) Height() int64 {
+	if s.VoteList.Len() == 0 {
+		return -1
+	}
+	return s.VoteList.Get(0).Height
+}
+
+func (s *doubleSignPatch) Signer() module.Address {
+	if s.VoteList.Len() == 0 {
+		return nil
+	}
+	msg := s.VoteList.Get(0)
+	if err := msg.Verify(); err != nil {
+		return nil
+	}
+	return msg.address()
+}
+
+func (s *doubleSignPatch) Verify(vl module.ValidatorList) error {
+	if l := s.VoteList.Len(); l != 2 {
+		return errors.Errorf("invalid number of votes %d", l)
+	}
+	v1, v2 := s.VoteList.Get(0), s.VoteList.Get(1)
+	if err := v1.Verify(); err != nil {
+		return err
+	}
+	if err := v2.Verify(); err != nil {
+		return err
+	}
+	if !v1.address().Equal(v2.address()) {
+		return errors.Errorf("different signers %v %v", v1.address(), v2.address())
+	}
+	if !isConflictingVote(v1, v2) {
+		return errors.Errorf("votes are not conflicting %v %v", v1, v2)
+	}
+	if vl.IndexOf(v1.address()) < 0 {
+		return errors.Errorf("bad voter %v", v1.address())
+	}
+	return nil
+}
+
+// key returns the key to identify the evidence regardless of the order of
+// the votes.
+func (s *doubleSignPatch) key() string {
+	msg := s.VoteList.Get(0)
+	return fmt.Sprintf("%x/%d/%d/%d", msg.address().ID(), msg.Height, msg.Round, msg.Type)
+}
+
+func newDoubleSignPatch(v1, v2 *VoteMessage) *doubleSignPatch {
+	if bytes.Compare(v1.BlockID, v2.BlockID) > 0 {
+		v1, v2 = v2, v1
+	}
+	p := &doubleSignPatch{}
+	p.VoteList.AddVote(v1)
+	p.VoteList.AddVote(v2)
+	return p
+}
+
+// NewDoubleSignPatch returns the evidence of double signing with the
+// conflicting votes.
+func NewDoubleSignPatch(v1, v2 *VoteMessage) module.DoubleSignPatch {
+	return newDoubleSignPatch(v1, v2)
+}
+
+// isConflictingVote returns true if the votes are for different blocks in
+// the same height, round and vote type.
+func isConflictingVote(v1, v2 *VoteMessage) bool {
+	return v1.Height == v2.Height && v1.Round == v2.Round &&
+		v1.Type == v2.Type && !bytes.Equal(v1.BlockID, v2.BlockID)
+}
+
 func DecodePatch(t string, bs []byte) (module.Patch, error) {
 	var err error
 	var patch module.Patch
@@ -76,6 +162,9 @@ func DecodePatch(t string, bs []byte) (module.Patch, error) {
 	case module.PatchTypeSkipTransaction:
 		patch = &skipPatch{}
 		_, err = codec.UnmarshalFromBytes(bs, patch)
+	case module.PatchTypeDoubleSign:
+		patch = &doubleSignPatch{}
+		_, err = codec.UnmarshalFromBytes(bs, patch)
 	default:
 		err = errors.ErrUnsupported
 	}
@@ -0,0 +1,129 @@
+package consensus
+
+import (
+	"time"
+
+	"github.com/icon-project/goloop/module"
+)
+
+const (
+	timeoutPropose   = time.Second * 1
+	timeoutPrevote   = time.Second * 1
+	timeoutPrecommit = time.Second * 1
+	timeoutNewRound  = time.Second * 1
+	timeoutMax       = time.Minute * 1
+)
+
+const (
+	configProposalLatencyWeight = 8
+	configProposalLatencyFactor = 2
+)
+
+// timeouts calculates timeouts for each step in a round. Each timeout grows
+// exponentially by increase percent for a round until it reaches max. If
+// adaptive is set, timeout for proposal is not less than
+// configProposalLatencyFactor times moving average of proposal latency.
+type timeouts struct {
+	module.ConsensusTimeouts
+	latency time.Duration
+}
+
+func (t *timeouts) set(ct *module.ConsensusTimeouts) {
+	t.ConsensusTimeouts = module.ConsensusTimeouts{
+		Propose:   timeoutPropose,
+		Prevote:   timeoutPrevote,
+		Precommit: timeoutPrecommit,
+		NewRound:  timeoutNewRound,
+		Max:       timeoutMax,
+	}
+	if ct == nil {
+		return
+	}
+	if ct.Propose > 0 {
+		t.Propose = ct.Propose
+	}
+	if ct.Prevote > 0 {
+		t.Prevote = ct.Prevote
+	}
+	if ct.Precommit > 0 {
+		t.Precommit = ct.Precommit
+	}
+	if ct.NewRound > 0 {
+		t.NewRound = ct.NewRound
+	}
+	if ct.Max > 0 {
+		t.Max = ct.Max
+	}
+	if ct.Increase > 0 {
+		t.Increase = ct.Increase
+	}
+	t.Adaptive = ct.Adaptive
+}
+
+func (t *timeouts) scale(base time.Duration, round int32) time.Duration {
+	limit := t.Max
+	if limit < base {
+		limit = base
+	}
+	d := base
+	if t.Increase > 0 {
+		for i := int32(0); i < round && d < limit; i++ {
+			d += d * time.Duration(t.Increase) / 100
+		}
+	}
+	if d > limit {
+		d = limit
+	}
+	return d
+}
+
+func (t *timeouts) propose(round int32) time.Duration {
+	d := t.scale(t.ConsensusTimeouts.Propose, round)
+	if t.Adaptive {
+		limit := t.Max
+		if limit < d {
+			limit = d
+		}
+		if ad := t.latency * configProposalLatencyFactor; ad > d {
+			d = ad
+		}
+		if d > limit {
+			d = limit
+		}
+	}
+	return d
+}
+
+func (t *timeouts) prevote(round int32) time.Duration {
+	return t.scale(t.ConsensusTimeouts.Prevote, round)
+}
+
+func (t *timeouts) precommit(round int32) time.Duration {
+	return t.scale(t.ConsensusTimeouts.Precommit, round)
+}
+
+func (t *timeouts) newRound(round int32) time.Duration {
+	return t.scale(t.ConsensusTimeouts.NewRound, round)
+}
+
+// onProposal updates moving average of proposal latency.
+func (t *timeouts) onProposal(latency time.Duration) {
+	if t.latency == 0 {
+		t.latency = latency
+	} else {
+		t.latency += (latency - t.latency) / configProposalLatencyWeight
+	}
+}
+
+func (t *timeouts) inspect() map[string]interface{} {
+	return map[string]interface{}{
+		"propose":         t.ConsensusTimeouts.Propose.String(),
+		"prevote":         t.ConsensusTimeouts.Prevote.String(),
+		"precommit":       t.ConsensusTimeouts.Precommit.String(),
+		"newRound":        t.ConsensusTimeouts.NewRound.String(),
+		"max":             t.Max.String(),
+		"increase":        t.Increase,
+		"adaptive":        t.Adaptive,
+		"proposalLatency": t.latency.String(),
+	}
+}
@@ -0,0 +1,82 @@
+package consensus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/module"
+)
+
+func TestTimeouts_Default(t *testing.T) {
+	var to timeouts
+	to.set(nil)
+	for _, r := range []int32{0, 1, 10, 100} {
+		assert.Equal(t, timeoutPropose, to.propose(r))
+		assert.Equal(t, timeoutPrevote, to.prevote(r))
+		assert.Equal(t, timeoutPrecommit, to.precommit(r))
+		assert.Equal(t, timeoutNewRound, to.newRound(r))
+	}
+}
+
+func TestTimeouts_Increase(t *testing.T) {
+	var to timeouts
+	to.set(&module.ConsensusTimeouts{
+		Propose:   2 * time.Second,
+		Prevote:   time.Second,
+		Precommit: time.Second,
+		NewRound:  time.Second,
+		Max:       5 * time.Second,
+		Increase:  50,
+	})
+	assert.Equal(t, 2*time.Second, to.propose(0))
+	assert.Equal(t, 3*time.Second, to.propose(1))
+	assert.Equal(t, 4500*time.Millisecond, to.propose(2))
+	assert.Equal(t, 5*time.Second, to.propose(3))
+	assert.Equal(t, 5*time.Second, to.propose(1000))
+	assert.Equal(t, time.Second, to.prevote(0))
+	assert.Equal(t, 1500*time.Millisecond, to.precommit(1))
+	assert.Equal(t, 2250*time.Millisecond, to.newRound(2))
+
+	// base timeout is used even if it's bigger than max
+	to.set(&module.ConsensusTimeouts{
+		Propose:  10 * time.Second,
+		Max:      5 * time.Second,
+		Increase: 50,
+	})
+	assert.Equal(t, 10*time.Second, to.propose(3))
+	assert.Equal(t, timeoutPrevote, to.prevote(0))
+	assert.Equal(t, 1500*time.Millisecond, to.prevote(1))
+}
+
+func TestTimeouts_Adaptive(t *testing.T) {
+	var to timeouts
+	to.set(&module.ConsensusTimeouts{
+		Max:      4 * time.Second,
+		Adaptive: true,
+	})
+	assert.Equal(t, timeoutPropose, to.propose(0))
+
+	to.onProposal(300 * time.Millisecond)
+	assert.Equal(t, timeoutPropose, to.propose(0))
+
+	to.onProposal(1100 * time.Millisecond)
+	assert.Equal(t, 400*time.Millisecond, to.latency)
+	assert.Equal(t, timeoutPropose, to.propose(0))
+
+	for i := 0; i < 100; i++ {
+		to.onProposal(1500 * time.Millisecond)
+	}
+	d := to.propose(0)
+	assert.True(t, d > 2*time.Second && d <= 3*time.Second, "timeout=%s", d)
+
+	for i := 0; i < 100; i++ {
+		to.onProposal(10 * time.Second)
+	}
+	assert.Equal(t, 4*time.Second, to.propose(0))
+
+	// adaptation is not applied if it's disabled
+	to.set(&module.ConsensusTimeouts{})
+	assert.Equal(t, timeoutPropose, to.propose(0))
+}
@@ -28,6 +28,15 @@ type voteSet struct {
 	count    int
 }
 
+// conflictOf returns the vote of the validator for other block if exists.
+func (vs *voteSet) conflictOf(index int, v *VoteMessage) *VoteMessage {
+	omsg := vs.msgs[index]
+	if omsg != nil && isConflictingVote(omsg, v) {
+		return omsg
+	}
+	return nil
+}
+
 // return true if added
 func (vs *voteSet) add(index int, v *VoteMessage) bool {
 	omsg := vs.msgs[index]
@@ -225,6 +234,10 @@ func (hvs *heightVoteSet) add(index int, v *VoteMessage) (bool, *voteSet) {
 	return vs.add(index, v), vs
 }
 
+func (hvs *heightVoteSet) conflictOf(index int, v *VoteMessage) *VoteMessage {
+	return hvs.votesFor(v.Round, v.Type).conflictOf(index, v)
+}
+
 func (hvs *heightVoteSet) votesFor(round int32, voteType VoteType) *voteSet {
 	rvs := hvs._votes[round]
 	if rvs[voteType] == nil {
@@ -678,6 +678,7 @@ var chainMethods = []*chainMethod{
 		scoreapi.FlagReadOnly | scoreapi.FlagExternal, 1,
 		[]scoreapi.Parameter{
 			{"address", scoreapi.Address, nil, nil},
+			{"dsa", scoreapi.String, nil, nil},
 		},
 		[]scoreapi.DataType{
 			scoreapi.Bytes,
@@ -688,6 +689,7 @@ var chainMethods = []*chainMethod{
 		scoreapi.FlagExternal, 1,
 		[]scoreapi.Parameter{
 			{"pubKey", scoreapi.Bytes, nil, nil},
+			{"dsa", scoreapi.String, nil, nil},
 		},
 		nil,
 	}, icmodule.RevisionBTP2, 0},
@@ -697,6 +699,7 @@ var chainMethods = []*chainMethod{
 		[]scoreapi.Parameter{
 			{"address", scoreapi.Address, nil, nil},
 			{"pubKey", scoreapi.Bytes, nil, nil},
+			{"dsa", scoreapi.String, nil, nil},
 		},
 		nil,
 	}, icmodule.RevisionBTP2, 0},
@@ -729,6 +732,16 @@ var chainMethods = []*chainMethod{
 		},
 		nil,
 	}, icmodule.RevisionBTP2, 0},
+	{scoreapi.Method{
+		scoreapi.Function, "getIScoreBreakdown",
+		scoreapi.FlagReadOnly | scoreapi.FlagExternal, 1,
+		[]scoreapi.Parameter{
+			{"address", scoreapi.Address, nil, nil},
+		},
+		[]scoreapi.DataType{
+			scoreapi.Dict,
+		},
+	}, icmodule.RevisionIScoreBreakdown, 0},
 }
 
 func applyStepLimits(fee *FeeConfig, as state.AccountState) error {
@@ -314,6 +314,14 @@ func (s *chainScore) handleRevisionChange(as state.AccountState, r1, r2 int) err
 			}
 		}
 
+		// Set slash ratio of Double Sign Penalty
+		if r1 < icmodule.RevisionPenalizeDoubleSign && r2 >= icmodule.RevisionPenalizeDoubleSign {
+			if err := es.State.SetDoubleSignPenaltySlashRatio(
+				icmodule.DefaultDoubleSignPenaltySlashRatio); err != nil {
+				return err
+			}
+		}
+
 		// Enable ExtraMainPReps
 		if r1 < icmodule.RevisionExtraMainPReps && r2 >= icmodule.RevisionExtraMainPReps {
 			iconConfig := s.loadIconConfig()
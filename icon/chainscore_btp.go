@@ -50,7 +50,7 @@ func (s *chainScore) Ex_getBTPNetworkTypeID(name string) (int64, error) {
 
 const iconDSA = "ecdsa/secp256k1"
 
-func (s *chainScore) Ex_getPRepNodePublicKey(address module.Address) ([]byte, error) {
+func (s *chainScore) Ex_getPRepNodePublicKey(address module.Address, dsa string) ([]byte, error) {
 	if err := s.tryChargeCall(false); err != nil {
 		return nil, err
 	}
@@ -63,24 +63,30 @@ func (s *chainScore) Ex_getPRepNodePublicKey(address module.Address) ([]byte, er
 		return nil, icmodule.IllegalArgumentError.New("address is not P-Rep")
 	}
 
-	return s.newBTPContext().GetPublicKey(prep.NodeAddress(), iconDSA), nil
+	if dsa == "" {
+		dsa = iconDSA
+	}
+	return s.newBTPContext().GetPublicKey(prep.NodeAddress(), dsa), nil
 }
 
-func (s *chainScore) Ex_setPRepNodePublicKey(pubKey []byte) error {
-	return s.setPRepNodePublicKey(nil, pubKey)
+func (s *chainScore) Ex_setPRepNodePublicKey(pubKey []byte, dsa string) error {
+	return s.setPRepNodePublicKey(nil, pubKey, dsa)
 }
 
-func (s *chainScore) Ex_registerPRepNodePublicKey(address module.Address, pubKey []byte) error {
-	return s.setPRepNodePublicKey(address, pubKey)
+func (s *chainScore) Ex_registerPRepNodePublicKey(address module.Address, pubKey []byte, dsa string) error {
+	return s.setPRepNodePublicKey(address, pubKey, dsa)
 }
 
-func (s *chainScore) setPRepNodePublicKey(address module.Address, pubKey []byte) error {
+func (s *chainScore) setPRepNodePublicKey(address module.Address, pubKey []byte, dsa string) error {
 	if s.from.IsContract() {
 		return scoreresult.New(module.StatusAccessDenied, "NoPermission")
 	}
 	if len(pubKey) == 0 {
 		return icmodule.IllegalArgumentError.New("Invalid pubKey")
 	}
+	if dsa != "" && dsa != iconDSA {
+		return s.setPRepNodeDSAKey(address, pubKey, dsa)
+	}
 	es, err := s.getExtensionState()
 	if err != nil {
 		return err
@@ -140,6 +146,45 @@ func (s *chainScore) setPRepNodePublicKey(address module.Address, pubKey []byte)
 	return nil
 }
 
+// setPRepNodeDSAKey sets the public key of the node for the DSA other than
+// iconDSA. The node address can't be derived from the public key, so only the
+// owner or the node of the P-Rep can set it.
+func (s *chainScore) setPRepNodeDSAKey(address module.Address, pubKey []byte, dsa string) error {
+	es, err := s.getExtensionState()
+	if err != nil {
+		return err
+	}
+	register := true
+	if address == nil {
+		register = false
+		address = s.from
+	}
+	prep := es.GetPRep(address)
+	if prep == nil {
+		return icmodule.IllegalArgumentError.New("address is not P-Rep")
+	}
+	nodeAddress := prep.NodeAddress()
+	if !s.from.Equal(prep.Owner()) && !s.from.Equal(nodeAddress) {
+		return scoreresult.New(module.StatusAccessDenied, "NoPermission")
+	}
+
+	bc := s.newBTPContext()
+	bs, err := s.getBTPState()
+	if err != nil {
+		return err
+	}
+	if register {
+		if v := bc.GetPublicKey(nodeAddress, dsa); v != nil {
+			return icmodule.IllegalArgumentError.New("There is public key already. To update public key, use setPRepNodePublicKey")
+		}
+	}
+	if err = bs.SetPublicKey(bc, nodeAddress, dsa, pubKey); err != nil {
+		return err
+	}
+	prep.SetDSAMask(bc.GetPublicKeyMask(nodeAddress))
+	return es.OnSetPublicKey(s.newCallContext(s.cc), prep.Owner(), bc.GetDSAIndex(dsa))
+}
+
 func (s *chainScore) Ex_openBTPNetwork(networkTypeName string, name string, owner module.Address) (int64, error) {
 	if err := s.checkGovernance(true); err != nil {
 		return 0, err
@@ -18,6 +18,7 @@ package icon
 
 import (
 	"bytes"
+	"context"
 	"encoding/hex"
 	"math/big"
 
@@ -463,6 +464,26 @@ func (s *chainScore) Ex_queryIScore(address module.Address) (map[string]interfac
 	return jso, nil
 }
 
+// Ex_getIScoreBreakdown returns the reward of the address calculated for the
+// previous term by reward type. It runs the whole calculation again, so it's
+// allowed only for queries, and the calculation is limited by the transaction
+// timeout.
+func (s *chainScore) Ex_getIScoreBreakdown(address module.Address) (map[string]interface{}, error) {
+	if err := s.tryChargeCall(true); err != nil {
+		return nil, err
+	}
+	if s.cc.TransactionInfo() != nil {
+		return nil, scoreresult.AccessDeniedError.New("QueryOnly")
+	}
+	es, err := s.getExtensionState()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.cc.TransactionTimeout())
+	defer cancel()
+	return es.GetIScoreBreakdownInJSON(ctx, address)
+}
+
 func (s *chainScore) Ex_estimateUnstakeLockPeriod() (map[string]interface{}, error) {
 	if err := s.tryChargeCall(true); err != nil {
 		return nil, err
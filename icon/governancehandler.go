@@ -54,6 +54,10 @@ func (g *governanceHandler) TraceLogger() *trace.Logger {
 	return g.log
 }
 
+func (g *governanceHandler) TraceCall() *module.TraceCall {
+	return contract.TraceCallOf(g.ch)
+}
+
 func applyGovernanceVariablesToSystem(cc contract.CallContext, govAs, sysAs containerdb.BytesStoreState) error {
 	price := scoredb.NewVarDB(govAs, state.VarStepPrice).Int64()
 	if price == 0 {
@@ -163,7 +163,7 @@ func (f *fastSyncer) processBlock(br fastsync.BlockResult) {
 	var proof [][]byte
 	_, err := codec.UnmarshalFromBytes(br.Votes(), &proof)
 	if err != nil {
-		br.Reject()
+		br.RejectInvalid()
 	}
 	err = f.bpp.Add(blk.Height(), blk.Hash(), proof)
 	if err != nil {
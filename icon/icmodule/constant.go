@@ -74,6 +74,7 @@ const (
 	DefaultDelegationSlotMax                     = 100
 	DefaultExtraMainPRepCount                    = 3
 	DefaultNonVotePenaltySlashRatio              = 0  // 0%
+	DefaultDoubleSignPenaltySlashRatio           = 10 // 10%
 )
 
 // The following variables are read-only
@@ -40,6 +40,7 @@ const (
 	Revision19
 	Revision20
 	Revision21
+	Revision22
 	RevisionReserved
 )
 
@@ -104,7 +105,10 @@ const (
 	// Unused
 	// RevisionJavaFixMapValues = Revision20
 
-	RevisionBTP2 = Revision21
+	RevisionBTP2               = Revision21
+	RevisionPenalizeDoubleSign = Revision21
+
+	RevisionIScoreBreakdown = Revision22
 )
 
 var revisionFlags = []module.Revision{
@@ -151,7 +155,9 @@ var revisionFlags = []module.Revision{
 	// Revision20
 	module.FixMapValues,
 	// Revision21
-	module.MultipleFeePayers,
+	module.MultipleFeePayers | module.AcceptDoubleSign,
+	// Revision22
+	0,
 }
 
 func init() {
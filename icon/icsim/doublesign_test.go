@@ -0,0 +1,84 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package icsim
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/icon/icmodule"
+	"github.com/icon-project/goloop/icon/iiss/icstate"
+	"github.com/icon-project/goloop/module"
+)
+
+func TestSimulator_PenalizeDoubleSign(t *testing.T) {
+	const (
+		termPeriod    = 100
+		mainPRepCount = 22
+	)
+	var csi module.ConsensusInfo
+
+	c := NewConfig()
+	c.MainPRepCount = mainPRepCount
+	c.TermPeriod = termPeriod
+	c.BondedPRepCount = mainPRepCount
+	env := initEnv(t, c, icmodule.Revision13)
+	sim := env.sim
+	prep0 := env.preps[0]
+
+	// the penalty is disabled before the revision
+	rcpts, err := sim.GoByTransaction(sim.HandleDoubleSign(prep0, sim.BlockHeight()), csi)
+	assert.NoError(t, err)
+	assert.Equal(t, Failure, rcpts[0].Status())
+	assert.Equal(t, icstate.Active, sim.GetPRep(prep0).Status())
+
+	rcpts, err = sim.GoByTransaction(sim.SetRevision(icmodule.RevisionPenalizeDoubleSign), csi)
+	assert.NoError(t, err)
+	assert.Equal(t, Success, rcpts[0].Status())
+	jso := sim.GetNetworkInfo()
+	assert.Equal(t, icmodule.DefaultDoubleSignPenaltySlashRatio, jso["doubleSignPenaltySlashRatio"])
+
+	oldBonded := sim.GetPRep(prep0).Bonded()
+	oldTotalBond := sim.TotalBond()
+	oldTotalStake := sim.TotalStake()
+	assert.True(t, oldBonded.Sign() > 0)
+	slashed := new(big.Int).Div(
+		new(big.Int).Mul(oldBonded, big.NewInt(icmodule.DefaultDoubleSignPenaltySlashRatio)),
+		big.NewInt(100))
+
+	rcpts, err = sim.GoByTransaction(sim.HandleDoubleSign(prep0, sim.BlockHeight()), csi)
+	assert.NoError(t, err)
+	assert.Equal(t, Success, rcpts[0].Status())
+
+	prep := sim.GetPRep(prep0)
+	assert.Equal(t, icstate.Disqualified, prep.Status())
+	assert.Zero(t, prep.Bonded().Cmp(new(big.Int).Sub(oldBonded, slashed)))
+	assert.Zero(t, sim.TotalBond().Cmp(new(big.Int).Sub(oldTotalBond, slashed)))
+	assert.Zero(t, sim.TotalStake().Cmp(new(big.Int).Sub(oldTotalStake, slashed)))
+
+	// disqualified P-Rep can't be penalized again
+	rcpts, err = sim.GoByTransaction(sim.HandleDoubleSign(prep0, sim.BlockHeight()), csi)
+	assert.NoError(t, err)
+	assert.Equal(t, Failure, rcpts[0].Status())
+
+	// no penalty for unknown node
+	rcpts, err = sim.GoByTransaction(sim.HandleDoubleSign(env.users[0], sim.BlockHeight()), csi)
+	assert.NoError(t, err)
+	assert.Equal(t, Failure, rcpts[0].Status())
+}
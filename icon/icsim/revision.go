@@ -197,3 +197,9 @@ func (sim *simulatorImpl) handleRev15(ws state.WorldState, r1, r2 int) error {
 func (sim *simulatorImpl) handleRev17(ws state.WorldState, r1, r2 int) error {
 	return nil
 }
+
+// handleRev21: icmodule.RevisionPenalizeDoubleSign
+func (sim *simulatorImpl) handleRev21(ws state.WorldState, r1, r2 int) error {
+	es := ws.GetExtensionState().(*iiss.ExtensionStateImpl)
+	return es.State.SetDoubleSignPenaltySlashRatio(icmodule.DefaultDoubleSignPenaltySlashRatio)
+}
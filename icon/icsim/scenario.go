@@ -0,0 +1,837 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package icsim
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/icon/icmodule"
+	"github.com/icon-project/goloop/icon/iiss/icstate"
+	"github.com/icon-project/goloop/icon/iiss/icutils"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/state"
+)
+
+// amount is an amount of loop in a scenario. It's written as a number or
+// a string of decimal or hexadecimal number. A string may have the unit
+// suffix "icx" or "loop", and an amount in ICX may have a fractional part.
+type amount struct {
+	big.Int
+}
+
+func (a *amount) UnmarshalJSON(b []byte) error {
+	var s string
+	if len(b) > 0 && b[0] == '"' {
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+	} else {
+		s = string(b)
+	}
+	v, err := parseAmount(s)
+	if err != nil {
+		return err
+	}
+	a.Set(v)
+	return nil
+}
+
+func (a *amount) Value() *big.Int {
+	if a == nil {
+		return nil
+	}
+	return new(big.Int).Set(&a.Int)
+}
+
+func parseAmount(s string) (*big.Int, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if strings.HasSuffix(s, "icx") {
+		r, ok := new(big.Rat).SetString(strings.TrimSpace(strings.TrimSuffix(s, "icx")))
+		if !ok {
+			return nil, errors.IllegalArgumentError.Errorf("InvalidAmount(%s)", s)
+		}
+		r.Mul(r, new(big.Rat).SetInt(icmodule.BigIntICX))
+		if !r.IsInt() {
+			return nil, errors.IllegalArgumentError.Errorf("InvalidAmount(%s)", s)
+		}
+		return new(big.Int).Set(r.Num()), nil
+	}
+	s = strings.TrimSpace(strings.TrimSuffix(s, "loop"))
+	v, ok := new(big.Int).SetString(s, 0)
+	if !ok {
+		return nil, errors.IllegalArgumentError.Errorf("InvalidAmount(%s)", s)
+	}
+	return v, nil
+}
+
+// condition is an expected amount with an optional comparison operator
+// prefix (==, !=, <, <=, >, >=) like ">= 100icx".
+type condition struct {
+	op    string
+	value *big.Int
+}
+
+var conditionOperators = []string{"==", "!=", "<=", ">=", "<", ">", "="}
+
+func (c *condition) UnmarshalJSON(b []byte) error {
+	var s string
+	if len(b) > 0 && b[0] == '"' {
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+	} else {
+		s = string(b)
+	}
+	s = strings.TrimSpace(s)
+	c.op = "=="
+	for _, op := range conditionOperators {
+		if strings.HasPrefix(s, op) {
+			c.op = op
+			s = s[len(op):]
+			break
+		}
+	}
+	if c.op == "=" {
+		c.op = "=="
+	}
+	v, err := parseAmount(s)
+	if err != nil {
+		return err
+	}
+	c.value = v
+	return nil
+}
+
+func (c *condition) check(v *big.Int) bool {
+	if v == nil {
+		v = icmodule.BigIntZero
+	}
+	cmp := v.Cmp(c.value)
+	switch c.op {
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	default:
+		return cmp == 0
+	}
+}
+
+func (c *condition) String() string {
+	return fmt.Sprintf("%s %s", c.op, c.value)
+}
+
+type scenarioVote struct {
+	Address string  `json:"address"`
+	Value   *amount `json:"value"`
+}
+
+type scenarioTx struct {
+	Type        string          `json:"type"`
+	From        string          `json:"from"`
+	Amount      *amount         `json:"amount"`
+	Delegations []*scenarioVote `json:"delegations"`
+	Bonds       []*scenarioVote `json:"bonds"`
+	Bonders     []string        `json:"bonders"`
+	Name        string          `json:"name"`
+	Node        string          `json:"node"`
+	Address     string          `json:"address"`
+	Revision    int             `json:"revision"`
+	Iglobal     *amount         `json:"iglobal"`
+	Iprep       *amount         `json:"iprep"`
+	Icps        *amount         `json:"icps"`
+	Irelay      *amount         `json:"irelay"`
+	Ivoter      *amount         `json:"ivoter"`
+	Rate        *int            `json:"rate"`
+	Fail        bool            `json:"fail"`
+}
+
+type accountExpect struct {
+	Balance    *condition `json:"balance"`
+	Stake      *condition `json:"stake"`
+	Delegation *condition `json:"delegation"`
+	Bond       *condition `json:"bond"`
+	IScore     *condition `json:"iscore"`
+	Delegated  *condition `json:"delegated"`
+	Bonded     *condition `json:"bonded"`
+	Grade      string     `json:"grade"`
+	Status     string     `json:"status"`
+}
+
+type scenarioExpect struct {
+	TotalSupply    *condition                `json:"totalSupply"`
+	TotalStake     *condition                `json:"totalStake"`
+	TotalBond      *condition                `json:"totalBond"`
+	TotalDelegated *condition                `json:"totalDelegated"`
+	Accounts       map[string]*accountExpect `json:"accounts"`
+}
+
+// scenarioStep moves the chain by Go, GoTo or GoToTermEnd, then executes
+// Transactions in a block, then checks Expect at the resulting height.
+type scenarioStep struct {
+	Name         string          `json:"name"`
+	Go           int64           `json:"go"`
+	GoTo         int64           `json:"goTo"`
+	GoToTermEnd  int             `json:"goToTermEnd"`
+	Absent       []string        `json:"absent"`
+	Transactions []*scenarioTx   `json:"transactions"`
+	Expect       *scenarioExpect `json:"expect"`
+}
+
+func (s *scenarioStep) String() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return "-"
+}
+
+// Scenario is a declarative description of an IISS simulation. It's loaded
+// from YAML or JSON by LoadScenario and driven by Run.
+type Scenario struct {
+	Revision   int                `json:"revision"`
+	Config     json.RawMessage    `json:"config"`
+	Validators int                `json:"validators"`
+	Accounts   map[string]*amount `json:"accounts"`
+	Steps      []*scenarioStep    `json:"steps"`
+}
+
+// ParseScenario parses the scenario written in YAML or JSON.
+func ParseScenario(bs []byte) (*Scenario, error) {
+	var obj interface{}
+	if err := yaml.Unmarshal(bs, &obj); err != nil {
+		return nil, errors.IllegalArgumentError.Wrapf(err, "InvalidScenario: %v", err)
+	}
+	js, err := json.Marshal(obj)
+	if err != nil {
+		return nil, errors.IllegalArgumentError.Wrapf(err, "InvalidScenario: %v", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(js))
+	dec.DisallowUnknownFields()
+	sc := new(Scenario)
+	if err = dec.Decode(sc); err != nil {
+		return nil, errors.IllegalArgumentError.Wrapf(err, "InvalidScenario: %v", err)
+	}
+	return sc, nil
+}
+
+// LoadScenario reads the scenario from the file.
+func LoadScenario(path string) (*Scenario, error) {
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseScenario(bs)
+}
+
+// Run drives a new simulator by the scenario. Statistics of each term are
+// written to out in CSV if out is not nil. It returns the simulator at the
+// end of the scenario, or the error of the first failed step.
+func (sc *Scenario) Run(out io.Writer) (Simulator, error) {
+	r, err := newScenarioRunner(sc, out)
+	if err != nil {
+		return nil, err
+	}
+	for i, step := range sc.Steps {
+		if err = r.runStep(step); err != nil {
+			return r.sim, errors.Wrapf(err, "step[%d](%s) height=%d: %v", i, step, r.sim.BlockHeight(), err)
+		}
+	}
+	if r.out != nil {
+		r.out.Flush()
+		if err = r.out.Error(); err != nil {
+			return r.sim, err
+		}
+	}
+	return r.sim, nil
+}
+
+var scenarioCSVHeader = []string{
+	"term", "startHeight", "endHeight", "revision", "totalSupply",
+	"totalStake", "totalBond", "totalDelegated", "iscore", "iglobal",
+}
+
+type scenarioRunner struct {
+	sim      Simulator
+	names    []string
+	accounts map[string]module.Address
+	out      *csv.Writer
+}
+
+// scenarioAddress returns the address of the account in the scenario. An
+// account named with an address uses the address, "treasury" is the treasury
+// paying I-Score claims, and others use the address derived from the name.
+func scenarioAddress(name string) module.Address {
+	if name == "treasury" {
+		return treasury
+	}
+	if addr, err := common.NewAddressFromString(name); err == nil {
+		return addr
+	}
+	return common.NewAccountAddress(crypto.SHA3Sum256([]byte(name))[:common.AddressIDBytes])
+}
+
+func newScenarioRunner(sc *Scenario, out io.Writer) (*scenarioRunner, error) {
+	c := NewConfig()
+	if len(sc.Config) > 0 {
+		dec := json.NewDecoder(bytes.NewReader(sc.Config))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(c); err != nil {
+			return nil, errors.IllegalArgumentError.Wrapf(err, "InvalidConfig: %v", err)
+		}
+	}
+	rev := sc.Revision
+	if rev == 0 {
+		rev = icmodule.LatestRevision
+	}
+	if rev < icmodule.RevisionIISS || rev > icmodule.MaxRevision {
+		return nil, errors.IllegalArgumentError.Errorf("InvalidRevision(%d)", rev)
+	}
+
+	r := &scenarioRunner{
+		accounts: make(map[string]module.Address),
+	}
+	balances := make(map[string]*big.Int)
+	for name, balance := range sc.Accounts {
+		addr := scenarioAddress(name)
+		r.accounts[name] = addr
+		r.names = append(r.names, name)
+		if balance != nil {
+			balances[icutils.ToKey(addr)] = balance.Value()
+		}
+	}
+	sort.Strings(r.names)
+
+	validatorLen := sc.Validators
+	if validatorLen == 0 {
+		validatorLen = int(c.MainPRepCount)
+	}
+	validators := make([]module.Validator, validatorLen)
+	for i := 0; i < validatorLen; i++ {
+		validator, _ := state.ValidatorFromAddress(newDummyAddress(4000 + i))
+		validators[i] = validator
+	}
+
+	r.sim = NewSimulator(icmodule.ValueToRevision(rev), validators, balances, c)
+	if r.sim == nil {
+		return nil, errors.InvalidStateError.New("FailToInitializeSimulator")
+	}
+	if out != nil {
+		r.out = csv.NewWriter(out)
+		if err := r.out.Write(scenarioCSVHeader); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func (r *scenarioRunner) address(name string) (module.Address, error) {
+	if addr, ok := r.accounts[name]; ok {
+		return addr, nil
+	}
+	if addr, err := common.NewAddressFromString(name); err == nil {
+		return addr, nil
+	}
+	return nil, errors.IllegalArgumentError.Errorf("UnknownAccount(%s)", name)
+}
+
+func (r *scenarioRunner) runStep(step *scenarioStep) error {
+	moves := 0
+	for _, set := range []bool{step.Go != 0, step.GoTo != 0, step.GoToTermEnd != 0} {
+		if set {
+			moves++
+		}
+	}
+	if moves > 1 {
+		return errors.IllegalArgumentError.New("MultipleMovesInStep")
+	}
+	absent := make([]module.Address, len(step.Absent))
+	for i, name := range step.Absent {
+		addr, err := r.address(name)
+		if err != nil {
+			return err
+		}
+		absent[i] = addr
+	}
+
+	var err error
+	switch {
+	case step.Go != 0:
+		err = r.goTo(r.sim.BlockHeight()+step.Go, absent)
+	case step.GoTo != 0:
+		if step.GoTo <= r.sim.BlockHeight() {
+			return errors.IllegalArgumentError.Errorf(
+				"InvalidHeight(cur=%d,new=%d)", r.sim.BlockHeight(), step.GoTo)
+		}
+		err = r.goTo(step.GoTo, absent)
+	case step.GoToTermEnd != 0:
+		for i := 0; i < step.GoToTermEnd && err == nil; i++ {
+			height := r.sim.BlockHeight() + 1
+			if term := r.sim.TermSnapshot(); term != nil && term.GetEndHeight() > r.sim.BlockHeight() {
+				height = term.GetEndHeight()
+			}
+			err = r.goTo(height, absent)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(step.Transactions) > 0 {
+		if err = r.executeTransactions(step.Transactions); err != nil {
+			return err
+		}
+	}
+	if step.Expect != nil {
+		return r.check(step.Expect)
+	}
+	return nil
+}
+
+// goTo generates blocks up to the height. Blocks are generated term by term
+// to record statistics of each term, and block by block if there are absent
+// validators as the validator list may be changed by penalties.
+func (r *scenarioRunner) goTo(height int64, absent []module.Address) error {
+	for r.sim.BlockHeight() < height {
+		cur := r.sim.BlockHeight()
+		blocks := height - cur
+		term := r.sim.TermSnapshot()
+		if term != nil {
+			if end := term.GetEndHeight(); end > cur && end < height {
+				blocks = end - cur
+			}
+		}
+		if len(absent) > 0 {
+			blocks = 1
+		}
+		csi, err := r.consensusInfo(absent)
+		if err != nil {
+			return err
+		}
+		if err = r.sim.Go(blocks, csi); err != nil {
+			return err
+		}
+		if err = r.onTermEnd(term); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *scenarioRunner) consensusInfo(absent []module.Address) (module.ConsensusInfo, error) {
+	nodes := make([]module.Address, 0, len(absent)*2)
+	for _, addr := range absent {
+		nodes = append(nodes, addr)
+		if prep := r.sim.GetPRep(addr); prep != nil {
+			nodes = append(nodes, prep.NodeAddress())
+		}
+	}
+	vl := r.sim.ValidatorList()
+	if len(vl) == 0 {
+		return nil, nil
+	}
+	vss, err := state.ValidatorSnapshotFromSlice(r.sim.Database(), vl)
+	if err != nil {
+		return nil, err
+	}
+	voted := make([]bool, len(vl))
+	for i, v := range vl {
+		voted[i] = true
+		for _, node := range nodes {
+			if v.Address().Equal(node) {
+				voted[i] = false
+				break
+			}
+		}
+	}
+	return common.NewConsensusInfo(vl[len(vl)-1].Address(), vss, voted), nil
+}
+
+// onTermEnd records statistics of the term if it's over.
+func (r *scenarioRunner) onTermEnd(term *icstate.TermSnapshot) error {
+	if term == nil {
+		return nil
+	}
+	if cur := r.sim.TermSnapshot(); cur != nil && cur.Sequence() == term.Sequence() {
+		return nil
+	}
+	if r.out == nil {
+		return nil
+	}
+	totalDelegated, _ := r.sim.GetNetworkInfo()["totalDelegated"].(*big.Int)
+	iscore := new(big.Int)
+	for _, name := range r.names {
+		if is := r.sim.QueryIScore(r.accounts[name]); is != nil {
+			iscore.Add(iscore, is)
+		}
+	}
+	return r.out.Write([]string{
+		fmt.Sprint(term.Sequence()),
+		fmt.Sprint(term.StartHeight()),
+		fmt.Sprint(term.GetEndHeight()),
+		fmt.Sprint(term.Revision()),
+		r.sim.TotalSupply().String(),
+		r.sim.TotalStake().String(),
+		r.sim.TotalBond().String(),
+		valueOrZero(totalDelegated).String(),
+		iscore.String(),
+		term.Iglobal().String(),
+	})
+}
+
+func (r *scenarioRunner) executeTransactions(txs []*scenarioTx) error {
+	block := NewBlock()
+	for i, tx := range txs {
+		t, err := r.transaction(tx)
+		if err != nil {
+			return errors.Wrapf(err, "tx[%d](%s): %v", i, tx.Type, err)
+		}
+		block.AddTransaction(t)
+	}
+	csi, err := r.consensusInfo(nil)
+	if err != nil {
+		return err
+	}
+	receipts, err := r.sim.ExecuteBlock(block, csi)
+	if err != nil {
+		return err
+	}
+	for i, rct := range receipts {
+		failed := rct.Status() != Success
+		if failed != txs[i].Fail {
+			if failed {
+				return errors.Wrapf(rct.Error(), "tx[%d](%s) failed: %v", i, txs[i].Type, rct.Error())
+			}
+			return errors.InvalidStateError.Errorf("tx[%d](%s) succeeded unexpectedly", i, txs[i].Type)
+		}
+	}
+	return nil
+}
+
+func (r *scenarioRunner) votes(vs []*scenarioVote) ([]module.Address, []*big.Int, error) {
+	addrs := make([]module.Address, len(vs))
+	values := make([]*big.Int, len(vs))
+	for i, v := range vs {
+		addr, err := r.address(v.Address)
+		if err != nil {
+			return nil, nil, err
+		}
+		if v.Value == nil {
+			return nil, nil, errors.IllegalArgumentError.Errorf("NoValue(%s)", v.Address)
+		}
+		addrs[i] = addr
+		values[i] = v.Value.Value()
+	}
+	return addrs, values, nil
+}
+
+func (r *scenarioRunner) transaction(tx *scenarioTx) (Transaction, error) {
+	var from module.Address
+	if tx.From != "" {
+		addr, err := r.address(tx.From)
+		if err != nil {
+			return nil, err
+		}
+		from = addr
+	}
+	requireFrom := func() error {
+		if from == nil {
+			return errors.IllegalArgumentError.New("NoFrom")
+		}
+		return nil
+	}
+	sim := r.sim
+
+	switch tx.Type {
+	case "setStake":
+		if err := requireFrom(); err != nil {
+			return nil, err
+		}
+		if tx.Amount == nil {
+			return nil, errors.IllegalArgumentError.New("NoAmount")
+		}
+		return sim.Stake(from, tx.Amount.Value()), nil
+	case "setDelegation":
+		if err := requireFrom(); err != nil {
+			return nil, err
+		}
+		addrs, values, err := r.votes(tx.Delegations)
+		if err != nil {
+			return nil, err
+		}
+		ds := make(icstate.Delegations, len(addrs))
+		for i := range addrs {
+			ds[i] = icstate.NewDelegation(common.AddressToPtr(addrs[i]), values[i])
+		}
+		return sim.SetDelegation(from, ds), nil
+	case "setBond":
+		if err := requireFrom(); err != nil {
+			return nil, err
+		}
+		addrs, values, err := r.votes(tx.Bonds)
+		if err != nil {
+			return nil, err
+		}
+		bonds := make(icstate.Bonds, len(addrs))
+		for i := range addrs {
+			bonds[i] = icstate.NewBond(common.AddressToPtr(addrs[i]), values[i])
+		}
+		return sim.SetBond(from, bonds), nil
+	case "setBonderList":
+		if err := requireFrom(); err != nil {
+			return nil, err
+		}
+		bl := make(icstate.BonderList, len(tx.Bonders))
+		for i, name := range tx.Bonders {
+			addr, err := r.address(name)
+			if err != nil {
+				return nil, err
+			}
+			bl[i] = common.AddressToPtr(addr)
+		}
+		return sim.SetBonderList(from, bl), nil
+	case "registerPRep":
+		if err := requireFrom(); err != nil {
+			return nil, err
+		}
+		name := tx.Name
+		if name == "" {
+			name = tx.From
+		}
+		var node module.Address
+		if tx.Node != "" {
+			addr, err := r.address(tx.Node)
+			if err != nil {
+				return nil, err
+			}
+			node = addr
+		}
+		return sim.RegisterPRep(from, newScenarioPRepInfo(name, node)), nil
+	case "unregisterPRep":
+		if err := requireFrom(); err != nil {
+			return nil, err
+		}
+		return sim.UnregisterPRep(from), nil
+	case "disqualifyPRep":
+		addr, err := r.address(tx.Address)
+		if err != nil {
+			return nil, err
+		}
+		if from == nil {
+			from = state.SystemAddress
+		}
+		return sim.DisqualifyPRep(from, addr), nil
+	case "claimIScore":
+		if err := requireFrom(); err != nil {
+			return nil, err
+		}
+		return sim.ClaimIScore(from), nil
+	case "setRevision":
+		if tx.Revision < r.sim.Revision().Value() || tx.Revision > icmodule.MaxRevision {
+			return nil, errors.IllegalArgumentError.Errorf("InvalidRevision(%d)", tx.Revision)
+		}
+		return sim.SetRevision(icmodule.ValueToRevision(tx.Revision)), nil
+	case "setRewardFund":
+		if tx.Iglobal == nil {
+			return nil, errors.IllegalArgumentError.New("NoIglobal")
+		}
+		return sim.SetRewardFund(tx.Iglobal.Value()), nil
+	case "setRewardFundAllocation":
+		if tx.Iprep == nil || tx.Icps == nil || tx.Irelay == nil || tx.Ivoter == nil {
+			return nil, errors.IllegalArgumentError.New("MissingAllocation")
+		}
+		return sim.SetRewardFundAllocation(
+			tx.Iprep.Value(), tx.Icps.Value(), tx.Irelay.Value(), tx.Ivoter.Value()), nil
+	case "setConsistentValidationSlashingRate":
+		if tx.Rate == nil {
+			return nil, errors.IllegalArgumentError.New("NoRate")
+		}
+		return sim.SetConsistentValidationSlashingRate(*tx.Rate), nil
+	case "setNonVoteSlashingRate":
+		if tx.Rate == nil {
+			return nil, errors.IllegalArgumentError.New("NoRate")
+		}
+		return sim.SetNonVoteSlashingRate(*tx.Rate), nil
+	default:
+		return nil, errors.IllegalArgumentError.Errorf("UnknownTransactionType(%s)", tx.Type)
+	}
+}
+
+func newScenarioPRepInfo(name string, node module.Address) *icstate.PRepInfo {
+	city := "Seoul"
+	country := "KOR"
+	email := fmt.Sprintf("%s@email.com", name)
+	website := fmt.Sprintf("https://%s.example.com/", name)
+	details := fmt.Sprintf("%sdetails/", website)
+	endpoint := fmt.Sprintf("%s.example.com:9080", name)
+	return &icstate.PRepInfo{
+		City:        &city,
+		Country:     &country,
+		Name:        &name,
+		Email:       &email,
+		WebSite:     &website,
+		Details:     &details,
+		P2PEndpoint: &endpoint,
+		Node:        node,
+	}
+}
+
+func parseGrade(s string) (icstate.Grade, error) {
+	switch strings.ToLower(s) {
+	case "main", "m":
+		return icstate.GradeMain, nil
+	case "sub", "s":
+		return icstate.GradeSub, nil
+	case "candidate", "c":
+		return icstate.GradeCandidate, nil
+	case "none", "n":
+		return icstate.GradeNone, nil
+	default:
+		return icstate.GradeNone, errors.IllegalArgumentError.Errorf("InvalidGrade(%s)", s)
+	}
+}
+
+func parseStatus(s string) (icstate.Status, error) {
+	switch strings.ToLower(s) {
+	case "active", "a":
+		return icstate.Active, nil
+	case "unregistered", "u":
+		return icstate.Unregistered, nil
+	case "disqualified", "d":
+		return icstate.Disqualified, nil
+	case "notready", "n":
+		return icstate.NotReady, nil
+	default:
+		return icstate.NotReady, errors.IllegalArgumentError.Errorf("InvalidStatus(%s)", s)
+	}
+}
+
+type expectChecker struct {
+	failures []string
+}
+
+func (ec *expectChecker) check(name string, c *condition, v *big.Int) {
+	if c != nil && !c.check(v) {
+		ec.failures = append(ec.failures,
+			fmt.Sprintf("%s: expected %s, actual %s", name, c, valueOrZero(v)))
+	}
+}
+
+func (ec *expectChecker) fail(format string, args ...interface{}) {
+	ec.failures = append(ec.failures, fmt.Sprintf(format, args...))
+}
+
+// check checks all expectations and returns an error listing the failed
+// ones.
+func (r *scenarioRunner) check(e *scenarioExpect) error {
+	ec := new(expectChecker)
+	sim := r.sim
+	ec.check("totalSupply", e.TotalSupply, sim.TotalSupply())
+	ec.check("totalStake", e.TotalStake, sim.TotalStake())
+	ec.check("totalBond", e.TotalBond, sim.TotalBond())
+	if e.TotalDelegated != nil {
+		totalDelegated, _ := sim.GetNetworkInfo()["totalDelegated"].(*big.Int)
+		ec.check("totalDelegated", e.TotalDelegated, totalDelegated)
+	}
+
+	names := make([]string, 0, len(e.Accounts))
+	for name := range e.Accounts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		ae := e.Accounts[name]
+		addr, err := r.address(name)
+		if err != nil {
+			return err
+		}
+		ec.check(name+".balance", ae.Balance, sim.GetBalance(addr))
+		if ae.Stake != nil {
+			stake, _ := sim.GetStake(addr)["stake"].(*big.Int)
+			ec.check(name+".stake", ae.Stake, stake)
+		}
+		if ae.Delegation != nil {
+			delegation, _ := sim.GetDelegation(addr)["totalDelegated"].(*big.Int)
+			ec.check(name+".delegation", ae.Delegation, delegation)
+		}
+		if ae.Bond != nil {
+			bond, _ := sim.GetBond(addr)["totalBonded"].(*big.Int)
+			ec.check(name+".bond", ae.Bond, bond)
+		}
+		if ae.IScore != nil {
+			ec.check(name+".iscore", ae.IScore, sim.QueryIScore(addr))
+		}
+		if ae.Delegated == nil && ae.Bonded == nil && ae.Grade == "" && ae.Status == "" {
+			continue
+		}
+		prep := sim.GetPRep(addr)
+		if prep == nil {
+			ec.fail("%s: not a P-Rep", name)
+			continue
+		}
+		ec.check(name+".delegated", ae.Delegated, prep.Delegated())
+		ec.check(name+".bonded", ae.Bonded, prep.Bonded())
+		if ae.Grade != "" {
+			grade, err := parseGrade(ae.Grade)
+			if err != nil {
+				return err
+			}
+			if prep.Grade() != grade {
+				ec.fail("%s.grade: expected %s, actual %s", name, grade, prep.Grade())
+			}
+		}
+		if ae.Status != "" {
+			status, err := parseStatus(ae.Status)
+			if err != nil {
+				return err
+			}
+			if prep.Status() != status {
+				ec.fail("%s.status: expected %s, actual %s", name, status, prep.Status())
+			}
+		}
+	}
+	if len(ec.failures) > 0 {
+		return errors.InvalidStateError.Errorf("ExpectationFailed\n\t%s",
+			strings.Join(ec.failures, "\n\t"))
+	}
+	return nil
+}
+
+func valueOrZero(v *big.Int) *big.Int {
+	if v == nil {
+		return icmodule.BigIntZero
+	}
+	return v
+}
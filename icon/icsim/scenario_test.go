@@ -0,0 +1,184 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package icsim
+
+import (
+	"bytes"
+	"encoding/csv"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/icon/iiss/icstate"
+	"github.com/icon-project/goloop/icon/iiss/icutils"
+)
+
+const testScenario = `
+revision: 14
+config:
+  termPeriod: 10
+  mainPRepCount: 2
+  subPRepCount: 2
+  # voting reward of the first term is calculated by IISS 2.0
+  rrep: 1
+validators: 2
+accounts:
+  prep1: 3000icx
+  prep2: 3000icx
+  prep3: 3000icx
+  user1: 10000icx
+  user2: "1000000000000000000000"
+  treasury: 100000icx
+steps:
+  - name: setup
+    transactions:
+      - {type: setRewardFund, iglobal: 3000000icx}
+      - {type: registerPRep, from: prep1}
+      - {type: registerPRep, from: prep2}
+      - {type: registerPRep, from: prep3}
+      - {type: setStake, from: prep1, amount: 100icx}
+      - {type: setBonderList, from: prep1, bonders: [prep1]}
+      - {type: setBond, from: prep1, bonds: [{address: prep1, value: 100icx}]}
+      - {type: setStake, from: prep2, amount: 100icx}
+      - {type: setBonderList, from: prep2, bonders: [prep2]}
+      - {type: setBond, from: prep2, bonds: [{address: prep2, value: 100icx}]}
+      - {type: setStake, from: user1, amount: 8000icx}
+      - {type: setDelegation, from: user2, delegations: [{address: prep1, value: 1icx}], fail: true}
+  - transactions:
+      - type: setDelegation
+        from: user1
+        delegations:
+          - {address: prep1, value: 4000icx}
+          - {address: prep2, value: 3000icx}
+          - {address: prep3, value: 1000icx}
+    expect:
+      totalStake: 8200icx
+      totalBond: 200icx
+      accounts:
+        user1: {stake: 8000icx, delegation: 8000icx, balance: 2000icx}
+        user2: {balance: 1000icx}
+        prep1: {bond: 100icx, bonded: 100icx, balance: 900icx, delegated: 4000icx, grade: candidate}
+  - name: decentralized
+    goToTermEnd: 2
+    expect:
+      accounts:
+        prep1: {grade: main, status: active}
+        prep3: {grade: sub}
+  - goToTermEnd: 3
+    absent: [prep2]
+    expect:
+      accounts:
+        user1: {iscore: "> 0"}
+  - transactions:
+      - {type: setRewardFund, iglobal: 1000000icx}
+      - {type: setRewardFundAllocation, iprep: 50, icps: 0, irelay: 0, ivoter: 50}
+      - {type: disqualifyPRep, address: prep3}
+      - {type: claimIScore, from: user1}
+    expect:
+      accounts:
+        prep3: {status: disqualified}
+        user1: {balance: "> 2000icx"}
+  - go: 5
+  - goTo: 200
+`
+
+func TestScenario_Run(t *testing.T) {
+	sc, err := ParseScenario([]byte(testScenario))
+	assert.NoError(t, err)
+
+	out := new(bytes.Buffer)
+	sim, err := sc.Run(out)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(200), sim.BlockHeight())
+
+	prep2 := sim.GetPRep(scenarioAddress("prep2"))
+	assert.NotZero(t, prep2.GetVFail(sim.BlockHeight()))
+
+	records, err := csv.NewReader(out).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, scenarioCSVHeader, records[0])
+	assert.True(t, len(records) > 10)
+	for i, record := range records[1:] {
+		assert.Len(t, record, len(scenarioCSVHeader))
+		if i > 0 {
+			assert.NotEqual(t, records[i][0], record[0])
+		}
+	}
+	assert.Equal(t, icutils.ToLoop(8200).String(), records[len(records)-1][5])
+}
+
+func TestScenario_Expectation(t *testing.T) {
+	sc, err := ParseScenario([]byte(`
+revision: 14
+accounts:
+  user1: 100icx
+steps:
+  - transactions:
+      - {type: setStake, from: user1, amount: 10.5icx}
+    expect:
+      totalStake: 10500000000000000000
+      accounts:
+        user1: {stake: "<= 10icx", balance: ">= 80icx"}
+`))
+	assert.NoError(t, err)
+	_, err = sc.Run(nil)
+	assert.True(t, errors.InvalidStateError.Equals(err))
+	assert.Contains(t, err.Error(), "user1.stake")
+	assert.NotContains(t, err.Error(), "user1.balance")
+}
+
+func TestScenario_Invalid(t *testing.T) {
+	_, err := ParseScenario([]byte("steps:\n  - goTo: 10\n    unknown: 1\n"))
+	assert.True(t, errors.IllegalArgumentError.Equals(err))
+
+	for _, s := range []string{
+		"revision: 4",
+		"config: {unknown: 1}",
+		"steps:\n  - {go: 1, goTo: 10}",
+		"steps:\n  - transactions: [{type: setStake, from: nobody, amount: 1}]",
+		"steps:\n  - transactions: [{type: transfer}]",
+	} {
+		sc, err := ParseScenario([]byte(s))
+		assert.NoError(t, err, s)
+		_, err = sc.Run(nil)
+		assert.True(t, errors.IllegalArgumentError.Equals(err), s)
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	for _, c := range []struct {
+		s     string
+		value *big.Int
+	}{
+		{"100", big.NewInt(100)},
+		{"0x64", big.NewInt(100)},
+		{"100 loop", big.NewInt(100)},
+		{"2ICX", icutils.ToLoop(2)},
+		{"0.5icx", new(big.Int).Div(icutils.ToLoop(1), big.NewInt(2))},
+	} {
+		v, err := parseAmount(c.s)
+		assert.NoError(t, err, c.s)
+		assert.Equal(t, 0, c.value.Cmp(v), c.s)
+	}
+	for _, s := range []string{"", "icx", "1e-30icx", "abc"} {
+		_, err := parseAmount(s)
+		assert.Error(t, err, s)
+	}
+	assert.Equal(t, "D", icstate.Disqualified.String())
+}
@@ -36,6 +36,13 @@ const (
 	TypeSetPRep
 	TypeSetRevision
 	TypeClaimIScore
+	TypeStake
+	TypeSetRewardFund
+	TypeSetRewardFundAllocation
+	TypeSetConsistentValidationSlashingRate
+	TypeSetNonVoteSlashingRate
+	TypeSetDoubleSignSlashingRate
+	TypeHandleDoubleSign
 )
 
 type Transaction interface {
@@ -88,11 +95,17 @@ type Simulator interface {
 	GoToTermEnd(csi module.ConsensusInfo) error
 	GoByBlock(block Block, csi module.ConsensusInfo) ([]Receipt, error)
 	GoByTransaction(tx Transaction, csi module.ConsensusInfo) ([]Receipt, error)
+	// ExecuteBlock executes transactions in a block like GoByBlock, but it
+	// also handles the block as Go does, including the end of a term.
+	ExecuteBlock(block Block, csi module.ConsensusInfo) ([]Receipt, error)
 
 	SetRevision(revision module.Revision) Transaction
 
 	GetStake(from module.Address) map[string]interface{}
 	SetStake(from module.Address, amount *big.Int) Transaction
+	// Stake works as setStake of the chain SCORE, which withdraws the balance
+	// and updates the total stake, while SetStake updates the stake only.
+	Stake(from module.Address, amount *big.Int) Transaction
 
 	QueryIScore(address module.Address) *big.Int
 	ClaimIScore(from module.Address) Transaction
@@ -113,4 +126,13 @@ type Simulator interface {
 	RegisterPRep(from module.Address, info *icstate.PRepInfo) Transaction
 	UnregisterPRep(from module.Address) Transaction
 	DisqualifyPRep(from module.Address, address module.Address) Transaction
+	// HandleDoubleSign works as the patch transaction for the evidence of
+	// double signing by the node at the height.
+	HandleDoubleSign(node module.Address, height int64) Transaction
+
+	SetRewardFund(iglobal *big.Int) Transaction
+	SetRewardFundAllocation(iprep, icps, irelay, ivoter *big.Int) Transaction
+	SetConsistentValidationSlashingRate(rate int) Transaction
+	SetNonVoteSlashingRate(rate int) Transaction
+	SetDoubleSignSlashingRate(rate int) Transaction
 }
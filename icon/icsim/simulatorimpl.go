@@ -132,6 +132,7 @@ func (sim *simulatorImpl) initRevHandler() {
 		icmodule.Revision14: sim.handleRev14,
 		icmodule.Revision15: sim.handleRev15,
 		icmodule.Revision17: sim.handleRev17,
+		icmodule.Revision21: sim.handleRev21,
 	}
 }
 
@@ -224,6 +225,18 @@ func (sim *simulatorImpl) setStake(es *iiss.ExtensionStateImpl, tx Transaction)
 	return es.State.GetAccountState(from).SetStake(amount)
 }
 
+func (sim *simulatorImpl) Stake(from module.Address, amount *big.Int) Transaction {
+	return NewTransaction(TypeStake, []interface{}{from, amount})
+}
+
+func (sim *simulatorImpl) stake(es *iiss.ExtensionStateImpl, wc WorldContext, tx Transaction) error {
+	args := tx.Args()
+	from := args[0].(module.Address)
+	amount := args[1].(*big.Int)
+	cc := NewCallContext(wc, from)
+	return es.SetStake(cc, amount)
+}
+
 // Go generates as many blocks as the number passed as a parameter "blocks"
 func (sim *simulatorImpl) Go(blocks int64, csi module.ConsensusInfo) error {
 	if blocks == 0 {
@@ -301,6 +314,14 @@ func (sim *simulatorImpl) GoToTermEnd(csi module.ConsensusInfo) error {
 }
 
 func (sim *simulatorImpl) GoByBlock(block Block, csi module.ConsensusInfo) ([]Receipt, error) {
+	return sim.goByBlock(block, csi, false)
+}
+
+func (sim *simulatorImpl) ExecuteBlock(block Block, csi module.ConsensusInfo) ([]Receipt, error) {
+	return sim.goByBlock(block, csi, true)
+}
+
+func (sim *simulatorImpl) goByBlock(block Block, csi module.ConsensusInfo, full bool) ([]Receipt, error) {
 	var err error
 	wss := sim.wss
 	ws := newWorldState(wss, false)
@@ -309,6 +330,15 @@ func (sim *simulatorImpl) GoByBlock(block Block, csi module.ConsensusInfo) ([]Re
 	blockHeight := sim.blockHeight + 1
 	receipts := make([]Receipt, len(block.Txs()))
 
+	if full {
+		wc := NewWorldContext(ws, blockHeight, sim.Revision(), csi, sim.stepPrice)
+		if err = sim.onExecutionBegin(wc); err != nil {
+			return nil, err
+		}
+		if err = sim.onBaseTx(wc); err != nil {
+			return nil, err
+		}
+	}
 	for i, tx := range block.Txs() {
 		wss = ws.GetSnapshot()
 		err = sim.executeTx(csi, ws, tx)
@@ -321,11 +351,21 @@ func (sim *simulatorImpl) GoByBlock(block Block, csi module.ConsensusInfo) ([]Re
 		}
 	}
 
+	if full {
+		wc := NewWorldContext(ws, blockHeight, sim.Revision(), csi, sim.stepPrice)
+		if err = sim.onExecutionEnd(wc); err != nil {
+			return nil, err
+		}
+	}
+
 	wss = ws.GetSnapshot()
 	if err = wss.Flush(); err != nil {
 		return nil, err
 	}
 
+	if full {
+		sim.onFinalize(wss)
+	}
 	sim.wss = wss
 	sim.blockHeight = blockHeight
 	return receipts, nil
@@ -365,6 +405,20 @@ func (sim *simulatorImpl) executeTx(csi module.ConsensusInfo, ws state.WorldStat
 		err = sim.setRevision(wc, tx)
 	case TypeClaimIScore:
 		err = sim.claimIScore(es, wc, tx)
+	case TypeStake:
+		err = sim.stake(es, wc, tx)
+	case TypeSetRewardFund:
+		err = sim.setRewardFund(es, tx)
+	case TypeSetRewardFundAllocation:
+		err = sim.setRewardFundAllocation(es, tx)
+	case TypeSetConsistentValidationSlashingRate:
+		err = sim.setConsistentValidationSlashingRate(es, tx)
+	case TypeSetNonVoteSlashingRate:
+		err = sim.setNonVoteSlashingRate(es, tx)
+	case TypeSetDoubleSignSlashingRate:
+		err = sim.setDoubleSignSlashingRate(es, tx)
+	case TypeHandleDoubleSign:
+		err = sim.handleDoubleSign(es, wc, tx)
 	default:
 		return errors.Errorf("Unexpected transaction: %v", tx.Type())
 	}
@@ -409,6 +463,18 @@ func (sim *simulatorImpl) disqualifyPRep(es *iiss.ExtensionStateImpl, wc WorldCo
 	return es.DisqualifyPRep(cc, address)
 }
 
+func (sim *simulatorImpl) HandleDoubleSign(node module.Address, height int64) Transaction {
+	return NewTransaction(TypeHandleDoubleSign, []interface{}{node, height})
+}
+
+func (sim *simulatorImpl) handleDoubleSign(es *iiss.ExtensionStateImpl, wc WorldContext, tx Transaction) error {
+	args := tx.Args()
+	node := args[0].(module.Address)
+	height := args[1].(int64)
+	cc := NewCallContext(wc, state.SystemAddress)
+	return es.PenalizeDoubleSign(cc, node, height)
+}
+
 func (sim *simulatorImpl) SetPRep(from module.Address, info *icstate.PRepInfo) Transaction {
 	return NewTransaction(TypeSetPRep, []interface{}{from, info})
 }
@@ -424,6 +490,9 @@ func (sim *simulatorImpl) setPRep(es *iiss.ExtensionStateImpl, wc WorldContext,
 func (sim *simulatorImpl) GetDelegation(from module.Address) map[string]interface{} {
 	es := sim.getExtensionState(true)
 	ia := es.State.GetAccountSnapshot(from)
+	if ia == nil {
+		ia = icstate.GetEmptyAccountSnapshot()
+	}
 	return ia.GetDelegationInJSON()
 }
 
@@ -484,6 +553,58 @@ func (sim *simulatorImpl) claimIScore(es *iiss.ExtensionStateImpl, wc WorldConte
 	return es.ClaimIScore(cc)
 }
 
+func (sim *simulatorImpl) SetRewardFund(iglobal *big.Int) Transaction {
+	return NewTransaction(TypeSetRewardFund, []interface{}{iglobal})
+}
+
+func (sim *simulatorImpl) setRewardFund(es *iiss.ExtensionStateImpl, tx Transaction) error {
+	args := tx.Args()
+	rf := es.State.GetRewardFund()
+	rf.Iglobal = args[0].(*big.Int)
+	return es.State.SetRewardFund(rf)
+}
+
+func (sim *simulatorImpl) SetRewardFundAllocation(iprep, icps, irelay, ivoter *big.Int) Transaction {
+	return NewTransaction(TypeSetRewardFundAllocation, []interface{}{iprep, icps, irelay, ivoter})
+}
+
+func (sim *simulatorImpl) setRewardFundAllocation(es *iiss.ExtensionStateImpl, tx Transaction) error {
+	args := tx.Args()
+	rf := es.State.GetRewardFund()
+	rf.Iprep = args[0].(*big.Int)
+	rf.Icps = args[1].(*big.Int)
+	rf.Irelay = args[2].(*big.Int)
+	rf.Ivoter = args[3].(*big.Int)
+	return es.State.SetRewardFund(rf)
+}
+
+func (sim *simulatorImpl) SetConsistentValidationSlashingRate(rate int) Transaction {
+	return NewTransaction(TypeSetConsistentValidationSlashingRate, []interface{}{rate})
+}
+
+func (sim *simulatorImpl) setConsistentValidationSlashingRate(es *iiss.ExtensionStateImpl, tx Transaction) error {
+	args := tx.Args()
+	return es.State.SetConsistentValidationPenaltySlashRatio(args[0].(int))
+}
+
+func (sim *simulatorImpl) SetNonVoteSlashingRate(rate int) Transaction {
+	return NewTransaction(TypeSetNonVoteSlashingRate, []interface{}{rate})
+}
+
+func (sim *simulatorImpl) setNonVoteSlashingRate(es *iiss.ExtensionStateImpl, tx Transaction) error {
+	args := tx.Args()
+	return es.State.SetNonVotePenaltySlashRatio(args[0].(int))
+}
+
+func (sim *simulatorImpl) SetDoubleSignSlashingRate(rate int) Transaction {
+	return NewTransaction(TypeSetDoubleSignSlashingRate, []interface{}{rate})
+}
+
+func (sim *simulatorImpl) setDoubleSignSlashingRate(es *iiss.ExtensionStateImpl, tx Transaction) error {
+	args := tx.Args()
+	return es.State.SetDoubleSignPenaltySlashRatio(args[0].(int))
+}
+
 func (sim *simulatorImpl) QueryIScore(address module.Address) *big.Int {
 	es := sim.getExtensionState(true)
 	iscore, _ := es.GetIScore(address, sim.revision.Value(), nil)
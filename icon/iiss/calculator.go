@@ -18,6 +18,7 @@ package iiss
 
 import (
 	"bytes"
+	"context"
 	"math/big"
 	"sort"
 	"sync"
@@ -75,6 +76,8 @@ type Calculator struct {
 	global      icstage.Global
 	temp        *icreward.State
 	stats       *statistics
+	detail      *RewardDetail
+	ctx         context.Context
 
 	lock    sync.Mutex
 	waiters []*sync.Cond
@@ -106,6 +109,21 @@ func (c *Calculator) Temp() *icreward.State {
 	return c.temp
 }
 
+// Detail returns the breakdown of the reward for the address given to
+// CalculateReward.
+func (c *Calculator) Detail() *RewardDetail {
+	return c.detail
+}
+
+// interrupted returns the error of the context given to CalculateReward
+// if it's done.
+func (c *Calculator) interrupted() error {
+	if c.ctx == nil {
+		return nil
+	}
+	return c.ctx.Err()
+}
+
 func (c *Calculator) Error() error {
 	return c.err
 }
@@ -317,6 +335,9 @@ func (c *Calculator) updateIScore(addr module.Address, reward *big.Int, t Reward
 		return err
 	}
 	c.log.Tracef("Update IScore %s by %d: %+v + %s = %+v", addr, t, iScore, reward, nIScore)
+	if c.detail != nil && c.detail.address.Equal(addr) {
+		c.detail.add(t, reward)
+	}
 
 	switch t {
 	case TypeBlockProduce:
@@ -851,6 +872,9 @@ func (c *Calculator) processVoting(
 		prefix = icreward.BondingKey.Build()
 	}
 	for iter := c.base.Filter(prefix); iter.Has(); iter.Next() {
+		if err := c.interrupted(); err != nil {
+			return err
+		}
 		o, key, err := iter.Get()
 		if err != nil {
 			return err
@@ -874,7 +898,8 @@ func (c *Calculator) processVoting(
 				c.log.Errorf("Failed to convert data to voting instance")
 				continue
 			}
-			reward = c.votingReward(multiplier, divider, from, to, prepInfo, voting.Iterator())
+			reward = c.votingRewardWith(multiplier, divider, from, to, prepInfo, voting.Iterator(),
+				c.detail.votingRecorder(addr, _type, false))
 		}
 		if err = c.updateIScore(addr, reward, TypeVoting); err != nil {
 			return err
@@ -901,6 +926,20 @@ func (c *Calculator) votingReward(
 	to int,
 	prepInfo map[string]*pRepEnable,
 	iter icstate.VotingIterator,
+) *big.Int {
+	return c.votingRewardWith(multiplier, divider, from, to, prepInfo, iter, nil)
+}
+
+// votingRewardWith works like votingReward, and calls record with the reward
+// for each P-Rep if record is not nil.
+func (c *Calculator) votingRewardWith(
+	multiplier *big.Int,
+	divider *big.Int,
+	from int,
+	to int,
+	prepInfo map[string]*pRepEnable,
+	iter icstate.VotingIterator,
+	record func(prep module.Address, reward *big.Int),
 ) *big.Int {
 	total := new(big.Int)
 	checkMinVoting := c.global.GetIISSVersion() == icstate.IISSVersion2
@@ -928,6 +967,9 @@ func (c *Calculator) votingReward(
 				reward.Mul(reward, big.NewInt(int64(period)))
 				reward.Div(reward, divider)
 				total.Add(total, reward)
+				if record != nil {
+					record(voting.To(), reward)
+				}
 				c.log.Tracef("VotingReward %s: %s = %s * %s * %d / %s",
 					voting.To(), reward, multiplier, voting.Amount(), period, divider)
 			}
@@ -945,6 +987,9 @@ func (c *Calculator) processVotingEvent(
 	eventMap map[string]map[int]icstage.VoteList,
 ) error {
 	for key, events := range eventMap { // each account
+		if err := c.interrupted(); err != nil {
+			return err
+		}
 		addr, _ := common.NewAddress([]byte(key))
 		reward := new(big.Int)
 		offsets := make([]int, 0, len(events))
@@ -959,6 +1004,9 @@ func (c *Calculator) processVotingEvent(
 			return err
 		}
 
+		add := c.detail.votingRecorder(addr, _type, false)
+		sub := c.detail.votingRecorder(addr, _type, true)
+
 		// initial voting took place in the previous period
 		// New configuration works from the next block
 		from := -1
@@ -968,15 +1016,15 @@ func (c *Calculator) processVotingEvent(
 			to := offsets[i]
 			switch iissVersion {
 			case icstate.IISSVersion2:
-				ret := c.votingReward(multiplier, divider, from, offsetLimit, prepInfo, voting.Iterator())
+				ret := c.votingRewardWith(multiplier, divider, from, offsetLimit, prepInfo, voting.Iterator(), add)
 				reward.Add(reward, ret)
 				c.log.Tracef("VotingEvent %s %d add: %d-%d %s", addr, i, from, offsetLimit, ret)
-				ret = c.votingReward(multiplier, divider, to, offsetLimit, prepInfo, voting.Iterator())
+				ret = c.votingRewardWith(multiplier, divider, to, offsetLimit, prepInfo, voting.Iterator(), sub)
 				reward.Sub(reward, ret)
 				c.log.Tracef("VotingEvent %s %d sub: %d-%d %s", addr, i, to, offsetLimit, ret)
 			case icstate.IISSVersion3:
 				to = offsets[i]
-				ret := c.votingReward(multiplier, divider, from, to, prepInfo, voting.Iterator())
+				ret := c.votingRewardWith(multiplier, divider, from, to, prepInfo, voting.Iterator(), add)
 				reward.Add(reward, ret)
 				c.log.Tracef("VotingEvent %s %d: %d-%d %s", addr, i, from, to, ret)
 			}
@@ -991,7 +1039,7 @@ func (c *Calculator) processVotingEvent(
 			from = to
 		}
 		// calculate reward for last event
-		ret := c.votingReward(multiplier, divider, from, offsetLimit, prepInfo, voting.Iterator())
+		ret := c.votingRewardWith(multiplier, divider, from, offsetLimit, prepInfo, voting.Iterator(), add)
 		reward.Add(reward, ret)
 		c.log.Tracef("VotingEvent %s last: %d, %d: %s", addr, from, offsetLimit, ret)
 
@@ -1116,15 +1164,12 @@ func (c *Calculator) processBTP() error {
 
 const InitBlockHeight = -1
 
-func NewCalculator(database db.Database, back *icstage.Snapshot, reward *icreward.Snapshot, logger log.Logger) *Calculator {
-	var err error
-	var global icstage.Global
+func newCalculator(database db.Database, back *icstage.Snapshot, reward *icreward.Snapshot, logger log.Logger) (*Calculator, error) {
 	var startHeight int64
 
-	global, err = back.GetGlobal()
+	global, err := back.GetGlobal()
 	if err != nil {
-		logger.Errorf("Failed to get Global values for calculator. %+v", err)
-		return nil
+		return nil, err
 	}
 	if global == nil {
 		// back has no global at first term
@@ -1142,12 +1187,48 @@ func NewCalculator(database db.Database, back *icstage.Snapshot, reward *icrewar
 		startHeight: startHeight,
 		stats:       newStatistics(),
 	}
-	if startHeight != InitBlockHeight {
+	return c, nil
+}
+
+func NewCalculator(database db.Database, back *icstage.Snapshot, reward *icreward.Snapshot, logger log.Logger) *Calculator {
+	c, err := newCalculator(database, back, reward, logger)
+	if err != nil {
+		logger.Errorf("Failed to get Global values for calculator. %+v", err)
+		return nil
+	}
+	if c.startHeight != InitBlockHeight {
 		go c.run()
 	}
 	return c
 }
 
+// CalculateReward runs the calculation with back and reward synchronously
+// without writing the result to the database. If address is not nil, the
+// reward of the address is recorded in the Detail of the calculator.
+// The calculation stops with the error of ctx when ctx is done.
+func CalculateReward(
+	ctx context.Context,
+	database db.Database, back *icstage.Snapshot, reward *icreward.Snapshot,
+	address module.Address, logger log.Logger,
+) (*Calculator, error) {
+	c, err := newCalculator(database, back, reward, logger)
+	if err != nil {
+		return nil, err
+	}
+	c.ctx = ctx
+	if c.startHeight == InitBlockHeight {
+		return nil, errors.NotFoundError.New("NoCalculation")
+	}
+	if address != nil {
+		c.detail = newRewardDetail(address, c.startHeight,
+			c.startHeight+int64(c.global.GetOffsetLimit()))
+	}
+	if err = c.run(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
 type CalculatorHolder struct {
 	lock   sync.Mutex
 	runner *Calculator
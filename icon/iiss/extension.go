@@ -18,6 +18,7 @@ package iiss
 
 import (
 	"bytes"
+	"context"
 	"math"
 	"math/big"
 	"sort"
@@ -64,6 +65,29 @@ func (s *ExtensionSnapshotImpl) Reward() *icreward.Snapshot {
 	return s.reward
 }
 
+// TermInfo returns the start height and the period of the current term.
+// The period is zero if there is no term yet.
+func (s *ExtensionSnapshotImpl) TermInfo() (int64, int64) {
+	es := icstate.NewStateFromSnapshot(s.state, true, icutils.NewIconLogger(nil))
+	term := es.GetTermSnapshot()
+	if term == nil {
+		return 0, 0
+	}
+	return term.StartHeight(), term.Period()
+}
+
+// RewardDetail runs the calculation for the previous term again, and returns
+// the breakdown of the reward for the address. It stops when ctx is done.
+func (s *ExtensionSnapshotImpl) RewardDetail(
+	ctx context.Context, address module.Address, logger log.Logger,
+) (map[string]interface{}, error) {
+	c, err := CalculateReward(ctx, s.database, s.back2, s.reward, address, logger)
+	if err != nil {
+		return nil, err
+	}
+	return c.Detail().ToJSON(), nil
+}
+
 func (s *ExtensionSnapshotImpl) Bytes() []byte {
 	return codec.BC.MustMarshalToBytes(s)
 }
@@ -166,6 +190,7 @@ type ExtensionStateImpl struct {
 	log              []ExtensionLog
 	illegalDelegated map[string]*icstate.PRepStatusState
 	claimed          map[string]*Claimed
+	recorder         state.ExtensionAccessRecorder
 
 	State  *icstate.State
 	Front  *icstage.State
@@ -576,6 +601,10 @@ func (es *ExtensionStateImpl) UnregisterPRep(cc icmodule.CallContext) error {
 }
 
 func (es *ExtensionStateImpl) DisqualifyPRep(cc icmodule.CallContext, address module.Address) error {
+	return es.disqualifyPRep(cc, address, icmodule.PenaltyPRepDisqualification)
+}
+
+func (es *ExtensionStateImpl) disqualifyPRep(cc icmodule.CallContext, address module.Address, pt icmodule.PenaltyType) error {
 	blockHeight := cc.BlockHeight()
 	if err := es.State.DisablePRep(address, icstate.Disqualified, blockHeight); err != nil {
 		return err
@@ -589,7 +618,7 @@ func (es *ExtensionStateImpl) DisqualifyPRep(cc icmodule.CallContext, address mo
 		[][]byte{[]byte("PenaltyImposed(Address,int,int)"), address.Bytes()},
 		[][]byte{
 			intconv.Int64ToBytes(int64(ps.Status())),
-			intconv.Int64ToBytes(int64(icmodule.PenaltyPRepDisqualification)),
+			intconv.Int64ToBytes(int64(pt)),
 		},
 	)
 	return nil
@@ -1439,6 +1468,7 @@ func (es *ExtensionStateImpl) GetIScore(from module.Address, revision int, txID
 		}
 		// replay ICON1's queryIScore behavior
 		if revision < icmodule.RevisionFixClaimIScore && i == 0 && len(txID) != 0 {
+			es.onWholeRead()
 			if claimed, ok := es.claimed[icutils.ToKey(from)]; ok {
 				if bytes.Compare(claimed.ID(), txID) == 0 {
 					// Subtract claimed amount only when claimIScore and queryIScore are in the same TX
@@ -1459,6 +1489,28 @@ func (es *ExtensionStateImpl) GetIScore(from module.Address, revision int, txID
 	return iScore, nil
 }
 
+// GetIScoreBreakdownInJSON runs the calculation for the previous term again
+// and returns the breakdown of the reward for the address. It stops when ctx
+// is done.
+func (es *ExtensionStateImpl) GetIScoreBreakdownInJSON(
+	ctx context.Context, address module.Address,
+) (map[string]interface{}, error) {
+	if es.Back2 == nil || es.Reward == nil {
+		return nil, scoreresult.InvalidRequestError.New("NoCalculation")
+	}
+	c, err := CalculateReward(ctx, es.database, es.Back2.GetSnapshot(), es.Reward.GetSnapshot(), address, es.logger)
+	if err != nil {
+		if errors.NotFoundError.Equals(err) {
+			return nil, scoreresult.InvalidRequestError.Wrap(err, "NoCalculation")
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, scoreresult.TimeoutError.Wrap(err, "CalculationTimeout")
+		}
+		return nil, scoreresult.UnknownFailureError.Wrapf(err, "Failed to calculate reward: address=%v", address)
+	}
+	return c.Detail().ToJSON(), nil
+}
+
 func (es *ExtensionStateImpl) ClaimIScore(cc icmodule.CallContext) error {
 	from := cc.From()
 
@@ -1502,6 +1554,7 @@ func (es *ExtensionStateImpl) ClaimIScore(cc icmodule.CallContext) error {
 	if revision < icmodule.RevisionFixClaimIScore {
 		cl := NewClaimIScoreLog(from, claim, ic)
 		es.AppendExtensionLog(cl)
+		es.onWholeWrite()
 		if es.claimed == nil {
 			es.claimed = make(map[string]*Claimed)
 		}
@@ -0,0 +1,111 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package iiss
+
+import (
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/trie"
+	"github.com/icon-project/goloop/icon/iiss/icobject"
+	"github.com/icon-project/goloop/service/state"
+)
+
+var _ state.RecordableExtensionState = (*ExtensionStateImpl)(nil)
+
+// Keys reported to the recorder are prefixed with the index of the store
+// in the order of stores().
+func (es *ExtensionStateImpl) stores() []*icobject.ObjectStoreState {
+	return []*icobject.ObjectStoreState{
+		es.State.Store(),
+		es.Front.Store(),
+		es.Back1.Store(),
+		es.Back2.Store(),
+		es.Reward.Store(),
+	}
+}
+
+func (es *ExtensionStateImpl) storeForKey(key []byte) (*icobject.ObjectStoreState, []byte, error) {
+	stores := es.stores()
+	if len(key) < 1 || int(key[0]) >= len(stores) {
+		return nil, nil, errors.IllegalArgumentError.Errorf("InvalidExtensionKey(key=%#x)", key)
+	}
+	return stores[key[0]], key[1:], nil
+}
+
+func (es *ExtensionStateImpl) SetAccessRecorder(r state.ExtensionAccessRecorder) {
+	es.recorder = r
+	for i, store := range es.stores() {
+		if r == nil {
+			store.SetAccessHook(nil)
+			continue
+		}
+		prefix := byte(i)
+		store.SetAccessHook(func(key []byte, write bool) {
+			k := make([]byte, len(key)+1)
+			k[0] = prefix
+			copy(k[1:], key)
+			if write {
+				r.OnWrite(k)
+			} else {
+				r.OnRead(k)
+			}
+		})
+	}
+}
+
+// onWholeRead and onWholeWrite are for the states which are not in the
+// stores.
+func (es *ExtensionStateImpl) onWholeRead() {
+	if es.recorder != nil {
+		es.recorder.OnRead(nil)
+	}
+}
+
+func (es *ExtensionStateImpl) onWholeWrite() {
+	if es.recorder != nil {
+		es.recorder.OnWrite(nil)
+	}
+}
+
+func (es *ExtensionStateImpl) GetValue(key []byte) (trie.Object, error) {
+	store, k, err := es.storeForKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return store.Get(k)
+}
+
+func (es *ExtensionStateImpl) SetValues(values map[string]trie.Object) error {
+	if err := es.State.Flush(); err != nil {
+		return err
+	}
+	for key, value := range values {
+		store, k, err := es.storeForKey([]byte(key))
+		if err != nil {
+			return err
+		}
+		if value == nil {
+			_, err = store.Delete(k)
+		} else {
+			_, err = store.Set(k, value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	es.State.ResetCache()
+	return nil
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package iiss
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/trie"
+)
+
+type testAccessRecorder struct {
+	reads  map[string]bool
+	writes map[string]bool
+}
+
+func (r *testAccessRecorder) OnRead(key []byte) {
+	r.reads[string(key)] = true
+}
+
+func (r *testAccessRecorder) OnWrite(key []byte) {
+	r.writes[string(key)] = true
+}
+
+func newTestAccessRecorder() *testAccessRecorder {
+	return &testAccessRecorder{
+		reads:  make(map[string]bool),
+		writes: make(map[string]bool),
+	}
+}
+
+func TestExtensionStateImpl_Record(t *testing.T) {
+	ess := NewExtensionSnapshot(db.NewMapDB(), nil)
+	addr := common.MustNewAddressFromString("hx1")
+
+	es1 := ess.NewState(false).(*ExtensionStateImpl)
+	rec := newTestAccessRecorder()
+	es1.SetAccessRecorder(rec)
+	assert.NoError(t, es1.State.GetAccountState(addr).SetStake(big.NewInt(100)))
+	_, err := es1.Front.AddIScoreClaim(addr, big.NewInt(10))
+	assert.NoError(t, err)
+	ess1 := es1.GetSnapshot()
+	es1.SetAccessRecorder(nil)
+
+	stores := make(map[byte]bool)
+	for k := range rec.writes {
+		stores[k[0]] = true
+	}
+	assert.Equal(t, map[byte]bool{0: true, 1: true}, stores)
+	assert.NotEmpty(t, rec.reads)
+
+	// apply written values to other state having the account in its cache
+	es2 := ess.NewState(false).(*ExtensionStateImpl)
+	assert.Equal(t, 0, es2.State.GetAccountState(addr).Stake().Sign())
+	values := make(map[string]trie.Object)
+	res := ess1.NewState(true).(*ExtensionStateImpl)
+	for k := range rec.writes {
+		values[k], err = res.GetValue([]byte(k))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, es2.SetValues(values))
+	assert.Equal(t, int64(100), es2.State.GetAccountState(addr).Stake().Int64())
+	assert.Equal(t, ess1.Bytes(), es2.GetSnapshot().Bytes())
+
+	_, err = es2.GetValue([]byte{5})
+	assert.Error(t, err)
+}
@@ -33,10 +33,41 @@ func (bytesConverter) BytesToObject(value []byte) trie.Object {
 type ObjectStoreState struct {
 	trie.MutableForObject
 	bytesConverter
+
+	hook func(key []byte, write bool)
+}
+
+// SetAccessHook sets the function called on accessing the keys of the
+// store. Use nil to clear it.
+func (o *ObjectStoreState) SetAccessHook(f func(key []byte, write bool)) {
+	o.hook = f
+}
+
+func (o *ObjectStoreState) Get(key []byte) (trie.Object, error) {
+	if o.hook != nil {
+		o.hook(key, false)
+	}
+	return o.MutableForObject.Get(key)
+}
+
+func (o *ObjectStoreState) Set(key []byte, obj trie.Object) (trie.Object, error) {
+	if o.hook != nil {
+		o.hook(key, true)
+	}
+	return o.MutableForObject.Set(key, obj)
+}
+
+func (o *ObjectStoreState) Delete(key []byte) (trie.Object, error) {
+	if o.hook != nil {
+		o.hook(key, true)
+	}
+	return o.MutableForObject.Delete(key)
 }
 
 func NewObjectStoreState(t trie.MutableForObject) *ObjectStoreState {
-	return &ObjectStoreState{t, bytesConverter{}}
+	return &ObjectStoreState{
+		MutableForObject: t,
+	}
 }
 
 type ObjectStoreSnapshot struct {
@@ -53,6 +53,11 @@ func (s *State) Reset(ss *Snapshot) {
 	s.store.Reset(ss.store.ImmutableForObject)
 }
 
+// Store returns the object store of the state.
+func (s *State) Store() *icobject.ObjectStoreState {
+	return s.store
+}
+
 func (s *State) GetIScore(addr module.Address) (*IScore, error) {
 	key := IScoreKey.Append(addr).Build()
 	obj, err := s.store.Get(key)
@@ -62,6 +62,11 @@ func (s *State) Reset(ss *Snapshot) {
 	s.store.Reset(ss.store.ImmutableForObject)
 }
 
+// Store returns the object store of the state.
+func (s *State) Store() *icobject.ObjectStoreState {
+	return s.store
+}
+
 func (s *State) GetIScoreClaim(addr module.Address) (*IScoreClaim, error) {
 	key := IScoreClaimKey.Append(addr).Build()
 	obj, err := s.store.Get(key)
@@ -82,6 +82,13 @@ type State struct {
 
 func (s *State) Reset(ss *Snapshot) error {
 	s.store.Reset(ss.store.ImmutableForObject)
+	s.ResetCache()
+	return nil
+}
+
+// ResetCache reloads the cached objects from the store. It's used after
+// modifying the store directly.
+func (s *State) ResetCache() {
 	s.accountCache.Reset()
 	s.nodeOwnerCache.Reset()
 	s.prepBaseCache.Reset()
@@ -89,7 +96,12 @@ func (s *State) Reset(ss *Snapshot) error {
 	s.unstakingTimerCache.Reset()
 	s.unbondingTimerCache.Reset()
 	s.networkScoreTimerCache.Reset()
-	return nil
+}
+
+// Store returns the object store of the state. Cached objects need to be
+// flushed before accessing it.
+func (s *State) Store() *icobject.ObjectStoreState {
+	return s.store
 }
 
 func (s *State) Flush() error {
@@ -23,8 +23,11 @@ import (
 	"github.com/icon-project/goloop/common/intconv"
 	"github.com/icon-project/goloop/icon/icmodule"
 	"github.com/icon-project/goloop/icon/iiss/icstage"
+	"github.com/icon-project/goloop/icon/iiss/icstate"
 	"github.com/icon-project/goloop/icon/iiss/icutils"
 	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/contract"
+	"github.com/icon-project/goloop/service/scoreresult"
 	"github.com/icon-project/goloop/service/state"
 )
 
@@ -70,6 +73,31 @@ func (es *ExtensionStateImpl) handlePenalty(cc icmodule.CallContext, owner modul
 	return es.addEventEnable(blockHeight, owner, icstage.ESDisableTemp)
 }
 
+// HandleDoubleSign imposes the penalty on the P-Rep whose node signed
+// conflicting votes. It's called by the patch handler of the evidence.
+func (es *ExtensionStateImpl) HandleDoubleSign(cc contract.CallContext, signer module.Address, height int64) error {
+	return es.PenalizeDoubleSign(NewCallContext(cc, state.SystemAddress), signer, height)
+}
+
+// PenalizeDoubleSign slashes the bond of the P-Rep whose node signed
+// conflicting votes at the height, and disqualifies the P-Rep.
+func (es *ExtensionStateImpl) PenalizeDoubleSign(cc icmodule.CallContext, signer module.Address, height int64) error {
+	if cc.Revision().Value() < icmodule.RevisionPenalizeDoubleSign {
+		return scoreresult.InvalidRequestError.New("DoubleSignPenaltyDisabled")
+	}
+	owner := es.State.GetOwnerByNode(signer)
+	ps := es.State.GetPRepStatusByOwner(owner, false)
+	if ps == nil || ps.Status() != icstate.Active {
+		return scoreresult.InvalidParameterError.Errorf("InvalidPRep(node=%s)", signer)
+	}
+	cc.FrameLogger().TSystemf("IISS double sign penalty owner=%s node=%s height=%d", owner, signer, height)
+
+	if err := es.slash(cc, owner, es.State.GetDoubleSignPenaltySlashRatio()); err != nil {
+		return err
+	}
+	return es.disqualifyPRep(cc, owner, icmodule.PenaltyDoubleSign)
+}
+
 func (es *ExtensionStateImpl) slash(cc icmodule.CallContext, owner module.Address, ratio int) error {
 	if ratio < 0 || 100 < ratio {
 		return errors.Errorf("Invalid slash ratio %d", ratio)
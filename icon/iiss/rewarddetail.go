@@ -0,0 +1,164 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package iiss
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/icon/iiss/icreward"
+	"github.com/icon-project/goloop/icon/iiss/icutils"
+	"github.com/icon-project/goloop/module"
+)
+
+// RewardDetail is a breakdown of I-Score rewarded to an account by a
+// calculation. Voting reward is broken down by the P-Rep which the account
+// delegated or bonded to.
+type RewardDetail struct {
+	address      module.Address
+	startHeight  int64
+	endHeight    int64
+	blockProduce *big.Int
+	voted        *big.Int
+	voting       *big.Int
+	delegating   map[string]*big.Int
+	bonding      map[string]*big.Int
+}
+
+func (d *RewardDetail) Address() module.Address {
+	return d.address
+}
+
+func (d *RewardDetail) BlockProduce() *big.Int {
+	return d.blockProduce
+}
+
+func (d *RewardDetail) Voted() *big.Int {
+	return d.voted
+}
+
+func (d *RewardDetail) Voting() *big.Int {
+	return d.voting
+}
+
+// Delegating returns the voting reward for delegating to the P-Rep.
+func (d *RewardDetail) Delegating(prep module.Address) *big.Int {
+	return valueOrZero(d.delegating[icutils.ToKey(prep)])
+}
+
+// Bonding returns the voting reward for bonding to the P-Rep.
+func (d *RewardDetail) Bonding(prep module.Address) *big.Int {
+	return valueOrZero(d.bonding[icutils.ToKey(prep)])
+}
+
+func (d *RewardDetail) IScore() *big.Int {
+	total := new(big.Int).Add(d.blockProduce, d.voted)
+	return total.Add(total, d.voting)
+}
+
+func (d *RewardDetail) add(t RewardType, reward *big.Int) {
+	switch t {
+	case TypeBlockProduce:
+		d.blockProduce.Add(d.blockProduce, reward)
+	case TypeVoted:
+		d.voted.Add(d.voted, reward)
+	case TypeVoting:
+		d.voting.Add(d.voting, reward)
+	}
+}
+
+// votingRecorder returns the function recording the voting reward of the
+// account for each P-Rep. It returns nil if the account is not the target.
+// The reward is subtracted if negative is true.
+func (d *RewardDetail) votingRecorder(addr module.Address, _type int, negative bool) func(module.Address, *big.Int) {
+	if d == nil || !d.address.Equal(addr) {
+		return nil
+	}
+	m := d.delegating
+	if _type == icreward.TypeBonding {
+		m = d.bonding
+	}
+	return func(prep module.Address, reward *big.Int) {
+		key := icutils.ToKey(prep)
+		value, ok := m[key]
+		if !ok {
+			value = new(big.Int)
+			m[key] = value
+		}
+		if negative {
+			value.Sub(value, reward)
+		} else {
+			value.Add(value, reward)
+		}
+	}
+}
+
+func votingDetailToJSON(m map[string]*big.Int) []interface{} {
+	keys := make([]string, 0, len(m))
+	for key, value := range m {
+		if value.Sign() != 0 {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return bytes.Compare([]byte(keys[i]), []byte(keys[j])) < 0
+	})
+	jso := make([]interface{}, len(keys))
+	for i, key := range keys {
+		jso[i] = map[string]interface{}{
+			"address": common.MustNewAddress([]byte(key)),
+			"iscore":  m[key],
+		}
+	}
+	return jso
+}
+
+func (d *RewardDetail) ToJSON() map[string]interface{} {
+	jso := make(map[string]interface{})
+	jso["address"] = d.address
+	jso["startBlockHeight"] = d.startHeight
+	jso["endBlockHeight"] = d.endHeight
+	jso["iscore"] = d.IScore()
+	jso["blockProduce"] = d.blockProduce
+	jso["voted"] = d.voted
+	jso["voting"] = d.voting
+	jso["delegating"] = votingDetailToJSON(d.delegating)
+	jso["bonding"] = votingDetailToJSON(d.bonding)
+	return jso
+}
+
+func valueOrZero(v *big.Int) *big.Int {
+	if v == nil {
+		return new(big.Int)
+	}
+	return v
+}
+
+func newRewardDetail(addr module.Address, startHeight, endHeight int64) *RewardDetail {
+	return &RewardDetail{
+		address:      addr,
+		startHeight:  startHeight,
+		endHeight:    endHeight,
+		blockProduce: new(big.Int),
+		voted:        new(big.Int),
+		voting:       new(big.Int),
+		delegating:   make(map[string]*big.Int),
+		bonding:      make(map[string]*big.Int),
+	}
+}
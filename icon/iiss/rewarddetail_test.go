@@ -0,0 +1,137 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package iiss
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/icon/icmodule"
+	"github.com/icon-project/goloop/icon/iiss/icreward"
+	"github.com/icon-project/goloop/icon/iiss/icstage"
+	"github.com/icon-project/goloop/icon/iiss/icstate"
+	"github.com/icon-project/goloop/module"
+)
+
+func TestCalculateReward(t *testing.T) {
+	database := db.NewMapDB()
+	prep1 := common.MustNewAddressFromString("hx1")
+	prep2 := common.MustNewAddressFromString("hx2")
+	voter1 := common.MustNewAddressFromString("hx11")
+	voter2 := common.MustNewAddressFromString("hx12")
+	amount := new(big.Int).Mul(big.NewInt(1000), icmodule.BigIntICX)
+
+	// no global for the first term
+	_, err := CalculateReward(context.Background(), database, icstage.NewSnapshot(database, nil),
+		icreward.NewSnapshot(database, nil), voter1, log.New())
+	assert.True(t, errors.NotFoundError.Equals(err))
+
+	back := icstage.NewState(database)
+	err = back.AddGlobalV2(icmodule.RevisionBTP2, 100, 99,
+		new(big.Int).Mul(big.NewInt(10000), icmodule.BigIntICX),
+		big.NewInt(50), big.NewInt(50), big.NewInt(0), big.NewInt(0), 22, 0)
+	assert.NoError(t, err)
+	_, _, err = back.AddEventDelegation(50, voter2, icstage.VoteList{
+		icstage.NewVote(prep1, amount),
+	})
+	assert.NoError(t, err)
+
+	base := icreward.NewState(database, nil)
+	for _, prep := range []*common.Address{prep1, prep2} {
+		voted := icreward.NewVoted()
+		voted.SetEnable(true)
+		voted.SetDelegated(new(big.Int).Mul(amount, big.NewInt(2)))
+		assert.NoError(t, base.SetVoted(prep, voted))
+	}
+	delegating := icreward.NewDelegating()
+	delegating.Delegations = icstate.Delegations{
+		icstate.NewDelegation(prep1, amount),
+		icstate.NewDelegation(prep2, new(big.Int).Mul(amount, big.NewInt(3))),
+	}
+	assert.NoError(t, base.SetDelegating(voter1, delegating))
+
+	iScoreOf := func(c *Calculator, addr module.Address) *big.Int {
+		is, err := c.Result().NewState().GetIScore(addr)
+		assert.NoError(t, err)
+		if is == nil {
+			return new(big.Int)
+		}
+		return is.Value()
+	}
+
+	// voting reward before the term by P-Rep
+	c, err := CalculateReward(context.Background(), database, back.GetSnapshot(), base.GetSnapshot(), voter1, log.New())
+	assert.NoError(t, err)
+	detail := c.Detail()
+	assert.True(t, detail.Address().Equal(voter1))
+	assert.Zero(t, detail.BlockProduce().Sign())
+	assert.Zero(t, detail.Voted().Sign())
+	assert.Equal(t, 1, detail.Delegating(prep1).Sign())
+	assert.Equal(t, 0, detail.Delegating(prep2).Cmp(
+		new(big.Int).Mul(detail.Delegating(prep1), big.NewInt(3))))
+	assert.Zero(t, detail.Bonding(prep1).Sign())
+	assert.Equal(t, 0, detail.Voting().Cmp(
+		new(big.Int).Add(detail.Delegating(prep1), detail.Delegating(prep2))))
+	assert.Equal(t, 0, detail.IScore().Cmp(iScoreOf(c, voter1)))
+
+	jso := detail.ToJSON()
+	assert.Equal(t, int64(100), jso["startBlockHeight"])
+	assert.Equal(t, int64(199), jso["endBlockHeight"])
+	assert.Len(t, jso["delegating"], 2)
+	assert.Len(t, jso["bonding"], 0)
+
+	// voting reward by the event in the term
+	c, err = CalculateReward(context.Background(), database, back.GetSnapshot(), base.GetSnapshot(), voter2, log.New())
+	assert.NoError(t, err)
+	detail = c.Detail()
+	assert.Equal(t, 1, detail.Voting().Sign())
+	assert.Equal(t, 0, detail.Voting().Cmp(detail.Delegating(prep1)))
+	assert.Equal(t, 0, detail.IScore().Cmp(iScoreOf(c, voter2)))
+
+	// voted reward of the P-Rep
+	c, err = CalculateReward(context.Background(), database, back.GetSnapshot(), base.GetSnapshot(), prep1, log.New())
+	assert.NoError(t, err)
+	detail = c.Detail()
+	assert.Equal(t, 1, detail.Voted().Sign())
+	assert.Zero(t, detail.Voting().Sign())
+	assert.Equal(t, 0, detail.IScore().Cmp(iScoreOf(c, prep1)))
+
+	// without the address
+	c, err = CalculateReward(context.Background(), database, back.GetSnapshot(), base.GetSnapshot(), nil, log.New())
+	assert.NoError(t, err)
+	assert.Nil(t, c.Detail())
+	assert.Equal(t, 1, c.TotalReward().Sign())
+
+	// cancelled calculation
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = CalculateReward(ctx, database, back.GetSnapshot(), base.GetSnapshot(), voter1, log.New())
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// calculation over the deadline
+	ctx, cancel = context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	_, err = CalculateReward(ctx, database, back.GetSnapshot(), base.GetSnapshot(), voter1, log.New())
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
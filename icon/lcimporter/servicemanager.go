@@ -17,6 +17,7 @@
 package lcimporter
 
 import (
+	"context"
 	"fmt"
 	"math/big"
 	"sync"
@@ -254,6 +255,10 @@ func (sm *ServiceManager) GetMinimizeBlockGen(result []byte) bool {
 	return true
 }
 
+func (sm *ServiceManager) GetConsensusTimeouts(result []byte) *module.ConsensusTimeouts {
+	return nil
+}
+
 func (sm *ServiceManager) GetNextBlockVersion(result []byte) int {
 	return module.BlockVersion2
 }
@@ -270,6 +275,18 @@ func (sm *ServiceManager) WaitTransactionResult(id []byte) (<-chan interface{},
 	return nil, errors.ErrInvalidState
 }
 
+func (sm *ServiceManager) GetPendingTransactions(from module.Address, max int) []*module.PendingTransaction {
+	return nil
+}
+
+func (sm *ServiceManager) GetTransactionStatus(id []byte) (module.TransactionStatus, error) {
+	return module.TransactionStatusUnknown, nil
+}
+
+func (sm *ServiceManager) WatchTxPool(cb func(ev *module.TxPoolEvent)) func() {
+	return func() {}
+}
+
 func (sm *ServiceManager) ExportResult(result []byte, vh []byte, dst db.Database) error {
 	return errors.ErrInvalidState
 }
@@ -282,10 +299,18 @@ func (sm *ServiceManager) ExecuteTransaction(result []byte, vh []byte, js []byte
 	return nil, errors.ErrInvalidState
 }
 
+func (sm *ServiceManager) SimulateTransaction(result []byte, vh []byte, js []byte, bi module.BlockInfo, cb module.TraceCallback) (module.Receipt, error) {
+	return nil, errors.ErrInvalidState
+}
+
 func (sm *ServiceManager) AddSyncRequest(id db.BucketID, key []byte) error {
 	return errors.ErrInvalidState
 }
 
+func (sm *ServiceManager) GetRewardDetail(ctx context.Context, result []byte, addr module.Address) (interface{}, error) {
+	return nil, errors.ErrInvalidState
+}
+
 func (sm *ServiceManager) BTPSectionFromResult(result []byte) (module.BTPSection, error) {
 	return btp.ZeroBTPSection, nil
 }
@@ -0,0 +1,90 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lightclient
+
+import (
+	"github.com/icon-project/goloop/block"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/module"
+)
+
+// Header is a block header returned by icx_getBlockHeaderByHeight.
+type Header struct {
+	format block.V2HeaderFormat
+	bytes  []byte
+	id     []byte
+}
+
+func NewHeaderFromBytes(bs []byte) (*Header, error) {
+	h := new(Header)
+	if _, err := codec.BC.UnmarshalFromBytes(bs, &h.format); err != nil {
+		return nil, errors.IllegalArgumentError.Wrap(err, "InvalidHeader")
+	}
+	if h.format.Version != module.BlockVersion2 {
+		return nil, errors.UnsupportedError.Errorf(
+			"UnsupportedBlockVersion(version=%d)", h.format.Version)
+	}
+	h.bytes = codec.BC.MustMarshalToBytes(&h.format)
+	h.id = crypto.SHA3Sum256(h.bytes)
+	return h, nil
+}
+
+func (h *Header) ID() []byte {
+	return h.id
+}
+
+func (h *Header) Height() int64 {
+	return h.format.Height
+}
+
+func (h *Header) Timestamp() int64 {
+	return h.format.Timestamp
+}
+
+func (h *Header) PrevID() []byte {
+	return h.format.PrevID
+}
+
+func (h *Header) NextValidatorsHash() []byte {
+	return h.format.NextValidatorsHash
+}
+
+// Result returns the result of the transactions in the previous block.
+func (h *Header) Result() []byte {
+	return h.format.Result
+}
+
+func (h *Header) Bytes() []byte {
+	return h.bytes
+}
+
+// votedBlock provides the block information used by commit votes.
+// Only ID and Height are available.
+type votedBlock struct {
+	module.BlockData
+	header *Header
+}
+
+func (b *votedBlock) ID() []byte {
+	return b.header.ID()
+}
+
+func (b *votedBlock) Height() int64 {
+	return b.header.Height()
+}
@@ -0,0 +1,305 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package lightclient verifies block headers, receipts and events
+// returned by an untrusted node starting from a trusted block header.
+//
+// Headers are followed one by one. A header is accepted if it's linked to
+// the previous one with PrevID and it's voted by more than 2/3 of the
+// next validators of the previous one. Receipts and events are verified
+// with the merkle proofs against the result in the accepted header.
+package lightclient
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/consensus"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service"
+	"github.com/icon-project/goloop/service/state"
+	"github.com/icon-project/goloop/service/txresult"
+)
+
+type LightClient struct {
+	lock sync.Mutex
+	src  Source
+
+	header         *Header
+	validators     module.ValidatorList
+	validatorBytes []byte
+}
+
+// New returns a light client trusting the header and the validators.
+// validators is the serialized next validators of the header.
+func New(src Source, header []byte, validators []byte) (*LightClient, error) {
+	h, err := NewHeaderFromBytes(header)
+	if err != nil {
+		return nil, err
+	}
+	vl, err := validatorListFromBytes(h.NextValidatorsHash(), validators)
+	if err != nil {
+		return nil, err
+	}
+	return &LightClient{
+		src:            src,
+		header:         h,
+		validators:     vl,
+		validatorBytes: validators,
+	}, nil
+}
+
+// NewWithTrustedID returns a light client trusting the header at the height
+// if its ID is same as the id.
+func NewWithTrustedID(src Source, height int64, id []byte) (*LightClient, error) {
+	h, err := getHeader(src, height)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(h.ID(), id) {
+		return nil, errors.InvalidStateError.Errorf(
+			"InvalidTrustedHeader(height=%d,exp=%#x,real=%#x)",
+			height, id, h.ID())
+	}
+	vb, err := getValidators(src, h.NextValidatorsHash())
+	if err != nil {
+		return nil, err
+	}
+	return New(src, h.Bytes(), vb)
+}
+
+func getHeader(src Source, height int64) (*Header, error) {
+	bs, err := src.GetHeader(height)
+	if err != nil {
+		return nil, err
+	}
+	h, err := NewHeaderFromBytes(bs)
+	if err != nil {
+		return nil, err
+	}
+	if h.Height() != height {
+		return nil, errors.InvalidStateError.Errorf(
+			"InvalidHeight(exp=%d,real=%d)", height, h.Height())
+	}
+	return h, nil
+}
+
+func getValidators(src Source, hash []byte) ([]byte, error) {
+	if len(hash) == 0 {
+		return nil, nil
+	}
+	return src.GetData(hash)
+}
+
+func validatorListFromBytes(hash []byte, bs []byte) (module.ValidatorList, error) {
+	if len(hash) == 0 {
+		if len(bs) != 0 {
+			return nil, errors.InvalidStateError.New("UnexpectedValidators")
+		}
+		return nil, nil
+	}
+	if !bytes.Equal(crypto.SHA3Sum256(bs), hash) {
+		return nil, errors.InvalidStateError.Errorf(
+			"InvalidValidators(hash=%#x)", hash)
+	}
+	dbase := db.NewMapDB()
+	bk, err := dbase.GetBucket(db.BytesByHash)
+	if err != nil {
+		return nil, err
+	}
+	if err := bk.Set(hash, bs); err != nil {
+		return nil, err
+	}
+	return state.ValidatorSnapshotFromHash(dbase, hash)
+}
+
+// Header returns the last verified header.
+func (lc *LightClient) Header() *Header {
+	lc.lock.Lock()
+	defer lc.lock.Unlock()
+	return lc.header
+}
+
+// Validators returns the serialized next validators of the last verified
+// header.
+func (lc *LightClient) Validators() []byte {
+	lc.lock.Lock()
+	defer lc.lock.Unlock()
+	return lc.validatorBytes
+}
+
+func (lc *LightClient) verifyNext() error {
+	height := lc.header.Height() + 1
+	h, err := getHeader(lc.src, height)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(h.PrevID(), lc.header.ID()) {
+		return errors.InvalidStateError.Errorf(
+			"InvalidPrevID(height=%d,exp=%#x,real=%#x)",
+			height, lc.header.ID(), h.PrevID())
+	}
+	vb, err := lc.src.GetVotes(height)
+	if err != nil {
+		return err
+	}
+	votes := consensus.NewCommitVoteSetFromBytes(vb)
+	if votes == nil {
+		return errors.InvalidStateError.Errorf("InvalidVotes(height=%d)", height)
+	}
+	if _, err := votes.VerifyBlock(&votedBlock{header: h}, lc.validators); err != nil {
+		return errors.InvalidStateError.Wrapf(err,
+			"InvalidVotes(height=%d)", height)
+	}
+
+	vl, vlb := lc.validators, lc.validatorBytes
+	if !bytes.Equal(h.NextValidatorsHash(), lc.header.NextValidatorsHash()) {
+		if vlb, err = getValidators(lc.src, h.NextValidatorsHash()); err != nil {
+			return err
+		}
+		if vl, err = validatorListFromBytes(h.NextValidatorsHash(), vlb); err != nil {
+			return err
+		}
+	}
+	lc.header, lc.validators, lc.validatorBytes = h, vl, vlb
+	return nil
+}
+
+// Sync verifies headers up to the height. If the height is negative, then
+// it follows the last block of the source.
+func (lc *LightClient) Sync(height int64) error {
+	lc.lock.Lock()
+	defer lc.lock.Unlock()
+	return lc.syncInLock(height)
+}
+
+func (lc *LightClient) syncInLock(height int64) error {
+	if height < 0 {
+		last, err := lc.src.GetLastHeight()
+		if err != nil {
+			return err
+		}
+		height = last
+	}
+	for lc.header.Height() < height {
+		if err := lc.verifyNext(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetHeader returns the verified header at the height. Headers after the
+// last verified one are verified with votes, and headers before it are
+// verified with PrevID.
+func (lc *LightClient) GetHeader(height int64) (*Header, error) {
+	lc.lock.Lock()
+	defer lc.lock.Unlock()
+	return lc.getHeaderInLock(height)
+}
+
+func (lc *LightClient) getHeaderInLock(height int64) (*Header, error) {
+	if height < 0 {
+		return nil, errors.IllegalArgumentError.Errorf(
+			"InvalidHeight(height=%d)", height)
+	}
+	if err := lc.syncInLock(height); err != nil {
+		return nil, err
+	}
+	h := lc.header
+	for h.Height() > height {
+		prev, err := getHeader(lc.src, h.Height()-1)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(prev.ID(), h.PrevID()) {
+			return nil, errors.InvalidStateError.Errorf(
+				"InvalidHeader(height=%d,exp=%#x,real=%#x)",
+				prev.Height(), h.PrevID(), prev.ID())
+		}
+		h = prev
+	}
+	return h, nil
+}
+
+// resultHeader returns the verified header containing the result of
+// the transactions in the block at the height.
+func (lc *LightClient) resultHeader(height int64) (*Header, []byte, error) {
+	h, err := lc.getHeaderInLock(height + 1)
+	if err != nil {
+		return nil, nil, err
+	}
+	rh, err := service.NormalReceiptHashFromResult(h.Result())
+	if err != nil {
+		return nil, nil, errors.InvalidStateError.Wrapf(err,
+			"InvalidResult(height=%d)", h.Height())
+	}
+	return h, rh, nil
+}
+
+// GetReceipt returns the verified receipt of the transaction at the index
+// in the block at the height. It needs the header of the next block.
+func (lc *LightClient) GetReceipt(height int64, idx int) (txresult.Receipt, error) {
+	lc.lock.Lock()
+	defer lc.lock.Unlock()
+
+	h, rh, err := lc.resultHeader(height)
+	if err != nil {
+		return nil, err
+	}
+	proof, err := lc.src.GetProofForResult(h.ID(), idx)
+	if err != nil {
+		return nil, err
+	}
+	return txresult.ProveReceipt(rh, idx, proof)
+}
+
+// GetEvents returns the verified receipt and events of the transaction at
+// the index in the block at the height.
+func (lc *LightClient) GetEvents(height int64, idx int, events []int) (txresult.Receipt, []module.EventLog, error) {
+	lc.lock.Lock()
+	defer lc.lock.Unlock()
+
+	if len(events) == 0 {
+		return nil, nil, errors.IllegalArgumentError.New("NoEvents")
+	}
+	h, rh, err := lc.resultHeader(height)
+	if err != nil {
+		return nil, nil, err
+	}
+	proofs, err := lc.src.GetProofForEvents(h.ID(), idx, events)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(proofs) != len(events)+1 {
+		return nil, nil, errors.InvalidStateError.Errorf(
+			"InvalidProofs(exp=%d,real=%d)", len(events)+1, len(proofs))
+	}
+	rct, err := txresult.ProveReceipt(rh, idx, proofs[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	evs := make([]module.EventLog, len(events))
+	for i, e := range events {
+		if evs[i], err = txresult.ProveEvent(rct, e, proofs[i+1]); err != nil {
+			return nil, nil, err
+		}
+	}
+	return rct, evs, nil
+}
@@ -0,0 +1,281 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lightclient
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/block"
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/wallet"
+	"github.com/icon-project/goloop/consensus"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/state"
+	"github.com/icon-project/goloop/service/txresult"
+)
+
+type testSource struct {
+	headers [][]byte
+	votes   [][]byte
+	data    map[string][]byte
+	rls     map[string]module.ReceiptList
+}
+
+func (s *testSource) GetLastHeight() (int64, error) {
+	return int64(len(s.headers) - 1), nil
+}
+
+func (s *testSource) GetHeader(height int64) ([]byte, error) {
+	if height >= int64(len(s.headers)) {
+		return nil, errors.ErrNotFound
+	}
+	return s.headers[height], nil
+}
+
+func (s *testSource) GetVotes(height int64) ([]byte, error) {
+	if height >= int64(len(s.votes)) {
+		return nil, errors.ErrNotFound
+	}
+	return s.votes[height], nil
+}
+
+func (s *testSource) GetData(hash []byte) ([]byte, error) {
+	if bs, ok := s.data[string(hash)]; ok {
+		return bs, nil
+	}
+	return nil, errors.ErrNotFound
+}
+
+func (s *testSource) GetProofForResult(id []byte, idx int) ([][]byte, error) {
+	return s.rls[string(id)].GetProof(idx)
+}
+
+func (s *testSource) GetProofForEvents(id []byte, idx int, events []int) ([][][]byte, error) {
+	rl := s.rls[string(id)]
+	proof, err := rl.GetProof(idx)
+	if err != nil {
+		return nil, err
+	}
+	proofs := [][][]byte{proof}
+	rct, _ := rl.Get(idx)
+	for _, e := range events {
+		p, err := rct.GetProofOfEvent(e)
+		if err != nil {
+			return nil, err
+		}
+		proofs = append(proofs, p)
+	}
+	return proofs, nil
+}
+
+type testChain struct {
+	*testSource
+	wallets []module.Wallet
+	vbs     []byte
+	vhash   []byte
+}
+
+func (c *testChain) setValidators(t *testing.T, wallets []module.Wallet) {
+	vl := make([]module.Validator, len(wallets))
+	for i, w := range wallets {
+		v, err := state.ValidatorFromAddress(w.Address())
+		assert.NoError(t, err)
+		vl[i] = v
+	}
+	vss, err := state.ValidatorSnapshotFromSlice(db.NewMapDB(), vl)
+	assert.NoError(t, err)
+	c.wallets = wallets
+	c.vbs = vss.Bytes()
+	c.vhash = vss.Hash()
+	c.data[string(c.vhash)] = c.vbs
+}
+
+func (c *testChain) addBlock(t *testing.T, rl module.ReceiptList) {
+	height := int64(len(c.headers))
+	var prevID []byte
+	if height > 0 {
+		prev, err := NewHeaderFromBytes(c.headers[height-1])
+		assert.NoError(t, err)
+		prevID = prev.ID()
+	}
+	var rh []byte
+	if rl != nil {
+		rh = rl.Hash()
+	}
+	hf := &block.V2HeaderFormat{
+		Version:            module.BlockVersion2,
+		Height:             height,
+		Timestamp:          height * 1000,
+		PrevID:             prevID,
+		NextValidatorsHash: c.vhash,
+		Result:             codec.BC.MustMarshalToBytes([][]byte{nil, nil, rh}),
+	}
+	hb := codec.BC.MustMarshalToBytes(hf)
+	id := crypto.SHA3Sum256(hb)
+	if rl != nil {
+		c.rls[string(id)] = rl
+	}
+	c.headers = append(c.headers, hb)
+}
+
+// vote adds votes for the last block by the wallets.
+func (c *testChain) vote(t *testing.T, wallets []module.Wallet) {
+	height := int64(len(c.headers) - 1)
+	h, err := NewHeaderFromBytes(c.headers[height])
+	assert.NoError(t, err)
+	psid := &consensus.PartSetID{Count: 1, Hash: crypto.SHA3Sum256(h.Bytes())}
+	var msgs []*consensus.VoteMessage
+	for _, w := range wallets {
+		msgs = append(msgs, consensus.NewVoteMessage(
+			w, consensus.VoteTypePrecommit, height, 0, h.ID(), psid,
+			h.Timestamp(), nil, nil, 0,
+		))
+	}
+	c.votes = append(c.votes, consensus.NewCommitVoteList(nil, msgs...).Bytes())
+}
+
+func newTestChain(t *testing.T, n int) *testChain {
+	c := &testChain{
+		testSource: &testSource{
+			data: map[string][]byte{},
+			rls:  map[string]module.ReceiptList{},
+		},
+	}
+	wallets := make([]module.Wallet, n)
+	for i := range wallets {
+		wallets[i] = wallet.New()
+	}
+	c.setValidators(t, wallets)
+	c.addBlock(t, nil)
+	c.vote(t, nil)
+	return c
+}
+
+func newReceiptList(t *testing.T) module.ReceiptList {
+	mdb := db.NewMapDB()
+	addr := common.MustNewAddressFromString("cx0000000000000000000000000000000000000001")
+	var rcts []txresult.Receipt
+	for i := 0; i < 2; i++ {
+		r := txresult.NewReceipt(mdb, module.UseMPTOnEvents, addr)
+		r.AddLog(addr, [][]byte{[]byte("Event(int)"), {byte(i)}}, nil)
+		r.AddLog(addr, [][]byte{[]byte("Event(int)"), {byte(i + 1)}}, nil)
+		r.SetResult(module.StatusSuccess, big.NewInt(100), big.NewInt(10), nil)
+		rcts = append(rcts, r)
+	}
+	rl := txresult.NewReceiptListFromSlice(mdb, rcts)
+	assert.NoError(t, rl.Flush())
+	return rl
+}
+
+func TestLightClient_Basic(t *testing.T) {
+	c := newTestChain(t, 4)
+	genesis, _ := NewHeaderFromBytes(c.headers[0])
+
+	// height 1 with transactions, voted by 3 of 4
+	c.addBlock(t, nil)
+	c.vote(t, c.wallets[:3])
+
+	// height 2 with receipts for height 1, and new validators
+	rl := newReceiptList(t)
+	oldWallets := c.wallets
+	c.setValidators(t, []module.Wallet{wallet.New(), wallet.New()})
+	c.addBlock(t, rl)
+	c.vote(t, oldWallets)
+
+	// height 3 voted by new validators
+	c.addBlock(t, nil)
+	c.vote(t, c.wallets)
+
+	lc, err := NewWithTrustedID(c, 0, genesis.ID())
+	assert.NoError(t, err)
+	assert.NoError(t, lc.Sync(-1))
+	assert.EqualValues(t, 3, lc.Header().Height())
+	assert.Equal(t, c.vbs, lc.Validators())
+
+	h, err := lc.GetHeader(1)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, h.Height())
+
+	rct, err := lc.GetReceipt(1, 1)
+	assert.NoError(t, err)
+	r1, _ := rl.Get(1)
+	assert.Equal(t, r1.Bytes(), rct.Bytes())
+
+	_, evs, err := lc.GetEvents(1, 1, []int{1})
+	assert.NoError(t, err)
+	assert.Len(t, evs, 1)
+	assert.Equal(t, [][]byte{[]byte("Event(int)"), {2}}, evs[0].Indexed())
+
+	_, err = lc.GetReceipt(1, 2)
+	assert.Error(t, err)
+
+	_, err = NewWithTrustedID(c, 0, h.ID())
+	assert.Error(t, err)
+}
+
+func TestLightClient_InvalidVotes(t *testing.T) {
+	c := newTestChain(t, 4)
+	genesis, _ := NewHeaderFromBytes(c.headers[0])
+
+	// not enough votes
+	c.addBlock(t, nil)
+	c.vote(t, c.wallets[:2])
+
+	lc, err := NewWithTrustedID(c, 0, genesis.ID())
+	assert.NoError(t, err)
+	assert.Error(t, lc.Sync(1))
+	assert.EqualValues(t, 0, lc.Header().Height())
+
+	// votes by unknown validators
+	c.votes = c.votes[:1]
+	c.vote(t, []module.Wallet{wallet.New(), wallet.New(), wallet.New()})
+	assert.Error(t, lc.Sync(1))
+
+	// valid votes
+	c.votes = c.votes[:1]
+	c.vote(t, c.wallets)
+	assert.NoError(t, lc.Sync(1))
+	assert.EqualValues(t, 1, lc.Header().Height())
+}
+
+func TestLightClient_InvalidHeader(t *testing.T) {
+	c := newTestChain(t, 1)
+	genesis, _ := NewHeaderFromBytes(c.headers[0])
+
+	c.addBlock(t, nil)
+	c.vote(t, c.wallets)
+	c.addBlock(t, nil)
+	c.vote(t, c.wallets)
+
+	lc, err := NewWithTrustedID(c, 0, genesis.ID())
+	assert.NoError(t, err)
+	assert.NoError(t, lc.Sync(2))
+
+	// replaced header is detected with PrevID
+	fake := newTestChain(t, 1)
+	fake.addBlock(t, nil)
+	c.headers[1] = fake.headers[1]
+	_, err = lc.GetHeader(1)
+	assert.Error(t, err)
+}
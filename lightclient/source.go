@@ -0,0 +1,91 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lightclient
+
+import (
+	"github.com/icon-project/goloop/client"
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/intconv"
+	"github.com/icon-project/goloop/server/jsonrpc"
+	v3 "github.com/icon-project/goloop/server/v3"
+)
+
+// Source provides the data to be verified by the light client.
+// Nothing returned by the source is trusted.
+type Source interface {
+	GetLastHeight() (int64, error)
+	GetHeader(height int64) ([]byte, error)
+	GetVotes(height int64) ([]byte, error)
+	GetData(hash []byte) ([]byte, error)
+	GetProofForResult(id []byte, idx int) ([][]byte, error)
+	GetProofForEvents(id []byte, idx int, events []int) ([][][]byte, error)
+}
+
+type clientSource struct {
+	c *client.ClientV3
+}
+
+func (s *clientSource) GetLastHeight() (int64, error) {
+	blk, err := s.c.GetLastBlock()
+	if err != nil {
+		return 0, err
+	}
+	return blk.Height, nil
+}
+
+func heightParam(height int64) *v3.BlockHeightParam {
+	return &v3.BlockHeightParam{
+		Height: jsonrpc.HexInt(intconv.FormatInt(height)),
+	}
+}
+
+func (s *clientSource) GetHeader(height int64) ([]byte, error) {
+	return s.c.GetBlockHeaderByHeight(heightParam(height))
+}
+
+func (s *clientSource) GetVotes(height int64) ([]byte, error) {
+	return s.c.GetVotesByHeight(heightParam(height))
+}
+
+func (s *clientSource) GetData(hash []byte) ([]byte, error) {
+	return s.c.GetDataByHash(&v3.DataHashParam{
+		Hash: jsonrpc.HexBytes(common.HexBytes(hash).String()),
+	})
+}
+
+func (s *clientSource) GetProofForResult(id []byte, idx int) ([][]byte, error) {
+	return s.c.GetProofForResult(&v3.ProofResultParam{
+		BlockHash: jsonrpc.HexBytes(common.HexBytes(id).String()),
+		Index:     jsonrpc.HexInt(intconv.FormatInt(int64(idx))),
+	})
+}
+
+func (s *clientSource) GetProofForEvents(id []byte, idx int, events []int) ([][][]byte, error) {
+	param := &v3.ProofEventsParam{
+		BlockHash: jsonrpc.HexBytes(common.HexBytes(id).String()),
+		Index:     jsonrpc.HexInt(intconv.FormatInt(int64(idx))),
+	}
+	for _, e := range events {
+		param.Events = append(param.Events, jsonrpc.HexInt(intconv.FormatInt(int64(e))))
+	}
+	return s.c.GetProofForEvents(param)
+}
+
+// NewClientSource returns the source using JSON-RPC APIs of the node.
+func NewClientSource(c *client.ClientV3) Source {
+	return &clientSource{c}
+}
@@ -33,6 +33,14 @@ type BTPProof interface {
 	ProofPartAt(i int) BTPProofPart
 }
 
+// BTPAggregatableProof is a BTPProof which can be aggregated into a compact
+// proof. The aggregated proof is verified by the same proof context, but its
+// proof parts can't be restored.
+type BTPAggregatableProof interface {
+	BTPProof
+	Aggregate() (BTPProof, error)
+}
+
 type WalletProvider interface {
 	// WalletFor returns public key for dsa.
 	WalletFor(dsa string) BaseWallet
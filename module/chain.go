@@ -38,6 +38,9 @@ type Chain interface {
 	ChildrenLimit() int
 	NephewsLimit() int
 	ValidateTxOnSend() bool
+	AddressIndex() AddressIndex
+	MaxLogsRange() int64
+	MaxLogsResults() int
 	Genesis() []byte
 	GenesisStorage() GenesisStorage
 	CommitVoteSetDecoder() CommitVoteSetDecoder
@@ -54,7 +57,7 @@ type Chain interface {
 	Stop() error
 	Import(src string, height int64) error
 	Prune(gs string, dbt string, height int64) error
-	Backup(file string, extra []string) error
+	Backup(file string, extra []string, base string) error
 	RunTask(task string, params json.RawMessage) error
 	Term() error
 	State() (string, int64, error)
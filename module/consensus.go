@@ -1,11 +1,28 @@
 package module
 
+import "time"
+
 type ConsensusStatus struct {
 	Height   int64
 	Round    int32
 	Proposer bool
 }
 
+// ConsensusTimeouts holds timeouts of consensus governed by the chain.
+// Zero value of a field means the default of the consensus. Round based
+// timeouts grow by Increase percent for each round until they reach Max.
+// If Adaptive is set, the timeout for proposal is adjusted with measured
+// latency of proposals.
+type ConsensusTimeouts struct {
+	Propose   time.Duration
+	Prevote   time.Duration
+	Precommit time.Duration
+	NewRound  time.Duration
+	Max       time.Duration
+	Increase  int
+	Adaptive  bool
+}
+
 const (
 	FlagNextProofContext = 0x1
 	FlagBTPBlockHeader   = 0x2
@@ -36,8 +36,19 @@ type ProtocolHandler interface {
 	Multicast(pi ProtocolInfo, b []byte, role Role) error
 	Unicast(pi ProtocolInfo, b []byte, id PeerID) error
 	GetPeers() []PeerID
+
+	// ReportPeer reports misbehavior of the peer with the penalty. The peer
+	// is banned for a while if accumulated penalties exceed the limit.
+	ReportPeer(id PeerID, penalty int, reason string)
 }
 
+// Penalties for ProtocolHandler.ReportPeer
+const (
+	PeerPenaltyMinor    = 10  // malformed or unexpected message
+	PeerPenaltyMajor    = 40  // invalid data
+	PeerPenaltyCritical = 100 // ban immediately
+)
+
 type BroadcastType byte
 type Role string
 
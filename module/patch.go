@@ -2,6 +2,7 @@ package module
 
 const (
 	PatchTypeSkipTransaction = "skip_txs"
+	PatchTypeDoubleSign      = "double_sign"
 )
 
 type Patch interface {
@@ -17,4 +18,14 @@ type SkipTransactionPatch interface {
 	Verify(vl ValidatorList, roundLimit int64, nid int) error
 }
 
+type DoubleSignPatch interface {
+	Patch
+	Height() int64   // height of the conflicting votes
+	Signer() Address // address of the validator signed the votes
+
+	// Verify check the votes are conflicting and signed by the validator
+	// in the list.
+	Verify(vl ValidatorList) error
+}
+
 type PatchDecoder func(t string, bs []byte) (Patch, error)
@@ -2,6 +2,7 @@ package module
 
 import (
 	"container/list"
+	"context"
 	"fmt"
 	"math/big"
 
@@ -297,6 +298,70 @@ type TransitionManager interface {
 	WaitForTransaction(parent Transition, bi BlockInfo, cb func()) bool
 }
 
+// PendingTransaction is a transaction waiting in the transaction pool.
+type PendingTransaction struct {
+	Transaction Transaction
+	// Received is the time(in nanoseconds) when the transaction is submitted
+	// to the node directly. It's zero for the transaction from the network.
+	Received int64
+	// Error is the reason of the failure on the last proposal.
+	// The transaction stays in the pool for some errors (ex. NotEnoughBalance).
+	Error error
+}
+
+type TransactionStatus int
+
+const (
+	TransactionStatusUnknown TransactionStatus = iota
+	TransactionStatusPending
+	TransactionStatusDropped
+	TransactionStatusCommitted
+)
+
+func (s TransactionStatus) String() string {
+	switch s {
+	case TransactionStatusPending:
+		return "pending"
+	case TransactionStatusDropped:
+		return "dropped"
+	case TransactionStatusCommitted:
+		return "committed"
+	default:
+		return "unknown"
+	}
+}
+
+type TxPoolEventType int
+
+const (
+	TxPoolEventAdd TxPoolEventType = iota
+	TxPoolEventDrop
+)
+
+func (t TxPoolEventType) String() string {
+	switch t {
+	case TxPoolEventAdd:
+		return "add"
+	case TxPoolEventDrop:
+		return "drop"
+	default:
+		return fmt.Sprintf("TxPoolEventType(%d)", int(t))
+	}
+}
+
+// TxPoolEvent is the event on addition or drop of a transaction in the
+// transaction pool.
+type TxPoolEvent struct {
+	Type TxPoolEventType
+	ID   []byte
+	// From is the sender of the transaction.
+	From Address
+	// Transaction is the added transaction. It's nil for drop events.
+	Transaction Transaction
+	// Error is the reason of the drop.
+	Error error
+}
+
 type ServiceManager interface {
 	TransitionManager
 
@@ -362,6 +427,10 @@ type ServiceManager interface {
 	// GetMinimizeEmptyBlock returns minimize empty block generation flag
 	GetMinimizeBlockGen(result []byte) bool
 
+	// GetConsensusTimeouts returns consensus timeouts. It returns nil if
+	// they are not configured.
+	GetConsensusTimeouts(result []byte) *ConsensusTimeouts
+
 	// GetNextBlockVersion returns version of next block
 	GetNextBlockVersion(result []byte) int
 
@@ -389,6 +458,21 @@ type ServiceManager interface {
 	// WaitTransactionResult return channel for result.
 	WaitTransactionResult(id []byte) (<-chan interface{}, error)
 
+	// GetPendingTransactions returns transactions in the pool. If from isn't
+	// nil, it returns transactions only from the address. It returns at
+	// most max transactions if max is positive.
+	GetPendingTransactions(from Address, max int) []*PendingTransaction
+
+	// GetTransactionStatus returns the status of the transaction known to
+	// the pool. For pending and dropped transactions, it also returns the
+	// reason of the last failure.
+	GetTransactionStatus(id []byte) (TransactionStatus, error)
+
+	// WatchTxPool registers the callback for the events of the transaction
+	// pool. The callback is called while the pool is locked, so it should
+	// return immediately. It returns the function to stop watching.
+	WatchTxPool(cb func(ev *TxPoolEvent)) (cancel func())
+
 	// ExportResult exports all related entries related with the result
 	// should be exported to the database
 	ExportResult(result []byte, vh []byte, dst db.Database) error
@@ -402,6 +486,47 @@ type ServiceManager interface {
 	// It ignores supplied step limit.
 	ExecuteTransaction(result []byte, vh []byte, js []byte, bi BlockInfo) (Receipt, error)
 
+	// SimulateTransaction executes the transaction on the specified state
+	// with the supplied step limit, and returns the receipt of it.
+	// The signature of the transaction isn't verified. Balance changes
+	// are reported to the callback.
+	SimulateTransaction(result []byte, vh []byte, js []byte, bi BlockInfo, cb TraceCallback) (Receipt, error)
+
 	// AddSyncRequest add sync request for specified data.
 	AddSyncRequest(id db.BucketID, key []byte) error
+
+	// GetRewardDetail calculates the reward of the previous term for the
+	// result again, and returns the breakdown of the reward for the address.
+	// The calculation stops when ctx is done. It returns
+	// errors.UnsupportedError if the platform has no reward calculation.
+	GetRewardDetail(ctx context.Context, result []byte, addr Address) (interface{}, error)
+}
+
+// AddressIndexEntry is an entry of transactions related to an address.
+type AddressIndexEntry struct {
+	Height int64
+	TxHash []byte
+}
+
+// AddressIndexRange is the range of indexed block heights.
+type AddressIndexRange struct {
+	First int64
+	Last  int64
+}
+
+// AddressIndex is the index of transactions by related addresses.
+type AddressIndex interface {
+	// AddBlock indexes patch and normal transactions of the block at the
+	// height with their receipts.
+	AddBlock(height int64, ptl TransactionList, prl ReceiptList, ntl TransactionList, nrl ReceiptList) error
+
+	// Range returns the range of indexed heights. It returns nil if
+	// nothing is indexed.
+	Range() (*AddressIndexRange, error)
+
+	// Get returns entries of the address in the range of heights from the
+	// latest one. It returns entries before the cursor if cursor is not
+	// negative, and the cursor for the next query. The returned cursor is
+	// negative if there is no more entries.
+	Get(addr Address, from, to int64, cursor int64, limit int) ([]*AddressIndexEntry, int64, error)
 }
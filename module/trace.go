@@ -81,3 +81,38 @@ type TraceCallback interface {
 	OnFrameExit(success bool) error
 	OnBalanceChange(opType OpType, from, to Address, amount *big.Int) error
 }
+
+// TraceCall is the information of the call executed in the frame.
+type TraceCall struct {
+	Type   string
+	From   Address
+	To     Address
+	Value  *big.Int
+	Method string
+	Params interface{}
+}
+
+// FrameTraceCallback is implemented by the callback which needs the details
+// of the frames. It's used only with TraceModeInvoke.
+type FrameTraceCallback interface {
+	OnFrameStart(call *TraceCall) error
+	OnFrameResult(status error, steps *big.Int, result interface{}) error
+}
+
+// StorageTraceCallback is implemented by the callback which needs the
+// changes of the storage of the contracts. It's used only with TraceModeInvoke.
+type StorageTraceCallback interface {
+	OnStorageChange(score Address, key, before, after []byte) error
+}
+
+// StepTraceCallback is implemented by the callback which needs the steps
+// used in the frames. It's used only with TraceModeInvoke.
+type StepTraceCallback interface {
+	// OnStepApply is called with the steps deducted in the current frame.
+	// stepType is empty if it's not deducted for the specific type.
+	OnStepApply(stepType string, steps *big.Int) error
+
+	// OnStepEstimate is called with the steps which are expected to be
+	// included in the steps reported by the execution engine.
+	OnStepEstimate(stepType string, steps *big.Int) error
+}
@@ -17,16 +17,18 @@ var (
 
 type ChannelNegotiator struct {
 	*peerHandler
-	netAddress NetAddress
-	m          map[string]*ProtocolInfos
-	mtx        sync.RWMutex
+	netAddress   NetAddress
+	m            map[string]*ProtocolInfos
+	mtx          sync.RWMutex
+	compressions []string
 }
 
 func newChannelNegotiator(netAddress NetAddress, l log.Logger) *ChannelNegotiator {
 	cn := &ChannelNegotiator{
-		netAddress:  netAddress,
-		peerHandler: newPeerHandler(l.WithFields(log.Fields{LoggerFieldKeySubModule: "negotiator"})),
-		m:           make(map[string]*ProtocolInfos),
+		netAddress:   netAddress,
+		peerHandler:  newPeerHandler(l.WithFields(log.Fields{LoggerFieldKeySubModule: "negotiator"})),
+		m:            make(map[string]*ProtocolInfos),
+		compressions: supportedCompressions,
 	}
 	return cn
 }
@@ -62,16 +64,21 @@ func (cn *ChannelNegotiator) onPacket(pkt *Packet, p *Peer) {
 	}
 }
 
+// JoinRequest and JoinResponse have Compressions for packet compression
+// supported by the peer. It's empty for legacy peers, then packets are sent
+// without compression.
 type JoinRequest struct {
-	Channel   string
-	Addr      NetAddress
-	Protocols []module.ProtocolInfo
+	Channel      string
+	Addr         NetAddress
+	Protocols    []module.ProtocolInfo
+	Compressions []string
 }
 
 type JoinResponse struct {
-	Channel   string
-	Addr      NetAddress
-	Protocols []module.ProtocolInfo
+	Channel      string
+	Addr         NetAddress
+	Protocols    []module.ProtocolInfo
+	Compressions []string
 }
 
 var defaultProtocols = []module.ProtocolInfo{
@@ -143,6 +150,12 @@ func (cn *ChannelNegotiator) resolveProtocols(p *Peer, channel string, protocols
 	return nil
 }
 
+func (cn *ChannelNegotiator) resolveCompression(p *Peer, compressions []string) {
+	c := resolveCompression(cn.compressions, compressions)
+	p.setCompression(c)
+	cn.logger.Debugln("resolveCompression", c, p)
+}
+
 func (cn *ChannelNegotiator) sendJoinRequest(p *Peer) {
 	pis := cn.ProtocolInfos(p.Channel())
 	if pis == nil {
@@ -151,7 +164,12 @@ func (cn *ChannelNegotiator) sendJoinRequest(p *Peer) {
 		p.CloseByError(err)
 		return
 	}
-	m := &JoinRequest{Channel: p.Channel(), Addr: cn.netAddress, Protocols: pis.Array()}
+	m := &JoinRequest{
+		Channel:      p.Channel(),
+		Addr:         cn.netAddress,
+		Protocols:    pis.Array(),
+		Compressions: cn.compressions,
+	}
 	cn.sendMessage(p2pProtoChan, p2pProtoChanJoinReq, m, p)
 	cn.logger.Traceln("sendJoinRequest", m, p)
 }
@@ -174,8 +192,14 @@ func (cn *ChannelNegotiator) handleJoinRequest(pkt *Packet, p *Peer) {
 		return
 	}
 	p.setNetAddress(rm.Addr)
+	cn.resolveCompression(p, rm.Compressions)
 
-	m := &JoinResponse{Channel: p.Channel(), Addr: cn.netAddress, Protocols: p.ProtocolInfos().Array()}
+	m := &JoinResponse{
+		Channel:      p.Channel(),
+		Addr:         cn.netAddress,
+		Protocols:    p.ProtocolInfos().Array(),
+		Compressions: cn.compressions,
+	}
 	cn.sendMessage(p2pProtoChan, p2pProtoChanJoinResp, m, p)
 
 	cn.nextOnPeer(p)
@@ -199,6 +223,7 @@ func (cn *ChannelNegotiator) handleJoinResponse(pkt *Packet, p *Peer) {
 		return
 	}
 	p.setNetAddress(rm.Addr)
+	cn.resolveCompression(p, rm.Compressions)
 
 	cn.nextOnPeer(p)
 }
@@ -0,0 +1,87 @@
+package network
+
+import (
+	"fmt"
+
+	"github.com/golang/snappy"
+)
+
+const (
+	DefaultPacketCompressionThreshold = 1024
+)
+
+// packetCompression identifies the algorithm used for compressing payload
+// of the packet. It's written as the first byte of compressed payload.
+type packetCompression byte
+
+const (
+	packetCompressionNone packetCompression = iota
+	packetCompressionSnappy
+)
+
+// supportedCompressions is the list of compression names in order of
+// preference. It's advertised on channel negotiation.
+var supportedCompressions = []string{
+	packetCompressionSnappy.String(),
+}
+
+func (c packetCompression) String() string {
+	switch c {
+	case packetCompressionNone:
+		return "none"
+	case packetCompressionSnappy:
+		return "snappy"
+	default:
+		return fmt.Sprintf("unknown(%d)", byte(c))
+	}
+}
+
+// resolveCompression returns the first compression in the preferences
+// supported by the peer. If there is no matching one, it returns
+// packetCompressionNone.
+func resolveCompression(preferences, supported []string) packetCompression {
+	for _, name := range preferences {
+		for _, s := range supported {
+			if name != s {
+				continue
+			}
+			switch name {
+			case packetCompressionSnappy.String():
+				return packetCompressionSnappy
+			}
+		}
+	}
+	return packetCompressionNone
+}
+
+// compress returns compressed payload prefixed by the compression type.
+func (c packetCompression) compress(b []byte) []byte {
+	switch c {
+	case packetCompressionSnappy:
+		out := make([]byte, 1+snappy.MaxEncodedLen(len(b)))
+		out[0] = byte(c)
+		enc := snappy.Encode(out[1:], b)
+		return out[:1+len(enc)]
+	default:
+		return nil
+	}
+}
+
+func decompressPayload(b []byte) ([]byte, error) {
+	if len(b) < 1 {
+		return nil, fmt.Errorf("short compressed payload")
+	}
+	switch c := packetCompression(b[0]); c {
+	case packetCompressionSnappy:
+		n, err := snappy.DecodedLen(b[1:])
+		if err != nil {
+			return nil, err
+		}
+		if n > DefaultPacketPayloadMax {
+			return nil, fmt.Errorf("invalid decompressed length %d", n)
+		}
+		return snappy.Decode(nil, b[1:])
+	default:
+		return nil, fmt.Errorf("unsupported compression %s", c)
+	}
+}
@@ -22,6 +22,7 @@ const (
 	DuplicatedPeerError
 	InvalidMessageSequenceError
 	InvalidSignatureError
+	BannedPeerError
 )
 
 var (
@@ -41,6 +42,7 @@ var (
 	ErrDuplicatedPeer            = errors.NewBase(DuplicatedPeerError, "DuplicatedPeer")
 	ErrInvalidMessageSequence    = errors.NewBase(InvalidMessageSequenceError, "InvalidMessageSequence")
 	ErrInvalidSignature          = errors.NewBase(InvalidSignatureError, "InvalidSignatureError")
+	ErrBannedPeer                = errors.NewBase(BannedPeerError, "BannedPeer")
 	ErrIllegalArgument           = errors.ErrIllegalArgument
 )
 
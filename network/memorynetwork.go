@@ -0,0 +1,498 @@
+package network
+
+import (
+	"encoding/binary"
+	"io"
+	"math/rand"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/module"
+)
+
+// FaultModel decides how packets are delivered between addresses of
+// transports in MemoryNetwork.
+type FaultModel interface {
+	// Connectable returns whether the transport of from can connect to
+	// the transport of to.
+	Connectable(from, to string) bool
+
+	// Deliver returns the delay of the packet from the address to the other
+	// address and whether the packet is dropped.
+	Deliver(from, to string, size int) (delay time.Duration, drop bool)
+}
+
+// LinkFault is the fault of the link from an address to another.
+// Packets are delayed by Latency plus random duration less than Jitter, so
+// packets can be reordered if Jitter is bigger than the interval of them.
+// Packets are dropped in the probability of Loss (0.0 ~ 1.0).
+type LinkFault struct {
+	Latency time.Duration
+	Jitter  time.Duration
+	Loss    float64
+}
+
+type linkKey struct {
+	from, to string
+}
+
+// Faults is a FaultModel which can be changed while it's used.
+// Random decisions are made with the seed for reproducible tests.
+type Faults struct {
+	mtx    sync.Mutex
+	rand   *rand.Rand
+	def    LinkFault
+	links  map[linkKey]LinkFault
+	groups map[string]int
+}
+
+func NewFaults(seed int64) *Faults {
+	return &Faults{
+		rand:  rand.New(rand.NewSource(seed)),
+		links: make(map[linkKey]LinkFault),
+	}
+}
+
+// SetDefault sets the fault for links not specified by SetLink.
+func (f *Faults) SetDefault(lf LinkFault) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	f.def = lf
+}
+
+// SetLink sets the fault of the link from an address to the other.
+func (f *Faults) SetLink(from, to string, lf LinkFault) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	f.links[linkKey{from, to}] = lf
+}
+
+// ClearLink removes the fault set by SetLink.
+func (f *Faults) ClearLink(from, to string) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	delete(f.links, linkKey{from, to})
+}
+
+// Partition splits addresses into the groups. Addresses in different groups
+// can't connect each other, and packets between them are dropped silently.
+// Addresses not in any group are reachable from all.
+func (f *Faults) Partition(groups ...[]string) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	f.groups = make(map[string]int)
+	for i, g := range groups {
+		for _, addr := range g {
+			f.groups[addr] = i
+		}
+	}
+}
+
+// Heal removes the partition.
+func (f *Faults) Heal() {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	f.groups = nil
+}
+
+func (f *Faults) _reachable(from, to string) bool {
+	gf, ok1 := f.groups[from]
+	gt, ok2 := f.groups[to]
+	return !ok1 || !ok2 || gf == gt
+}
+
+func (f *Faults) Connectable(from, to string) bool {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	return f._reachable(from, to)
+}
+
+func (f *Faults) Deliver(from, to string, size int) (time.Duration, bool) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	if !f._reachable(from, to) {
+		return 0, true
+	}
+	lf, ok := f.links[linkKey{from, to}]
+	if !ok {
+		lf = f.def
+	}
+	if lf.Loss > 0 && f.rand.Float64() < lf.Loss {
+		return 0, true
+	}
+	d := lf.Latency
+	if lf.Jitter > 0 {
+		d += time.Duration(f.rand.Int63n(int64(lf.Jitter)))
+	}
+	return d, false
+}
+
+// MemoryNetwork connects transports in a process without sockets. Faults
+// of the FaultModel are applied for each packet, so secure suites except
+// SecureSuiteNone (default for the transport) can't be used.
+// Delays are measured with the clock, then tests may use test/clock for
+// controlling delivery of packets.
+type MemoryNetwork struct {
+	mtx       sync.Mutex
+	listeners map[string]*memoryListener
+	fm        FaultModel
+	clock     common.Clock
+}
+
+// NewMemoryNetwork returns a new MemoryNetwork. If fm is nil, packets are
+// delivered without faults, and if clock is nil, it uses system time.
+func NewMemoryNetwork(fm FaultModel, clock common.Clock) *MemoryNetwork {
+	if clock == nil {
+		clock = &common.GoTimeClock{}
+	}
+	return &MemoryNetwork{
+		listeners: make(map[string]*memoryListener),
+		fm:        fm,
+		clock:     clock,
+	}
+}
+
+// NewTransport returns a new transport using the network. The address is
+// used for the listen address, and it's also used to identify the transport
+// in the fault model.
+func (mn *MemoryNetwork) NewTransport(address string, w module.Wallet, l log.Logger) module.NetworkTransport {
+	return newTransport(address, w, mn, l)
+}
+
+func (mn *MemoryNetwork) Listen(address string) (net.Listener, error) {
+	mn.mtx.Lock()
+	defer mn.mtx.Unlock()
+
+	if _, ok := mn.listeners[address]; ok {
+		return nil, errors.InvalidStateError.Errorf("AlreadyInUse(addr=%s)", address)
+	}
+	l := &memoryListener{
+		mn:      mn,
+		address: address,
+		ch:      make(chan net.Conn),
+		closeCh: make(chan struct{}),
+	}
+	mn.listeners[address] = l
+	return l, nil
+}
+
+func (mn *MemoryNetwork) removeListener(l *memoryListener) {
+	mn.mtx.Lock()
+	defer mn.mtx.Unlock()
+
+	if mn.listeners[l.address] == l {
+		delete(mn.listeners, l.address)
+	}
+}
+
+func (mn *MemoryNetwork) Dial(from, to string, timeout time.Duration) (net.Conn, error) {
+	mn.mtx.Lock()
+	l, ok := mn.listeners[to]
+	mn.mtx.Unlock()
+
+	if !ok {
+		return nil, errors.NotFoundError.Errorf("ConnectionRefused(addr=%s)", to)
+	}
+	if mn.fm != nil && !mn.fm.Connectable(from, to) {
+		return nil, errors.TimeoutError.Errorf("Unreachable(from=%s,to=%s)", from, to)
+	}
+	c1, c2 := mn.newConnPair(from, to)
+	select {
+	case l.ch <- c2:
+		return c1, nil
+	case <-l.closeCh:
+		return nil, errors.NotFoundError.Errorf("ConnectionRefused(addr=%s)", to)
+	}
+}
+
+func (mn *MemoryNetwork) newConnPair(from, to string) (*memoryConn, *memoryConn) {
+	b1 := newMemoryBuffer()
+	b2 := newMemoryBuffer()
+	c1 := &memoryConn{
+		local:  memoryAddr(from),
+		remote: memoryAddr(to),
+		rb:     b1,
+		link:   &memoryLink{mn: mn, from: from, to: to, target: b2},
+	}
+	c2 := &memoryConn{
+		local:  memoryAddr(to),
+		remote: memoryAddr(from),
+		rb:     b2,
+		link:   &memoryLink{mn: mn, from: to, to: from, target: b1},
+	}
+	return c1, c2
+}
+
+type memoryAddr string
+
+func (a memoryAddr) Network() string {
+	return "memory"
+}
+
+func (a memoryAddr) String() string {
+	return string(a)
+}
+
+type memoryListener struct {
+	mn        *MemoryNetwork
+	address   string
+	ch        chan net.Conn
+	closeCh   chan struct{}
+	closeOnce sync.Once
+}
+
+func (l *memoryListener) Accept() (net.Conn, error) {
+	select {
+	case c := <-l.ch:
+		return c, nil
+	case <-l.closeCh:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *memoryListener) Close() error {
+	l.closeOnce.Do(func() {
+		l.mn.removeListener(l)
+		close(l.closeCh)
+	})
+	return nil
+}
+
+func (l *memoryListener) Addr() net.Addr {
+	return memoryAddr(l.address)
+}
+
+// memoryBuffer keeps received bytes until they are read.
+type memoryBuffer struct {
+	mtx    sync.Mutex
+	cond   *sync.Cond
+	data   []byte
+	closed bool
+}
+
+func newMemoryBuffer() *memoryBuffer {
+	b := &memoryBuffer{}
+	b.cond = sync.NewCond(&b.mtx)
+	return b
+}
+
+func (b *memoryBuffer) Read(p []byte) (int, error) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	for len(b.data) == 0 && !b.closed {
+		b.cond.Wait()
+	}
+	if len(b.data) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, b.data)
+	b.data = b.data[n:]
+	return n, nil
+}
+
+func (b *memoryBuffer) write(p []byte) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	if !b.closed {
+		b.data = append(b.data, p...)
+		b.cond.Broadcast()
+	}
+}
+
+func (b *memoryBuffer) close() {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	b.closed = true
+	b.cond.Broadcast()
+}
+
+type memoryFrame struct {
+	due   time.Time
+	seq   int
+	bytes []byte // nil for closing the connection
+}
+
+// memoryLink delivers frames from a connection to the other. Frames are
+// delivered in order of due time, and frames having same due time are
+// delivered in order of sending.
+type memoryLink struct {
+	mn     *MemoryNetwork
+	from   string
+	to     string
+	target *memoryBuffer
+
+	mtx    sync.Mutex
+	seq    int
+	frames []*memoryFrame
+}
+
+func (l *memoryLink) send(b []byte) {
+	var d time.Duration
+	if b != nil && l.mn.fm != nil {
+		var drop bool
+		if d, drop = l.mn.fm.Deliver(l.from, l.to, len(b)); drop {
+			return
+		}
+	}
+	l.mtx.Lock()
+	now := l.mn.clock.Now()
+	if b == nil && len(l.frames) > 0 {
+		// close after delivering all the frames
+		if last := l.frames[len(l.frames)-1].due; last.After(now) {
+			d = last.Sub(now)
+		}
+	}
+	l.seq++
+	f := &memoryFrame{
+		due:   now.Add(d),
+		seq:   l.seq,
+		bytes: b,
+	}
+	idx := sort.Search(len(l.frames), func(i int) bool {
+		return f.due.Before(l.frames[i].due)
+	})
+	l.frames = append(l.frames, nil)
+	copy(l.frames[idx+1:], l.frames[idx:])
+	l.frames[idx] = f
+	l.mtx.Unlock()
+
+	if d <= 0 {
+		l.flush()
+	} else {
+		l.mn.clock.AfterFunc(d, l.flush)
+	}
+}
+
+func (l *memoryLink) flush() {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	now := l.mn.clock.Now()
+	for len(l.frames) > 0 && !l.frames[0].due.After(now) {
+		f := l.frames[0]
+		l.frames[0] = nil
+		l.frames = l.frames[1:]
+		if f.bytes == nil {
+			l.target.close()
+		} else {
+			l.target.write(f.bytes)
+		}
+	}
+}
+
+// memoryConn is a connection of MemoryNetwork. Written bytes are split into
+// packets for applying faults.
+type memoryConn struct {
+	local  memoryAddr
+	remote memoryAddr
+	rb     *memoryBuffer
+	link   *memoryLink
+
+	mtx    sync.Mutex
+	wb     []byte
+	closed bool
+}
+
+// packetFrameLength returns the length of the packet at the beginning of
+// the bytes. It returns zero if the bytes are not enough for the packet.
+func packetFrameLength(b []byte) (int, error) {
+	if len(b) < packetHeaderSize {
+		return 0, nil
+	}
+	l := binary.BigEndian.Uint32(b[packetHeaderSize-4:]) &^ packetLengthCompressed
+	if l > DefaultPacketPayloadMax {
+		return 0, errors.IllegalArgumentError.Errorf("InvalidPacketLength(len=%d)", l)
+	}
+	n := packetHeaderSize + int(l) + packetFooterSize
+	if len(b) < n {
+		return 0, nil
+	}
+	n += newPacketExtendInfoFrom(b[n-2:]).len()
+	if len(b) < n {
+		return 0, nil
+	}
+	return n, nil
+}
+
+func (c *memoryConn) Read(b []byte) (int, error) {
+	return c.rb.Read(b)
+}
+
+func (c *memoryConn) Write(b []byte) (int, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if c.closed {
+		return 0, net.ErrClosed
+	}
+	c.wb = append(c.wb, b...)
+	for {
+		n, err := packetFrameLength(c.wb)
+		if err != nil {
+			c._close()
+			return 0, err
+		}
+		if n == 0 {
+			break
+		}
+		frame := make([]byte, n)
+		copy(frame, c.wb)
+		c.wb = c.wb[n:]
+		c.link.send(frame)
+	}
+	return len(b), nil
+}
+
+func (c *memoryConn) _close() {
+	if !c.closed {
+		c.closed = true
+		c.rb.close()
+		c.link.send(nil)
+	}
+}
+
+func (c *memoryConn) Close() error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if c.closed {
+		return net.ErrClosed
+	}
+	c._close()
+	return nil
+}
+
+func (c *memoryConn) LocalAddr() net.Addr {
+	return c.local
+}
+
+func (c *memoryConn) RemoteAddr() net.Addr {
+	return c.remote
+}
+
+func (c *memoryConn) SetDeadline(t time.Time) error {
+	return nil
+}
+
+func (c *memoryConn) SetReadDeadline(t time.Time) error {
+	return nil
+}
+
+func (c *memoryConn) SetWriteDeadline(t time.Time) error {
+	return nil
+}
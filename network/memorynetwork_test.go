@@ -0,0 +1,202 @@
+package network
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/test/clock"
+)
+
+func newMemoryConnPair(t *testing.T, mn *MemoryNetwork, from, to string) (*memoryConn, *memoryConn) {
+	ln, err := mn.Listen(to)
+	assert.NoError(t, err)
+	defer ln.Close()
+
+	ch := make(chan net.Conn, 1)
+	go func() {
+		c, _ := ln.Accept()
+		ch <- c
+	}()
+	c1, err := mn.Dial(from, to, DefaultDialTimeout)
+	assert.NoError(t, err)
+	return c1.(*memoryConn), (<-ch).(*memoryConn)
+}
+
+func (b *memoryBuffer) len() int {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return len(b.data)
+}
+
+func writeTestPacket(t *testing.T, c net.Conn, msg string) {
+	w := NewPacketWriter(c)
+	pkt := newPacket(packetTestProtocolInfo, packetTestProtocolInfo, []byte(msg), generatePeerID())
+	assert.NoError(t, w.WritePacket(pkt))
+	assert.NoError(t, w.Flush())
+}
+
+func readTestPacket(t *testing.T, r *PacketReader) string {
+	pkt, err := r.ReadPacket()
+	assert.NoError(t, err)
+	return string(pkt.payload)
+}
+
+func Test_memoryNetwork_faults(t *testing.T) {
+	const (
+		addr1 = "127.0.0.1:9001"
+		addr2 = "127.0.0.1:9002"
+	)
+	cl := &clock.Clock{}
+	f := NewFaults(1)
+	mn := NewMemoryNetwork(f, cl)
+
+	_, err := mn.Dial(addr1, addr2, DefaultDialTimeout)
+	assert.Error(t, err)
+
+	c1, c2 := newMemoryConnPair(t, mn, addr1, addr2)
+	r := NewPacketReader(c2)
+
+	// latency
+	f.SetDefault(LinkFault{Latency: 100 * time.Millisecond})
+	writeTestPacket(t, c1, "test1")
+	assert.Equal(t, 0, c2.rb.len())
+	cl.PassTime(99 * time.Millisecond)
+	assert.Equal(t, 0, c2.rb.len())
+	cl.PassTime(time.Millisecond)
+	assert.Equal(t, "test1", readTestPacket(t, r))
+
+	// loss
+	f.SetDefault(LinkFault{})
+	f.SetLink(addr1, addr2, LinkFault{Loss: 1.0})
+	writeTestPacket(t, c1, "test2")
+	f.ClearLink(addr1, addr2)
+	writeTestPacket(t, c1, "test3")
+	assert.Equal(t, "test3", readTestPacket(t, r))
+
+	// reordering by jitter
+	f.SetDefault(LinkFault{Jitter: time.Second})
+	msgs := make(map[string]bool)
+	for i := 0; i < 10; i++ {
+		msg := fmt.Sprintf("test4.%d", i)
+		writeTestPacket(t, c1, msg)
+		msgs[msg] = true
+	}
+	cl.PassTime(time.Second)
+	for i := 0; i < 10; i++ {
+		msg := readTestPacket(t, r)
+		assert.True(t, msgs[msg], msg)
+		delete(msgs, msg)
+	}
+
+	// partition
+	f.SetDefault(LinkFault{})
+	f.Partition([]string{addr1}, []string{addr2})
+	ln, err := mn.Listen(addr2)
+	assert.NoError(t, err)
+	_, err = mn.Dial(addr1, addr2, DefaultDialTimeout)
+	assert.Error(t, err)
+	assert.NoError(t, ln.Close())
+	writeTestPacket(t, c1, "test5")
+	assert.Equal(t, 0, c2.rb.len())
+	f.Heal()
+	writeTestPacket(t, c1, "test6")
+	assert.Equal(t, "test6", readTestPacket(t, r))
+
+	// close after delivering pending packets
+	f.SetDefault(LinkFault{Latency: time.Second})
+	writeTestPacket(t, c1, "test7")
+	assert.NoError(t, c1.Close())
+	_, err = c1.Write([]byte("test"))
+	assert.Error(t, err)
+	cl.PassTime(time.Second)
+	assert.Equal(t, "test7", readTestPacket(t, r))
+	_, err = r.ReadPacket()
+	assert.Equal(t, io.EOF, err)
+}
+
+func generateMemoryNetwork(mn *MemoryNetwork, name string, port int, n int, t *testing.T, roles ...module.Role) []*testReactor {
+	arr := make([]*testReactor, n)
+	for i := 0; i < n; i++ {
+		w := walletFromGeneratedPrivateKey()
+		nodeLogger := log.New().WithFields(log.Fields{log.FieldKeyWallet: hex.EncodeToString(w.Address().ID())})
+		nodeLogger.SetLevel(testLogLevel)
+		nodeLogger.SetConsoleLevel(testLogLevel)
+		nt := mn.NewTransport(fmt.Sprintf("127.0.0.1:%d", port+i), w, nodeLogger)
+		chainLogger := nodeLogger.WithFields(log.Fields{log.FieldKeyCID: "1"})
+		c := &dummyChain{nid: 1, metricCtx: context.Background(), logger: chainLogger}
+		nm := NewManager(c, nt, "", roles...)
+		r := newTestReactor(fmt.Sprintf("%s_%d", name, i), nm, ProtoTestNetwork, t)
+		r.nt = nt
+		if err := r.nt.Listen(); err != nil {
+			t.Fatal(err)
+		}
+		if err := nm.Start(); err != nil {
+			t.Fatal(err)
+		}
+		arr[i] = r
+	}
+	return arr
+}
+
+func waitJoin(ch <-chan context.Context, n int, d time.Duration) error {
+	tm := time.NewTimer(d)
+	defer tm.Stop()
+	for rn := 0; rn < n; {
+		select {
+		case ctx := <-ch:
+			if ctx.Value("op") == "join" {
+				rn++
+			}
+		case <-tm.C:
+			return fmt.Errorf("timeout d:%v n:%d rn:%d", d, n, rn)
+		}
+	}
+	return nil
+}
+
+func Test_memoryNetwork_managers(t *testing.T) {
+	f := NewFaults(1)
+	mn := NewMemoryNetwork(f, nil)
+	f.SetDefault(LinkFault{Latency: time.Millisecond, Jitter: time.Millisecond})
+
+	arr := generateMemoryNetwork(mn, "TestMemory", 9100, 3, t, module.ROLE_VALIDATOR)
+	ch := make(chan context.Context, 100)
+	for _, r := range arr {
+		r.ch = ch
+	}
+	dailByList(t, arr[1:], arr[0].p2p.NetAddress(), 0)
+	assert.NoError(t, waitJoin(ch, 4, 5*time.Second))
+
+	msg := arr[1].Broadcast("Test1")
+	err := wait(ch, ProtoTestNetworkBroadcast, msg, 1, time.Second, arr[2].name)
+	assert.NoError(t, err, "Broadcast", "Test1")
+
+	f.Partition([]string{
+		string(arr[0].p2p.NetAddress()),
+		string(arr[1].p2p.NetAddress()),
+	}, []string{
+		string(arr[2].p2p.NetAddress()),
+	})
+	msg = arr[1].Broadcast("Test2")
+	err = wait(ch, ProtoTestNetworkBroadcast, msg, 1, 500*time.Millisecond, arr[2].name)
+	assert.Error(t, err, "Broadcast", "Test2")
+
+	f.Heal()
+	msg = arr[1].Broadcast("Test3")
+	err = wait(ch, ProtoTestNetworkBroadcast, msg, 1, time.Second, arr[2].name)
+	assert.NoError(t, err, "Broadcast", "Test3")
+
+	for _, r := range arr {
+		assert.NoError(t, r.nt.Close())
+		r.nm.Term()
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/icon-project/goloop/common/errors"
 	"github.com/icon-project/goloop/common/log"
@@ -68,6 +69,12 @@ func NewManager(c module.Chain, nt module.NetworkTransport, trustSeeds string, r
 	m.SetInitialRoles(roles...)
 	m.SetTrustSeeds(trustSeeds)
 
+	if database := c.Database(); database != nil {
+		if err := m.p2p.scores.load(database); err != nil {
+			m.logger.Warnf("fail to load banned peers err=%+v", err)
+		}
+	}
+
 	m.p2p.setConnectionLimit(p2pConnTypeChildren, c.ChildrenLimit())
 	m.p2p.setConnectionLimit(p2pConnTypeNephew, c.NephewsLimit())
 
@@ -379,6 +386,26 @@ func (m *manager) SetInitialRoles(roles ...module.Role) {
 	m.p2p.setRole(role)
 }
 
+// PeerScoreManager manages scores and bans of peers. The network manager
+// implements it.
+type PeerScoreManager interface {
+	PeerScores() []*PeerScore
+	BanPeer(id module.PeerID, d time.Duration, reason string) error
+	UnbanPeer(id module.PeerID) error
+}
+
+func (m *manager) PeerScores() []*PeerScore {
+	return m.p2p.scores.list()
+}
+
+func (m *manager) BanPeer(id module.PeerID, d time.Duration, reason string) error {
+	return m.p2p.scores.ban(id, d, reason)
+}
+
+func (m *manager) UnbanPeer(id module.PeerID) error {
+	return m.p2p.scores.unban(id)
+}
+
 func ChannelOfNetID(id int) string {
 	return strconv.FormatInt(int64(id), 16)
 }
@@ -12,6 +12,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/db"
 	"github.com/icon-project/goloop/common/log"
 	"github.com/icon-project/goloop/module"
 )
@@ -288,6 +289,7 @@ func (c *dummyChain) Logger() log.Logger             { return c.logger }
 func (c *dummyChain) MetricContext() context.Context { return c.metricCtx }
 func (c *dummyChain) ChildrenLimit() int             { return -1 }
 func (c *dummyChain) NephewsLimit() int              { return -1 }
+func (c *dummyChain) Database() db.Database          { return nil }
 
 func generateNetwork(name string, port int, n int, t *testing.T, roles ...module.Role) ([]*testReactor, int) {
 	arr := make([]*testReactor, n)
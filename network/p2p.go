@@ -108,6 +108,9 @@ type PeerToPeer struct {
 	allowedSeeds *PeerIDSet
 	allowedPeers *PeerIDSet
 
+	//scores of peers
+	scores *peerScoreBoard
+
 	//connection limit
 	cLimit    map[PeerConnectionType]int
 	cLimitMtx sync.RWMutex
@@ -166,6 +169,8 @@ func newPeerToPeer(channel string, self *Peer, d *Dialer, mtr *metric.NetworkMet
 		allowedSeeds: NewPeerIDSet(),
 		allowedPeers: NewPeerIDSet(),
 		//
+		scores: newPeerScoreBoard(p2pLogger),
+		//
 		cLimit: make(map[PeerConnectionType]int),
 		//
 		logger: p2pLogger,
@@ -181,6 +186,7 @@ func newPeerToPeer(channel string, self *Peer, d *Dialer, mtr *metric.NetworkMet
 	p2p.allowedPeers.onUpdate = func(s *PeerIDSet) {
 		p2p.onAllowedPeerIDSetUpdate(s, p2pRoleNone)
 	}
+	p2p.scores.setOnBan(p2p.onBan)
 	return p2p
 }
 
@@ -305,6 +311,10 @@ func (p2p *PeerToPeer) onPeer(p *Peer) {
 		p.CloseByError(fmt.Errorf("onPeer not allowed connection"))
 		return
 	}
+	if p2p.scores.isBanned(p.ID()) {
+		p.CloseByError(ErrBannedPeer)
+		return
+	}
 	if p2p.isTrustSeed(p) {
 		p2p.trustSeeds.SetAndRemoveByData(p.DialNetAddress(), string(p.NetAddress()))
 	}
@@ -372,6 +382,13 @@ func (p2p *PeerToPeer) onClose(p *Peer) {
 	}
 }
 
+//callback from peerScoreBoard on banning the peer
+func (p2p *PeerToPeer) onBan(id module.PeerID) {
+	for _, p := range p2p.findPeers(func(p *Peer) bool { return p.ID().Equal(id) }) {
+		p.CloseByError(ErrBannedPeer)
+	}
+}
+
 func (p2p *PeerToPeer) onEvent(evt string, p *Peer) {
 	//if !p2p.IsStarted() {
 	//	return
@@ -17,6 +17,11 @@ import (
 const (
 	packetHeaderSize = 10 + peerIDSize
 	packetFooterSize = 10
+
+	// packetLengthCompressed is set on lengthOfPayload in the header if the
+	// payload is compressed. It's used only for the peer supporting
+	// compression.
+	packetLengthCompressed = 1 << 31
 )
 
 //srcPeerId, castType, destInfo, TTL(0:unlimited)
@@ -43,6 +48,12 @@ type Packet struct {
 	timestamp time.Time
 	forceSend bool
 	mtx       sync.RWMutex
+	//compression
+	compressed bool
+	zpayload   []byte
+	zcodec     packetCompression
+	zMtx       sync.Mutex
+	wireLength uint32
 }
 
 type packetDestInfo uint16
@@ -156,16 +167,32 @@ func (p *Packet) _read(r io.Reader, n int) ([]byte, int, error) {
 }
 
 func (p *Packet) WriteTo(w io.Writer) (n int64, err error) {
+	return p.writeTo(w, packetCompressionNone)
+}
+
+// writeTo writes the packet with the payload compressed by c if it's
+// worth. The packet itself isn't changed since it could be sent to other
+// peers, and hashOfPacket is always calculated with the original payload.
+func (p *Packet) writeTo(w io.Writer, c packetCompression) (n int64, err error) {
 	if err = p.updateHash(false); err != nil {
 		return
 	}
 
+	header := p.headerToBytes(false)
+	payload := p.payload[:p.lengthOfPayload]
+	if zp := p.compressedPayload(c); zp != nil {
+		header = append([]byte(nil), header...)
+		binary.BigEndian.PutUint32(header[packetHeaderSize-4:],
+			uint32(len(zp))|packetLengthCompressed)
+		payload = zp
+	}
+
 	var tn int
-	tn, err = w.Write(p.headerToBytes(false))
+	tn, err = w.Write(header)
 	if n += int64(tn); err != nil {
 		return
 	}
-	tn, err = w.Write(p.payload[:p.lengthOfPayload])
+	tn, err = w.Write(payload)
 	if n += int64(tn); err != nil {
 		return
 	}
@@ -182,6 +209,44 @@ func (p *Packet) WriteTo(w io.Writer) (n int64, err error) {
 	return
 }
 
+// compressedPayload returns payload compressed by c. It returns nil if the
+// payload is too small or compression doesn't reduce the size.
+func (p *Packet) compressedPayload(c packetCompression) []byte {
+	if c == packetCompressionNone || p.lengthOfPayload < DefaultPacketCompressionThreshold {
+		return nil
+	}
+	p.zMtx.Lock()
+	defer p.zMtx.Unlock()
+
+	if p.zcodec != c {
+		zp := c.compress(p.payload[:p.lengthOfPayload])
+		if len(zp) >= int(p.lengthOfPayload) {
+			zp = nil
+		}
+		p.zpayload, p.zcodec = zp, c
+	}
+	return p.zpayload
+}
+
+// lengthOnWire returns length of the payload in the frame sent with the
+// compression. It doesn't depend on how the packet was received, so it's
+// also correct for the packet forwarded to other peers.
+func (p *Packet) lengthOnWire(c packetCompression) uint32 {
+	if zp := p.compressedPayload(c); zp != nil {
+		return uint32(len(zp))
+	}
+	return p.lengthOfPayload
+}
+
+// lengthReceived returns length of the payload in the frame received from
+// the peer.
+func (p *Packet) lengthReceived() uint32 {
+	if p.wireLength != 0 {
+		return p.wireLength
+	}
+	return p.lengthOfPayload
+}
+
 func (p *Packet) updateHash(force bool) error {
 	if p.hashOfPacket == 0 || force {
 		h, err := p._hash(force)
@@ -272,6 +337,15 @@ func (p *Packet) ReadFrom(r io.Reader) (n int64, err error) {
 		}
 	}
 
+	if p.compressed {
+		p.wireLength = p.lengthOfPayload
+		if p.payload, err = decompressPayload(p.payload); err != nil {
+			return
+		}
+		p.lengthOfPayload = uint32(len(p.payload))
+		p.headerToBytes(true)
+	}
+
 	h, err := p._hash(false)
 	if err != nil {
 		return
@@ -302,6 +376,8 @@ func (p *Packet) setHeader(b []byte) ([]byte, error) {
 	tb = tb[1:]
 	p.lengthOfPayload = binary.BigEndian.Uint32(tb[:4])
 	tb = tb[4:]
+	p.compressed = p.lengthOfPayload&packetLengthCompressed != 0
+	p.lengthOfPayload &^= packetLengthCompressed
 	if p.lengthOfPayload > DefaultPacketPayloadMax {
 		return b[packetHeaderSize:], fmt.Errorf("invalid lengthOfPayload")
 	}
@@ -380,7 +456,11 @@ func (pw *PacketWriter) Reset(wr io.Writer) {
 }
 
 func (pw *PacketWriter) WritePacket(pkt *Packet) error {
-	_, err := pkt.WriteTo(pw)
+	return pw.writePacket(pkt, packetCompressionNone)
+}
+
+func (pw *PacketWriter) writePacket(pkt *Packet, c packetCompression) error {
+	_, err := pkt.writeTo(pw, c)
 	if err != nil {
 		return err
 	}
@@ -86,3 +86,52 @@ func FuzzPacketReadFrom(f *testing.F) {
 		assert.LessOrEqual(t, n, int64(len(data)))
 	})
 }
+
+func Test_packet_Compression(t *testing.T) {
+	prw := NewPacketReadWriter()
+
+	payload := bytes.Repeat([]byte("compressible payload "), 200)
+	pkt := newPacket(packetTestProtocolInfo, packetTestProtocolInfo, payload, generatePeerID())
+	assert.NoError(t, pkt.updateHash(false))
+	assert.NoError(t, prw.wr.writePacket(pkt, packetCompressionSnappy))
+	assert.NoError(t, prw.wr.Flush())
+	wire := pkt.lengthOnWire(packetCompressionSnappy)
+	assert.True(t, wire < pkt.lengthOfPayload)
+	assert.Equal(t, int(packetHeaderSize+wire+packetFooterSize), prw.b.Len())
+
+	rpkt, err := prw.rd.ReadPacket()
+	assert.NoError(t, err)
+	assert.Equal(t, payload, rpkt.payload)
+	assert.Equal(t, pkt.lengthOfPayload, rpkt.lengthOfPayload)
+	assert.Equal(t, pkt.hashOfPacket, rpkt.hashOfPacket)
+	assert.Equal(t, pkt.headerToBytes(false), rpkt.headerToBytes(false))
+	assert.Equal(t, wire, rpkt.lengthReceived())
+
+	// forwarded packet reports the length of the outgoing frame
+	assert.Equal(t, pkt.lengthOfPayload, rpkt.lengthOnWire(packetCompressionNone))
+	assert.NoError(t, prw.wr.writePacket(rpkt, packetCompressionNone))
+	assert.NoError(t, prw.wr.Flush())
+	assert.Equal(t, int(packetHeaderSize+pkt.lengthOfPayload+packetFooterSize), prw.b.Len())
+	fpkt, err := prw.rd.ReadPacket()
+	assert.NoError(t, err)
+	assert.Equal(t, pkt.lengthOfPayload, fpkt.lengthReceived())
+	assert.Equal(t, wire, rpkt.lengthOnWire(packetCompressionSnappy))
+
+	// small payload is sent as it is
+	pkt = newPacket(packetTestProtocolInfo, packetTestProtocolInfo, []byte("test"), generatePeerID())
+	assert.NoError(t, prw.wr.writePacket(pkt, packetCompressionSnappy))
+	assert.NoError(t, prw.wr.Flush())
+	rpkt, err = prw.rd.ReadPacket()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("test"), rpkt.payload)
+	assert.Equal(t, uint32(4), rpkt.lengthReceived())
+}
+
+func Test_packet_resolveCompression(t *testing.T) {
+	assert.Equal(t, packetCompressionSnappy,
+		resolveCompression(supportedCompressions, []string{"zstd", "snappy"}))
+	assert.Equal(t, packetCompressionNone,
+		resolveCompression(supportedCompressions, []string{"zstd"}))
+	assert.Equal(t, packetCompressionNone,
+		resolveCompression(supportedCompressions, nil))
+}
@@ -60,6 +60,8 @@ type Peer struct {
 	pisMtx        sync.RWMutex
 	attr          map[string]interface{}
 	attrMtx       sync.RWMutex
+	compression   packetCompression
+	compressMtx   sync.RWMutex
 
 	//
 	secureKey *secureKey
@@ -419,7 +421,7 @@ func (p *Peer) receiveRoutine() {
 
 		pkt.sender = p.ID()
 		p.pool.Put(pkt.hashOfPacket)
-		p.getMetric().OnRecv(pkt.dest, pkt.ttl, pkt.extendInfo.hint(), pkt.protocol.Uint16(), pkt.lengthOfPayload)
+		p.getMetric().OnRecv(pkt.dest, pkt.ttl, pkt.extendInfo.hint(), pkt.protocol.Uint16(), pkt.lengthOfPayload, pkt.lengthReceived())
 		//TODO peer.packet_dump
 		if isLoggingPacket {
 			log.Println(p.ID(), "Peer", "receiveRoutine", p.ConnType(), p.ConnString(), pkt)
@@ -433,12 +435,16 @@ func (p *Peer) receiveRoutine() {
 }
 
 func (p *Peer) sendDirect(pkt *Packet) error {
+	return p.sendWith(pkt, packetCompressionNone)
+}
+
+func (p *Peer) sendWith(pkt *Packet, c packetCompression) error {
 	defer p.sendMtx.Unlock()
 	p.sendMtx.Lock()
 
 	if err := p.conn.SetWriteDeadline(time.Now().Add(DefaultSendTimeout)); err != nil {
 		return err
-	} else if err := p.writer.WritePacket(pkt); err != nil {
+	} else if err := p.writer.writePacket(pkt, c); err != nil {
 		return err
 	} else if err := p.writer.Flush(); err != nil {
 		return err
@@ -464,7 +470,8 @@ Loop:
 					break
 				}
 				pkt := ctx.Value(p2pContextKeyPacket).(*Packet)
-				if err := p.sendDirect(pkt); err != nil {
+				c := p.getCompression()
+				if err := p.sendWith(pkt, c); err != nil {
 					r := p.isTemporaryError(err)
 					p.logger.Tracef("Peer.sendRoutine Error isTemporary:{%v} error:{%+v} peer:%s", r, err, p.String())
 					p.CloseByError(err)
@@ -475,7 +482,7 @@ Loop:
 					log.Println(p.ID(), "Peer", "sendRoutine", p.ConnType(), p.ConnString(), pkt)
 				}
 				p.pool.Put(pkt.hashOfPacket)
-				p.getMetric().OnSend(pkt.dest, pkt.ttl, pkt.extendInfo.hint(), pkt.protocol.Uint16(), pkt.lengthOfPayload)
+				p.getMetric().OnSend(pkt.dest, pkt.ttl, pkt.extendInfo.hint(), pkt.protocol.Uint16(), pkt.lengthOfPayload, pkt.lengthOnWire(c))
 			}
 		case <-secondTick.C:
 			p.pool.RemoveBefore(DefaultPeerPoolExpireSecond)
@@ -527,6 +534,20 @@ func (p *Peer) sendPacket(pkt *Packet) error {
 	return p.send(ctx)
 }
 
+// getCompression returns the compression for packets to the peer. It's
+// resolved on channel negotiation.
+func (p *Peer) getCompression() packetCompression {
+	p.compressMtx.RLock()
+	defer p.compressMtx.RUnlock()
+	return p.compression
+}
+
+func (p *Peer) setCompression(c packetCompression) {
+	p.compressMtx.Lock()
+	defer p.compressMtx.Unlock()
+	p.compression = c
+}
+
 func (p *Peer) setMetric(nm *metric.NetworkMetric) {
 	p.metricMtx.Lock()
 	defer p.metricMtx.Unlock()
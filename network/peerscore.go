@@ -0,0 +1,312 @@
+package network
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/module"
+)
+
+const (
+	DefaultPeerScoreLimit     = 100
+	DefaultPeerScoreRecovery  = 10 // per minute
+	DefaultPeerBanDuration    = time.Hour
+	DefaultPeerBanDurationMax = 24 * time.Hour
+)
+
+var keyPeerBans = db.Raw("network.peer_bans")
+
+// PeerScore is the status of the peer in the score board.
+type PeerScore struct {
+	ID     module.PeerID
+	Score  int
+	Until  time.Time
+	Reason string
+}
+
+func (s *PeerScore) IsBanned(now time.Time) bool {
+	return now.Before(s.Until)
+}
+
+type peerScore struct {
+	PeerScore
+	updated time.Time
+	bans    int
+}
+
+// recover applies recovery of the score since the last update.
+func (s *peerScore) recover(now time.Time) {
+	if s.Score < 0 {
+		m := now.Sub(s.updated) / time.Minute
+		s.Score += int(m) * DefaultPeerScoreRecovery
+		s.updated = s.updated.Add(m * time.Minute)
+	}
+	if s.Score >= 0 {
+		s.Score = 0
+		s.updated = now
+	}
+}
+
+// expired returns true if nothing is left to keep for the peer. Count of
+// bans is kept until DefaultPeerBanDurationMax passes after the last ban.
+func (s *peerScore) expired(now time.Time) bool {
+	if s.IsBanned(now) {
+		return false
+	}
+	s.recover(now)
+	if s.Score != 0 {
+		return false
+	}
+	return s.bans == 0 || now.Sub(s.Until) >= DefaultPeerBanDurationMax
+}
+
+type peerBan struct {
+	ID     []byte
+	Until  int64
+	Reason string
+	Bans   int
+}
+
+// peerScoreBoard keeps scores of peers. Score of a peer is decreased by
+// penalties reported by reactors, and it recovers as time goes on. If the
+// score reaches -DefaultPeerScoreLimit, the peer is banned for a while.
+// Duration of the ban gets longer for repeated bans. Banned peers are stored
+// in the database if it's available.
+type peerScoreBoard struct {
+	mtx    sync.Mutex
+	scores map[string]*peerScore
+	bk     *db.CodedBucket
+	onBan  func(id module.PeerID)
+	logger log.Logger
+	pruned time.Time
+
+	now func() time.Time
+}
+
+func newPeerScoreBoard(l log.Logger) *peerScoreBoard {
+	return &peerScoreBoard{
+		scores: make(map[string]*peerScore),
+		logger: l,
+		now:    time.Now,
+	}
+}
+
+func (b *peerScoreBoard) setOnBan(f func(id module.PeerID)) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	b.onBan = f
+}
+
+// load restores banned peers from the database and keeps the database for
+// following changes.
+func (b *peerScoreBoard) load(database db.Database) error {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	bk, err := db.NewCodedBucket(database, db.ChainProperty, nil)
+	if err != nil {
+		return err
+	}
+	b.bk = bk
+	var bans []*peerBan
+	if err := bk.Get(keyPeerBans, &bans); err != nil {
+		if errors.NotFoundError.Equals(err) {
+			return nil
+		}
+		return err
+	}
+	now := b.now()
+	for _, ban := range bans {
+		until := time.Unix(0, ban.Until*int64(time.Microsecond))
+		if !now.Before(until) {
+			continue
+		}
+		id := NewPeerID(ban.ID)
+		b.scores[string(ban.ID)] = &peerScore{
+			PeerScore: PeerScore{
+				ID:     id,
+				Score:  -DefaultPeerScoreLimit,
+				Until:  until,
+				Reason: ban.Reason,
+			},
+			updated: until,
+			bans:    ban.Bans,
+		}
+	}
+	return nil
+}
+
+func (b *peerScoreBoard) _store() {
+	if b.bk == nil {
+		return
+	}
+	now := b.now()
+	bans := make([]*peerBan, 0)
+	for k, s := range b.scores {
+		if s.IsBanned(now) {
+			bans = append(bans, &peerBan{
+				ID:     []byte(k),
+				Until:  s.Until.UnixNano() / int64(time.Microsecond),
+				Reason: s.Reason,
+				Bans:   s.bans,
+			})
+		}
+	}
+	if err := b.bk.Set(keyPeerBans, bans); err != nil {
+		b.logger.Warnf("fail to store banned peers err=%+v", err)
+	}
+}
+
+// _prune drops expired scores. It's done at most once a minute.
+func (b *peerScoreBoard) _prune(now time.Time) {
+	if now.Sub(b.pruned) < time.Minute {
+		return
+	}
+	b.pruned = now
+	for k, s := range b.scores {
+		if s.expired(now) {
+			delete(b.scores, k)
+		}
+	}
+}
+
+func (b *peerScoreBoard) _get(id module.PeerID, now time.Time) *peerScore {
+	b._prune(now)
+	k := string(id.Bytes())
+	s, ok := b.scores[k]
+	if !ok {
+		s = &peerScore{
+			PeerScore: PeerScore{ID: id},
+			updated:   now,
+		}
+		b.scores[k] = s
+	} else if !s.IsBanned(now) {
+		s.recover(now)
+	}
+	return s
+}
+
+func (b *peerScoreBoard) _ban(s *peerScore, d time.Duration, reason string, now time.Time) {
+	s.Score = -DefaultPeerScoreLimit
+	s.Until = now.Add(d)
+	s.Reason = reason
+	s.updated = s.Until
+	b._store()
+	b.logger.Infof("Ban peer=%s until=%s reason=%s", s.ID, s.Until, reason)
+}
+
+func (b *peerScoreBoard) notifyBan(id module.PeerID) {
+	b.mtx.Lock()
+	onBan := b.onBan
+	b.mtx.Unlock()
+
+	if onBan != nil {
+		onBan(id)
+	}
+}
+
+// report decreases the score of the peer with the penalty and bans the
+// peer if the score reaches the limit. It returns true if the peer is
+// banned by the report.
+func (b *peerScoreBoard) report(id module.PeerID, penalty int, reason string) bool {
+	if penalty <= 0 {
+		return false
+	}
+	if b._report(id, penalty, reason) {
+		b.notifyBan(id)
+		return true
+	}
+	return false
+}
+
+func (b *peerScoreBoard) _report(id module.PeerID, penalty int, reason string) bool {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	now := b.now()
+	s := b._get(id, now)
+	if s.IsBanned(now) {
+		return false
+	}
+	s.Score -= penalty
+	b.logger.Debugf("ReportPeer peer=%s penalty=%d score=%d reason=%s",
+		id, penalty, s.Score, reason)
+	if s.Score > -DefaultPeerScoreLimit {
+		return false
+	}
+	d := DefaultPeerBanDuration
+	for i := 0; i < s.bans && d < DefaultPeerBanDurationMax; i++ {
+		d *= 2
+	}
+	if d > DefaultPeerBanDurationMax {
+		d = DefaultPeerBanDurationMax
+	}
+	s.bans++
+	b._ban(s, d, reason, now)
+	return true
+}
+
+func (b *peerScoreBoard) ban(id module.PeerID, d time.Duration, reason string) error {
+	if d <= 0 {
+		return errors.IllegalArgumentError.Errorf("InvalidDuration(%s)", d)
+	}
+	b.mtx.Lock()
+	now := b.now()
+	b._ban(b._get(id, now), d, reason, now)
+	b.mtx.Unlock()
+
+	b.notifyBan(id)
+	return nil
+}
+
+func (b *peerScoreBoard) unban(id module.PeerID) error {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	k := string(id.Bytes())
+	s, ok := b.scores[k]
+	if !ok || !s.IsBanned(b.now()) {
+		return errors.NotFoundError.Errorf("NotBanned(peer=%s)", id)
+	}
+	delete(b.scores, k)
+	b._store()
+	b.logger.Infof("Unban peer=%s", id)
+	return nil
+}
+
+func (b *peerScoreBoard) isBanned(id module.PeerID) bool {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	s, ok := b.scores[string(id.Bytes())]
+	return ok && s.IsBanned(b.now())
+}
+
+// list returns peers having penalties or bans, and it drops expired ones.
+func (b *peerScoreBoard) list() []*PeerScore {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	now := b.now()
+	l := make([]*PeerScore, 0, len(b.scores))
+	for k, s := range b.scores {
+		if s.expired(now) {
+			delete(b.scores, k)
+			continue
+		}
+		if !s.IsBanned(now) && s.Score == 0 {
+			continue
+		}
+		ps := s.PeerScore
+		l = append(l, &ps)
+	}
+	sort.Slice(l, func(i, j int) bool {
+		return l[i].ID.String() < l[j].ID.String()
+	})
+	return l
+}
@@ -0,0 +1,127 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/module"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func (c *testClock) Add(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTestPeerScoreBoard() (*peerScoreBoard, *testClock, *[]module.PeerID) {
+	clock := &testClock{now: time.Unix(1000000, 0)}
+	b := newPeerScoreBoard(log.New())
+	b.now = clock.Now
+	banned := make([]module.PeerID, 0)
+	b.setOnBan(func(id module.PeerID) {
+		banned = append(banned, id)
+	})
+	return b, clock, &banned
+}
+
+func TestPeerScoreBoard_Report(t *testing.T) {
+	b, clock, banned := newTestPeerScoreBoard()
+	id := generatePeerID()
+
+	assert.False(t, b.report(id, module.PeerPenaltyMajor, "test"))
+	assert.False(t, b.report(id, module.PeerPenaltyMajor, "test"))
+	assert.False(t, b.isBanned(id))
+	l := b.list()
+	assert.Len(t, l, 1)
+	assert.Equal(t, -2*module.PeerPenaltyMajor, l[0].Score)
+
+	// recovered for a minute
+	clock.Add(time.Minute + time.Second)
+	l = b.list()
+	assert.Equal(t, -2*module.PeerPenaltyMajor+DefaultPeerScoreRecovery, l[0].Score)
+
+	assert.True(t, b.report(id, module.PeerPenaltyCritical, "critical"))
+	assert.True(t, b.isBanned(id))
+	assert.Equal(t, []module.PeerID{id}, *banned)
+	l = b.list()
+	assert.Len(t, l, 1)
+	assert.Equal(t, "critical", l[0].Reason)
+	assert.Equal(t, clock.Now().Add(DefaultPeerBanDuration), l[0].Until)
+
+	// no more reports while it's banned
+	assert.False(t, b.report(id, module.PeerPenaltyCritical, "critical"))
+
+	// ban expires, and the next ban gets longer
+	clock.Add(DefaultPeerBanDuration)
+	assert.False(t, b.isBanned(id))
+	assert.True(t, b.report(id, module.PeerPenaltyCritical, "critical"))
+	l = b.list()
+	assert.Equal(t, clock.Now().Add(2*DefaultPeerBanDuration), l[0].Until)
+
+	// fully recovered peer is dropped from the list
+	b2, clock2, _ := newTestPeerScoreBoard()
+	b2.report(id, module.PeerPenaltyMinor, "minor")
+	clock2.Add(time.Minute)
+	assert.Len(t, b2.list(), 0)
+}
+
+func TestPeerScoreBoard_BanUnban(t *testing.T) {
+	b, clock, banned := newTestPeerScoreBoard()
+	id := generatePeerID()
+
+	err := b.ban(id, 0, "invalid")
+	assert.True(t, errors.IllegalArgumentError.Equals(err))
+
+	err = b.unban(id)
+	assert.True(t, errors.NotFoundError.Equals(err))
+
+	assert.NoError(t, b.ban(id, time.Minute, "manual"))
+	assert.True(t, b.isBanned(id))
+	assert.Equal(t, []module.PeerID{id}, *banned)
+
+	assert.NoError(t, b.unban(id))
+	assert.False(t, b.isBanned(id))
+	assert.Len(t, b.list(), 0)
+
+	assert.NoError(t, b.ban(id, time.Minute, "manual"))
+	clock.Add(time.Minute)
+	assert.False(t, b.isBanned(id))
+	err = b.unban(id)
+	assert.True(t, errors.NotFoundError.Equals(err))
+}
+
+func TestPeerScoreBoard_Prune(t *testing.T) {
+	b, clock, _ := newTestPeerScoreBoard()
+
+	for i := 0; i < 10; i++ {
+		b.report(generatePeerID(), module.PeerPenaltyMinor, "minor")
+	}
+	banned := generatePeerID()
+	assert.True(t, b.report(banned, module.PeerPenaltyCritical, "critical"))
+	assert.Len(t, b.scores, 11)
+
+	// recovered peers are dropped on the next report
+	clock.Add(time.Minute)
+	id := generatePeerID()
+	b.report(id, module.PeerPenaltyMinor, "minor")
+	assert.Len(t, b.scores, 2)
+
+	// history of the ban is kept for a while after the ban expires
+	clock.Add(DefaultPeerBanDuration + time.Minute)
+	b.report(id, module.PeerPenaltyMinor, "minor")
+	assert.Len(t, b.scores, 2)
+
+	clock.Add(DefaultPeerBanDurationMax)
+	b.report(id, module.PeerPenaltyMinor, "minor")
+	assert.Len(t, b.scores, 1)
+}
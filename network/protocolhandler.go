@@ -352,3 +352,8 @@ func (ph *protocolHandler) Broadcast(pi module.ProtocolInfo, b []byte, bt module
 func (ph *protocolHandler) GetPeers() []module.PeerID {
 	return ph.m.getPeersByProtocol(ph.protocol)
 }
+
+func (ph *protocolHandler) ReportPeer(id module.PeerID, penalty int, reason string) {
+	ph.logger.Infoln("ReportPeer", id, penalty, reason)
+	ph.m.p2p.scores.report(id, penalty, reason)
+}
@@ -162,6 +162,10 @@ func (r *streamReactor) GetPeers() []module.PeerID {
 	return r.ph.GetPeers()
 }
 
+func (r *streamReactor) ReportPeer(id module.PeerID, penalty int, reason string) {
+	r.ph.ReportPeer(id, penalty, reason)
+}
+
 func newStream(r *streamReactor, id module.PeerID) *stream {
 	return &stream{
 		r:  r,
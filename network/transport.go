@@ -5,11 +5,28 @@ import (
 	"net"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/icon-project/goloop/common/log"
 	"github.com/icon-project/goloop/module"
 )
 
+// transportNetwork provides listeners and connections for the transport.
+type transportNetwork interface {
+	Listen(address string) (net.Listener, error)
+	Dial(from, to string, timeout time.Duration) (net.Conn, error)
+}
+
+type tcpNetwork struct{}
+
+func (tcpNetwork) Listen(address string) (net.Listener, error) {
+	return net.Listen(DefaultTransportNet, address)
+}
+
+func (tcpNetwork) Dial(from, to string, timeout time.Duration) (net.Conn, error) {
+	return net.DialTimeout(DefaultTransportNet, to, timeout)
+}
+
 type transport struct {
 	l       *Listener
 	address NetAddress
@@ -17,10 +34,15 @@ type transport struct {
 	cn      *ChannelNegotiator
 	pd      *PeerDispatcher
 	dMap    map[string]*Dialer
+	nw      transportNetwork
 	logger  log.Logger
 }
 
 func NewTransport(address string, w module.Wallet, l log.Logger) module.NetworkTransport {
+	return newTransport(address, w, tcpNetwork{}, l)
+}
+
+func newTransport(address string, w module.Wallet, nw transportNetwork, l log.Logger) *transport {
 	na := NetAddress(address)
 	if err := na.Validate(); err != nil {
 		l.Panicf("invalid P2P Address err:%+v", err)
@@ -29,7 +51,7 @@ func NewTransport(address string, w module.Wallet, l log.Logger) module.NetworkT
 	a := newAuthenticator(w, transportLogger)
 	cn := newChannelNegotiator(na, transportLogger)
 	pd := newPeerDispatcher(NewPeerIDFromAddress(w.Address()), transportLogger, a, cn)
-	listener := newListener(address, pd.onAccept, transportLogger)
+	listener := newListener(address, nw, pd.onAccept, transportLogger)
 	t := &transport{
 		l:       listener,
 		address: na,
@@ -37,6 +59,7 @@ func NewTransport(address string, w module.Wallet, l log.Logger) module.NetworkT
 		cn:      cn,
 		pd:      pd,
 		dMap:    make(map[string]*Dialer),
+		nw:      nw,
 		logger:  transportLogger,
 	}
 	return t
@@ -73,7 +96,7 @@ func (t *transport) GetListenAddress() string {
 func (t *transport) GetDialer(channel string) *Dialer {
 	d, ok := t.dMap[channel]
 	if !ok {
-		d = newDialer(channel, t.pd.onConnect)
+		d = newDialer(channel, string(t.address), t.nw, t.pd.onConnect)
 		t.dMap[channel] = d
 	}
 	return d
@@ -133,6 +156,7 @@ func (t *transport) GetSecureAeads(channel string) string {
 
 type Listener struct {
 	address  string
+	nw       transportNetwork
 	ln       net.Listener
 	mtx      sync.Mutex
 	closeCh  chan bool
@@ -143,9 +167,10 @@ type Listener struct {
 
 type acceptCbFunc func(conn net.Conn)
 
-func newListener(address string, cbFunc acceptCbFunc, l log.Logger) *Listener {
+func newListener(address string, nw transportNetwork, cbFunc acceptCbFunc, l log.Logger) *Listener {
 	return &Listener{
 		address:  address,
+		nw:       nw,
 		onAccept: cbFunc,
 		logger:   l.WithFields(log.Fields{LoggerFieldKeySubModule: "listener"}),
 	}
@@ -178,7 +203,7 @@ func (l *Listener) Listen() error {
 	if l.ln != nil {
 		return ErrAlreadyListened
 	}
-	ln, err := net.Listen(DefaultTransportNet, l.address)
+	ln, err := l.nw.Listen(l.address)
 	if err != nil {
 		return err
 	}
@@ -221,15 +246,19 @@ func (l *Listener) acceptRoutine() {
 type Dialer struct {
 	onConnect connectCbFunc
 	channel   string
+	address   string
+	nw        transportNetwork
 	dialing   *Set
 }
 
 type connectCbFunc func(conn net.Conn, addr string, d *Dialer)
 
-func newDialer(channel string, cbFunc connectCbFunc) *Dialer {
+func newDialer(channel string, address string, nw transportNetwork, cbFunc connectCbFunc) *Dialer {
 	return &Dialer{
 		onConnect: cbFunc,
 		channel:   channel,
+		address:   address,
+		nw:        nw,
 		dialing:   NewSet(),
 	}
 }
@@ -238,7 +267,7 @@ func (d *Dialer) Dial(addr string) error {
 	if !d.dialing.Add(addr) {
 		return ErrAlreadyDialing
 	}
-	conn, err := net.DialTimeout(DefaultTransportNet, addr, DefaultDialTimeout)
+	conn, err := d.nw.Dial(d.address, addr, DefaultDialTimeout)
 	_ = d.dialing.Remove(addr)
 	if err != nil {
 		return err
@@ -15,6 +15,7 @@ import (
 
 	"github.com/icon-project/goloop/chain"
 	"github.com/icon-project/goloop/chain/gs"
+	"github.com/icon-project/goloop/common"
 	"github.com/icon-project/goloop/common/errors"
 	"github.com/icon-project/goloop/common/log"
 	"github.com/icon-project/goloop/module"
@@ -378,6 +379,10 @@ func (n *Node) JoinChain(
 
 	channel := chain.GetChannel(p.Channel, nid)
 
+	if !chain.IsHistoryRetention(p.HistoryRetention) {
+		return nil, errors.Errorf("InvalidHistoryRetention(%s)", p.HistoryRetention)
+	}
+
 	if err := n._canAdd(cid, nid, channel, false); err != nil {
 		return nil, err
 	}
@@ -414,6 +419,11 @@ func (n *Node) JoinChain(
 		ChildrenLimit:    p.ChildrenLimit,
 		NephewsLimit:     p.NephewsLimit,
 		ValidateTxOnSend: p.ValidateTxOnSend,
+		AddressIndex:     p.AddressIndex,
+		MaxLogsRange:     p.MaxLogsRange,
+		MaxLogsResults:   p.MaxLogsResults,
+		StateRetention:   p.StateRetention,
+		HistoryRetention: p.HistoryRetention,
 	}
 
 	if err := cfg.Save(); err != nil {
@@ -530,7 +540,82 @@ func (n *Node) PruneChain(cid int, dbt string, height int64) error {
 	return c.Prune(gs, dbt, height)
 }
 
-func (n *Node) BackupChain(cid int, manual bool) (string, error) {
+func (n *Node) _getPeerScoreManager(cid int) (network.PeerScoreManager, error) {
+	c, err := n._get(cid)
+	if err != nil {
+		return nil, err
+	}
+	if nm := c.NetworkManager(); nm != nil {
+		if psm, ok := nm.(network.PeerScoreManager); ok {
+			return psm, nil
+		}
+	}
+	return nil, errors.InvalidStateError.Errorf(
+		"NetworkManagerNotAvailable(cid=%#x)", cid)
+}
+
+func parsePeerID(peer string) (module.PeerID, error) {
+	addr, err := common.NewAddressFromString(peer)
+	if err != nil || addr.IsContract() {
+		return nil, errors.IllegalArgumentError.Errorf("InvalidPeer(%s)", peer)
+	}
+	return network.NewPeerIDFromAddress(addr), nil
+}
+
+func (n *Node) GetPeerScores(cid int) ([]*network.PeerScore, error) {
+	defer n.mtx.RUnlock()
+	n.mtx.RLock()
+
+	psm, err := n._getPeerScoreManager(cid)
+	if err != nil {
+		return nil, err
+	}
+	return psm.PeerScores(), nil
+}
+
+// BanPeer bans the peer for the duration. The peer is disconnected and it
+// can't connect to the chain until the ban expires or it's unbanned.
+func (n *Node) BanPeer(cid int, peer string, d time.Duration, reason string) error {
+	defer n.mtx.RUnlock()
+	n.mtx.RLock()
+
+	psm, err := n._getPeerScoreManager(cid)
+	if err != nil {
+		return err
+	}
+	id, err := parsePeerID(peer)
+	if err != nil {
+		return err
+	}
+	return psm.BanPeer(id, d, reason)
+}
+
+func (n *Node) UnbanPeer(cid int, peer string) error {
+	defer n.mtx.RUnlock()
+	n.mtx.RLock()
+
+	psm, err := n._getPeerScoreManager(cid)
+	if err != nil {
+		return err
+	}
+	id, err := parsePeerID(peer)
+	if err != nil {
+		return err
+	}
+	return psm.UnbanPeer(id)
+}
+
+const (
+	BackupTypeFull         = "full"
+	BackupTypeIncremental  = "incremental"
+	BackupTypeDifferential = "differential"
+)
+
+// BackupChain starts to backup the chain. Incremental backups record only
+// files changed since the base, which is the latest backup of the chain
+// for BackupTypeIncremental and the latest full backup for
+// BackupTypeDifferential if it's not specified.
+func (n *Node) BackupChain(cid int, manual bool, btype string, base string) (string, error) {
 	defer n.mtx.RUnlock()
 	n.mtx.RLock()
 
@@ -540,18 +625,80 @@ func (n *Node) BackupChain(cid int, manual bool) (string, error) {
 	}
 
 	if manual {
-		return "manual", c.Backup("", nil)
+		return "manual", c.Backup("", nil, "")
 	}
 	backupDir := n.cfg.ResolveAbsolute(n.cfg.BackupDir)
 	if err := os.MkdirAll(backupDir, 0700); err != nil {
 		return "", errors.InvalidStateError.Wrapf(err,
 			"Fail to make backup directory=%s", backupDir)
 	}
+
+	switch btype {
+	case "", BackupTypeFull:
+		if base != "" {
+			return "", errors.IllegalArgumentError.New("BaseForFullBackup")
+		}
+	case BackupTypeIncremental, BackupTypeDifferential:
+		full := btype == BackupTypeDifferential
+		if base == "" {
+			if base, err = n._latestBackupOf(c, backupDir, full); err != nil {
+				return "", err
+			}
+		} else {
+			if path.Base(base) != base {
+				return "", errors.IllegalArgumentError.Errorf(
+					"InvalidBackupName(name=%s)", base)
+			}
+			info, err := chain.GetBackupInfoOf(path.Join(backupDir, base))
+			if err != nil {
+				return "", errors.NotFoundError.Wrapf(err,
+					"InvalidBase(name=%s)", base)
+			}
+			if full && info.Base != nil {
+				return "", errors.IllegalArgumentError.Errorf(
+					"NotFullBackup(name=%s)", base)
+			}
+		}
+	default:
+		return "", errors.IllegalArgumentError.Errorf(
+			"InvalidBackupType(type=%s)", btype)
+	}
+
 	now := time.Now()
 	name := fmt.Sprintf("%#x_%#x_%s_%s.zip", c.CID(), c.NID(), c.Channel(),
 		now.Format("20060102-150405"))
 	file := path.Join(backupDir, name)
-	return name, c.Backup(file, []string{ChainGenesisZipFileName, ChainConfigFileName})
+	if base != "" {
+		base = path.Join(backupDir, base)
+	}
+	return name, c.Backup(file, []string{ChainGenesisZipFileName, ChainConfigFileName}, base)
+}
+
+func (n *Node) _latestBackupOf(c *Chain, backupDir string, full bool) (string, error) {
+	infos, err := getBackups(backupDir)
+	if err != nil {
+		return "", err
+	}
+	var latest *BackupInfo
+	for i := range infos {
+		info := &infos[i]
+		if int(info.CID.Value) != c.CID() || int(info.NID.Value) != c.NID() ||
+			info.Channel != c.Channel() || len(info.Hash) == 0 {
+			continue
+		}
+		if full && info.Base != nil {
+			continue
+		}
+		if latest == nil || info.Height > latest.Height ||
+			(info.Height == latest.Height && info.Name > latest.Name) {
+			latest = info
+		}
+	}
+	if latest == nil {
+		return "", errors.NotFoundError.Errorf(
+			"NoBaseBackup(cid=%#x)", c.CID())
+	}
+	return latest.Name, nil
 }
 
 type BackupInfo struct {
@@ -560,11 +707,7 @@ type BackupInfo struct {
 	chain.BackupInfo
 }
 
-func (n *Node) GetBackups() ([]BackupInfo, error) {
-	n.mtx.Lock()
-	defer n.mtx.Unlock()
-
-	backupDir := n.cfg.ResolveAbsolute(n.cfg.BackupDir)
+func getBackups(backupDir string) ([]BackupInfo, error) {
 	fis, err := ioutil.ReadDir(backupDir)
 	if err != nil {
 		return nil, err
@@ -589,6 +732,34 @@ func (n *Node) GetBackups() ([]BackupInfo, error) {
 	return infos, nil
 }
 
+func (n *Node) GetBackups() ([]BackupInfo, error) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+
+	backupDir := n.cfg.ResolveAbsolute(n.cfg.BackupDir)
+	return getBackups(backupDir)
+}
+
+// VerifyBackup checks whether the backup and its bases have all files
+// of the backup with valid checksums.
+func (n *Node) VerifyBackup(name string) error {
+	backupDir := func() string {
+		n.mtx.Lock()
+		defer n.mtx.Unlock()
+		return n.cfg.ResolveAbsolute(n.cfg.BackupDir)
+	}()
+	if path.Base(name) != name {
+		return errors.IllegalArgumentError.Errorf(
+			"InvalidBackupName(name=%s)", name)
+	}
+	seq, err := chain.OpenBackupSequence(backupDir, name)
+	if err != nil {
+		return err
+	}
+	defer seq.Close()
+	return seq.Verify(nil)
+}
+
 type RestoreView struct {
 	State     string `json:"state"`
 	Name      string `json:"name,omitempty"`
@@ -767,6 +938,35 @@ func (n *Node) ConfigureChain(cid int, key string, value string) error {
 			} else {
 				c.cfg.ValidateTxOnSend = bc
 			}
+		case "addressIndex":
+			if bc, err := strconv.ParseBool(value); err != nil {
+				return errors.Wrapf(err, "InvalidValueType(exp=bool,val=%s)", value)
+			} else {
+				c.cfg.AddressIndex = bc
+			}
+		case "maxLogsRange":
+			if intVal, err := strconv.ParseInt(value, 0, 64); err != nil {
+				return errors.Wrapf(err, "invalid value type")
+			} else {
+				c.cfg.MaxLogsRange = intVal
+			}
+		case "maxLogsResults":
+			if intVal, err := strconv.Atoi(value); err != nil {
+				return errors.Wrapf(err, "invalid value type")
+			} else {
+				c.cfg.MaxLogsResults = intVal
+			}
+		case "stateRetention":
+			if intVal, err := strconv.ParseInt(value, 0, 64); err != nil {
+				return errors.Wrapf(err, "invalid value type")
+			} else {
+				c.cfg.StateRetention = intVal
+			}
+		case "historyRetention":
+			if !chain.IsHistoryRetention(value) {
+				return errors.Errorf("InvalidHistoryRetention(%s)", value)
+			}
+			c.cfg.HistoryRetention = value
 		default:
 			return errors.Errorf("not found key %s", key)
 		}
@@ -879,6 +1079,13 @@ func (n *Node) Configure(key string, value string) error {
 			n.rcfg.RPCRosetta = boolVal
 		}
 		n.srv.SetRosetta(n.rcfg.RPCRosetta)
+	case "rpcStateOverride":
+		if boolVal, err := strconv.ParseBool(value); err != nil {
+			return errors.Wrapf(err, "invalid value type")
+		} else {
+			n.rcfg.RPCStateOverride = boolVal
+		}
+		n.srv.SetStateOverride(n.rcfg.RPCStateOverride)
 	case "rpcBatchLimit":
 		if intVal, err := strconv.Atoi(value); err != nil {
 			return errors.Wrapf(err, "invalid value type")
@@ -929,6 +1136,7 @@ func NewNode(
 		JSONRPCDump:           cfg.RPCDump,
 		JSONRPCIncludeDebug:   rcfg.RPCIncludeDebug,
 		JSONRPCRosetta:        rcfg.RPCRosetta,
+		JSONRPCStateOverride:  rcfg.RPCStateOverride,
 		JSONRPCDefaultChannel: rcfg.RPCDefaultChannel,
 		JSONRPCBatchLimit:     rcfg.RPCBatchLimit,
 		WSMaxSession:          rcfg.WSMaxSession,
@@ -20,6 +20,7 @@ import (
 	"github.com/icon-project/goloop/common"
 	"github.com/icon-project/goloop/common/db"
 	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/consensus"
 	"github.com/icon-project/goloop/module"
 	"github.com/icon-project/goloop/network"
 	"github.com/icon-project/goloop/server"
@@ -103,6 +104,11 @@ type ChainConfig struct {
 	ChildrenLimit    *int   `json:"childrenLimit,omitempty"`
 	NephewsLimit     *int   `json:"nephewsLimit,omitempty"`
 	ValidateTxOnSend bool   `json:"validateTxOnSend,omitempty"`
+	AddressIndex     bool   `json:"addressIndex,omitempty"`
+	MaxLogsRange     int64  `json:"maxLogsRange,omitempty"`
+	MaxLogsResults   int    `json:"maxLogsResults,omitempty"`
+	StateRetention   int64  `json:"stateRetention,omitempty"`
+	HistoryRetention string `json:"historyRetention,omitempty"`
 }
 
 type ChainResetParam struct {
@@ -120,8 +126,37 @@ type ChainPruneParam struct {
 	Height int64  `json:"height"`
 }
 
+type ChainSnapshotExportParam struct {
+	File   string `json:"file"`
+	Height int64  `json:"height,omitempty"`
+}
+
+type ChainSnapshotImportParam struct {
+	File   string `json:"file"`
+	DBType string `json:"db_type,omitempty"`
+}
+
 type ChainBackupParam struct {
-	Manual bool `json:"manual,omitempty"`
+	Manual bool   `json:"manual,omitempty"`
+	Type   string `json:"type,omitempty"`
+	Base   string `json:"base,omitempty"`
+}
+
+type ChainBanParam struct {
+	Peer     string `json:"peer"`
+	Duration string `json:"duration,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+type ChainUnbanParam struct {
+	Peer string `json:"peer"`
+}
+
+type PeerScoreView struct {
+	ID          string     `json:"id"`
+	Score       int        `json:"score"`
+	BannedUntil *time.Time `json:"bannedUntil,omitempty"`
+	Reason      string     `json:"reason,omitempty"`
 }
 
 type ConfigureParam struct {
@@ -134,6 +169,10 @@ type RestoreBackupParam struct {
 	Overwrite bool   `json:"overwrite"`
 }
 
+type VerifyBackupParam struct {
+	Name string `json:"name"`
+}
+
 func NewChainView(c *Chain) *ChainView {
 	state, height, lastErr := c.State()
 	v := &ChainView{
@@ -185,6 +224,11 @@ func NewChainConfig(cfg *chain.Config) *ChainConfig {
 		ChildrenLimit:    cfg.ChildrenLimit,
 		NephewsLimit:     cfg.NephewsLimit,
 		ValidateTxOnSend: cfg.ValidateTxOnSend,
+		AddressIndex:     cfg.AddressIndex,
+		MaxLogsRange:     cfg.MaxLogsRange,
+		MaxLogsResults:   cfg.MaxLogsResults,
+		StateRetention:   cfg.StateRetention,
+		HistoryRetention: cfg.HistoryRetention,
 	}
 	return v
 }
@@ -214,6 +258,7 @@ func RegisterRest(n *Node) {
 	r.RegisterDBHandlers(n.cliSrv.e.Group(UrlDB))
 
 	_ = RegisterInspectFunc("metrics", metric.Inspect)
+	_ = RegisterInspectFunc("consensus", consensus.Inspect)
 	_ = RegisterInspectFunc("network", network.Inspect)
 	_ = RegisterInspectFunc("service", service.Inspect)
 
@@ -237,6 +282,9 @@ func (r *Rest) RegisterChainHandlers(g *echo.Group) {
 	g.POST(UrlChainRes+"/import", r.ImportChain, r.ChainInjector)
 	g.POST(UrlChainRes+"/prune", r.PruneChain, r.ChainInjector)
 	g.POST(UrlChainRes+"/backup", r.BackupChain, r.ChainInjector)
+	g.GET(UrlChainRes+"/peers", r.GetChainPeers, r.ChainInjector)
+	g.POST(UrlChainRes+"/ban", r.BanChainPeer, r.ChainInjector)
+	g.POST(UrlChainRes+"/unban", r.UnbanChainPeer, r.ChainInjector)
 	route := g.GET(UrlChainRes+"/genesis", r.GetChainGenesis, r.ChainInjector)
 	if r.a != nil {
 		r.a.SetSkip(route, false)
@@ -421,13 +469,75 @@ func (r *Rest) BackupChain(ctx echo.Context) error {
 	if err := ctx.Bind(param); err != nil {
 		return echo.ErrBadRequest
 	}
-	if name, err := r.n.BackupChain(c.CID(), param.Manual); err != nil {
+	if name, err := r.n.BackupChain(c.CID(), param.Manual, param.Type, param.Base); err != nil {
 		return err
 	} else {
 		return ctx.String(http.StatusOK, name)
 	}
 }
 
+func (r *Rest) GetChainPeers(ctx echo.Context) error {
+	c := ctx.Get("chain").(*Chain)
+	scores, err := r.n.GetPeerScores(c.CID())
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	l := make([]*PeerScoreView, 0, len(scores))
+	for _, s := range scores {
+		v := &PeerScoreView{
+			ID:     s.ID.String(),
+			Score:  s.Score,
+			Reason: s.Reason,
+		}
+		if s.IsBanned(now) {
+			until := s.Until
+			v.BannedUntil = &until
+		}
+		l = append(l, v)
+	}
+	return ctx.JSON(http.StatusOK, l)
+}
+
+func (r *Rest) BanChainPeer(ctx echo.Context) error {
+	c := ctx.Get("chain").(*Chain)
+	param := &ChainBanParam{}
+	if err := ctx.Bind(param); err != nil {
+		return echo.ErrBadRequest
+	}
+	d := network.DefaultPeerBanDuration
+	if len(param.Duration) > 0 {
+		var err error
+		if d, err = time.ParseDuration(param.Duration); err != nil {
+			return echo.ErrBadRequest
+		}
+	}
+	if err := r.n.BanPeer(c.CID(), param.Peer, d, param.Reason); err != nil {
+		if errors.IllegalArgumentError.Equals(err) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return err
+	}
+	return ctx.String(http.StatusOK, "OK")
+}
+
+func (r *Rest) UnbanChainPeer(ctx echo.Context) error {
+	c := ctx.Get("chain").(*Chain)
+	param := &ChainUnbanParam{}
+	if err := ctx.Bind(param); err != nil {
+		return echo.ErrBadRequest
+	}
+	if err := r.n.UnbanPeer(c.CID(), param.Peer); err != nil {
+		if errors.IllegalArgumentError.Equals(err) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		} else if errors.NotFoundError.Equals(err) {
+			return ctx.String(http.StatusNotFound, err.Error())
+		}
+		return err
+	}
+	return ctx.String(http.StatusOK, "OK")
+}
+
 func (r *Rest) GetChainGenesis(ctx echo.Context) error {
 	c := ctx.Get("chain").(*Chain)
 	gsFile := path.Join(c.cfg.AbsBaseDir(), ChainGenesisZipFileName)
@@ -513,6 +623,7 @@ func (r *Rest) ConfigureSystem(ctx echo.Context) error {
 
 func (r *Rest) RegistryBackupHandlers(g *echo.Group) {
 	g.GET("", r.GetBackups)
+	g.POST("/verify", r.VerifyBackup)
 }
 
 func (r *Rest) GetBackups(ctx echo.Context) error {
@@ -523,6 +634,17 @@ func (r *Rest) GetBackups(ctx echo.Context) error {
 	return ctx.JSON(http.StatusOK, backups)
 }
 
+func (r *Rest) VerifyBackup(ctx echo.Context) error {
+	param := new(VerifyBackupParam)
+	if err := ctx.Bind(param); err != nil {
+		return echo.ErrBadRequest
+	}
+	if err := r.n.VerifyBackup(param.Name); err != nil {
+		return err
+	}
+	return ctx.String(http.StatusOK, "OK")
+}
+
 func (r *Rest) RegistryRestoreHandlers(g *echo.Group) {
 	g.POST("", r.RestoreBackup)
 	g.GET("", r.GetRestore)
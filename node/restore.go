@@ -103,21 +103,20 @@ func (m *RestoreManager) Start(node *Node, file string, baseDir string, overwrit
 		}
 	}()
 
-	zr, err := zip.OpenReader(file)
+	seq, err := chain.OpenBackupSequence(path.Dir(file), path.Base(file))
 	if err != nil {
-		return errors.IllegalArgumentError.Wrapf(err,
-			"ZipOpenFailure(backup=%s)", file)
+		return err
 	}
 	defer func() {
 		if ret != nil {
-			zr.Close()
+			seq.Close()
 		}
 	}()
 
-	info, err := chain.ReadBackupInfo(&zr.Reader)
+	info := seq.Last().Info
+	files, err := seq.Files()
 	if err != nil {
-		return errors.IllegalArgumentError.Wrap(err,
-			"InvalidBackupInfo")
+		return err
 	}
 
 	if info.Codec != codec.BC.Name() {
@@ -131,7 +130,7 @@ func (m *RestoreManager) Start(node *Node, file string, baseDir string, overwrit
 	}
 
 	go func() {
-		if err := m._restore(node, zr, tmpDir, overwrite); err != nil {
+		if err := m._restore(node, seq, files, tmpDir, overwrite); err != nil {
 			node.logger.Debugf("Restore failed err=%+v", err)
 			if errors.InterruptedError.Equals(err) {
 				m._setState(RestoreNone, nil)
@@ -147,7 +146,7 @@ func (m *RestoreManager) Start(node *Node, file string, baseDir string, overwrit
 	m.overwrite = overwrite
 	m.state = RestoreStarted
 	m.current = 0
-	m.total = len(zr.File)
+	m.total = len(files)
 	return nil
 }
 
@@ -235,15 +234,15 @@ func zipExtract(file *zip.File, tmpDir string) (ret error) {
 	return err
 }
 
-func (m *RestoreManager) _restore(node *Node, zr *zip.ReadCloser, tmpDir string, overwrite bool) (ret error) {
+func (m *RestoreManager) _restore(node *Node, seq chain.BackupSequence, files []*zip.File, tmpDir string, overwrite bool) (ret error) {
 	defer func() {
 		if ret != nil {
 			os.RemoveAll(tmpDir)
 		}
 	}()
-	defer zr.Close()
+	defer seq.Close()
 
-	for idx, file := range zr.File {
+	for idx, file := range files {
 		if err := zipExtract(file, tmpDir); err != nil {
 			return err
 		}
@@ -0,0 +1,205 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/server/jsonrpc"
+	"github.com/icon-project/goloop/server/v3"
+)
+
+type LogsRequest struct {
+	EventFilter
+	FromHeight common.HexInt64  `json:"fromHeight"`
+	ToHeight   *common.HexInt64 `json:"toHeight,omitempty"`
+
+	Filters EventFilters `json:"eventFilters,omitempty"`
+}
+
+func (r *LogsRequest) Compile() (EventFilters, error) {
+	return compileEventFilters(&r.EventFilter, r.Filters)
+}
+
+type EventLogEntry struct {
+	BlockHeight common.HexInt64 `json:"blockHeight"`
+	BlockHash   common.HexBytes `json:"blockHash"`
+	TxIndex     common.HexInt32 `json:"txIndex"`
+	TxHash      common.HexBytes `json:"txHash"`
+	LogIndex    common.HexInt32 `json:"logIndex"`
+	Log         module.EventLog `json:"log"`
+}
+
+// collectLogs returns matching event logs of the transactions in the blocks
+// of the range. Logs of patch transactions of a block come before the ones
+// of normal transactions, because they are executed first. It stops
+// collecting when the number of logs exceeds the limit. Receipts of normal
+// transactions of a block are in the result of the next block, so the block
+// at the height, to+1, should be available.
+func collectLogs(
+	bm module.BlockManager, sm module.ServiceManager,
+	filters EventFilters, from, to int64, limit int,
+) ([]*EventLogEntry, error) {
+	logs := make([]*EventLogEntry, 0)
+	blk, err := bm.GetBlockByHeight(from)
+	if err != nil {
+		return nil, err
+	}
+	for height := from; height <= to; height++ {
+		nblk, err := bm.GetBlockByHeight(height + 1)
+		if err != nil {
+			return nil, err
+		}
+		// receipts of patch transactions are in the result of the block
+		logs, err = appendLogsOf(logs, sm, filters, blk, blk,
+			module.TransactionGroupPatch, limit)
+		if err != nil || len(logs) > limit {
+			return logs, err
+		}
+		logs, err = appendLogsOf(logs, sm, filters, blk, nblk,
+			module.TransactionGroupNormal, limit)
+		if err != nil || len(logs) > limit {
+			return logs, err
+		}
+		blk = nblk
+	}
+	return logs, nil
+}
+
+// appendLogsOf appends matching event logs of the transactions in the group
+// of the block. Receipts of the transactions are in the result of rblk. The
+// index of the transaction is the one in the group as icx_getTransactionResult
+// returns. It stops appending when the number of logs exceeds the limit.
+func appendLogsOf(
+	logs []*EventLogEntry, sm module.ServiceManager, filters EventFilters,
+	blk, rblk module.Block, group module.TransactionGroup, limit int,
+) ([]*EventLogEntry, error) {
+	filters2, contained := filters.FilteredByLogBloom(rblk.LogsBloom())
+	if !contained {
+		return logs, nil
+	}
+	rl, err := sm.ReceiptListFromResult(rblk.Result(), group)
+	if err != nil {
+		return nil, err
+	}
+	var txs module.TransactionList
+	if group == module.TransactionGroupPatch {
+		txs = blk.PatchTransactions()
+	} else {
+		txs = blk.NormalTransactions()
+	}
+	index := 0
+	for rit := rl.Iterator(); rit.Has(); _, index = rit.Next(), index+1 {
+		r, err := rit.Get()
+		if err != nil {
+			return nil, err
+		}
+		es, el, err := filters2.MatchEvents(r, true)
+		if err != nil {
+			return nil, err
+		}
+		if len(es) == 0 {
+			continue
+		}
+		tx, err := txs.Get(index)
+		if err != nil {
+			return nil, err
+		}
+		for i, e := range es {
+			if len(logs) > limit {
+				return logs, nil
+			}
+			logs = append(logs, &EventLogEntry{
+				BlockHeight: common.HexInt64{Value: blk.Height()},
+				BlockHash:   blk.ID(),
+				TxIndex:     common.HexInt32{Value: int32(index)},
+				TxHash:      tx.ID(),
+				LogIndex:    e,
+				Log:         el[i],
+			})
+		}
+	}
+	return logs, nil
+}
+
+func getLogs(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
+	debug := ctx.IncludeDebug()
+
+	var param LogsRequest
+	if err := params.Convert(&param); err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, debug)
+	}
+	filters, err := param.Compile()
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, debug)
+	}
+
+	chain, err := ctx.Chain()
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeServer.Wrap(err, debug)
+	}
+
+	bm := chain.BlockManager()
+	sm := chain.ServiceManager()
+	if bm == nil || sm == nil {
+		return nil, jsonrpc.ErrorCodeServer.New("Stopped")
+	}
+
+	blk, err := bm.GetLastBlock()
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, debug)
+	}
+	// receipts of the last block are not available yet.
+	last := blk.Height() - 1
+
+	// without toHeight, it returns logs of blocks as many as possible.
+	maxRange := chain.MaxLogsRange()
+	from := param.FromHeight.Value
+	to := last
+	if param.ToHeight != nil {
+		to = param.ToHeight.Value
+	} else if from+maxRange-1 < to {
+		to = from + maxRange - 1
+	}
+	if from < 0 || to < from {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Errorf(
+			"InvalidHeightRange(from=%d,to=%d)", from, to)
+	}
+	if base := v3.LowestHeightOf(chain); from < base {
+		return nil, jsonrpc.ErrorCodePruned.Errorf(
+			"PrunedBlock(height=%d,base=%d)", from, base)
+	}
+	if to > last {
+		return nil, jsonrpc.ErrorCodeNotFound.Errorf(
+			"NoReceipts(height=%d,last=%d)", to, last)
+	}
+	if to-from+1 > maxRange {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Errorf(
+			"TooLargeRange(from=%d,to=%d,max=%d)", from, to, maxRange)
+	}
+
+	maxResults := chain.MaxLogsResults()
+	logs, err := collectLogs(bm, sm, filters, from, to, maxResults)
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, debug)
+	}
+	if len(logs) > maxResults {
+		return nil, jsonrpc.ErrorLackOfResource.Errorf(
+			"TooManyLogs(max=%d)", maxResults)
+	}
+	return logs, nil
+}
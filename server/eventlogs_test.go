@@ -0,0 +1,323 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/server/jsonrpc"
+	"github.com/icon-project/goloop/server/metric"
+	"github.com/icon-project/goloop/server/v3"
+)
+
+type testTransaction struct {
+	module.Transaction
+	id []byte
+}
+
+func (tx *testTransaction) ID() []byte {
+	return tx.id
+}
+
+type testTransactionList struct {
+	module.TransactionList
+	txs []module.Transaction
+}
+
+func (l *testTransactionList) Get(i int) (module.Transaction, error) {
+	if i < 0 || i >= len(l.txs) {
+		return nil, errors.ErrNotFound
+	}
+	return l.txs[i], nil
+}
+
+type testTxBlock struct {
+	testBlock
+	txs  module.TransactionList
+	ptxs module.TransactionList
+}
+
+func (b *testTxBlock) Height() int64 {
+	return b.height
+}
+
+func (b *testTxBlock) NormalTransactions() module.TransactionList {
+	return b.txs
+}
+
+func (b *testTxBlock) PatchTransactions() module.TransactionList {
+	return b.ptxs
+}
+
+func (bm *testBlockManager) GetBlockByHeight(height int64) (module.Block, error) {
+	getBlock, err := bm.fetcher(height)
+	if err != nil {
+		return nil, err
+	}
+	return getBlock(), nil
+}
+
+func newTestTransactions(name string, height int, events [][]*testEventLog) (*testTransactionList, testReceiptList) {
+	txs := &testTransactionList{}
+	var rl testReceiptList
+	for i, logs := range events {
+		txs.txs = append(txs.txs, &testTransaction{
+			id: []byte(fmt.Sprintf("%s%d_%d", name, height, i)),
+		})
+		rl = append(rl, newTestReceipt(logs))
+	}
+	return txs, rl
+}
+
+// newTestChainData builds blocks whose transactions emit the events. Each
+// element of events is for a block, and each element of that is for a
+// transaction. Receipts of normal transactions of a block are in the result
+// of the next block. patches are events of patch transactions of the blocks,
+// and their receipts are in the result of the block.
+func newTestChainData(events, patches [][][]*testEventLog) (*testBlockManager, *testServiceManager) {
+	receipts := make(blockReceipts)
+	patchReceipts := make(blockReceipts)
+	var blocks []*testTxBlock
+	var rl testReceiptList
+	var result string
+	for height := 0; height <= len(events); height++ {
+		var prl testReceiptList
+		blk := &testTxBlock{
+			testBlock: testBlock{height: int64(height), result: result},
+			ptxs:      &testTransactionList{},
+		}
+		if height < len(patches) {
+			blk.ptxs, prl = newTestTransactions("patch", height, patches[height])
+			patchReceipts[result] = prl
+		}
+		blk.lb = append(prl, rl...).LogsBloom()
+		blocks = append(blocks, blk)
+		if height == len(events) {
+			break
+		}
+		blk.txs, rl = newTestTransactions("tx", height, events[height])
+		result = fmt.Sprintf("result%d", height)
+		receipts[result] = rl
+	}
+	fetcher := func(h int64) (getBlockFunc, error) {
+		if h < 0 || h >= int64(len(blocks)) {
+			return nil, errors.ErrNotFound
+		}
+		return func() module.Block { return blocks[h] }, nil
+	}
+	return &testBlockManager{fetcher: fetcher}, &testServiceManager{
+		receipts:      receipts,
+		patchReceipts: patchReceipts,
+	}
+}
+
+func TestCollectLogs(t *testing.T) {
+	evA := newTestEventLog("cx01", "TestEventA()", nil, nil)
+	evB1 := newTestEventLog("cx02", "TestEventB(int)", [][]string{{"int", "0x1"}}, nil)
+	evB2 := newTestEventLog("cx02", "TestEventB(int)", [][]string{{"int", "0x2"}}, nil)
+	bm, sm := newTestChainData([][][]*testEventLog{
+		{{evA}},
+		{{}, {evB1, evA}},
+		{{evB2}},
+		{{evA, evB1}},
+	}, nil)
+
+	compile := func(fs ...*EventFilter) EventFilters {
+		filters, err := (&LogsRequest{Filters: fs}).Compile()
+		assert.NoError(t, err)
+		return filters
+	}
+
+	logs, err := collectLogs(bm, sm, compile(&EventFilter{
+		Signature: "TestEventB(int)",
+	}), 0, 3, 10)
+	assert.NoError(t, err)
+	assert.Len(t, logs, 3)
+	for i, e := range []struct {
+		height   int64
+		txIndex  int32
+		logIndex int32
+		txHash   string
+	}{
+		{1, 1, 0, "tx1_1"},
+		{2, 0, 0, "tx2_0"},
+		{3, 0, 1, "tx3_0"},
+	} {
+		assert.Equal(t, e.height, logs[i].BlockHeight.Value)
+		assert.Equal(t, testHeightToBlockID(e.height), logs[i].BlockHash.Bytes())
+		assert.Equal(t, e.txIndex, logs[i].TxIndex.Value)
+		assert.Equal(t, e.logIndex, logs[i].LogIndex.Value)
+		assert.Equal(t, []byte(e.txHash), logs[i].TxHash.Bytes())
+	}
+
+	logs, err = collectLogs(bm, sm, compile(&EventFilter{
+		Addr:      common.MustNewAddressFromString("cx02"),
+		Signature: "TestEventB(int)",
+		Indexed:   []*string{stringPtr("0x1")},
+	}, &EventFilter{
+		Signature: "TestEventA()",
+	}), 1, 2, 10)
+	assert.NoError(t, err)
+	assert.Len(t, logs, 2)
+	assert.EqualValues(t, 0, logs[0].LogIndex.Value)
+	assert.EqualValues(t, 1, logs[1].LogIndex.Value)
+
+	// it stops when it exceeds the limit
+	logs, err = collectLogs(bm, sm, compile(&EventFilter{
+		Signature: "TestEventA()",
+	}), 0, 3, 1)
+	assert.NoError(t, err)
+	assert.Len(t, logs, 2)
+
+	// receipts of the last block are not available
+	_, err = collectLogs(bm, sm, compile(&EventFilter{
+		Signature: "TestEventA()",
+	}), 0, 4, 10)
+	assert.Error(t, err)
+}
+
+func TestCollectLogs_PatchTransactions(t *testing.T) {
+	evA := newTestEventLog("cx01", "TestEventA()", nil, nil)
+	evB := newTestEventLog("cx02", "TestEventB()", nil, nil)
+	bm, sm := newTestChainData([][][]*testEventLog{
+		{{evA}},
+		{{evB}, {evA}},
+		{{evB}},
+	}, [][][]*testEventLog{
+		{},
+		{{evB}, {evA, evB}},
+		{},
+	})
+
+	filters, err := (&LogsRequest{Filters: []*EventFilter{
+		{Signature: "TestEventA()"},
+	}}).Compile()
+	assert.NoError(t, err)
+	logs, err := collectLogs(bm, sm, filters, 0, 2, 10)
+	assert.NoError(t, err)
+	assert.Len(t, logs, 3)
+	for i, e := range []struct {
+		height   int64
+		txIndex  int32
+		logIndex int32
+		txHash   string
+	}{
+		{0, 0, 0, "tx0_0"},
+		{1, 1, 0, "patch1_1"},
+		{1, 1, 0, "tx1_1"},
+	} {
+		assert.Equal(t, e.height, logs[i].BlockHeight.Value)
+		assert.Equal(t, testHeightToBlockID(e.height), logs[i].BlockHash.Bytes())
+		assert.Equal(t, e.txIndex, logs[i].TxIndex.Value)
+		assert.Equal(t, e.logIndex, logs[i].LogIndex.Value)
+		assert.Equal(t, []byte(e.txHash), logs[i].TxHash.Bytes())
+	}
+
+	// it stops at the limit with logs of patch transactions
+	logs, err = collectLogs(bm, sm, filters, 1, 2, 0)
+	assert.NoError(t, err)
+	assert.Len(t, logs, 1)
+	assert.Equal(t, []byte("patch1_1"), logs[0].TxHash.Bytes())
+}
+
+func TestLogsRequest_Compile(t *testing.T) {
+	r := &LogsRequest{
+		EventFilter: EventFilter{Signature: "TestEventA()"},
+		Filters: EventFilters{
+			{Signature: "TestEventB(int)"},
+		},
+	}
+	_, err := r.Compile()
+	assert.Error(t, err)
+
+	r.Signature = ""
+	filters, err := r.Compile()
+	assert.NoError(t, err)
+	assert.Len(t, filters, 1)
+}
+
+type testLogsBlockManager struct {
+	*testBlockManager
+	last int64
+}
+
+func (bm *testLogsBlockManager) GetLastBlock() (module.Block, error) {
+	return bm.GetBlockByHeight(bm.last)
+}
+
+type testLogsChain struct {
+	testChain
+}
+
+func (c *testLogsChain) MaxLogsRange() int64 {
+	return 10
+}
+
+func (c *testLogsChain) MaxLogsResults() int {
+	return 10
+}
+
+func (c *testLogsChain) MetricContext() context.Context {
+	return metric.DefaultMetricContext()
+}
+
+func TestGetLogs_WithMetric(t *testing.T) {
+	evA := newTestEventLog("cx01", "TestEventA()", nil, nil)
+	bm, sm := newTestChainData([][][]*testEventLog{
+		{{evA}},
+		{{evA}},
+	}, nil)
+	chain := &testLogsChain{testChain{
+		bm: &testLogsBlockManager{bm, 2},
+		sm: sm,
+		gs: &testGenesisStorage{height: 0},
+	}}
+
+	// metric of the server doesn't allow unknown methods
+	mtr := metric.NewJsonrpcMetric(metric.DefaultJsonrpcDurationsExpire, metric.DefaultJsonrpcDurationsSize, false)
+	mr := v3.MethodRepository(mtr)
+	mr.RegisterMethod("icx_getLogs", getLogs)
+
+	e := echo.New()
+	e.Validator = jsonrpc.NewValidator()
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	c := e.NewContext(req, httptest.NewRecorder())
+	c.Set("chain", chain)
+	c.Set("includeDebug", false)
+
+	raw := json.RawMessage(`{"jsonrpc":"2.0","method":"icx_getLogs","id":1,
+		"params":{"fromHeight":"0x0","event":"TestEventA()"}}`)
+	assert.NotPanics(t, func() {
+		status, resp := mr.HandleMessage(c, raw)
+		assert.Equal(t, http.StatusOK, status)
+		if res, ok := resp.(*jsonrpc.Response); assert.True(t, ok) {
+			assert.Nil(t, res.Error)
+			assert.Len(t, res.Result, 2)
+		}
+	})
+}
@@ -134,6 +134,11 @@ func (ctx *Context) IncludeDebug() bool {
 	return v && serverDebug
 }
 
+func (ctx *Context) StateOverride() bool {
+	enabled, _ := ctx.Get("stateOverride").(bool)
+	return enabled
+}
+
 func (ctx *Context) BatchLimit() int {
 	batchLimit, ok := ctx.Get("batchLimit").(int)
 	if !ok {
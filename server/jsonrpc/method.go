@@ -132,8 +132,19 @@ func (mr *MethodRepository) handle(ctx *Context, raw json.RawMessage) *Response
 }
 
 func (mr *MethodRepository) Handle(c echo.Context) error {
-	ctx := NewContext(c)
 	raw := c.Get("raw").(json.RawMessage)
+	status, resp := mr.HandleMessage(c, raw)
+	if resp == nil {
+		return c.NoContent(status)
+	}
+	return c.JSON(status, resp)
+}
+
+// HandleMessage handles the message of a request or batch requests. It
+// returns the HTTP status and the response. The response is nil if there
+// is nothing to respond.
+func (mr *MethodRepository) HandleMessage(c echo.Context, raw json.RawMessage) (int, interface{}) {
+	ctx := NewContext(c)
 	var raws []json.RawMessage
 	if err := json.Unmarshal(raw, &raws); err == nil {
 		n := len(raws)
@@ -143,7 +154,7 @@ func (mr *MethodRepository) Handle(c echo.Context) error {
 				Error:   ErrInvalidRequest(),
 			}
 			mr.mtr.OnHandle(ctx.MetricContext(), "", time.Now(), resp.Error)
-			return c.JSON(http.StatusBadRequest, resp)
+			return http.StatusBadRequest, resp
 		}
 		if n > ctx.BatchLimit() {
 			resp := &Response{
@@ -151,7 +162,7 @@ func (mr *MethodRepository) Handle(c echo.Context) error {
 				Error:   ErrInvalidRequest("too many request"),
 			}
 			mr.mtr.OnHandle(ctx.MetricContext(), "", time.Now(), resp.Error)
-			return c.JSON(http.StatusServiceUnavailable, resp)
+			return http.StatusServiceUnavailable, resp
 		}
 		var wg sync.WaitGroup
 		wg.Add(n)
@@ -170,17 +181,17 @@ func (mr *MethodRepository) Handle(c echo.Context) error {
 				resps = append(resps, r)
 			}
 		}
-		return c.JSON(http.StatusOK, resps)
+		return http.StatusOK, resps
 	} else {
 		resp := mr.handle(ctx, raw)
 		if resp != nil {
 			if resp.Error != nil {
-				return c.JSON(http.StatusBadRequest, resp)
+				return http.StatusBadRequest, resp
 			} else {
-				return c.JSON(http.StatusOK, resp)
+				return http.StatusOK, resp
 			}
 		} else {
-			return c.NoContent(http.StatusOK)
+			return http.StatusOK, nil
 		}
 	}
 }
@@ -14,6 +14,8 @@ var (
 	msRound      = stats.Int64("consensus_round", "round", stats.UnitDimensionless)
 	msHeightD    = stats.Int64("consensus_height_duration", "block_duration", stats.UnitMilliseconds)
 	msRoundD     = stats.Int64("consensus_round_duration", "block_duration", stats.UnitMilliseconds)
+	msProposeT   = stats.Int64("consensus_propose_timeout", "propose_timeout", stats.UnitMilliseconds)
+	msProposalL  = stats.Int64("consensus_proposal_latency", "proposal_latency", stats.UnitMilliseconds)
 	consensusMks = []tag.Key{}
 )
 
@@ -22,6 +24,8 @@ func RegisterConsensus() {
 	RegisterMetricView(msRound, view.LastValue(), consensusMks)
 	RegisterMetricView(msHeightD, view.LastValue(), consensusMks)
 	RegisterMetricView(msRoundD, view.LastValue(), consensusMks)
+	RegisterMetricView(msProposeT, view.LastValue(), consensusMks)
+	RegisterMetricView(msProposalL, view.LastValue(), consensusMks)
 }
 
 type ConsensusMetric struct {
@@ -44,6 +48,14 @@ func (m *ConsensusMetric) OnRound(round int32) {
 	stats.Record(m.ctx, msRound.M(int64(round)), msRoundD.M(int64(d/time.Millisecond)))
 }
 
+func (m *ConsensusMetric) OnProposeTimeout(d time.Duration) {
+	stats.Record(m.ctx, msProposeT.M(int64(d/time.Millisecond)))
+}
+
+func (m *ConsensusMetric) OnProposalLatency(d time.Duration) {
+	stats.Record(m.ctx, msProposalL.M(int64(d/time.Millisecond)))
+}
+
 func NewConsensusMetric(ctx context.Context) *ConsensusMetric {
 	return &ConsensusMetric{
 		ctx : ctx,
@@ -0,0 +1,66 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metric
+
+import (
+	"context"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+var (
+	msExecTx       = stats.Int64("txexec_parallel", "Transactions executed in parallel", stats.UnitDimensionless)
+	msReExecTx     = stats.Int64("txexec_reexecute", "Transactions re-executed for conflicts", stats.UnitDimensionless)
+	msConflictRate = stats.Int64("txexec_conflict_rate", "Conflict rate of parallel execution", stats.UnitDimensionless)
+	msConcurrency  = stats.Int64("txexec_concurrency", "Concurrency level of parallel execution", stats.UnitDimensionless)
+	executionMks   = []tag.Key{}
+)
+
+func RegisterExecution() {
+	RegisterMetricView(msExecTx, view.Sum(), executionMks)
+	RegisterMetricView(msReExecTx, view.Count(), executionMks)
+	RegisterMetricView(msReExecTx, view.Sum(), executionMks)
+	RegisterMetricView(msConflictRate, view.LastValue(), executionMks)
+	RegisterMetricView(msConcurrency, view.LastValue(), executionMks)
+}
+
+type ExecutionMetric struct {
+	ctx context.Context
+}
+
+// OnExecute records the result of parallel execution of a block.
+// Conflict rate is recorded in percent of the transactions.
+func (m *ExecutionMetric) OnExecute(level, txs, reExecuted int) {
+	var rate int64
+	if txs > 0 {
+		rate = int64(reExecuted) * 100 / int64(txs)
+	}
+	stats.Record(m.ctx,
+		msExecTx.M(int64(txs)),
+		msReExecTx.M(int64(reExecuted)),
+		msConflictRate.M(rate),
+		msConcurrency.M(int64(level)),
+	)
+}
+
+func NewExecutionMetric(ctx context.Context) *ExecutionMetric {
+	return &ExecutionMetric{
+		ctx: ctx,
+	}
+}
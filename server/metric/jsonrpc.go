@@ -25,6 +25,7 @@ var (
 	emptyMks = []tag.Key{}
 	msMap    = map[string]*measure{
 		"icx_getLastBlock":     msRetrieve,
+		"icx_getLowestBlock":   msRetrieve,
 		"icx_getBlockByHeight": msRetrieve,
 		"icx_getBlockByHash":   msRetrieve,
 		"icx_call": {
@@ -32,11 +33,13 @@ var (
 			stats.Int64("jsonrpc_call_avg", "moving average of jsonrpc icx_call method", "ns"),
 			emptyMks,
 		},
-		"icx_getBalance":           msRetrieve,
-		"icx_getScoreApi":          msRetrieve,
-		"icx_getTotalSupply":       msRetrieve,
-		"icx_getTransactionResult": msRetrieve,
-		"icx_getTransactionByHash": msRetrieve,
+		"icx_getBalance":               msRetrieve,
+		"icx_getScoreApi":              msRetrieve,
+		"icx_getTotalSupply":           msRetrieve,
+		"icx_getTransactionResult":     msRetrieve,
+		"icx_getTransactionByHash":     msRetrieve,
+		"icx_getTransactionStatus":     msRetrieve,
+		"icx_getTransactionsByAddress": msRetrieve,
 		"icx_sendTransaction": {
 			stats.Int64("jsonrpc_send_transaction", "jsonrpc icx_sendTransaction method", "ns"),
 			stats.Int64("jsonrpc_send_transaction_avg", "moving average of jsonrpc icx_sendTransaction methods", "ns"),
@@ -58,6 +61,7 @@ var (
 		"icx_getProofForResult":      msRetrieve,
 		"icx_getProofForEvents":      msRetrieve,
 		"icx_getScoreStatus":         msRetrieve,
+		"icx_getLogs":                msRetrieve,
 		"btp_getNetworkInfo":         msRetrieve,
 		"btp_getNetworkTypeInfo":     msRetrieve,
 		"btp_getMessages":            msRetrieve,
@@ -74,6 +78,17 @@ var (
 			stats.Int64("jsonrpc_estimate_step_avg", "moving average of jsonrpc debug_estimateStep method", "ns"),
 			emptyMks,
 		},
+		"debug_simulateTransaction": {
+			stats.Int64("jsonrpc_simulate_transaction", "jsonrpc debug_simulateTransaction method", "ns"),
+			stats.Int64("jsonrpc_simulate_transaction_avg", "moving average of jsonrpc debug_simulateTransaction method", "ns"),
+			emptyMks,
+		},
+		"debug_getIScoreBreakdown": {
+			stats.Int64("jsonrpc_get_iscore_breakdown", "jsonrpc debug_getIScoreBreakdown method", "ns"),
+			stats.Int64("jsonrpc_get_iscore_breakdown_avg", "moving average of jsonrpc debug_getIScoreBreakdown method", "ns"),
+			emptyMks,
+		},
+		"debug_getPendingTransactions": msRetrieve,
 		"rosetta_getTrace": {
 			stats.Int64("jsonrpc_rosetta_trace_", "jsonrpc rosetta_getTrace method", "ns"),
 			stats.Int64("jsonrpc_rosetta_trace_avg", "moving average of jsonrpc rosetta_getTTrace method", "ns"),
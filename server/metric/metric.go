@@ -188,6 +188,8 @@ func PrometheusExporter() *prometheus.Exporter {
 	RegisterConsensus()
 	RegisterNetwork()
 	RegisterTransaction()
+	RegisterExecution()
+	RegisterPruning()
 	RegisterJsonrpc()
 	return pe
 }
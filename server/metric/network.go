@@ -13,6 +13,8 @@ import (
 var (
 	msSend     = stats.Int64("network_send", "send", stats.UnitBytes)
 	msRecv     = stats.Int64("network_recv", "recv", stats.UnitBytes)
+	msSendWire = stats.Int64("network_send_wire", "send on wire", stats.UnitBytes)
+	msRecvWire = stats.Int64("network_recv_wire", "recv on wire", stats.UnitBytes)
 	mkDest     = NewMetricKey("dest")
 	mkProtocol = NewMetricKey("protocol")
 	networkMks = []tag.Key{mkDest, mkProtocol}
@@ -23,6 +25,8 @@ func RegisterNetwork() {
 	RegisterMetricView(msSend, view.Sum(), networkMks)
 	RegisterMetricView(msRecv, view.Count(), networkMks)
 	RegisterMetricView(msRecv, view.Sum(), networkMks)
+	RegisterMetricView(msSendWire, view.Sum(), networkMks)
+	RegisterMetricView(msRecvWire, view.Sum(), networkMks)
 }
 
 type NetworkMetric struct {
@@ -59,14 +63,16 @@ func (m *NetworkMetric) getMetricContext(dest byte, ttl byte, hint byte, protoco
 	return ctx
 }
 
-func (m *NetworkMetric) OnSend(dest byte, ttl byte, hint byte, protocol uint16, pktLen uint32) {
+// OnSend records length of the payload and length of the payload on wire,
+// which could be compressed.
+func (m *NetworkMetric) OnSend(dest byte, ttl byte, hint byte, protocol uint16, pktLen uint32, wireLen uint32) {
 	ctx := m.getMetricContext(dest, ttl, hint, protocol)
-	stats.Record(ctx, msSend.M(int64(pktLen)))
+	stats.Record(ctx, msSend.M(int64(pktLen)), msSendWire.M(int64(wireLen)))
 }
 
-func (m *NetworkMetric) OnRecv(dest byte, ttl byte, hint byte, protocol uint16, pktLen uint32) {
+func (m *NetworkMetric) OnRecv(dest byte, ttl byte, hint byte, protocol uint16, pktLen uint32, wireLen uint32) {
 	ctx := m.getMetricContext(dest, ttl, hint, protocol)
-	stats.Record(ctx, msRecv.M(int64(pktLen)))
+	stats.Record(ctx, msRecv.M(int64(pktLen)), msRecvWire.M(int64(wireLen)))
 }
 
 func NewNetworkMetric(ctx context.Context) *NetworkMetric {
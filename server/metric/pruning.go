@@ -0,0 +1,83 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metric
+
+import (
+	"context"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+var (
+	msPruningFrom     = stats.Int64("pruning_retained_from", "Lowest height of the retained states", stats.UnitDimensionless)
+	msPruningTo       = stats.Int64("pruning_retained_to", "Highest height of the retained states", stats.UnitDimensionless)
+	msPruningProgress = stats.Int64("pruning_progress", "Progress of the running pruning in percent", stats.UnitDimensionless)
+	msPruningEntries  = stats.Int64("pruning_reclaimed_entries", "Deleted entries by pruning", stats.UnitDimensionless)
+	msPruningBytes    = stats.Int64("pruning_reclaimed_bytes", "Reclaimed bytes by pruning", stats.UnitBytes)
+	msPruningD        = stats.Int64("pruning_duration", "Duration of the last pruning", stats.UnitMilliseconds)
+	pruningMks        = []tag.Key{}
+)
+
+func RegisterPruning() {
+	RegisterMetricView(msPruningFrom, view.LastValue(), pruningMks)
+	RegisterMetricView(msPruningTo, view.LastValue(), pruningMks)
+	RegisterMetricView(msPruningProgress, view.LastValue(), pruningMks)
+	RegisterMetricView(msPruningEntries, view.Sum(), pruningMks)
+	RegisterMetricView(msPruningBytes, view.Sum(), pruningMks)
+	RegisterMetricView(msPruningD, view.LastValue(), pruningMks)
+}
+
+type PruningMetric struct {
+	ctx context.Context
+}
+
+// OnStart records the range of heights of the states retained by
+// the pruning started now.
+func (m *PruningMetric) OnStart(from, to int64) {
+	stats.Record(m.ctx,
+		msPruningFrom.M(from),
+		msPruningTo.M(to),
+		msPruningProgress.M(0),
+	)
+}
+
+func (m *PruningMetric) OnProgress(percent int) {
+	stats.Record(m.ctx, msPruningProgress.M(int64(percent)))
+}
+
+func (m *PruningMetric) OnDelete(entries, bytes int) {
+	stats.Record(m.ctx,
+		msPruningEntries.M(int64(entries)),
+		msPruningBytes.M(int64(bytes)),
+	)
+}
+
+func (m *PruningMetric) OnFinish(d time.Duration) {
+	stats.Record(m.ctx,
+		msPruningProgress.M(100),
+		msPruningD.M(int64(d/time.Millisecond)),
+	)
+}
+
+func NewPruningMetric(ctx context.Context) *PruningMetric {
+	return &PruningMetric{
+		ctx: ctx,
+	}
+}
@@ -44,6 +44,19 @@ func ChainInjector(srv *Manager) echo.MiddlewareFunc {
 	}
 }
 
+// ConfigInjector sets the configuration of the server for JSON-RPC handlers.
+func ConfigInjector(srv *Manager) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			ctx.Set("includeDebug", srv.IncludeDebug())
+			ctx.Set("batchLimit", srv.BatchLimit())
+			ctx.Set("rosetta", srv.Rosetta())
+			ctx.Set("stateOverride", srv.StateOverride())
+			return next(ctx)
+		}
+	}
+}
+
 // Chunk()
 func Chunk() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
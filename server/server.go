@@ -27,6 +27,7 @@ type Config struct {
 	JSONRPCDump           bool
 	JSONRPCIncludeDebug   bool
 	JSONRPCRosetta        bool
+	JSONRPCStateOverride  bool
 	JSONRPCDefaultChannel string
 	JSONRPCBatchLimit     int
 	WSMaxSession          int
@@ -43,6 +44,7 @@ type Manager struct {
 	jsonrpcMessageDump    int32
 	jsonrpcRosetta        int32
 	jsonrpcIncludeDebug   int32
+	jsonrpcStateOverride  int32
 	jsonrpcBatchLimit     int32
 	logger                log.Logger
 	metricsHandler        echo.HandlerFunc
@@ -79,6 +81,7 @@ func NewManager(
 	m.SetMessageDump(config.JSONRPCDump)
 	m.SetIncludeDebug(config.JSONRPCIncludeDebug)
 	m.SetRosetta(config.JSONRPCRosetta)
+	m.SetStateOverride(config.JSONRPCStateOverride)
 	return m
 }
 
@@ -167,6 +170,14 @@ func (srv *Manager) Rosetta() bool {
 	return atomicLoad(&srv.jsonrpcRosetta)
 }
 
+func (srv *Manager) SetStateOverride(enable bool) {
+	atomicStore(&srv.jsonrpcStateOverride, enable)
+}
+
+func (srv *Manager) StateOverride() bool {
+	return atomicLoad(&srv.jsonrpcStateOverride)
+}
+
 func (srv *Manager) SetBatchLimit(limitOfBatch int) {
 	atomic.StoreInt32(&srv.jsonrpcBatchLimit, int32(limitOfBatch))
 }
@@ -206,17 +217,11 @@ func (srv *Manager) RegisterAPIHandler(g *echo.Group) {
 			srv.logger.Printf("response=%s", resBody)
 		}
 	}))
-	rpc.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
-		return func(ctx echo.Context) error {
-			ctx.Set("includeDebug", srv.IncludeDebug())
-			ctx.Set("batchLimit", srv.BatchLimit())
-			ctx.Set("rosetta", srv.Rosetta())
-			return next(ctx)
-		}
-	})
+	rpc.Use(ConfigInjector(srv))
 
 	// v3 APIs
 	mr := v3.MethodRepository(srv.mtr)
+	mr.RegisterMethod("icx_getLogs", getLogs)
 	v3api := rpc.Group("/v3")
 	v3api.Use(JsonRpc(), Chunk())
 	v3api.POST("", mr.Handle, ChainInjector(srv))
@@ -243,6 +248,8 @@ func (srv *Manager) RegisterAPIHandler(g *echo.Group) {
 	ws.GET("/v3/:channel/block", srv.wssm.RunBlockSession, ChainInjector(srv))
 	ws.GET("/v3/:channel/event", srv.wssm.RunEventSession, ChainInjector(srv))
 	ws.GET("/v3/:channel/btp", srv.wssm.RunBtpSession, ChainInjector(srv))
+	ws.GET("/v3/:channel/txpool", srv.wssm.RunTxPoolSession, ChainInjector(srv))
+	ws.GET("/v3/:channel/ws", srv.wssm.RunJSONRPCSession(mr), ChainInjector(srv), ConfigInjector(srv))
 }
 
 func (srv *Manager) RegisterMetricsHandler(g *echo.Group) {
@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"encoding/base64"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"math"
 	"strconv"
 	"time"
 	"unsafe"
@@ -33,6 +35,7 @@ func MethodRepository(mtr *metric.JsonrpcMetric) *jsonrpc.MethodRepository {
 	RegisterValidationRule(mr.Validator())
 
 	mr.RegisterMethod("icx_getLastBlock", getLastBlock)
+	mr.RegisterMethod("icx_getLowestBlock", getLowestBlock)
 	mr.RegisterMethod("icx_getBlockByHeight", getBlockByHeight)
 	mr.RegisterMethod("icx_getBlockByHash", getBlockByHash)
 	mr.RegisterMethod("icx_call", call)
@@ -41,6 +44,8 @@ func MethodRepository(mtr *metric.JsonrpcMetric) *jsonrpc.MethodRepository {
 	mr.RegisterMethod("icx_getTotalSupply", getTotalSupply)
 	mr.RegisterMethod("icx_getTransactionResult", getTransactionResult)
 	mr.RegisterMethod("icx_getTransactionByHash", getTransactionByHash)
+	mr.RegisterMethod("icx_getTransactionsByAddress", getTransactionsByAddress)
+	mr.RegisterMethod("icx_getTransactionStatus", getTransactionStatus)
 	mr.RegisterMethod("icx_sendTransaction", sendTransaction)
 	mr.RegisterMethod("icx_sendTransactionAndWait", sendTransactionAndWait)
 	mr.RegisterMethod("icx_waitTransactionResult", waitTransactionResult)
@@ -85,18 +90,62 @@ func fillTransactions(blockJson interface{}, b module.Block, v module.JSONVersio
 	return nil
 }
 
-func checkBaseHeight(c module.Chain, height int64) error {
+// LowestHeightOf returns the lowest height of the blocks whose bodies and
+// results are available in the chain.
+func LowestHeightOf(c module.Chain) int64 {
+	base := c.GenesisStorage().Height()
+	if dbase := c.Database(); dbase != nil {
+		if lowest := block.GetLowestHeightOf(dbase); lowest > base {
+			return lowest
+		}
+	}
+	return base
+}
+
+func checkHeight(height, base int64, debug bool) error {
 	if height < 0 {
-		return errors.NotFoundError.Errorf("NegativeHeight(height=%d)", height)
+		err := errors.NotFoundError.Errorf("NegativeHeight(height=%d)", height)
+		return jsonrpc.ErrorCodeNotFound.Wrap(err, debug)
 	}
-	base := c.GenesisStorage().Height()
 	if height < base {
-		return errors.NotFoundError.Errorf(
+		err := block.PrunedError.Errorf(
 			"PrunedBlock(height=%d,base=%d)", height, base)
+		return jsonrpc.ErrorCodePruned.Wrap(err, debug)
 	}
 	return nil
 }
 
+// checkBaseHeight returns an error if the body and the result of the block
+// at the height are not available.
+func checkBaseHeight(c module.Chain, height int64, debug bool) error {
+	return checkHeight(height, LowestHeightOf(c), debug)
+}
+
+// LowestStateHeightOf returns the lowest height of the blocks whose world
+// states are available in the chain.
+func LowestStateHeightOf(c module.Chain) int64 {
+	lowest := LowestHeightOf(c)
+	if dbase := c.Database(); dbase != nil {
+		if height := block.GetLowestStateHeightOf(dbase); height > lowest {
+			return height
+		}
+	}
+	return lowest
+}
+
+// checkStateHeight returns an error if the world state of the block at the
+// height is not available.
+func checkStateHeight(c module.Chain, height int64, debug bool) error {
+	return checkHeight(height, LowestStateHeightOf(c), debug)
+}
+
+// checkHeaderHeight returns an error if the header and the votes of the
+// block at the height are not available. They are retained even though
+// the body of the block is pruned by the history retention.
+func checkHeaderHeight(c module.Chain, height int64, debug bool) error {
+	return checkHeight(height, c.GenesisStorage().Height(), debug)
+}
+
 func getLastBlock(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
 	debug := ctx.IncludeDebug()
 	var param struct{}
@@ -130,6 +179,39 @@ func getLastBlock(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, er
 	return blockJson, nil
 }
 
+func getLowestBlock(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
+	debug := ctx.IncludeDebug()
+	var param struct{}
+	if err := params.Convert(&param); err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, debug)
+	}
+
+	chain, err := ctx.Chain()
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeServer.Wrap(err, debug)
+	}
+
+	bm := chain.BlockManager()
+	if bm == nil {
+		return nil, jsonrpc.ErrorCodeServer.New("Stopped")
+	}
+
+	block, err := bm.GetBlockByHeight(LowestHeightOf(chain))
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, debug)
+	}
+
+	blockJson, err := block.ToJSON(module.JSONVersion3)
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, debug)
+	}
+
+	if err := fillTransactions(blockJson, block, module.JSONVersion3); err != nil {
+		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, debug)
+	}
+	return blockJson, nil
+}
+
 func getBlockByHeight(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
 	debug := ctx.IncludeDebug()
 
@@ -147,8 +229,8 @@ func getBlockByHeight(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}
 		return nil, jsonrpc.ErrorCodeServer.Wrap(err, debug)
 	}
 
-	if err := checkBaseHeight(chain, height); err != nil {
-		return nil, jsonrpc.ErrorCodeNotFound.Wrap(err, debug)
+	if err := checkBaseHeight(chain, height, debug); err != nil {
+		return nil, err
 	}
 
 	bm := chain.BlockManager()
@@ -198,8 +280,8 @@ func getBlockByHash(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{},
 	} else if err != nil {
 		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, debug)
 	}
-	if err := checkBaseHeight(chain, block.Height()); err != nil {
-		return nil, jsonrpc.ErrorCodeNotFound.Wrap(err, debug)
+	if err := checkBaseHeight(chain, block.Height(), debug); err != nil {
+		return nil, err
 	}
 
 	blockJson, err := block.ToJSON(module.JSONVersion3)
@@ -220,6 +302,9 @@ func call(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
 	if err := params.Convert(&param); err != nil {
 		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, debug)
 	}
+	if param.StateOverride != nil && !ctx.StateOverride() {
+		return nil, jsonrpc.ErrorCodeInvalidParams.New("StateOverrideDisabled")
+	}
 
 	chain, err := ctx.Chain()
 	if err != nil {
@@ -232,12 +317,9 @@ func call(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
 		return nil, jsonrpc.ErrorCodeServer.New("Stopped")
 	}
 
-	block, err := getBlock(chain, bm, param.Height)
+	block, err := getBlock(chain, bm, param.Height, debug)
 	if err != nil {
-		if errors.NotFoundError.Equals(err) {
-			return nil, jsonrpc.ErrorCodeNotFound.Wrap(err, debug)
-		}
-		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, debug)
+		return nil, err
 	}
 	bi := common.NewBlockInfo(block.Height(), block.Timestamp())
 	result, err := sm.Call(block.Result(), block.NextValidators(), params.RawMessage(), bi)
@@ -254,17 +336,28 @@ func call(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
 	}
 }
 
-func getBlock(chain module.Chain, bm module.BlockManager, height jsonrpc.HexInt) (block module.Block, err error) {
+// getBlock returns the block at the height or the last block if the height
+// is not specified. It's used for the queries on the world state, so it
+// fails if the state of the block is pruned. Returned error is
+// *jsonrpc.Error.
+func getBlock(chain module.Chain, bm module.BlockManager, height jsonrpc.HexInt, debug bool) (module.Block, error) {
+	var blk module.Block
+	var err error
 	if height == "" {
-		block, err = bm.GetLastBlock()
+		blk, err = bm.GetLastBlock()
 	} else {
 		h, _ := height.Int64()
-		if err := checkBaseHeight(chain, h); err != nil {
+		if err := checkStateHeight(chain, h, debug); err != nil {
 			return nil, err
 		}
-		block, err = bm.GetBlockByHeight(h)
+		blk, err = bm.GetBlockByHeight(h)
+	}
+	if errors.NotFoundError.Equals(err) {
+		return nil, jsonrpc.ErrorCodeNotFound.Wrap(err, debug)
+	} else if err != nil {
+		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, debug)
 	}
-	return
+	return blk, nil
 }
 
 func getBalance(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
@@ -286,9 +379,9 @@ func getBalance(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, erro
 	}
 
 	var balance common.HexInt
-	block, err := getBlock(chain, bm, param.Height)
+	block, err := getBlock(chain, bm, param.Height, debug)
 	if err != nil {
-		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, debug)
+		return nil, err
 	}
 	b, err := sm.GetBalance(block.Result(), param.Address.Address())
 	if err != nil {
@@ -313,9 +406,9 @@ func getScoreApi(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, err
 	if bm == nil || sm == nil {
 		return nil, jsonrpc.ErrorCodeServer.New("Stopped")
 	}
-	b, err := getBlock(chain, bm, param.Height)
+	b, err := getBlock(chain, bm, param.Height, debug)
 	if err != nil {
-		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, debug)
+		return nil, err
 	}
 	info, err := sm.GetAPIInfo(b.Result(), param.Address.Address())
 	if service.NoActiveContractError.Equals(err) {
@@ -353,9 +446,9 @@ func getTotalSupply(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{},
 		return nil, jsonrpc.ErrorCodeServer.New("Stopped")
 	}
 
-	b, err := getBlock(chain, bm, height)
+	b, err := getBlock(chain, bm, height, debug)
 	if err != nil {
-		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, debug)
+		return nil, err
 	}
 
 	var tsValue common.HexInt
@@ -398,8 +491,8 @@ func getTransactionResult(ctx *jsonrpc.Context, params *jsonrpc.Params) (interfa
 	}
 
 	blk := txInfo.Block()
-	if err := checkBaseHeight(chain, blk.Height()); err != nil {
-		return nil, jsonrpc.ErrorCodeNotFound.Wrap(err, debug)
+	if err := checkBaseHeight(chain, blk.Height(), debug); err != nil {
+		return nil, err
 	}
 	receipt, err := txInfo.GetReceipt()
 	if block.ResultNotFinalizedError.Equals(err) {
@@ -446,6 +539,10 @@ func getTransactionByHash(ctx *jsonrpc.Context, params *jsonrpc.Params) (interfa
 		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, debug)
 	}
 
+	blk := txInfo.Block()
+	if err := checkBaseHeight(chain, blk.Height(), debug); err != nil {
+		return nil, err
+	}
 	tx, err := txInfo.Transaction()
 	if err != nil {
 		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, debug)
@@ -455,7 +552,6 @@ func getTransactionByHash(ctx *jsonrpc.Context, params *jsonrpc.Params) (interfa
 		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, debug)
 	}
 
-	blk := txInfo.Block()
 	result := res.(map[string]interface{})
 	result["blockHash"] = "0x" + hex.EncodeToString(blk.ID())
 	result["blockHeight"] = "0x" + strconv.FormatInt(int64(blk.Height()), 16)
@@ -464,6 +560,125 @@ func getTransactionByHash(ctx *jsonrpc.Context, params *jsonrpc.Params) (interfa
 	return result, nil
 }
 
+const (
+	defaultTransactionsByAddressLimit = 20
+	maxTransactionsByAddressLimit     = 100
+)
+
+func getTransactionsByAddress(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
+	debug := ctx.IncludeDebug()
+
+	var param TransactionsByAddressParam
+	if err := params.Convert(&param); err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, debug)
+	}
+
+	chain, err := ctx.Chain()
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeServer.Wrap(err, debug)
+	}
+	idx := chain.AddressIndex()
+	if idx == nil {
+		return nil, jsonrpc.ErrorCodeServer.New("AddressIndexDisabled")
+	}
+
+	from, to := int64(0), int64(math.MaxInt64)
+	if param.FromHeight != "" {
+		from = param.FromHeight.Value()
+	}
+	if param.ToHeight != "" {
+		to = param.ToHeight.Value()
+	}
+	if from < 0 || to < from {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Errorf(
+			"InvalidHeightRange(from=%d,to=%d)", from, to)
+	}
+	cursor := int64(-1)
+	if param.Cursor != "" {
+		cursor = param.Cursor.Value()
+		if cursor < 0 {
+			return nil, jsonrpc.ErrorCodeInvalidParams.Errorf(
+				"InvalidCursor(cursor=%d)", cursor)
+		}
+	}
+	limit := defaultTransactionsByAddressLimit
+	if param.Limit != "" {
+		limit = int(param.Limit.Value())
+		if limit <= 0 || limit > maxTransactionsByAddressLimit {
+			return nil, jsonrpc.ErrorCodeInvalidParams.Errorf(
+				"InvalidLimit(limit=%d,max=%d)", limit, maxTransactionsByAddressLimit)
+		}
+	}
+
+	entries, next, err := idx.Get(param.Address.Address(), from, to, cursor, limit)
+	if errors.NotFoundError.Equals(err) {
+		return nil, jsonrpc.ErrorCodeNotFound.Wrap(err, debug)
+	} else if err != nil {
+		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, debug)
+	}
+
+	txs := make([]interface{}, len(entries))
+	for i, e := range entries {
+		txs[i] = map[string]interface{}{
+			"txHash":      "0x" + hex.EncodeToString(e.TxHash),
+			"blockHeight": "0x" + strconv.FormatInt(e.Height, 16),
+		}
+	}
+	result := map[string]interface{}{
+		"address":      param.Address,
+		"transactions": txs,
+	}
+	if next >= 0 {
+		result["next"] = "0x" + strconv.FormatInt(next, 16)
+	}
+	return result, nil
+}
+
+func getTransactionStatus(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
+	debug := ctx.IncludeDebug()
+
+	var param TransactionHashParam
+	if err := params.Convert(&param); err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, debug)
+	}
+
+	chain, err := ctx.Chain()
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeServer.Wrap(err, debug)
+	}
+
+	bm := chain.BlockManager()
+	sm := chain.ServiceManager()
+	if bm == nil || sm == nil {
+		return nil, jsonrpc.ErrorCodeServer.New("Stopped")
+	}
+
+	txInfo, err := bm.GetTransactionInfo(param.Hash.Bytes())
+	if err == nil {
+		blk := txInfo.Block()
+		return map[string]interface{}{
+			"status":      module.TransactionStatusCommitted.String(),
+			"blockHash":   "0x" + hex.EncodeToString(blk.ID()),
+			"blockHeight": "0x" + strconv.FormatInt(blk.Height(), 16),
+			"txIndex":     "0x" + strconv.FormatInt(int64(txInfo.Index()), 16),
+		}, nil
+	} else if !errors.NotFoundError.Equals(err) {
+		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, debug)
+	}
+
+	status, reason := sm.GetTransactionStatus(param.Hash.Bytes())
+	if status == module.TransactionStatusUnknown {
+		return nil, jsonrpc.ErrorCodeNotFound.Wrap(err, debug)
+	}
+	result := map[string]interface{}{
+		"status": status.String(),
+	}
+	if failure := TxFailureOf(reason); failure != nil {
+		result["failure"] = failure
+	}
+	return result, nil
+}
+
 func sendTransaction(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
 	debug := ctx.IncludeDebug()
 
@@ -555,8 +770,8 @@ func getBlockHeaderByHeight(ctx *jsonrpc.Context, params *jsonrpc.Params) (inter
 		return nil, jsonrpc.ErrorCodeServer.Wrap(err, debug)
 	}
 
-	if err := checkBaseHeight(chain, height); err != nil {
-		return nil, jsonrpc.ErrorCodeNotFound.Wrap(err, debug)
+	if err := checkHeaderHeight(chain, height, debug); err != nil {
+		return nil, err
 	}
 
 	bm := chain.BlockManager()
@@ -596,8 +811,8 @@ func getVotesByHeight(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}
 		return nil, jsonrpc.ErrorCodeServer.Wrap(err, debug)
 	}
 
-	if err := checkBaseHeight(chain, height); err != nil {
-		return nil, jsonrpc.ErrorCodeNotFound.Wrap(err, debug)
+	if err := checkHeaderHeight(chain, height, debug); err != nil {
+		return nil, err
 	}
 
 	cs := chain.Consensus()
@@ -643,8 +858,8 @@ func getProofForResult(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{
 	} else if err != nil {
 		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, debug)
 	}
-	if err := checkBaseHeight(chain, block.Height()); err != nil {
-		return nil, jsonrpc.ErrorCodeNotFound.Wrap(err, debug)
+	if err := checkBaseHeight(chain, block.Height(), debug); err != nil {
+		return nil, err
 	}
 
 	blockResult := block.Result()
@@ -696,8 +911,8 @@ func getProofForEvents(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{
 	} else if err != nil {
 		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, debug)
 	}
-	if err := checkBaseHeight(chain, block.Height()); err != nil {
-		return nil, jsonrpc.ErrorCodeNotFound.Wrap(err, debug)
+	if err := checkBaseHeight(chain, block.Height(), debug); err != nil {
+		return nil, err
 	}
 
 	blockResult := block.Result()
@@ -751,12 +966,9 @@ func getScoreStatus(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{},
 	if bm == nil || sm == nil {
 		return nil, jsonrpc.ErrorCodeServer.New("Stopped")
 	}
-	b, err := getBlock(chain, bm, param.Height)
+	b, err := getBlock(chain, bm, param.Height, debug)
 	if err != nil {
-		if errors.NotFoundError.Equals(err) {
-			return nil, jsonrpc.ErrorCodeNotFound.Wrap(err, debug)
-		}
-		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, debug)
+		return nil, err
 	}
 	s, err := sm.GetSCOREStatus(b.Result(), param.Address.Address())
 	if err != nil {
@@ -780,8 +992,7 @@ func getBTPNetworkInfo(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{
 		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, debug)
 	}
 
-	height, err := param.Height.Int64()
-	if err != nil {
+	if _, err := param.Height.Int64(); err != nil {
 		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, debug)
 	}
 	nid, err := param.Id.Int64()
@@ -799,13 +1010,9 @@ func getBTPNetworkInfo(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{
 	if bm == nil || sm == nil {
 		return nil, jsonrpc.ErrorCodeServer.New("Stopped")
 	}
-	block, err := getBlock(chain, bm, param.Height)
-	if errors.NotFoundError.Equals(err) {
-		err = errors.NotFoundError.Wrapf(err,
-			"fail to get a block for height=%d", height)
-		return nil, jsonrpc.ErrorCodeNotFound.Wrap(err, debug)
-	} else if err != nil {
-		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, debug)
+	block, err := getBlock(chain, bm, param.Height, debug)
+	if err != nil {
+		return nil, err
 	}
 
 	blockResult := block.Result()
@@ -831,8 +1038,7 @@ func getBTPNetworkTypeInfo(ctx *jsonrpc.Context, params *jsonrpc.Params) (interf
 		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, debug)
 	}
 
-	height, err := param.Height.Int64()
-	if err != nil {
+	if _, err := param.Height.Int64(); err != nil {
 		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, debug)
 	}
 	ntid, err := param.Id.Int64()
@@ -850,12 +1056,9 @@ func getBTPNetworkTypeInfo(ctx *jsonrpc.Context, params *jsonrpc.Params) (interf
 	if bm == nil || sm == nil {
 		return nil, jsonrpc.ErrorCodeServer.New("Stopped")
 	}
-	block, err := getBlock(chain, bm, param.Height)
-	if errors.NotFoundError.Equals(err) {
-		err = errors.NotFoundError.Wrapf(err, "fail to get a block for height=%d", height)
-		return nil, jsonrpc.ErrorCodeNotFound.Wrap(err, debug)
-	} else if err != nil {
-		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, debug)
+	block, err := getBlock(chain, bm, param.Height, debug)
+	if err != nil {
+		return nil, err
 	}
 
 	blockResult := block.Result()
@@ -896,8 +1099,8 @@ func getBTPMessages(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{},
 		return nil, jsonrpc.ErrorCodeServer.New("Stopped")
 	}
 
-	if err := checkBaseHeight(chain, height); err != nil {
-		return nil, jsonrpc.ErrorCodeNotFound.Wrap(err, debug)
+	if err := checkBaseHeight(chain, height, debug); err != nil {
+		return nil, err
 	}
 	block, err := bm.GetBlockByHeight(height)
 	if errors.NotFoundError.Equals(err) {
@@ -976,8 +1179,8 @@ func getBTPHeader(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, er
 		return nil, jsonrpc.ErrorCodeServer.New("Stopped")
 	}
 
-	if err := checkBaseHeight(chain, height); err != nil {
-		return nil, jsonrpc.ErrorCodeNotFound.Wrap(err, debug)
+	if err := checkBaseHeight(chain, height, debug); err != nil {
+		return nil, err
 	}
 	block, err := bm.GetBlockByHeight(height)
 	if errors.NotFoundError.Equals(err) {
@@ -1023,8 +1226,8 @@ func getBTPProof(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, err
 		return nil, jsonrpc.ErrorCodeServer.New("Stopped")
 	}
 
-	if err := checkBaseHeight(chain, height); err != nil {
-		return nil, jsonrpc.ErrorCodeNotFound.Wrap(err, debug)
+	if err := checkBaseHeight(chain, height, debug); err != nil {
+		return nil, err
 	}
 	block, err := bm.GetBlockByHeight(height)
 	if errors.NotFoundError.Equals(err) {
@@ -1236,8 +1439,8 @@ func waitTransactionResultOnChannel(ctx *jsonrpc.Context, chain module.Chain, bm
 	}
 
 	blk := txInfo.Block()
-	if err := checkBaseHeight(chain, blk.Height()); err != nil {
-		return nil, jsonrpc.ErrorCodeNotFound.Wrap(err, debug)
+	if err := checkBaseHeight(chain, blk.Height(), debug); err != nil {
+		return nil, err
 	}
 	res, err := receipt.ToJSON(module.JSONVersion3)
 	if err != nil {
@@ -1258,18 +1461,90 @@ func DebugMethodRepository(mtr *metric.JsonrpcMetric) *jsonrpc.MethodRepository
 
 	mr.RegisterMethod("debug_getTrace", getTrace)
 	mr.RegisterMethod("debug_estimateStep", estimateStep)
+	mr.RegisterMethod("debug_simulateTransaction", simulateTransaction)
+	mr.RegisterMethod("debug_getIScoreBreakdown", getIScoreBreakdown)
+	mr.RegisterMethod("debug_getPendingTransactions", getPendingTransactions)
 
 	return mr
 }
 
+const (
+	defaultPendingTransactionsLimit = 100
+	maxPendingTransactionsLimit     = 1000
+)
+
+func getPendingTransactions(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
+	debug := ctx.IncludeDebug()
+
+	var param *PendingTransactionsParam
+	if err := params.Convert(&param); err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, debug)
+	}
+
+	chain, err := ctx.Chain()
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeServer.Wrap(err, debug)
+	}
+	sm := chain.ServiceManager()
+	if sm == nil {
+		return nil, jsonrpc.ErrorCodeServer.New("Stopped")
+	}
+
+	var from module.Address
+	limit := defaultPendingTransactionsLimit
+	if param != nil {
+		if param.FromAddress != "" {
+			from = param.FromAddress.Address()
+		}
+		if param.Limit != "" {
+			limit = int(param.Limit.Value())
+			if limit <= 0 || limit > maxPendingTransactionsLimit {
+				return nil, jsonrpc.ErrorCodeInvalidParams.Errorf(
+					"InvalidLimit(limit=%d,max=%d)", limit, maxPendingTransactionsLimit)
+			}
+		}
+	}
+
+	ptxs := sm.GetPendingTransactions(from, limit)
+	txs := make([]interface{}, 0, len(ptxs))
+	for _, ptx := range ptxs {
+		res, err := ptx.Transaction.ToJSON(module.JSONVersion3)
+		if err != nil {
+			return nil, jsonrpc.ErrorCodeSystem.Wrap(err, debug)
+		}
+		jso, ok := res.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if ptx.Received != 0 {
+			jso["receivedAt"] = "0x" + strconv.FormatInt(ptx.Received/int64(time.Microsecond), 16)
+		}
+		if failure := TxFailureOf(ptx.Error); failure != nil {
+			jso["failure"] = failure
+		}
+		txs = append(txs, jso)
+	}
+	return map[string]interface{}{
+		"transactions": txs,
+	}, nil
+}
+
 func getTrace(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
 	debug := ctx.IncludeDebug()
 
-	var param TransactionHashParam
+	var param TraceParam
 	if err := params.Convert(&param); err != nil {
 		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, debug)
 	}
 
+	var tracer trace.Tracer
+	if len(param.Tracer) > 0 {
+		var err error
+		if tracer, err = trace.NewTracer(param.Tracer); err != nil {
+			return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, debug)
+		}
+	}
+
 	chain, err := ctx.Chain()
 	if err != nil {
 		return nil, jsonrpc.ErrorCodeServer.Wrap(err, debug)
@@ -1281,36 +1556,70 @@ func getTrace(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error)
 		return nil, jsonrpc.ErrorCodeServer.New("Stopped")
 	}
 
-	txInfo, err := bm.GetTransactionInfo(param.Hash.Bytes())
-	if errors.NotFoundError.Equals(err) {
-		if sm.HasTransaction(param.Hash.Bytes()) {
-			return nil, jsonrpc.ErrorCodePending.New("Pending")
-		}
-		return nil, jsonrpc.ErrorCodeNotFound.Wrap(err, debug)
-	} else if err != nil {
-		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, debug)
+	ti := module.TraceInfo{
+		TraceMode: module.TraceModeInvoke,
 	}
+	var blk module.Block
+	timeout := time.Second * 5
+	if len(param.TxHash) > 0 {
+		txInfo, err := bm.GetTransactionInfo(param.TxHash.Bytes())
+		if errors.NotFoundError.Equals(err) {
+			if sm.HasTransaction(param.TxHash.Bytes()) {
+				return nil, jsonrpc.ErrorCodePending.New("Pending")
+			}
+			return nil, jsonrpc.ErrorCodeNotFound.Wrap(err, debug)
+		} else if err != nil {
+			return nil, jsonrpc.ErrorCodeSystem.Wrap(err, debug)
+		}
 
-	if txInfo.Group() == module.TransactionGroupPatch {
-		return nil, jsonrpc.ErrorCodeInvalidParams.New("Patch transaction can't be replayed")
-	}
+		if txInfo.Group() == module.TransactionGroupPatch {
+			return nil, jsonrpc.ErrorCodeInvalidParams.New("Patch transaction can't be replayed")
+		}
 
-	blk := txInfo.Block()
-	if err = checkBaseHeight(chain, blk.Height()); err != nil {
-		return nil, jsonrpc.ErrorCodeNotFound.Wrap(err, debug)
-	}
-	_, err = txInfo.GetReceipt()
-	if block.ResultNotFinalizedError.Equals(err) {
-		return nil, jsonrpc.ErrorCodeExecuting.New("Executing")
-	} else if err != nil {
-		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, debug)
+		blk = txInfo.Block()
+		if err = checkStateHeight(chain, blk.Height(), debug); err != nil {
+			return nil, err
+		}
+		_, err = txInfo.GetReceipt()
+		if block.ResultNotFinalizedError.Equals(err) {
+			return nil, jsonrpc.ErrorCodeExecuting.New("Executing")
+		} else if err != nil {
+			return nil, jsonrpc.ErrorCodeSystem.Wrap(err, debug)
+		}
+		ti.Range = module.TraceRangeTransaction
+		ti.Group = txInfo.Group()
+		ti.Index = txInfo.Index()
+	} else {
+		if len(param.Block) == 0 && len(param.Height) == 0 {
+			return nil, jsonrpc.ErrorCodeInvalidParams.New("NoTarget")
+		}
+		if tracer == nil {
+			return nil, jsonrpc.ErrorCodeInvalidParams.New("NoTracerForBlock")
+		}
+		if len(param.Block) > 0 {
+			blk, err = bm.GetBlock(param.Block.Bytes())
+		} else {
+			blk, err = bm.GetBlockByHeight(param.Height.Value())
+		}
+		if errors.NotFoundError.Equals(err) {
+			return nil, jsonrpc.ErrorCodeNotFound.Wrap(err, debug)
+		} else if err != nil {
+			return nil, jsonrpc.ErrorCodeSystem.Wrap(err, debug)
+		}
+		if err = checkStateHeight(chain, blk.Height(), debug); err != nil {
+			return nil, err
+		}
+		ti.Range = module.TraceRangeBlock
+		timeout = time.Second * 60
 	}
 	csi, err := bm.NewConsensusInfo(blk)
 	if err != nil {
 		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, debug)
 	}
 	nblk, err := bm.GetBlockByHeight(blk.Height() + 1)
-	if err != nil {
+	if errors.NotFoundError.Equals(err) {
+		return nil, jsonrpc.ErrorCodeExecuting.New("Executing")
+	} else if err != nil {
 		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, debug)
 	}
 	tr1, err := sm.CreateInitialTransition(blk.Result(), blk.NextValidators())
@@ -1326,26 +1635,21 @@ func getTrace(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error)
 	cb := &traceCallback{
 		logs:    make([]interface{}, 0, 100),
 		channel: make(chan interface{}, 10),
+		tracer:  tracer,
 	}
-	ti := module.TraceInfo{
-		TraceMode: module.TraceModeInvoke,
-		Range:     module.TraceRangeTransaction,
-		Group:     txInfo.Group(),
-		Index:     txInfo.Index(),
-		Callback:  cb,
-	}
+	ti.Callback = cb
 	canceller, err := tr2.ExecuteForTrace(ti)
 	if err != nil {
 		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, debug)
 	}
 
-	timer := time.After(time.Second * 5)
+	timer := time.After(timeout)
 	for {
 		select {
 		case <-timer:
 			canceller()
 			return nil, jsonrpc.ErrorCodeSystemTimeout.Errorf(
-				"Not enough time to get result of %x", param.Hash.Bytes())
+				"Not enough time to get result of %+v", param)
 		case <-cb.channel:
 			return cb.invokeTraceToJSON(), nil
 		}
@@ -1365,6 +1669,9 @@ func estimateStep(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, er
 	if err := params.Convert(&param); err != nil {
 		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, debug)
 	}
+	if param.StateOverride != nil && !ctx.StateOverride() {
+		return nil, jsonrpc.ErrorCodeInvalidParams.New("StateOverrideDisabled")
+	}
 
 	bm := chain.BlockManager()
 	sm := chain.ServiceManager()
@@ -1409,6 +1716,83 @@ func estimateStep(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, er
 	return steps, nil
 }
 
+func simulateTransaction(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
+	debug := ctx.IncludeDebug()
+
+	chain, err := ctx.Chain()
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeServer.Wrap(err, debug)
+	}
+
+	var param TransactionParamForSimulate
+	if err := params.Convert(&param); err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, debug)
+	}
+	if param.StateOverride != nil && !ctx.StateOverride() {
+		return nil, jsonrpc.ErrorCodeInvalidParams.New("StateOverrideDisabled")
+	}
+
+	bm := chain.BlockManager()
+	sm := chain.ServiceManager()
+	if bm == nil || sm == nil {
+		return nil, jsonrpc.ErrorCodeServer.New("ChannelStopped")
+	}
+
+	// height is not a part of the transaction
+	var jso map[string]json.RawMessage
+	if err := json.Unmarshal(params.RawMessage(), &jso); err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, debug)
+	}
+	delete(jso, "height")
+	js, err := json.Marshal(jso)
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, debug)
+	}
+
+	var bi module.BlockInfo
+	blk, err := getBlock(chain, bm, param.Height, debug)
+	if err != nil {
+		return nil, err
+	}
+	if param.Height != "" {
+		bi = common.NewBlockInfo(blk.Height(), blk.Timestamp())
+	} else {
+		// new block information based on the last
+		oldTS := blk.Timestamp()
+		newTS := common.UnixMicroFromTime(time.Now())
+		if newTS <= oldTS {
+			newTS = oldTS + 1
+		}
+		bi = common.NewBlockInfo(blk.Height()+1, newTS)
+	}
+
+	cb := &traceCallback{
+		channel: make(chan interface{}, 1),
+		bt:      trace.NewBalanceTracer(1, nil),
+	}
+	rct, err := sm.SimulateTransaction(
+		blk.Result(),
+		blk.NextValidators().Hash(),
+		js,
+		bi,
+		cb,
+	)
+	if err != nil {
+		if scoreresult.InvalidParameterError.Equals(err) {
+			return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, debug)
+		}
+		return nil, jsonrpc.ErrorCodeServer.Wrap(err, debug)
+	}
+	rctJson, err := rct.ToJSON(module.JSONVersion3)
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, debug)
+	}
+	return map[string]interface{}{
+		"receipt":        rctJson,
+		"balanceChanges": cb.bt.ToJSON(bi.Height()),
+	}, nil
+}
+
 const CIDForMainNet = 0x1
 
 func getTraceForRosetta(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
@@ -1614,3 +1998,41 @@ func RosettaMethodRepository(mtr *metric.JsonrpcMetric) *jsonrpc.MethodRepositor
 
 	return mr
 }
+
+func getIScoreBreakdown(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
+	debug := ctx.IncludeDebug()
+
+	chain, err := ctx.Chain()
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeServer.Wrap(err, debug)
+	}
+
+	var param AddressParam
+	if err := params.Convert(&param); err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, debug)
+	}
+
+	bm := chain.BlockManager()
+	sm := chain.ServiceManager()
+	if bm == nil || sm == nil {
+		return nil, jsonrpc.ErrorCodeServer.New("ChannelStopped")
+	}
+
+	blk, err := getBlock(chain, bm, param.Height, debug)
+	if err != nil {
+		return nil, err
+	}
+
+	// the calculation stops when the request is cancelled
+	result, err := sm.GetRewardDetail(ctx.Request().Context(), blk.Result(), param.Address.Address())
+	if err != nil {
+		if errors.UnsupportedError.Equals(err) {
+			return nil, jsonrpc.ErrorCodeMethodNotFound.Wrap(err, debug)
+		} else if errors.NotFoundError.Equals(err) {
+			return nil, jsonrpc.ErrorCodeNotFound.Wrap(err, debug)
+		} else {
+			return nil, jsonrpc.ErrorCodeSystem.Wrap(err, debug)
+		}
+	}
+	return result, nil
+}
@@ -1,6 +1,7 @@
 package v3
 
 import (
+	"github.com/icon-project/goloop/common/errors"
 	"github.com/icon-project/goloop/common/intconv"
 	"github.com/icon-project/goloop/server/jsonrpc"
 )
@@ -31,6 +32,8 @@ type CallParam struct {
 	DataType    string          `json:"dataType" validate:"required,call"`
 	Data        interface{}     `json:"data"`
 	Height      jsonrpc.HexInt  `json:"height,omitempty" validate:"optional,t_int"`
+
+	StateOverride interface{} `json:"stateOverride,omitempty"`
 }
 
 type AddressParam struct {
@@ -47,6 +50,37 @@ type TransactionHashParam struct {
 	Hash jsonrpc.HexBytes `json:"txHash" validate:"required,t_hash"`
 }
 
+type TransactionsByAddressParam struct {
+	Address    jsonrpc.Address `json:"address" validate:"required,t_addr"`
+	FromHeight jsonrpc.HexInt  `json:"fromHeight,omitempty" validate:"optional,t_int"`
+	ToHeight   jsonrpc.HexInt  `json:"toHeight,omitempty" validate:"optional,t_int"`
+	Cursor     jsonrpc.HexInt  `json:"cursor,omitempty" validate:"optional,t_int"`
+	Limit      jsonrpc.HexInt  `json:"limit,omitempty" validate:"optional,t_int"`
+}
+
+type PendingTransactionsParam struct {
+	FromAddress jsonrpc.Address `json:"from,omitempty" validate:"optional,t_addr_eoa"`
+	Limit       jsonrpc.HexInt  `json:"limit,omitempty" validate:"optional,t_int"`
+}
+
+// TxFailure is the reason of the failure of the transaction in the pool.
+type TxFailure struct {
+	Code    jsonrpc.HexInt `json:"code"`
+	Message string         `json:"message"`
+}
+
+// TxFailureOf returns the failure for the error. It returns nil if the error
+// is nil.
+func TxFailureOf(err error) *TxFailure {
+	if err == nil {
+		return nil
+	}
+	return &TxFailure{
+		Code:    jsonrpc.HexInt(intconv.FormatInt(int64(errors.CodeOf(err)))),
+		Message: err.Error(),
+	}
+}
+
 type TransactionParamForEstimate struct {
 	Version     jsonrpc.HexInt  `json:"version" validate:"required,t_int"`
 	FromAddress jsonrpc.Address `json:"from" validate:"required,t_addr_eoa"`
@@ -57,6 +91,25 @@ type TransactionParamForEstimate struct {
 	Nonce       jsonrpc.HexInt  `json:"nonce,omitempty" validate:"optional,t_int"`
 	DataType    string          `json:"dataType,omitempty" validate:"optional,call|deploy|message|deposit"`
 	Data        interface{}     `json:"data,omitempty"`
+
+	StateOverride interface{} `json:"stateOverride,omitempty"`
+}
+
+type TransactionParamForSimulate struct {
+	Version     jsonrpc.HexInt  `json:"version" validate:"required,t_int"`
+	FromAddress jsonrpc.Address `json:"from" validate:"required,t_addr_eoa"`
+	ToAddress   jsonrpc.Address `json:"to" validate:"required,t_addr"`
+	Value       jsonrpc.HexInt  `json:"value,omitempty" validate:"optional,t_int"`
+	StepLimit   jsonrpc.HexInt  `json:"stepLimit" validate:"required,t_int"`
+	Timestamp   jsonrpc.HexInt  `json:"timestamp" validate:"required,t_int"`
+	NetworkID   jsonrpc.HexInt  `json:"nid" validate:"required,t_int"`
+	Nonce       jsonrpc.HexInt  `json:"nonce,omitempty" validate:"optional,t_int"`
+	Signature   string          `json:"signature,omitempty" validate:"optional,t_sig"`
+	DataType    string          `json:"dataType,omitempty" validate:"optional,call|deploy|message|deposit"`
+	Data        interface{}     `json:"data,omitempty"`
+
+	Height        jsonrpc.HexInt `json:"height,omitempty" validate:"optional,t_int"`
+	StateOverride interface{}    `json:"stateOverride,omitempty"`
 }
 
 type TransactionParam struct {
@@ -88,6 +141,13 @@ type ProofEventsParam struct {
 	Events    []jsonrpc.HexInt `json:"events" validate:"gt=0,dive,t_int"`
 }
 
+type TraceParam struct {
+	TxHash jsonrpc.HexBytes `json:"txHash,omitempty" validate:"optional,t_hash"`
+	Block  jsonrpc.HexBytes `json:"block,omitempty" validate:"optional,t_hash"`
+	Height jsonrpc.HexInt   `json:"height,omitempty" validate:"optional,gte=0,t_int"`
+	Tracer string           `json:"tracer,omitempty"`
+}
+
 type RosettaTraceParam struct {
 	Tx     jsonrpc.HexBytes `json:"tx,omitempty" validate:"optional,t_rhash"`
 	Block  jsonrpc.HexBytes `json:"block,omitempty" validate:"optional,t_hash"`
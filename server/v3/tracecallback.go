@@ -19,6 +19,7 @@ type traceCallback struct {
 	ts      time.Time
 	channel chan interface{}
 	bt      *trace.BalanceTracer
+	tracer  trace.Tracer
 }
 
 type traceLog struct {
@@ -31,6 +32,10 @@ func (t *traceCallback) OnLog(level module.TraceLevel, msg string) {
 	t.lock.Lock()
 	defer t.lock.Unlock()
 
+	if t.tracer != nil {
+		return
+	}
+
 	ts := time.Now()
 	if len(t.logs) == 0 {
 		t.ts = ts
@@ -53,8 +58,11 @@ func (t *traceCallback) invokeTraceToJSON() interface{} {
 	t.lock.Lock()
 	defer t.lock.Unlock()
 
-	result := map[string]interface{}{
-		"logs": t.logs,
+	result := make(map[string]interface{})
+	if t.tracer != nil {
+		result["result"] = t.tracer.ToJSON()
+	} else {
+		result["logs"] = t.logs
 	}
 	if t.last == nil {
 		result["status"] = "0x1"
@@ -94,6 +102,11 @@ func (t *traceCallback) OnTransactionStart(txIndex int, txHash []byte, isBlockTx
 		defer t.lock.Unlock()
 		return t.bt.OnTransactionStart(txIndex, txHash, isBlockTx)
 	}
+	if t.tracer != nil {
+		t.lock.Lock()
+		defer t.lock.Unlock()
+		return t.tracer.OnTransactionStart(txIndex, txHash, isBlockTx)
+	}
 	return nil
 }
 
@@ -105,6 +118,9 @@ func (t *traceCallback) OnTransactionReset() error {
 	if t.bt != nil {
 		return t.bt.OnTransactionReset()
 	}
+	if t.tracer != nil {
+		return t.tracer.OnTransactionReset()
+	}
 	return nil
 }
 
@@ -114,6 +130,11 @@ func (t *traceCallback) OnTransactionEnd(txIndex int, txHash []byte) error {
 		defer t.lock.Unlock()
 		return t.bt.OnTransactionEnd(txIndex, txHash)
 	}
+	if t.tracer != nil {
+		t.lock.Lock()
+		defer t.lock.Unlock()
+		return t.tracer.OnTransactionEnd(txIndex, txHash)
+	}
 	return nil
 }
 
@@ -143,3 +164,48 @@ func (t *traceCallback) OnBalanceChange(opType module.OpType, from, to module.Ad
 	}
 	return nil
 }
+
+func (t *traceCallback) OnFrameStart(call *module.TraceCall) error {
+	if t.tracer != nil {
+		t.lock.Lock()
+		defer t.lock.Unlock()
+		return t.tracer.OnFrameStart(call)
+	}
+	return nil
+}
+
+func (t *traceCallback) OnFrameResult(status error, steps *big.Int, result interface{}) error {
+	if t.tracer != nil {
+		t.lock.Lock()
+		defer t.lock.Unlock()
+		return t.tracer.OnFrameResult(status, steps, result)
+	}
+	return nil
+}
+
+func (t *traceCallback) OnStorageChange(score module.Address, key, before, after []byte) error {
+	if t.tracer != nil {
+		t.lock.Lock()
+		defer t.lock.Unlock()
+		return t.tracer.OnStorageChange(score, key, before, after)
+	}
+	return nil
+}
+
+func (t *traceCallback) OnStepApply(stepType string, steps *big.Int) error {
+	if t.tracer != nil {
+		t.lock.Lock()
+		defer t.lock.Unlock()
+		return t.tracer.OnStepApply(stepType, steps)
+	}
+	return nil
+}
+
+func (t *traceCallback) OnStepEstimate(stepType string, steps *big.Int) error {
+	if t.tracer != nil {
+		t.lock.Lock()
+		defer t.lock.Unlock()
+		return t.tracer.OnStepEstimate(stepType, steps)
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"sync"
@@ -14,6 +15,7 @@ import (
 	"github.com/icon-project/goloop/common/log"
 	"github.com/icon-project/goloop/module"
 	"github.com/icon-project/goloop/server/jsonrpc"
+	"github.com/icon-project/goloop/server/v3"
 )
 
 type WebSocketConn interface {
@@ -171,6 +173,64 @@ func (wm *wsSessionManager) chain(ctx echo.Context) (module.Chain, error) {
 	return c, nil
 }
 
+// wsMonitorRequest is a request for monitoring blocks of the chain.
+type wsMonitorRequest interface {
+	// prepare checks the request for the chain, and returns the response
+	// for the failure.
+	prepare(chain module.Chain) *WSResponse
+
+	// run sends notifications until an error is delivered through ech or
+	// it fails to send. It returns *WSResponse if it's stopped for the
+	// reason to be notified.
+	run(chain module.Chain, ech <-chan error, send func(v interface{}) error) error
+}
+
+func (wm *wsSessionManager) runMonitorSession(ctx echo.Context, req wsMonitorRequest) error {
+	wss, err := wm.initSession(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer wm.StopSession(wss)
+
+	if res := req.prepare(wss.chain); res != nil {
+		_ = wss.WriteJSON(res)
+		return nil
+	}
+
+	_ = wss.response(0, "")
+
+	ech := make(chan error, 1)
+	wss.RunLoop(ech)
+
+	err = req.run(wss.chain, ech, func(v interface{}) error {
+		if err := wss.WriteJSON(v); err != nil {
+			wm.logger.Infof("fail to write json %T err:%+v\n", v, err)
+			return err
+		}
+		return nil
+	})
+	if res, ok := err.(*WSResponse); ok {
+		_ = wss.WriteJSON(res)
+	}
+	wm.logger.Warnf("%+v\n", err)
+	return nil
+}
+
+// checkMonitorHeight checks whether the chain is running and has the block
+// at the height.
+func checkMonitorHeight(chain module.Chain, h int64) *WSResponse {
+	if chain.BlockManager() == nil || chain.ServiceManager() == nil {
+		return &WSResponse{Code: int(jsonrpc.ErrorCodeServer), Message: "Stopped"}
+	}
+	if lh := v3.LowestHeightOf(chain); lh > h {
+		return &WSResponse{
+			Code:    int(jsonrpc.ErrorCodePruned),
+			Message: fmt.Sprintf("given height(%d) is lower than lowest height(%d)", h, lh),
+		}
+	}
+	return nil
+}
+
 func (wss *wsSession) response(code int, msg string) error {
 	wsResponse := WSResponse{
 		Code:    code,
@@ -221,6 +281,10 @@ type WSResponse struct {
 	Message string `json:"message,omitempty"`
 }
 
+func (r *WSResponse) Error() string {
+	return fmt.Sprintf("WSResponse(code=%d,message=%s)", r.Code, r.Message)
+}
+
 // websocketUpgrader is implementation of WebSocketUpgrader
 type websocketUpgrader struct {
 	upgrader websocket.Upgrader
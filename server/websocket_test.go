@@ -29,6 +29,7 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/icon-project/goloop/common/db"
 	"github.com/icon-project/goloop/common/errors"
 	"github.com/icon-project/goloop/common/log"
 	"github.com/icon-project/goloop/module"
@@ -186,6 +187,10 @@ func (c *testChain) GenesisStorage() module.GenesisStorage {
 	return c.gs
 }
 
+func (c *testChain) Database() db.Database {
+	return nil
+}
+
 type getBlockFunc func() module.Block
 type blockFetcher func(h int64) (getBlockFunc, error)
 type blockReceipts map[string]testReceiptList
@@ -308,10 +313,14 @@ func (bm *testBlockManager) WaitForBlock(h int64) (<-chan module.Block, error) {
 
 type testServiceManager struct {
 	module.ServiceManager
-	receipts blockReceipts
+	receipts      blockReceipts
+	patchReceipts blockReceipts
 }
 
 func (sm *testServiceManager) ReceiptListFromResult(result []byte, group module.TransactionGroup) (module.ReceiptList, error) {
+	if group == module.TransactionGroupPatch {
+		return sm.patchReceipts[string(result)], nil
+	}
 	list, ok := sm.receipts[string(result)]
 	if !ok {
 		return nil, errors.ErrNotFound
@@ -26,37 +26,22 @@ type BlockNotification struct {
 }
 
 func (wm *wsSessionManager) RunBlockSession(ctx echo.Context) error {
-	var br BlockRequest
-	wss, err := wm.initSession(ctx, &br)
-	if err != nil {
-		return err
-	}
-	defer wm.StopSession(wss)
+	return wm.runMonitorSession(ctx, new(BlockRequest))
+}
 
+func (br *BlockRequest) prepare(chain module.Chain) *WSResponse {
 	if err := br.Compile(); err != nil {
-		_ = wss.response(int(jsonrpc.ErrorCodeInvalidParams), err.Error())
-		return nil
-	}
-
-	bm := wss.chain.BlockManager()
-	sm := wss.chain.ServiceManager()
-	if bm == nil || sm == nil {
-		_ = wss.response(int(jsonrpc.ErrorCodeServer), "Stopped")
-		return nil
+		return &WSResponse{Code: int(jsonrpc.ErrorCodeInvalidParams), Message: err.Error()}
 	}
+	return checkMonitorHeight(chain, br.Height.Value)
+}
 
+func (br *BlockRequest) run(chain module.Chain, ech <-chan error, send func(v interface{}) error) error {
+	bm := chain.BlockManager()
+	sm := chain.ServiceManager()
 	h := br.Height.Value
-	if gh := wss.chain.GenesisStorage().Height(); gh > h {
-		_ = wss.response(int(jsonrpc.ErrorCodeInvalidParams),
-			fmt.Sprintf("given height(%d) is lower than genesis height(%d)", h, gh))
-		return nil
-	}
-
-	_ = wss.response(0, "")
-
-	ech := make(chan error, 1)
-	wss.RunLoop(ech)
 
+	var err error
 	var bch <-chan module.Block
 	indexes := make([][]common.HexInt32, len(br.EventFilters))
 	events := make([][][]common.HexInt32, len(br.EventFilters))
@@ -134,15 +119,13 @@ loop:
 					}
 				}
 			}
-			if err = wss.WriteJSON(&br.bn); err != nil {
-				wm.logger.Infof("fail to write json BlockNotification err:%+v\n", err)
+			if err = send(&br.bn); err != nil {
 				break loop
 			}
 		}
 		h++
 	}
-	wm.logger.Warnf("%+v\n", err)
-	return nil
+	return err
 }
 
 func (r *BlockRequest) Compile() error {
@@ -7,6 +7,7 @@ import (
 	"github.com/labstack/echo/v4"
 
 	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/errors"
 	"github.com/icon-project/goloop/module"
 	"github.com/icon-project/goloop/server/jsonrpc"
 )
@@ -24,40 +25,28 @@ type BTPNotification struct {
 }
 
 func (wm *wsSessionManager) RunBtpSession(ctx echo.Context) error {
-	var br BTPRequest
-	wss, err := wm.initSession(ctx, &br)
-	if err != nil {
-		return err
-	}
-	defer wm.StopSession(wss)
-
-	bm := wss.chain.BlockManager()
+	return wm.runMonitorSession(ctx, new(BTPRequest))
+}
 
-	sm := wss.chain.ServiceManager()
-	if bm == nil || sm == nil {
-		_ = wss.response(int(jsonrpc.ErrorCodeServer), "Stopped")
-		return nil
-	}
+func (br *BTPRequest) prepare(chain module.Chain) *WSResponse {
+	return checkMonitorHeight(chain, br.Height.Value)
+}
 
+func (br *BTPRequest) run(chain module.Chain, ech <-chan error, send func(v interface{}) error) error {
+	bm := chain.BlockManager()
+	sm := chain.ServiceManager()
+	cs := chain.Consensus()
 	h := br.Height.Value
-	if gh := wss.chain.GenesisStorage().Height(); gh > h {
-		_ = wss.response(int(jsonrpc.ErrorCodeInvalidParams),
-			fmt.Sprintf("given height(%d) is lower than genesis height(%d)", h, gh))
-		return nil
-	}
-
-	_ = wss.response(0, "")
-
-	ech := make(chan error, 1)
-	wss.RunLoop(ech)
 
 	var bch <-chan module.Block
 
 	block, err := bm.GetLastBlock()
+	if err != nil {
+		return err
+	}
 	nw, err := sm.BTPNetworkFromResult(block.Result(), br.NetworkId.Value)
 	if err != nil {
-		wm.logger.Infof("not found nid=%d height=%d, err:%+v\n", br.NetworkId.Value, h, err)
-		return nil
+		return errors.Wrapf(err, "not found nid=%d height=%d", br.NetworkId.Value, h)
 	}
 
 loop:
@@ -74,19 +63,16 @@ loop:
 				break loop
 			}
 			if nw.StartHeight()+1 <= h {
-				chain, ok := ctx.Get("chain").(module.Chain)
-				if chain == nil || !ok {
-					wm.logger.Infof("err:%+v\n", err)
-					break loop
-				}
-
-				cs := chain.Consensus()
 				nw, err := sm.BTPNetworkFromResult(blk.Result(), br.NetworkId.Value)
+				if err != nil {
+					return err
+				}
 				if !nw.Open() {
-					wm.logger.Infof("network is closed (height=%d, err:%+v)\n", h, err)
-					_ = wss.response(int(jsonrpc.ErrorCodeInvalidParams),
-						fmt.Sprintf("network is closed ( height(%d) , networkId(%d)", h, br.NetworkId))
-					break loop
+					return &WSResponse{
+						Code: int(jsonrpc.ErrorCodeInvalidParams),
+						Message: fmt.Sprintf("network is closed ( height(%d) , networkId(%d)",
+							h, br.NetworkId.Value),
+					}
 				}
 
 				var flag uint
@@ -103,15 +89,13 @@ loop:
 						br.bn.Proof = base64.StdEncoding.EncodeToString(proof)
 					}
 
-					if err = wss.WriteJSON(&br.bn); err != nil {
-						wm.logger.Infof("fail to write json BtpNotification err:%+v\n", err)
-						break loop
+					if err = send(&br.bn); err != nil {
+						return err
 					}
 				}
 			}
 		}
 		h++
 	}
-	wm.logger.Warnf("%+v\n", err)
-	return nil
+	return err
 }
@@ -20,6 +20,7 @@ type EventRequest struct {
 	Logs   common.HexInt32 `json:"logs,omitempty""`
 
 	Filters EventFilters `json:"eventFilters,omitempty"`
+	filters EventFilters
 }
 
 type EventFilters []*EventFilter
@@ -100,38 +101,25 @@ func (fs EventFilters) filterEvents(r module.Receipt, v func(fi, idx int, log mo
 }
 
 func (wm *wsSessionManager) RunEventSession(ctx echo.Context) error {
-	var er EventRequest
-	wss, err := wm.initSession(ctx, &er)
-	if err != nil {
-		return err
-	}
-	defer wm.StopSession(wss)
+	return wm.runMonitorSession(ctx, new(EventRequest))
+}
 
+func (er *EventRequest) prepare(chain module.Chain) *WSResponse {
 	filters, err := er.Compile()
 	if err != nil {
-		_ = wss.response(int(jsonrpc.ErrorCodeInvalidParams), "bad event request parameter")
-		return nil
-	}
-
-	bm := wss.chain.BlockManager()
-	sm := wss.chain.ServiceManager()
-	if bm == nil || sm == nil {
-		_ = wss.response(int(jsonrpc.ErrorCodeServer), "Stopped")
-		return nil
+		return &WSResponse{Code: int(jsonrpc.ErrorCodeInvalidParams), Message: "bad event request parameter"}
 	}
+	er.filters = filters
+	return checkMonitorHeight(chain, er.Height.Value)
+}
 
+func (er *EventRequest) run(chain module.Chain, ech <-chan error, send func(v interface{}) error) error {
+	bm := chain.BlockManager()
+	sm := chain.ServiceManager()
+	filters := er.filters
 	h := er.Height.Value
-	if gh := wss.chain.GenesisStorage().Height(); gh > h {
-		_ = wss.response(int(jsonrpc.ErrorCodeInvalidParams),
-			fmt.Sprintf("given height(%d) is lower than genesis height(%d)", h, gh))
-		return nil
-	}
-
-	_ = wss.response(0, "")
-
-	ech := make(chan error, 1)
-	wss.RunLoop(ech)
 
+	var err error
 	var bch <-chan module.Block
 
 loop:
@@ -161,15 +149,14 @@ loop:
 				if err != nil {
 					break loop
 				}
-				if es, el, err := filters2.MatchEvents(r, er.Logs.Value != 0); err == nil && len(es) > 0 {
+				if es, el, merr := filters2.MatchEvents(r, er.Logs.Value != 0); merr == nil && len(es) > 0 {
 					var en EventNotification
 					en.Height.Value = h
 					en.Hash = blk.ID()
 					en.Index.Value = index
 					en.Events = es
 					en.Logs = el
-					if err := wss.WriteJSON(&en); err != nil {
-						wm.logger.Infof("fail to write json EventNotification err:%+v\n", err)
+					if err = send(&en); err != nil {
 						break loop
 					}
 				}
@@ -178,8 +165,7 @@ loop:
 		}
 		h++
 	}
-	wm.logger.Warnf("%+v\n", err)
-	return nil
+	return err
 }
 
 func (f *EventFilter) Compile() error {
@@ -300,14 +286,20 @@ func (f *EventFilter) filterEvents(r module.Receipt, v func(idx int, log module.
 }
 
 func (f *EventRequest) Compile() (EventFilters, error) {
+	return compileEventFilters(&f.EventFilter, f.Filters)
+}
+
+// compileEventFilters compiles the filters if there is, otherwise it
+// compiles the single filter.
+func compileEventFilters(f *EventFilter, fs EventFilters) (EventFilters, error) {
 	var filters []*EventFilter
-	if len(f.Filters) > 0 {
+	if len(fs) > 0 {
 		if len(f.Signature) != 0 {
 			return nil, errors.New("both eventFilters and event is used")
 		}
-		filters = f.Filters
+		filters = fs
 	} else {
-		filters = []*EventFilter{&f.EventFilter}
+		filters = []*EventFilter{f}
 	}
 	for idx, filter := range filters {
 		if filter == nil {
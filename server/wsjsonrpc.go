@@ -0,0 +1,427 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/server/jsonrpc"
+)
+
+const (
+	WSMethodSubscribe    = "subscribe"
+	WSMethodUnsubscribe  = "unsubscribe"
+	WSMethodNotification = "notification"
+
+	WSSubscriptionBlock  = "block"
+	WSSubscriptionEvent  = "event"
+	WSSubscriptionBTP    = "btp"
+	WSSubscriptionTxPool = "txpool"
+)
+
+const (
+	wsMaxSubscriptions = 32
+	wsMaxPendingCalls  = 8
+	wsSendQueueSize    = 256
+	wsWriteTimeout     = 10 * time.Second
+)
+
+// wsCallContextKeys are keys of the values set by the middlewares, which
+// are copied to the contexts for the calls.
+var wsCallContextKeys = []string{
+	"chain", "includeDebug", "batchLimit", "rosetta", "stateOverride",
+}
+
+var (
+	errWSClosed       = errors.New("AlreadyClosed")
+	errWSSlowConsumer = errors.New("SlowConsumer")
+	errWSUnsubscribed = errors.New("Unsubscribed")
+)
+
+type SubscribeParam struct {
+	Type    string          `json:"type"`
+	Request json.RawMessage `json:"request"`
+}
+
+type UnsubscribeParam struct {
+	Subscription common.HexInt64 `json:"subscription"`
+}
+
+type SubscriptionNotification struct {
+	Subscription common.HexInt64 `json:"subscription"`
+	Result       interface{}     `json:"result,omitempty"`
+	Error        *jsonrpc.Error  `json:"error,omitempty"`
+}
+
+type wsNotification struct {
+	Version string                    `json:"jsonrpc"`
+	Method  string                    `json:"method"`
+	Params  *SubscriptionNotification `json:"params"`
+}
+
+type wsSubscription struct {
+	id  int64
+	ech chan error
+}
+
+func (sub *wsSubscription) stop() {
+	select {
+	case sub.ech <- errWSUnsubscribed:
+	default:
+	}
+}
+
+// wsRPCSession is a websocket session speaking JSON-RPC. It handles
+// subscribe and unsubscribe, and passes other requests to the method
+// repository. All messages to the client are queued, and the session is
+// closed if the queue is full.
+type wsRPCSession struct {
+	wm  *wsSessionManager
+	wss *wsSession
+	c   WebSocketConn
+	mr  *jsonrpc.MethodRepository
+	ctx echo.Context
+
+	lock   sync.Mutex
+	subs   map[int64]*wsSubscription
+	lastID int64
+	closed bool
+
+	// calls are handled with their own contexts using callCtx, which is
+	// cancelled on close, because the context of the websocket request is
+	// reused after the handler returns.
+	callCtx context.Context
+	cancel  context.CancelFunc
+
+	sendQ    chan []byte
+	calls    chan struct{}
+	done     chan struct{}
+	dropOnce sync.Once
+}
+
+// RunJSONRPCSession returns the handler of the websocket for JSON-RPC
+// requests and subscriptions.
+func (wm *wsSessionManager) RunJSONRPCSession(mr *jsonrpc.MethodRepository) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		chain, err := wm.chain(ctx)
+		if err != nil {
+			return err
+		}
+		c, err := wm.upgrader.Upgrade(ctx)
+		if err != nil {
+			return err
+		}
+		wss := wm.NewSession(c, chain)
+		if wss == nil {
+			c.WriteJSON(&jsonrpc.Response{
+				Version: jsonrpc.Version,
+				Error:   jsonrpc.ErrorLackOfResource.New("too many monitor"),
+			})
+			c.Close()
+			return errors.New("too many monitor")
+		}
+		defer wm.StopSession(wss)
+
+		callCtx, cancel := context.WithCancel(context.Background())
+		s := &wsRPCSession{
+			wm:      wm,
+			wss:     wss,
+			c:       c,
+			mr:      mr,
+			ctx:     ctx,
+			subs:    make(map[int64]*wsSubscription),
+			callCtx: callCtx,
+			cancel:  cancel,
+			sendQ:   make(chan []byte, wsSendQueueSize),
+			calls:   make(chan struct{}, wsMaxPendingCalls),
+			done:    make(chan struct{}),
+		}
+		s.run()
+		return nil
+	}
+}
+
+func (s *wsRPCSession) run() {
+	go s.writeLoop()
+	for {
+		_, msg, err := s.c.ReadMessage()
+		if err != nil {
+			s.wm.logger.Debugf("websocket read fails err:%+v", err)
+			break
+		}
+		s.handleMessage(msg)
+	}
+	s.close()
+
+	// wait for the pending calls
+	for i := 0; i < cap(s.calls); i++ {
+		s.calls <- struct{}{}
+	}
+}
+
+func (s *wsRPCSession) writeLoop() {
+	dc, _ := s.c.(interface {
+		SetWriteDeadline(t time.Time) error
+	})
+	for {
+		select {
+		case <-s.done:
+			return
+		case bs := <-s.sendQ:
+			if dc != nil {
+				_ = dc.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			}
+			if err := s.wss.WriteJSON(json.RawMessage(bs)); err != nil {
+				s.wm.logger.Infof("fail to write json err:%+v\n", err)
+				s.wss.Close()
+				return
+			}
+		}
+	}
+}
+
+// send queues the message. It closes the connection if the queue is full
+// instead of blocking the sender. The connection is closed directly, since
+// the session may be blocked by writing to the slow consumer.
+func (s *wsRPCSession) send(v interface{}) error {
+	bs, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	select {
+	case <-s.done:
+		return errWSClosed
+	default:
+	}
+	select {
+	case s.sendQ <- bs:
+		return nil
+	default:
+		s.dropOnce.Do(func() {
+			s.wm.logger.Warnf("drop slow websocket consumer queue=%d", len(s.sendQ))
+			_ = s.c.Close()
+		})
+		return errWSSlowConsumer
+	}
+}
+
+func (s *wsRPCSession) close() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.closed {
+		return
+	}
+	s.closed = true
+	for id, sub := range s.subs {
+		sub.stop()
+		delete(s.subs, id)
+	}
+	close(s.done)
+	s.cancel()
+}
+
+func (s *wsRPCSession) respond(req *jsonrpc.Request, result interface{}, err *jsonrpc.Error) {
+	if req.ID == nil {
+		return
+	}
+	resp := &jsonrpc.Response{
+		Version: jsonrpc.Version,
+		ID:      req.ID,
+	}
+	if err != nil {
+		resp.Error = err
+	} else {
+		resp.Result = result
+	}
+	_ = s.send(resp)
+}
+
+func (s *wsRPCSession) handleMessage(msg []byte) {
+	req := new(jsonrpc.Request)
+	if err := json.Unmarshal(msg, req); err == nil && req.Method != nil {
+		switch *req.Method {
+		case WSMethodSubscribe:
+			s.subscribe(req)
+			return
+		case WSMethodUnsubscribe:
+			s.unsubscribe(req)
+			return
+		}
+	}
+
+	// it stops reading messages if there are too many pending calls.
+	s.calls <- struct{}{}
+	ctx := s.newCallContext()
+	go func() {
+		defer func() {
+			<-s.calls
+		}()
+		if _, resp := s.mr.HandleMessage(ctx, msg); resp != nil {
+			_ = s.send(resp)
+		}
+	}()
+}
+
+// newCallContext returns a new context for a call with the values of the
+// websocket request. The request of it is cancelled when the session is
+// closed.
+func (s *wsRPCSession) newCallContext() echo.Context {
+	req := s.ctx.Request().Clone(s.callCtx)
+	ctx := s.ctx.Echo().NewContext(req, nil)
+	ctx.SetParamNames(s.ctx.ParamNames()...)
+	ctx.SetParamValues(s.ctx.ParamValues()...)
+	for _, key := range wsCallContextKeys {
+		if v := s.ctx.Get(key); v != nil {
+			ctx.Set(key, v)
+		}
+	}
+	return ctx
+}
+
+func newMonitorRequest(param *SubscribeParam) (wsMonitorRequest, *jsonrpc.Error) {
+	var req wsMonitorRequest
+	switch param.Type {
+	case WSSubscriptionBlock:
+		req = new(BlockRequest)
+	case WSSubscriptionEvent:
+		req = new(EventRequest)
+	case WSSubscriptionBTP:
+		req = new(BTPRequest)
+	case WSSubscriptionTxPool:
+		req = new(TxPoolRequest)
+	default:
+		return nil, jsonrpc.ErrorCodeInvalidParams.Errorf("unknown type %q", param.Type)
+	}
+	jd := json.NewDecoder(bytes.NewBuffer(param.Request))
+	jd.DisallowUnknownFields()
+	if err := jd.Decode(req); err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Errorf("bad %s request", param.Type)
+	}
+	return req, nil
+}
+
+func errorOfResponse(res *WSResponse) *jsonrpc.Error {
+	return &jsonrpc.Error{
+		Code:    jsonrpc.ErrorCode(res.Code),
+		Message: res.Message,
+	}
+}
+
+func (s *wsRPCSession) subscribe(req *jsonrpc.Request) {
+	var param SubscribeParam
+	if err := json.Unmarshal(req.Params, &param); err != nil {
+		s.respond(req, nil, jsonrpc.ErrorCodeInvalidParams.New("bad subscribe param"))
+		return
+	}
+	mreq, je := newMonitorRequest(&param)
+	if je != nil {
+		s.respond(req, nil, je)
+		return
+	}
+	if res := mreq.prepare(s.wss.chain); res != nil {
+		s.respond(req, nil, errorOfResponse(res))
+		return
+	}
+
+	s.lock.Lock()
+	if s.closed {
+		s.lock.Unlock()
+		return
+	}
+	if len(s.subs) >= wsMaxSubscriptions {
+		s.lock.Unlock()
+		s.respond(req, nil, jsonrpc.ErrorLackOfResource.New("too many subscriptions"))
+		return
+	}
+	s.lastID += 1
+	sub := &wsSubscription{
+		id:  s.lastID,
+		ech: make(chan error, 1),
+	}
+	s.subs[sub.id] = sub
+	s.lock.Unlock()
+
+	s.respond(req, &common.HexInt64{Value: sub.id}, nil)
+	go s.runSubscription(sub, mreq)
+}
+
+func (s *wsRPCSession) removeSubscription(id int64) *wsSubscription {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	sub, ok := s.subs[id]
+	if ok {
+		delete(s.subs, id)
+	}
+	return sub
+}
+
+func (s *wsRPCSession) runSubscription(sub *wsSubscription, req wsMonitorRequest) {
+	chain := s.wss.chain
+	err := req.run(chain, sub.ech, func(v interface{}) error {
+		return s.send(&wsNotification{
+			Version: jsonrpc.Version,
+			Method:  WSMethodNotification,
+			Params: &SubscriptionNotification{
+				Subscription: common.HexInt64{Value: sub.id},
+				Result:       v,
+			},
+		})
+	})
+
+	// notify the end of the subscription unless it's unsubscribed.
+	if s.removeSubscription(sub.id) == nil {
+		return
+	}
+	n := &SubscriptionNotification{
+		Subscription: common.HexInt64{Value: sub.id},
+	}
+	if res, ok := err.(*WSResponse); ok {
+		n.Error = errorOfResponse(res)
+	} else if err != nil {
+		n.Error = jsonrpc.ErrorCodeServer.New(err.Error())
+	} else {
+		n.Error = jsonrpc.ErrorCodeServer.New("Stopped")
+	}
+	_ = s.send(&wsNotification{
+		Version: jsonrpc.Version,
+		Method:  WSMethodNotification,
+		Params:  n,
+	})
+}
+
+func (s *wsRPCSession) unsubscribe(req *jsonrpc.Request) {
+	var param UnsubscribeParam
+	if err := json.Unmarshal(req.Params, &param); err != nil {
+		s.respond(req, nil, jsonrpc.ErrorCodeInvalidParams.New("bad unsubscribe param"))
+		return
+	}
+	sub := s.removeSubscription(param.Subscription.Value)
+	if sub != nil {
+		sub.stop()
+	}
+	s.respond(req, sub != nil, nil)
+}
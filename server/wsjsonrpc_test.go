@@ -0,0 +1,258 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/server/jsonrpc"
+	"github.com/icon-project/goloop/server/metric"
+)
+
+type testRPCChain struct {
+	module.Chain
+}
+
+func (c *testRPCChain) MetricContext() context.Context {
+	return metric.DefaultMetricContext()
+}
+
+type testRPCContext struct {
+	*testContext
+	req *http.Request
+	e   *echo.Echo
+}
+
+func (ctx *testRPCContext) Request() *http.Request {
+	return ctx.req
+}
+
+func (ctx *testRPCContext) Echo() *echo.Echo {
+	return ctx.e
+}
+
+func (ctx *testRPCContext) ParamNames() []string {
+	return nil
+}
+
+func (ctx *testRPCContext) ParamValues() []string {
+	return nil
+}
+
+func newTestRPCContext(chain module.Chain) *testRPCContext {
+	ctx := newTestContext(&testRPCChain{chain})
+	ctx.config["includeDebug"] = false
+	return &testRPCContext{
+		testContext: ctx,
+		req:         httptest.NewRequest(http.MethodGet, "/api/v3/icon_dex/ws", nil),
+		e:           echo.New(),
+	}
+}
+
+type testRPCMessage struct {
+	ID     interface{}     `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *jsonrpc.Error  `json:"error"`
+}
+
+func readTestRPCMessage(t *testing.T, conn *testWebSocketConn) *testRPCMessage {
+	bs, err := conn.clientRead()
+	assert.NoError(t, err)
+	msg := new(testRPCMessage)
+	assert.NoError(t, json.Unmarshal(bs, msg))
+	return msg
+}
+
+func TestWsSessionManager_RunJSONRPCSession(t *testing.T) {
+	logger := log.New()
+	logger.SetOutput(io.Discard)
+
+	connCh := make(chan *testWebSocketConn, 1)
+	upgrader := newTestWebsocketUpgrader(func(ctx echo.Context, conn *testWebSocketConn) {
+		connCh <- conn
+	})
+	wm := newWSSessionManagerWithUpgrader(logger, 10, upgrader)
+
+	mtr := metric.NewJsonrpcMetric(metric.DefaultJsonrpcDurationsExpire, metric.DefaultJsonrpcDurationsSize, true)
+	mr := jsonrpc.NewMethodRepository(mtr)
+	mr.RegisterMethod("hello", func(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
+		return "world", nil
+	})
+
+	// blocks after the second one are not delivered until s1 is closed.
+	s1 := make(chan struct{})
+	chain := newTestChain(0,
+		func(h int64) (getBlockFunc, error) {
+			return func() module.Block {
+				if h > 2 {
+					<-s1
+				}
+				return &testBlock{height: h, result: "empty"}
+			}, nil
+		},
+		blockReceipts{"empty": testReceiptList{}},
+	)
+	done := make(chan struct{})
+	go func() {
+		_ = wm.RunJSONRPCSession(mr)(newTestRPCContext(chain))
+		close(done)
+	}()
+	conn := <-connCh
+
+	// unknown type of subscription
+	assert.NoError(t, conn.clientWrite([]byte(`{"jsonrpc":"2.0","method":"subscribe","params":{"type":"unknown","request":{}},"id":1}`)))
+	msg := readTestRPCMessage(t, conn)
+	assert.EqualValues(t, 1, msg.ID)
+	if assert.NotNil(t, msg.Error) {
+		assert.Equal(t, jsonrpc.ErrorCodeInvalidParams, msg.Error.Code)
+	}
+
+	// invalid request of the subscription
+	assert.NoError(t, conn.clientWrite([]byte(`{"jsonrpc":"2.0","method":"subscribe","params":{"type":"block","request":{"height":"0x1","unknown":1}},"id":2}`)))
+	msg = readTestRPCMessage(t, conn)
+	if assert.NotNil(t, msg.Error) {
+		assert.Equal(t, jsonrpc.ErrorCodeInvalidParams, msg.Error.Code)
+	}
+
+	// subscribe blocks
+	assert.NoError(t, conn.clientWrite([]byte(`{"jsonrpc":"2.0","method":"subscribe","params":{"type":"block","request":{"height":"0x1"}},"id":3}`)))
+	msg = readTestRPCMessage(t, conn)
+	assert.EqualValues(t, 3, msg.ID)
+	assert.Nil(t, msg.Error)
+	assert.Equal(t, `"0x1"`, string(msg.Result))
+
+	for h := int64(1); h <= 2; h++ {
+		msg = readTestRPCMessage(t, conn)
+		assert.Equal(t, WSMethodNotification, msg.Method)
+		var n struct {
+			Subscription common.HexInt64   `json:"subscription"`
+			Result       BlockNotification `json:"result"`
+		}
+		assert.NoError(t, json.Unmarshal(msg.Params, &n))
+		assert.EqualValues(t, 1, n.Subscription.Value)
+		assert.Equal(t, h, n.Result.Height.Value)
+		assert.Equal(t, common.HexBytes(testHeightToBlockID(h)), n.Result.Hash)
+	}
+
+	// plain call over the same connection
+	assert.NoError(t, conn.clientWrite([]byte(`{"jsonrpc":"2.0","method":"hello","id":4}`)))
+	msg = readTestRPCMessage(t, conn)
+	assert.EqualValues(t, 4, msg.ID)
+	assert.Nil(t, msg.Error)
+	assert.Equal(t, `"world"`, string(msg.Result))
+
+	// unsubscribe
+	assert.NoError(t, conn.clientWrite([]byte(`{"jsonrpc":"2.0","method":"unsubscribe","params":{"subscription":"0x1"},"id":5}`)))
+	msg = readTestRPCMessage(t, conn)
+	assert.EqualValues(t, 5, msg.ID)
+	assert.Equal(t, `true`, string(msg.Result))
+
+	assert.NoError(t, conn.clientWrite([]byte(`{"jsonrpc":"2.0","method":"unsubscribe","params":{"subscription":"0x1"},"id":6}`)))
+	msg = readTestRPCMessage(t, conn)
+	assert.EqualValues(t, 6, msg.ID)
+	assert.Equal(t, `false`, string(msg.Result))
+
+	conn.Close()
+	close(s1)
+	<-done
+	assert.Equal(t, 0, len(wm.sessions))
+}
+
+func TestWsSessionManager_RunJSONRPCSessionWithPendingCall(t *testing.T) {
+	logger := log.New()
+	logger.SetOutput(io.Discard)
+
+	connCh := make(chan *testWebSocketConn, 1)
+	upgrader := newTestWebsocketUpgrader(func(ctx echo.Context, conn *testWebSocketConn) {
+		connCh <- conn
+	})
+	wm := newWSSessionManagerWithUpgrader(logger, 10, upgrader)
+
+	// the call waits until the session is closed
+	started := make(chan struct{})
+	var finished int32
+	mtr := metric.NewJsonrpcMetric(metric.DefaultJsonrpcDurationsExpire, metric.DefaultJsonrpcDurationsSize, true)
+	mr := jsonrpc.NewMethodRepository(mtr)
+	mr.RegisterMethod("wait", func(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
+		close(started)
+		<-ctx.Request().Context().Done()
+		chain, err := ctx.Chain()
+		assert.NoError(t, err)
+		assert.NotNil(t, chain)
+		atomic.StoreInt32(&finished, 1)
+		return nil, ctx.Request().Context().Err()
+	})
+
+	chain := newTestChain(0, nil, nil)
+	done := make(chan struct{})
+	go func() {
+		_ = wm.RunJSONRPCSession(mr)(newTestRPCContext(chain))
+		close(done)
+	}()
+	conn := <-connCh
+
+	assert.NoError(t, conn.clientWrite([]byte(`{"jsonrpc":"2.0","method":"wait","id":1}`)))
+	<-started
+	conn.Close()
+	<-done
+	assert.EqualValues(t, 1, atomic.LoadInt32(&finished))
+}
+
+func TestWsRPCSession_SlowConsumer(t *testing.T) {
+	logger := log.New()
+	logger.SetOutput(io.Discard)
+
+	conn := &testWebSocketConn{
+		in:  make(chan interface{}, 3),
+		out: make(chan interface{}, 3),
+	}
+	wm := newWSSessionManagerWithUpgrader(logger, 10, nil)
+	s := &wsRPCSession{
+		wm:    wm,
+		wss:   &wsSession{c: conn},
+		c:     conn,
+		sendQ: make(chan []byte, 2),
+		done:  make(chan struct{}),
+	}
+	s.callCtx, s.cancel = context.WithCancel(context.Background())
+
+	// it doesn't block the sender, but drops the connection.
+	assert.NoError(t, s.send("msg1"))
+	assert.NoError(t, s.send("msg2"))
+	assert.Equal(t, errWSSlowConsumer, s.send("msg3"))
+	assert.Equal(t, errWSSlowConsumer, s.send("msg4"))
+
+	_, _, err := conn.ReadMessage()
+	assert.Error(t, err)
+
+	s.close()
+	assert.Equal(t, errWSClosed, s.send("msg5"))
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"sync"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/server/jsonrpc"
+	"github.com/icon-project/goloop/server/v3"
+)
+
+const txPoolEventQueueSize = 256
+
+type TxPoolRequest struct {
+	From *common.Address `json:"from,omitempty"`
+	tn   TxPoolNotification
+}
+
+type TxPoolNotification struct {
+	Type    string          `json:"type"`
+	TxHash  common.HexBytes `json:"txHash"`
+	From    *common.Address `json:"from,omitempty"`
+	Failure *v3.TxFailure   `json:"failure,omitempty"`
+}
+
+func (wm *wsSessionManager) RunTxPoolSession(ctx echo.Context) error {
+	return wm.runMonitorSession(ctx, new(TxPoolRequest))
+}
+
+func (r *TxPoolRequest) prepare(chain module.Chain) *WSResponse {
+	if chain.ServiceManager() == nil {
+		return &WSResponse{Code: int(jsonrpc.ErrorCodeServer), Message: "Stopped"}
+	}
+	return nil
+}
+
+// run sends events of the transaction pool for the transactions of the
+// sender if it's specified.
+func (r *TxPoolRequest) run(chain module.Chain, ech <-chan error, send func(v interface{}) error) error {
+	sm := chain.ServiceManager()
+
+	evch := make(chan *module.TxPoolEvent, txPoolEventQueueSize)
+	overflow := make(chan struct{})
+	var once sync.Once
+	cancel := sm.WatchTxPool(func(ev *module.TxPoolEvent) {
+		if r.From != nil && !r.From.Equal(ev.From) {
+			return
+		}
+		select {
+		case evch <- ev:
+		default:
+			once.Do(func() {
+				close(overflow)
+			})
+		}
+	})
+	defer cancel()
+
+	for {
+		select {
+		case err := <-ech:
+			return err
+		case <-overflow:
+			return &WSResponse{
+				Code:    int(jsonrpc.ErrorLackOfResource),
+				Message: "too many events",
+			}
+		case ev := <-evch:
+			r.tn.Type = ev.Type.String()
+			r.tn.TxHash = ev.ID
+			r.tn.From = common.AddressToPtr(ev.From)
+			r.tn.Failure = v3.TxFailureOf(ev.Error)
+			if err := send(&r.tn); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,159 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/server/jsonrpc"
+)
+
+type testTxPoolServiceManager struct {
+	module.ServiceManager
+	lock sync.Mutex
+	cb   func(ev *module.TxPoolEvent)
+	ch   chan struct{}
+}
+
+func (sm *testTxPoolServiceManager) WatchTxPool(cb func(ev *module.TxPoolEvent)) func() {
+	sm.lock.Lock()
+	defer sm.lock.Unlock()
+	sm.cb = cb
+	close(sm.ch)
+	return func() {
+		sm.lock.Lock()
+		defer sm.lock.Unlock()
+		sm.cb = nil
+	}
+}
+
+func (sm *testTxPoolServiceManager) emit(ev *module.TxPoolEvent) {
+	sm.lock.Lock()
+	defer sm.lock.Unlock()
+	if sm.cb != nil {
+		sm.cb(ev)
+	}
+}
+
+type testTxPoolChain struct {
+	module.Chain
+	sm *testTxPoolServiceManager
+}
+
+func (c *testTxPoolChain) ServiceManager() module.ServiceManager {
+	return c.sm
+}
+
+type testTxPoolTransaction struct {
+	module.Transaction
+	from module.Address
+}
+
+func (tx *testTxPoolTransaction) From() module.Address {
+	return tx.from
+}
+
+func TestTxPoolRequest_Run(t *testing.T) {
+	sm := &testTxPoolServiceManager{ch: make(chan struct{})}
+	chain := &testTxPoolChain{sm: sm}
+
+	addr1 := common.MustNewAddressFromString("hx1111111111111111111111111111111111111111")
+	addr2 := common.MustNewAddressFromString("hx2222222222222222222222222222222222222222")
+	req := &TxPoolRequest{From: addr1}
+	assert.Nil(t, req.prepare(chain))
+
+	ech := make(chan error, 1)
+	nch := make(chan TxPoolNotification, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- req.run(chain, ech, func(v interface{}) error {
+			nch <- *v.(*TxPoolNotification)
+			return nil
+		})
+	}()
+	<-sm.ch
+
+	sm.emit(&module.TxPoolEvent{
+		Type:        module.TxPoolEventAdd,
+		ID:          []byte("tx2"),
+		From:        addr2,
+		Transaction: &testTxPoolTransaction{from: addr2},
+	})
+	sm.emit(&module.TxPoolEvent{
+		Type:        module.TxPoolEventAdd,
+		ID:          []byte("tx1"),
+		From:        addr1,
+		Transaction: &testTxPoolTransaction{from: addr1},
+	})
+	sm.emit(&module.TxPoolEvent{
+		Type:  module.TxPoolEventDrop,
+		ID:    []byte("tx2"),
+		From:  addr2,
+		Error: errors.InvalidStateError.New("AlreadyProcessed"),
+	})
+	sm.emit(&module.TxPoolEvent{
+		Type:  module.TxPoolEventDrop,
+		ID:    []byte("tx1"),
+		From:  addr1,
+		Error: errors.InvalidStateError.New("AlreadyProcessed"),
+	})
+
+	n := <-nch
+	assert.Equal(t, "add", n.Type)
+	assert.Equal(t, common.HexBytes("tx1"), n.TxHash)
+	assert.True(t, addr1.Equal(n.From))
+	assert.Nil(t, n.Failure)
+
+	n = <-nch
+	assert.Equal(t, "drop", n.Type)
+	assert.Equal(t, common.HexBytes("tx1"), n.TxHash)
+	assert.True(t, addr1.Equal(n.From))
+	if assert.NotNil(t, n.Failure) {
+		assert.Equal(t, "AlreadyProcessed", n.Failure.Message)
+	}
+
+	ech <- errors.New("Stop")
+	assert.Error(t, <-done)
+	assert.Nil(t, sm.cb)
+
+	// too many events
+	sm = &testTxPoolServiceManager{ch: make(chan struct{})}
+	chain = &testTxPoolChain{sm: sm}
+	req = new(TxPoolRequest)
+	block := make(chan struct{})
+	go func() {
+		done <- req.run(chain, ech, func(v interface{}) error {
+			<-block
+			return nil
+		})
+	}()
+	<-sm.ch
+	for i := 0; i < txPoolEventQueueSize+2; i++ {
+		sm.emit(&module.TxPoolEvent{Type: module.TxPoolEventDrop, ID: []byte("tx")})
+	}
+	close(block)
+	err := <-done
+	if res, ok := err.(*WSResponse); assert.True(t, ok) {
+		assert.Equal(t, int(jsonrpc.ErrorLackOfResource), res.Code)
+	}
+}
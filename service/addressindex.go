@@ -0,0 +1,320 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"encoding/binary"
+	"sync"
+
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/module"
+)
+
+const (
+	addressIndexRangeKey = "address_index.range"
+)
+
+// AddressIndex maintains list of transactions for each address.
+// A transaction is related to the address if the address is the sender,
+// the receiver or the SCORE emitting events in the transaction.
+//
+// For each address, it stores the number of entries with the address as
+// a key, and each entry with the address followed by the index of the entry.
+// Entries are ordered by block height, so it can find entries in the range
+// of heights with binary search.
+type AddressIndex struct {
+	lock  sync.Mutex
+	bk    *db.CodedBucket
+	props *db.CodedBucket
+}
+
+func countKeyOf(addr module.Address) db.Raw {
+	return addr.Bytes()
+}
+
+func entryKeyOf(addr module.Address, idx int64) db.Raw {
+	bs := addr.Bytes()
+	key := make([]byte, len(bs)+8)
+	copy(key, bs)
+	binary.BigEndian.PutUint64(key[len(bs):], uint64(idx))
+	return key
+}
+
+func (idx *AddressIndex) countOf(addr module.Address) (int64, error) {
+	var cnt int64
+	if err := idx.bk.Get(countKeyOf(addr), &cnt); err != nil {
+		if errors.NotFoundError.Equals(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return cnt, nil
+}
+
+func (idx *AddressIndex) entryOf(addr module.Address, i int64) (*module.AddressIndexEntry, error) {
+	e := new(module.AddressIndexEntry)
+	if err := idx.bk.Get(entryKeyOf(addr, i), e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Range returns the range of indexed heights. It returns nil if
+// nothing is indexed.
+func (idx *AddressIndex) Range() (*module.AddressIndexRange, error) {
+	idx.lock.Lock()
+	defer idx.lock.Unlock()
+	return idx.rangeInLock()
+}
+
+func (idx *AddressIndex) rangeInLock() (*module.AddressIndexRange, error) {
+	r := new(module.AddressIndexRange)
+	if err := idx.props.Get(db.Raw(addressIndexRangeKey), r); err != nil {
+		if errors.NotFoundError.Equals(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return r, nil
+}
+
+type addressIndexWriter struct {
+	idx    *AddressIndex
+	height int64
+	counts map[string]int64
+}
+
+// add appends the transaction to the list of the address. On the first
+// touch of the address, it truncates entries at the same or higher height
+// for re-indexing the block.
+func (w *addressIndexWriter) add(addr module.Address, id []byte, added map[string]bool) error {
+	if addr == nil {
+		return nil
+	}
+	key := string(addr.Bytes())
+	if added[key] {
+		return nil
+	}
+	added[key] = true
+
+	cnt, ok := w.counts[key]
+	if !ok {
+		var err error
+		if cnt, err = w.idx.countOf(addr); err != nil {
+			return err
+		}
+		for cnt > 0 {
+			e, err := w.idx.entryOf(addr, cnt-1)
+			if err != nil {
+				return err
+			}
+			if e.Height < w.height {
+				break
+			}
+			cnt -= 1
+		}
+	}
+	e := &module.AddressIndexEntry{
+		Height: w.height,
+		TxHash: id,
+	}
+	if err := w.idx.bk.Set(entryKeyOf(addr, cnt), e); err != nil {
+		return err
+	}
+	w.counts[key] = cnt + 1
+	return nil
+}
+
+func (w *addressIndexWriter) addTransactions(tl module.TransactionList, rl module.ReceiptList) error {
+	if tl == nil || rl == nil {
+		return nil
+	}
+	for itr := tl.Iterator(); itr.Has(); log.Must(itr.Next()) {
+		tx, i, err := itr.Get()
+		if err != nil {
+			return err
+		}
+		rct, err := rl.Get(i)
+		if err != nil {
+			return err
+		}
+		added := make(map[string]bool)
+		if err := w.add(tx.From(), tx.ID(), added); err != nil {
+			return err
+		}
+		if err := w.add(rct.To(), tx.ID(), added); err != nil {
+			return err
+		}
+		for eitr := rct.EventLogIterator(); eitr.Has(); log.Must(eitr.Next()) {
+			ev, err := eitr.Get()
+			if err != nil {
+				return err
+			}
+			if err := w.add(ev.Address(), tx.ID(), added); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (w *addressIndexWriter) flush() error {
+	for key, cnt := range w.counts {
+		if err := w.idx.bk.Set(db.Raw(key), cnt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddBlock indexes patch and normal transactions of the block at the height.
+// Re-indexing the block replaces entries of the block.
+// If there is a gap between indexed range and the height, then indexed
+// range starts from the height.
+func (idx *AddressIndex) AddBlock(
+	height int64,
+	ptl module.TransactionList, prl module.ReceiptList,
+	ntl module.TransactionList, nrl module.ReceiptList,
+) error {
+	idx.lock.Lock()
+	defer idx.lock.Unlock()
+
+	w := &addressIndexWriter{
+		idx:    idx,
+		height: height,
+		counts: make(map[string]int64),
+	}
+	if err := w.addTransactions(ptl, prl); err != nil {
+		return err
+	}
+	if err := w.addTransactions(ntl, nrl); err != nil {
+		return err
+	}
+	if err := w.flush(); err != nil {
+		return err
+	}
+
+	r, err := idx.rangeInLock()
+	if err != nil {
+		return err
+	}
+	if r == nil || height > r.Last+1 {
+		r = &module.AddressIndexRange{First: height, Last: height}
+	} else {
+		r.Last = height
+		if height < r.First {
+			r.First = height
+		}
+	}
+	return idx.props.Set(db.Raw(addressIndexRangeKey), r)
+}
+
+// searchHeight returns the smallest index in [0,cnt) whose entry has
+// greater height than the height (or same height if inclusive is true).
+func (idx *AddressIndex) searchHeight(addr module.Address, cnt int64, height int64, inclusive bool) (int64, error) {
+	low, high := int64(0), cnt
+	for low < high {
+		mid := low + (high-low)/2
+		e, err := idx.entryOf(addr, mid)
+		if err != nil {
+			return 0, err
+		}
+		if e.Height > height || (inclusive && e.Height == height) {
+			high = mid
+		} else {
+			low = mid + 1
+		}
+	}
+	return low, nil
+}
+
+// Get returns entries of the address in the range of heights from the
+// latest one. It returns entries before the cursor if cursor is not
+// negative, and the cursor for the next query. The returned cursor is
+// negative if there is no more entries.
+func (idx *AddressIndex) Get(
+	addr module.Address, from, to int64, cursor int64, limit int,
+) ([]*module.AddressIndexEntry, int64, error) {
+	idx.lock.Lock()
+	defer idx.lock.Unlock()
+
+	r, err := idx.rangeInLock()
+	if err != nil {
+		return nil, -1, err
+	}
+	if r == nil {
+		return nil, -1, errors.NotFoundError.New("NoIndexedBlocks")
+	}
+	if from < r.First {
+		from = r.First
+	}
+	if to > r.Last {
+		to = r.Last
+	}
+	if from > to {
+		return []*module.AddressIndexEntry{}, -1, nil
+	}
+
+	cnt, err := idx.countOf(addr)
+	if err != nil {
+		return nil, -1, err
+	}
+	lower, err := idx.searchHeight(addr, cnt, from, true)
+	if err != nil {
+		return nil, -1, err
+	}
+	upper, err := idx.searchHeight(addr, cnt, to, false)
+	if err != nil {
+		return nil, -1, err
+	}
+	if cursor >= 0 && cursor < upper {
+		upper = cursor
+	}
+
+	entries := make([]*module.AddressIndexEntry, 0, limit)
+	for i := upper - 1; i >= lower && len(entries) < limit; i-- {
+		e, err := idx.entryOf(addr, i)
+		if err != nil {
+			return nil, -1, err
+		}
+		entries = append(entries, e)
+	}
+	next := upper - int64(len(entries))
+	if next <= lower {
+		next = -1
+	}
+	return entries, next, nil
+}
+
+var _ module.AddressIndex = (*AddressIndex)(nil)
+
+func NewAddressIndex(dbase db.Database) (*AddressIndex, error) {
+	bk, err := db.NewCodedBucket(dbase, db.TransactionsByAddress, nil)
+	if err != nil {
+		return nil, err
+	}
+	props, err := db.NewCodedBucket(dbase, db.ChainProperty, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &AddressIndex{
+		bk:    bk,
+		props: props,
+	}, nil
+}
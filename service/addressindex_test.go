@@ -0,0 +1,143 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/txresult"
+)
+
+type testTxIterator struct {
+	txs []module.Transaction
+	idx int
+}
+
+func (i *testTxIterator) Has() bool {
+	return i.idx < len(i.txs)
+}
+
+func (i *testTxIterator) Next() error {
+	i.idx += 1
+	return nil
+}
+
+func (i *testTxIterator) Get() (module.Transaction, int, error) {
+	return i.txs[i.idx], i.idx, nil
+}
+
+type testTxList struct {
+	module.TransactionList
+	txs []module.Transaction
+}
+
+func (l *testTxList) Iterator() module.TransactionIterator {
+	return &testTxIterator{txs: l.txs}
+}
+
+func TestAddressIndex_Basic(t *testing.T) {
+	dbase := db.NewMapDB()
+	idx, err := NewAddressIndex(dbase)
+	assert.NoError(t, err)
+
+	addr1 := common.MustNewAddressFromString("hx1111111111111111111111111111111111111111")
+	addr2 := common.MustNewAddressFromString("hx2222222222222222222222222222222222222222")
+	score := common.MustNewAddressFromString("cx3333333333333333333333333333333333333333")
+
+	_, _, err = idx.Get(addr1, 0, 10, -1, 10)
+	assert.Error(t, err)
+
+	addBlock := func(height int64, ids ...string) {
+		var txs []module.Transaction
+		var rcts []txresult.Receipt
+		for i, id := range ids {
+			from := addr1
+			if i%2 == 1 {
+				from = addr2
+			}
+			txs = append(txs, newMockTransaction([]byte(id), from, 0))
+			rct := txresult.NewReceipt(dbase, module.LatestRevision, score)
+			rct.AddLog(score, [][]byte{[]byte("Event()")}, nil)
+			rct.SetResult(module.StatusSuccess, big.NewInt(100), big.NewInt(10), nil)
+			rcts = append(rcts, rct)
+		}
+		err := idx.AddBlock(height, nil, nil,
+			&testTxList{txs: txs}, txresult.NewReceiptListFromSlice(dbase, rcts))
+		assert.NoError(t, err)
+	}
+
+	addBlock(1, "tx1", "tx2")
+	addBlock(2, "tx3")
+	addBlock(3, "tx4", "tx5")
+
+	r, err := idx.Range()
+	assert.NoError(t, err)
+	assert.Equal(t, &module.AddressIndexRange{First: 1, Last: 3}, r)
+
+	entries, next, err := idx.Get(addr1, 0, 10, -1, 10)
+	assert.NoError(t, err)
+	assert.EqualValues(t, -1, next)
+	assert.Equal(t, []*module.AddressIndexEntry{
+		{Height: 3, TxHash: []byte("tx4")},
+		{Height: 2, TxHash: []byte("tx3")},
+		{Height: 1, TxHash: []byte("tx1")},
+	}, entries)
+
+	entries, next, err = idx.Get(score, 0, 10, -1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []*module.AddressIndexEntry{
+		{Height: 3, TxHash: []byte("tx5")},
+		{Height: 3, TxHash: []byte("tx4")},
+	}, entries)
+	entries, next, err = idx.Get(score, 0, 10, next, 2)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, next)
+	assert.Equal(t, []*module.AddressIndexEntry{
+		{Height: 2, TxHash: []byte("tx3")},
+		{Height: 1, TxHash: []byte("tx2")},
+	}, entries)
+	entries, next, err = idx.Get(score, 0, 10, next, 2)
+	assert.NoError(t, err)
+	assert.EqualValues(t, -1, next)
+	assert.Equal(t, []*module.AddressIndexEntry{
+		{Height: 1, TxHash: []byte("tx1")},
+	}, entries)
+
+	entries, _, err = idx.Get(addr2, 2, 3, -1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []*module.AddressIndexEntry{
+		{Height: 3, TxHash: []byte("tx5")},
+	}, entries)
+
+	// re-indexing replaces entries at the height or higher
+	addBlock(2, "tx6")
+	r, err = idx.Range()
+	assert.NoError(t, err)
+	assert.Equal(t, &module.AddressIndexRange{First: 1, Last: 2}, r)
+	entries, _, err = idx.Get(addr1, 0, 10, -1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []*module.AddressIndexEntry{
+		{Height: 2, TxHash: []byte("tx6")},
+		{Height: 1, TxHash: []byte("tx1")},
+	}, entries)
+}
@@ -148,18 +148,22 @@ func (cc *callContext) pushFrame(handler ContractHandler, limit *big.Int) *callF
 		frame.snapshot = cc.GetSnapshot()
 	}
 	logger.OnFrameEnter(cc.frame.fid)
+	if logger.TraceMode() == module.TraceModeInvoke {
+		logger.OnFrameStart(TraceCallOf(handler))
+	}
 	frame.fid = cc.nextFID
 	cc.nextFID += 1
 	cc.frame = frame
 	return frame
 }
 
-func (cc *callContext) popFrame(success bool) *callFrame {
+func (cc *callContext) popFrame(status error, result *codec.TypedObj) *callFrame {
 	cc.lock.Lock()
 	defer cc.lock.Unlock()
 
+	success := status == nil
 	frame := cc.frame
-	cc.frame.log.OnFrameExit(success, &frame.stepUsed)
+	cc.frame.log.OnFrameExit(status, &frame.stepUsed, result)
 	if !frame.isQuery {
 		if success {
 			frame.parent.applyFrameLogsOf(frame)
@@ -385,7 +389,7 @@ func (cc *callContext) handleResult(target *callFrame, status error, result *cod
 		return false
 	}
 
-	current := cc.popFrame(status == nil)
+	current := cc.popFrame(status, result)
 	if current == nil {
 		return false
 	}
@@ -517,6 +521,7 @@ func (cc *callContext) applyStepsInLock(t state.StepType, n int) bool {
 	steps := big.NewInt(cc.StepsFor(t, n))
 	ok := cc.frame.deductSteps(steps)
 	cc.frame.log.TSystemf("STEP apply type=%s count=%d cost=%s total=%s", t, n, steps, &cc.frame.stepUsed)
+	cc.frame.log.OnStepApply(string(t), steps)
 	return ok
 }
 
@@ -540,6 +545,7 @@ func (cc *callContext) DeductSteps(s *big.Int) bool {
 	defer cc.lock.Unlock()
 	ok := cc.frame.deductSteps(s)
 	cc.frame.log.TSystemf("STEP apply cost=%s total=%d", s, &cc.frame.stepUsed)
+	cc.frame.log.OnStepApply("", s)
 	return ok
 }
 
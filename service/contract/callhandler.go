@@ -162,6 +162,17 @@ func (h *CallHandler) contract(as state.AccountState) state.ContractState {
 	}
 }
 
+func (h *CallHandler) TraceCall() *module.TraceCall {
+	call := h.CommonHandler.TraceCall()
+	call.Method = h.name
+	if h.paramObj != nil {
+		call.Params, _ = common.DecodeAnyForJSON(h.paramObj)
+	} else if h.params != nil {
+		call.Params = json.RawMessage(h.params)
+	}
+	return call
+}
+
 func (h *CallHandler) TLogStart() {
 	h.Log.TSystemf("INVOKE start score=%s method=%s", h.To, h.name)
 }
@@ -212,6 +223,12 @@ func (h *CallHandler) DoExecuteAsync(cc CallContext, ch eeproxy.CallContext, sto
 	if store != nil {
 		h.store = store
 	}
+	if h.Log.TraceMode() == module.TraceModeInvoke {
+		h.store = containerdb.NewBytesStoreStateWithTrace(h.store,
+			func(key, before, after []byte) {
+				h.Log.OnStorageChange(h.To, key, before, after)
+			})
+	}
 	c := h.contract(h.as)
 	if c == nil || c.Status() != state.CSActive {
 		return scoreresult.New(module.StatusContractNotFound, "NotAContractAccount")
@@ -417,6 +434,33 @@ func (h *CallHandler) EEType() state.EEType {
 	return c.EEType()
 }
 
+// traceStorageSteps notifies steps for the storage access, which are used
+// by the execution engine, to the tracer. Steps are calculated with the step
+// costs in the same way as the execution engine.
+func (h *CallHandler) traceStorageSteps(t state.StepType, old, value []byte) {
+	if h.Log.TraceMode() != module.TraceModeInvoke {
+		return
+	}
+	var steps int64
+	switch t {
+	case state.StepTypeGet:
+		steps = h.cc.StepsFor(state.StepTypeGetBase, 1) +
+			h.cc.StepsFor(state.StepTypeGet, len(value))
+	case state.StepTypeSet:
+		steps = h.cc.StepsFor(state.StepTypeSetBase, 1) +
+			h.cc.StepsFor(state.StepTypeSet, len(value))
+	case state.StepTypeReplace:
+		steps = (h.cc.StepsFor(state.StepTypeSetBase, 1)+
+			h.cc.StepsFor(state.StepTypeDeleteBase, 1))/2 +
+			h.cc.StepsFor(state.StepTypeDelete, len(old)) +
+			h.cc.StepsFor(state.StepTypeSet, len(value))
+	case state.StepTypeDelete:
+		steps = h.cc.StepsFor(state.StepTypeDeleteBase, 1) +
+			h.cc.StepsFor(state.StepTypeDelete, len(old))
+	}
+	h.Log.OnStepEstimate(string(t), big.NewInt(steps))
+}
+
 func (h *CallHandler) GetValue(key []byte) ([]byte, error) {
 	if h.store != nil {
 		var value []byte
@@ -428,6 +472,7 @@ func (h *CallHandler) GetValue(key []byte) ([]byte, error) {
 			h.Log.TSystemf("GETVALUE key=<%x> err=%+v", key, err)
 		} else {
 			h.Log.TSystemf("GETVALUE key=<%x> value=<%x>", key, value)
+			h.traceStorageSteps(state.StepTypeGet, nil, value)
 		}
 		return value, err
 	} else {
@@ -451,6 +496,11 @@ func (h *CallHandler) SetValue(key []byte, value []byte) ([]byte, error) {
 			h.Log.TSystemf("SETVALUE key=<%x> value=<%x> err=%+v", key, value, err)
 		} else {
 			h.Log.TSystemf("SETVALUE key=<%x> value=<%x> old=<%x>", key, value, old)
+			if old != nil {
+				h.traceStorageSteps(state.StepTypeReplace, old, value)
+			} else {
+				h.traceStorageSteps(state.StepTypeSet, nil, value)
+			}
 		}
 		return old, err
 	} else {
@@ -474,6 +524,7 @@ func (h *CallHandler) DeleteValue(key []byte) ([]byte, error) {
 			h.Log.TSystemf("DELETE key=<%x> err=%+v", key, err)
 		} else {
 			h.Log.TSystemf("DELETE key=<%x> old=<%x>", key, old)
+			h.traceStorageSteps(state.StepTypeDelete, old, nil)
 		}
 		return old, err
 	} else {
@@ -518,6 +569,7 @@ func (h *CallHandler) ArrayDBContains(prefix, value []byte, limit int64) (bool,
 	})
 	h.Log.TSystemf("CONTAINS prefix=<%x> value=<%x> found=%v count=%d size=%d",
 		prefix, value, found, count, size)
+	h.Log.OnStepEstimate(string(state.StepTypeGet), big.NewInt(cost))
 	return found, count, size, nil
 }
 
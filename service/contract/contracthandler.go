@@ -95,3 +95,25 @@ func (h *CommonHandler) TraceLogger() *trace.Logger {
 func (h *CommonHandler) Logger() log.Logger {
 	return h.Log
 }
+
+func (h *CommonHandler) TraceCall() *module.TraceCall {
+	return &module.TraceCall{
+		Type:  "call",
+		From:  h.From,
+		To:    h.To,
+		Value: h.Value,
+	}
+}
+
+// TraceCallHandler is implemented by the handler which provides the
+// information of the call to the tracer.
+type TraceCallHandler interface {
+	TraceCall() *module.TraceCall
+}
+
+func TraceCallOf(handler ContractHandler) *module.TraceCall {
+	if h, ok := handler.(TraceCallHandler); ok {
+		return h.TraceCall()
+	}
+	return &module.TraceCall{Type: "unknown"}
+}
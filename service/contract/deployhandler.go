@@ -28,6 +28,7 @@ type DeployHandler struct {
 	contentType    string
 	params         []byte
 	preDefinedAddr module.Address
+	override       bool
 }
 
 type ContentBytes struct {
@@ -180,6 +181,26 @@ func NewDeployHandlerForPreInstall(owner, scoreAddr module.Address, contentType
 	}
 }
 
+// NewDeployHandlerForOverride returns a handler installing the contract
+// at the address. If the contract exists, it updates the contract.
+// It's used to override the code of the contract in a state which is
+// never committed.
+func NewDeployHandlerForOverride(owner, scoreAddr module.Address, contentType string,
+	content []byte, params []byte, log log.Logger,
+) *DeployHandler {
+	var zero big.Int
+	eeType, _ := state.EETypeFromContentType(contentType)
+	return &DeployHandler{
+		CommonHandler:  NewCommonHandler(owner, state.SystemAddress, &zero, false, log),
+		content:        &ContentBytes{Bytes: content},
+		contentType:    contentType,
+		preDefinedAddr: scoreAddr,
+		override:       true,
+		eeType:         eeType,
+		params:         params,
+	}
+}
+
 // genContractAddr generate new contract address
 // nonce, timestamp, from
 // data = from(20 bytes) + timestamp (32 bytes) + if exists, nonce (32 bytes)
@@ -223,6 +244,15 @@ func genContractAddr(from module.Address, timestamp int64, nonce, salt *big.Int)
 	return addr
 }
 
+func (h *DeployHandler) TraceCall() *module.TraceCall {
+	call := h.CommonHandler.TraceCall()
+	call.Type = "deploy"
+	if h.params != nil {
+		call.Params = json.RawMessage(h.params)
+	}
+	return call
+}
+
 func (h *DeployHandler) Prepare(ctx Context) (state.WorldContext, error) {
 	lq := []state.LockRequest{
 		{state.WorldIDStr, state.AccountWriteLock},
@@ -284,6 +314,9 @@ func (h *DeployHandler) DoExecuteSync(cc CallContext) (error, *codec.TypedObj, m
 			contractID = genContractAddr(h.From, txInfo.Timestamp, txInfo.Nonce, salt)
 		}
 		as = cc.GetAccountState(contractID)
+		if h.override && as.IsContract() {
+			update = true
+		}
 	} else { // deploy for update
 		if !h.To.IsContract() {
 			return scoreresult.InvalidParameterError.Errorf(
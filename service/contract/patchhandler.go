@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 
 	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/intconv"
 	"github.com/icon-project/goloop/module"
 	"github.com/icon-project/goloop/service/scoredb"
 	"github.com/icon-project/goloop/service/scoreresult"
@@ -20,6 +21,15 @@ type patchHandler struct {
 	patch *Patch
 }
 
+func (h *patchHandler) TraceCall() *module.TraceCall {
+	call := h.CommonHandler.TraceCall()
+	call.Type = "patch"
+	if h.patch != nil {
+		call.Method = h.patch.Type
+	}
+	return call
+}
+
 func (h *patchHandler) Prepare(ctx Context) (state.WorldContext, error) {
 	lq := []state.LockRequest{
 		{state.WorldIDStr, state.AccountWriteLock},
@@ -77,6 +87,68 @@ func (h *patchHandler) handleSkipTransaction(cc CallContext) error {
 	return nil
 }
 
+// DoubleSignHandler is implemented by the extension state of the platform
+// imposing the penalty on the validator signed conflicting votes.
+type DoubleSignHandler interface {
+	HandleDoubleSign(cc CallContext, signer module.Address, height int64) error
+}
+
+func (h *patchHandler) handleDoubleSign(cc CallContext) error {
+	decode := cc.PatchDecoder()
+	if decode == nil {
+		h.Log.Warn("PatchHandler: patch decoder isn't set")
+		return scoreresult.InvalidParameterError.New("PatchDecoderIsNil")
+	}
+	pd, err := decode(h.patch.Type, h.patch.Data)
+	if err != nil {
+		h.Log.Warnf("PatchHandler: decode fail err=%+v", err)
+		return scoreresult.InvalidParameterError.Wrap(err, "DecodeFail")
+	}
+	p := pd.(module.DoubleSignPatch)
+	if p.Height() < 1 || p.Height() > cc.BlockHeight() {
+		return scoreresult.InvalidParameterError.Errorf("InvalidHeight(bh=%d,ph=%d)",
+			cc.BlockHeight(), p.Height())
+	}
+	if !cc.Revision().Has(module.AcceptDoubleSign) {
+		return scoreresult.InvalidRequestError.New("DoubleSignPatchDisabled")
+	}
+
+	// validators in the initial world state sign the votes from the height
+	// recorded on the last change, so older evidence can't be verified.
+	as := cc.GetAccountState(state.SystemID)
+	since := scoredb.NewVarDB(as, state.VarValidatorsSince).Int64()
+	if p.Height() < since {
+		return scoreresult.InvalidParameterError.Errorf(
+			"EvidenceBeforeValidatorsChange(height=%d,since=%d)", p.Height(), since)
+	}
+	wss := cc.GetProperty(PropInitialSnapshot).(state.WorldSnapshot)
+	if err := p.Verify(wss.GetValidatorSnapshot()); err != nil {
+		h.Log.Warnf("FailToVerifyDoubleSignPatch(err=%v)", err)
+		return scoreresult.InvalidParameterError.Wrap(err, "VerifyDoubleSignPatchFail")
+	}
+
+	// only one evidence is accepted for each height of the signer.
+	signer := p.Signer()
+	reported := scoredb.NewDictDB(as, state.VarDoubleSignReported, 1)
+	if v := reported.Get(signer); v != nil && v.Int64() >= p.Height() {
+		return scoreresult.InvalidParameterError.Errorf(
+			"AlreadyReported(signer=%s,height=%d)", signer, v.Int64())
+	}
+	if err := reported.Set(signer, p.Height()); err != nil {
+		return err
+	}
+	cc.OnEvent(state.SystemAddress,
+		[][]byte{[]byte("DoubleSign(Address,int)"), signer.Bytes()},
+		[][]byte{intconv.Int64ToBytes(p.Height())},
+	)
+	h.Log.Warnf("PatchHandler: DOUBLE SIGN signer=%s height=%d", signer, p.Height())
+
+	if dh, ok := cc.GetExtensionState().(DoubleSignHandler); ok {
+		return dh.HandleDoubleSign(cc, signer, p.Height())
+	}
+	return nil
+}
+
 func (h *patchHandler) ExecuteSync(cc CallContext) (error, *codec.TypedObj, module.Address) {
 	vs := cc.GetValidatorState()
 	if idx := vs.IndexOf(h.From); idx < 0 {
@@ -93,6 +165,9 @@ func (h *patchHandler) ExecuteSync(cc CallContext) (error, *codec.TypedObj, modu
 	case module.PatchTypeSkipTransaction:
 		s := h.handleSkipTransaction(cc)
 		return s, nil, nil
+	case module.PatchTypeDoubleSign:
+		s := h.handleDoubleSign(cc)
+		return s, nil, nil
 	default:
 		return scoreresult.InvalidParameterError.Errorf("InvalidDataType(%s)", h.patch.Type), nil, nil
 	}
@@ -117,7 +192,7 @@ func ParsePatchData(data []byte) (*Patch, error) {
 			"InvalidJSON(json=%s)", data)
 	}
 	switch p.Type {
-	case module.PatchTypeSkipTransaction:
+	case module.PatchTypeSkipTransaction, module.PatchTypeDoubleSign:
 		// do nothing
 	default:
 		return nil, scoreresult.InvalidParameterError.Errorf(
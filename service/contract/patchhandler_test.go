@@ -0,0 +1,222 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package contract
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/scoredb"
+	"github.com/icon-project/goloop/service/scoreresult"
+	"github.com/icon-project/goloop/service/state"
+)
+
+type testDoubleSignPatch struct {
+	height int64
+	signer module.Address
+	valid  bool
+}
+
+func (p *testDoubleSignPatch) Type() string {
+	return module.PatchTypeDoubleSign
+}
+
+func (p *testDoubleSignPatch) Data() []byte {
+	return nil
+}
+
+func (p *testDoubleSignPatch) Height() int64 {
+	return p.height
+}
+
+func (p *testDoubleSignPatch) Signer() module.Address {
+	return p.signer
+}
+
+func (p *testDoubleSignPatch) Verify(vl module.ValidatorList) error {
+	if !p.valid {
+		return errors.New("NotConflicting")
+	}
+	if vl.IndexOf(p.signer) < 0 {
+		return errors.Errorf("bad voter %v", p.signer)
+	}
+	return nil
+}
+
+type testDoubleSignHandler struct {
+	state.ExtensionState
+	signer module.Address
+	height int64
+}
+
+func (h *testDoubleSignHandler) HandleDoubleSign(cc CallContext, signer module.Address, height int64) error {
+	h.signer = signer
+	h.height = height
+	return nil
+}
+
+type testPatchCallContext struct {
+	CallContext
+	ws       state.WorldState
+	wss      state.WorldSnapshot
+	height   int64
+	revision module.Revision
+	patch    module.Patch
+	es       state.ExtensionState
+	events   int
+}
+
+func (cc *testPatchCallContext) PatchDecoder() module.PatchDecoder {
+	if cc.patch == nil {
+		return nil
+	}
+	return func(t string, bs []byte) (module.Patch, error) {
+		if bs == nil {
+			return nil, errors.ErrIllegalArgument
+		}
+		return cc.patch, nil
+	}
+}
+
+func (cc *testPatchCallContext) BlockHeight() int64 {
+	return cc.height
+}
+
+func (cc *testPatchCallContext) Revision() module.Revision {
+	return cc.revision
+}
+
+func (cc *testPatchCallContext) GetAccountState(id []byte) state.AccountState {
+	return cc.ws.GetAccountState(id)
+}
+
+func (cc *testPatchCallContext) GetProperty(name string) interface{} {
+	if name == PropInitialSnapshot {
+		return cc.wss
+	}
+	return nil
+}
+
+func (cc *testPatchCallContext) OnEvent(addr module.Address, indexed, data [][]byte) {
+	cc.events++
+}
+
+func (cc *testPatchCallContext) GetExtensionState() state.ExtensionState {
+	return cc.es
+}
+
+func TestPatchHandler_handleDoubleSign(t *testing.T) {
+	validator := common.MustNewAddressFromString("hx01")
+	other := common.MustNewAddressFromString("hx02")
+
+	ws := state.NewWorldState(db.NewMapDB(), nil, nil, nil, nil)
+	v, err := state.ValidatorFromAddress(validator)
+	assert.NoError(t, err)
+	assert.NoError(t, ws.GetValidatorState().Set([]module.Validator{v}))
+	wss := ws.GetSnapshot()
+
+	newContext := func(p *testDoubleSignPatch) *testPatchCallContext {
+		cc := &testPatchCallContext{
+			ws:       state.NewWorldState(db.NewMapDB(), nil, nil, nil, nil),
+			wss:      wss,
+			height:   10,
+			revision: module.LatestRevision,
+		}
+		if p != nil {
+			cc.patch = p
+		}
+		return cc
+	}
+	newHandler := func(data []byte) *patchHandler {
+		return &patchHandler{
+			CommonHandler: NewCommonHandler(validator, state.SystemAddress, nil, false, log.New()),
+			patch:         &Patch{Type: module.PatchTypeDoubleSign, Data: data},
+		}
+	}
+	data := []byte{0x00}
+
+	t.Run("NoDecoder", func(t *testing.T) {
+		cc := newContext(nil)
+		err := newHandler(data).handleDoubleSign(cc)
+		assert.True(t, scoreresult.InvalidParameterError.Equals(err))
+	})
+	t.Run("DecodeFail", func(t *testing.T) {
+		cc := newContext(&testDoubleSignPatch{height: 5, signer: validator, valid: true})
+		err := newHandler(nil).handleDoubleSign(cc)
+		assert.True(t, scoreresult.InvalidParameterError.Equals(err))
+	})
+	for _, h := range []int64{0, 11} {
+		t.Run("InvalidHeight", func(t *testing.T) {
+			cc := newContext(&testDoubleSignPatch{height: h, signer: validator, valid: true})
+			err := newHandler(data).handleDoubleSign(cc)
+			assert.True(t, scoreresult.InvalidParameterError.Equals(err))
+		})
+	}
+	t.Run("Disabled", func(t *testing.T) {
+		cc := newContext(&testDoubleSignPatch{height: 5, signer: validator, valid: true})
+		cc.revision = module.LatestRevision &^ module.AcceptDoubleSign
+		err := newHandler(data).handleDoubleSign(cc)
+		assert.True(t, scoreresult.InvalidRequestError.Equals(err))
+	})
+	t.Run("BeforeValidatorsChange", func(t *testing.T) {
+		cc := newContext(&testDoubleSignPatch{height: 5, signer: validator, valid: true})
+		as := cc.ws.GetAccountState(state.SystemID)
+		assert.NoError(t, scoredb.NewVarDB(as, state.VarValidatorsSince).Set(6))
+		err := newHandler(data).handleDoubleSign(cc)
+		assert.True(t, scoreresult.InvalidParameterError.Equals(err))
+	})
+	t.Run("VerifyFail", func(t *testing.T) {
+		cc := newContext(&testDoubleSignPatch{height: 5, signer: validator, valid: false})
+		err := newHandler(data).handleDoubleSign(cc)
+		assert.True(t, scoreresult.InvalidParameterError.Equals(err))
+
+		cc = newContext(&testDoubleSignPatch{height: 5, signer: other, valid: true})
+		err = newHandler(data).handleDoubleSign(cc)
+		assert.True(t, scoreresult.InvalidParameterError.Equals(err))
+	})
+	t.Run("Success", func(t *testing.T) {
+		cc := newContext(&testDoubleSignPatch{height: 5, signer: validator, valid: true})
+		as := cc.ws.GetAccountState(state.SystemID)
+		assert.NoError(t, scoredb.NewVarDB(as, state.VarValidatorsSince).Set(5))
+		dh := &testDoubleSignHandler{}
+		cc.es = dh
+		err := newHandler(data).handleDoubleSign(cc)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, cc.events)
+		assert.True(t, validator.Equal(dh.signer))
+		assert.Equal(t, int64(5), dh.height)
+
+		// evidence at the same or lower height is rejected
+		for _, h := range []int64{5} {
+			cc.patch = &testDoubleSignPatch{height: h, signer: validator, valid: true}
+			err = newHandler(data).handleDoubleSign(cc)
+			assert.True(t, scoreresult.InvalidParameterError.Equals(err))
+		}
+		assert.Equal(t, 1, cc.events)
+
+		// evidence at the higher height is accepted
+		cc.patch = &testDoubleSignPatch{height: 7, signer: validator, valid: true}
+		assert.NoError(t, newHandler(data).handleDoubleSign(cc))
+		assert.Equal(t, int64(7), dh.height)
+	})
+}
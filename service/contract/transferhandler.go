@@ -33,6 +33,12 @@ func (h *TransferHandler) ExecuteSync(cc CallContext) (err error, ro *codec.Type
 	return h.DoExecuteSync(cc)
 }
 
+func (h *TransferHandler) TraceCall() *module.TraceCall {
+	call := h.CommonHandler.TraceCall()
+	call.Type = "transfer"
+	return call
+}
+
 func (h *TransferHandler) DoExecuteSync(cc CallContext) (err error, ro *codec.TypedObj, addr module.Address) {
 	if cc.QueryMode() {
 		return scoreresult.AccessDeniedError.New("TransferIsNotAllowed"), nil, nil
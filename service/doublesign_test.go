@@ -0,0 +1,106 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common/wallet"
+	"github.com/icon-project/goloop/consensus"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/state"
+	"github.com/icon-project/goloop/test"
+)
+
+func newDoubleSignPatch(w module.Wallet, height int64, ts int64) module.Patch {
+	vote := func(id string) *consensus.VoteMessage {
+		return consensus.NewVoteMessage(
+			w, consensus.VoteTypePrevote, height, 0, []byte(id),
+			nil, ts, nil, nil, 0,
+		)
+	}
+	return consensus.NewDoubleSignPatch(vote("block1"), vote("block2"))
+}
+
+// finalizePatchBlock makes a block including patch transactions, and returns
+// the receipts of them. Patch transactions are executed along with the
+// normal transactions of the previous block, so the block has the result.
+func finalizePatchBlock(t *testing.T, nd *test.Node) []module.Receipt {
+	nd.ProposeFinalizeBlock(nd.NewVoteListForLastBlock())
+	ptxs := nd.LastBlock.PatchTransactions()
+	assert.NotEmpty(t, ptxs.Hash())
+
+	rl, err := nd.SM.ReceiptListFromResult(nd.LastBlock.Result(), module.TransactionGroupPatch)
+	assert.NoError(t, err)
+	var rcts []module.Receipt
+	for itr := rl.Iterator(); itr.Has(); assert.NoError(t, itr.Next()) {
+		r, err := itr.Get()
+		assert.NoError(t, err)
+		rcts = append(rcts, r)
+	}
+	return rcts
+}
+
+func TestTransition_DoubleSignPatch(t *testing.T) {
+	w := wallet.New()
+	gs := fmt.Sprintf(`{
+		"accounts": [
+			{"name": "treasury", "address": "hx1000000000000000000000000000000000000000", "balance": "0x0"},
+			{"name": "god", "address": "hx0000000000000000000000000000000000000000", "balance": "0x0"}
+		],
+		"message": "",
+		"nid": "0x1",
+		"chain": {"revision": "0x9", "validatorList": ["%s"]}
+	}`, w.Address())
+	nd := test.NewNode(t, test.UseGenesis(gs), test.UseWallet(w))
+	defer nd.Close()
+
+	nd.ProposeFinalizeBlock(consensus.NewEmptyCommitVoteList())
+	nd.ProposeFinalizeBlock(nd.NewVoteListForLastBlock())
+	assert.EqualValues(t, 2, nd.LastBlock.Height())
+
+	// validators are known from height 2, so evidence at height 1 is rejected.
+	ts := nd.LastBlock.Timestamp()
+	assert.NoError(t, nd.SM.SendPatch(newDoubleSignPatch(w, 1, ts)))
+	rcts := finalizePatchBlock(t, nd)
+	assert.Len(t, rcts, 1)
+	assert.Equal(t, module.StatusInvalidParameter, rcts[0].Status())
+
+	assert.NoError(t, nd.SM.SendPatch(newDoubleSignPatch(w, 2, ts)))
+	rcts = finalizePatchBlock(t, nd)
+	assert.Len(t, rcts, 1)
+	assert.Equal(t, module.StatusSuccess, rcts[0].Status())
+	var logs int
+	for itr := rcts[0].EventLogIterator(); itr.Has(); assert.NoError(t, itr.Next()) {
+		ev, err := itr.Get()
+		assert.NoError(t, err)
+		assert.True(t, state.SystemAddress.Equal(ev.Address()))
+		assert.Equal(t, []byte("DoubleSign(Address,int)"), ev.Indexed()[0])
+		assert.Equal(t, w.Address().Bytes(), ev.Indexed()[1])
+		logs++
+	}
+	assert.Equal(t, 1, logs)
+
+	// the same evidence is accepted only once.
+	assert.NoError(t, nd.SM.SendPatch(newDoubleSignPatch(w, 2, ts)))
+	rcts = finalizePatchBlock(t, nd)
+	assert.Len(t, rcts, 1)
+	assert.Equal(t, module.StatusInvalidParameter, rcts[0].Status())
+}
@@ -15,6 +15,8 @@ const (
 	NotContractAddressError
 	InvalidPatchDataError
 	CommittedTransactionError
+	ReplacedTransactionError
+	UnderpricedTransactionError
 )
 
 var (
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"math/big"
@@ -82,6 +83,9 @@ func NewManager(chain module.Chain, nm module.NetworkManager,
 	pTxPool := NewTransactionPool(module.TransactionGroupPatch, chain.PatchTxPoolSize(), tim, pMetric, logger)
 	nTxPool := NewTransactionPool(module.TransactionGroupNormal, chain.NormalTxPoolSize(), tim, nMetric, logger)
 	tm := NewTransactionManager(chain.NID(), tsc, pTxPool, nTxPool, tim, logger)
+	if idx := chain.AddressIndex(); idx != nil {
+		tm.SetAddressIndex(idx)
+	}
 	syncm := ssync.NewSyncManager(chain.Database(), chain.NetworkManager(), plt, logger)
 
 	mgr := &manager{
@@ -189,7 +193,8 @@ func (m *manager) CreateTransition(
 }
 
 func (m *manager) SendPatch(data module.Patch) error {
-	if data.Type() == module.PatchTypeSkipTransaction {
+	switch data.Type() {
+	case module.PatchTypeSkipTransaction:
 		patch, ok := data.(module.SkipTransactionPatch)
 		if !ok {
 			return InvalidPatchDataError.New("Invalid Skip Transaction Patch Data")
@@ -200,11 +205,41 @@ func (m *manager) SendPatch(data module.Patch) error {
 		}
 		m.skipTxPatch.Store(patch)
 		return nil
-	} else {
+	case module.PatchTypeDoubleSign:
+		return m.sendDoubleSignPatch(data)
+	default:
 		return InvalidPatchDataError.New("UnknownPatch")
 	}
 }
 
+// sendDoubleSignPatch puts the patch transaction for the evidence into the
+// patch pool, and propagates it to other validators, so that any proposer
+// may include it.
+func (m *manager) sendDoubleSignPatch(data module.Patch) error {
+	patch, ok := data.(module.DoubleSignPatch)
+	if !ok {
+		return InvalidPatchDataError.New("Invalid Double Sign Patch Data")
+	}
+	if patch.Height() < 1 {
+		return InvalidPatchDataError.Errorf(
+			"InvalidHeightValue(height=%d)", patch.Height())
+	}
+	tx, err := transaction.NewPatchTransaction(
+		patch, m.chain.NID(), common.UnixMicroFromTime(time.Now()), m.chain.Wallet())
+	if err != nil {
+		return err
+	}
+	if err := m.tm.Add(tx, true, true); err != nil {
+		return err
+	}
+	if err := m.txReactor.PropagateTransaction(tx); err != nil {
+		if !network.NotAvailableError.Equals(err) {
+			m.log.Tracef("FAIL to propagate patch tx err=%+v", err)
+		}
+	}
+	return nil
+}
+
 // GetPatches returns all patch transactions based on the parent transition.
 // If it doesn't have any patches, it returns nil.
 func (m *manager) GetPatches(parent module.Transition, bi module.BlockInfo) module.TransactionList {
@@ -289,7 +324,7 @@ func (m *manager) Finalize(t module.Transition, opt int) error {
 			if err := tst.finalizeResult(false, keepParent); err != nil {
 				return err
 			}
-			m.tm.NotifyFinalized(tst.patchTransactions, tst.patchReceipts, tst.normalTransactions, tst.normalReceipts)
+			m.tm.NotifyFinalized(tst.bi.Height(), tst.patchTransactions, tst.patchReceipts, tst.normalTransactions, tst.normalReceipts)
 			now := time.Now()
 			m.patchMetric.OnFinalize(tst.patchTransactions.Hash(), now)
 			m.normalMetric.OnFinalize(tst.normalTransactions.Hash(), now)
@@ -460,9 +495,10 @@ func (m *manager) Call(resultHash []byte,
 	vl module.ValidatorList, js []byte, bi module.BlockInfo,
 ) (interface{}, error) {
 	type callJSON struct {
-		To       common.Address  `json:"to"`
-		DataType *string         `json:"dataType"`
-		Data     json.RawMessage `json:"data"`
+		To            common.Address  `json:"to"`
+		DataType      *string         `json:"dataType"`
+		Data          json.RawMessage `json:"data"`
+		StateOverride json.RawMessage `json:"stateOverride"`
 	}
 
 	var jso callJSON
@@ -472,14 +508,28 @@ func (m *manager) Call(resultHash []byte,
 	if jso.DataType == nil || *jso.DataType != contract.DataTypeCall {
 		return nil, InvalidQueryError.New("InvalidDataType")
 	}
+	so, err := parseStateOverride(jso.StateOverride)
+	if err != nil {
+		return nil, InvalidQueryError.Wrap(err, "InvalidStateOverride")
+	}
 
-	var wc state.WorldContext
-	if wss, err := m.trc.GetWorldSnapshot(resultHash, vl.Hash()); err == nil {
-		ws := state.NewReadOnlyWorldState(wss)
-		wc = state.NewWorldContext(ws, bi, nil, m.plt)
-	} else {
+	wss, err := m.trc.GetWorldSnapshot(resultHash, vl.Hash())
+	if err != nil {
 		return nil, err
 	}
+	if len(so) > 0 {
+		ws, err := state.WorldStateFromSnapshot(wss)
+		if err != nil {
+			return nil, err
+		}
+		wc := state.NewWorldContext(ws, bi, nil, m.plt)
+		ctx := contract.NewContext(wc, m.cm, m.eem, m.chain, m.log, nil, eeproxy.ForQuery)
+		if err := so.Apply(ctx); err != nil {
+			return nil, err
+		}
+		wss = ws.GetSnapshot()
+	}
+	wc := state.NewWorldContext(state.NewReadOnlyWorldState(wss), bi, nil, m.plt)
 
 	qh, err := NewQueryHandler(m.cm, &jso.To, jso.Data)
 	if err != nil {
@@ -697,6 +747,14 @@ func (m *manager) GetMinimizeBlockGen(result []byte) bool {
 	return scoredb.NewVarDB(as, state.VarMinimizeBlockGen).Bool()
 }
 
+func (m *manager) GetConsensusTimeouts(result []byte) *module.ConsensusTimeouts {
+	as, err := m.getSystemByteStoreState(result)
+	if err != nil {
+		return nil
+	}
+	return state.GetConsensusTimeouts(as)
+}
+
 func (m *manager) GetNextBlockVersion(result []byte) int {
 	if result == nil {
 		return m.plt.DefaultBlockVersionFor(m.chain.CID())
@@ -801,6 +859,18 @@ func (m *manager) HasTransaction(id []byte) bool {
 	return m.tm.HasTx(id)
 }
 
+func (m *manager) GetPendingTransactions(from module.Address, max int) []*module.PendingTransaction {
+	return m.tm.PendingTransactions(from, max)
+}
+
+func (m *manager) GetTransactionStatus(id []byte) (module.TransactionStatus, error) {
+	return m.tm.GetStatus(id)
+}
+
+func (m *manager) WatchTxPool(cb func(ev *module.TxPoolEvent)) func() {
+	return m.tm.Watch(cb)
+}
+
 func (m *manager) WaitForTransaction(
 	parent module.Transition,
 	bi module.BlockInfo,
@@ -813,33 +883,41 @@ func (m *manager) WaitForTransaction(
 	return m.tm.Wait(wc, cb)
 }
 
-func (m *manager) ExportResult(result []byte, vh []byte, d db.Database) error {
+// RequestResult requests all entries related with the result to the
+// builder. Requested entries are verified by the builder with their hashes.
+func RequestResult(builder merkle.Builder, plt base.Platform, result []byte, vh []byte) error {
 	r, err := newTransitionResultFromBytes(result)
 	if err != nil {
 		return err
 	}
+	txresult.NewReceiptListWithBuilder(builder, r.NormalReceiptHash)
+	txresult.NewReceiptListWithBuilder(builder, r.PatchReceiptHash)
+	es := plt.NewExtensionWithBuilder(builder, r.ExtensionData)
+	_, err = state.NewWorldSnapshotWithBuilder(builder, r.StateHash, vh, es, r.BTPData)
+	return err
+}
+
+func (m *manager) ExportResult(result []byte, vh []byte, d db.Database) error {
 	e := merkle.NewCopyContext(m.db, d)
-	txresult.NewReceiptListWithBuilder(e.Builder(), r.NormalReceiptHash)
-	txresult.NewReceiptListWithBuilder(e.Builder(), r.PatchReceiptHash)
-	ess := m.plt.NewExtensionWithBuilder(e.Builder(), r.ExtensionData)
-	state.NewWorldSnapshotWithBuilder(e.Builder(), r.StateHash, vh, ess, r.BTPData)
+	if err := RequestResult(e.Builder(), m.plt, result, vh); err != nil {
+		return err
+	}
 	return e.Run()
 }
 
 func (m *manager) ImportResult(result []byte, vh []byte, src db.Database) error {
-	r, err := newTransitionResultFromBytes(result)
-	if err != nil {
+	e := merkle.NewCopyContext(src, m.db)
+	if err := RequestResult(e.Builder(), m.plt, result, vh); err != nil {
 		return err
 	}
-	e := merkle.NewCopyContext(src, m.db)
-	txresult.NewReceiptListWithBuilder(e.Builder(), r.NormalReceiptHash)
-	txresult.NewReceiptListWithBuilder(e.Builder(), r.PatchReceiptHash)
-	es := m.plt.NewExtensionWithBuilder(e.Builder(), r.ExtensionData)
-	state.NewWorldSnapshotWithBuilder(e.Builder(), r.StateHash, vh, es, r.BTPData)
 	return e.Run()
 }
 
 func (m *manager) ExecuteTransaction(result []byte, vh []byte, js []byte, bi module.BlockInfo) (module.Receipt, error) {
+	js, so, err := splitStateOverride(js)
+	if err != nil {
+		return nil, scoreresult.InvalidParameterError.Wrap(err, "InvalidStateOverride")
+	}
 	tx, err := transaction.NewTransactionFromJSON(js)
 	if err != nil {
 		return nil, err
@@ -866,6 +944,12 @@ func (m *manager) ExecuteTransaction(result []byte, vh []byte, js []byte, bi mod
 		return nil, err
 	}
 	ctx := contract.NewContext(wc, m.cm, m.eem, m.chain, m.log, nil, eeproxy.ForQuery)
+	if len(so) > 0 {
+		if err := so.Apply(ctx); err != nil {
+			return nil, err
+		}
+		wss = ctx.GetSnapshot()
+	}
 	ctx.SetTransactionInfo(&state.TransactionInfo{
 		Group:     module.TransactionGroupNormal,
 		Index:     0,
@@ -879,6 +963,124 @@ func (m *manager) ExecuteTransaction(result []byte, vh []byte, js []byte, bi mod
 	return txh.Execute(ctx, wss, true)
 }
 
+// simulatedBlock provides the receipt of the simulated transaction
+// to the trace logger.
+type simulatedBlock struct {
+	rct module.Receipt
+}
+
+func (b *simulatedBlock) ID() []byte {
+	return nil
+}
+
+func (b *simulatedBlock) GetReceipt(txIndex int) module.Receipt {
+	return b.rct
+}
+
+func (m *manager) SimulateTransaction(result []byte, vh []byte, js []byte, bi module.BlockInfo, cb module.TraceCallback) (module.Receipt, error) {
+	js, so, err := splitStateOverride(js)
+	if err != nil {
+		return nil, scoreresult.InvalidParameterError.Wrap(err, "InvalidStateOverride")
+	}
+	tx, err := transaction.NewTransactionFromJSON(js)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Verify(); err != nil && !transaction.InvalidSignatureError.Equals(err) {
+		return nil, scoreresult.InvalidParameterError.Wrap(err, "InvalidTransaction")
+	}
+	if tx.Group() != module.TransactionGroupNormal {
+		return nil, scoreresult.InvalidParameterError.New("InvalidTransactionGroup")
+	}
+
+	txh, err := tx.GetHandler(m.cm)
+	if err != nil {
+		return nil, err
+	}
+	defer txh.Dispose()
+
+	wss, err := m.trc.GetWorldSnapshot(result, vh)
+	if err != nil {
+		return nil, err
+	}
+	ws, err := state.WorldStateFromSnapshot(wss)
+	if err != nil {
+		return nil, err
+	}
+	wc := state.NewWorldContext(ws, bi, nil, m.plt)
+	if len(so) > 0 {
+		ctx := contract.NewContext(wc, m.cm, m.eem, m.chain, m.log, nil, eeproxy.ForQuery)
+		if err := so.Apply(ctx); err != nil {
+			return nil, err
+		}
+	}
+	// apply the same checks as SendTransaction on the overridden state
+	if err := tx.PreValidate(wc, false); err != nil {
+		return nil, err
+	}
+
+	blk := new(simulatedBlock)
+	ti := &module.TraceInfo{
+		TraceMode:  module.TraceModeBalanceChange,
+		TraceBlock: blk,
+		Range:      module.TraceRangeTransaction,
+		Group:      module.TransactionGroupNormal,
+		Index:      0,
+		Callback:   cb,
+	}
+	ctx := contract.NewContext(wc, m.cm, m.eem, m.chain, m.log, ti, eeproxy.ForQuery)
+	txInfo := &state.TransactionInfo{
+		Group:     module.TransactionGroupNormal,
+		Index:     0,
+		Hash:      tx.ID(),
+		From:      tx.From(),
+		Timestamp: tx.Timestamp(),
+		Nonce:     tx.Nonce(),
+	}
+	ctx.SetTransactionInfo(txInfo)
+	ctx.UpdateSystemInfo()
+
+	wcs := ctx.GetSnapshot()
+	tlog := ctx.GetTraceLogger(module.EPhaseTransaction)
+	tlog.OnTransactionStart(0, tx.ID())
+	rct, err := txh.Execute(ctx, wcs, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.plt.OnTransactionEnd(ctx, m.log, rct); err != nil {
+		return nil, err
+	}
+	blk.rct = rct
+	tlog.OnTransactionEnd(0, tx.ID(), txInfo.From, ctx.Treasury(), ctx.Revision(), rct)
+	return rct, nil
+}
+
 func (m *manager) AddSyncRequest(id db.BucketID, key []byte) error {
 	return m.syncer.AddRequest(id, key)
 }
+
+// rewardDetailer is implemented by the extension snapshots of the platforms
+// calculating rewards.
+type rewardDetailer interface {
+	RewardDetail(ctx context.Context, addr module.Address, logger log.Logger) (map[string]interface{}, error)
+}
+
+func (m *manager) GetRewardDetail(ctx context.Context, result []byte, addr module.Address) (interface{}, error) {
+	r, err := newTransitionResultFromBytes(result)
+	if err != nil {
+		return nil, err
+	}
+	rd, ok := m.plt.NewExtensionSnapshot(m.db, r.ExtensionData).(rewardDetailer)
+	if !ok {
+		return nil, errors.UnsupportedError.New("NoRewardCalculation")
+	}
+	detail, err := rd.RewardDetail(ctx, addr, m.log)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := common.EncodeAny(detail)
+	if err != nil {
+		return nil, err
+	}
+	return common.DecodeAnyForJSON(obj)
+}
@@ -22,6 +22,7 @@ import (
 	"math/big"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/icon-project/goloop/common"
 	"github.com/icon-project/goloop/common/errors"
@@ -576,6 +577,28 @@ var chainMethods = []*chainMethod{
 		},
 		nil,
 	}, Revision9, 0},
+	{scoreapi.Method{
+		scoreapi.Function, "setConsensusTimeouts",
+		scoreapi.FlagExternal, 7,
+		[]scoreapi.Parameter{
+			{"propose", scoreapi.Integer, nil, nil},
+			{"prevote", scoreapi.Integer, nil, nil},
+			{"precommit", scoreapi.Integer, nil, nil},
+			{"newRound", scoreapi.Integer, nil, nil},
+			{"max", scoreapi.Integer, nil, nil},
+			{"increase", scoreapi.Integer, nil, nil},
+			{"adaptive", scoreapi.Bool, nil, nil},
+		},
+		nil,
+	}, Revision9, 0},
+	{scoreapi.Method{
+		scoreapi.Function, "getConsensusTimeouts",
+		scoreapi.FlagReadOnly | scoreapi.FlagExternal, 0,
+		nil,
+		[]scoreapi.DataType{
+			scoreapi.Dict,
+		},
+	}, Revision9, 0},
 }
 
 func (s *ChainScore) GetAPI() *scoreapi.Info {
@@ -614,6 +637,15 @@ type Chain struct {
 	DepositTerm        *common.HexInt64  `json:"depositTerm"`
 	DepositIssueRate   *common.HexInt64  `json:"depositIssueRate"`
 	FeeSharingEnabled  *common.HexInt16  `json:"feeSharingEnabled"`
+	ConsensusTimeouts  *struct {
+		Propose   common.HexInt64 `json:"propose"`
+		Prevote   common.HexInt64 `json:"prevote"`
+		Precommit common.HexInt64 `json:"precommit"`
+		NewRound  common.HexInt64 `json:"newRound"`
+		Max       common.HexInt64 `json:"max"`
+		Increase  common.HexInt64 `json:"increase"`
+		Adaptive  common.HexInt16 `json:"adaptive"`
+	} `json:"consensusTimeouts"`
 }
 
 func (s *ChainScore) Install(param []byte) error {
@@ -694,6 +726,19 @@ func (s *ChainScore) Install(param []byte) error {
 		}
 	}
 
+	if ct := chain.ConsensusTimeouts; ct != nil {
+		timeouts, err := newConsensusTimeouts(
+			ct.Propose.Value, ct.Prevote.Value, ct.Precommit.Value,
+			ct.NewRound.Value, ct.Max.Value, ct.Increase.Value,
+			ct.Adaptive.Value != 0)
+		if err != nil {
+			return scoreresult.IllegalFormatError.Wrap(err, "InvalidConsensusTimeouts")
+		}
+		if err := state.SetConsensusTimeouts(as, timeouts); err != nil {
+			return err
+		}
+	}
+
 	if chain.DepositTerm != nil {
 		if chain.DepositTerm.Value < 0 {
 			return scoreresult.IllegalFormatError.Errorf("InvalidDepositTerm(%s)", chain.DepositTerm)
@@ -1472,6 +1517,85 @@ func (s *ChainScore) Ex_setRoundLimitFactor(f *common.HexInt) error {
 	return factor.Set(f)
 }
 
+const (
+	maxConsensusTimeoutIncrease = 1000
+	maxConsensusTimeout         = int64(10 * time.Minute / time.Millisecond)
+)
+
+func isValidConsensusTimeout(ms int64) bool {
+	return ms > 0 && ms <= maxConsensusTimeout
+}
+
+func newConsensusTimeouts(
+	propose, prevote, precommit, newRound, max, increase int64, adaptive bool,
+) (*module.ConsensusTimeouts, error) {
+	if !isValidConsensusTimeout(propose) || !isValidConsensusTimeout(prevote) ||
+		!isValidConsensusTimeout(precommit) || !isValidConsensusTimeout(newRound) {
+		return nil, errors.IllegalArgumentError.Errorf(
+			"InvalidTimeout(propose=%d,prevote=%d,precommit=%d,newRound=%d)",
+			propose, prevote, precommit, newRound)
+	}
+	if max < 0 || max > maxConsensusTimeout {
+		return nil, errors.IllegalArgumentError.Errorf("InvalidMaxTimeout(%d)", max)
+	}
+	if increase < 0 || increase > maxConsensusTimeoutIncrease {
+		return nil, errors.IllegalArgumentError.Errorf("InvalidIncrease(%d)", increase)
+	}
+	return &module.ConsensusTimeouts{
+		Propose:   time.Duration(propose) * time.Millisecond,
+		Prevote:   time.Duration(prevote) * time.Millisecond,
+		Precommit: time.Duration(precommit) * time.Millisecond,
+		NewRound:  time.Duration(newRound) * time.Millisecond,
+		Max:       time.Duration(max) * time.Millisecond,
+		Increase:  int(increase),
+		Adaptive:  adaptive,
+	}, nil
+}
+
+func (s *ChainScore) Ex_setConsensusTimeouts(
+	propose, prevote, precommit, newRound, max, increase *common.HexInt, adaptive bool,
+) error {
+	if err := s.checkGovernance(true); err != nil {
+		return err
+	}
+	for _, v := range []*common.HexInt{propose, prevote, precommit, newRound, max, increase} {
+		if !v.IsInt64() {
+			return scoreresult.New(StatusIllegalArgument, "IllegalArgument")
+		}
+	}
+	timeouts, err := newConsensusTimeouts(
+		propose.Int64(), prevote.Int64(), precommit.Int64(),
+		newRound.Int64(), max.Int64(), increase.Int64(), adaptive)
+	if err != nil {
+		return scoreresult.New(StatusIllegalArgument, err.Error())
+	}
+	as := s.cc.GetAccountState(state.SystemID)
+	return state.SetConsensusTimeouts(as, timeouts)
+}
+
+func (s *ChainScore) Ex_getConsensusTimeouts() (map[string]interface{}, error) {
+	if err := s.tryChargeCall(); err != nil {
+		return nil, err
+	}
+	as := s.cc.GetAccountState(state.SystemID)
+	timeouts := state.GetConsensusTimeouts(as)
+	if timeouts == nil {
+		return nil, nil
+	}
+	ms := func(d time.Duration) int64 {
+		return int64(d / time.Millisecond)
+	}
+	return map[string]interface{}{
+		"propose":   ms(timeouts.Propose),
+		"prevote":   ms(timeouts.Prevote),
+		"precommit": ms(timeouts.Precommit),
+		"newRound":  ms(timeouts.NewRound),
+		"max":       ms(timeouts.Max),
+		"increase":  timeouts.Increase,
+		"adaptive":  timeouts.Adaptive,
+	}, nil
+}
+
 func (s *ChainScore) Ex_getMinimizeBlockGen() (bool, error) {
 	if err := s.tryChargeCall(); err != nil {
 		return false, err
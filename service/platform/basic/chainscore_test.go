@@ -0,0 +1,60 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package basic
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common/errors"
+)
+
+func TestNewConsensusTimeouts(t *testing.T) {
+	to, err := newConsensusTimeouts(1000, 2000, 3000, 4000, maxConsensusTimeout, 10, true)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Second, to.Propose)
+	assert.Equal(t, 2*time.Second, to.Prevote)
+	assert.Equal(t, 3*time.Second, to.Precommit)
+	assert.Equal(t, 4*time.Second, to.NewRound)
+	assert.Equal(t, 10*time.Minute, to.Max)
+	assert.Equal(t, 10, to.Increase)
+	assert.True(t, to.Adaptive)
+
+	cases := []struct {
+		name                                  string
+		propose, prevote, precommit, newRound int64
+		max, increase                         int64
+	}{
+		{"ZeroPropose", 0, 1000, 1000, 1000, 0, 0},
+		{"NegativePrevote", 1000, -1, 1000, 1000, 0, 0},
+		{"OverPrecommit", 1000, 1000, maxConsensusTimeout + 1, 1000, 0, 0},
+		{"OverflowNewRound", 1000, 1000, 1000, math.MaxInt64, 0, 0},
+		{"NegativeMax", 1000, 1000, 1000, 1000, -1, 0},
+		{"OverflowMax", 1000, 1000, 1000, 1000, math.MaxInt64/1000 + 1, 0},
+		{"OverIncrease", 1000, 1000, 1000, 1000, 0, maxConsensusTimeoutIncrease + 1},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := newConsensusTimeouts(c.propose, c.prevote, c.precommit,
+				c.newRound, c.max, c.increase, false)
+			assert.True(t, errors.IllegalArgumentError.Equals(err), err)
+		})
+	}
+}
@@ -0,0 +1,122 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/consensus"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service"
+	"github.com/icon-project/goloop/service/trace"
+	"github.com/icon-project/goloop/service/transaction"
+	"github.com/icon-project/goloop/test"
+)
+
+// simulateChain provides the metric context required by service.NewManager.
+type simulateChain struct {
+	*test.Chain
+}
+
+func (c *simulateChain) MetricContext() context.Context {
+	return context.Background()
+}
+
+type simulateCallback struct {
+	*trace.BalanceTracer
+}
+
+func (cb *simulateCallback) OnLog(level module.TraceLevel, msg string) {}
+
+func (cb *simulateCallback) OnEnd(e error) {}
+
+func TestManager_SimulateTransaction(t *testing.T) {
+	const (
+		god      = "hx0000000000000000000000000000000000000001"
+		receiver = "hx0000000000000000000000000000000000000002"
+	)
+	gs := fmt.Sprintf(`{
+		"accounts": [
+			{"name": "treasury", "address": "hx1000000000000000000000000000000000000000", "balance": "0x0"},
+			{"name": "god", "address": "%s", "balance": "0x1000"}
+		],
+		"message": "",
+		"nid": "0x1",
+		"chain": {"revision": "0x8"}
+	}`, god)
+	nd := test.NewNode(t, test.UseGenesis(gs))
+	defer nd.Close()
+
+	nd.ProposeFinalizeBlock(consensus.NewEmptyCommitVoteList())
+	blk := nd.LastBlock
+
+	sm, err := service.NewManager(&simulateChain{nd.Chain}, nil, nil, nd.Platform, path.Join(nd.Base, "simulate"))
+	assert.NoError(t, err)
+	defer sm.Term()
+
+	simulate := func(js string) (module.Receipt, error) {
+		bi := common.NewBlockInfo(blk.Height()+1, blk.Timestamp()+1)
+		return sm.SimulateTransaction(
+			blk.Result(), nil, []byte(js), bi,
+			&simulateCallback{trace.NewBalanceTracer(1, nil)},
+		)
+	}
+	newTx := func(to, value, data string) string {
+		return fmt.Sprintf(`{
+			"version": "0x3",
+			"from": "%s",
+			"to": "%s",
+			"value": "%s",
+			"stepLimit": "0x100000",
+			"nid": "0x1",
+			"timestamp": "%#x"%s
+		}`, god, to, value, blk.Timestamp(), data)
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		rct, err := simulate(newTx(receiver, "0x10", ""))
+		assert.NoError(t, err)
+		assert.Equal(t, module.StatusSuccess, rct.Status())
+	})
+
+	t.Run("Revert", func(t *testing.T) {
+		rct, err := simulate(newTx(
+			"cx0000000000000000000000000000000000000000", "0x0",
+			`, "dataType": "call", "data": {"method": "noSuchMethod"}`,
+		))
+		assert.NoError(t, err)
+		assert.NotEqual(t, module.StatusSuccess, rct.Status())
+	})
+
+	t.Run("NotEnoughBalance", func(t *testing.T) {
+		_, err := simulate(newTx(receiver, "0x1001", ""))
+		assert.True(t, transaction.NotEnoughBalanceError.Equals(err), err)
+
+		// balance by state override is used for the validation
+		js := newTx(receiver, "0x1001",
+			fmt.Sprintf(`, "stateOverride": {"%s": {"balance": "0x2000"}}`, god))
+		rct, err := simulate(js)
+		assert.NoError(t, err)
+		assert.Equal(t, module.StatusSuccess, rct.Status())
+	})
+}
@@ -62,6 +62,7 @@ type AccountSnapshot interface {
 
 	CanAcceptTx(pc PayContext) bool
 	CheckDeposit(pc PayContext) bool
+	PayableSteps(pc PayContext) *big.Int
 	GetDepositInfo(dc DepositContext, v module.JSONVersion) (map[string]interface{}, error)
 }
 
@@ -110,6 +111,7 @@ type AccountState interface {
 	PaySteps(pc PayContext, steps *big.Int) (*big.Int, *big.Int, error)
 	CanAcceptTx(pc PayContext) bool
 	CheckDeposit(pc PayContext) bool
+	PayableSteps(pc PayContext) *big.Int
 	GetDepositInfo(dc DepositContext, v module.JSONVersion) (map[string]interface{}, error)
 }
 
@@ -217,6 +219,15 @@ func (s *accountData) CheckDeposit(pc PayContext) bool {
 	return true
 }
 
+// PayableSteps returns the maximum steps which can be paid by the deposits
+// of the account for the fee sharing.
+func (s *accountData) PayableSteps(pc PayContext) *big.Int {
+	if pc.FeeSharingEnabled() && s.deposits.Has() {
+		return s.deposits.PayableSteps(pc.BlockHeight(), pc.StepPrice())
+	}
+	return new(big.Int)
+}
+
 func (s *accountData) GetDepositInfo(dc DepositContext, v module.JSONVersion) (
 	map[string]interface{}, error,
 ) {
@@ -0,0 +1,86 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package state
+
+import (
+	"time"
+
+	"github.com/icon-project/goloop/common/containerdb"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/scoredb"
+)
+
+const (
+	keyTimeoutPropose   = "propose"
+	keyTimeoutPrevote   = "prevote"
+	keyTimeoutPrecommit = "precommit"
+	keyTimeoutNewRound  = "newRound"
+	keyTimeoutMax       = "max"
+	keyTimeoutIncrease  = "increase"
+	keyTimeoutAdaptive  = "adaptive"
+)
+
+// GetConsensusTimeouts returns consensus timeouts stored in the store of
+// the system account. It returns nil if they are not configured.
+// Durations are stored in milliseconds.
+func GetConsensusTimeouts(store containerdb.BytesStoreState) *module.ConsensusTimeouts {
+	db := scoredb.NewDictDB(store, VarConsensusTimeouts, 1)
+	if db.Get(keyTimeoutPropose) == nil {
+		return nil
+	}
+	i64 := func(key string) int64 {
+		if v := db.Get(key); v != nil {
+			return v.Int64()
+		}
+		return 0
+	}
+	ms := func(key string) time.Duration {
+		return time.Duration(i64(key)) * time.Millisecond
+	}
+	return &module.ConsensusTimeouts{
+		Propose:   ms(keyTimeoutPropose),
+		Prevote:   ms(keyTimeoutPrevote),
+		Precommit: ms(keyTimeoutPrecommit),
+		NewRound:  ms(keyTimeoutNewRound),
+		Max:       ms(keyTimeoutMax),
+		Increase:  int(i64(keyTimeoutIncrease)),
+		Adaptive:  i64(keyTimeoutAdaptive) != 0,
+	}
+}
+
+// SetConsensusTimeouts stores consensus timeouts in the store of the system
+// account.
+func SetConsensusTimeouts(store containerdb.BytesStoreState, t *module.ConsensusTimeouts) error {
+	db := scoredb.NewDictDB(store, VarConsensusTimeouts, 1)
+	for _, kv := range []struct {
+		key   string
+		value interface{}
+	}{
+		{keyTimeoutPropose, int64(t.Propose / time.Millisecond)},
+		{keyTimeoutPrevote, int64(t.Prevote / time.Millisecond)},
+		{keyTimeoutPrecommit, int64(t.Precommit / time.Millisecond)},
+		{keyTimeoutNewRound, int64(t.NewRound / time.Millisecond)},
+		{keyTimeoutMax, int64(t.Max / time.Millisecond)},
+		{keyTimeoutIncrease, int64(t.Increase)},
+		{keyTimeoutAdaptive, t.Adaptive},
+	} {
+		if err := db.Set(kv.key, kv.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -213,6 +213,19 @@ func (dl depositList) ToJSON(dc DepositContext, v module.JSONVersion) (map[strin
 	return jso, nil
 }
 
+// PayableSteps returns the maximum steps which can be paid with the virtual
+// steps and the deposits at the height.
+func (dl depositList) PayableSteps(bh int64, price *big.Int) *big.Int {
+	steps := new(big.Int)
+	if price.Sign() <= 0 || !dl.Has() {
+		return steps
+	}
+	for _, dp := range dl {
+		steps.Add(steps, dp.GetAvailableSteps(bh))
+	}
+	return steps.Add(steps, new(big.Int).Div(dl.getAvailableDeposit(bh), price))
+}
+
 func (dl depositList) CanPay(pc PayContext) bool {
 	height := pc.BlockHeight()
 	limit := pc.FeeLimit()
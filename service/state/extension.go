@@ -16,6 +16,8 @@
 
 package state
 
+import "github.com/icon-project/goloop/common/trie"
+
 type ExtensionSnapshot interface {
 	Bytes() []byte
 	Flush() error
@@ -28,6 +30,28 @@ type ExtensionState interface {
 	ClearCache()
 }
 
+// ExtensionAccessRecorder receives the keys of the extension state accessed
+// by the transaction. A nil key is for the whole state.
+type ExtensionAccessRecorder interface {
+	OnRead(key []byte)
+	OnWrite(key []byte)
+}
+
+// RecordableExtensionState is implemented by the extension state supporting
+// recording accesses by keys. It's used for detecting conflicts between
+// transactions executed concurrently.
+type RecordableExtensionState interface {
+	ExtensionState
+	// SetAccessRecorder sets the recorder for the accesses. Use nil to
+	// stop recording.
+	SetAccessRecorder(r ExtensionAccessRecorder)
+	// GetValue returns the value for the key reported to the recorder.
+	GetValue(key []byte) (trie.Object, error)
+	// SetValues sets the values for the keys. The key is deleted if its
+	// value is nil.
+	SetValues(values map[string]trie.Object) error
+}
+
 type extensionStateHolder struct {
 	state ExtensionState
 }
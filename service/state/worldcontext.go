@@ -39,6 +39,9 @@ const (
 	VarNextBlockVersion   = "next_block_version"
 	VarEnabledEETypes     = "enabled_ee_types"
 	VarSystemDepositUsage = "system_deposit_usage"
+	VarDoubleSignReported = "double_sign_reported"
+	VarValidatorsSince    = "validators_since"
+	VarConsensusTimeouts  = "consensus_timeouts"
 )
 
 const (
@@ -377,6 +380,8 @@ func (c *worldContext) WorldStateChanged(ws WorldState) WorldContext {
 		governance:   c.governance,
 		systemInfo:   c.systemInfo,
 		blockInfo:    c.blockInfo,
+		csInfo:       c.csInfo,
+		platform:     c.platform,
 	}
 	return wc
 }
@@ -0,0 +1,695 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package state
+
+import (
+	"bytes"
+	"math/big"
+	"sync"
+
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/trie"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/scoreapi"
+)
+
+// AccountAccess is the parts of an account accessed by a transaction.
+type AccountAccess struct {
+	// Whole is for whole account including storage and balance.
+	Whole bool
+	// Meta is for the parts other than storage and balance, such as
+	// contract, owner, state flags and deposits.
+	Meta    bool
+	Balance bool
+	Keys    map[string]bool
+}
+
+// AccessSet is the set of accounts and storage keys accessed by
+// transactions.
+type AccessSet struct {
+	// World is for validators, BTP state and the extension state which
+	// can't be recorded by keys.
+	World bool
+	// Extension is for the keys of the extension state.
+	Extension map[string]bool
+	Accounts  map[string]*AccountAccess
+}
+
+func (s *AccessSet) Account(id []byte) *AccountAccess {
+	ids := string(id)
+	if aa, ok := s.Accounts[ids]; ok {
+		return aa
+	}
+	aa := &AccountAccess{Keys: make(map[string]bool)}
+	s.Accounts[ids] = aa
+	return aa
+}
+
+// Conflicts returns whether the accesses in the set are affected by
+// the modifications in dirty.
+func (s *AccessSet) Conflicts(dirty *AccessSet) bool {
+	if s.World && (dirty.World || len(dirty.Extension) > 0) {
+		return true
+	}
+	if dirty.World && len(s.Extension) > 0 {
+		return true
+	}
+	for k := range s.Extension {
+		if dirty.Extension[k] {
+			return true
+		}
+	}
+	for ids, aa := range s.Accounts {
+		da, ok := dirty.Accounts[ids]
+		if !ok {
+			continue
+		}
+		if aa.Whole || da.Whole {
+			return true
+		}
+		if (aa.Meta && da.Meta) || (aa.Balance && da.Balance) {
+			return true
+		}
+		for k := range aa.Keys {
+			if da.Keys[k] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Merge adds the accesses in the other set.
+func (s *AccessSet) Merge(o *AccessSet) {
+	s.World = s.World || o.World
+	for k := range o.Extension {
+		s.Extension[k] = true
+	}
+	for ids, oa := range o.Accounts {
+		aa := s.Account([]byte(ids))
+		aa.Whole = aa.Whole || oa.Whole
+		aa.Meta = aa.Meta || oa.Meta
+		aa.Balance = aa.Balance || oa.Balance
+		for k := range oa.Keys {
+			aa.Keys[k] = true
+		}
+	}
+}
+
+func NewAccessSet() *AccessSet {
+	return &AccessSet{
+		Extension: make(map[string]bool),
+		Accounts:  make(map[string]*AccountAccess),
+	}
+}
+
+type accountWrite struct {
+	// snapshot is for whole account. Others are ignored if it's not nil.
+	snapshot AccountSnapshot
+	balance  *big.Int
+	// values has nil for deleted keys.
+	values map[string][]byte
+}
+
+// WriteSet is the modifications of the world state by a transaction.
+type WriteSet struct {
+	// World is whether validators, BTP state or the extension state which
+	// can't be recorded by keys are modified. They can't be applied to
+	// other world state.
+	World bool
+	// extension has nil for deleted keys.
+	extension map[string]trie.Object
+	accounts  map[string]*accountWrite
+}
+
+// Footprint returns the accesses modified by the set.
+func (w *WriteSet) Footprint() *AccessSet {
+	as := NewAccessSet()
+	as.World = w.World
+	for k := range w.extension {
+		as.Extension[k] = true
+	}
+	for ids, aw := range w.accounts {
+		aa := as.Account([]byte(ids))
+		if aw.snapshot != nil {
+			aa.Whole = true
+			continue
+		}
+		aa.Balance = aw.balance != nil
+		for k := range aw.values {
+			aa.Keys[k] = true
+		}
+	}
+	return as
+}
+
+// Apply applies modifications of accounts to the world state.
+func (w *WriteSet) Apply(ws WorldState) error {
+	if w.World {
+		return errors.InvalidStateError.New("WorldStateModified")
+	}
+	if len(w.extension) > 0 {
+		es, ok := ws.GetExtensionState().(RecordableExtensionState)
+		if !ok {
+			return errors.InvalidStateError.New("NotRecordableExtensionState")
+		}
+		if err := es.SetValues(w.extension); err != nil {
+			return err
+		}
+	}
+	for ids, aw := range w.accounts {
+		as := ws.GetAccountState([]byte(ids))
+		if aw.snapshot != nil {
+			if err := as.Reset(aw.snapshot); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := applyAccountWrite(as, aw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyAccountWrite(as AccountState, aw *accountWrite) error {
+	if aw.balance != nil {
+		as.SetBalance(aw.balance)
+	}
+	for k, v := range aw.values {
+		var err error
+		if v == nil {
+			_, err = as.DeleteValue([]byte(k))
+		} else {
+			_, err = as.SetValue([]byte(k), v)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WorldStateRecorder records accesses on the world state for optimistic
+// concurrent execution of transactions.
+type WorldStateRecorder interface {
+	WorldState
+	// Reads returns the accesses on the world state.
+	Reads() *AccessSet
+	// Writes returns the modifications since the recorder is created.
+	// Accounts modified as whole are also added to the reads.
+	Writes() (*WriteSet, error)
+}
+
+type worldStateRecorder struct {
+	WorldState
+	base WorldSnapshot
+	ext  RecordableExtensionState
+
+	lock     sync.Mutex
+	reads    *AccessSet
+	accounts map[string]*accountStateRecorder
+
+	// extWritten is the keys of the extension state written.
+	extWritten map[string]bool
+	// extWhole is whether the extension state is modified in the way
+	// which can't be recorded by keys.
+	extWhole bool
+}
+
+func (r *worldStateRecorder) onRead(id []byte, f func(aa *AccountAccess)) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	f(r.reads.Account(id))
+}
+
+func (r *worldStateRecorder) GetAccountState(id []byte) AccountState {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	ids := string(id)
+	if as, ok := r.accounts[ids]; ok {
+		return as
+	}
+	as := &accountStateRecorder{
+		AccountState: r.WorldState.GetAccountState(id),
+		id:           id,
+		recorder:     r,
+		written:      make(map[string]bool),
+	}
+	r.accounts[ids] = as
+	return as
+}
+
+func (r *worldStateRecorder) GetAccountSnapshot(id []byte) AccountSnapshot {
+	r.onRead(id, func(aa *AccountAccess) { aa.Whole = true })
+	return r.WorldState.GetAccountSnapshot(id)
+}
+
+func (r *worldStateRecorder) onWorldRead() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.reads.World = true
+}
+
+func (r *worldStateRecorder) GetValidatorState() ValidatorState {
+	r.onWorldRead()
+	return r.WorldState.GetValidatorState()
+}
+
+func (r *worldStateRecorder) GetExtensionState() ExtensionState {
+	if r.ext == nil {
+		r.onWorldRead()
+	}
+	return r.WorldState.GetExtensionState()
+}
+
+func (r *worldStateRecorder) OnRead(key []byte) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if key == nil {
+		r.reads.World = true
+	} else {
+		r.reads.Extension[string(key)] = true
+	}
+}
+
+func (r *worldStateRecorder) OnWrite(key []byte) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if key == nil {
+		r.extWhole = true
+	} else {
+		r.extWritten[string(key)] = true
+	}
+}
+
+func (r *worldStateRecorder) GetBTPState() BTPState {
+	r.onWorldRead()
+	return r.WorldState.GetBTPState()
+}
+
+func (r *worldStateRecorder) Reads() *AccessSet {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.reads
+}
+
+func accountSnapshotOf(wss WorldSnapshot, id []byte) AccountSnapshot {
+	if ass := wss.GetAccountSnapshot(id); ass != nil {
+		return ass
+	}
+	return newAccountSnapshot(wss.Database())
+}
+
+func worldModified(s1, s2 WorldSnapshot, withExtension bool) bool {
+	if withExtension && !bytes.Equal(s1.ExtensionData(), s2.ExtensionData()) {
+		return true
+	}
+	if !bytes.Equal(s1.BTPData(), s2.BTPData()) {
+		return true
+	}
+	v1, v2 := s1.GetValidatorSnapshot(), s2.GetValidatorSnapshot()
+	if v1 == nil || v2 == nil {
+		return v1 != v2
+	}
+	return !bytes.Equal(v1.Hash(), v2.Hash())
+}
+
+func objectEqual(o1, o2 trie.Object) bool {
+	if o1 == nil || o2 == nil {
+		return o1 == o2
+	}
+	return o1.Equal(o2)
+}
+
+// extensionWrites returns the values of the keys modified in the extension
+// state. It returns false if the final state can't be reproduced with them.
+func (r *worldStateRecorder) extensionWrites(final WorldSnapshot) (map[string]trie.Object, bool, error) {
+	if r.extWhole {
+		return nil, false, nil
+	}
+	bess, fess := r.base.GetExtensionSnapshot(), final.GetExtensionSnapshot()
+	if bytes.Equal(r.base.ExtensionData(), final.ExtensionData()) {
+		return nil, true, nil
+	}
+	bes, ok1 := bess.NewState(true).(RecordableExtensionState)
+	fes, ok2 := fess.NewState(true).(RecordableExtensionState)
+	if !ok1 || !ok2 {
+		return nil, false, nil
+	}
+	values := make(map[string]trie.Object)
+	for k := range r.extWritten {
+		bv, err := bes.GetValue([]byte(k))
+		if err != nil {
+			return nil, false, err
+		}
+		fv, err := fes.GetValue([]byte(k))
+		if err != nil {
+			return nil, false, err
+		}
+		if !objectEqual(bv, fv) {
+			values[k] = fv
+		}
+	}
+
+	// The extension may be modified without the stores, so it checks
+	// whether the values reproduce the final state.
+	res := bess.NewState(false).(RecordableExtensionState)
+	if err := res.SetValues(values); err != nil {
+		return nil, false, err
+	}
+	if !bytes.Equal(res.GetSnapshot().Bytes(), final.ExtensionData()) {
+		return nil, false, nil
+	}
+	return values, true, nil
+}
+
+func (r *worldStateRecorder) Writes() (*WriteSet, error) {
+	// taking snapshot flushes cached values of the extension state,
+	// so it should be done before stopping recording and locking.
+	final := r.WorldState.GetSnapshot()
+	if r.ext != nil {
+		r.ext.SetAccessRecorder(nil)
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	w := &WriteSet{
+		World:    worldModified(r.base, final, r.ext == nil),
+		accounts: make(map[string]*accountWrite),
+	}
+	if r.ext != nil {
+		values, ok, err := r.extensionWrites(final)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			w.extension = values
+		} else {
+			w.World = true
+			r.reads.World = true
+		}
+	}
+	for ids, as := range r.accounts {
+		id := []byte(ids)
+		bss := accountSnapshotOf(r.base, id)
+		fss := accountSnapshotOf(final, id)
+		if bss.Equal(fss) {
+			continue
+		}
+		aw := &accountWrite{values: make(map[string][]byte)}
+		if bss.GetBalance().Cmp(fss.GetBalance()) != 0 {
+			aw.balance = fss.GetBalance()
+		}
+		for k := range as.written {
+			bv, err := bss.GetValue([]byte(k))
+			if err != nil {
+				return nil, err
+			}
+			fv, err := fss.GetValue([]byte(k))
+			if err != nil {
+				return nil, err
+			}
+			if !bytes.Equal(bv, fv) {
+				aw.values[k] = fv
+			}
+		}
+
+		// Other parts are modified if it fails to reproduce the final
+		// state with the balance and the values. It compares encoded
+		// accounts for modified storage may not be hashed yet.
+		ras := newAccountState(r.base.Database(), bss, nil, false)
+		if ras == nil {
+			return nil, errors.InvalidStateError.Errorf("FailToRestoreAccount(id=%x)", id)
+		}
+		if err := applyAccountWrite(ras, aw); err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(ras.GetSnapshot().Bytes(), fss.Bytes()) {
+			aw = &accountWrite{snapshot: fss}
+			r.reads.Account(id).Whole = true
+		}
+		w.accounts[ids] = aw
+	}
+	return w, nil
+}
+
+// NewWorldStateRecorder returns a WorldState recording accesses on the
+// world state. It takes a snapshot of the world state to get modifications.
+// Accesses on the extension state are recorded by keys if it implements
+// RecordableExtensionState, and the recording stops on Writes().
+func NewWorldStateRecorder(ws WorldState) WorldStateRecorder {
+	r := &worldStateRecorder{
+		WorldState: ws,
+		base:       ws.GetSnapshot(),
+		reads:      NewAccessSet(),
+		accounts:   make(map[string]*accountStateRecorder),
+		extWritten: make(map[string]bool),
+	}
+	if es, ok := ws.GetExtensionState().(RecordableExtensionState); ok {
+		r.ext = es
+		es.SetAccessRecorder(r)
+	}
+	return r
+}
+
+type accountStateRecorder struct {
+	AccountState
+	id       []byte
+	recorder *worldStateRecorder
+	written  map[string]bool
+}
+
+func (s *accountStateRecorder) onWhole() {
+	s.recorder.onRead(s.id, func(aa *AccountAccess) { aa.Whole = true })
+}
+
+func (s *accountStateRecorder) onMeta() {
+	s.recorder.onRead(s.id, func(aa *AccountAccess) { aa.Meta = true })
+}
+
+func (s *accountStateRecorder) onBalance() {
+	s.recorder.onRead(s.id, func(aa *AccountAccess) { aa.Balance = true })
+}
+
+func (s *accountStateRecorder) onKey(k []byte, write bool) {
+	s.recorder.onRead(s.id, func(aa *AccountAccess) {
+		aa.Keys[string(k)] = true
+		if write {
+			s.written[string(k)] = true
+		}
+	})
+}
+
+func (s *accountStateRecorder) Version() int {
+	s.onMeta()
+	return s.AccountState.Version()
+}
+
+func (s *accountStateRecorder) MigrateForRevision(rev module.Revision) error {
+	s.onMeta()
+	return s.AccountState.MigrateForRevision(rev)
+}
+
+func (s *accountStateRecorder) GetBalance() *big.Int {
+	s.onBalance()
+	return s.AccountState.GetBalance()
+}
+
+func (s *accountStateRecorder) IsContract() bool {
+	s.onMeta()
+	return s.AccountState.IsContract()
+}
+
+func (s *accountStateRecorder) GetValue(k []byte) ([]byte, error) {
+	s.onKey(k, false)
+	return s.AccountState.GetValue(k)
+}
+
+func (s *accountStateRecorder) SetBalance(v *big.Int) {
+	s.onBalance()
+	s.AccountState.SetBalance(v)
+}
+
+func (s *accountStateRecorder) SetValue(k, v []byte) ([]byte, error) {
+	s.onKey(k, true)
+	return s.AccountState.SetValue(k, v)
+}
+
+func (s *accountStateRecorder) DeleteValue(k []byte) ([]byte, error) {
+	s.onKey(k, true)
+	return s.AccountState.DeleteValue(k)
+}
+
+func (s *accountStateRecorder) GetSnapshot() AccountSnapshot {
+	s.onWhole()
+	return s.AccountState.GetSnapshot()
+}
+
+func (s *accountStateRecorder) Reset(snapshot AccountSnapshot) error {
+	s.onWhole()
+	return s.AccountState.Reset(snapshot)
+}
+
+func (s *accountStateRecorder) Clear() {
+	s.onWhole()
+	s.AccountState.Clear()
+}
+
+func (s *accountStateRecorder) IsContractOwner(owner module.Address) bool {
+	s.onMeta()
+	return s.AccountState.IsContractOwner(owner)
+}
+
+func (s *accountStateRecorder) SetContractOwner(owner module.Address) error {
+	s.onMeta()
+	return s.AccountState.SetContractOwner(owner)
+}
+
+func (s *accountStateRecorder) InitContractAccount(address module.Address) bool {
+	s.onMeta()
+	return s.AccountState.InitContractAccount(address)
+}
+
+func (s *accountStateRecorder) DeployContract(code []byte, eeType EEType, contentType string, params []byte, txHash []byte) ([]byte, error) {
+	s.onMeta()
+	return s.AccountState.DeployContract(code, eeType, contentType, params, txHash)
+}
+
+func (s *accountStateRecorder) APIInfo() (*scoreapi.Info, error) {
+	s.onMeta()
+	return s.AccountState.APIInfo()
+}
+
+func (s *accountStateRecorder) SetAPIInfo(info *scoreapi.Info) {
+	s.onMeta()
+	s.AccountState.SetAPIInfo(info)
+}
+
+func (s *accountStateRecorder) ActivateNextContract() error {
+	s.onMeta()
+	return s.AccountState.ActivateNextContract()
+}
+
+func (s *accountStateRecorder) AcceptContract(txHash []byte, auditTxHash []byte) error {
+	s.onMeta()
+	return s.AccountState.AcceptContract(txHash, auditTxHash)
+}
+
+func (s *accountStateRecorder) RejectContract(txHash []byte, auditTxHash []byte) error {
+	s.onMeta()
+	return s.AccountState.RejectContract(txHash, auditTxHash)
+}
+
+func (s *accountStateRecorder) Contract() ContractState {
+	s.onMeta()
+	return s.AccountState.Contract()
+}
+
+func (s *accountStateRecorder) ActiveContract() ContractState {
+	s.onMeta()
+	return s.AccountState.ActiveContract()
+}
+
+func (s *accountStateRecorder) NextContract() ContractState {
+	s.onMeta()
+	return s.AccountState.NextContract()
+}
+
+func (s *accountStateRecorder) SetDisable(b bool) {
+	s.onMeta()
+	s.AccountState.SetDisable(b)
+}
+
+func (s *accountStateRecorder) IsDisabled() bool {
+	s.onMeta()
+	return s.AccountState.IsDisabled()
+}
+
+func (s *accountStateRecorder) SetBlock(b bool) {
+	s.onMeta()
+	s.AccountState.SetBlock(b)
+}
+
+func (s *accountStateRecorder) IsBlocked() bool {
+	s.onMeta()
+	return s.AccountState.IsBlocked()
+}
+
+func (s *accountStateRecorder) UseSystemDeposit() bool {
+	s.onMeta()
+	return s.AccountState.UseSystemDeposit()
+}
+
+func (s *accountStateRecorder) SetUseSystemDeposit(yn bool) error {
+	s.onMeta()
+	return s.AccountState.SetUseSystemDeposit(yn)
+}
+
+func (s *accountStateRecorder) ContractOwner() module.Address {
+	s.onMeta()
+	return s.AccountState.ContractOwner()
+}
+
+func (s *accountStateRecorder) GetObjGraph(id []byte, flags bool) (int, []byte, []byte, error) {
+	s.onMeta()
+	return s.AccountState.GetObjGraph(id, flags)
+}
+
+func (s *accountStateRecorder) SetObjGraph(id []byte, flags bool, nextHash int, objGraph []byte) error {
+	s.onMeta()
+	return s.AccountState.SetObjGraph(id, flags, nextHash, objGraph)
+}
+
+// Deposit related operations may use the balance of the account.
+
+func (s *accountStateRecorder) AddDeposit(dc DepositContext, value *big.Int) error {
+	s.onWhole()
+	return s.AccountState.AddDeposit(dc, value)
+}
+
+func (s *accountStateRecorder) WithdrawDeposit(dc DepositContext, id []byte, value *big.Int) (*big.Int, *big.Int, error) {
+	s.onWhole()
+	return s.AccountState.WithdrawDeposit(dc, id, value)
+}
+
+func (s *accountStateRecorder) PaySteps(pc PayContext, steps *big.Int) (*big.Int, *big.Int, error) {
+	s.onWhole()
+	return s.AccountState.PaySteps(pc, steps)
+}
+
+func (s *accountStateRecorder) CanAcceptTx(pc PayContext) bool {
+	s.onWhole()
+	return s.AccountState.CanAcceptTx(pc)
+}
+
+func (s *accountStateRecorder) CheckDeposit(pc PayContext) bool {
+	s.onWhole()
+	return s.AccountState.CheckDeposit(pc)
+}
+
+func (s *accountStateRecorder) PayableSteps(pc PayContext) *big.Int {
+	s.onWhole()
+	return s.AccountState.PayableSteps(pc)
+}
+
+func (s *accountStateRecorder) GetDepositInfo(dc DepositContext, v module.JSONVersion) (map[string]interface{}, error) {
+	s.onWhole()
+	return s.AccountState.GetDepositInfo(dc, v)
+}
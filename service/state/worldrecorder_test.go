@@ -0,0 +1,287 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package state
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/merkle"
+	"github.com/icon-project/goloop/common/trie"
+)
+
+func newTestWorldSnapshot(t *testing.T) WorldSnapshot {
+	ws := NewWorldState(db.NewMapDB(), nil, nil, nil, nil)
+	as := ws.GetAccountState([]byte("a"))
+	as.SetBalance(big.NewInt(100))
+	_, err := as.SetValue([]byte("k1"), []byte("v1"))
+	assert.NoError(t, err)
+	_, err = as.SetValue([]byte("k2"), []byte("v2"))
+	assert.NoError(t, err)
+	return ws.GetSnapshot()
+}
+
+func recordOn(t *testing.T, base WorldSnapshot, f func(ws WorldState)) (*AccessSet, *WriteSet) {
+	ws, err := WorldStateFromSnapshot(base)
+	assert.NoError(t, err)
+	rec := NewWorldStateRecorder(ws)
+	f(rec)
+	writes, err := rec.Writes()
+	assert.NoError(t, err)
+	return rec.Reads(), writes
+}
+
+func TestWorldStateRecorder_Conflicts(t *testing.T) {
+	base := newTestWorldSnapshot(t)
+	id := []byte("a")
+
+	r1, w1 := recordOn(t, base, func(ws WorldState) {
+		_, err := ws.GetAccountState(id).SetValue([]byte("k1"), []byte("v3"))
+		assert.NoError(t, err)
+	})
+	r2, w2 := recordOn(t, base, func(ws WorldState) {
+		as := ws.GetAccountState(id)
+		v, err := as.GetValue([]byte("k2"))
+		assert.NoError(t, err)
+		as.SetBalance(new(big.Int).SetBytes(v))
+	})
+	r3, _ := recordOn(t, base, func(ws WorldState) {
+		_, err := ws.GetAccountState(id).GetValue([]byte("k1"))
+		assert.NoError(t, err)
+	})
+	r4, w4 := recordOn(t, base, func(ws WorldState) {
+		ws.GetAccountState(id).SetBlock(true)
+	})
+
+	dirty := NewAccessSet()
+	assert.False(t, r1.Conflicts(dirty))
+	dirty.Merge(w1.Footprint())
+	assert.False(t, r2.Conflicts(dirty))
+	dirty.Merge(w2.Footprint())
+	assert.True(t, r3.Conflicts(dirty))
+
+	// modifying other than storage and balance is for whole account.
+	assert.True(t, w4.Footprint().Account(id).Whole)
+	assert.True(t, r4.Conflicts(dirty))
+	assert.False(t, r4.Conflicts(NewAccessSet()))
+}
+
+func TestWriteSet_Apply(t *testing.T) {
+	base := newTestWorldSnapshot(t)
+	id := []byte("a")
+
+	apply1 := func(ws WorldState) {
+		as := ws.GetAccountState(id)
+		_, err := as.SetValue([]byte("k1"), []byte("v3"))
+		assert.NoError(t, err)
+		_, err = as.DeleteValue([]byte("k2"))
+		assert.NoError(t, err)
+	}
+	apply2 := func(ws WorldState) {
+		ws.GetAccountState(id).SetBalance(big.NewInt(200))
+		ws.GetAccountState([]byte("b")).SetBlock(true)
+	}
+
+	_, w1 := recordOn(t, base, apply1)
+	_, w2 := recordOn(t, base, apply2)
+
+	ws1, err := WorldStateFromSnapshot(base)
+	assert.NoError(t, err)
+	assert.NoError(t, w1.Apply(ws1))
+	assert.NoError(t, w2.Apply(ws1))
+
+	ws2, err := WorldStateFromSnapshot(base)
+	assert.NoError(t, err)
+	apply1(ws2)
+	apply2(ws2)
+
+	assert.Equal(t, ws2.GetSnapshot().StateHash(), ws1.GetSnapshot().StateHash())
+}
+
+type testObject []byte
+
+func (o testObject) Bytes() []byte                        { return o }
+func (o testObject) Reset(s db.Database, k []byte) error  { return nil }
+func (o testObject) Flush() error                         { return nil }
+func (o testObject) Equal(o2 trie.Object) bool            { return bytes.Equal(o, o2.Bytes()) }
+func (o testObject) Resolve(builder merkle.Builder) error { return nil }
+func (o testObject) ClearCache()                          {}
+
+// testExtensionSnapshot has values accessed by keys and extra which isn't
+// recorded by keys.
+type testExtensionSnapshot struct {
+	values map[string]string
+	extra  string
+}
+
+func (s *testExtensionSnapshot) Bytes() []byte {
+	keys := make([]string, 0, len(s.values))
+	for k := range s.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var buf bytes.Buffer
+	for _, k := range keys {
+		buf.WriteString(k + "=" + s.values[k] + ";")
+	}
+	buf.WriteString(s.extra)
+	return buf.Bytes()
+}
+
+func (s *testExtensionSnapshot) Flush() error {
+	return nil
+}
+
+func (s *testExtensionSnapshot) NewState(readonly bool) ExtensionState {
+	es := &testExtensionState{}
+	es.Reset(s)
+	return es
+}
+
+type testExtensionState struct {
+	testExtensionSnapshot
+	recorder ExtensionAccessRecorder
+}
+
+func (s *testExtensionState) GetSnapshot() ExtensionSnapshot {
+	values := make(map[string]string)
+	for k, v := range s.values {
+		values[k] = v
+	}
+	return &testExtensionSnapshot{values: values, extra: s.extra}
+}
+
+func (s *testExtensionState) Reset(snapshot ExtensionSnapshot) {
+	ess := snapshot.(*testExtensionSnapshot)
+	s.values = make(map[string]string)
+	for k, v := range ess.values {
+		s.values[k] = v
+	}
+	s.extra = ess.extra
+}
+
+func (s *testExtensionState) ClearCache() {}
+
+func (s *testExtensionState) SetAccessRecorder(r ExtensionAccessRecorder) {
+	s.recorder = r
+}
+
+func (s *testExtensionState) GetValue(key []byte) (trie.Object, error) {
+	if v, ok := s.values[string(key)]; ok {
+		return testObject(v), nil
+	}
+	return nil, nil
+}
+
+func (s *testExtensionState) SetValues(values map[string]trie.Object) error {
+	for k, v := range values {
+		if v == nil {
+			delete(s.values, k)
+		} else {
+			s.values[k] = string(v.Bytes())
+		}
+	}
+	return nil
+}
+
+func (s *testExtensionState) get(k string) string {
+	if s.recorder != nil {
+		s.recorder.OnRead([]byte(k))
+	}
+	return s.values[k]
+}
+
+func (s *testExtensionState) set(k, v string) {
+	if s.recorder != nil {
+		s.recorder.OnWrite([]byte(k))
+	}
+	s.values[k] = v
+}
+
+func testExtensionOf(ws WorldState) *testExtensionState {
+	return ws.GetExtensionState().(*testExtensionState)
+}
+
+func TestWorldStateRecorder_Extension(t *testing.T) {
+	ess := &testExtensionSnapshot{
+		values: map[string]string{"k1": "v1", "k2": "v2"},
+	}
+	base := NewWorldState(db.NewMapDB(), nil, nil, ess, nil).GetSnapshot()
+
+	apply1 := func(ws WorldState) {
+		testExtensionOf(ws).set("k1", "v3")
+	}
+	apply2 := func(ws WorldState) {
+		es := testExtensionOf(ws)
+		es.set("k3", es.get("k2"))
+		// same value is not a modification
+		es.set("k2", "v2")
+	}
+	r1, w1 := recordOn(t, base, apply1)
+	r2, w2 := recordOn(t, base, apply2)
+	r3, _ := recordOn(t, base, func(ws WorldState) {
+		testExtensionOf(ws).get("k1")
+	})
+	r4, w4 := recordOn(t, base, func(ws WorldState) {
+		testExtensionOf(ws).extra = "modified"
+	})
+
+	assert.False(t, r1.World)
+	assert.False(t, w1.World)
+	assert.False(t, w2.World)
+	assert.Equal(t, map[string]bool{"k3": true}, w2.Footprint().Extension)
+
+	dirty := NewAccessSet()
+	assert.False(t, r1.Conflicts(dirty))
+	dirty.Merge(w1.Footprint())
+	assert.False(t, r2.Conflicts(dirty))
+	dirty.Merge(w2.Footprint())
+	assert.True(t, r3.Conflicts(dirty))
+
+	// modification not recorded by keys is for whole extension state.
+	assert.True(t, w4.World)
+	assert.True(t, r4.World)
+	assert.True(t, r4.Conflicts(dirty))
+	assert.True(t, r3.Conflicts(w4.Footprint()))
+	assert.Error(t, w4.Apply(NewWorldState(db.NewMapDB(), nil, nil, ess, nil)))
+
+	ws1, err := WorldStateFromSnapshot(base)
+	assert.NoError(t, err)
+	assert.NoError(t, w1.Apply(ws1))
+	assert.NoError(t, w2.Apply(ws1))
+
+	ws2, err := WorldStateFromSnapshot(base)
+	assert.NoError(t, err)
+	apply1(ws2)
+	apply2(ws2)
+
+	assert.Equal(t, ws2.GetSnapshot().ExtensionData(), ws1.GetSnapshot().ExtensionData())
+
+	// recording stops on Writes()
+	ws3, err := WorldStateFromSnapshot(base)
+	assert.NoError(t, err)
+	rec := NewWorldStateRecorder(ws3)
+	assert.NotNil(t, testExtensionOf(ws3).recorder)
+	_, err = rec.Writes()
+	assert.NoError(t, err)
+	assert.Nil(t, testExtensionOf(ws3).recorder)
+}
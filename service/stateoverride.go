@@ -0,0 +1,208 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/contract"
+	"github.com/icon-project/goloop/service/scoreresult"
+	"github.com/icon-project/goloop/service/state"
+)
+
+const keyStateOverride = "stateOverride"
+
+// StateOverride replaces the state of the accounts before the execution
+// of the query or the transaction. It's keyed by the address of the account.
+// Overridden state is never committed.
+type StateOverride map[string]*AccountOverride
+
+type AccountOverride struct {
+	Balance *common.HexInt     `json:"balance,omitempty"`
+	Code    *CodeOverride      `json:"code,omitempty"`
+	Storage []*StorageOverride `json:"storage,omitempty"`
+}
+
+// CodeOverride is the code to be installed at the address. If there is a
+// contract at the address, it updates the contract, and on_update is called.
+type CodeOverride struct {
+	ContentType string          `json:"contentType"`
+	Content     common.HexBytes `json:"content"`
+	Params      json.RawMessage `json:"params,omitempty"`
+}
+
+// StorageOverride is the value for the key in the storage of the contract.
+// The value is deleted if Value is nil.
+type StorageOverride struct {
+	Key   common.HexBytes `json:"key"`
+	Value common.HexBytes `json:"value"`
+}
+
+func parseStateOverride(js []byte) (StateOverride, error) {
+	if len(js) == 0 {
+		return nil, nil
+	}
+	var so StateOverride
+	if err := json.Unmarshal(js, &so); err != nil {
+		return nil, errors.IllegalArgumentError.Wrap(err, "InvalidStateOverride")
+	}
+	return so, nil
+}
+
+// splitStateOverride removes the state override from the JSON object, and
+// returns the rest of the object and the state override.
+func splitStateOverride(js []byte) ([]byte, StateOverride, error) {
+	var jso map[string]json.RawMessage
+	if err := json.Unmarshal(js, &jso); err != nil {
+		return js, nil, nil
+	}
+	value, ok := jso[keyStateOverride]
+	if !ok {
+		return js, nil, nil
+	}
+	delete(jso, keyStateOverride)
+	so, err := parseStateOverride(value)
+	if err != nil {
+		return nil, nil, err
+	}
+	rest, err := json.Marshal(jso)
+	if err != nil {
+		return nil, nil, errors.UnknownError.Wrap(err, "FailToMarshal")
+	}
+	return rest, so, nil
+}
+
+// stateOverrideTxHash is used as the hash of the transaction deploying
+// the code of the state override.
+var stateOverrideTxHash = crypto.SHA3Sum256([]byte(keyStateOverride))
+
+type accountOverride struct {
+	addr module.Address
+	*AccountOverride
+}
+
+func (so StateOverride) accounts() ([]accountOverride, error) {
+	accounts := make([]accountOverride, 0, len(so))
+	for k, ao := range so {
+		if ao == nil {
+			continue
+		}
+		addr, err := common.NewAddressFromString(k)
+		if err != nil {
+			return nil, scoreresult.InvalidParameterError.Wrapf(err,
+				"InvalidAddress(addr=%s)", k)
+		}
+		accounts = append(accounts, accountOverride{addr, ao})
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i].addr.String() < accounts[j].addr.String()
+	})
+	return accounts, nil
+}
+
+// Apply applies the state override to the world of the context in order
+// of the address. Code is installed first, then storage and balance are
+// replaced. The context must not be committed.
+func (so StateOverride) Apply(ctx contract.Context) error {
+	if len(so) == 0 {
+		return nil
+	}
+	accounts, err := so.accounts()
+	if err != nil {
+		return err
+	}
+	for _, ao := range accounts {
+		if err := applyCode(ctx, ao.addr, ao.Code); err != nil {
+			return err
+		}
+	}
+	for _, ao := range accounts {
+		addr := ao.addr
+		as := ctx.GetAccountState(addr.ID())
+		if len(ao.Storage) > 0 && !as.IsContract() {
+			return scoreresult.InvalidParameterError.Errorf(
+				"StorageForNonContract(addr=%s)", addr)
+		}
+		for _, s := range ao.Storage {
+			if s == nil || len(s.Key) == 0 {
+				return scoreresult.InvalidParameterError.Errorf(
+					"InvalidStorageKey(addr=%s)", addr)
+			}
+			if s.Value == nil {
+				_, err = as.DeleteValue(s.Key)
+			} else {
+				_, err = as.SetValue(s.Key, s.Value)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if ao.Balance != nil {
+			if ao.Balance.Sign() < 0 {
+				return scoreresult.InvalidParameterError.Errorf(
+					"InvalidBalance(addr=%s,balance=%s)", addr, ao.Balance)
+			}
+			as.SetBalance(ao.Balance.Value())
+		}
+	}
+	return nil
+}
+
+func applyCode(ctx contract.Context, addr module.Address, code *CodeOverride) error {
+	if code == nil {
+		return nil
+	}
+	if !addr.IsContract() {
+		return scoreresult.InvalidParameterError.Errorf(
+			"CodeForNonContract(addr=%s)", addr)
+	}
+	if len(code.Content) == 0 {
+		return scoreresult.InvalidParameterError.Errorf(
+			"NoContent(addr=%s)", addr)
+	}
+	owner := ctx.GetAccountState(addr.ID()).ContractOwner()
+	if owner == nil {
+		owner = state.SystemAddress
+	}
+	ctx.SetTransactionInfo(&state.TransactionInfo{
+		Group:     module.TransactionGroupNormal,
+		Index:     0,
+		Hash:      stateOverrideTxHash,
+		From:      owner,
+		Timestamp: ctx.BlockTimeStamp(),
+	})
+	ctx.UpdateSystemInfo()
+
+	var params []byte
+	if len(code.Params) > 0 {
+		params = code.Params
+	}
+	handler := contract.NewDeployHandlerForOverride(owner, addr,
+		code.ContentType, code.Content, params, ctx.Logger())
+	cc := contract.NewCallContext(ctx, ctx.GetStepLimit(state.StepLimitTypeInvoke), false)
+	defer cc.Dispose()
+	status, _, _, _ := cc.Call(handler, cc.StepAvailable())
+	if status != nil {
+		return scoreresult.Validate(status)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/contract"
+	"github.com/icon-project/goloop/service/eeproxy"
+	"github.com/icon-project/goloop/service/state"
+)
+
+func Test_splitStateOverride(t *testing.T) {
+	js := []byte(`{"to":"hx0000000000000000000000000000000000000001","stateOverride":{"hx0000000000000000000000000000000000000002":{"balance":"0x10"}}}`)
+	rest, so, err := splitStateOverride(js)
+	assert.NoError(t, err)
+	assert.Len(t, so, 1)
+	assert.Equal(t, int64(0x10), so["hx0000000000000000000000000000000000000002"].Balance.Int64())
+	var jso map[string]interface{}
+	assert.NoError(t, json.Unmarshal(rest, &jso))
+	assert.Equal(t, map[string]interface{}{
+		"to": "hx0000000000000000000000000000000000000001",
+	}, jso)
+
+	js = []byte(`{"to":"hx0000000000000000000000000000000000000001"}`)
+	rest, so, err = splitStateOverride(js)
+	assert.NoError(t, err)
+	assert.Nil(t, so)
+	assert.Equal(t, js, rest)
+
+	_, _, err = splitStateOverride([]byte(`{"stateOverride":[]}`))
+	assert.Error(t, err)
+}
+
+type dummyPlatform struct{}
+
+func (p dummyPlatform) ToRevision(value int) module.Revision {
+	return module.LatestRevision
+}
+
+func newContextForStateOverride(wss state.WorldSnapshot) contract.Context {
+	ws, _ := state.WorldStateFromSnapshot(wss)
+	wc := state.NewWorldContext(ws, common.NewBlockInfo(1, 0), nil, dummyPlatform{})
+	return contract.NewContext(wc, nil, nil, nil, log.GlobalLogger(), nil, eeproxy.ForQuery)
+}
+
+func TestStateOverride_Apply(t *testing.T) {
+	eoa := common.MustNewAddressFromString("hx0000000000000000000000000000000000000001")
+	score := common.MustNewAddressFromString("cx0000000000000000000000000000000000000002")
+
+	ws := state.NewWorldState(db.NewMapDB(), nil, nil, nil, nil)
+	ws.GetAccountState(eoa.ID()).SetBalance(big.NewInt(100))
+	as := ws.GetAccountState(score.ID())
+	as.InitContractAccount(eoa)
+	as.SetValue([]byte("k1"), []byte("v1"))
+	as.SetValue([]byte("k2"), []byte("v2"))
+	wss := ws.GetSnapshot()
+
+	so, err := parseStateOverride([]byte(`{
+		"hx0000000000000000000000000000000000000001": { "balance": "0x1000" },
+		"cx0000000000000000000000000000000000000002": {
+			"storage": [
+				{ "key": "0x6b31", "value": "0x7631" },
+				{ "key": "0x6b32", "value": null },
+				{ "key": "0x6b33", "value": "0x7633" }
+			]
+		}
+	}`))
+	assert.NoError(t, err)
+
+	ctx := newContextForStateOverride(wss)
+	assert.NoError(t, so.Apply(ctx))
+	assert.Equal(t, big.NewInt(0x1000), ctx.GetAccountState(eoa.ID()).GetBalance())
+	sas := ctx.GetAccountState(score.ID())
+	v, _ := sas.GetValue([]byte("k1"))
+	assert.Equal(t, []byte("v1"), v)
+	v, _ = sas.GetValue([]byte("k2"))
+	assert.Nil(t, v)
+	v, _ = sas.GetValue([]byte("k3"))
+	assert.Equal(t, []byte("v3"), v)
+
+	// original state is not changed
+	assert.Equal(t, big.NewInt(100), wss.GetAccountSnapshot(eoa.ID()).GetBalance())
+	v, _ = wss.GetAccountSnapshot(score.ID()).GetValue([]byte("k2"))
+	assert.Equal(t, []byte("v2"), v)
+
+	for _, js := range []string{
+		`{"hxzz": { "balance": "0x1" }}`,
+		`{"hx0000000000000000000000000000000000000001": { "balance": "-0x1" }}`,
+		`{"hx0000000000000000000000000000000000000001": { "storage": [{"key":"0x01","value":"0x01"}] }}`,
+		`{"hx0000000000000000000000000000000000000001": { "code": {"contentType":"application/java","content":"0x01"} }}`,
+		`{"cx0000000000000000000000000000000000000002": { "storage": [{"key":"0x","value":"0x01"}] }}`,
+	} {
+		so, err := parseStateOverride([]byte(js))
+		assert.NoError(t, err)
+		assert.Error(t, so.Apply(newContextForStateOverride(wss)), js)
+	}
+}
@@ -13,6 +13,7 @@ import (
 
 type DataSender interface {
 	RequestData(peer module.PeerID, reqID uint32, reqData []BucketIDAndBytes) error
+	ReportPeer(peer module.PeerID, penalty int, reason string)
 }
 
 type DataHandler func(reqID uint32, sender *peer, data []BucketIDAndBytes)
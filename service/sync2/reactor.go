@@ -68,6 +68,10 @@ func (r *ReactorCommon) OnLeave(id module.PeerID) {
 	})
 }
 
+func (r *ReactorCommon) ReportPeer(id module.PeerID, penalty int, reason string) {
+	r.ph.ReportPeer(id, penalty, reason)
+}
+
 func (r *ReactorCommon) GetVersion() byte {
 	return r.version
 }
@@ -66,6 +66,10 @@ func (r *mockReactor) UnwatchPeers(watcher PeerWatcher) bool {
 	return true
 }
 
+func (r *mockReactor) ReportPeer(id module.PeerID, penalty int, reason string) {
+	r.logger.Debugf("mockReactor(%v) ReportPeer() peer=%v penalty=%d reason=%s", r.version, id, penalty, reason)
+}
+
 func (r *mockReactor) RequestData(id module.PeerID, reqID uint32, reqData []BucketIDAndBytes) error {
 	r.logger.Debugf("mockReactor(%v) RequestData() reqID=%d", r.version, reqID)
 
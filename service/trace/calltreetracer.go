@@ -0,0 +1,147 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package trace
+
+import (
+	"math/big"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/scoreresult"
+)
+
+type callNode struct {
+	parent   *callNode
+	call     *module.TraceCall
+	done     bool
+	status   error
+	steps    *big.Int
+	result   interface{}
+	children []*callNode
+}
+
+func (n *callNode) childrenToJSON() []interface{} {
+	calls := make([]interface{}, len(n.children))
+	for i, c := range n.children {
+		calls[i] = c.toJSON()
+	}
+	return calls
+}
+
+func (n *callNode) toJSON() map[string]interface{} {
+	jso := map[string]interface{}{
+		"type": n.call.Type,
+	}
+	if n.call.From != nil {
+		jso["from"] = n.call.From
+	}
+	if n.call.To != nil {
+		jso["to"] = n.call.To
+	}
+	if n.call.Value != nil {
+		jso["value"] = new(common.HexInt).SetValue(n.call.Value)
+	}
+	if len(n.call.Method) > 0 {
+		jso["method"] = n.call.Method
+	}
+	if n.call.Params != nil {
+		jso["params"] = n.call.Params
+	}
+	if n.done && n.status == nil {
+		jso["status"] = "0x1"
+		if n.result != nil {
+			jso["return"] = n.result
+		}
+	} else {
+		jso["status"] = "0x0"
+		if n.status != nil {
+			status, _ := scoreresult.StatusOf(n.status)
+			jso["failure"] = map[string]interface{}{
+				"code":    status,
+				"message": n.status.Error(),
+			}
+		}
+	}
+	if n.steps != nil {
+		jso["steps"] = new(common.HexInt).SetValue(n.steps)
+	}
+	if len(n.children) > 0 {
+		jso["calls"] = n.childrenToJSON()
+	}
+	return jso
+}
+
+// CallTreeTracer builds the tree of the calls made by the transaction.
+type CallTreeTracer struct {
+	tracerBase
+	root    *callNode
+	current *callNode
+}
+
+func (t *CallTreeTracer) reset() {
+	t.root = new(callNode)
+	t.current = t.root
+}
+
+func (t *CallTreeTracer) OnTransactionStart(txIndex int, txHash []byte, isBlockTx bool) error {
+	t.reset()
+	return t.tracerBase.OnTransactionStart(txIndex, txHash, isBlockTx)
+}
+
+func (t *CallTreeTracer) OnTransactionReset() error {
+	t.reset()
+	return nil
+}
+
+func (t *CallTreeTracer) OnTransactionEnd(txIndex int, txHash []byte) error {
+	if t.root == nil {
+		return errors.InvalidStateError.New("NoTransaction")
+	}
+	t.addResult(map[string]interface{}{
+		"calls": t.root.childrenToJSON(),
+	})
+	t.root, t.current = nil, nil
+	return nil
+}
+
+func (t *CallTreeTracer) OnFrameStart(call *module.TraceCall) error {
+	if t.current == nil {
+		return errors.InvalidStateError.New("NoTransaction")
+	}
+	n := &callNode{parent: t.current, call: call}
+	t.current.children = append(t.current.children, n)
+	t.current = n
+	return nil
+}
+
+func (t *CallTreeTracer) OnFrameResult(status error, steps *big.Int, result interface{}) error {
+	if t.current == nil || t.current == t.root {
+		return errors.InvalidStateError.New("NoFrame")
+	}
+	n := t.current
+	n.done = true
+	n.status = status
+	n.steps = new(big.Int).Set(steps)
+	n.result = result
+	t.current = n.parent
+	return nil
+}
+
+func NewCallTreeTracer() *CallTreeTracer {
+	return new(CallTreeTracer)
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"math/big"
 
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/codec"
 	"github.com/icon-project/goloop/common/log"
 	"github.com/icon-project/goloop/module"
 	"github.com/icon-project/goloop/service/txresult"
@@ -194,7 +196,19 @@ func (l *Logger) OnFrameEnter(frameId int) {
 	}
 }
 
-func (l *Logger) OnFrameExit(success bool, stepUsed *big.Int) {
+// OnFrameStart notifies the call executed in the entered frame.
+func (l *Logger) OnFrameStart(call *module.TraceCall) {
+	if l.TraceMode() != module.TraceModeInvoke {
+		return
+	}
+	if cb, ok := l.cb.(module.FrameTraceCallback); ok {
+		if err := cb.OnFrameStart(call); err != nil {
+			l.Warnf("OnFrameStart() error: err=%#v", err)
+		}
+	}
+}
+
+func (l *Logger) OnFrameExit(status error, stepUsed *big.Int, result *codec.TypedObj) {
 	if l.TraceMode() == module.TraceModeNone {
 		return
 	}
@@ -203,12 +217,58 @@ func (l *Logger) OnFrameExit(success bool, stepUsed *big.Int) {
 		return
 	}
 
+	success := status == nil
 	l.TSystemf("END success=%v steps=%d", success, stepUsed)
+	if cb, ok := l.cb.(module.FrameTraceCallback); ok && l.TraceMode() == module.TraceModeInvoke {
+		var ro interface{}
+		if result != nil {
+			ro, _ = common.DecodeAnyForJSON(result)
+		}
+		if err := cb.OnFrameResult(status, stepUsed, ro); err != nil {
+			l.Warnf("OnFrameResult() error: success=%t err=%#v", success, err)
+		}
+	}
 	if err := l.cb.OnFrameExit(success); err != nil {
 		l.Warnf("OnFrameExit() error: success=%t err=%#v", success, err)
 	}
 }
 
+func (l *Logger) OnStorageChange(score module.Address, key, before, after []byte) {
+	if l.TraceMode() != module.TraceModeInvoke {
+		return
+	}
+	if cb, ok := l.cb.(module.StorageTraceCallback); ok {
+		if err := cb.OnStorageChange(score, key, before, after); err != nil {
+			l.Warnf("OnStorageChange() error: score=%s key=%#x err=%#v",
+				score, key, err)
+		}
+	}
+}
+
+func (l *Logger) OnStepApply(stepType string, steps *big.Int) {
+	if l.TraceMode() != module.TraceModeInvoke {
+		return
+	}
+	if cb, ok := l.cb.(module.StepTraceCallback); ok {
+		if err := cb.OnStepApply(stepType, steps); err != nil {
+			l.Warnf("OnStepApply() error: type=%s steps=%d err=%#v",
+				stepType, steps, err)
+		}
+	}
+}
+
+func (l *Logger) OnStepEstimate(stepType string, steps *big.Int) {
+	if l.TraceMode() != module.TraceModeInvoke {
+		return
+	}
+	if cb, ok := l.cb.(module.StepTraceCallback); ok {
+		if err := cb.OnStepEstimate(stepType, steps); err != nil {
+			l.Warnf("OnStepEstimate() error: type=%s steps=%d err=%#v",
+				stepType, steps, err)
+		}
+	}
+}
+
 func (l *Logger) OnBalanceChange(opType module.OpType, from, to module.Address, amount *big.Int) {
 	if l.TraceMode() == module.TraceModeNone {
 		return
@@ -0,0 +1,188 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package trace
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/module"
+)
+
+// StepCategoryExecution is the category for the steps which can't be
+// attributed to other categories, which are mostly used by the execution
+// engines for running the code.
+const StepCategoryExecution = "execution"
+
+type stepFrame struct {
+	parent    *stepFrame
+	applied   map[string]*big.Int
+	untyped   big.Int
+	estimated map[string]*big.Int
+	children  big.Int
+}
+
+func addSteps(m map[string]*big.Int, category string, steps *big.Int) {
+	if v, ok := m[category]; ok {
+		v.Add(v, steps)
+	} else {
+		m[category] = new(big.Int).Set(steps)
+	}
+}
+
+func (f *stepFrame) total() *big.Int {
+	total := new(big.Int).Set(&f.untyped)
+	for _, v := range f.applied {
+		total.Add(total, v)
+	}
+	return total
+}
+
+// settle adds steps of the frame to the categories. Steps deducted without
+// type include the steps of the child frames and the steps reported by the
+// execution engine. The rest after excluding steps of the child frames are
+// attributed to the estimated categories first, then to execution.
+func (f *stepFrame) settle(categories map[string]*big.Int) {
+	for k, v := range f.applied {
+		addSteps(categories, k, v)
+	}
+	remain := new(big.Int).Sub(&f.untyped, &f.children)
+	types := make([]string, 0, len(f.estimated))
+	for k := range f.estimated {
+		types = append(types, k)
+	}
+	sort.Strings(types)
+	for _, k := range types {
+		if remain.Sign() <= 0 {
+			break
+		}
+		steps := f.estimated[k]
+		if steps.Cmp(remain) > 0 {
+			steps = remain
+		}
+		addSteps(categories, k, steps)
+		remain = new(big.Int).Sub(remain, steps)
+	}
+	if remain.Sign() > 0 {
+		addSteps(categories, StepCategoryExecution, remain)
+	}
+	if f.parent != nil {
+		f.parent.children.Add(&f.parent.children, f.total())
+	}
+}
+
+func newStepFrame(parent *stepFrame) *stepFrame {
+	return &stepFrame{
+		parent:    parent,
+		applied:   make(map[string]*big.Int),
+		estimated: make(map[string]*big.Int),
+	}
+}
+
+// StepProfileTracer attributes the steps used by the transaction to the
+// categories. Categories are the step types in the step cost table except
+// StepCategoryExecution. Steps used by the execution engines for accessing
+// the storage are estimated with the step costs.
+type StepProfileTracer struct {
+	tracerBase
+	frame      *stepFrame
+	categories map[string]*big.Int
+}
+
+func (t *StepProfileTracer) reset() {
+	t.frame = newStepFrame(nil)
+	t.categories = make(map[string]*big.Int)
+}
+
+func (t *StepProfileTracer) OnTransactionStart(txIndex int, txHash []byte, isBlockTx bool) error {
+	t.reset()
+	return t.tracerBase.OnTransactionStart(txIndex, txHash, isBlockTx)
+}
+
+func (t *StepProfileTracer) OnTransactionReset() error {
+	t.reset()
+	return nil
+}
+
+func (t *StepProfileTracer) OnTransactionEnd(txIndex int, txHash []byte) error {
+	if t.frame == nil {
+		return errors.InvalidStateError.New("NoTransaction")
+	}
+	// frames may remain on failure, settle them with the base frame
+	for t.frame != nil {
+		t.frame.settle(t.categories)
+		if t.frame.parent == nil {
+			break
+		}
+		t.frame = t.frame.parent
+	}
+	total := t.frame.total()
+
+	categories := make(map[string]interface{}, len(t.categories))
+	for k, v := range t.categories {
+		categories[k] = new(common.HexInt).SetValue(v)
+	}
+	t.addResult(map[string]interface{}{
+		"steps":      new(common.HexInt).SetValue(total),
+		"categories": categories,
+	})
+	t.frame, t.categories = nil, nil
+	return nil
+}
+
+func (t *StepProfileTracer) OnFrameStart(call *module.TraceCall) error {
+	if t.frame == nil {
+		return errors.InvalidStateError.New("NoTransaction")
+	}
+	t.frame = newStepFrame(t.frame)
+	return nil
+}
+
+func (t *StepProfileTracer) OnFrameResult(status error, steps *big.Int, result interface{}) error {
+	if t.frame == nil || t.frame.parent == nil {
+		return errors.InvalidStateError.New("NoFrame")
+	}
+	t.frame.settle(t.categories)
+	t.frame = t.frame.parent
+	return nil
+}
+
+func (t *StepProfileTracer) OnStepApply(stepType string, steps *big.Int) error {
+	if t.frame == nil {
+		return errors.InvalidStateError.New("NoTransaction")
+	}
+	if len(stepType) == 0 {
+		t.frame.untyped.Add(&t.frame.untyped, steps)
+	} else {
+		addSteps(t.frame.applied, stepType, steps)
+	}
+	return nil
+}
+
+func (t *StepProfileTracer) OnStepEstimate(stepType string, steps *big.Int) error {
+	if t.frame == nil {
+		return errors.InvalidStateError.New("NoTransaction")
+	}
+	addSteps(t.frame.estimated, stepType, steps)
+	return nil
+}
+
+func NewStepProfileTracer() *StepProfileTracer {
+	return new(StepProfileTracer)
+}
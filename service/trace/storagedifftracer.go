@@ -0,0 +1,178 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package trace
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/module"
+)
+
+type storageChange struct {
+	score  module.Address
+	key    []byte
+	before []byte
+	after  []byte
+}
+
+type storageFrame struct {
+	parent  *storageFrame
+	changes []*storageChange
+}
+
+type storageDiff struct {
+	key    []byte
+	before []byte
+	after  []byte
+}
+
+func (d *storageDiff) changed() bool {
+	if (d.before == nil) != (d.after == nil) {
+		return true
+	}
+	return !bytes.Equal(d.before, d.after)
+}
+
+// StorageDiffTracer collects the values of the storage of the contracts
+// before and after the transaction. Changes made by failed frames are
+// ignored.
+type StorageDiffTracer struct {
+	tracerBase
+	frame *storageFrame
+}
+
+func (t *StorageDiffTracer) OnTransactionStart(txIndex int, txHash []byte, isBlockTx bool) error {
+	t.frame = new(storageFrame)
+	return t.tracerBase.OnTransactionStart(txIndex, txHash, isBlockTx)
+}
+
+func (t *StorageDiffTracer) OnTransactionReset() error {
+	t.frame = new(storageFrame)
+	return nil
+}
+
+func (t *StorageDiffTracer) OnTransactionEnd(txIndex int, txHash []byte) error {
+	if t.frame == nil {
+		return errors.InvalidStateError.New("NoTransaction")
+	}
+	root := t.frame
+	for root.parent != nil {
+		root = root.parent
+	}
+	t.addResult(map[string]interface{}{
+		"storage": storageChangesToJSON(root.changes),
+	})
+	t.frame = nil
+	return nil
+}
+
+func (t *StorageDiffTracer) OnFrameStart(call *module.TraceCall) error {
+	if t.frame == nil {
+		return errors.InvalidStateError.New("NoTransaction")
+	}
+	t.frame = &storageFrame{parent: t.frame}
+	return nil
+}
+
+func (t *StorageDiffTracer) OnFrameResult(status error, steps *big.Int, result interface{}) error {
+	if t.frame == nil || t.frame.parent == nil {
+		return errors.InvalidStateError.New("NoFrame")
+	}
+	frame := t.frame
+	if status == nil {
+		frame.parent.changes = append(frame.parent.changes, frame.changes...)
+	}
+	t.frame = frame.parent
+	return nil
+}
+
+func (t *StorageDiffTracer) OnStorageChange(score module.Address, key, before, after []byte) error {
+	if t.frame == nil {
+		return errors.InvalidStateError.New("NoTransaction")
+	}
+	t.frame.changes = append(t.frame.changes, &storageChange{
+		score:  score,
+		key:    key,
+		before: before,
+		after:  after,
+	})
+	return nil
+}
+
+func storageChangesToJSON(changes []*storageChange) []interface{} {
+	scores := make(map[string]map[string]*storageDiff)
+	for _, c := range changes {
+		id := string(c.score.Bytes())
+		diffs, ok := scores[id]
+		if !ok {
+			diffs = make(map[string]*storageDiff)
+			scores[id] = diffs
+		}
+		if d, ok := diffs[string(c.key)]; ok {
+			d.after = c.after
+		} else {
+			diffs[string(c.key)] = &storageDiff{
+				key:    c.key,
+				before: c.before,
+				after:  c.after,
+			}
+		}
+	}
+
+	ids := make([]string, 0, len(scores))
+	for id := range scores {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	jso := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		diffs := make([]*storageDiff, 0, len(scores[id]))
+		for _, d := range scores[id] {
+			if d.changed() {
+				diffs = append(diffs, d)
+			}
+		}
+		if len(diffs) == 0 {
+			continue
+		}
+		sort.Slice(diffs, func(i, j int) bool {
+			return bytes.Compare(diffs[i].key, diffs[j].key) < 0
+		})
+		entries := make([]interface{}, len(diffs))
+		for i, d := range diffs {
+			entries[i] = map[string]interface{}{
+				"key":    common.HexBytes(d.key),
+				"before": common.HexBytes(d.before),
+				"after":  common.HexBytes(d.after),
+			}
+		}
+		jso = append(jso, map[string]interface{}{
+			"score":   common.MustNewAddress([]byte(id)),
+			"changes": entries,
+		})
+	}
+	return jso
+}
+
+func NewStorageDiffTracer() *StorageDiffTracer {
+	return new(StorageDiffTracer)
+}
@@ -0,0 +1,108 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package trace
+
+import (
+	"math/big"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/module"
+)
+
+const (
+	TracerCallTree    = "callTree"
+	TracerStorageDiff = "storageDiff"
+	TracerStepProfile = "stepProfile"
+)
+
+// Tracer builds structured result of the execution for each transaction
+// with the trace callbacks. It's used with TraceModeInvoke.
+type Tracer interface {
+	OnTransactionStart(txIndex int, txHash []byte, isBlockTx bool) error
+	OnTransactionReset() error
+	OnTransactionEnd(txIndex int, txHash []byte) error
+	module.FrameTraceCallback
+	module.StorageTraceCallback
+	module.StepTraceCallback
+
+	// ToJSON returns results of the transactions in the order of execution.
+	ToJSON() []interface{}
+}
+
+func NewTracer(name string) (Tracer, error) {
+	switch name {
+	case TracerCallTree:
+		return NewCallTreeTracer(), nil
+	case TracerStorageDiff:
+		return NewStorageDiffTracer(), nil
+	case TracerStepProfile:
+		return NewStepProfileTracer(), nil
+	default:
+		return nil, errors.IllegalArgumentError.Errorf("UnknownTracer(name=%s)", name)
+	}
+}
+
+// tracerBase keeps the results of the transactions. It ignores all
+// callbacks, so the tracer overrides the callbacks it needs.
+type tracerBase struct {
+	txIndex int
+	txHash  []byte
+	results []interface{}
+}
+
+func (t *tracerBase) OnTransactionStart(txIndex int, txHash []byte, isBlockTx bool) error {
+	t.txIndex = txIndex
+	t.txHash = txHash
+	return nil
+}
+
+func (t *tracerBase) OnTransactionReset() error {
+	return nil
+}
+
+func (t *tracerBase) addResult(jso map[string]interface{}) {
+	jso["txIndex"] = common.HexInt32{Value: int32(t.txIndex)}
+	if t.txHash != nil {
+		jso["txHash"] = common.HexBytes(t.txHash)
+	}
+	t.results = append(t.results, jso)
+}
+
+func (t *tracerBase) OnFrameStart(call *module.TraceCall) error {
+	return nil
+}
+
+func (t *tracerBase) OnFrameResult(status error, steps *big.Int, result interface{}) error {
+	return nil
+}
+
+func (t *tracerBase) OnStorageChange(score module.Address, key, before, after []byte) error {
+	return nil
+}
+
+func (t *tracerBase) OnStepApply(stepType string, steps *big.Int) error {
+	return nil
+}
+
+func (t *tracerBase) OnStepEstimate(stepType string, steps *big.Int) error {
+	return nil
+}
+
+func (t *tracerBase) ToJSON() []interface{} {
+	return t.results
+}
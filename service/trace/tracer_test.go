@@ -0,0 +1,161 @@
+package trace
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/scoreresult"
+)
+
+func toJSONMap(t *testing.T, v interface{}) map[string]interface{} {
+	bs, err := json.Marshal(v)
+	assert.NoError(t, err)
+	var jso map[string]interface{}
+	assert.NoError(t, json.Unmarshal(bs, &jso))
+	return jso
+}
+
+func TestNewTracer(t *testing.T) {
+	for _, name := range []string{TracerCallTree, TracerStorageDiff, TracerStepProfile} {
+		tr, err := NewTracer(name)
+		assert.NoError(t, err)
+		assert.NotNil(t, tr)
+	}
+	_, err := NewTracer("unknown")
+	assert.Error(t, err)
+}
+
+func TestCallTreeTracer(t *testing.T) {
+	from := common.MustNewAddressFromString("hx100")
+	score1 := common.MustNewAddressFromString("cx101")
+	score2 := common.MustNewAddressFromString("cx102")
+	txHash := newRandomHash(32)
+
+	tr := NewCallTreeTracer()
+	assert.Error(t, tr.OnFrameStart(&module.TraceCall{}))
+
+	assert.NoError(t, tr.OnTransactionStart(0, txHash, false))
+	assert.NoError(t, tr.OnFrameStart(&module.TraceCall{
+		Type:   "call",
+		From:   from,
+		To:     score1,
+		Value:  big.NewInt(0),
+		Method: "transfer",
+		Params: map[string]interface{}{"_to": "hx200"},
+	}))
+	assert.NoError(t, tr.OnFrameStart(&module.TraceCall{
+		Type:   "call",
+		From:   score1,
+		To:     score2,
+		Method: "balanceOf",
+	}))
+	assert.NoError(t, tr.OnFrameResult(nil, big.NewInt(100), "0x10"))
+	assert.NoError(t, tr.OnFrameStart(&module.TraceCall{
+		Type:   "call",
+		From:   score1,
+		To:     score2,
+		Method: "fail",
+	}))
+	assert.NoError(t, tr.OnFrameResult(scoreresult.ErrMethodNotFound, big.NewInt(50), nil))
+	assert.NoError(t, tr.OnFrameResult(nil, big.NewInt(1000), nil))
+	assert.Error(t, tr.OnFrameResult(nil, big.NewInt(0), nil))
+	assert.NoError(t, tr.OnTransactionEnd(0, txHash))
+
+	results := tr.ToJSON()
+	assert.Len(t, results, 1)
+	jso := toJSONMap(t, results[0])
+	assert.Equal(t, "0x0", jso["txIndex"])
+	assert.Equal(t, common.HexBytes(txHash).String(), jso["txHash"])
+	calls := jso["calls"].([]interface{})
+	assert.Len(t, calls, 1)
+	c1 := calls[0].(map[string]interface{})
+	assert.Equal(t, score1.String(), c1["to"])
+	assert.Equal(t, "transfer", c1["method"])
+	assert.Equal(t, "0x1", c1["status"])
+	assert.Equal(t, "0x3e8", c1["steps"])
+	sub := c1["calls"].([]interface{})
+	assert.Len(t, sub, 2)
+	assert.Equal(t, "0x10", sub[0].(map[string]interface{})["return"])
+	assert.Equal(t, "0x0", sub[1].(map[string]interface{})["status"])
+	assert.NotNil(t, sub[1].(map[string]interface{})["failure"])
+}
+
+func TestStorageDiffTracer(t *testing.T) {
+	score1 := common.MustNewAddressFromString("cx101")
+	score2 := common.MustNewAddressFromString("cx102")
+
+	tr := NewStorageDiffTracer()
+	assert.NoError(t, tr.OnTransactionStart(1, nil, true))
+	assert.NoError(t, tr.OnFrameStart(&module.TraceCall{}))
+	assert.NoError(t, tr.OnStorageChange(score1, []byte("k1"), nil, []byte("v1")))
+	assert.NoError(t, tr.OnStorageChange(score1, []byte("k1"), []byte("v1"), []byte("v2")))
+	assert.NoError(t, tr.OnStorageChange(score1, []byte("k0"), []byte("v0"), nil))
+	assert.NoError(t, tr.OnStorageChange(score1, []byte("k2"), []byte("v"), []byte("v")))
+
+	// changes of the failed frame are ignored
+	assert.NoError(t, tr.OnFrameStart(&module.TraceCall{}))
+	assert.NoError(t, tr.OnStorageChange(score2, []byte("k1"), nil, []byte("v1")))
+	assert.NoError(t, tr.OnFrameResult(scoreresult.ErrOutOfStep, big.NewInt(0), nil))
+
+	assert.NoError(t, tr.OnFrameResult(nil, big.NewInt(0), nil))
+	assert.NoError(t, tr.OnTransactionEnd(1, nil))
+
+	results := tr.ToJSON()
+	assert.Len(t, results, 1)
+	jso := toJSONMap(t, results[0])
+	assert.Equal(t, "0x1", jso["txIndex"])
+	assert.NotContains(t, jso, "txHash")
+	storage := jso["storage"].([]interface{})
+	assert.Len(t, storage, 1)
+	s1 := storage[0].(map[string]interface{})
+	assert.Equal(t, score1.String(), s1["score"])
+	changes := s1["changes"].([]interface{})
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"key": "0x6b30", "before": "0x7630", "after": nil},
+		map[string]interface{}{"key": "0x6b31", "before": nil, "after": "0x7632"},
+	}, changes)
+}
+
+func TestStepProfileTracer(t *testing.T) {
+	tr := NewStepProfileTracer()
+	assert.NoError(t, tr.OnTransactionStart(0, nil, false))
+	assert.NoError(t, tr.OnStepApply("default", big.NewInt(100000)))
+	assert.NoError(t, tr.OnStepApply("input", big.NewInt(200)))
+
+	// frame executed by the execution engine
+	assert.NoError(t, tr.OnFrameStart(&module.TraceCall{}))
+	assert.NoError(t, tr.OnStepApply("contractCall", big.NewInt(25000)))
+	assert.NoError(t, tr.OnStepEstimate("get", big.NewInt(100)))
+	assert.NoError(t, tr.OnStepEstimate("set", big.NewInt(300)))
+
+	// child frame with system method
+	assert.NoError(t, tr.OnFrameStart(&module.TraceCall{}))
+	assert.NoError(t, tr.OnStepApply("apiCall", big.NewInt(10000)))
+	assert.NoError(t, tr.OnFrameResult(nil, big.NewInt(10000), nil))
+
+	// reported steps by the execution engine including the child frame
+	assert.NoError(t, tr.OnStepApply("", big.NewInt(15000)))
+	assert.NoError(t, tr.OnFrameResult(nil, big.NewInt(40000), nil))
+
+	assert.NoError(t, tr.OnStepApply("", big.NewInt(40000)))
+	assert.NoError(t, tr.OnTransactionEnd(0, nil))
+
+	results := tr.ToJSON()
+	assert.Len(t, results, 1)
+	jso := toJSONMap(t, results[0])
+	assert.Equal(t, "0x223a8", jso["steps"])
+	assert.Equal(t, map[string]interface{}{
+		"default":      "0x186a0",
+		"input":        "0xc8",
+		"contractCall": "0x61a8",
+		"apiCall":      "0x2710",
+		"get":          "0x64",
+		"set":          "0x12c",
+		"execution":    "0x11f8",
+	}, jso["categories"])
+}
@@ -146,3 +146,19 @@ func Unwrap(t module.Transaction) module.Transaction {
 		return t
 	}
 }
+
+type stepLimiter interface {
+	StepLimit() *big.Int
+}
+
+var zeroStepLimit = new(big.Int)
+
+// StepLimitOf returns step limit of the transaction. It returns zero for
+// transactions without step limit (ex. genesis transaction).
+// Returned value must not be modified.
+func StepLimitOf(t module.Transaction) *big.Int {
+	if sl, ok := Unwrap(t).(stepLimiter); ok {
+		return sl.StepLimit()
+	}
+	return zeroStepLimit
+}
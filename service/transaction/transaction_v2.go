@@ -203,6 +203,12 @@ func (tx *transactionV2) Nonce() *big.Int {
 	return nil
 }
 
+// StepLimit returns fixed steps for the transaction, because V2 transaction
+// always uses fixed fee (version2FixedFee).
+func (tx *transactionV2) StepLimit() *big.Int {
+	return version2StepUsed
+}
+
 func (tx *transactionV2) To() module.Address {
 	return &tx.transactionV3Data.To
 }
@@ -168,8 +168,8 @@ func (tx *transactionV3) Verify() error {
 	if tx.Value != nil && tx.Value.Sign() < 0 {
 		return InvalidTxValue.Errorf("InvalidTxValue(%s)", tx.Value.String())
 	}
-	if tx.StepLimit.Sign() < 0 {
-		return InvalidTxValue.Errorf("InvalidTxStepLimit(%s)", tx.StepLimit.String())
+	if tx.transactionV3Data.StepLimit.Sign() < 0 {
+		return InvalidTxValue.Errorf("InvalidTxStepLimit(%s)", tx.transactionV3Data.StepLimit.String())
 	}
 
 	// character level size of data element <= 512KB
@@ -243,15 +243,15 @@ func (tx *transactionV3) PreValidate(wc state.WorldContext, update bool) error {
 			return err
 		}
 		minStep := big.NewInt(wc.StepsFor(state.StepTypeDefault, 1) + wc.StepsFor(state.StepTypeInput, cnt))
-		if tx.StepLimit.Cmp(minStep) < 0 {
-			return NotEnoughStepError.Errorf("NotEnoughStep(txStepLimit:%s, minStep:%s)", &tx.StepLimit.Int, minStep)
+		if tx.transactionV3Data.StepLimit.Cmp(minStep) < 0 {
+			return NotEnoughStepError.Errorf("NotEnoughStep(txStepLimit:%s, minStep:%s)", &tx.transactionV3Data.StepLimit.Int, minStep)
 		}
 	}
 
 	// balance >= (fee + value)
 	stepPrice := wc.StepPrice()
 
-	trans := new(big.Int).Mul(&tx.StepLimit.Int, stepPrice)
+	trans := new(big.Int).Mul(&tx.transactionV3Data.StepLimit.Int, stepPrice)
 	if tx.Value != nil {
 		trans.Add(trans, &tx.Value.Int)
 	}
@@ -296,7 +296,7 @@ func (tx *transactionV3) GetHandler(cm contract.ContractManager) (Handler, error
 		tx.From(),
 		tx.To(),
 		value,
-		&tx.StepLimit.Int,
+		&tx.transactionV3Data.StepLimit.Int,
 		tx.DataType,
 		tx.Data)
 }
@@ -345,6 +345,10 @@ func (tx *transactionV3) Nonce() *big.Int {
 	return nil
 }
 
+func (tx *transactionV3) StepLimit() *big.Int {
+	return &tx.transactionV3Data.StepLimit.Int
+}
+
 func (tx *transactionV3) To() module.Address {
 	return &tx.transactionV3Data.To
 }
@@ -1,8 +1,10 @@
 package service
 
 import (
+	"math/big"
 	"time"
 
+	"github.com/icon-project/goloop/common/intconv"
 	"github.com/icon-project/goloop/module"
 	"github.com/icon-project/goloop/service/transaction"
 )
@@ -15,6 +17,15 @@ func indexAndBucketKeyFromKey(k string) (int, string) {
 	return int(k[0]), k[1:]
 }
 
+// nonceKeyOf returns the key for the sender and the nonce. Nonce of the
+// transaction is optional, so it returns false if the nonce is nil.
+func nonceKeyOf(from module.Address, nonce *big.Int) (string, bool) {
+	if nonce == nil {
+		return "", false
+	}
+	return string(from.ID()) + string(intconv.BigIntToBytes(nonce)), true
+}
+
 type transactionList struct {
 	size      int
 	listFront *txElement
@@ -22,12 +33,18 @@ type transactionList struct {
 
 	idMap        []map[string]*txElement
 	srcMapToLast []map[string]*txElement
+	nonceMap     map[string]*txElement
 }
 
 type txElement struct {
-	value transaction.Transaction
-	ts    int64
-	err   error
+	value     transaction.Transaction
+	ts        int64
+	err       error
+	stepLimit *big.Int
+
+	// used by TransactionPool.Candidate
+	senderSteps *big.Int
+	order       int
 
 	list               *transactionList
 	listNext, listPrev *txElement
@@ -119,14 +136,18 @@ func (l *transactionList) Add(tx transaction.Transaction, ts bool) error {
 	}
 
 	e := &txElement{
-		value: tx,
-		list:  l,
+		value:     tx,
+		list:      l,
+		stepLimit: transaction.StepLimitOf(tx),
 	}
 	if ts {
 		e.ts = time.Now().UnixNano()
 	}
 
 	l.idMap[tidBk][tidSlot] = e
+	if key, ok := nonceKeyOf(tx.From(), tx.Nonce()); ok {
+		l.nonceMap[key] = e
+	}
 
 	uidBk, uidSlot := indexAndBucketKeyFromKey(string(tx.From().ID()))
 	t2, ok := l.srcMapToLast[uidBk][uidSlot]
@@ -229,12 +250,40 @@ func (l *transactionList) Remove(t *txElement) bool {
 
 	tidBk, tidSlot := indexAndBucketKeyFromKey(string(t.value.ID()))
 	delete(l.idMap[tidBk], tidSlot)
+	if key, ok := nonceKeyOf(t.value.From(), t.value.Nonce()); ok {
+		if l.nonceMap[key] == t {
+			delete(l.nonceMap, key)
+		}
+	}
 
 	l.size -= 1
 	t.list = nil
 	return true
 }
 
+// FindByNonce returns the transaction element from the sender with the
+// same nonce. It returns nil if there is no such element or the nonce is nil.
+func (l *transactionList) FindByNonce(from module.Address, nonce *big.Int) *txElement {
+	if key, ok := nonceKeyOf(from, nonce); ok {
+		return l.nonceMap[key]
+	}
+	return nil
+}
+
+// Get returns the transaction element with the id. It returns nil if there
+// is no such element.
+func (l *transactionList) Get(id []byte) *txElement {
+	tidBk, tidSlot := indexAndBucketKeyFromKey(string(id))
+	return l.idMap[tidBk][tidSlot]
+}
+
+// LastOf returns the last transaction element from the sender.
+// Previous ones can be found by following srcPrev.
+func (l *transactionList) LastOf(from module.Address) *txElement {
+	uidBk, uidSlot := indexAndBucketKeyFromKey(string(from.ID()))
+	return l.srcMapToLast[uidBk][uidSlot]
+}
+
 func (l *transactionList) Front() *txElement {
 	return l.listFront
 }
@@ -265,5 +314,6 @@ func newTransactionList() *transactionList {
 		l.idMap[i] = make(map[string]*txElement)
 		l.srcMapToLast[i] = make(map[string]*txElement)
 	}
+	l.nonceMap = make(map[string]*txElement)
 	return l
 }
@@ -15,7 +15,10 @@ type mockTransaction struct {
 	NID       int
 	id        []byte
 	from      module.Address
+	to        module.Address
 	timeStamp int64
+	nonce     *big.Int
+	stepLimit *big.Int
 }
 
 func (*mockTransaction) Group() module.TransactionGroup {
@@ -51,7 +54,7 @@ func (*mockTransaction) ToJSON(version module.JSONVersion) (interface{}, error)
 }
 
 func (*mockTransaction) PreValidate(wc state.WorldContext, update bool) error {
-	panic("implement me")
+	return nil
 }
 
 func (*mockTransaction) GetHandler(cm contract.ContractManager) (transaction.Handler, error) {
@@ -62,12 +65,19 @@ func (t *mockTransaction) Timestamp() int64 {
 	return t.timeStamp
 }
 
-func (*mockTransaction) Nonce() *big.Int {
-	panic("implement me")
+func (t *mockTransaction) Nonce() *big.Int {
+	return t.nonce
+}
+
+func (t *mockTransaction) StepLimit() *big.Int {
+	if t.stepLimit == nil {
+		return new(big.Int)
+	}
+	return t.stepLimit
 }
 
 func (t *mockTransaction) To() module.Address {
-	panic("implement me")
+	return t.to
 }
 
 func (t *mockTransaction) ValidateNetwork(nid int) bool {
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"container/list"
 	"sync"
 
 	"github.com/icon-project/goloop/common/errors"
@@ -12,10 +13,72 @@ import (
 
 const (
 	hashSize = 32
+
+	configDroppedTxCacheSize = 4096
 )
 
 type hashValue [hashSize]byte
 
+func hashValueOf(id []byte) hashValue {
+	var hv hashValue
+	copy(hv[:], id)
+	return hv
+}
+
+// droppedTxCache keeps the reasons of recently dropped transactions.
+type droppedTxCache struct {
+	size  int
+	queue *list.List
+	items map[hashValue]*list.Element
+}
+
+type droppedTx struct {
+	hv     hashValue
+	reason error
+}
+
+func (c *droppedTxCache) add(id []byte, reason error) {
+	hv := hashValueOf(id)
+	if e, ok := c.items[hv]; ok {
+		e.Value.(*droppedTx).reason = reason
+		c.queue.MoveToBack(e)
+		return
+	}
+	c.items[hv] = c.queue.PushBack(&droppedTx{hv, reason})
+	for c.queue.Len() > c.size {
+		e := c.queue.Front()
+		c.queue.Remove(e)
+		delete(c.items, e.Value.(*droppedTx).hv)
+	}
+}
+
+func (c *droppedTxCache) remove(id []byte) {
+	hv := hashValueOf(id)
+	if e, ok := c.items[hv]; ok {
+		c.queue.Remove(e)
+		delete(c.items, hv)
+	}
+}
+
+func (c *droppedTxCache) get(id []byte) (error, bool) {
+	if e, ok := c.items[hashValueOf(id)]; ok {
+		return e.Value.(*droppedTx).reason, true
+	}
+	return nil, false
+}
+
+func newDroppedTxCache(size int) *droppedTxCache {
+	return &droppedTxCache{
+		size:  size,
+		queue: list.New(),
+		items: make(map[hashValue]*list.Element),
+	}
+}
+
+type txPoolWatcher struct {
+	cb func(ev *module.TxPoolEvent)
+}
+
 type TransactionManager struct {
 	nid  int
 	tsc  *TxTimestampChecker
@@ -27,8 +90,11 @@ type TransactionManager struct {
 	normalTxPool *TransactionPool
 
 	callback func()
+	index    module.AddressIndex
 
 	txWaiters map[hashValue][]chan<- interface{}
+	dropped   *droppedTxCache
+	watchers  map[*txPoolWatcher]struct{}
 }
 
 func (m *TransactionManager) getTxPool(g module.TransactionGroup) *TransactionPool {
@@ -65,9 +131,17 @@ func (m *TransactionManager) Candidate(
 }
 
 func (m *TransactionManager) NotifyFinalized(
+	height int64,
 	l1 module.TransactionList, r1 module.ReceiptList,
 	l2 module.TransactionList, r2 module.ReceiptList,
 ) {
+	if m.index != nil {
+		if err := m.index.AddBlock(height, l1, r1, l2, r2); err != nil {
+			m.log.Warnf("Fail to index transactions height=%d err=%+v",
+				height, err)
+		}
+	}
+
 	m.lock.Lock()
 	defer m.lock.Unlock()
 	w1 := len(m.txWaiters)
@@ -123,6 +197,26 @@ func (m *TransactionManager) removeWaitersInLock(id []byte) []chan<- interface{}
 	return nil
 }
 
+func (m *TransactionManager) removeWaiter(id []byte, rc chan<- interface{}) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	var hv hashValue
+	copy(hv[:], id)
+	ws := m.txWaiters[hv]
+	for i, c := range ws {
+		if c == rc {
+			ws = append(ws[:i], ws[i+1:]...)
+			break
+		}
+	}
+	if len(ws) == 0 {
+		delete(m.txWaiters, hv)
+	} else {
+		m.txWaiters[hv] = ws
+	}
+}
+
 func (m *TransactionManager) removeWaiters(id []byte) []chan<- interface{} {
 	m.lock.Lock()
 	defer m.lock.Unlock()
@@ -130,8 +224,9 @@ func (m *TransactionManager) removeWaiters(id []byte) []chan<- interface{} {
 }
 
 type TxDrop struct {
-	ID  []byte
-	Err error
+	ID   []byte
+	From module.Address
+	Err  error
 }
 
 func (m *TransactionManager) OnTxDrops(drops []TxDrop) {
@@ -144,22 +239,91 @@ func (m *TransactionManager) OnTxDrops(drops []TxDrop) {
 			c <- drop.Err
 			close(c)
 		}
+		m.dropped.add(drop.ID, drop.Err)
+		m.notifyInLock(&module.TxPoolEvent{
+			Type:  module.TxPoolEventDrop,
+			ID:    drop.ID,
+			From:  drop.From,
+			Error: drop.Err,
+		})
+	}
+}
+
+func (m *TransactionManager) notifyInLock(ev *module.TxPoolEvent) {
+	for w := range m.watchers {
+		w.cb(ev)
+	}
+}
+
+// Watch registers the callback for the events of the pools. The callback
+// is called with the lock of the manager. It returns the function to stop
+// watching.
+func (m *TransactionManager) Watch(cb func(ev *module.TxPoolEvent)) func() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	w := &txPoolWatcher{cb: cb}
+	m.watchers[w] = struct{}{}
+	return func() {
+		m.lock.Lock()
+		defer m.lock.Unlock()
+		delete(m.watchers, w)
 	}
 }
 
+// PendingTransactions returns transactions in the pools. Patch transactions
+// come first.
+func (m *TransactionManager) PendingTransactions(from module.Address, max int) []*module.PendingTransaction {
+	txs := m.patchTxPool.PendingTransactions(from, max)
+	if max > 0 {
+		if len(txs) >= max {
+			return txs
+		}
+		max -= len(txs)
+	}
+	return append(txs, m.normalTxPool.PendingTransactions(from, max)...)
+}
+
+// GetStatus returns the status of the transaction with the reason of the
+// last failure for pending or dropped one.
+func (m *TransactionManager) GetStatus(id []byte) (module.TransactionStatus, error) {
+	if ptx := m.normalTxPool.GetPending(id); ptx != nil {
+		return module.TransactionStatusPending, ptx.Error
+	}
+	if ptx := m.patchTxPool.GetPending(id); ptx != nil {
+		return module.TransactionStatusPending, ptx.Error
+	}
+	if has, err := m.tim.HasRecent(id); err == nil && has {
+		return module.TransactionStatusCommitted, nil
+	}
+	if has, err := m.tim.HasLocator(id); err == nil && has {
+		return module.TransactionStatusCommitted, nil
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if reason, ok := m.dropped.get(id); ok {
+		return module.TransactionStatusDropped, reason
+	}
+	return module.TransactionStatusUnknown, nil
+}
+
 func (m *TransactionManager) AddAndWait(tx transaction.Transaction) (
 	<-chan interface{}, error,
 ) {
+	// register the waiter before adding, so that the result is delivered
+	// even if the transaction is finalized right after it's added.
+	rc := make(chan interface{}, 1)
 	m.lock.Lock()
-	defer m.lock.Unlock()
+	m.addWaiterInLock(tx.ID(), rc)
+	m.lock.Unlock()
 
-	if err := m.addInLock(tx, true); err != nil {
+	if err := m.add(tx, true); err != nil {
 		if err != ErrDuplicateTransaction {
+			m.removeWaiter(tx.ID(), rc)
 			return nil, err
 		}
 	}
-	rc := make(chan interface{}, 1)
-	m.addWaiterInLock(tx.ID(), rc)
 	return rc, nil
 }
 
@@ -191,9 +355,7 @@ func (m *TransactionManager) Add(tx transaction.Transaction, direct bool, verifi
 			return err
 		}
 	}
-	m.lock.Lock()
-	defer m.lock.Unlock()
-	return m.addInLock(tx, direct)
+	return m.add(tx, direct)
 }
 
 func (m *TransactionManager) VerifyTx(tx transaction.Transaction) error {
@@ -210,7 +372,10 @@ func (m *TransactionManager) VerifyTx(tx transaction.Transaction) error {
 	}
 	return nil
 }
-func (m *TransactionManager) addInLock(tx transaction.Transaction, direct bool) error {
+
+// add adds the transaction to the pool. The pool is accessed without the
+// lock, because the pool reports replaced transactions through OnTxDrops.
+func (m *TransactionManager) add(tx transaction.Transaction, direct bool) error {
 	if err := m.tim.CheckTXForAdd(tx); err != nil {
 		return err
 	}
@@ -219,6 +384,16 @@ func (m *TransactionManager) addInLock(tx transaction.Transaction, direct bool)
 	if err := pool.Add(tx, direct); err != nil {
 		return err
 	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.dropped.remove(tx.ID())
+	m.notifyInLock(&module.TxPoolEvent{
+		Type:        module.TxPoolEventAdd,
+		ID:          tx.ID(),
+		From:        tx.From(),
+		Transaction: tx,
+	})
 	if m.callback != nil {
 		cb := m.callback
 		m.callback = nil
@@ -256,6 +431,11 @@ func (m *TransactionManager) SetPoolCapacityMonitor(pcm PoolCapacityMonitor) {
 	m.normalTxPool.SetPoolCapacityMonitor(pcm)
 }
 
+// SetAddressIndex sets the index to be updated on finalization.
+func (m *TransactionManager) SetAddressIndex(idx module.AddressIndex) {
+	m.index = idx
+}
+
 func NewTransactionManager(nid int, tsc *TxTimestampChecker, ptp *TransactionPool, ntp *TransactionPool, tim TXIDManager, logger log.Logger) *TransactionManager {
 	txm := &TransactionManager{
 		nid:          nid,
@@ -265,6 +445,8 @@ func NewTransactionManager(nid int, tsc *TxTimestampChecker, ptp *TransactionPoo
 		tim:          tim,
 		log:          logger,
 		txWaiters:    map[hashValue][]chan<- interface{}{},
+		dropped:      newDroppedTxCache(configDroppedTxCacheSize),
+		watchers:     make(map[*txPoolWatcher]struct{}),
 	}
 	ptp.SetTxManager(txm)
 	ntp.SetTxManager(txm)
@@ -17,6 +17,8 @@
 package service
 
 import (
+	"container/heap"
+	"math/big"
 	"sync"
 	"time"
 
@@ -32,6 +34,8 @@ const (
 	configDefaultMaxTxBytesInABlock = 1024 * 1024
 	configDefaultTxSliceCapacity    = 1024
 	configDefaultMaxTxCount         = 1500
+	configSenderShareOfBlock        = 20 // percent of max tx count in a block
+	configMinReplaceFeeBump         = 10 // percent of the fee of the replaced
 )
 
 type Monitor interface {
@@ -110,7 +114,7 @@ func (tp *TransactionPool) DropOldTXs(bts int64) {
 					"ExpiredTransaction(diff=%s)", TimestampToDuration(bts-tx.Timestamp()))
 			}
 			tp.log.Debugf("DROP TX: id=0x%x reason=%v", tx.ID(), iter.err)
-			drops = append(drops, TxDrop{tx.ID(), iter.err})
+			drops = append(drops, TxDrop{tx.ID(), tx.From(), iter.err})
 			tp.monitor.OnDropTx(len(tx.Bytes()), direct)
 		}
 		iter = next
@@ -122,6 +126,103 @@ func (tp *TransactionPool) DropOldTXs(bts int64) {
 	tp.pcm.OnPoolCapacityUpdated(tp.group, tp.size, tp.list.Len())
 }
 
+// txPriorityQueue orders transactions by the fee paid by their senders.
+// Step price is common for all transactions in a block, so it compares
+// steps instead of fees (steps x step price). Steps which may be paid by
+// the contract with its deposit are excluded (see senderSteps).
+// Transactions with the same fee are ordered by their positions in the
+// pool to keep it deterministic.
+type txPriorityQueue []*txElement
+
+func (q txPriorityQueue) Len() int {
+	return len(q)
+}
+
+func (q txPriorityQueue) Less(i, j int) bool {
+	if c := q[i].senderSteps.Cmp(q[j].senderSteps); c != 0 {
+		return c > 0
+	}
+	return q[i].order < q[j].order
+}
+
+func (q txPriorityQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+}
+
+func (q *txPriorityQueue) Push(x interface{}) {
+	*q = append(*q, x.(*txElement))
+}
+
+func (q *txPriorityQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return e
+}
+
+// payableStepsCache keeps steps which the contracts can pay for the
+// transactions with their deposits while collecting candidates.
+type payableStepsCache struct {
+	wc    state.WorldContext
+	steps map[string]*big.Int
+}
+
+// payableStepsOf returns the steps which the contract can pay for the
+// transaction. It returns nil if the contract uses the system deposit,
+// which can pay all steps.
+func (c *payableStepsCache) payableStepsOf(to module.Address) *big.Int {
+	key := string(to.ID())
+	if steps, ok := c.steps[key]; ok {
+		return steps
+	}
+	var steps *big.Int
+	as := c.wc.GetAccountState(to.ID())
+	if !as.UseSystemDeposit() {
+		steps = as.PayableSteps(c.wc)
+	}
+	c.steps[key] = steps
+	return steps
+}
+
+// senderSteps returns the steps which the sender of the transaction pays at
+// least. The contract may pay the fee with its deposit if the fee sharing is
+// enabled, so the steps payable by the contract are excluded.
+func (c *payableStepsCache) senderSteps(tx transaction.Transaction, stepLimit *big.Int) *big.Int {
+	if !c.wc.FeeSharingEnabled() {
+		return stepLimit
+	}
+	to := tx.To()
+	if to == nil || !to.IsContract() {
+		return stepLimit
+	}
+	payable := c.payableStepsOf(to)
+	if payable == nil || payable.Cmp(stepLimit) >= 0 {
+		return zeroSteps
+	}
+	if payable.Sign() == 0 {
+		return stepLimit
+	}
+	return new(big.Int).Sub(stepLimit, payable)
+}
+
+var zeroSteps = new(big.Int)
+
+// senderLimitFor returns maximum number of transactions from one sender
+// in a block with maxCount transactions.
+func senderLimitFor(maxCount int) int {
+	limit := maxCount * configSenderShareOfBlock / 100
+	if limit < 1 {
+		limit = 1
+	}
+	return limit
+}
+
+// Candidate returns transactions for a new block ordered by the fee paid by
+// the sender. Transactions from the same sender are returned in the order
+// they were added to the pool, and the number of transactions from one
+// sender is limited by senderLimitFor.
 // It returns all candidates for a negative integer n.
 func (tp *TransactionPool) Candidate(wc state.WorldContext, maxBytes int, maxCount int) (
 	[]module.Transaction, int,
@@ -142,13 +243,37 @@ func (tp *TransactionPool) Candidate(wc state.WorldContext, maxBytes int, maxCou
 		maxCount = configDefaultMaxTxCount
 	}
 
+	// push the first transaction of each sender
+	queue := make(txPriorityQueue, 0, configDefaultTxSliceCapacity)
+	psc := &payableStepsCache{wc: wc, steps: make(map[string]*big.Int)}
+	order := 0
+	for e := tp.list.Front(); e != nil; e = e.Next() {
+		e.order = order
+		e.senderSteps = psc.senderSteps(e.Value(), e.stepLimit)
+		order += 1
+		if e.srcPrev == nil {
+			queue = append(queue, e)
+		}
+	}
+	heap.Init(&queue)
+
 	tsr := NewTxTimestampRangeFor(wc, tp.group)
 	txs := make([]module.Transaction, 0, configDefaultTxSliceCapacity)
 	dropped := make([]*txElement, 0, configDefaultTxSliceCapacity)
+	senders := make(map[string]int)
+	senderLimit := senderLimitFor(maxCount)
 	poolSize := tp.list.Len()
 	txSize := int(0)
-	for e := tp.list.Front(); e != nil && txSize < maxBytes && len(txs) < maxCount; e = e.Next() {
+	for queue.Len() > 0 && txSize < maxBytes && len(txs) < maxCount {
+		e := heap.Pop(&queue).(*txElement)
 		tx := e.Value()
+		sender := string(tx.From().ID())
+		if senders[sender] >= senderLimit {
+			continue
+		}
+		if e.srcNext != nil {
+			heap.Push(&queue, e.srcNext)
+		}
 		if err := tsr.CheckTx(tx); err != nil {
 			if ExpiredTransactionError.Equals(err) {
 				if e.err == nil {
@@ -182,6 +307,7 @@ func (tp *TransactionPool) Candidate(wc state.WorldContext, maxBytes int, maxCou
 		}
 		txSize += len(bs)
 		txs = append(txs, tx)
+		senders[sender] += 1
 	}
 	lock.Unlock()
 
@@ -213,27 +339,70 @@ func (tp *TransactionPool) CheckTxs(wc state.WorldContext) bool {
 	return false
 }
 
+// isEnoughFeeBump returns true if the new transaction offers enough fee to
+// replace the old one. Maximum fee of a transaction is step limit x step
+// price, and step price is common for the transactions in the pool. So the
+// new one should have configMinReplaceFeeBump percent more step limit.
+func isEnoughFeeBump(oldLimit, newLimit *big.Int) bool {
+	if newLimit.Cmp(oldLimit) <= 0 {
+		return false
+	}
+	min := new(big.Int).Mul(oldLimit, big.NewInt(100+configMinReplaceFeeBump))
+	return new(big.Int).Mul(newLimit, big.NewInt(100)).Cmp(min) >= 0
+}
+
 /*
 	return nil if tx is nil or tx is added to pool
 	return ErrTransactionPoolOverFlow if pool is full
+	return UnderpricedTransactionError if it doesn't offer
+	configMinReplaceFeeBump percent more fee than the transaction with the
+	same nonce from the sender.
+
+	If there is a transaction with the same nonce from the sender, then
+	the new transaction replaces it. Nonce is optional, so transactions
+	without nonce are never replaced.
 */
 func (tp *TransactionPool) Add(tx transaction.Transaction, direct bool) error {
 	if tx == nil {
 		return nil
 	}
-	tp.mutex.Lock()
-	defer tp.mutex.Unlock()
+	lock := common.LockForAutoCall(&tp.mutex)
+	defer lock.Unlock()
+
+	if tp.list.HasTx(tx.ID()) {
+		return ErrDuplicateTransaction
+	}
 
-	if tp.list.Len() >= tp.size {
+	old := tp.list.FindByNonce(tx.From(), tx.Nonce())
+	if old != nil {
+		stepLimit := transaction.StepLimitOf(tx)
+		if !isEnoughFeeBump(old.stepLimit, stepLimit) {
+			return UnderpricedTransactionError.Errorf(
+				"UnderpricedTransaction(stepLimit=%s,replacing=%s,minBump=%d%%)",
+				stepLimit, old.stepLimit, configMinReplaceFeeBump)
+		}
+	} else if tp.list.Len() >= tp.size {
 		return ErrTransactionPoolOverFlow
 	}
 
-	err := tp.list.Add(tx, direct)
-	if err == nil {
-		tp.monitor.OnAddTx(len(tx.Bytes()), direct)
-		tp.pcm.OnPoolCapacityUpdated(tp.group, tp.size, tp.list.Len())
+	if err := tp.list.Add(tx, direct); err != nil {
+		return err
 	}
-	return err
+	tp.monitor.OnAddTx(len(tx.Bytes()), direct)
+
+	if old != nil {
+		tp.list.Remove(old)
+		old.err = ReplacedTransactionError.Errorf(
+			"ReplacedTransaction(by=%#x)", tx.ID())
+		tp.log.Debugf("DROP TX: id=0x%x reason=%v", old.value.ID(), old.err)
+		tp.monitor.OnDropTx(len(old.value.Bytes()), old.ts != 0)
+		drops := []TxDrop{{old.value.ID(), old.value.From(), old.err}}
+		lock.CallAfterUnlock(func() {
+			tp.txm.OnTxDrops(drops)
+		})
+	}
+	tp.pcm.OnPoolCapacityUpdated(tp.group, tp.size, tp.list.Len())
+	return nil
 }
 
 // removeList remove transactions when transactions are finalized.
@@ -280,6 +449,50 @@ func (tp *TransactionPool) HasTx(tid []byte) bool {
 	return tp.list.HasTx(tid)
 }
 
+func pendingTransactionOf(e *txElement) *module.PendingTransaction {
+	return &module.PendingTransaction{
+		Transaction: e.Value(),
+		Received:    e.ts,
+		Error:       e.err,
+	}
+}
+
+// PendingTransactions returns at most max transactions in the pool in the
+// order of the pool. If from isn't nil, it returns transactions only from
+// the sender in the order of timestamp.
+func (tp *TransactionPool) PendingTransactions(from module.Address, max int) []*module.PendingTransaction {
+	tp.mutex.Lock()
+	defer tp.mutex.Unlock()
+
+	var txs []*module.PendingTransaction
+	if from != nil {
+		var first *txElement
+		for e := tp.list.LastOf(from); e != nil; e = e.srcPrev {
+			first = e
+		}
+		for e := first; e != nil && (max <= 0 || len(txs) < max); e = e.srcNext {
+			txs = append(txs, pendingTransactionOf(e))
+		}
+		return txs
+	}
+	for e := tp.list.Front(); e != nil && (max <= 0 || len(txs) < max); e = e.Next() {
+		txs = append(txs, pendingTransactionOf(e))
+	}
+	return txs
+}
+
+// GetPending returns the transaction in the pool. It returns nil if there
+// is no such transaction.
+func (tp *TransactionPool) GetPending(tid []byte) *module.PendingTransaction {
+	tp.mutex.Lock()
+	defer tp.mutex.Unlock()
+
+	if e := tp.list.Get(tid); e != nil {
+		return pendingTransactionOf(e)
+	}
+	return nil
+}
+
 func (tp *TransactionPool) Size() int {
 	return tp.size
 }
@@ -316,6 +529,8 @@ func (tp *TransactionPool) GetBloom() *TxBloom {
 func (tp *TransactionPool) dropTransactions(txs []*txElement) {
 	lock := common.LockForAutoCall(&tp.mutex)
 	defer lock.Unlock()
+	// tp.mutex.Lock()
+	// defer tp.mutex.Unlock()
 
 	var drops []TxDrop
 	for _, e := range txs {
@@ -326,7 +541,7 @@ func (tp *TransactionPool) dropTransactions(txs []*txElement) {
 				tp.log.Panicf("No reason to drop the tx=<%#x>", tx.ID())
 			}
 			tp.log.Debugf("DROP TX: id=0x%x reason=%v", tx.ID(), e.err)
-			drops = append(drops, TxDrop{tx.ID(), e.err})
+			drops = append(drops, TxDrop{tx.ID(), tx.From(), e.err})
 			tp.monitor.OnDropTx(len(tx.Bytes()), direct)
 		}
 	}
@@ -1,13 +1,18 @@
 package service
 
 import (
+	"fmt"
+	"math/big"
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
+
 	"github.com/icon-project/goloop/common"
 	"github.com/icon-project/goloop/common/db"
 	"github.com/icon-project/goloop/common/log"
 	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/state"
 )
 
 type mockMonitor struct {
@@ -43,3 +48,301 @@ func TestTransactionPool_Add(t *testing.T) {
 		t.Error("Fail to add transaction with valid network ID")
 	}
 }
+
+type mockWorldContext struct {
+	state.WorldContext
+	ts         int64
+	feeSharing bool
+	accounts   map[string]state.AccountState
+}
+
+func (wc *mockWorldContext) FeeSharingEnabled() bool {
+	return wc.feeSharing
+}
+
+func (wc *mockWorldContext) GetAccountState(id []byte) state.AccountState {
+	return wc.accounts[string(id)]
+}
+
+func (wc *mockWorldContext) BlockTimeStamp() int64 {
+	return wc.ts
+}
+
+func (wc *mockWorldContext) TransactionTimestampThreshold() int64 {
+	return 0
+}
+
+func (wc *mockWorldContext) StepPrice() *big.Int {
+	return big.NewInt(10)
+}
+
+func newMockTransactionWithFee(id string, from module.Address, ts int64, nonce int64, stepLimit int64) *mockTransaction {
+	tx := newMockTransaction([]byte(id), from, ts)
+	tx.nonce = big.NewInt(nonce)
+	tx.stepLimit = big.NewInt(stepLimit)
+	return tx
+}
+
+type mockAccountState struct {
+	state.AccountState
+	payable *big.Int
+}
+
+func (as *mockAccountState) UseSystemDeposit() bool {
+	return as.payable == nil
+}
+
+func (as *mockAccountState) PayableSteps(pc state.PayContext) *big.Int {
+	return as.payable
+}
+
+func newTestTransactionPool(size int) *TransactionPool {
+	dbase := db.NewMapDB()
+	tsc := NewTimestampChecker()
+	tim, _ := NewTXIDManager(dbase, tsc)
+	return NewTransactionPool(module.TransactionGroupNormal, size, tim, &mockMonitor{}, log.New())
+}
+
+func TestTransactionPool_CandidateByFee(t *testing.T) {
+	pool := newTestTransactionPool(5000)
+
+	addr1 := common.MustNewAddressFromString("hx1111111111111111111111111111111111111111")
+	addr2 := common.MustNewAddressFromString("hx2222222222222222222222222222222222222222")
+	addr3 := common.MustNewAddressFromString("hx3333333333333333333333333333333333333333")
+
+	tx1 := newMockTransactionWithFee("tx1", addr1, 1, 1, 100)
+	tx2 := newMockTransactionWithFee("tx2", addr1, 2, 2, 300)
+	tx3 := newMockTransactionWithFee("tx3", addr2, 1, 1, 200)
+	tx4 := newMockTransactionWithFee("tx4", addr3, 1, 1, 200)
+
+	for _, tx := range []*mockTransaction{tx1, tx2, tx3, tx4} {
+		assert.NoError(t, pool.Add(tx, true))
+	}
+
+	wc := &mockWorldContext{ts: 1}
+	txs, _ := pool.Candidate(wc, 0, 0)
+	assert.Equal(t, []module.Transaction{tx3, tx4, tx1, tx2}, txs)
+
+	// same fee keeps the order in the pool
+	txs, _ = pool.Candidate(wc, 0, 2)
+	assert.Equal(t, []module.Transaction{tx3, tx4}, txs)
+}
+
+func TestTransactionPool_CandidateByFeeSharing(t *testing.T) {
+	pool := newTestTransactionPool(5000)
+
+	addr1 := common.MustNewAddressFromString("hx1111111111111111111111111111111111111111")
+	addr2 := common.MustNewAddressFromString("hx2222222222222222222222222222222222222222")
+	addr3 := common.MustNewAddressFromString("hx3333333333333333333333333333333333333333")
+	score1 := common.MustNewAddressFromString("cx1111111111111111111111111111111111111111")
+	score2 := common.MustNewAddressFromString("cx2222222222222222222222222222222222222222")
+
+	// tx1 pays 300 - 250, tx2 pays 200, tx3 pays nothing
+	tx1 := newMockTransactionWithFee("tx1", addr1, 1, 1, 300)
+	tx1.to = score1
+	tx2 := newMockTransactionWithFee("tx2", addr2, 1, 1, 200)
+	tx3 := newMockTransactionWithFee("tx3", addr3, 1, 1, 100)
+	tx3.to = score2
+	for _, tx := range []*mockTransaction{tx1, tx2, tx3} {
+		assert.NoError(t, pool.Add(tx, true))
+	}
+
+	wc := &mockWorldContext{
+		ts: 1,
+		accounts: map[string]state.AccountState{
+			string(score1.ID()): &mockAccountState{payable: big.NewInt(250)},
+			string(score2.ID()): &mockAccountState{},
+		},
+	}
+	txs, _ := pool.Candidate(wc, 0, 0)
+	assert.Equal(t, []module.Transaction{tx1, tx2, tx3}, txs)
+
+	wc.feeSharing = true
+	txs, _ = pool.Candidate(wc, 0, 0)
+	assert.Equal(t, []module.Transaction{tx2, tx1, tx3}, txs)
+}
+
+func TestTransactionPool_CandidateSenderLimit(t *testing.T) {
+	pool := newTestTransactionPool(5000)
+
+	addr1 := common.MustNewAddressFromString("hx1111111111111111111111111111111111111111")
+	addr2 := common.MustNewAddressFromString("hx2222222222222222222222222222222222222222")
+
+	var txs1 []module.Transaction
+	for i := 0; i < 10; i++ {
+		tx := newMockTransactionWithFee(fmt.Sprintf("tx1-%d", i), addr1, 1, int64(i), 1000)
+		assert.NoError(t, pool.Add(tx, true))
+		txs1 = append(txs1, tx)
+	}
+	tx2 := newMockTransactionWithFee("tx2", addr2, 1, 1, 100)
+	assert.NoError(t, pool.Add(tx2, true))
+
+	wc := &mockWorldContext{ts: 1}
+	txs, _ := pool.Candidate(wc, 0, 10)
+	limit := senderLimitFor(10)
+	assert.Equal(t, append(txs1[0:limit:limit], tx2), txs)
+}
+
+func TestTransactionPool_Replace(t *testing.T) {
+	pool := newTestTransactionPool(1)
+
+	addr := common.MustNewAddressFromString("hx1111111111111111111111111111111111111111")
+	tx1 := newMockTransactionWithFee("tx1", addr, 1, 1, 100)
+	tx2 := newMockTransactionWithFee("tx2", addr, 2, 1, 100)
+	tx3 := newMockTransactionWithFee("tx3", addr, 3, 1, 109)
+	tx4 := newMockTransactionWithFee("tx4", addr, 4, 1, 110)
+	tx5 := newMockTransactionWithFee("tx5", addr, 5, 2, 300)
+
+	assert.NoError(t, pool.Add(tx1, true))
+	assert.Equal(t, ErrDuplicateTransaction, pool.Add(tx1, true))
+
+	err := pool.Add(tx2, true)
+	assert.True(t, UnderpricedTransactionError.Equals(err))
+	assert.True(t, pool.HasTx(tx1.ID()))
+
+	// it requires configMinReplaceFeeBump percent more fee
+	err = pool.Add(tx3, true)
+	assert.True(t, UnderpricedTransactionError.Equals(err))
+	assert.True(t, pool.HasTx(tx1.ID()))
+
+	assert.NoError(t, pool.Add(tx4, true))
+	assert.False(t, pool.HasTx(tx1.ID()))
+	assert.True(t, pool.HasTx(tx4.ID()))
+	assert.Equal(t, 1, pool.Used())
+
+	assert.Equal(t, ErrTransactionPoolOverFlow, pool.Add(tx5, true))
+}
+
+func TestTransactionPool_ReplaceWithoutNonce(t *testing.T) {
+	pool := newTestTransactionPool(5000)
+
+	addr := common.MustNewAddressFromString("hx1111111111111111111111111111111111111111")
+	tx1 := newMockTransactionWithFee("tx1", addr, 1, 0, 100)
+	tx1.nonce = nil
+	tx2 := newMockTransactionWithFee("tx2", addr, 2, 0, 200)
+	tx2.nonce = nil
+	tx3 := newMockTransactionWithFee("tx3", addr, 3, 0, 300)
+
+	// transactions without nonce are not replaced
+	assert.NoError(t, pool.Add(tx1, true))
+	assert.NoError(t, pool.Add(tx2, true))
+	assert.NoError(t, pool.Add(tx3, true))
+	assert.Equal(t, 3, pool.Used())
+
+	// the nonce is kept by the replacing one
+	tx4 := newMockTransactionWithFee("tx4", addr, 4, 0, 400)
+	assert.NoError(t, pool.Add(tx4, true))
+	assert.False(t, pool.HasTx(tx3.ID()))
+	tx5 := newMockTransactionWithFee("tx5", addr, 5, 0, 400)
+	assert.True(t, UnderpricedTransactionError.Equals(pool.Add(tx5, true)))
+	assert.Equal(t, 3, pool.Used())
+}
+
+func TestTransactionPool_PendingTransactions(t *testing.T) {
+	pool := newTestTransactionPool(5000)
+
+	addr1 := common.MustNewAddressFromString("hx1111111111111111111111111111111111111111")
+	addr2 := common.MustNewAddressFromString("hx2222222222222222222222222222222222222222")
+
+	tx1 := newMockTransactionWithFee("tx1", addr1, 2, 2, 100)
+	tx2 := newMockTransactionWithFee("tx2", addr2, 1, 1, 100)
+	tx3 := newMockTransactionWithFee("tx3", addr1, 1, 1, 100)
+	assert.NoError(t, pool.Add(tx1, true))
+	assert.NoError(t, pool.Add(tx2, false))
+	assert.NoError(t, pool.Add(tx3, true))
+
+	txsOf := func(ptxs []*module.PendingTransaction) []module.Transaction {
+		var txs []module.Transaction
+		for _, ptx := range ptxs {
+			txs = append(txs, ptx.Transaction)
+		}
+		return txs
+	}
+	assert.Equal(t, []module.Transaction{tx3, tx1, tx2}, txsOf(pool.PendingTransactions(nil, 0)))
+	assert.Equal(t, []module.Transaction{tx3, tx1}, txsOf(pool.PendingTransactions(nil, 2)))
+	assert.Equal(t, []module.Transaction{tx3, tx1}, txsOf(pool.PendingTransactions(addr1, 0)))
+	assert.Equal(t, []module.Transaction{tx3}, txsOf(pool.PendingTransactions(addr1, 1)))
+
+	ptx := pool.GetPending(tx2.ID())
+	if assert.NotNil(t, ptx) {
+		assert.Equal(t, tx2, ptx.Transaction)
+		assert.Zero(t, ptx.Received)
+		assert.NoError(t, ptx.Error)
+	}
+	assert.NotZero(t, pool.GetPending(tx1.ID()).Received)
+	assert.Nil(t, pool.GetPending([]byte("tx4")))
+}
+
+func TestTransactionManager_StatusAndWatch(t *testing.T) {
+	dbase := db.NewMapDB()
+	tsc := NewTimestampChecker()
+	tim, _ := NewTXIDManager(dbase, tsc)
+	ptp := NewTransactionPool(module.TransactionGroupPatch, 5000, tim, &mockMonitor{}, log.New())
+	ntp := NewTransactionPool(module.TransactionGroupNormal, 5000, tim, &mockMonitor{}, log.New())
+	tm := NewTransactionManager(1, tsc, ptp, ntp, tim, log.New())
+
+	evch := make(chan *module.TxPoolEvent, 10)
+	cancel := tm.Watch(func(ev *module.TxPoolEvent) {
+		evch <- ev
+	})
+
+	addr := common.MustNewAddressFromString("hx1111111111111111111111111111111111111111")
+	tx1 := newMockTransactionWithFee("tx1", addr, 1, 1, 100)
+	tx2 := newMockTransactionWithFee("tx2", addr, 2, 1, 200)
+
+	rc, err := tm.AddAndWait(tx1)
+	assert.NoError(t, err)
+	ev := <-evch
+	assert.Equal(t, module.TxPoolEventAdd, ev.Type)
+	assert.Equal(t, tx1.ID(), ev.ID)
+	assert.True(t, addr.Equal(ev.From))
+
+	status, reason := tm.GetStatus(tx1.ID())
+	assert.Equal(t, module.TransactionStatusPending, status)
+	assert.NoError(t, reason)
+
+	// tx2 replaces tx1, and tx1 is dropped.
+	assert.NoError(t, tm.Add(tx2, true, true))
+	assert.Len(t, evch, 2)
+	ev = <-evch
+	assert.Equal(t, module.TxPoolEventDrop, ev.Type)
+	assert.Equal(t, tx1.ID(), ev.ID)
+	assert.True(t, addr.Equal(ev.From))
+	assert.True(t, ReplacedTransactionError.Equals(ev.Error))
+	ev = <-evch
+	assert.Equal(t, module.TxPoolEventAdd, ev.Type)
+	assert.Equal(t, tx2.ID(), ev.ID)
+	assert.True(t, ReplacedTransactionError.Equals((<-rc).(error)))
+
+	status, reason = tm.GetStatus(tx1.ID())
+	assert.Equal(t, module.TransactionStatusDropped, status)
+	assert.True(t, ReplacedTransactionError.Equals(reason))
+
+	status, _ = tm.GetStatus([]byte("tx3"))
+	assert.Equal(t, module.TransactionStatusUnknown, status)
+
+	assert.Len(t, tm.PendingTransactions(addr, 0), 1)
+
+	cancel()
+	tx3 := newMockTransactionWithFee("tx3", addr, 3, 2, 100)
+	assert.NoError(t, tm.Add(tx3, true, true))
+	assert.Len(t, evch, 0)
+}
+
+func TestDroppedTxCache(t *testing.T) {
+	c := newDroppedTxCache(2)
+	c.add([]byte("tx1"), ErrExpiredTransaction)
+	c.add([]byte("tx2"), ErrExpiredTransaction)
+	c.add([]byte("tx1"), ErrInvalidTransaction)
+	c.add([]byte("tx3"), ErrExpiredTransaction)
+
+	reason, ok := c.get([]byte("tx1"))
+	assert.True(t, ok)
+	assert.Equal(t, ErrInvalidTransaction, reason)
+	_, ok = c.get([]byte("tx2"))
+	assert.False(t, ok)
+
+	c.remove([]byte("tx3"))
+	_, ok = c.get([]byte("tx3"))
+	assert.False(t, ok)
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"bytes"
 	"container/list"
 	"fmt"
 	"math/big"
@@ -718,6 +719,10 @@ func (t *transition) doExecute(alreadyValidated bool) {
 		t.reportExecution(err)
 		return
 	}
+	if err = t.recordValidatorsSince(ctx); err != nil {
+		t.reportExecution(err)
+		return
+	}
 
 	bc := state.NewBTPContext(ctx, ctx.GetAccountState(state.SystemID))
 	if bs, err := ctx.GetBTPState().BuildAndApplySection(bc, btpMsgs); err != nil {
@@ -752,6 +757,28 @@ func (t *transition) doExecute(alreadyValidated bool) {
 	t.reportExecution(nil)
 }
 
+// recordValidatorsSince records the lowest height of the votes signed by the
+// validators in the world state. Validators changed while executing the block
+// at H sign the votes from H+2. It's used to check whether the evidence of
+// double signing can be verified with the validators in the world state.
+func (t *transition) recordValidatorsSince(ctx contract.Context) error {
+	if !ctx.Revision().Has(module.AcceptDoubleSign) {
+		return nil
+	}
+	as := ctx.GetAccountState(state.SystemID)
+	since := scoredb.NewVarDB(as, state.VarValidatorsSince)
+	wss := ctx.GetProperty(contract.PropInitialSnapshot).(state.WorldSnapshot)
+	vss1 := wss.GetValidatorSnapshot()
+	vss2 := ctx.GetValidatorState().GetSnapshot()
+	if !bytes.Equal(vss1.Hash(), vss2.Hash()) {
+		return since.Set(ctx.BlockHeight() + 2)
+	}
+	if since.Bytes() == nil {
+		return since.Set(ctx.BlockHeight() + 1)
+	}
+	return nil
+}
+
 func (t *transition) onPlatformExecutionEnd(ctx contract.Context, er base.ExecutionResult) error {
 	ctx.SetTransactionInfo(&state.TransactionInfo{
 		Index: int32(t.ntxCount),
@@ -799,7 +826,8 @@ func (t *transition) executeTxs(l module.TransactionList, ctx contract.Context,
 		// it will skip skippable transactions
 		return t.executeTxsSequential(l, ctx, rctBuf)
 	}
-	if cc := t.chain.ConcurrencyLevel(); cc > 1 {
+	// trace requires transactions to be executed in order.
+	if cc := t.chain.ConcurrencyLevel(); cc > 1 && t.ti == nil {
 		return t.executeTxsConcurrent(cc, l, ctx, rctBuf)
 	}
 	return t.executeTxsSequential(l, ctx, rctBuf)
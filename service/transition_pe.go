@@ -3,140 +3,149 @@ package service
 import (
 	"sync"
 
-	"github.com/icon-project/goloop/common/errors"
 	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/server/metric"
 	"github.com/icon-project/goloop/service/contract"
 	"github.com/icon-project/goloop/service/state"
 	"github.com/icon-project/goloop/service/transaction"
 	"github.com/icon-project/goloop/service/txresult"
 )
 
-type executionContext struct {
-	waiter    chan struct{}
-	lastError error
-	lock      sync.Mutex
+// speculation is the result of execution of a transaction on the world state
+// before executing the block. It's valid only if the accounts, the storage
+// keys and the keys of the extension state read by the transaction are not
+// modified by the previous transactions.
+type speculation struct {
+	receipt txresult.Receipt
+	reads   *state.AccessSet
+	writes  *state.WriteSet
+	err     error
 }
 
-func (c *executionContext) Done() {
-	c.waiter <- struct{}{}
-}
-
-func (c *executionContext) Ready() {
-	<-c.waiter
+func newTransactionInfo(txo transaction.Transaction, index int) *state.TransactionInfo {
+	return &state.TransactionInfo{
+		Group:     txo.Group(),
+		Index:     int32(index),
+		Timestamp: txo.Timestamp(),
+		Nonce:     txo.Nonce(),
+		Hash:      txo.ID(),
+		From:      txo.From(),
+	}
 }
 
-func (c *executionContext) Error() error {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	return c.lastError
+func (t *transition) newRecordingContext(ctx contract.Context, ws state.WorldState) (contract.Context, state.WorldStateRecorder) {
+	rec := state.NewWorldStateRecorder(ws)
+	rctx := t.newContractContext(ctx.WorldStateChanged(rec))
+	rctx.SetProperty(contract.PropInitialSnapshot, ctx.GetProperty(contract.PropInitialSnapshot))
+	return rctx, rec
 }
 
-func (c *executionContext) Report(e error) {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	if c.lastError != nil {
-		c.lastError = e
+func (t *transition) speculate(ctx contract.Context, base state.WorldSnapshot, txo transaction.Transaction, index int) *speculation {
+	ws, err := state.WorldStateFromSnapshot(base)
+	if err != nil {
+		return &speculation{err: err}
 	}
-}
+	sctx, rec := t.newRecordingContext(ctx, ws)
+	sctx.SetTransactionInfo(newTransactionInfo(txo, index))
 
-func newExecutionContext(n int) *executionContext {
-	ch := make(chan struct{}, n)
-	for i := 0; i < n; i++ {
-		ch <- struct{}{}
+	txh, err := txo.GetHandler(t.cm)
+	if err != nil {
+		return &speculation{err: err}
+	}
+	sctx.UpdateSystemInfo()
+	rct, err := txh.Execute(sctx, rec.GetSnapshot(), false)
+	txh.Dispose()
+	if err == nil {
+		err = t.plt.OnTransactionEnd(sctx, t.log, rct)
+	}
+	if err != nil {
+		return &speculation{err: err}
+	}
+	writes, err := rec.Writes()
+	if err != nil {
+		return &speculation{err: err}
+	}
+	return &speculation{
+		receipt: rct,
+		reads:   rec.Reads(),
+		writes:  writes,
 	}
-	return &executionContext{waiter: ch}
 }
 
+// executeTxsConcurrent executes transactions optimistically. All transactions
+// are executed concurrently on the world state before them, then the results
+// are applied in order. Transactions which read the states modified by the
+// previous transactions are executed again on the updated world state.
 func (t *transition) executeTxsConcurrent(level int, l module.TransactionList, ctx contract.Context, rctBuf []txresult.Receipt) error {
-	ec := newExecutionContext(level)
-
-	cnt := 0
+	var txs []transaction.Transaction
 	for i := l.Iterator(); i.Has(); i.Next() {
-		if err := ec.Error(); err != nil {
-			return err
-		}
-
-		if t.canceled() {
-			return ErrTransitionInterrupted
-		}
-
 		txi, _, err := i.Get()
 		if err != nil {
 			t.log.Errorf("Fail to iterate transaction list err=%+v", err)
 			return err
 		}
-		txo := txi.(transaction.Transaction)
-		txh, err := txo.GetHandler(t.cm)
-		if err != nil {
-			t.log.Debugf("Fail to handle transaction for %+v", err)
-			return err
-		}
-		wc, err2 := txh.Prepare(ctx)
-		ctx = t.newContractContext(wc)
-		if err2 != nil {
-			t.log.Debugf("Fail to prepare for %+v", err2)
-			return err2
-		}
-
-		ec.Ready()
-		go func(ctx contract.Context, wc state.WorldContext, txo transaction.Transaction, cnt int, rb *txresult.Receipt) {
-			wvs := ctx.WorldVirtualState()
-			wvss := wvs.GetSnapshot()
-			for retry := 0; ; retry++ {
-				ctx.SetTransactionInfo(&state.TransactionInfo{
-					Group:     txo.Group(),
-					Index:     int32(cnt),
-					Timestamp: txo.Timestamp(),
-					Nonce:     txo.Nonce(),
-					Hash:      txo.ID(),
-					From:      txo.From(),
-				})
-				ctx.UpdateSystemInfo()
-				rct, err := txh.Execute(ctx, wvss, false)
-				txh.Dispose()
-				if err == nil {
-					err = t.plt.OnTransactionEnd(ctx, t.log, rct)
-				}
-				if err == nil {
-					*rb = rct
-					break
-				}
-
-				if !errors.ExecutionFailError.Equals(err) && !errors.CriticalRerunError.Equals(err) {
-					t.log.Warnf("Fail to execute transaction err=%+v", err)
-					ec.Report(err)
-					break
-				}
-
-				if retry >= RetryCount {
-					t.log.Warnf("Fail to execute transaction retry=%d err=%+v", retry, err)
-					ec.Report(err)
-					break
-				}
-
-				t.log.Warnf("RETRY TX <%#x> for err=%+v", txo.ID(), err)
-				if err := wvs.Reset(wvss); err != nil {
-					t.log.Errorf("Fail to revert status on rerun err=%+v", err)
-					ec.Report(errors.CriticalUnknownError.Wrapf(err, "FailToResetForRetry"))
-					break
-				}
+		txs = append(txs, txi.(transaction.Transaction))
+	}
 
-				txh, err = txo.GetHandler(t.cm)
-				if err != nil {
-					t.log.Debugf("Fail to get handler err=%+v", err)
-					ec.Report(err)
-					break
-				}
-				ctx = t.newContractContext(wc)
+	base := ctx.GetSnapshot()
+	specs := make([]*speculation, len(txs))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(level)
+	for w := 0; w < level; w++ {
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				specs[idx] = t.speculate(ctx, base, txs[idx], idx)
 			}
-			wvs.Commit()
-			ec.Done()
-		}(ctx, wc, txo, cnt, &rctBuf[cnt])
-
-		cnt++
+		}()
+	}
+	for idx := range txs {
+		if t.canceled() {
+			break
+		}
+		jobs <- idx
 	}
-	if wvs := ctx.WorldVirtualState(); wvs != nil {
-		wvs.Realize()
+	close(jobs)
+	wg.Wait()
+
+	dirty := state.NewAccessSet()
+	reExecuted := 0
+	for idx, txo := range txs {
+		if t.canceled() {
+			return ErrTransitionInterrupted
+		}
+		txInfo := newTransactionInfo(txo, idx)
+		ctx.SetTransactionInfo(txInfo)
+		traceLogger := ctx.GetTraceLogger(module.EPhaseTransaction)
+		traceLogger.OnTransactionStart(idx, txo.ID())
+
+		spec := specs[idx]
+		if spec.err == nil && !spec.writes.World && !spec.reads.Conflicts(dirty) {
+			if err := spec.writes.Apply(ctx); err != nil {
+				t.log.Errorf("Fail to apply result of TX <%#x> err=%+v", txo.ID(), err)
+				return err
+			}
+			rctBuf[idx] = spec.receipt
+			dirty.Merge(spec.writes.Footprint())
+		} else {
+			t.log.Tracef("RE-EXECUTE TX <%#x> err=%v", txo.ID(), spec.err)
+			reExecuted++
+			rctx, rec := t.newRecordingContext(ctx, ctx)
+			rctx.SetTransactionInfo(txInfo)
+			rct, _, err := t.executeTx(rctx, txo, rec.GetSnapshot(), traceLogger)
+			if err != nil {
+				return err
+			}
+			writes, err := rec.Writes()
+			if err != nil {
+				return err
+			}
+			rctBuf[idx] = rct
+			dirty.Merge(writes.Footprint())
+		}
+		traceLogger.OnTransactionEnd(idx, txo.ID(), txInfo.From, ctx.Treasury(), ctx.Revision(), rctBuf[idx])
 	}
+	metric.NewExecutionMetric(t.chain.MetricContext()).OnExecute(level, len(txs), reExecuted)
 	return nil
 }
@@ -0,0 +1,155 @@
+/*
+ * Copyright 2022 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/wallet"
+	"github.com/icon-project/goloop/consensus"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/transaction"
+	"github.com/icon-project/goloop/test"
+)
+
+func newTransferTx(t *testing.T, from module.Wallet, to module.Address, value int64, ts int64) string {
+	param := map[string]interface{}{
+		"version":   "0x3",
+		"from":      from.Address().String(),
+		"to":        to.String(),
+		"value":     fmt.Sprintf("0x%x", value),
+		"stepLimit": "0x100000",
+		"timestamp": fmt.Sprintf("0x%x", ts),
+		"nid":       "0x1",
+	}
+	bs, err := transaction.SerializeMap(param, nil, map[string]bool{"signature": true})
+	assert.NoError(t, err)
+	bs = append([]byte("icx_sendTransaction."), bs...)
+	sig, err := from.Sign(crypto.SHA3Sum256(bs))
+	assert.NoError(t, err)
+	param["signature"] = base64.StdEncoding.EncodeToString(sig)
+	js, err := json.Marshal(param)
+	assert.NoError(t, err)
+	return string(js)
+}
+
+func newTransferGenesis(validator module.Wallet, wallets []module.Wallet, balance int64) string {
+	accounts := []string{
+		`{"name": "treasury", "address": "hx1000000000000000000000000000000000000000", "balance": "0x0"}`,
+		`{"name": "god", "address": "hx0000000000000000000000000000000000000000", "balance": "0x0"}`,
+	}
+	for i, w := range wallets {
+		accounts = append(accounts, fmt.Sprintf(
+			`{"name": "user%d", "address": "%s", "balance": "0x%x"}`,
+			i, w.Address(), balance,
+		))
+	}
+	return fmt.Sprintf(`{
+		"accounts": [%s],
+		"message": "",
+		"nid": "0x1",
+		"chain": {"validatorList": ["%s"]}
+	}`, strings.Join(accounts, ","), validator.Address())
+}
+
+func TestTransition_ConcurrentExecution(t *testing.T) {
+	wallets := make([]module.Wallet, 6)
+	for i := range wallets {
+		wallets[i] = wallet.New()
+	}
+	addr := func(i int) module.Address {
+		return wallets[i].Address()
+	}
+	w := wallet.New()
+	gs := newTransferGenesis(w, wallets, 1000)
+
+	seq := test.NewNode(t, test.UseGenesis(gs), test.UseWallet(w))
+	defer seq.Close()
+	con := test.NewNode(t, test.UseGenesis(gs), test.UseWallet(w), test.UseConcurrencyLevel(4))
+	defer con.Close()
+
+	ts := seq.LastBlock.Timestamp()
+	v1, v2 := "v1", "v2"
+	txs := []string{
+		newTransferTx(t, wallets[0], addr(1), 100, ts),
+		newTransferTx(t, wallets[2], addr(3), 100, ts+1),
+		// depends on the first one
+		newTransferTx(t, wallets[1], addr(4), 1050, ts+2),
+		newTransferTx(t, wallets[4], addr(2), 10, ts+3),
+		newTransferTx(t, wallets[5], addr(5), 10, ts+4),
+		// fails for no contract
+		newTransferTx(t, wallets[3], common.MustNewAddressFromString("cx0000000000000000000000000000000000000999"), 10, ts+5),
+		// conflicts on the storage of the system account
+		test.NewTx().SetTimestamp(ts + 6).SetVarTest(&v1).String(),
+		test.NewTx().SetTimestamp(ts + 7).SetVarTest(&v2).String(),
+		// modifies validators
+		test.NewTx().SetTimestamp(ts + 8).SetValidators(w.Address(), addr(0)).String(),
+		newTransferTx(t, wallets[0], addr(5), 200, ts+9),
+	}
+	for _, tx := range txs {
+		_, err := seq.SM.SendTransaction(nil, 0, tx)
+		assert.NoError(t, err)
+	}
+
+	// the next block has the result of the transactions
+	votes := []func() module.CommitVoteSet{
+		consensus.NewEmptyCommitVoteList,
+		seq.NewVoteListForLastBlock,
+	}
+	for _, vf := range votes {
+		bc := seq.ProposeBlock(vf())
+		buf := bytes.NewBuffer(nil)
+		assert.NoError(t, bc.Marshal(buf))
+		seq.FinalizeBlock(bc)
+		bc.Dispose()
+
+		bc = con.ImportBlockByReader(buf, 0)
+		con.FinalizeBlock(bc)
+		bc.Dispose()
+	}
+
+	result := seq.LastBlock.Result()
+	assert.Equal(t, result, con.LastBlock.Result())
+
+	rl1, err := seq.SM.ReceiptListFromResult(result, module.TransactionGroupNormal)
+	assert.NoError(t, err)
+	rl2, err := con.SM.ReceiptListFromResult(con.LastBlock.Result(), module.TransactionGroupNormal)
+	assert.NoError(t, err)
+	var status []module.Status
+	itr1, itr2 := rl1.Iterator(), rl2.Iterator()
+	for ; itr1.Has() && itr2.Has(); _, _ = itr1.Next(), itr2.Next() {
+		r1, err := itr1.Get()
+		assert.NoError(t, err)
+		r2, err := itr2.Get()
+		assert.NoError(t, err)
+		assert.Equal(t, r1.Bytes(), r2.Bytes())
+		status = append(status, r1.Status())
+	}
+	assert.False(t, itr1.Has() || itr2.Has())
+	assert.Len(t, status, len(txs))
+	assert.Equal(t, module.StatusSuccess, status[2])
+	assert.NotEqual(t, module.StatusSuccess, status[5])
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/icon-project/goloop/module"
 	"github.com/icon-project/goloop/service/contract"
 	"github.com/icon-project/goloop/service/state"
+	"github.com/icon-project/goloop/service/trace"
 	"github.com/icon-project/goloop/service/transaction"
 	"github.com/icon-project/goloop/service/txresult"
 )
@@ -36,7 +37,6 @@ func (t *transition) executeTxsSequential(l module.TransactionList, ctx contract
 			continue
 		}
 		t.log.Tracef("START TX <0x%x>", txo.ID())
-		ts := time.Now()
 		txInfo := &state.TransactionInfo{
 			Group:     txo.Group(),
 			Index:     int32(cnt),
@@ -50,37 +50,11 @@ func (t *transition) executeTxsSequential(l module.TransactionList, ctx contract
 		traceLogger := ctx.GetTraceLogger(module.EPhaseTransaction)
 		traceLogger.OnTransactionStart(cnt, txo.ID())
 
-		for retry := 0; ; retry++ {
-			txh, err := txo.GetHandler(t.cm)
-			if err != nil {
-				t.log.Errorf("Fail to GetHandler err=%+v", err)
-				return err
-			}
-			ctx.UpdateSystemInfo()
-			rct, err := txh.Execute(ctx, wcs, false)
-			txh.Dispose()
-			if err == nil {
-				if err = t.plt.OnTransactionEnd(ctx, t.log, rct); err == nil {
-					rctBuf[cnt] = rct
-					break
-				}
-			}
-			if !errors.ExecutionFailError.Equals(err) && !errors.CriticalRerunError.Equals(err) {
-				t.log.Warnf("Fail to execute transaction err=%+v", err)
-				return err
-			}
-			if retry >= RetryCount {
-				t.log.Warnf("Fail to execute transaction retry=%d err=%+v", retry, err)
-				return err
-			}
-			t.log.Warnf("RETRY TX <%#x> for err=%+v", txo.ID(), err)
-			if err := ctx.Reset(wcs); err != nil {
-				t.log.Errorf("Fail to revert status on rerun err=%+v", err)
-				return errors.CriticalUnknownError.Wrapf(err, "FailToResetForRetry")
-			}
-			ts = time.Now()
-			traceLogger.OnTransactionReset()
+		rct, ts, err := t.executeTx(ctx, txo, wcs, traceLogger)
+		if err != nil {
+			return err
 		}
+		rctBuf[cnt] = rct
 
 		traceLogger.OnTransactionEnd(cnt, txo.ID(), txInfo.From, ctx.Treasury(), ctx.Revision(), rctBuf[cnt])
 		duration := time.Now().Sub(ts)
@@ -89,3 +63,40 @@ func (t *transition) executeTxsSequential(l module.TransactionList, ctx contract
 	}
 	return nil
 }
+
+// executeTx executes the transaction on the context. It retries the
+// execution after reverting the context to wcs on rerun errors. It returns
+// the start time of the last attempt along with the receipt.
+func (t *transition) executeTx(ctx contract.Context, txo transaction.Transaction, wcs state.WorldSnapshot, traceLogger *trace.Logger) (txresult.Receipt, time.Time, error) {
+	ts := time.Now()
+	for retry := 0; ; retry++ {
+		txh, err := txo.GetHandler(t.cm)
+		if err != nil {
+			t.log.Errorf("Fail to GetHandler err=%+v", err)
+			return nil, ts, err
+		}
+		ctx.UpdateSystemInfo()
+		rct, err := txh.Execute(ctx, wcs, false)
+		txh.Dispose()
+		if err == nil {
+			if err = t.plt.OnTransactionEnd(ctx, t.log, rct); err == nil {
+				return rct, ts, nil
+			}
+		}
+		if !errors.ExecutionFailError.Equals(err) && !errors.CriticalRerunError.Equals(err) {
+			t.log.Warnf("Fail to execute transaction err=%+v", err)
+			return nil, ts, err
+		}
+		if retry >= RetryCount {
+			t.log.Warnf("Fail to execute transaction retry=%d err=%+v", retry, err)
+			return nil, ts, err
+		}
+		t.log.Warnf("RETRY TX <%#x> for err=%+v", txo.ID(), err)
+		if err := ctx.Reset(wcs); err != nil {
+			t.log.Errorf("Fail to revert status on rerun err=%+v", err)
+			return nil, ts, errors.CriticalUnknownError.Wrapf(err, "FailToResetForRetry")
+		}
+		traceLogger.OnTransactionReset()
+		ts = time.Now()
+	}
+}
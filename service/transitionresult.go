@@ -143,6 +143,30 @@ func NewBTPContext(dbase db.Database, result []byte) (state.BTPContext, error) {
 	return state.NewBTPContext(nil, as), nil
 }
 
+func StateHashFromResult(result []byte) ([]byte, error) {
+	r, err := newTransitionResultFromBytes(result)
+	if err != nil {
+		return nil, err
+	}
+	return r.StateHash, nil
+}
+
+func PatchReceiptHashFromResult(result []byte) ([]byte, error) {
+	r, err := newTransitionResultFromBytes(result)
+	if err != nil {
+		return nil, err
+	}
+	return r.PatchReceiptHash, nil
+}
+
+func NormalReceiptHashFromResult(result []byte) ([]byte, error) {
+	r, err := newTransitionResultFromBytes(result)
+	if err != nil {
+		return nil, err
+	}
+	return r.NormalReceiptHash, nil
+}
+
 func BTPDigestHashFromResult(result []byte) ([]byte, error) {
 	r, err := newTransitionResultFromBytes(result)
 	if err != nil {
@@ -150,3 +174,11 @@ func BTPDigestHashFromResult(result []byte) ([]byte, error) {
 	}
 	return r.BTPData, nil
 }
+
+func ExtensionDataFromResult(result []byte) ([]byte, error) {
+	r, err := newTransitionResultFromBytes(result)
+	if err != nil {
+		return nil, err
+	}
+	return r.ExtensionData, nil
+}
@@ -411,6 +411,25 @@ func (r *receipt) GetProofOfEvent(i int) ([][]byte, error) {
 	return proof, nil
 }
 
+// ProveEvent returns the event log at the index of the receipt if the
+// proof is valid.
+func ProveEvent(r Receipt, i int, proof [][]byte) (module.EventLog, error) {
+	rct, ok := r.(*receipt)
+	if !ok || rct.version < Version2 || rct.eventLogs == nil {
+		return nil, errors.ErrInvalidState
+	}
+	k := codec.BC.MustMarshalToBytes(uint(i))
+	obj, err := rct.eventLogs.Prove(k, proof)
+	if err != nil {
+		return nil, errors.InvalidStateError.Wrapf(err, "InvalidProof(idx=%d)", i)
+	}
+	if ev, ok := obj.(*eventLog); !ok {
+		return nil, errors.InvalidStateError.Errorf("InvalidProof(idx=%d)", i)
+	} else {
+		return ev, nil
+	}
+}
+
 // AddPayment add payment information
 // addr is payer. steps is total steps paid by the payer.
 // feeSteps is amount of steps for fee.
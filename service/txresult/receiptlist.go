@@ -98,3 +98,25 @@ func NewReceiptListWithBuilder(builder merkle.Builder, h []byte) module.ReceiptL
 	snapshot.Resolve(builder)
 	return &receiptList{snapshot}
 }
+
+// ProveReceipt returns the receipt at the index of the receipt list with
+// the hash if the proof is valid.
+func ProveReceipt(h []byte, n int, proof [][]byte) (Receipt, error) {
+	if len(h) == 0 {
+		return nil, errors.NotFoundError.New("EmptyReceiptList")
+	}
+	b, err := codec.BC.MarshalToBytes(uint(n))
+	if err != nil {
+		return nil, err
+	}
+	immutable := trie_manager.NewImmutableForObject(db.NewMapDB(), h, ReceiptType)
+	obj, err := immutable.Prove(b, proof)
+	if err != nil {
+		return nil, errors.InvalidStateError.Wrapf(err, "InvalidProof(idx=%d)", n)
+	}
+	if rct, ok := obj.(Receipt); !ok {
+		return nil, errors.InvalidStateError.Errorf("InvalidProof(idx=%d)", n)
+	} else {
+		return rct, nil
+	}
+}
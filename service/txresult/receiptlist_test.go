@@ -7,6 +7,8 @@ import (
 	"math/big"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+
 	"github.com/icon-project/goloop/common"
 	"github.com/icon-project/goloop/common/db"
 	"github.com/icon-project/goloop/module"
@@ -65,3 +67,42 @@ func testReceiptListByRev(t *testing.T, rev module.Revision) {
 		idx++
 	}
 }
+
+func TestProveReceipt(t *testing.T) {
+	mdb := db.NewMapDB()
+	rev := module.UseMPTOnEvents
+	addr := common.MustNewAddressFromString("cx0003737589788888888888888888888888888888")
+
+	rslice := make([]Receipt, 0)
+	for i := 0; i < 3; i++ {
+		r := NewReceipt(mdb, rev, addr)
+		for j := 0; j < 3; j++ {
+			r.AddLog(addr, [][]byte{[]byte("Event(int)"), {byte(i)}}, [][]byte{{byte(j)}})
+		}
+		r.SetResult(module.StatusSuccess, big.NewInt(100), big.NewInt(10), nil)
+		rslice = append(rslice, r)
+	}
+	rl := NewReceiptListFromSlice(mdb, rslice)
+	hash := rl.Hash()
+	assert.NoError(t, rl.Flush())
+
+	proof, err := rl.GetProof(1)
+	assert.NoError(t, err)
+	rct, err := ProveReceipt(hash, 1, proof)
+	assert.NoError(t, err)
+	assert.Equal(t, rslice[1].Bytes(), rct.Bytes())
+
+	_, err = ProveReceipt(hash, 2, proof)
+	assert.Error(t, err)
+
+	r, _ := rl.Get(1)
+	eproof, err := r.GetProofOfEvent(2)
+	assert.NoError(t, err)
+	ev, err := ProveEvent(rct, 2, eproof)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{{2}}, ev.Data())
+	assert.Equal(t, [][]byte{[]byte("Event(int)"), {1}}, ev.Indexed())
+
+	_, err = ProveEvent(rct, 1, eproof)
+	assert.Error(t, err)
+}
@@ -24,6 +24,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/icon-project/goloop/btp/ntm"
 	"github.com/icon-project/goloop/chain/gs"
 	"github.com/icon-project/goloop/common/db"
 	"github.com/icon-project/goloop/common/log"
@@ -46,6 +47,8 @@ type Chain struct {
 	cvd       module.CommitVoteSetDecoder
 	gsBytes   []byte
 
+	concurrencyLevel int
+
 	mu    sync.Mutex
 	bwMap map[string]module.BaseWallet
 }
@@ -76,6 +79,9 @@ func (c *Chain) Channel() string {
 }
 
 func (c *Chain) ConcurrencyLevel() int {
+	if c.concurrencyLevel > 1 {
+		return c.concurrencyLevel
+	}
 	return 1
 }
 
@@ -115,6 +121,18 @@ func (c *Chain) ValidateTxOnSend() bool {
 	panic("implement me")
 }
 
+func (c *Chain) AddressIndex() module.AddressIndex {
+	return nil
+}
+
+func (c *Chain) MaxLogsRange() int64 {
+	return 1000
+}
+
+func (c *Chain) MaxLogsResults() int {
+	return 1000
+}
+
 var defaultGenesis = "{\n  \"accounts\": [\n    {\n      \"name\": \"god\",\n      \"address\": \"hx54f7853dc6481b670caf69c5a27c7c8fe5be8269\",\n      \"balance\": \"0x2961fff8ca4a62327800000\"\n    },\n    {\n      \"name\": \"treasury\",\n      \"address\": \"hx1000000000000000000000000000000000000000\",\n      \"balance\": \"0x0\"\n    }\n  ],\n  \"message\": \"A rhizome has no beginning or end; it is always in the middle, between things, interbeing, intermezzo. The tree is filiation, but the rhizome is alliance, uniquely alliance. The tree imposes the verb \\\"to be\\\" but the fabric of the rhizome is the conjunction, \\\"and ... and ...and...\\\"This conjunction carries enough force to shake and uproot the verb \\\"to be.\\\" Where are you going? Where are you coming from? What are you heading for? These are totally useless questions.\\n\\n - Mille Plateaux, Gilles Deleuze & Felix Guattari\\n\\n\\\"Hyperconnect the world\\\"\"\n}\n"
 
 func (c *Chain) Genesis() []byte {
@@ -173,7 +191,7 @@ func (c *Chain) Prune(gs string, dbt string, height int64) error {
 	panic("implement me")
 }
 
-func (c *Chain) Backup(file string, extra []string) error {
+func (c *Chain) Backup(file string, extra []string, base string) error {
 	panic("implement me")
 }
 
@@ -231,6 +249,13 @@ func (c *Chain) WalletFor(dsa string) module.BaseWallet {
 	switch dsa {
 	case "ecdsa/secp256k1":
 		return c.wallet
+	case "bls12-381":
+		bw, err := ntm.NewBLSWallet(c.wallet)
+		if err != nil {
+			return nil
+		}
+		c.bwMap[dsa] = bw
+		return bw
 	}
 	return nil
 }
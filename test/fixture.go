@@ -27,6 +27,7 @@ import (
 	"github.com/icon-project/goloop/common/wallet"
 	"github.com/icon-project/goloop/consensus"
 	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/network"
 )
 
 type Fixture struct {
@@ -157,6 +158,14 @@ func (f *Fixture) NewPrecommitList(blk module.BlockData, round int32, ntsVoteCou
 	return votes
 }
 
+// SetFaultModel sets the fault model of all nodes. Nodes are identified by
+// the string of their addresses in the fault model.
+func (f *Fixture) SetFaultModel(fm network.FaultModel) {
+	for _, n := range f.Nodes {
+		n.NM.SetFaultModel(fm)
+	}
+}
+
 func (f *Fixture) NewCommitVoteListForLastBlock(round int32, ntsVoteCount int) module.CommitVoteSet {
 	votes, pcm := f.newPrecommitsAndPCM(f.LastBlock, round, ntsVoteCount)
 	return consensus.NewCommitVoteList(pcm, votes...)
@@ -47,6 +47,7 @@ type FixtureConfig struct {
 	GenesisStorage    module.GenesisStorage
 	Wallet            module.Wallet
 	AddDefaultNode    *bool
+	ConcurrencyLevel  int
 }
 
 func NewFixtureConfig(t *testing.T, o ...FixtureOption) *FixtureConfig {
@@ -140,5 +141,8 @@ func (cf *FixtureConfig) Override(cf2 *FixtureConfig) *FixtureConfig {
 	if cf2.AddDefaultNode != nil {
 		res.AddDefaultNode = cf2.AddDefaultNode
 	}
+	if cf2.ConcurrencyLevel != 0 {
+		res.ConcurrencyLevel = cf2.ConcurrencyLevel
+	}
 	return &res
 }
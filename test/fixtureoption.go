@@ -61,3 +61,9 @@ func UseWallet(w module.Wallet) FixtureOption {
 func AddDefaultNode(v bool) FixtureOption {
 	return UseConfig(&FixtureConfig{AddDefaultNode: &v})
 }
+
+// UseConcurrencyLevel option makes the node execute transactions
+// concurrently if n is greater than one.
+func UseConcurrencyLevel(n int) FixtureOption {
+	return UseConfig(&FixtureConfig{ConcurrencyLevel: n})
+}
@@ -19,6 +19,7 @@ package test
 import (
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/icon-project/goloop/common"
 	"github.com/icon-project/goloop/common/errors"
@@ -39,6 +40,7 @@ type NetworkManager struct {
 	peers    []Peer
 	handlers []*nmHandler
 	roles    map[string]module.Role
+	fm       network.FaultModel
 }
 
 func indexOf(pl []Peer, id module.PeerID) int {
@@ -119,6 +121,37 @@ func (n *NetworkManager) detach(p Peer) {
 	}
 }
 
+// SetFaultModel sets the fault model for packets sent to the peers. Peers
+// are identified by the string of their IDs in the fault model. Packets
+// dropped by the model are lost silently, so it may be used for injecting
+// latency, loss and partition same as network.MemoryNetwork.
+func (n *NetworkManager) SetFaultModel(fm network.FaultModel) {
+	al := common.Lock(&nmMu)
+	defer al.Unlock()
+
+	n.fm = fm
+}
+
+// sendTo delivers the packet to the peer applying the fault model. It's
+// called without nmMu.
+func sendTo(fm network.FaultModel, pk *Packet, p Peer) {
+	if fm == nil {
+		p.notifyPacket(pk, nil)
+		return
+	}
+	delay, drop := fm.Deliver(pk.Src.String(), p.ID().String(), len(pk.Data))
+	if drop {
+		return
+	}
+	if delay > 0 {
+		time.AfterFunc(delay, func() {
+			p.notifyPacket(pk, nil)
+		})
+	} else {
+		p.notifyPacket(pk, nil)
+	}
+}
+
 func (n *NetworkManager) notifyPacket(pk *Packet, cb func(rebroadcast bool, err error)) {
 	n.rCh <- packetEntry{pk, cb}
 }
@@ -242,10 +275,11 @@ func (h *nmHandler) Broadcast(pi module.ProtocolInfo, b []byte, bt module.Broadc
 		b,
 	}
 	peers := append([]Peer(nil), h.n.peers...)
+	fm := h.n.fm
 	al.Unlock()
 
 	for _, p := range peers {
-		p.notifyPacket(pk, nil)
+		sendTo(fm, pk, p)
 	}
 	return nil
 }
@@ -268,9 +302,10 @@ func (h *nmHandler) Multicast(pi module.ProtocolInfo, b []byte, role module.Role
 			peers = append(peers, p)
 		}
 	}
+	fm := h.n.fm
 	al.Unlock()
 	for _, p := range peers {
-		p.notifyPacket(pk, nil)
+		sendTo(fm, pk, p)
 	}
 	return nil
 }
@@ -289,9 +324,10 @@ func (h *nmHandler) Unicast(pi module.ProtocolInfo, b []byte, id module.PeerID)
 			b,
 		}
 		p := h.n.peers[idx]
+		fm := h.n.fm
 		al.Unlock()
 
-		p.notifyPacket(pk, nil)
+		sendTo(fm, pk, p)
 		return nil
 	}
 	return errors.New("no peer")
@@ -300,3 +336,6 @@ func (h *nmHandler) Unicast(pi module.ProtocolInfo, b []byte, id module.PeerID)
 func (h *nmHandler) GetPeers() []module.PeerID {
 	return h.n.GetPeers()
 }
+
+func (h *nmHandler) ReportPeer(id module.PeerID, penalty int, reason string) {
+}
@@ -70,6 +70,7 @@ func NewNode(t *testing.T, o ...FixtureOption) *Node {
 	c, err := NewChain(t, w, dbase, logger, cf.CVSD, cf.Genesis)
 	assert.NoError(t, err)
 	c.Logger().SetLevel(log.TraceLevel)
+	c.concurrencyLevel = cf.ConcurrencyLevel
 
 	// set up sm
 	RegisterTransactionFactory()
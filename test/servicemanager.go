@@ -53,6 +53,8 @@ type ServiceManager struct {
 	nextBlockVersion int
 	pool             []module.Transaction
 	txWaiters        []func()
+	pending          []module.Patch
+	patchTXs         map[string]module.Patch
 }
 
 func NewServiceManager(
@@ -72,6 +74,7 @@ func NewServiceManager(
 		tsc:              service.NewTimestampChecker(),
 		emptyTXs:         transaction.NewTransactionListFromSlice(dbase, nil),
 		nextBlockVersion: module.BlockVersion2,
+		patchTXs:         make(map[string]module.Patch),
 	}
 }
 
@@ -135,12 +138,41 @@ func (sm *ServiceManager) CreateTransition(parent module.Transition, txs module.
 	), nil
 }
 
+// GetPatches returns patch transactions for the patches sent by SendPatch,
+// which are not included in finalized blocks yet.
 func (sm *ServiceManager) GetPatches(parent module.Transition, bi module.BlockInfo) module.TransactionList {
-	return sm.emptyTXs
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if len(sm.pending) == 0 {
+		return sm.emptyTXs
+	}
+	var txs []module.Transaction
+	for _, p := range sm.pending {
+		tx, err := transaction.NewPatchTransaction(
+			p, sm.chain.NID(), bi.Timestamp(), sm.chain.Wallet())
+		if err != nil {
+			sm.logger.Panicf("Fail to make transaction from patch err=%+v", err)
+		}
+		sm.patchTXs[string(tx.ID())] = p
+		txs = append(txs, tx)
+	}
+	return transaction.NewTransactionListFromSlice(sm.dbase, txs)
+}
+
+func (sm *ServiceManager) SendPatch(patch module.Patch) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	sm.pending = append(sm.pending, patch)
+	return nil
 }
 
 func (sm *ServiceManager) PatchTransition(transition module.Transition, patches module.TransactionList, bi module.BlockInfo) module.Transition {
-	return transition
+	if len(patches.Hash()) == 0 {
+		return transition
+	}
+	return service.PatchTransition(transition, bi, patches)
 }
 
 func (sm *ServiceManager) CreateSyncTransition(transition module.Transition, result []byte, vlHash []byte, noBuffer bool) module.Transition {
@@ -162,9 +194,32 @@ func (sm *ServiceManager) Finalize(transition module.Transition, opt int) error
 		filtered = append(filtered, t)
 	}
 	sm.pool = filtered
+	sm.removeIncludedPatches(transition.PatchTransactions())
 	return res
 }
 
+func (sm *ServiceManager) removeIncludedPatches(txs module.TransactionList) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	for i := txs.Iterator(); i.Has(); i.Next() {
+		tx, _, err := i.Get()
+		if err != nil {
+			continue
+		}
+		p, ok := sm.patchTXs[string(tx.ID())]
+		if !ok {
+			continue
+		}
+		for idx, pp := range sm.pending {
+			if pp == p {
+				sm.pending = append(sm.pending[:idx], sm.pending[idx+1:]...)
+				break
+			}
+		}
+	}
+}
+
 func (sm *ServiceManager) WaitForTransaction(parent module.Transition, bi module.BlockInfo, cb func()) bool {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
@@ -219,6 +274,19 @@ func (sm *ServiceManager) GetMinimizeBlockGen(result []byte) bool {
 	return scoredb.NewVarDB(as, state.VarMinimizeBlockGen).Bool()
 }
 
+func (sm *ServiceManager) GetConsensusTimeouts(result []byte) *module.ConsensusTimeouts {
+	ws, err := service.NewWorldSnapshot(sm.dbase, sm.plt, result, nil)
+	if err != nil {
+		return nil
+	}
+	ass := ws.GetAccountSnapshot(state.SystemID)
+	as := scoredb.NewStateStoreWith(ass)
+	if as == nil {
+		return nil
+	}
+	return state.GetConsensusTimeouts(as)
+}
+
 func (sm *ServiceManager) GetNextBlockVersion(result []byte) int {
 	if result == nil {
 		return sm.plt.DefaultBlockVersionFor(sm.chain.CID())